@@ -1,12 +1,53 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
 	"github.com/kevsmith/my-mcp/pkg/server"
-	mcpServer "github.com/mark3labs/mcp-go/server"
 )
 
 func main() {
-	srv := server.DocumentSetup()
+	// Parse command line flags; they override environment variables
+	applyFlags := server.DocumentFlags(flag.CommandLine)
+	transport := server.RegisterTransportFlags(flag.CommandLine)
+	applyLimits := server.LimitFlags(flag.CommandLine)
+	loadConfig := server.ConfigFlag(flag.CommandLine)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: document-mcp [flags] [root-dir1] [root-dir2] ...\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "With root directories, only documents under them can be read or written.\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	applyFlags()
+	applyLimits()
+
+	config, err := loadConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	allowedRoots := flag.Args()
+	if len(allowedRoots) == 0 {
+		allowedRoots = config.Document.AllowedRoots
+	}
+
+	srv, err := server.DocumentSetup(allowedRoots)
+	if err != nil {
+		log.Fatalf("Failed to create server: %v", err)
+	}
+
+	if len(allowedRoots) > 0 {
+		fmt.Fprintf(os.Stderr, "Starting document-mcp server with allowed roots: %v\n", allowedRoots)
+	}
 
-	mcpServer.ServeStdio(srv)
+	// Serve returns on SIGINT or SIGTERM once requests in flight are done
+	err = server.Serve(srv, *transport)
+	fmt.Fprintf(os.Stderr, "\nShutting down document-mcp server...\n")
+	server.ShutdownDocument()
+	if err != nil {
+		log.Fatalf("Server error: %v", err)
+	}
 }
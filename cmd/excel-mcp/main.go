@@ -2,33 +2,55 @@ package main
 
 import (
 	"flag"
+	"fmt"
+	"log"
 	"os"
-	"strconv"
 
 	"github.com/kevsmith/my-mcp/pkg/server"
-	mcpServer "github.com/mark3labs/mcp-go/server"
 )
 
 func main() {
-	var cacheSize int
-	var cacheTTLMinutes int
-
-	// Parse command line flags
-	flag.IntVar(&cacheSize, "cache-size", 0, "Maximum number of Excel files to cache (default: 10, env: EXCEL_CACHE_MAX_SIZE)")
-	flag.IntVar(&cacheTTLMinutes, "cache-ttl", 0, "Cache TTL in minutes (default: 5, env: EXCEL_CACHE_TTL_MINUTES)")
+	// Parse command line flags; they override environment variables
+	applyFlags := server.ExcelFlags(flag.CommandLine)
+	transport := server.RegisterTransportFlags(flag.CommandLine)
+	applyLimits := server.LimitFlags(flag.CommandLine)
+	loadConfig := server.ConfigFlag(flag.CommandLine)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: excel-mcp [flags] [root-dir1] [root-dir2] ...\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "With root directories, only workbooks under them can be opened.\n")
+		flag.PrintDefaults()
+	}
 	flag.Parse()
+	applyFlags()
+	applyLimits()
 
-	// Override environment variables if command line args are provided
-	if cacheSize > 0 {
-		os.Setenv("EXCEL_CACHE_MAX_SIZE", strconv.Itoa(cacheSize))
+	config, err := loadConfig()
+	if err != nil {
+		log.Fatal(err)
 	}
-	if cacheTTLMinutes > 0 {
-		os.Setenv("EXCEL_CACHE_TTL_MINUTES", strconv.Itoa(cacheTTLMinutes))
+
+	// Root directories given as arguments replace those in the config file
+	allowedRoots := flag.Args()
+	if len(allowedRoots) == 0 {
+		allowedRoots = config.Excel.AllowedRoots
 	}
 
 	// Setup the MCP server with all tools and handlers
-	srv := server.ExcelSetup()
+	srv, err := server.ExcelSetup(allowedRoots)
+	if err != nil {
+		log.Fatalf("Failed to create server: %v", err)
+	}
 
-	// Start serving via stdio
-	mcpServer.ServeStdio(srv)
+	if len(allowedRoots) > 0 {
+		fmt.Fprintf(os.Stderr, "Starting excel-mcp server with allowed roots: %v\n", allowedRoots)
+	}
+
+	// Start serving via stdio, or HTTP with --transport; Serve returns on
+	// SIGINT or SIGTERM once requests in flight are done
+	err = server.Serve(srv, *transport)
+	fmt.Fprintf(os.Stderr, "\nShutting down excel-mcp server...\n")
+	server.ShutdownExcel()
+	if err != nil {
+		log.Fatalf("Server error: %v", err)
+	}
 }
@@ -1,20 +1,43 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
 	"os"
 
 	mcpserver "github.com/kevsmith/my-mcp/pkg/server"
-	"github.com/mark3labs/mcp-go/server"
 )
 
 func main() {
-	if len(os.Args) < 2 {
-		log.Fatal("Usage: fs-mcp <root-dir1> [root-dir2] [root-dir3] ...")
+	// Parse command line flags; they override environment variables
+	applyFlags := mcpserver.FilesystemFlags(flag.CommandLine)
+	transport := mcpserver.RegisterTransportFlags(flag.CommandLine)
+	applyLimits := mcpserver.LimitFlags(flag.CommandLine)
+	loadConfig := mcpserver.ConfigFlag(flag.CommandLine)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: fs-mcp [flags] <root-dir1> [root-dir2] [root-dir3] ...\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "Root directories may instead be set as filesystem.allowed_roots in the config file.\n")
+		flag.PrintDefaults()
 	}
+	flag.Parse()
+	applyFlags()
+	applyLimits()
 
-	allowedRoots := os.Args[1:]
+	config, err := loadConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Root directories given as arguments replace those in the config file
+	allowedRoots := flag.Args()
+	if len(allowedRoots) == 0 {
+		allowedRoots = config.Filesystem.AllowedRoots
+	}
+	if len(allowedRoots) == 0 {
+		flag.Usage()
+		os.Exit(1)
+	}
 
 	s, err := mcpserver.NewMCPServer(allowedRoots)
 	if err != nil {
@@ -23,7 +46,11 @@ func main() {
 
 	fmt.Fprintf(os.Stderr, "Starting fs-mcp server v2.0 with allowed roots: %v\n", allowedRoots)
 
-	if err := server.ServeStdio(s); err != nil {
+	// Serve returns on SIGINT or SIGTERM once requests in flight are done;
+	// workspaces that haven't expired are removed afterwards
+	err = mcpserver.Serve(s, *transport)
+	mcpserver.ShutdownFilesystem()
+	if err != nil {
 		log.Fatalf("Server error: %v", err)
 	}
 }
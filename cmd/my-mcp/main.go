@@ -0,0 +1,162 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/kevsmith/my-mcp/pkg/server"
+	"github.com/kevsmith/my-mcp/pkg/shared"
+	mcpServer "github.com/mark3labs/mcp-go/server"
+)
+
+const usage = `Usage: my-mcp <command> [flags] [args]
+
+Commands:
+  excel      Excel spreadsheet tools (like excel-mcp)
+  fs         Filesystem tools under the given root directories (like fs-mcp)
+  document   Document text extraction tools (like document-mcp)
+  outlook    Outlook mail, calendar and contact tools (like outlook-mcp)
+  all        Every toolset in one server, tool names prefixed with
+             excel_, document_, fs_ and outlook_
+
+Run "my-mcp <command> -h" for the flags of a command.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	command, args := os.Args[1], os.Args[2:]
+	if command == "help" || command == "-h" || command == "--help" {
+		fmt.Print(usage)
+		return
+	}
+
+	flags := flag.NewFlagSet("my-mcp "+command, flag.ExitOnError)
+	transport := server.RegisterTransportFlags(flags)
+	applyLimits := server.LimitFlags(flags)
+	loadConfig := server.ConfigFlag(flags)
+
+	// config applies the limit flags and loads the configuration file once
+	// the command's flags are applied, so that they take precedence over it
+	config := func() *shared.Config {
+		applyLimits()
+		config, err := loadConfig()
+		if err != nil {
+			log.Fatal(err)
+		}
+		return config
+	}
+
+	var srv *mcpServer.MCPServer
+	var shutdown func() error
+	var err error
+
+	switch command {
+	case "excel":
+		applyFlags := server.ExcelFlags(flags)
+		flags.Usage = commandUsage(flags, "[root-dir1] [root-dir2] ...")
+		flags.Parse(args)
+		applyFlags()
+		srv, err = server.ExcelSetup(rootsOr(flags.Args(), config().Excel.AllowedRoots))
+		shutdown = server.ShutdownExcel
+
+	case "fs":
+		applyFlags := server.FilesystemFlags(flags)
+		flags.Usage = commandUsage(flags, "<root-dir1> [root-dir2] ...")
+		flags.Parse(args)
+		applyFlags()
+		roots := rootsOr(flags.Args(), config().Filesystem.AllowedRoots)
+		if len(roots) == 0 {
+			flags.Usage()
+			os.Exit(2)
+		}
+		srv, err = server.NewMCPServer(roots)
+		shutdown = server.ShutdownFilesystem
+
+	case "document":
+		applyFlags := server.DocumentFlags(flags)
+		flags.Usage = commandUsage(flags, "[root-dir1] [root-dir2] ...")
+		flags.Parse(args)
+		applyFlags()
+		srv, err = server.DocumentSetup(rootsOr(flags.Args(), config().Document.AllowedRoots))
+		shutdown = server.ShutdownDocument
+
+	case "outlook":
+		applyFlags := server.OutlookFlags(flags)
+		flags.Parse(args)
+		applyFlags()
+		config()
+		if err := server.OutlookAvailable(); err != nil {
+			log.Fatal(err)
+		}
+		srv, err = server.NewOutlookMCPServer()
+		shutdown = server.ShutdownOutlookManager
+
+	case "all":
+		// Document and Excel share flag names, so their settings come from
+		// the environment in this mode
+		applyFS := server.FilesystemFlags(flags)
+		applyOutlook := server.OutlookFlags(flags)
+		var noOutlook bool
+		flags.BoolVar(&noOutlook, "no-outlook", false, "Leave out the Outlook tools")
+		flags.Usage = commandUsage(flags, "[root-dir1] [root-dir2] ...")
+		flags.Parse(args)
+		applyFS()
+		applyOutlook()
+		roots := rootsOr(flags.Args(), config().Filesystem.AllowedRoots)
+
+		includeOutlook := !noOutlook
+		if includeOutlook {
+			if err := server.OutlookAvailable(); err != nil {
+				fmt.Fprintf(os.Stderr, "Leaving out the Outlook tools: %v\n", err)
+				includeOutlook = false
+			}
+		}
+		if len(roots) == 0 {
+			fmt.Fprintf(os.Stderr, "Leaving out the filesystem tools: no root directories given\n")
+		}
+		srv, err = server.AllSetup(roots, includeOutlook)
+		shutdown = server.ShutdownAll
+
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n%s", command, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatalf("Failed to create server: %v", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Starting my-mcp %s server...\n", command)
+
+	// Serve returns on SIGINT or SIGTERM once requests in flight are done
+	err = server.Serve(srv, *transport)
+	if shutdown != nil {
+		fmt.Fprintf(os.Stderr, "\nShutting down my-mcp %s server...\n", command)
+		shutdown()
+	}
+	if err != nil {
+		log.Fatalf("Server error: %v", err)
+	}
+}
+
+// rootsOr returns the root directories given as arguments, or the
+// configured ones when there are none
+func rootsOr(args, configured []string) []string {
+	if len(args) > 0 {
+		return args
+	}
+	return configured
+}
+
+// commandUsage prints a command's usage line with its positional arguments
+// followed by its flags
+func commandUsage(flags *flag.FlagSet, arguments string) func() {
+	return func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s [flags] %s\n", flags.Name(), arguments)
+		flags.PrintDefaults()
+	}
+}
@@ -1,21 +1,40 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
 	"os"
-	"os/signal"
-	"runtime"
-	"syscall"
 
+	"github.com/kevsmith/my-mcp/pkg/outlook"
 	outlookserver "github.com/kevsmith/my-mcp/pkg/server"
-	"github.com/mark3labs/mcp-go/server"
 )
 
 func main() {
-	// Check if running on Windows
-	if runtime.GOOS != "windows" {
-		log.Fatal("outlook-mcp server is only supported on Windows")
+	// Parse command line flags; they override environment variables
+	applyFlags := outlookserver.OutlookFlags(flag.CommandLine)
+	transport := outlookserver.RegisterTransportFlags(flag.CommandLine)
+	applyLimits := outlookserver.LimitFlags(flag.CommandLine)
+	loadConfig := outlookserver.ConfigFlag(flag.CommandLine)
+	stopInstance := flag.Bool("stop-instance", false, "Stop the persistent PowerShell server named by --instance and exit")
+	flag.Parse()
+	applyFlags()
+	applyLimits()
+
+	if _, err := loadConfig(); err != nil {
+		log.Fatal(err)
+	}
+
+	if *stopInstance {
+		if err := outlook.StopInstance(); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Fprintf(os.Stderr, "Stopped persistent instance %q\n", os.Getenv("OUTLOOK_INSTANCE"))
+		return
+	}
+
+	if err := outlookserver.OutlookAvailable(); err != nil {
+		log.Fatal(err)
 	}
 
 	s, err := outlookserver.NewOutlookMCPServer()
@@ -23,19 +42,13 @@ func main() {
 		log.Fatalf("Failed to create server: %v", err)
 	}
 
-	// Handle graceful shutdown
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
-	go func() {
-		<-c
-		fmt.Fprintf(os.Stderr, "\nShutting down outlook-mcp server...\n")
-		outlookserver.ShutdownOutlookManager()
-		os.Exit(0)
-	}()
-
-	fmt.Fprintf(os.Stderr, "Starting outlook-mcp server for Windows...\n")
+	fmt.Fprintf(os.Stderr, "Starting outlook-mcp server...\n")
 
-	if err := server.ServeStdio(s); err != nil {
+	// Serve returns on SIGINT or SIGTERM once requests in flight are done
+	err = outlookserver.Serve(s, *transport)
+	fmt.Fprintf(os.Stderr, "\nShutting down outlook-mcp server...\n")
+	outlookserver.ShutdownOutlookManager()
+	if err != nil {
 		log.Fatalf("Server error: %v", err)
 	}
 }
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+
+	"github.com/kevsmith/my-mcp/pkg/outlook"
+	workspaceserver "github.com/kevsmith/my-mcp/pkg/server"
+)
+
+func main() {
+	applyOutlookFlags := workspaceserver.OutlookFlags(flag.CommandLine)
+	transport := workspaceserver.RegisterTransportFlags(flag.CommandLine)
+	applyLimits := workspaceserver.LimitFlags(flag.CommandLine)
+	loadConfig := workspaceserver.ConfigFlag(flag.CommandLine)
+	flag.Parse()
+	applyOutlookFlags()
+	applyLimits()
+
+	if _, err := loadConfig(); err != nil {
+		log.Fatal(err)
+	}
+
+	// The workspace server drives Outlook, so it shares outlook-mcp's platform
+	// restriction unless OUTLOOK_BACKEND selects Microsoft Graph
+	if os.Getenv("OUTLOOK_BACKEND") != outlook.BackendGraph && runtime.GOOS != "windows" {
+		log.Fatal("workspace-mcp server is only supported on Windows unless OUTLOOK_BACKEND=graph")
+	}
+
+	s, err := workspaceserver.NewWorkspaceMCPServer()
+	if err != nil {
+		log.Fatalf("Failed to create server: %v", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Starting workspace-mcp server...\n")
+
+	// Serve returns on SIGINT or SIGTERM once requests in flight are done
+	err = workspaceserver.Serve(s, *transport)
+	fmt.Fprintf(os.Stderr, "\nShutting down workspace-mcp server...\n")
+	workspaceserver.ShutdownWorkspace()
+	if err != nil {
+		log.Fatalf("Server error: %v", err)
+	}
+}
@@ -0,0 +1,343 @@
+package document
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Analysis parameters
+const (
+	wordsPerMinute     = 238 // Average silent reading speed for non-fiction
+	maxVocabularyTerms = 20
+	languageSampleSize = 200 // Words per sample when detecting languages
+	minLanguageShare   = 0.1 // Languages below this share of the text are not reported
+)
+
+// Language is a language detected in a document with the share of its words
+// written in it
+type Language struct {
+	Code  string  `json:"code"` // ISO 639-1
+	Name  string  `json:"name"`
+	Share float64 `json:"share"`
+}
+
+// VocabularyTerm is a frequent content word
+type VocabularyTerm struct {
+	Word  string `json:"word"`
+	Count int    `json:"count"`
+}
+
+// DocumentAnalysis describes the text of a document: its languages, size,
+// reading time and vocabulary
+type DocumentAnalysis struct {
+	FilePath           string           `json:"file_path"`
+	Languages          []Language       `json:"languages"`
+	Characters         int              `json:"characters"`
+	Words              int              `json:"words"`
+	Sentences          int              `json:"sentences"`
+	Paragraphs         int              `json:"paragraphs"`
+	WordsPerSentence   float64          `json:"words_per_sentence"`
+	ReadingTimeMinutes float64          `json:"reading_time_minutes"`
+	UniqueWords        int              `json:"unique_words"`
+	LexicalDiversity   float64          `json:"lexical_diversity"` // Unique words / words
+	TopWords           []VocabularyTerm `json:"top_words"`
+	Text               string           `json:"text,omitempty"`
+}
+
+// languageNames names the languages the detector can report
+var languageNames = map[string]string{
+	"en": "English", "fr": "French", "de": "German", "es": "Spanish", "it": "Italian",
+	"pt": "Portuguese", "nl": "Dutch", "sv": "Swedish", "ru": "Russian", "uk": "Ukrainian",
+	"el": "Greek", "ar": "Arabic", "he": "Hebrew", "hi": "Hindi", "th": "Thai",
+	"zh": "Chinese", "ja": "Japanese", "ko": "Korean",
+}
+
+// languageStopwords are the most frequent function words of the languages
+// written in the Latin script, which tell them apart in a few sentences
+var languageStopwords = stopwordSets(map[string]string{
+	"en": "the and of to a in is that it for on with as was are be this by not or have from at but an they which you his her he she we has were been their will would there can all its",
+	"fr": "le la les de des du et est un une que qui dans pour pas sur au aux ce cette il elle sont avec par plus ne se nous vous ont été être son sa ses leur mais ou",
+	"de": "der die das und ist nicht ein eine zu den mit von dem des sich auf für im ich sie es auch als wird werden wir aus bei oder nach hat sind wie noch nur über",
+	"es": "el la los las de del y en que un una es por con para no se su sus al lo como más pero fue son ha este esta entre sin sobre también le muy",
+	"it": "il lo la gli le di del della e che un una è per non con sono si nel alla dei delle anche come più ma ha questo questa da al tra essere",
+	"pt": "o a os as de do da dos das e que um uma é em no na não para com por se mais como mas foi ao são seu sua também ele ela isso entre",
+	"nl": "de het een en van is dat in niet op te zijn voor met die er aan als ook maar bij of om dan nog wel naar door wordt zo kan hebben",
+	"sv": "och att det som en på är av för med den till inte har de om ett men var jag så från kan vid eller när skulle också nu sig hade",
+})
+
+func stopwordSets(lists map[string]string) map[string]map[string]bool {
+	sets := make(map[string]map[string]bool, len(lists))
+	for code, list := range lists {
+		sets[code] = make(map[string]bool)
+		for _, word := range strings.Fields(list) {
+			sets[code][word] = true
+		}
+	}
+	return sets
+}
+
+// AnalyzeDocument extracts the text of a document and reports its languages,
+// word, sentence and paragraph counts, reading time and most frequent content
+// words. With includeText the text itself is returned as well.
+func (m *Manager) AnalyzeDocument(ctx context.Context, filePath string, includeText bool) (*DocumentAnalysis, error) {
+	text, err := m.ExtractText(ctx, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	analysis := analyzeText(text)
+	analysis.FilePath = filePath
+	if includeText {
+		analysis.Text = text
+	}
+	return analysis, nil
+}
+
+// analyzeText computes the statistics of a text
+func analyzeText(text string) *DocumentAnalysis {
+	words := textWords(text)
+	analysis := &DocumentAnalysis{
+		Languages:  detectLanguages(words),
+		Characters: len([]rune(text)),
+		Words:      len(words),
+		Sentences:  countSentences(text),
+		TopWords:   []VocabularyTerm{},
+	}
+
+	for _, paragraph := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n\n") {
+		if strings.TrimSpace(paragraph) != "" {
+			analysis.Paragraphs++
+		}
+	}
+	if len(words) == 0 {
+		return analysis
+	}
+
+	analysis.WordsPerSentence = round1(float64(len(words)) / float64(max(analysis.Sentences, 1)))
+	analysis.ReadingTimeMinutes = round1(float64(len(words)) / wordsPerMinute)
+
+	counts := make(map[string]int)
+	for _, word := range words {
+		counts[word]++
+	}
+	analysis.UniqueWords = len(counts)
+	analysis.LexicalDiversity = math.Round(float64(len(counts))/float64(len(words))*1000) / 1000
+
+	for word, count := range counts {
+		if isContentWord(word) {
+			analysis.TopWords = append(analysis.TopWords, VocabularyTerm{Word: word, Count: count})
+		}
+	}
+	sort.Slice(analysis.TopWords, func(i, j int) bool {
+		a, b := analysis.TopWords[i], analysis.TopWords[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Word < b.Word
+	})
+	if len(analysis.TopWords) > maxVocabularyTerms {
+		analysis.TopWords = analysis.TopWords[:maxVocabularyTerms]
+	}
+
+	return analysis
+}
+
+// textWords splits text into lower-case words. Chinese and Japanese are
+// written without spaces, so each of their characters counts as a word.
+func textWords(text string) []string {
+	var words []string
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			words = append(words, word.String())
+			word.Reset()
+		}
+	}
+
+	for _, r := range text {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana):
+			flush()
+			words = append(words, string(r))
+		case unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.Is(unicode.Mn, r):
+			word.WriteRune(unicode.ToLower(r))
+		case (r == '\'' || r == '’' || r == '-') && word.Len() > 0:
+			word.WriteRune(r) // Contractions and compounds stay one word
+		default:
+			flush()
+		}
+	}
+	flush()
+
+	// Drop the apostrophes and hyphens left at the end of a word
+	for i, w := range words {
+		words[i] = strings.TrimRight(w, "'’-")
+	}
+	return words
+}
+
+// countSentences counts sentence-ending punctuation followed by a space or
+// the end of the text (CJK full stops need no space); trailing text without
+// a full stop counts as a sentence too
+func countSentences(text string) int {
+	sentences := 0
+	inTerminator, pending := false, false // After a full stop; words since the last sentence
+	for _, r := range text {
+		switch {
+		case strings.ContainsRune("。！？", r):
+			sentences++
+			inTerminator, pending = false, false
+		case strings.ContainsRune(".!?؟।", r):
+			inTerminator = pending
+		case inTerminator && strings.ContainsRune(`"'”’)]»`, r):
+			// Closing quotes and brackets belong to the sentence
+		case inTerminator && unicode.IsSpace(r):
+			sentences++
+			inTerminator, pending = false, false
+		default:
+			inTerminator = false
+			pending = pending || unicode.IsLetter(r) || unicode.IsNumber(r)
+		}
+	}
+	if pending {
+		sentences++
+	}
+	return sentences
+}
+
+// detectLanguages detects the language of consecutive samples of words and
+// reports each language with its share of the sampled words, most used first
+func detectLanguages(words []string) []Language {
+	shares := make(map[string]int)
+	detected := 0
+	for start := 0; start < len(words); start += languageSampleSize {
+		sample := words[start:min(start+languageSampleSize, len(words))]
+		if code := detectLanguage(sample); code != "" {
+			shares[code] += len(sample)
+			detected += len(sample)
+		}
+	}
+
+	languages := []Language{}
+	for code, count := range shares {
+		share := float64(count) / float64(detected)
+		if share >= minLanguageShare {
+			languages = append(languages, Language{Code: code, Name: languageNames[code], Share: math.Round(share*100) / 100})
+		}
+	}
+	sort.Slice(languages, func(i, j int) bool {
+		if languages[i].Share != languages[j].Share {
+			return languages[i].Share > languages[j].Share
+		}
+		return languages[i].Code < languages[j].Code
+	})
+	return languages
+}
+
+// detectLanguage identifies the language of a sample of words: by script for
+// the languages that have one of their own, else by stopword frequency.
+// Samples that can't be identified return "".
+func detectLanguage(words []string) string {
+	scripts := make(map[string]int)
+	letters := 0
+	for _, word := range words {
+		for _, r := range word {
+			if !unicode.IsLetter(r) {
+				continue
+			}
+			letters++
+			switch {
+			case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+				scripts["ja"]++
+			case unicode.Is(unicode.Han, r):
+				scripts["zh"]++
+			case unicode.Is(unicode.Hangul, r):
+				scripts["ko"]++
+			case strings.ContainsRune("іїєґ", r):
+				scripts["uk"]++
+			case unicode.Is(unicode.Cyrillic, r):
+				scripts["ru"]++
+			case unicode.Is(unicode.Greek, r):
+				scripts["el"]++
+			case unicode.Is(unicode.Arabic, r):
+				scripts["ar"]++
+			case unicode.Is(unicode.Hebrew, r):
+				scripts["he"]++
+			case unicode.Is(unicode.Devanagari, r):
+				scripts["hi"]++
+			case unicode.Is(unicode.Thai, r):
+				scripts["th"]++
+			case unicode.Is(unicode.Latin, r):
+				scripts["latin"]++
+			}
+		}
+	}
+	if letters == 0 {
+		return ""
+	}
+
+	// Japanese mixes kana with Han characters; Ukrainian is Cyrillic with a
+	// few letters of its own
+	if scripts["ja"] > 0 && scripts["ja"]+scripts["zh"] > letters/2 {
+		return "ja"
+	}
+	if scripts["uk"] > 0 && scripts["uk"]+scripts["ru"] > letters/2 {
+		return "uk"
+	}
+	best, bestCount := "", 0
+	for script, count := range scripts {
+		if count > bestCount || count == bestCount && script < best {
+			best, bestCount = script, count
+		}
+	}
+	if best != "latin" {
+		return best
+	}
+
+	hits := make(map[string]int)
+	for _, word := range words {
+		for code, stopwords := range languageStopwords {
+			if stopwords[word] {
+				hits[code]++
+			}
+		}
+	}
+	best, bestCount = "", 0
+	for code, count := range hits {
+		if count > bestCount || count == bestCount && code < best {
+			best, bestCount = code, count
+		}
+	}
+	// A couple of stopwords in a list of names or numbers prove nothing
+	if bestCount < 2 || bestCount*20 < len(words) {
+		return ""
+	}
+	return best
+}
+
+// isContentWord reports whether a word is worth listing in the vocabulary:
+// not a stopword, a number or a single character
+func isContentWord(word string) bool {
+	if len([]rune(word)) < 3 {
+		return false
+	}
+	for _, stopwords := range languageStopwords {
+		if stopwords[word] {
+			return false
+		}
+	}
+	for _, r := range word {
+		if unicode.IsLetter(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// round1 rounds to one decimal place
+func round1(value float64) float64 {
+	return math.Round(value*10) / 10
+}
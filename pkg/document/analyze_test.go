@@ -0,0 +1,77 @@
+package document
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeText(t *testing.T) {
+	text := "The budget was approved. Budget reviews start in March!\n\n" +
+		"The committee said: \"Budget cuts are unlikely.\" Reviews continue"
+
+	analysis := analyzeText(text)
+	if analysis.Words != 18 || analysis.Sentences != 4 || analysis.Paragraphs != 2 {
+		t.Errorf("Unexpected counts: %+v", analysis)
+	}
+	if analysis.WordsPerSentence != 4.5 || analysis.ReadingTimeMinutes != 0.1 {
+		t.Errorf("Unexpected averages: %+v", analysis)
+	}
+	expectedTop := []VocabularyTerm{{"budget", 3}, {"reviews", 2}, {"approved", 1}}
+	if len(analysis.TopWords) < 3 || !reflect.DeepEqual(analysis.TopWords[:3], expectedTop) {
+		t.Errorf("TopWords = %+v, want %+v first", analysis.TopWords, expectedTop)
+	}
+	if expected := []Language{{Code: "en", Name: "English", Share: 1}}; !reflect.DeepEqual(analysis.Languages, expected) {
+		t.Errorf("Languages = %+v, want %+v", analysis.Languages, expected)
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	tests := map[string]string{
+		"Le conseil a voté le budget et les membres sont satisfaits de la décision":                    "fr",
+		"Der Vorstand hat den Haushalt beschlossen und die Mitglieder sind mit dem Ergebnis zufrieden": "de",
+		"El consejo aprobó el presupuesto y los miembros están contentos con la decisión":              "es",
+		"Совет утвердил бюджет на следующий год":                                                       "ru",
+		"Рада затвердила бюджет на наступний рік":                                                      "uk",
+		"理事会は来年の予算を承認しました":                                                                             "ja",
+		"董事会批准了明年的预算":                                                                                  "zh",
+		"Alice Bob 2024 Q3 KPI":                                                                        "",
+	}
+	for text, expected := range tests {
+		if code := detectLanguage(textWords(text)); code != expected {
+			t.Errorf("detectLanguage(%q) = %q, want %q", text, code, expected)
+		}
+	}
+}
+
+func TestDetectLanguagesMixed(t *testing.T) {
+	english := strings.Repeat("the report is ready and the team will review it with the board ", 20)
+	french := strings.Repeat("le rapport est prêt et les membres vont le lire avec la direction ", 10)
+
+	languages := detectLanguages(textWords(english + french))
+	if len(languages) != 2 || languages[0].Code != "en" || languages[1].Code != "fr" {
+		t.Errorf("Expected English then French, got %+v", languages)
+	}
+}
+
+func TestAnalyzeDocument(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("Quarterly results are in. Revenue grew."), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	analysis, err := NewManager().AnalyzeDocument(context.Background(), path, true)
+	if err != nil {
+		t.Fatalf("AnalyzeDocument failed: %v", err)
+	}
+	if analysis.FilePath != path || analysis.Words != 6 || analysis.Sentences != 2 || analysis.Text != "Quarterly results are in. Revenue grew." {
+		t.Errorf("Unexpected analysis: %+v", analysis)
+	}
+
+	if analysis, err = NewManager().AnalyzeDocument(context.Background(), path, false); err != nil || analysis.Text != "" {
+		t.Errorf("Expected no text, got %+v, %v", analysis, err)
+	}
+}
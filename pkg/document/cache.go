@@ -0,0 +1,234 @@
+package document
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kevsmith/my-mcp/pkg/shared"
+)
+
+// CachedResult holds what has been extracted from a document so far; fields
+// are filled in as tools request them
+type CachedResult struct {
+	Text       string
+	HasText    bool
+	Properties *DocumentProperties
+	Metadata   map[string]string
+}
+
+// CacheEntry represents the cached results of one document with TTL
+type CacheEntry struct {
+	result   CachedResult
+	expireAt time.Time
+	listNode *list.Element
+}
+
+// ResultCache is an LRU cache with TTL for extraction results, keyed by a
+// hash of the document content so renamed or copied files hit the cache and
+// modified files miss it
+type ResultCache struct {
+	mutex      sync.RWMutex
+	cache      map[string]*CacheEntry
+	lruList    *list.List
+	maxSize    int
+	defaultTTL time.Duration
+	hits       int64 // Guarded by mutex
+	misses     int64
+}
+
+// CacheConfig holds cache configuration parameters
+type CacheConfig struct {
+	MaxSize    int
+	DefaultTTL time.Duration
+}
+
+// GetCacheConfig returns cache configuration from environment variables or defaults
+func GetCacheConfig() CacheConfig {
+	config := CacheConfig{
+		MaxSize:    20,               // Default max 20 documents
+		DefaultTTL: 10 * time.Minute, // Default 10 minute TTL
+	}
+
+	if maxSizeStr := os.Getenv("DOCUMENT_CACHE_MAX_SIZE"); maxSizeStr != "" {
+		if maxSize, err := strconv.Atoi(maxSizeStr); err == nil && maxSize > 0 {
+			config.MaxSize = maxSize
+		}
+	}
+
+	if ttlStr := os.Getenv("DOCUMENT_CACHE_TTL_MINUTES"); ttlStr != "" {
+		if ttlMinutes, err := strconv.Atoi(ttlStr); err == nil && ttlMinutes > 0 {
+			config.DefaultTTL = time.Duration(ttlMinutes) * time.Minute
+		}
+	}
+
+	return config
+}
+
+// NewResultCache creates a new LRU cache with TTL for extraction results
+func NewResultCache(config CacheConfig) *ResultCache {
+	return &ResultCache{
+		cache:      make(map[string]*CacheEntry),
+		lruList:    list.New(),
+		maxSize:    config.MaxSize,
+		defaultTTL: config.DefaultTTL,
+	}
+}
+
+// Get retrieves the results for a key if they exist and haven't expired
+func (rc *ResultCache) Get(key string) (CachedResult, bool) {
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+
+	entry, exists := rc.cache[key]
+	if !exists {
+		rc.misses++
+		return CachedResult{}, false
+	}
+	if time.Now().After(entry.expireAt) {
+		rc.removeEntry(key, entry)
+		rc.misses++
+		return CachedResult{}, false
+	}
+
+	rc.lruList.MoveToFront(entry.listNode)
+	rc.hits++
+	return entry.result, true
+}
+
+// Update applies update to the results stored for a key, creating the entry
+// if needed, and restarts its TTL
+func (rc *ResultCache) Update(key string, update func(*CachedResult)) {
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+
+	// If already exists, update it
+	if entry, exists := rc.cache[key]; exists {
+		update(&entry.result)
+		entry.expireAt = time.Now().Add(rc.defaultTTL)
+		rc.lruList.MoveToFront(entry.listNode)
+		return
+	}
+
+	// Create new entry
+	entry := &CacheEntry{expireAt: time.Now().Add(rc.defaultTTL)}
+	update(&entry.result)
+
+	// Add to front of LRU list
+	entry.listNode = rc.lruList.PushFront(key)
+	rc.cache[key] = entry
+
+	// Evict oldest entries if cache is full
+	for rc.lruList.Len() > rc.maxSize {
+		rc.evictOldest()
+	}
+}
+
+// Clear removes all entries from the cache
+func (rc *ResultCache) Clear() {
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+
+	rc.cache = make(map[string]*CacheEntry)
+	rc.lruList.Init()
+}
+
+// CleanExpired removes all expired entries from the cache
+func (rc *ResultCache) CleanExpired() {
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+
+	now := time.Now()
+	for key, entry := range rc.cache {
+		if now.After(entry.expireAt) {
+			rc.removeEntry(key, entry)
+		}
+	}
+}
+
+// Size returns the current number of cached documents
+func (rc *ResultCache) Size() int {
+	rc.mutex.RLock()
+	defer rc.mutex.RUnlock()
+	return len(rc.cache)
+}
+
+// Stats returns the cache's hit and miss counts and current size
+func (rc *ResultCache) Stats() shared.CacheStats {
+	rc.mutex.RLock()
+	defer rc.mutex.RUnlock()
+	return shared.CacheStats{Hits: rc.hits, Misses: rc.misses, Entries: len(rc.cache)}
+}
+
+// removeEntry removes an entry from both cache map and LRU list
+func (rc *ResultCache) removeEntry(key string, entry *CacheEntry) {
+	delete(rc.cache, key)
+	rc.lruList.Remove(entry.listNode)
+}
+
+// evictOldest removes the least recently used entry
+func (rc *ResultCache) evictOldest() {
+	if oldest := rc.lruList.Back(); oldest != nil {
+		key := oldest.Value.(string)
+		if entry := rc.cache[key]; entry != nil {
+			rc.removeEntry(key, entry)
+		}
+	}
+}
+
+// StartCleanupTicker starts a background goroutine to periodically clean
+// expired entries. Calling the returned function stops it; later calls do
+// nothing.
+func (rc *ResultCache) StartCleanupTicker(interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				rc.CleanExpired()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			ticker.Stop()
+			close(done)
+		})
+	}
+}
+
+// cacheKey identifies a document by the SHA-256 of its content and its
+// detected type, since the same bytes can be extracted differently by type
+// (a .md and a .txt file). Documents of unknown type are not cached.
+func (m *Manager) cacheKey(filePath string) (string, bool) {
+	if m.cache == nil {
+		return "", false
+	}
+	docType := m.detectFileType(filePath)
+	if docType == DocumentTypeUnknown {
+		return "", false
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", false
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", false
+	}
+	return fmt.Sprintf("%s:%d", hex.EncodeToString(hash.Sum(nil)), docType), true
+}
@@ -0,0 +1,149 @@
+package document
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGetDocumentCacheConfig(t *testing.T) {
+	t.Setenv("DOCUMENT_CACHE_MAX_SIZE", "")
+	t.Setenv("DOCUMENT_CACHE_TTL_MINUTES", "")
+	config := GetCacheConfig()
+	if config.MaxSize != 20 || config.DefaultTTL != 10*time.Minute {
+		t.Errorf("Unexpected default config: %+v", config)
+	}
+
+	t.Setenv("DOCUMENT_CACHE_MAX_SIZE", "5")
+	t.Setenv("DOCUMENT_CACHE_TTL_MINUTES", "30")
+	config = GetCacheConfig()
+	if config.MaxSize != 5 || config.DefaultTTL != 30*time.Minute {
+		t.Errorf("Unexpected config from environment: %+v", config)
+	}
+
+	// Invalid values keep the defaults
+	t.Setenv("DOCUMENT_CACHE_MAX_SIZE", "-1")
+	t.Setenv("DOCUMENT_CACHE_TTL_MINUTES", "soon")
+	config = GetCacheConfig()
+	if config.MaxSize != 20 || config.DefaultTTL != 10*time.Minute {
+		t.Errorf("Expected defaults for invalid values, got %+v", config)
+	}
+}
+
+func TestResultCacheLRU(t *testing.T) {
+	cache := NewResultCache(CacheConfig{MaxSize: 2, DefaultTTL: time.Hour})
+	setText := func(text string) func(*CachedResult) {
+		return func(result *CachedResult) { result.Text, result.HasText = text, true }
+	}
+
+	cache.Update("a", setText("A"))
+	cache.Update("b", setText("B"))
+	cache.Get("a") // "b" is now the least recently used
+	cache.Update("c", setText("C"))
+
+	if _, found := cache.Get("b"); found {
+		t.Error("Expected the least recently used entry to be evicted")
+	}
+	if result, found := cache.Get("a"); !found || result.Text != "A" {
+		t.Errorf("Expected entry a to be kept, got %+v, %v", result, found)
+	}
+
+	// Updates merge into the existing entry
+	cache.Update("a", func(result *CachedResult) { result.Metadata = map[string]string{"lines": "1"} })
+	if result, _ := cache.Get("a"); result.Text != "A" || result.Metadata["lines"] != "1" {
+		t.Errorf("Expected merged results, got %+v", result)
+	}
+	if cache.Size() != 2 {
+		t.Errorf("Expected 2 entries, got %d", cache.Size())
+	}
+	if stats := cache.Stats(); stats.Hits != 3 || stats.Misses != 1 {
+		t.Errorf("Expected 3 hits and 1 miss, got %+v", stats)
+	}
+}
+
+func TestResultCacheExpiration(t *testing.T) {
+	cache := NewResultCache(CacheConfig{MaxSize: 10, DefaultTTL: 10 * time.Millisecond})
+	cache.Update("a", func(result *CachedResult) { result.HasText = true })
+	time.Sleep(20 * time.Millisecond)
+
+	if _, found := cache.Get("a"); found {
+		t.Error("Expected the entry to expire")
+	}
+	cache.Update("b", func(result *CachedResult) { result.HasText = true })
+	time.Sleep(20 * time.Millisecond)
+	cache.CleanExpired()
+	if cache.Size() != 0 {
+		t.Errorf("Expected expired entries to be cleaned, got %d", cache.Size())
+	}
+}
+
+func TestExtractTextCachedByContent(t *testing.T) {
+	manager := NewManagerWithConfig(CacheConfig{MaxSize: 10, DefaultTTL: time.Hour})
+	defer manager.Close()
+
+	calls := 0
+	manager.RegisterFormat(Format{Type: DocumentTypeText, Name: "text", Extensions: []string{".txt"}, Extract: func(_ context.Context, filePath string) (string, error) {
+		calls++
+		content, err := os.ReadFile(filePath)
+		return string(content), err
+	}})
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(path, []byte("first draft"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for range 2 {
+		if text, err := manager.ExtractText(context.Background(), path); err != nil || text != "first draft" {
+			t.Fatalf("ExtractText = %q, %v", text, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("Expected one extraction for repeated requests, got %d", calls)
+	}
+
+	// A copy has the same content and hits the cache
+	copyPath := filepath.Join(dir, "copy.txt")
+	if err := os.WriteFile(copyPath, []byte("first draft"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	manager.ExtractText(context.Background(), copyPath)
+	if calls != 1 {
+		t.Errorf("Expected a copy to hit the cache, got %d extractions", calls)
+	}
+
+	// Changed content misses it
+	if err := os.WriteFile(path, []byte("second draft"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if text, _ := manager.ExtractText(context.Background(), path); text != "second draft" || calls != 2 {
+		t.Errorf("Expected a fresh extraction after a change, got %q after %d extractions", text, calls)
+	}
+}
+
+func TestGetDocumentInfoCached(t *testing.T) {
+	manager := NewManagerWithConfig(CacheConfig{MaxSize: 10, DefaultTTL: time.Hour})
+	defer manager.Close()
+
+	path := filepath.Join(t.TempDir(), "notes.md")
+	if err := os.WriteFile(path, []byte("---\ntitle: Plan\n---\nSome words here"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := manager.GetDocumentInfo(context.Background(), path)
+	if err != nil {
+		t.Fatalf("GetDocumentInfo failed: %v", err)
+	}
+	first.Metadata["title"] = "changed by caller"
+
+	second, err := manager.GetDocumentInfo(context.Background(), path)
+	if err != nil {
+		t.Fatalf("GetDocumentInfo failed: %v", err)
+	}
+	if second.Title != "Plan" || second.Metadata["title"] != "Plan" || second.WordCount != first.WordCount {
+		t.Errorf("Unexpected cached info: %+v", second)
+	}
+}
@@ -0,0 +1,126 @@
+package document
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// charsPerToken approximates tokenizer output for English prose; max_tokens
+// limits are converted to characters with it
+const charsPerToken = 4
+
+// ChunkOptions controls how extracted text is split into chunks. Exactly one
+// of MaxChars and MaxTokens is set; Overlap is in the same unit.
+type ChunkOptions struct {
+	MaxChars  int
+	MaxTokens int
+	Overlap   int
+}
+
+// TextChunk is one piece of a document's text. Start and End are character
+// offsets into the full extracted text.
+type TextChunk struct {
+	Index           int    `json:"index"`
+	Total           int    `json:"total"`
+	Start           int    `json:"start"`
+	End             int    `json:"end"`
+	EstimatedTokens int    `json:"estimated_tokens"`
+	Text            string `json:"text"`
+}
+
+// ExtractChunks extracts a document's text and splits it into overlapping chunks
+func (m *Manager) ExtractChunks(ctx context.Context, filePath string, options ChunkOptions) ([]TextChunk, error) {
+	size, overlap, err := options.characterLimits()
+	if err != nil {
+		return nil, err
+	}
+
+	text, err := m.ExtractText(ctx, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return chunkText(text, size, overlap), nil
+}
+
+// characterLimits validates the options and converts them to characters
+func (o ChunkOptions) characterLimits() (int, int, error) {
+	switch {
+	case o.MaxChars > 0 && o.MaxTokens > 0:
+		return 0, 0, fmt.Errorf("specify either max_chars or max_tokens, not both")
+	case o.MaxChars < 0 || o.MaxTokens < 0 || o.Overlap < 0:
+		return 0, 0, fmt.Errorf("chunk sizes and overlap must not be negative")
+	}
+
+	size, overlap := o.MaxChars, o.Overlap
+	if o.MaxTokens > 0 {
+		size, overlap = o.MaxTokens*charsPerToken, o.Overlap*charsPerToken
+	}
+	if size == 0 {
+		return 0, 0, fmt.Errorf("max_chars or max_tokens is required")
+	}
+	if overlap >= size {
+		return 0, 0, fmt.Errorf("overlap must be smaller than the chunk size")
+	}
+	return size, overlap, nil
+}
+
+// chunkText splits text into chunks of at most size characters, each starting
+// overlap characters before the previous one ended. Chunks end at a paragraph,
+// sentence or word boundary when one falls in their second half.
+func chunkText(text string, size, overlap int) []TextChunk {
+	runes := []rune(text)
+	var chunks []TextChunk
+
+	for start := 0; start < len(runes); {
+		end := min(start+size, len(runes))
+		if end < len(runes) {
+			end = chunkBoundary(runes, start, end)
+		}
+
+		chunkRunes := runes[start:end]
+		chunks = append(chunks, TextChunk{
+			Index:           len(chunks),
+			Start:           start,
+			End:             end,
+			EstimatedTokens: (len(chunkRunes) + charsPerToken - 1) / charsPerToken,
+			Text:            strings.TrimSpace(string(chunkRunes)),
+		})
+		if end == len(runes) {
+			break
+		}
+
+		// Step back by the overlap, then forward to the start of a word, but
+		// always make progress
+		next := max(end-overlap, start+1)
+		for next < end && next > 0 && !unicode.IsSpace(runes[next-1]) {
+			next++
+		}
+		for next < len(runes) && unicode.IsSpace(runes[next]) {
+			next++
+		}
+		start = next
+	}
+
+	for i := range chunks {
+		chunks[i].Total = len(chunks)
+	}
+	return chunks
+}
+
+// chunkBoundary picks where a chunk ending at or before limit should end,
+// preferring the latest paragraph break, then sentence end, then space in the
+// second half of the chunk
+func chunkBoundary(runes []rune, start, limit int) int {
+	floor := start + (limit-start)/2
+	window := string(runes[floor:limit])
+
+	for _, separator := range []string{"\n\n", ". ", "! ", "? ", "\n", " "} {
+		if index := strings.LastIndex(window, separator); index >= 0 {
+			return floor + len([]rune(window[:index])) + len([]rune(separator))
+		}
+	}
+	return limit // No boundary: cut mid-word
+}
@@ -0,0 +1,98 @@
+package document
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestChunkText(t *testing.T) {
+	text := "First paragraph here.\n\nSecond paragraph is a bit longer. It has two sentences.\n\nThird."
+
+	chunks := chunkText(text, 40, 0)
+	expected := []string{"First paragraph here.", "Second paragraph is a bit longer.", "It has two sentences.\n\nThird."}
+	if len(chunks) != len(expected) {
+		t.Fatalf("Expected %d chunks, got %d: %+v", len(expected), len(chunks), chunks)
+	}
+	for i, chunk := range chunks {
+		if chunk.Text != expected[i] || chunk.Index != i || chunk.Total != len(expected) {
+			t.Errorf("Chunk %d = %+v, want text %q", i, chunk, expected[i])
+		}
+		if chunk.End-chunk.Start > 40 {
+			t.Errorf("Chunk %d exceeds the size limit: %+v", i, chunk)
+		}
+	}
+
+	// Offsets are in characters, not bytes
+	if got := string([]rune(text)[chunks[1].Start:chunks[1].End]); strings.TrimSpace(got) != chunks[1].Text {
+		t.Errorf("Offsets %d-%d don't match the chunk text: %q", chunks[1].Start, chunks[1].End, got)
+	}
+}
+
+func TestChunkTextOverlap(t *testing.T) {
+	words := strings.Fields("alpha beta gamma delta epsilon zeta eta theta iota kappa lambda")
+	text := strings.Join(words, " ")
+
+	chunks := chunkText(text, 24, 10)
+	for i := 1; i < len(chunks); i++ {
+		previous := strings.Fields(chunks[i-1].Text)
+		if first := strings.Fields(chunks[i].Text)[0]; !strings.Contains(chunks[i-1].Text, first) {
+			t.Errorf("Chunk %d should start inside chunk %d (%v), starts with %q", i, i-1, previous, first)
+		}
+	}
+	if last := chunks[len(chunks)-1].Text; !strings.HasSuffix(last, "lambda") {
+		t.Errorf("Last chunk should reach the end of the text, got %q", last)
+	}
+
+	// Text without any boundary is cut mid-word and still makes progress
+	chunks = chunkText(strings.Repeat("é", 25), 10, 9)
+	if len(chunks) == 0 || chunks[len(chunks)-1].End != 25 {
+		t.Errorf("Unexpected chunks for unbroken text: %+v", chunks)
+	}
+}
+
+func TestChunkOptions(t *testing.T) {
+	tests := []struct {
+		options  ChunkOptions
+		size     int
+		overlap  int
+		errorMsg string
+	}{
+		{ChunkOptions{MaxChars: 500, Overlap: 50}, 500, 50, ""},
+		{ChunkOptions{MaxTokens: 100, Overlap: 10}, 400, 40, ""},
+		{ChunkOptions{MaxChars: 100, MaxTokens: 100}, 0, 0, "not both"},
+		{ChunkOptions{MaxChars: 100, Overlap: 100}, 0, 0, "smaller than the chunk size"},
+		{ChunkOptions{MaxChars: -1}, 0, 0, "must not be negative"},
+		{ChunkOptions{Overlap: 5}, 0, 0, "is required"},
+	}
+
+	for _, test := range tests {
+		size, overlap, err := test.options.characterLimits()
+		if test.errorMsg != "" {
+			if err == nil || !strings.Contains(err.Error(), test.errorMsg) {
+				t.Errorf("%+v: error = %v, want %q", test.options, err, test.errorMsg)
+			}
+			continue
+		}
+		if err != nil || size != test.size || overlap != test.overlap {
+			t.Errorf("%+v: got %d, %d, %v", test.options, size, overlap, err)
+		}
+	}
+}
+
+func TestExtractChunks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.md")
+	if err := os.WriteFile(path, []byte(strings.Repeat("Lorem ipsum dolor sit amet. ", 20)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	chunks, err := NewManager().ExtractChunks(context.Background(), path, ChunkOptions{MaxTokens: 30, Overlap: 5})
+	if err != nil {
+		t.Fatalf("ExtractChunks failed: %v", err)
+	}
+	if len(chunks) < 4 || chunks[0].Total != len(chunks) || chunks[0].EstimatedTokens > 30 {
+		t.Errorf("Unexpected chunks: %+v", chunks)
+	}
+}
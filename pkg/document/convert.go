@@ -0,0 +1,708 @@
+package document
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// ConvertToMarkdown converts a document to Markdown, keeping the structure
+// that extract_text flattens: headings, lists, tables, hyperlinks and
+// emphasis from DOCX and HTML, and bookmark headings for PDF. Markdown files
+// are returned without their front matter.
+func (m *Manager) ConvertToMarkdown(ctx context.Context, filePath string) (string, error) {
+	var convert func(context.Context, string) (string, error)
+	switch m.detectFileType(filePath) {
+	case DocumentTypeDOCX:
+		convert = docxMarkdown
+	case DocumentTypeHTML:
+		convert = htmlMarkdown
+	case DocumentTypePDF:
+		convert = m.pdfMarkdown
+	case DocumentTypeMarkdown:
+		convert = m.extractMarkdownText
+	default:
+		return "", fmt.Errorf("markdown conversion supports .docx, .html, .pdf and .md files only: %s", filePath)
+	}
+
+	if err := m.checkFileSize(filePath); err != nil {
+		return "", err
+	}
+	return withTimeout(ctx, m.limits.Timeout, func(ctx context.Context) (string, error) {
+		return convert(ctx, filePath)
+	})
+}
+
+// markdownDocument collects Markdown blocks, separating them with blank
+// lines except between items of the same list
+type markdownDocument struct {
+	text     strings.Builder
+	lastList bool
+}
+
+func (d *markdownDocument) add(block string, listItem bool) {
+	if strings.TrimSpace(block) == "" {
+		return
+	}
+	if d.text.Len() > 0 {
+		if listItem && d.lastList {
+			d.text.WriteString("\n")
+		} else {
+			d.text.WriteString("\n\n")
+		}
+	}
+	d.text.WriteString(block)
+	d.lastList = listItem
+}
+
+func (d *markdownDocument) String() string {
+	return d.text.String()
+}
+
+// markdownHeading returns a heading line; levels beyond 6 are clamped
+func markdownHeading(level int, text string) string {
+	if text = singleLine(text); text == "" {
+		return ""
+	}
+	return strings.Repeat("#", min(max(level, 1), 6)) + " " + text
+}
+
+// markdownTable renders rows as a pipe table with the first row as header
+func markdownTable(rows [][]string) string {
+	columns := 0
+	for _, row := range rows {
+		columns = max(columns, len(row))
+	}
+	if columns == 0 {
+		return ""
+	}
+
+	var table strings.Builder
+	writeRow := func(row []string) {
+		table.WriteString("|")
+		for i := range columns {
+			cell := ""
+			if i < len(row) {
+				cell = strings.ReplaceAll(singleLine(row[i]), "|", `\|`)
+			}
+			table.WriteString(" " + cell + " |")
+		}
+		table.WriteString("\n")
+	}
+	writeRow(rows[0])
+	table.WriteString("|" + strings.Repeat(" --- |", columns) + "\n")
+	for _, row := range rows[1:] {
+		writeRow(row)
+	}
+	return strings.TrimSuffix(table.String(), "\n")
+}
+
+// wrapInline surrounds text with Markdown markup, keeping leading and
+// trailing spaces outside it since "** bold**" is not emphasis
+func wrapInline(text, open, close string) string {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+	start := strings.Index(text, trimmed)
+	return text[:start] + open + trimmed + close + text[start+len(trimmed):]
+}
+
+// singleLine collapses all whitespace, including line breaks, to single spaces
+func singleLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// tidyParagraph collapses spaces within each line and drops empty lines
+func tidyParagraph(text string) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = singleLine(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// markdownSpan is a piece of run text with its formatting
+type markdownSpan struct {
+	text         string
+	bold, italic bool
+	link         string
+}
+
+// renderSpans writes runs as inline Markdown, merging neighbouring runs with
+// the same formatting so that a word split across runs isn't broken up by
+// markup
+func renderSpans(spans []markdownSpan) string {
+	var merged []markdownSpan
+	for _, span := range spans {
+		if n := len(merged); n > 0 && merged[n-1].bold == span.bold && merged[n-1].italic == span.italic && merged[n-1].link == span.link {
+			merged[n-1].text += span.text
+			continue
+		}
+		merged = append(merged, span)
+	}
+
+	var out strings.Builder
+	for i := 0; i < len(merged); {
+		link := merged[i].link
+		var inner strings.Builder
+		for ; i < len(merged) && merged[i].link == link; i++ {
+			marker := ""
+			switch span := merged[i]; {
+			case span.bold && span.italic:
+				marker = "***"
+			case span.bold:
+				marker = "**"
+			case span.italic:
+				marker = "*"
+			}
+			inner.WriteString(wrapInline(merged[i].text, marker, marker))
+		}
+		if link != "" {
+			out.WriteString(wrapInline(inner.String(), "[", "]("+link+")"))
+		} else {
+			out.WriteString(inner.String())
+		}
+	}
+	return out.String()
+}
+
+// docxMarkdown converts the body of a Word document. Headings come from
+// paragraph styles and outline levels as in get_outline, list items from
+// paragraph numbering, emphasis from bold and italic runs (or the Strong and
+// Emphasis character styles) and links from external hyperlinks. Nested
+// tables are flattened into the cell that holds them.
+func docxMarkdown(ctx context.Context, filePath string) (string, error) {
+	reader, err := zip.OpenReader(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open DOCX file: %w", err)
+	}
+	defer reader.Close()
+
+	files := make(map[string]*zip.File, len(reader.File))
+	for _, f := range reader.File {
+		files[f.Name] = f
+	}
+	if files["word/document.xml"] == nil {
+		return "", fmt.Errorf("failed to read DOCX file: word/document.xml not found")
+	}
+	rc, err := files["word/document.xml"].Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to read DOCX file: %w", err)
+	}
+	defer rc.Close()
+
+	styleLevels := docxStyleLevels(files["word/styles.xml"])
+	listFormats := docxListFormats(files["word/numbering.xml"])
+	links := docxHyperlinks(files)
+
+	type openTable struct {
+		rows   [][]string
+		cell   []string // Paragraphs of the current cell
+		span   int
+		inCell bool
+	}
+	var stack []*openTable
+	var doc markdownDocument
+
+	// Paragraph and run state
+	depth, level, listLevel := 0, 0, 0
+	listID := ""
+	var spans []markdownSpan
+	inRun, inText, bold, italic := false, false, false, false
+	link := ""
+
+	decoder := xml.NewDecoder(rc)
+	decoder.Strict = false
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		var top *openTable
+		if len(stack) > 0 {
+			top = stack[len(stack)-1]
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "p":
+				if depth++; depth == 1 {
+					level, listLevel, listID, spans = 0, 0, "", nil
+				}
+			case "pStyle":
+				if depth == 1 && level == 0 {
+					level = styleLevels[xmlAttr(t, "val")]
+					if match := headingStylePattern.FindStringSubmatch(xmlAttr(t, "val")); level == 0 && match != nil {
+						level = atoiOrZero(match[1])
+					}
+				}
+			case "outlineLvl":
+				// Outline levels are 0-based; 9 is body text
+				if n := atoiOrZero(xmlAttr(t, "val")); depth == 1 && n < 9 {
+					level = n + 1
+				}
+			case "numId":
+				if depth == 1 {
+					listID = xmlAttr(t, "val")
+				}
+			case "ilvl":
+				if depth == 1 {
+					listLevel = atoiOrZero(xmlAttr(t, "val"))
+				}
+			case "r":
+				inRun, bold, italic = true, false, false
+			case "b":
+				if inRun {
+					bold = xmlOn(t)
+				}
+			case "i":
+				if inRun {
+					italic = xmlOn(t)
+				}
+			case "rStyle":
+				switch xmlAttr(t, "val") {
+				case "Strong":
+					bold = bold || inRun
+				case "Emphasis":
+					italic = italic || inRun
+				}
+			case "hyperlink":
+				link = links[xmlAttr(t, "id")]
+			case "t":
+				inText = true
+			case "tab", "br", "cr":
+				if depth > 0 {
+					spans = append(spans, markdownSpan{text: " ", bold: bold, italic: italic, link: link})
+				}
+			case "tbl":
+				stack = append(stack, &openTable{})
+			case "tr":
+				if top != nil {
+					top.rows = append(top.rows, nil)
+				}
+			case "tc":
+				if top != nil {
+					top.cell, top.span, top.inCell = nil, 1, true
+				}
+			case "gridSpan":
+				if top != nil {
+					top.span = max(1, atoiOrZero(xmlAttr(t, "val")))
+				}
+			}
+		case xml.CharData:
+			if inText && depth > 0 {
+				spans = append(spans, markdownSpan{text: string(t), bold: bold, italic: italic, link: link})
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "r":
+				inRun = false
+			case "hyperlink":
+				link = ""
+			case "p":
+				if depth--; depth != 0 {
+					depth = max(depth, 0)
+					continue
+				}
+				if err := ctx.Err(); err != nil {
+					return "", err
+				}
+				text := renderSpans(spans)
+				switch {
+				case top != nil && top.inCell:
+					if text = singleLine(text); text != "" {
+						top.cell = append(top.cell, text)
+					}
+				case level > 0:
+					doc.add(markdownHeading(level, text), false)
+				case listID != "" && listID != "0":
+					if text = singleLine(text); text != "" {
+						doc.add(docxListPrefix(listFormats[listID], listLevel)+text, true)
+					}
+				default:
+					doc.add(tidyParagraph(text), false)
+				}
+			case "tc":
+				if top != nil && len(top.rows) > 0 {
+					row := &top.rows[len(top.rows)-1]
+					*row = append(*row, strings.Join(top.cell, " "))
+					for i := 1; i < top.span; i++ {
+						*row = append(*row, "")
+					}
+					top.inCell = false
+				}
+			case "tbl":
+				if top == nil {
+					continue
+				}
+				stack = stack[:len(stack)-1]
+				if len(stack) == 0 {
+					doc.add(markdownTable(top.rows), false)
+					continue
+				}
+				// A nested table becomes text in the outer cell
+				var cells []string
+				for _, row := range top.rows {
+					for _, cell := range row {
+						if cell != "" {
+							cells = append(cells, cell)
+						}
+					}
+				}
+				if parent := stack[len(stack)-1]; parent.inCell && len(cells) > 0 {
+					parent.cell = append(parent.cell, strings.Join(cells, " "))
+				}
+			}
+		}
+	}
+
+	return doc.String(), nil
+}
+
+// xmlOn reports whether a toggle property such as <w:b/> or <w:i w:val="0"/> is on
+func xmlOn(element xml.StartElement) bool {
+	switch xmlAttr(element, "val") {
+	case "0", "false", "off", "none":
+		return false
+	}
+	return true
+}
+
+// docxListPrefix returns the indented marker of a list item. Nested items
+// are indented by the width of their parents' markers.
+func docxListPrefix(ordered map[int]bool, level int) string {
+	marker := func(level int) string {
+		if ordered[level] {
+			return "1. "
+		}
+		return "- "
+	}
+	indent := 0
+	for outer := range min(level, 8) {
+		indent += len(marker(outer))
+	}
+	return strings.Repeat(" ", indent) + marker(level)
+}
+
+// docxListFormats maps numbering IDs to whether each of their levels is
+// numbered (true) or bulleted, from word/numbering.xml
+func docxListFormats(file *zip.File) map[string]map[int]bool {
+	var numbering struct {
+		Abstract []struct {
+			ID     string `xml:"abstractNumId,attr"`
+			Levels []struct {
+				Level  string `xml:"ilvl,attr"`
+				Format xmlVal `xml:"numFmt"`
+			} `xml:"lvl"`
+		} `xml:"abstractNum"`
+		Nums []struct {
+			ID       string `xml:"numId,attr"`
+			Abstract xmlVal `xml:"abstractNumId"`
+		} `xml:"num"`
+	}
+	formats := make(map[string]map[int]bool)
+	if decodeZipXML(file, &numbering) != nil {
+		return formats
+	}
+
+	abstract := make(map[string]map[int]bool, len(numbering.Abstract))
+	for _, definition := range numbering.Abstract {
+		levels := make(map[int]bool, len(definition.Levels))
+		for _, level := range definition.Levels {
+			format := level.Format.Val
+			levels[atoiOrZero(level.Level)] = format != "" && format != "bullet" && format != "none"
+		}
+		abstract[definition.ID] = levels
+	}
+	for _, num := range numbering.Nums {
+		formats[num.ID] = abstract[num.Abstract.Val]
+	}
+	return formats
+}
+
+// docxHyperlinks maps the relationship IDs of the document's external
+// hyperlinks to their URLs
+func docxHyperlinks(files map[string]*zip.File) map[string]string {
+	var relationships struct {
+		Relationships []struct {
+			ID         string `xml:"Id,attr"`
+			Type       string `xml:"Type,attr"`
+			Target     string `xml:"Target,attr"`
+			TargetMode string `xml:"TargetMode,attr"`
+		} `xml:"Relationship"`
+	}
+	links := make(map[string]string)
+	if decodeZipXML(files["word/_rels/document.xml.rels"], &relationships) != nil {
+		return links
+	}
+	for _, rel := range relationships.Relationships {
+		if rel.TargetMode == "External" && strings.HasSuffix(rel.Type, "/hyperlink") {
+			links[rel.ID] = rel.Target
+		}
+	}
+	return links
+}
+
+// htmlMarkdownBlocks are the elements converted as blocks of their own;
+// everything else is inline content of the enclosing block
+var htmlMarkdownBlocks = map[atom.Atom]bool{
+	atom.Html: true, atom.Body: true, atom.P: true, atom.Div: true, atom.Section: true, atom.Article: true,
+	atom.Main: true, atom.Header: true, atom.Footer: true, atom.Nav: true, atom.Aside: true, atom.Figure: true,
+	atom.Ul: true, atom.Ol: true, atom.Table: true, atom.Pre: true, atom.Blockquote: true, atom.Hr: true,
+	atom.Dl: true, atom.Dt: true, atom.Dd: true, atom.Form: true, atom.Details: true, atom.Address: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+}
+
+// htmlMarkdown converts the body of an HTML document
+func htmlMarkdown(_ context.Context, filePath string) (string, error) {
+	content, err := readTextFile(filePath, "HTML")
+	if err != nil {
+		return "", err
+	}
+	root, err := html.Parse(strings.NewReader(content))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	var doc markdownDocument
+	htmlBlocks(&doc, root)
+	return controlPattern.ReplaceAllString(doc.String(), ""), nil
+}
+
+// htmlBlocks converts the children of a container element, gathering runs of
+// inline content into paragraphs
+func htmlBlocks(doc *markdownDocument, node *html.Node) {
+	var paragraph strings.Builder
+	flush := func() {
+		doc.add(tidyParagraph(paragraph.String()), false)
+		paragraph.Reset()
+	}
+
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && htmlMarkdownBlocks[child.DataAtom] {
+			flush()
+			htmlBlock(doc, child)
+			continue
+		}
+		paragraph.WriteString(htmlInline(child))
+	}
+	flush()
+}
+
+// htmlBlock converts one block element
+func htmlBlock(doc *markdownDocument, node *html.Node) {
+	switch node.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		doc.add(markdownHeading(htmlHeadingLevels[node.DataAtom], htmlInline(node)), false)
+	case atom.Ul, atom.Ol:
+		doc.add(strings.Join(htmlListLines(node, ""), "\n"), false)
+	case atom.Table:
+		doc.add(markdownTable(htmlTableRows(node)), false)
+	case atom.Pre:
+		if code := strings.Trim(htmlNodeText(node), "\n"); strings.TrimSpace(code) != "" {
+			doc.add("```\n"+code+"\n```", false)
+		}
+	case atom.Blockquote:
+		var quote markdownDocument
+		htmlBlocks(&quote, node)
+		if quote.text.Len() > 0 {
+			lines := strings.Split(quote.String(), "\n")
+			for i, line := range lines {
+				lines[i] = strings.TrimRight("> "+line, " ")
+			}
+			doc.add(strings.Join(lines, "\n"), false)
+		}
+	case atom.Hr:
+		doc.add("---", false)
+	default:
+		htmlBlocks(doc, node)
+	}
+}
+
+// htmlInline returns the inline Markdown of a node: text with emphasis, code
+// spans, links and images. Line breaks are kept as newlines.
+func htmlInline(node *html.Node) string {
+	switch node.Type {
+	case html.TextNode:
+		return whitespacePattern.ReplaceAllString(node.Data, " ")
+	case html.ElementNode:
+	default:
+		return ""
+	}
+	if htmlSkippedElements[node.DataAtom] {
+		return ""
+	}
+
+	var inner strings.Builder
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		inner.WriteString(htmlInline(child))
+	}
+	text := inner.String()
+
+	switch node.DataAtom {
+	case atom.Br:
+		return "\n"
+	case atom.Img:
+		if src := htmlAttr(node, "src"); src != "" {
+			return "![" + singleLine(htmlAttr(node, "alt")) + "](" + src + ")"
+		}
+		return ""
+	case atom.Strong, atom.B:
+		return wrapInline(text, "**", "**")
+	case atom.Em, atom.I:
+		return wrapInline(text, "*", "*")
+	case atom.S, atom.Del, atom.Strike:
+		return wrapInline(text, "~~", "~~")
+	case atom.Code, atom.Kbd, atom.Samp:
+		return wrapInline(text, "`", "`")
+	case atom.A:
+		href := htmlAttr(node, "href")
+		lower := strings.ToLower(href)
+		if href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(lower, "javascript:") {
+			return text
+		}
+		return wrapInline(text, "[", "]("+href+")")
+	case atom.Td, atom.Th:
+		return text + " "
+	}
+	if htmlMarkdownBlocks[node.DataAtom] || node.DataAtom == atom.Li || node.DataAtom == atom.Tr {
+		return "\n" + text + "\n"
+	}
+	return text
+}
+
+// htmlListLines returns the items of a list, each prefixed with its marker
+// and nested lists indented by the width of their parent's marker
+func htmlListLines(list *html.Node, indent string) []string {
+	number := 1
+	if start, err := strconv.Atoi(htmlAttr(list, "start")); err == nil {
+		number = start
+	}
+
+	var lines []string
+	for item := list.FirstChild; item != nil; item = item.NextSibling {
+		if item.Type != html.ElementNode {
+			continue
+		}
+		if item.DataAtom == atom.Ul || item.DataAtom == atom.Ol {
+			lines = append(lines, htmlListLines(item, indent+"  ")...)
+			continue
+		}
+
+		marker := "- "
+		if list.DataAtom == atom.Ol {
+			marker = strconv.Itoa(number) + ". "
+			number++
+		}
+		var text strings.Builder
+		var nested []string
+		for child := item.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type == html.ElementNode && (child.DataAtom == atom.Ul || child.DataAtom == atom.Ol) {
+				nested = append(nested, htmlListLines(child, indent+strings.Repeat(" ", len(marker)))...)
+				continue
+			}
+			text.WriteString(htmlInline(child))
+		}
+		lines = append(lines, indent+marker+singleLine(text.String()))
+		lines = append(lines, nested...)
+	}
+	return lines
+}
+
+// htmlTableRows returns the cell text of a table's rows; cells spanning
+// several columns are followed by empty cells
+func htmlTableRows(table *html.Node) [][]string {
+	var rows [][]string
+	var collect func(node *html.Node)
+	collect = func(node *html.Node) {
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			switch child.DataAtom {
+			case atom.Thead, atom.Tbody, atom.Tfoot:
+				collect(child)
+			case atom.Tr:
+				var row []string
+				for cell := child.FirstChild; cell != nil; cell = cell.NextSibling {
+					if cell.DataAtom != atom.Td && cell.DataAtom != atom.Th {
+						continue
+					}
+					row = append(row, singleLine(htmlInline(cell)))
+					for span, _ := strconv.Atoi(htmlAttr(cell, "colspan")); span > 1 && len(row) < 1000; span-- {
+						row = append(row, "")
+					}
+				}
+				rows = append(rows, row)
+			}
+		}
+	}
+	collect(table)
+	return rows
+}
+
+// htmlNodeText returns the raw text of a node, keeping its whitespace
+func htmlNodeText(node *html.Node) string {
+	if node.Type == html.TextNode {
+		return node.Data
+	}
+	var text strings.Builder
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if child.DataAtom == atom.Br {
+			text.WriteString("\n")
+		}
+		text.WriteString(htmlNodeText(child))
+	}
+	return text.String()
+}
+
+// htmlAttr returns the value of an attribute of an element
+func htmlAttr(node *html.Node, name string) string {
+	for _, attr := range node.Attr {
+		if attr.Key == name {
+			return strings.TrimSpace(attr.Val)
+		}
+	}
+	return ""
+}
+
+// pdfMarkdown converts a PDF page by page, placing each bookmark as a heading
+// before the text of the page it points to. PDF text carries no reliable
+// structure of its own, so documents without bookmarks become one paragraph
+// per page.
+func (m *Manager) pdfMarkdown(ctx context.Context, filePath string) (string, error) {
+	extraction, err := m.extractPDFPages(ctx, filePath, "")
+	if err != nil {
+		return "", err
+	}
+	// Bookmarks are optional; a damaged outline still leaves the text
+	entries, _ := pdfOutline(filePath)
+
+	// Bookmarks without a resolvable page stay with the bookmark before them
+	headings := make(map[int][]OutlineEntry)
+	last := 1
+	for _, entry := range entries {
+		if entry.Page > 0 {
+			last = entry.Page
+		}
+		headings[last] = append(headings[last], entry)
+	}
+
+	var doc markdownDocument
+	for _, page := range extraction.Pages {
+		for _, entry := range headings[page.Number] {
+			doc.add(markdownHeading(entry.Level, entry.Title), false)
+		}
+		doc.add(page.Text, false)
+	}
+	return doc.String(), nil
+}
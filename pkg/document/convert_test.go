@@ -0,0 +1,119 @@
+package document
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDocxToMarkdown(t *testing.T) {
+	const namespaces = `xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"`
+	run := func(properties, text string) string {
+		if properties != "" {
+			properties = `<w:rPr>` + properties + `</w:rPr>`
+		}
+		return `<w:r>` + properties + `<w:t xml:space="preserve">` + text + `</w:t></w:r>`
+	}
+	listItem := func(numID, level, text string) string {
+		return `<w:p><w:pPr><w:numPr><w:ilvl w:val="` + level + `"/><w:numId w:val="` + numID + `"/></w:numPr></w:pPr>` + run("", text) + `</w:p>`
+	}
+	cell := func(text string) string {
+		return `<w:tc><w:p>` + run("", text) + `</w:p></w:tc>`
+	}
+
+	path := filepath.Join(t.TempDir(), "report.docx")
+	writeTestZip(t, path,
+		[2]string{"word/numbering.xml", `<w:numbering ` + namespaces + `>` +
+			`<w:abstractNum w:abstractNumId="0"><w:lvl w:ilvl="0"><w:numFmt w:val="bullet"/></w:lvl><w:lvl w:ilvl="1"><w:numFmt w:val="decimal"/></w:lvl></w:abstractNum>` +
+			`<w:abstractNum w:abstractNumId="1"><w:lvl w:ilvl="0"><w:numFmt w:val="decimal"/></w:lvl></w:abstractNum>` +
+			`<w:num w:numId="1"><w:abstractNumId w:val="0"/></w:num><w:num w:numId="2"><w:abstractNumId w:val="1"/></w:num>` +
+			`</w:numbering>`},
+		[2]string{"word/_rels/document.xml.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId5" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink" Target="https://example.com/plan" TargetMode="External"/>` +
+			`</Relationships>`},
+		[2]string{"word/document.xml", `<w:document ` + namespaces + `><w:body>` +
+			`<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr>` + run("", "Budget plan") + `</w:p>` +
+			`<w:p>` + run("", "The ") + run("<w:b/>", "total ") + run("<w:b/>", "cost") + run("", " is ") + run("<w:i/>", "estimated") +
+			run("<w:b w:val=\"0\"/>", ", see ") + `<w:hyperlink r:id="rId5">` + run("", "the plan") + `</w:hyperlink>` + run("", ".") + `</w:p>` +
+			listItem("1", "0", "Staff") + listItem("1", "1", "Contractors") + listItem("2", "0", "Approve") +
+			`<w:tbl><w:tr>` + cell("Item") + cell("Cost") + `</w:tr><w:tr>` + cell("Travel") + cell("1|200") + `</w:tr></w:tbl>` +
+			`</w:body></w:document>`},
+	)
+
+	markdown, err := NewManager().ConvertToMarkdown(context.Background(), path)
+	if err != nil {
+		t.Fatalf("ConvertToMarkdown failed: %v", err)
+	}
+	expected := "# Budget plan\n\n" +
+		"The **total cost** is *estimated*, see [the plan](https://example.com/plan).\n\n" +
+		"- Staff\n  1. Contractors\n1. Approve\n\n" +
+		"| Item | Cost |\n| --- | --- |\n| Travel | 1\\|200 |"
+	if markdown != expected {
+		t.Errorf("ConvertToMarkdown =\n%s\nwant\n%s", markdown, expected)
+	}
+}
+
+func TestHTMLToMarkdown(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "page.html")
+	content := `<html><head><title>Ignored</title><style>p { color: red }</style></head><body>
+<h1>Release notes</h1>
+<p>Version <strong>2.0</strong> adds <em>offline mode</em>. Read the <a href="https://example.com/docs">docs</a> or <a href="#top">go up</a>.</p>
+<ul><li>Faster sync<ul><li>Delta uploads</li></ul></li><li><code>--dry-run</code> flag</li></ul>
+<ol start="3"><li>Install</li><li>Restart</li></ol>
+<table><thead><tr><th>Platform</th><th>Status</th></tr></thead><tbody><tr><td colspan="2">All supported</td></tr></tbody></table>
+<pre>go build
+go test</pre>
+<blockquote><p>Thanks to all contributors.</p></blockquote>
+</body></html>`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	markdown, err := NewManager().ConvertToMarkdown(context.Background(), path)
+	if err != nil {
+		t.Fatalf("ConvertToMarkdown failed: %v", err)
+	}
+	expected := "# Release notes\n\n" +
+		"Version **2.0** adds *offline mode*. Read the [docs](https://example.com/docs) or go up.\n\n" +
+		"- Faster sync\n  - Delta uploads\n- `--dry-run` flag\n\n" +
+		"3. Install\n4. Restart\n\n" +
+		"| Platform | Status |\n| --- | --- |\n| All supported |  |\n\n" +
+		"```\ngo build\ngo test\n```\n\n" +
+		"> Thanks to all contributors."
+	if markdown != expected {
+		t.Errorf("ConvertToMarkdown =\n%s\nwant\n%s", markdown, expected)
+	}
+}
+
+func TestPDFToMarkdown(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.pdf")
+	// Pages are objects 4 and 6; the outline starts at object 8
+	writeTestPDFObjects(t, path, testPDF{
+		Catalog: "/Outlines 8 0 R",
+		Extra: []string{
+			"<< /Type /Outlines /First 9 0 R /Last 10 0 R /Count 2 >>",
+			"<< /Title (Summary) /Parent 8 0 R /Next 10 0 R /Dest [4 0 R /Fit] >>",
+			"<< /Title (Findings) /Parent 8 0 R /Prev 9 0 R /Dest [6 0 R /Fit] >>",
+		},
+	}, "BT /F1 12 Tf 72 720 Td (Revenue grew.) Tj ET", "BT /F1 12 Tf 72 720 Td (Costs fell.) Tj ET")
+
+	markdown, err := NewManager().ConvertToMarkdown(context.Background(), path)
+	if err != nil {
+		t.Fatalf("ConvertToMarkdown failed: %v", err)
+	}
+	if expected := "# Summary\n\nRevenue grew.\n\n# Findings\n\nCosts fell."; markdown != expected {
+		t.Errorf("ConvertToMarkdown =\n%s\nwant\n%s", markdown, expected)
+	}
+}
+
+func TestConvertToMarkdownUnsupportedFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("plain"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewManager().ConvertToMarkdown(context.Background(), path); err == nil || !strings.Contains(err.Error(), "supports .docx, .html, .pdf and .md files only") {
+		t.Errorf("Expected an unsupported format error, got %v", err)
+	}
+}
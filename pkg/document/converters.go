@@ -0,0 +1,191 @@
+package document
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kevsmith/my-mcp/pkg/shared"
+)
+
+// Converter runs an external command to turn documents of formats the
+// package can't read into text, or into a format it can. Arguments may use
+// the placeholders {input} (the document path), {outdir} (a temporary
+// directory that is also the working directory and is removed afterwards)
+// and {name} (the document's file name without its extension).
+//
+// For example, LibreOffice converts presentations to PDF with
+//
+//	{"extensions": [".odp"], "command": "soffice",
+//	 "args": ["--headless", "--convert-to", "pdf", "--outdir", "{outdir}", "{input}"],
+//	 "output": "{name}.pdf", "timeout_seconds": 120}
+type Converter struct {
+	Name           string   `json:"name"`       // Format name used in messages; defaults to the command's base name
+	Extensions     []string `json:"extensions"` // File extensions handled, e.g. ".odp"
+	Command        string   `json:"command"`
+	Args           []string `json:"args"`
+	Output         string   `json:"output"`          // File the command writes in {outdir}; empty reads text from standard output
+	TimeoutSeconds int      `json:"timeout_seconds"` // 0 leaves only the extraction timeout
+}
+
+// maxConverterOutput bounds the standard output and error kept from a converter
+const maxConverterOutput = 64 << 20
+
+// GetConverters reads the external converters configured in the JSON file
+// named by DOCUMENT_CONVERTERS, a list of Converter objects. No variable
+// means no converters.
+func GetConverters() ([]Converter, error) {
+	path := os.Getenv("DOCUMENT_CONVERTERS")
+	if path == "" {
+		return nil, nil
+	}
+	return LoadConverters(path)
+}
+
+// LoadConverters reads external converters from a JSON file
+func LoadConverters(path string) ([]Converter, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read converter config: %w", err)
+	}
+	var converters []Converter
+	if err := json.Unmarshal(data, &converters); err != nil {
+		return nil, fmt.Errorf("failed to parse converter config %s: %w", path, err)
+	}
+	return converters, nil
+}
+
+// RegisterConverter registers an external converter as the format of its
+// extensions. Extensions handled by built-in formats can't be taken over.
+func (m *Manager) RegisterConverter(converter Converter) error {
+	if converter.Command == "" {
+		return fmt.Errorf("converter has no command")
+	}
+	if converter.Name == "" {
+		converter.Name = strings.TrimSuffix(filepath.Base(converter.Command), filepath.Ext(converter.Command))
+	}
+	if len(converter.Extensions) == 0 {
+		return fmt.Errorf("converter %s has no extensions", converter.Name)
+	}
+	if converter.TimeoutSeconds < 0 {
+		return fmt.Errorf("converter %s has a negative timeout", converter.Name)
+	}
+
+	extensions := make([]string, len(converter.Extensions))
+	for i, ext := range converter.Extensions {
+		ext = strings.ToLower(ext)
+		if !strings.HasPrefix(ext, ".") {
+			ext = "." + ext
+		}
+		if format, ok := m.formatForExtension(ext); ok {
+			return fmt.Errorf("converter %s: extension %s is already handled by the %s format", converter.Name, ext, format.Name)
+		}
+		extensions[i] = ext
+	}
+
+	documentType := documentTypeExternal
+	for {
+		if _, taken := m.formats[documentType]; !taken {
+			break
+		}
+		documentType++
+	}
+	m.RegisterFormat(Format{
+		Type:       documentType,
+		Name:       converter.Name,
+		Extensions: extensions,
+		Extract: func(ctx context.Context, filePath string) (string, error) {
+			return m.convert(ctx, converter, filePath)
+		},
+	})
+	return nil
+}
+
+// convert runs a converter on a document in a temporary directory and
+// extracts the text of what it produced with the built-in formats
+func (m *Manager) convert(ctx context.Context, converter Converter, filePath string) (string, error) {
+	input, err := filepath.Abs(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve document path: %w", err)
+	}
+	outdir, err := os.MkdirTemp("", "converter-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create converter directory: %w", err)
+	}
+	defer os.RemoveAll(outdir)
+
+	name := strings.TrimSuffix(filepath.Base(input), filepath.Ext(input))
+	placeholders := strings.NewReplacer("{input}", input, "{outdir}", outdir, "{name}", name)
+	args := make([]string, len(converter.Args))
+	for i, arg := range converter.Args {
+		args[i] = placeholders.Replace(arg)
+	}
+
+	runCtx := ctx
+	timeout := time.Duration(converter.TimeoutSeconds) * time.Second
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(runCtx, converter.Command, args...)
+	cmd.Dir = outdir
+	cmd.Stdout = &limitedBuffer{buffer: &stdout, limit: maxConverterOutput}
+	cmd.Stderr = &limitedBuffer{buffer: &stderr, limit: 4096}
+	// Don't wait for children of a killed converter that still hold its output
+	cmd.WaitDelay = time.Second
+
+	if err := cmd.Run(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return "", ctxErr
+		}
+		if errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+			return "", shared.NewError(shared.CodeTimeout, "converter %s timed out after %s", converter.Name, timeout)
+		}
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("converter %s failed: %w: %s", converter.Name, err, message)
+		}
+		return "", fmt.Errorf("converter %s failed: %w", converter.Name, err)
+	}
+
+	output := filepath.Join(outdir, "stdout.txt")
+	if converter.Output == "" {
+		if err := os.WriteFile(output, stdout.Bytes(), 0600); err != nil {
+			return "", fmt.Errorf("failed to save converter output: %w", err)
+		}
+	} else {
+		output = filepath.Join(outdir, placeholders.Replace(converter.Output))
+		if _, err := os.Stat(output); err != nil {
+			return "", fmt.Errorf("converter %s did not produce %s", converter.Name, filepath.Base(output))
+		}
+	}
+
+	// Converted output is read by a built-in format, never by a converter
+	if documentType := m.detectContentType(output); documentType == DocumentTypeUnknown {
+		return "", fmt.Errorf("converter %s produced an unsupported file: %s", converter.Name, filepath.Base(output))
+	}
+	return m.extract(ctx, output)
+}
+
+// limitedBuffer keeps the first limit bytes written to it and discards the
+// rest, so a runaway converter can't exhaust memory
+type limitedBuffer struct {
+	buffer *bytes.Buffer
+	limit  int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if remaining := b.limit - b.buffer.Len(); remaining > 0 {
+		b.buffer.Write(p[:min(len(p), remaining)])
+	}
+	return len(p), nil
+}
@@ -0,0 +1,122 @@
+package document
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// requireShell skips tests whose converters are shell commands
+func requireShell(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+}
+
+func TestExternalConverter(t *testing.T) {
+	requireShell(t)
+	dir := t.TempDir()
+	slides := filepath.Join(dir, "deck.odp")
+	if err := os.WriteFile(slides, []byte("PK\x03\x04 quarterly review"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	notes := filepath.Join(dir, "notes.wpd")
+	if err := os.WriteFile(notes, []byte("\xffWPC meeting notes"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewManager()
+	// Writes an HTML file, which is then read by the built-in HTML extractor
+	if err := m.RegisterConverter(Converter{
+		Extensions: []string{"ODP"},
+		Command:    "sh",
+		Args:       []string{"-c", `printf '<html><body><p>%s</p></body></html>' "$(tail -c +6 "$1")" > "$2/$3.html"`, "sh", "{input}", "{outdir}", "{name}"},
+		Output:     "{name}.html",
+	}); err != nil {
+		t.Fatalf("RegisterConverter failed: %v", err)
+	}
+	// Prints text on standard output
+	if err := m.RegisterConverter(Converter{
+		Name:       "wpd",
+		Extensions: []string{".wpd"},
+		Command:    "sh",
+		Args:       []string{"-c", `tail -c +6 "$1"`, "sh", "{input}"},
+	}); err != nil {
+		t.Fatalf("RegisterConverter failed: %v", err)
+	}
+
+	tests := map[string]string{slides: "quarterly review", notes: "meeting notes"}
+	for path, expected := range tests {
+		text, err := m.ExtractText(context.Background(), path)
+		if err != nil {
+			t.Errorf("ExtractText(%s) failed: %v", filepath.Base(path), err)
+			continue
+		}
+		if text != expected {
+			t.Errorf("ExtractText(%s) = %q, want %q", filepath.Base(path), text, expected)
+		}
+	}
+	if !m.isSupportedPath(slides) {
+		t.Error("Expected converter extensions to be supported")
+	}
+}
+
+func TestExternalConverterErrors(t *testing.T) {
+	requireShell(t)
+	path := filepath.Join(t.TempDir(), "drawing.odg")
+	if err := os.WriteFile(path, []byte("PK\x03\x04"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		converter Converter
+		expected  string
+	}{
+		"failure": {Converter{Command: "sh", Args: []string{"-c", "echo broken >&2; exit 3"}}, "converter sh failed: exit status 3: broken"},
+		"timeout": {Converter{Command: "sh", Args: []string{"-c", "exec sleep 5"}, TimeoutSeconds: 1}, "converter sh timed out after 1s"},
+		"missing": {Converter{Command: "sh", Args: []string{"-c", "true"}, Output: "{name}.pdf"}, "did not produce drawing.pdf"},
+		"binary":  {Converter{Command: "sh", Args: []string{"-c", `printf '\000\000\000\000\000'`}}, "produced an unsupported file"},
+	}
+	for name, test := range tests {
+		m := NewManager()
+		test.converter.Extensions = []string{".odg"}
+		if err := m.RegisterConverter(test.converter); err != nil {
+			t.Fatalf("%s: RegisterConverter failed: %v", name, err)
+		}
+		if _, err := m.ExtractText(context.Background(), path); err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("%s: error = %v, want %q", name, err, test.expected)
+		}
+	}
+}
+
+func TestLoadConverters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "converters.json")
+	config := `[{"extensions": [".odp"], "command": "soffice", "args": ["--headless", "--convert-to", "pdf", "--outdir", "{outdir}", "{input}"], "output": "{name}.pdf", "timeout_seconds": 120}]`
+	if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DOCUMENT_CONVERTERS", path)
+
+	converters, err := GetConverters()
+	if err != nil {
+		t.Fatalf("GetConverters failed: %v", err)
+	}
+	if len(converters) != 1 || converters[0].Output != "{name}.pdf" || converters[0].TimeoutSeconds != 120 || len(converters[0].Args) != 6 {
+		t.Fatalf("Unexpected converters: %+v", converters)
+	}
+
+	m := NewManager()
+	if err := m.RegisterConverter(converters[0]); err != nil {
+		t.Fatalf("RegisterConverter failed: %v", err)
+	}
+	if err := m.RegisterConverter(Converter{Command: "pandoc", Extensions: []string{".docx"}}); err == nil || !strings.Contains(err.Error(), "already handled by the docx format") {
+		t.Errorf("Expected a built-in extension error, got %v", err)
+	}
+	if err := m.RegisterConverter(Converter{Extensions: []string{".wpd"}}); err == nil {
+		t.Error("Expected an error for a converter without a command")
+	}
+}
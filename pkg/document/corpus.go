@@ -0,0 +1,252 @@
+package document
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/kevsmith/my-mcp/pkg/shared"
+	"github.com/ledongthuc/pdf"
+)
+
+// corpusIndexFile is the name of the index written at the root of the output tree
+const corpusIndexFile = "index.json"
+
+var pptxSlidePattern = regexp.MustCompile(`^ppt/slides/slide\d+\.xml$`)
+
+// CorpusEntry describes one converted document in the corpus index
+type CorpusEntry struct {
+	Title      string `json:"title"`
+	SourcePath string `json:"source_path"`
+	OutputPath string `json:"output_path"` // Relative to the output directory
+	Pages      int    `json:"pages,omitempty"`
+	WordCount  int    `json:"word_count"`
+}
+
+// CorpusFailure records a document that could not be converted
+type CorpusFailure struct {
+	SourcePath string `json:"source_path"`
+	Error      string `json:"error"`
+}
+
+// CorpusIndex is the content of index.json for a converted corpus
+type CorpusIndex struct {
+	SourceDir   string          `json:"source_dir"`
+	OutputDir   string          `json:"output_dir"`
+	GeneratedAt time.Time       `json:"generated_at"`
+	Documents   []CorpusEntry   `json:"documents"`
+	Failures    []CorpusFailure `json:"failures,omitempty"`
+}
+
+// ConvertCorpus walks sourceDir, converts every supported document to a Markdown
+// file under outputDir (mirroring the source layout) and writes an index.json
+// describing the result. Documents that fail to convert are listed in the index
+// rather than aborting the run; cancelling ctx stops the run.
+func (m *Manager) ConvertCorpus(ctx context.Context, sourceDir, outputDir string) (*CorpusIndex, error) {
+	absSource, err := filepath.Abs(sourceDir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid source directory: %w", err)
+	}
+	info, err := os.Stat(absSource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access source directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, shared.NewError(shared.CodeInvalidArgument, "source path is not a directory: %s", absSource)
+	}
+
+	absOutput, err := filepath.Abs(outputDir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output directory: %w", err)
+	}
+	if absOutput == absSource {
+		return nil, fmt.Errorf("output directory must differ from the source directory")
+	}
+	if err := os.MkdirAll(absOutput, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	index := &CorpusIndex{
+		SourceDir:   absSource,
+		OutputDir:   absOutput,
+		GeneratedAt: time.Now().UTC(),
+		Documents:   []CorpusEntry{},
+	}
+	written := make(map[string]bool)
+
+	err = filepath.WalkDir(absSource, func(path string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if err != nil {
+			index.Failures = append(index.Failures, CorpusFailure{SourcePath: path, Error: err.Error()})
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if d.IsDir() {
+			// Don't convert our own output when it lives inside the source tree
+			if path == absOutput {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !d.Type().IsRegular() || !m.isSupportedPath(path) {
+			return nil
+		}
+
+		entry, convErr := m.convertCorpusDocument(ctx, absSource, absOutput, path, written)
+		if convErr != nil {
+			index.Failures = append(index.Failures, CorpusFailure{SourcePath: path, Error: convErr.Error()})
+			return nil
+		}
+		index.Documents = append(index.Documents, *entry)
+		return nil
+	})
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, fmt.Errorf("corpus conversion cancelled: %w", ctxErr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk source directory: %w", err)
+	}
+
+	indexJSON, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode index: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(absOutput, corpusIndexFile), indexJSON, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write index: %w", err)
+	}
+
+	return index, nil
+}
+
+// convertCorpusDocument converts a single document and writes its Markdown file
+func (m *Manager) convertCorpusDocument(ctx context.Context, sourceDir, outputDir, path string, written map[string]bool) (*CorpusEntry, error) {
+	text, err := m.ExtractText(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	relPath, err := filepath.Rel(sourceDir, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute relative path: %w", err)
+	}
+
+	// report.pdf becomes report.md; if report.docx already claimed that name,
+	// fall back to report.docx.md so neither document is overwritten
+	ext := filepath.Ext(relPath)
+	outRel := strings.TrimSuffix(relPath, ext) + ".md"
+	if written[outRel] {
+		outRel = relPath + ".md"
+	}
+	written[outRel] = true
+
+	title := strings.TrimSuffix(filepath.Base(path), ext)
+	outPath := filepath.Join(outputDir, outRel)
+	if err := os.MkdirAll(filepath.Dir(outPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if err := os.WriteFile(outPath, []byte(renderCorpusMarkdown(title, path, text)), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write markdown: %w", err)
+	}
+
+	return &CorpusEntry{
+		Title:      title,
+		SourcePath: path,
+		OutputPath: filepath.ToSlash(outRel),
+		Pages:      m.countPages(path),
+		WordCount:  len(strings.Fields(text)),
+	}, nil
+}
+
+// renderCorpusMarkdown wraps extracted text in a Markdown document
+func renderCorpusMarkdown(title, sourcePath, text string) string {
+	var md strings.Builder
+	md.WriteString("# ")
+	md.WriteString(title)
+	md.WriteString("\n\n")
+	md.WriteString("> Source: ")
+	md.WriteString(sourcePath)
+	md.WriteString("\n\n")
+	md.WriteString(text)
+	md.WriteString("\n")
+	return md.String()
+}
+
+// countPages returns the page (or slide) count of a document, or 0 if unknown
+func (m *Manager) countPages(filePath string) int {
+	switch m.detectFileType(filePath) {
+	case DocumentTypePDF:
+		file, reader, err := pdf.Open(filePath)
+		if err != nil {
+			return 0
+		}
+		defer file.Close()
+		return reader.NumPage()
+	case DocumentTypePPTX:
+		reader, err := zip.OpenReader(filePath)
+		if err != nil {
+			return 0
+		}
+		defer reader.Close()
+
+		slides := 0
+		for _, f := range reader.File {
+			if pptxSlidePattern.MatchString(f.Name) {
+				slides++
+			}
+		}
+		return slides
+	case DocumentTypeDOCX:
+		return docxPageCount(filePath)
+	case DocumentTypeDOC:
+		return olePageCount(filePath, DocumentTypeDOC)
+	case DocumentTypePPT:
+		return olePageCount(filePath, DocumentTypePPT)
+	default:
+		return 0
+	}
+}
+
+// docxPageCount reads the page count Word stores in docProps/app.xml
+func docxPageCount(filePath string) int {
+	reader, err := zip.OpenReader(filePath)
+	if err != nil {
+		return 0
+	}
+	defer reader.Close()
+
+	for _, f := range reader.File {
+		if f.Name != "docProps/app.xml" {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return 0
+		}
+		defer rc.Close()
+
+		var props struct {
+			Pages int `xml:"Pages"`
+		}
+		if err := xml.NewDecoder(rc).Decode(&props); err != nil {
+			return 0
+		}
+		return props.Pages
+	}
+
+	return 0
+}
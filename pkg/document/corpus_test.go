@@ -0,0 +1,152 @@
+package document
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// writeTestDocx creates a minimal DOCX containing the given paragraph text
+func writeTestDocx(t *testing.T, path, text string, pages int) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	zw := zip.NewWriter(file)
+	parts := map[string]string{
+		"[Content_Types].xml":          `<?xml version="1.0" encoding="UTF-8"?><Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"></Types>`,
+		"word/document.xml":            `<?xml version="1.0" encoding="UTF-8"?><w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body><w:p><w:r><w:t>` + text + `</w:t></w:r></w:p></w:body></w:document>`,
+		"word/_rels/document.xml.rels": `<?xml version="1.0" encoding="UTF-8"?><Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"></Relationships>`,
+		"docProps/app.xml":             `<?xml version="1.0" encoding="UTF-8"?><Properties xmlns="http://schemas.openxmlformats.org/officeDocument/2006/extended-properties"><Pages>` + strconv.Itoa(pages) + `</Pages></Properties>`,
+	}
+	for name, content := range parts {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConvertCorpus(t *testing.T) {
+	sourceDir := t.TempDir()
+	outputDir := filepath.Join(sourceDir, "markdown")
+
+	writeTestDocx(t, filepath.Join(sourceDir, "handbook.docx"), "Welcome to the team handbook", 3)
+	writeTestDocx(t, filepath.Join(sourceDir, "policies", "travel.docx"), "Book travel two weeks ahead", 1)
+	if err := os.WriteFile(filepath.Join(sourceDir, "archive.zip"), []byte("not a document"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(sourceDir, "broken.pdf"), []byte("garbage"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	manager := NewManager()
+	index, err := manager.ConvertCorpus(context.Background(), sourceDir, outputDir)
+	if err != nil {
+		t.Fatalf("ConvertCorpus failed: %v", err)
+	}
+
+	if len(index.Documents) != 2 {
+		t.Fatalf("Expected 2 converted documents, got %d: %+v", len(index.Documents), index.Documents)
+	}
+	if len(index.Failures) != 1 || !strings.HasSuffix(index.Failures[0].SourcePath, "broken.pdf") {
+		t.Errorf("Expected broken.pdf to be reported as a failure, got %+v", index.Failures)
+	}
+
+	handbook := index.Documents[0]
+	if handbook.Title != "handbook" || handbook.OutputPath != "handbook.md" {
+		t.Errorf("Unexpected entry: %+v", handbook)
+	}
+	if handbook.Pages != 3 || handbook.WordCount != 5 {
+		t.Errorf("Expected 3 pages and 5 words, got %d pages and %d words", handbook.Pages, handbook.WordCount)
+	}
+	if index.Documents[1].OutputPath != "policies/travel.md" {
+		t.Errorf("Expected mirrored output path, got %s", index.Documents[1].OutputPath)
+	}
+
+	markdown, err := os.ReadFile(filepath.Join(outputDir, "handbook.md"))
+	if err != nil {
+		t.Fatalf("Markdown file not written: %v", err)
+	}
+	if !strings.HasPrefix(string(markdown), "# handbook\n\n> Source: ") || !strings.Contains(string(markdown), "Welcome to the team handbook") {
+		t.Errorf("Unexpected markdown content: %q", markdown)
+	}
+
+	indexJSON, err := os.ReadFile(filepath.Join(outputDir, corpusIndexFile))
+	if err != nil {
+		t.Fatalf("index.json not written: %v", err)
+	}
+	var decoded CorpusIndex
+	if err := json.Unmarshal(indexJSON, &decoded); err != nil {
+		t.Fatalf("index.json is not valid JSON: %v", err)
+	}
+	if len(decoded.Documents) != 2 {
+		t.Errorf("Expected 2 documents in index.json, got %d", len(decoded.Documents))
+	}
+
+	// Converting again must not pick up the generated Markdown tree
+	index, err = manager.ConvertCorpus(context.Background(), sourceDir, outputDir)
+	if err != nil {
+		t.Fatalf("Second ConvertCorpus failed: %v", err)
+	}
+	if len(index.Documents) != 2 {
+		t.Errorf("Expected output tree to be skipped, got %d documents", len(index.Documents))
+	}
+}
+
+func TestConvertCorpusNameCollision(t *testing.T) {
+	sourceDir := t.TempDir()
+	outputDir := t.TempDir()
+
+	writeTestDocx(t, filepath.Join(sourceDir, "report.docx"), "Docx report", 1)
+	writeTestDocx(t, filepath.Join(sourceDir, "report.pptx"), "placeholder", 1)
+
+	manager := NewManager()
+	index, err := manager.ConvertCorpus(context.Background(), sourceDir, outputDir)
+	if err != nil {
+		t.Fatalf("ConvertCorpus failed: %v", err)
+	}
+
+	// Both documents share the stem "report"; the second keeps its extension
+	if len(index.Documents) != 2 {
+		t.Fatalf("Expected 2 documents, got %+v", index.Documents)
+	}
+	if index.Documents[0].OutputPath != "report.md" || index.Documents[1].OutputPath != "report.pptx.md" {
+		t.Errorf("Unexpected output paths: %s, %s", index.Documents[0].OutputPath, index.Documents[1].OutputPath)
+	}
+	for _, name := range []string{"report.md", "report.pptx.md"} {
+		if _, err := os.Stat(filepath.Join(outputDir, name)); err != nil {
+			t.Errorf("Expected %s to be written: %v", name, err)
+		}
+	}
+}
+
+func TestConvertCorpusInvalidDirectories(t *testing.T) {
+	manager := NewManager()
+	sourceDir := t.TempDir()
+
+	if _, err := manager.ConvertCorpus(context.Background(), filepath.Join(sourceDir, "missing"), t.TempDir()); err == nil {
+		t.Error("Expected error for missing source directory")
+	}
+	if _, err := manager.ConvertCorpus(context.Background(), sourceDir, sourceDir); err == nil {
+		t.Error("Expected error when output equals source")
+	}
+}
@@ -7,20 +7,240 @@ import (
 func GetToolDefinitions() []mcp.Tool {
 	return []mcp.Tool{
 		mcp.NewTool("extract_text",
-			mcp.WithDescription("Extract clean prose text from document files (.pdf, .docx, .pptx) - removes XML markup and formatting"),
+			mcp.WithDescription("Extract clean prose text from document files (.pdf, .docx, .pptx, .rtf, .odt, .epub, .html, .md, .txt, .log, and legacy .doc, .ppt) - removes XML markup and formatting; HTML keeps headings and link targets, Markdown is returned as written without its front matter, and text files are converted to UTF-8 from their detected encoding with lines kept. Set max_chars or max_tokens to split the text into overlapping chunks for retrieval pipelines; chunks are returned as JSON with their index, total count and character offsets. When the request carries a progress token, PDF extraction sends progress notifications every 10 pages; use start_extraction for documents too large to extract in one call"),
 			mcp.WithReadOnlyHintAnnotation(true),
 			mcp.WithString("file_path",
 				mcp.Description("Absolute path to the document file"),
 				mcp.Required(),
 			),
+			mcp.WithNumber("max_chars",
+				mcp.Description("Chunk mode: maximum characters per chunk. Chunks end at a paragraph, sentence or word boundary where possible"),
+			),
+			mcp.WithNumber("max_tokens",
+				mcp.Description("Chunk mode: maximum estimated tokens per chunk (about 4 characters per token); use instead of max_chars"),
+			),
+			mcp.WithNumber("overlap",
+				mcp.Description("Chunk mode: how much of the end of each chunk is repeated at the start of the next, in the same unit as the chunk size (default: 0)"),
+			),
+			mcp.WithNumber("chunk_index",
+				mcp.Description("Chunk mode: return only this chunk (0-based) instead of all chunks"),
+			),
+			mcp.WithBoolean("include_review",
+				mcp.Description("DOCX only: append comments (with the text they refer to), footnotes, endnotes and tracked insertions/deletions after the document text (default: false)"),
+			),
+			mcp.WithString("password",
+				mcp.Description("Password of an encrypted PDF or Office (.docx, .pptx) document; the text of encrypted documents is not cached"),
+			),
 		),
 		mcp.NewTool("get_document_info",
-			mcp.WithDescription("Get metadata and information about a document file"),
+			mcp.WithDescription("Get metadata and information about a document file: file stats plus embedded properties (title, author, subject, keywords, created and last-saved dates, page or slide count, word count) from PDF info/XMP, Office core properties, OpenDocument meta and legacy Office summary information, HTML title and meta tags, Markdown front matter, and the encoding and line count of text files. Reports whether the document is encrypted; the properties of an encrypted document are only read with its password. For PDF and Office files also reports digital signatures (signer, certificate issuer, signing time, reason; not cryptographically validated) and restrictions: PDF permissions such as printing or copying, Word editing protection, PowerPoint password to modify and Mark as Final"),
 			mcp.WithReadOnlyHintAnnotation(true),
 			mcp.WithString("file_path",
 				mcp.Description("Absolute path to the document file"),
 				mcp.Required(),
 			),
+			mcp.WithString("password",
+				mcp.Description("Password of an encrypted PDF or Office (.docx, .pptx) document"),
+			),
+		),
+		mcp.NewTool("convert_corpus",
+			mcp.WithDescription("Convert every supported document (.pdf, .docx, .pptx, .doc, .ppt, .rtf, .odt, .epub, .html, .md, .txt, .log) under a directory to Markdown files in an output tree and write an index.json (title, source path, pages, word count)"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithString("source_dir",
+				mcp.Description("Absolute path to the directory to convert (searched recursively)"),
+				mcp.Required(),
+			),
+			mcp.WithString("output_dir",
+				mcp.Description("Absolute path to the directory that receives the Markdown files and index.json (created if missing; existing files with the same names are overwritten)"),
+				mcp.Required(),
+			),
+		),
+		mcp.NewTool("extract_pages",
+			mcp.WithDescription("Extract clean text page by page from a PDF, or slide by slide from a PPTX, for selected pages only - use this to work through long documents incrementally instead of extracting everything at once"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("file_path",
+				mcp.Description("Absolute path to the .pdf or .pptx file"),
+				mcp.Required(),
+			),
+			mcp.WithString("pages",
+				mcp.Description("Pages or slides to extract, numbered from 1: a comma-separated list of numbers and ranges such as '1-5,8,12-' (an open range runs to the end). Defaults to all pages"),
+			),
+		),
+		mcp.NewTool("extract_tables",
+			mcp.WithDescription("Extract tables as structured rows and columns: DOCX tables are read from the document structure (merged cells keep the grid), PDF tables are detected from the alignment of text in columns. Returns JSON with each table's index, page and rows, or CSV"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("file_path",
+				mcp.Description("Absolute path to the .docx or .pdf file"),
+				mcp.Required(),
+			),
+			mcp.WithString("pages",
+				mcp.Description("PDF only: pages to scan, such as '1-5,8,12-'. Defaults to all pages"),
+			),
+			mcp.WithString("format",
+				mcp.Description("Output format: 'json' (default) or 'csv'"),
+				mcp.Enum("json", "csv"),
+			),
+		),
+		mcp.NewTool("get_outline",
+			mcp.WithDescription("Get the heading structure of a document with page or slide anchors, to find the section to extract: PDF bookmarks, DOCX paragraphs with heading styles (pages estimated from the page breaks Word saved), or PPTX slide titles in presentation order"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("file_path",
+				mcp.Description("Absolute path to the .pdf, .docx or .pptx file"),
+				mcp.Required(),
+			),
+		),
+		mcp.NewTool("search_document",
+			mcp.WithDescription("Find a word, phrase or regular expression in a document without extracting all of it: returns each match with its page (PDF) or slide (PPTX) number, line and surrounding text. Works with every format extract_text supports"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("file_path",
+				mcp.Description("Absolute path to the document"),
+				mcp.Required(),
+			),
+			mcp.WithString("query",
+				mcp.Description("Text to find, or a regular expression (RE2 syntax) when regex is true"),
+				mcp.Required(),
+			),
+			mcp.WithBoolean("regex",
+				mcp.Description("Treat query as a regular expression (default: false)"),
+			),
+			mcp.WithBoolean("case_sensitive",
+				mcp.Description("Match letter case exactly (default: false)"),
+			),
+			mcp.WithNumber("context_chars",
+				mcp.Description("Characters of surrounding text to show on each side of a match (default: 80)"),
+			),
+			mcp.WithNumber("max_results",
+				mcp.Description("Maximum number of matches to return (default: 50); the total count is always reported"),
+			),
+		),
+		mcp.NewTool("list_images",
+			mcp.WithDescription("List the images embedded in a document with their format, pixel dimensions and size: per page for PDF, per slide for PPTX (in presentation order), and in document order for DOCX, ODT and EPUB. Use the index with extract_image"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("file_path",
+				mcp.Description("Absolute path to the .pdf, .docx, .pptx, .odt or .epub file"),
+				mcp.Required(),
+			),
+		),
+		mcp.NewTool("extract_image",
+			mcp.WithDescription("Save an embedded image to a file, e.g. to pass a figure to a vision model. Package images are saved as stored; PDF JPEG images are saved as .jpg and other PDF images are converted to PNG"),
+			mcp.WithString("file_path",
+				mcp.Description("Absolute path to the document"),
+				mcp.Required(),
+			),
+			mcp.WithNumber("index",
+				mcp.Description("Index of the image as reported by list_images, numbered from 1"),
+				mcp.Required(),
+			),
+			mcp.WithString("output_path",
+				mcp.Description("File to write, or an existing directory to save the image in under its own name"),
+				mcp.Required(),
+			),
+		),
+		mcp.NewTool("extract_slides",
+			mcp.WithDescription("Extract PowerPoint (.pptx) slides with their speaker notes: returns each slide's title, body text and notes in presentation order. Slides hidden in the slide show are skipped unless include_hidden is set"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("file_path",
+				mcp.Description("Absolute path to the .pptx file"),
+				mcp.Required(),
+			),
+			mcp.WithString("slides",
+				mcp.Description("Slides to extract, numbered from 1 in presentation order (hidden slides included in the numbering): a list such as '1-5,8,12-'. Defaults to all slides"),
+			),
+			mcp.WithBoolean("include_hidden",
+				mcp.Description("Include slides hidden in the slide show (default: false)"),
+			),
+		),
+		mcp.NewTool("extract_review",
+			mcp.WithDescription("Extract the review content of a Word (.docx) document that is not part of its main text: comments with author, date and the text they refer to, footnotes, endnotes, and tracked insertions and deletions. Returns JSON"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("file_path",
+				mcp.Description("Absolute path to the .docx file"),
+				mcp.Required(),
+			),
+		),
+		mcp.NewTool("convert_to_markdown",
+			mcp.WithDescription("Convert a document to Markdown, keeping the structure extract_text flattens: headings, bulleted and numbered lists, tables, hyperlinks and bold/italic emphasis from .docx and .html files. PDFs are converted page by page with their bookmarks as headings; .md files are returned without front matter"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("file_path",
+				mcp.Description("Absolute path to the .docx, .html, .pdf or .md file"),
+				mcp.Required(),
+			),
+		),
+		mcp.NewTool("analyze_document",
+			mcp.WithDescription("Analyze the text of a document before summarizing or routing it: detected languages with their share of the text, character, word, sentence and paragraph counts, average sentence length, reading time, vocabulary size and the most frequent content words. Returns JSON, including the extracted text unless include_text is false"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("file_path",
+				mcp.Description("Absolute path to the document file"),
+				mcp.Required(),
+			),
+			mcp.WithBoolean("include_text",
+				mcp.Description("Include the extracted text in the result (default: true)"),
+			),
+		),
+		mcp.NewTool("extract_from_url",
+			mcp.WithDescription("Download a document over HTTP or HTTPS and extract its clean text like extract_text. Accepts PDF, Word, PowerPoint, RTF, OpenDocument, EPUB, HTML, Markdown and plain text responses (generic binary responses when the URL ends in a supported extension); the download is bound by the server's file size limit and timeout"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithOpenWorldHintAnnotation(true),
+			mcp.WithString("url",
+				mcp.Description("http or https URL of the document"),
+				mcp.Required(),
+			),
+			mcp.WithString("password",
+				mcp.Description("Password of an encrypted PDF or Office (.docx, .pptx) document"),
+			),
+		),
+		mcp.NewTool("get_form_fields",
+			mcp.WithDescription("List the interactive form (AcroForm) fields of a PDF as JSON: fully qualified name, type (text, checkbox, radio, button, combo, list, signature), current value, options, required and read-only flags and page, plus the number of filled fields and the names of unfilled and missing required fields - use this to read filled-in forms and report what's missing"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("file_path",
+				mcp.Description("Absolute path to the .pdf file"),
+				mcp.Required(),
+			),
+		),
+		mcp.NewTool("start_extraction",
+			mcp.WithDescription("Start extracting the text of a large document in the background and return a job ID at once. Poll get_extraction_status for progress and read the text page range by page range with get_extraction_chunk while the extraction runs; chunks extracted before a failure or timeout stay available. Finished jobs are kept for 30 minutes"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("file_path",
+				mcp.Description("Absolute path to the document file"),
+				mcp.Required(),
+			),
+			mcp.WithString("password",
+				mcp.Description("Password of an encrypted PDF or Office (.docx, .pptx) document"),
+			),
+		),
+		mcp.NewTool("get_extraction_status",
+			mcp.WithDescription("Report the progress of a background extraction as JSON: status (running, completed, failed), pages extracted, total pages, the number of chunks available and any error"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("job_id",
+				mcp.Description("Job ID returned by start_extraction"),
+				mcp.Required(),
+			),
+		),
+		mcp.NewTool("get_extraction_chunk",
+			mcp.WithDescription("Return the text of one chunk of a background extraction as JSON with its page range. Chunks hold 10 pages each (documents without pages are a single chunk); a chunk is available once all its pages are extracted"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("job_id",
+				mcp.Description("Job ID returned by start_extraction"),
+				mcp.Required(),
+			),
+			mcp.WithNumber("chunk_index",
+				mcp.Description("Chunk to return (0-based)"),
+				mcp.Required(),
+			),
+		),
+		mcp.NewTool("get_embedded_workbooks",
+			mcp.WithDescription("List the Excel workbooks (.xlsx, .xlsm) embedded in a Word (.docx) or PowerPoint (.pptx) document as JSON: package name, whether it is an embedded object or chart data, the slide showing it, and each sheet's name, size and first rows as tab-separated text. Set output_dir to also save the workbooks as files that the Excel tools can open"),
+			mcp.WithString("file_path",
+				mcp.Description("Absolute path to the .docx or .pptx file"),
+				mcp.Required(),
+			),
+			mcp.WithNumber("max_rows",
+				mcp.Description("Rows of each sheet to return as text (default: 50)"),
+			),
+			mcp.WithString("output_dir",
+				mcp.Description("Directory to save the embedded workbooks in, under their package names"),
+			),
 		),
 	}
 }
@@ -0,0 +1,197 @@
+package document
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// defaultEmbeddedRows is how many rows of each embedded sheet are returned
+// unless the caller asks for more
+const defaultEmbeddedRows = 50
+
+// EmbeddedSheet is a worksheet of an embedded workbook. Text holds its first
+// rows with cells separated by tabs.
+type EmbeddedSheet struct {
+	Name      string `json:"name"`
+	Rows      int    `json:"rows"`
+	Columns   int    `json:"columns"`
+	Text      string `json:"text"`
+	Truncated bool   `json:"truncated,omitempty"` // Text stops before the last row
+}
+
+// EmbeddedWorkbook is an Excel workbook stored inside a Word or PowerPoint
+// package, either as an embedded object or as the data of a chart
+type EmbeddedWorkbook struct {
+	Index     int             `json:"index"`           // 1-based position in the document
+	Name      string          `json:"name"`            // Path in the package
+	Source    string          `json:"source"`          // "object" or "chart"
+	Slide     int             `json:"slide,omitempty"` // PPTX slide showing the workbook
+	Size      int64           `json:"size"`
+	Sheets    []EmbeddedSheet `json:"sheets"`
+	Error     string          `json:"error,omitempty"`      // Why the sheets couldn't be read
+	SavedPath string          `json:"saved_path,omitempty"` // Copy that Excel tools can open
+}
+
+// EmbeddedWorkbooks is the result of listing the workbooks in a document
+type EmbeddedWorkbooks struct {
+	FilePath  string             `json:"file_path"`
+	Workbooks []EmbeddedWorkbook `json:"workbooks"`
+}
+
+// GetEmbeddedWorkbooks returns the Excel workbooks (.xlsx, .xlsm) embedded in
+// a DOCX or PPTX document with the first maxRows rows of each sheet. With an
+// output directory, each workbook is also saved there under its package name
+// so that spreadsheet tools can open it.
+func (m *Manager) GetEmbeddedWorkbooks(filePath string, maxRows int, outputDir string) (*EmbeddedWorkbooks, error) {
+	if err := m.checkFileSize(filePath); err != nil {
+		return nil, err
+	}
+	docType := m.detectFileType(filePath)
+	if docType != DocumentTypeDOCX && docType != DocumentTypePPTX {
+		return nil, fmt.Errorf("embedded workbooks are supported for .docx and .pptx files only: %s", filePath)
+	}
+	if maxRows <= 0 {
+		maxRows = defaultEmbeddedRows
+	}
+
+	reader, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open document package: %w", err)
+	}
+	defer reader.Close()
+
+	files := make(map[string]*zip.File, len(reader.File))
+	for _, f := range reader.File {
+		files[f.Name] = f
+	}
+
+	result := &EmbeddedWorkbooks{FilePath: filePath, Workbooks: []EmbeddedWorkbook{}}
+	if outputDir != "" {
+		if err := os.MkdirAll(outputDir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+	for i, workbook := range embeddedWorkbookParts(files, docType) {
+		f := files[workbook.Name]
+		workbook.Index = i + 1
+		workbook.Size = int64(f.UncompressedSize64)
+
+		data, err := readZipFile(f)
+		if err != nil {
+			workbook.Error = fmt.Sprintf("failed to read workbook: %v", err)
+			result.Workbooks = append(result.Workbooks, workbook)
+			continue
+		}
+		workbook.Sheets, err = workbookSheets([]byte(data), maxRows)
+		if err != nil {
+			workbook.Error = err.Error()
+		}
+		if outputDir != "" {
+			savedPath := filepath.Join(outputDir, path.Base(workbook.Name))
+			if err := os.WriteFile(savedPath, []byte(data), 0644); err != nil {
+				return nil, fmt.Errorf("failed to write workbook: %w", err)
+			}
+			workbook.SavedPath = savedPath
+		}
+		result.Workbooks = append(result.Workbooks, workbook)
+	}
+	return result, nil
+}
+
+// embeddedWorkbookParts finds the workbooks of a package: those the document
+// body or slides embed, then chart data, then any others in the package
+func embeddedWorkbookParts(files map[string]*zip.File, docType DocumentType) []EmbeddedWorkbook {
+	var workbooks []EmbeddedWorkbook
+	listed := make(map[string]bool)
+	add := func(name, source string, slide int) {
+		if files[name] != nil && isWorkbookPart(name) && !listed[name] {
+			listed[name] = true
+			workbooks = append(workbooks, EmbeddedWorkbook{Name: name, Source: source, Slide: slide})
+		}
+	}
+	// Objects are embedded as packages; charts keep their data in a package
+	// related to the chart part
+	scan := func(part string, slide int) {
+		for _, rel := range partRelationships(files, part) {
+			switch {
+			case strings.HasSuffix(rel.Type, "/package"):
+				add(rel.Target, "object", slide)
+			case strings.HasSuffix(rel.Type, "/chart"):
+				for _, chartRel := range partRelationships(files, rel.Target) {
+					if strings.HasSuffix(chartRel.Type, "/package") {
+						add(chartRel.Target, "chart", slide)
+					}
+				}
+			}
+		}
+	}
+
+	if docType == DocumentTypePPTX {
+		for i, slide := range pptxSlideOrder(files) {
+			scan(slide, i+1)
+		}
+	} else {
+		scan("word/document.xml", 0)
+	}
+
+	var remaining []string
+	for name := range files {
+		if !listed[name] && isWorkbookPart(name) {
+			remaining = append(remaining, name)
+		}
+	}
+	sort.Strings(remaining)
+	for _, name := range remaining {
+		add(name, "object", 0)
+	}
+	return workbooks
+}
+
+// isWorkbookPart reports whether a package part is an Office Open XML workbook
+func isWorkbookPart(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".xlsx", ".xlsm":
+		return true
+	}
+	return false
+}
+
+// workbookSheets reads the visible cell values of each worksheet of a
+// workbook, keeping the first maxRows rows as text
+func workbookSheets(data []byte, maxRows int) ([]EmbeddedSheet, error) {
+	workbook, err := excelize.OpenReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open workbook: %w", err)
+	}
+	defer workbook.Close()
+
+	sheets := []EmbeddedSheet{}
+	for _, name := range workbook.GetSheetList() {
+		rows, err := workbook.GetRows(name)
+		if err != nil {
+			continue // Chart sheets have no cells
+		}
+		sheet := EmbeddedSheet{Name: name, Rows: len(rows)}
+		var text strings.Builder
+		for i, row := range rows {
+			sheet.Columns = max(sheet.Columns, len(row))
+			if i >= maxRows {
+				sheet.Truncated = true
+				continue
+			}
+			text.WriteString(strings.Join(row, "\t"))
+			text.WriteString("\n")
+		}
+		sheet.Text = strings.TrimRight(text.String(), "\n")
+		sheets = append(sheets, sheet)
+	}
+	return sheets, nil
+}
@@ -0,0 +1,92 @@
+package document
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// testWorkbook returns an .xlsx file with the given rows on sheet "Budget"
+func testWorkbook(t *testing.T, rows ...[]any) string {
+	t.Helper()
+	workbook := excelize.NewFile()
+	defer workbook.Close()
+	if err := workbook.SetSheetName("Sheet1", "Budget"); err != nil {
+		t.Fatal(err)
+	}
+	for i, row := range rows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+1)
+		if err := workbook.SetSheetRow("Budget", cell, &row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	buffer, err := workbook.WriteToBuffer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buffer.String()
+}
+
+func TestGetEmbeddedWorkbooks(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "review.pptx")
+	const relationships = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+	writeTestPptxShapes(t, path, []int{1, 2}, [][2]string{
+		{"ppt/slides/_rels/slide2.xml.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="` + relationships + `/chart" Target="../charts/chart1.xml"/>` +
+			`<Relationship Id="rId2" Type="` + relationships + `/package" Target="../embeddings/Microsoft_Excel_Worksheet.xlsx"/></Relationships>`},
+		{"ppt/charts/chart1.xml", `<c:chartSpace xmlns:c="http://schemas.openxmlformats.org/drawingml/2006/chart"/>`},
+		{"ppt/charts/_rels/chart1.xml.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="` + relationships + `/package" Target="../embeddings/Microsoft_Excel_Worksheet1.xlsx"/></Relationships>`},
+		{"ppt/embeddings/Microsoft_Excel_Worksheet.xlsx", testWorkbook(t, []any{"Item", "Cost"}, []any{"Travel", 1200}, []any{"Hardware", 800})},
+		{"ppt/embeddings/Microsoft_Excel_Worksheet1.xlsx", testWorkbook(t, []any{"Quarter", "Revenue"}, []any{"Q1", 10})},
+		{"ppt/embeddings/Microsoft_Excel_Worksheet2.xlsx", "not a workbook"},
+	}, `<p:sp/>`, `<p:sp/>`)
+
+	output := filepath.Join(dir, "workbooks")
+	result, err := NewManager().GetEmbeddedWorkbooks(path, 2, output)
+	if err != nil {
+		t.Fatalf("GetEmbeddedWorkbooks failed: %v", err)
+	}
+	if len(result.Workbooks) != 3 {
+		t.Fatalf("Expected 3 workbooks, got %+v", result.Workbooks)
+	}
+
+	chart, object, broken := result.Workbooks[0], result.Workbooks[1], result.Workbooks[2]
+	if chart.Name != "ppt/embeddings/Microsoft_Excel_Worksheet1.xlsx" || chart.Source != "chart" || chart.Slide != 2 {
+		t.Errorf("Unexpected chart workbook: %+v", chart)
+	}
+	if object.Source != "object" || object.Slide != 2 || len(object.Sheets) != 1 {
+		t.Fatalf("Unexpected object workbook: %+v", object)
+	}
+	sheet := object.Sheets[0]
+	if sheet.Name != "Budget" || sheet.Rows != 3 || sheet.Columns != 2 || sheet.Text != "Item\tCost\nTravel\t1200" || !sheet.Truncated {
+		t.Errorf("Unexpected sheet: %+v", sheet)
+	}
+	if broken.Slide != 0 || broken.Error == "" || broken.Sheets != nil {
+		t.Errorf("Unexpected unreadable workbook: %+v", broken)
+	}
+
+	// Saved copies open as workbooks of their own
+	saved, err := excelize.OpenFile(object.SavedPath)
+	if err != nil {
+		t.Fatalf("Failed to open saved workbook: %v", err)
+	}
+	defer saved.Close()
+	if value, _ := saved.GetCellValue("Budget", "A3"); value != "Hardware" {
+		t.Errorf("Saved workbook A3 = %q", value)
+	}
+}
+
+func TestGetEmbeddedWorkbooksUnsupportedFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("plain"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewManager().GetEmbeddedWorkbooks(path, 0, ""); err == nil || !strings.Contains(err.Error(), "supported for .docx and .pptx files only") {
+		t.Errorf("Expected an unsupported format error, got %v", err)
+	}
+}
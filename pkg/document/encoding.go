@@ -0,0 +1,113 @@
+package document
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/encoding/unicode/utf32"
+)
+
+// textEncodingSample is how much of a file the UTF-16 heuristic inspects
+const textEncodingSample = 4096
+
+// textEncoding is a character encoding detected for a text document
+type textEncoding struct {
+	Name    string            // Label reported in metadata, e.g. "utf-16le"
+	BOM     []byte            // Byte order mark stripped before decoding, if present
+	Decoder encoding.Encoding // nil for UTF-8
+}
+
+// Byte order marks, longest first so UTF-32LE isn't mistaken for UTF-16LE
+var textBOMs = []textEncoding{
+	{"utf-32le", []byte{0xFF, 0xFE, 0x00, 0x00}, utf32.UTF32(utf32.LittleEndian, utf32.IgnoreBOM)},
+	{"utf-32be", []byte{0x00, 0x00, 0xFE, 0xFF}, utf32.UTF32(utf32.BigEndian, utf32.IgnoreBOM)},
+	{"utf-8", utf8BOM, nil},
+	{"utf-16le", []byte{0xFF, 0xFE}, unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)},
+	{"utf-16be", []byte{0xFE, 0xFF}, unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM)},
+}
+
+// detectTextEncoding identifies the encoding of text content: a byte order
+// mark wins, then UTF-16 is recognised by the zero high bytes of ASCII
+// characters, then valid UTF-8 is accepted as is. Anything else is assumed to
+// be Windows-1252, the usual legacy encoding of Windows-produced text and logs.
+func detectTextEncoding(content []byte) textEncoding {
+	for _, candidate := range textBOMs {
+		if bytes.HasPrefix(content, candidate.BOM) {
+			return candidate
+		}
+	}
+
+	if littleEndian, ok := detectUTF16(content); ok {
+		if littleEndian {
+			return textEncoding{Name: "utf-16le", Decoder: unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM)}
+		}
+		return textEncoding{Name: "utf-16be", Decoder: unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM)}
+	}
+
+	if utf8.Valid(content) {
+		return textEncoding{Name: "utf-8"}
+	}
+	return textEncoding{Name: "windows-1252", Decoder: charmap.Windows1252}
+}
+
+// detectUTF16 recognises UTF-16 without a byte order mark: in mostly-ASCII
+// text one byte of nearly every code unit is zero, on the same side
+func detectUTF16(content []byte) (littleEndian bool, ok bool) {
+	sample := content[:min(len(content), textEncodingSample)]
+	units := len(sample) / 2
+	if units < 2 {
+		return false, false
+	}
+
+	evenZeros, oddZeros := 0, 0
+	for i := 0; i+1 < len(sample); i += 2 {
+		if sample[i] == 0 {
+			evenZeros++
+		}
+		if sample[i+1] == 0 {
+			oddZeros++
+		}
+	}
+
+	// Require a clear majority on one side and almost none on the other
+	switch {
+	case oddZeros*10 >= units*6 && evenZeros*10 <= units:
+		return true, true
+	case evenZeros*10 >= units*6 && oddZeros*10 <= units:
+		return false, true
+	}
+	return false, false
+}
+
+// isTextContent reports whether the start of a file looks like text in one
+// of the encodings detectTextEncoding understands
+func isTextContent(head []byte) bool {
+	for _, candidate := range textBOMs {
+		if bytes.HasPrefix(head, candidate.BOM) {
+			return true
+		}
+	}
+	if _, ok := detectUTF16(head); ok {
+		return true
+	}
+	return bytes.IndexByte(head, 0) < 0
+}
+
+// decodeText converts text content to UTF-8 and reports the detected encoding
+func decodeText(content []byte) (string, string, error) {
+	detected := detectTextEncoding(content)
+	content = bytes.TrimPrefix(content, detected.BOM)
+	if detected.Decoder == nil {
+		return string(content), detected.Name, nil
+	}
+
+	decoded, err := detected.Decoder.NewDecoder().Bytes(content)
+	if err != nil {
+		return "", detected.Name, fmt.Errorf("failed to decode %s text: %w", detected.Name, err)
+	}
+	return string(decoded), detected.Name, nil
+}
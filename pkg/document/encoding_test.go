@@ -0,0 +1,115 @@
+package document
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+func utf16Bytes(text string, littleEndian bool) []byte {
+	var content []byte
+	for _, unit := range utf16.Encode([]rune(text)) {
+		if littleEndian {
+			content = append(content, byte(unit), byte(unit>>8))
+		} else {
+			content = append(content, byte(unit>>8), byte(unit))
+		}
+	}
+	return content
+}
+
+func TestDecodeText(t *testing.T) {
+	latin1, err := charmap.Windows1252.NewEncoder().Bytes([]byte("Café – naïve"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		content  []byte
+		encoding string
+	}{
+		{"utf-8", []byte("Café – naïve"), "utf-8"},
+		{"utf-8 bom", append([]byte{0xEF, 0xBB, 0xBF}, "Café – naïve"...), "utf-8"},
+		{"utf-16le bom", append([]byte{0xFF, 0xFE}, utf16Bytes("Café – naïve", true)...), "utf-16le"},
+		{"utf-16be bom", append([]byte{0xFE, 0xFF}, utf16Bytes("Café – naïve", false)...), "utf-16be"},
+		{"utf-16le without bom", utf16Bytes("Café – naïve", true), "utf-16le"},
+		{"utf-16be without bom", utf16Bytes("Café – naïve", false), "utf-16be"},
+		{"utf-32le bom", []byte{0xFF, 0xFE, 0, 0, 'C', 0, 0, 0, 'a', 0, 0, 0, 'f', 0, 0, 0, 0xE9, 0, 0, 0, ' ', 0, 0, 0, 0x13, 0x20, 0, 0, ' ', 0, 0, 0,
+			'n', 0, 0, 0, 'a', 0, 0, 0, 0xEF, 0, 0, 0, 'v', 0, 0, 0, 'e', 0, 0, 0}, "utf-32le"},
+		{"windows-1252", latin1, "windows-1252"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			text, encodingName, err := decodeText(test.content)
+			if err != nil {
+				t.Fatalf("decodeText failed: %v", err)
+			}
+			if encodingName != test.encoding {
+				t.Errorf("Detected %s, want %s", encodingName, test.encoding)
+			}
+			if text != "Café – naïve" {
+				t.Errorf("Unexpected text: %q", text)
+			}
+		})
+	}
+}
+
+func TestIsTextContent(t *testing.T) {
+	if !isTextContent([]byte("plain")) || !isTextContent(utf16Bytes("wide text", true)) {
+		t.Error("Expected text content to be recognised")
+	}
+	if isTextContent([]byte{0x89, 'P', 'N', 'G', 0x0D, 0x0A, 0x1A, 0x0A, 0, 0, 0, 0x0D, 'I', 'H', 'D', 'R'}) {
+		t.Error("Expected binary content to be rejected")
+	}
+}
+
+func TestExtractPlainText(t *testing.T) {
+	dir := t.TempDir()
+	manager := NewManager()
+
+	// Windows tools often write logs as UTF-16 with CRLF line endings
+	logPath := filepath.Join(dir, "service.log.1")
+	content := append([]byte{0xFF, 0xFE}, utf16Bytes("2026-01-05 10:00:01  INFO   started\r\n2026-01-05 10:00:02  WARN   disk at 91%\r\n", true)...)
+	if err := os.WriteFile(logPath, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	text, err := manager.ExtractText(context.Background(), logPath)
+	if err != nil {
+		t.Fatalf("ExtractText failed: %v", err)
+	}
+	if text != "2026-01-05 10:00:01 INFO started\n2026-01-05 10:00:02 WARN disk at 91%" {
+		t.Errorf("Unexpected text: %q", text)
+	}
+
+	info, err := manager.GetDocumentInfo(context.Background(), logPath)
+	if err != nil {
+		t.Fatalf("GetDocumentInfo failed: %v", err)
+	}
+	if info.Metadata["encoding"] != "utf-16le" || info.Metadata["lines"] != "2" {
+		t.Errorf("Unexpected metadata: %v", info.Metadata)
+	}
+
+	notesPath := filepath.Join(dir, "notes.txt")
+	latin1, _ := charmap.Windows1252.NewEncoder().Bytes([]byte("Résumé\x00 draft"))
+	if err := os.WriteFile(notesPath, latin1, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := manager.ExtractText(context.Background(), notesPath); err == nil || !strings.Contains(err.Error(), "invalid .txt format") {
+		t.Errorf("Expected a binary .txt file to be rejected, got %v", err)
+	}
+
+	if err := os.WriteFile(notesPath, latin1[:6], 0644); err != nil {
+		t.Fatal(err)
+	}
+	if text, err := manager.ExtractText(context.Background(), notesPath); err != nil || text != "Résumé" {
+		t.Errorf("Expected Windows-1252 text to be decoded, got %q, %v", text, err)
+	}
+}
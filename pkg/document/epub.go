@@ -0,0 +1,141 @@
+package document
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// epubContainer is META-INF/container.xml, which points at the package document
+type epubContainer struct {
+	Rootfiles []struct {
+		FullPath string `xml:"full-path,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+// epubPackage is the OPF package document listing the book's content files
+type epubPackage struct {
+	Manifest []struct {
+		ID        string `xml:"id,attr"`
+		Href      string `xml:"href,attr"`
+		MediaType string `xml:"media-type,attr"`
+	} `xml:"manifest>item"`
+	Spine []struct {
+		IDRef string `xml:"idref,attr"`
+	} `xml:"spine>itemref"`
+}
+
+func (m *Manager) extractEPUBText(ctx context.Context, filePath string) (string, error) {
+	reader, err := zip.OpenReader(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open EPUB file: %w", err)
+	}
+	defer reader.Close()
+
+	files := make(map[string]*zip.File, len(reader.File))
+	for _, file := range reader.File {
+		files[file.Name] = file
+	}
+
+	chapters, err := epubReadingOrder(files)
+	if err != nil {
+		return "", err
+	}
+
+	var parts []string
+	for _, name := range chapters {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+
+		file, ok := files[name]
+		if !ok {
+			continue // Manifest entries may point at files missing from the archive
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return "", fmt.Errorf("failed to read EPUB chapter %s: %w", name, err)
+		}
+		text, err := htmlText(rc, false)
+		rc.Close()
+		if err != nil {
+			return "", fmt.Errorf("failed to parse EPUB chapter %s: %w", name, err)
+		}
+		if text = m.cleanExtractedText(text); text != "" {
+			parts = append(parts, text)
+		}
+	}
+
+	return strings.Join(parts, "\n\n"), nil
+}
+
+// epubReadingOrder returns the archive paths of the book's XHTML content in
+// spine order, falling back to every HTML file when there is no usable package
+// document
+func epubReadingOrder(files map[string]*zip.File) ([]string, error) {
+	var container epubContainer
+	if err := decodeZipXML(files["META-INF/container.xml"], &container); err != nil || len(container.Rootfiles) == 0 {
+		return epubHTMLFiles(files), nil
+	}
+
+	opfPath := container.Rootfiles[0].FullPath
+	var pkg epubPackage
+	if err := decodeZipXML(files[opfPath], &pkg); err != nil {
+		return nil, fmt.Errorf("failed to parse EPUB package %s: %w", opfPath, err)
+	}
+
+	hrefs := make(map[string]string, len(pkg.Manifest))
+	for _, item := range pkg.Manifest {
+		if item.MediaType == "application/xhtml+xml" || item.MediaType == "text/html" {
+			hrefs[item.ID] = item.Href
+		}
+	}
+
+	var chapters []string
+	for _, itemref := range pkg.Spine {
+		href, ok := hrefs[itemref.IDRef]
+		if !ok {
+			continue
+		}
+		// Hrefs are URLs relative to the package document
+		href = strings.SplitN(href, "#", 2)[0]
+		chapters = append(chapters, path.Join(path.Dir(opfPath), href))
+	}
+	if len(chapters) == 0 {
+		return epubHTMLFiles(files), nil
+	}
+	return chapters, nil
+}
+
+// epubHTMLFiles lists the HTML files of an archive in name order
+func epubHTMLFiles(files map[string]*zip.File) []string {
+	var names []string
+	for name := range files {
+		switch strings.ToLower(path.Ext(name)) {
+		case ".xhtml", ".html", ".htm":
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// decodeZipXML unmarshals an XML file from a zip archive
+func decodeZipXML(file *zip.File, v any) error {
+	if file == nil {
+		return fmt.Errorf("file not found in archive")
+	}
+	rc, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	decoder := xml.NewDecoder(rc)
+	decoder.Strict = false
+	return decoder.Decode(v)
+}
@@ -0,0 +1,274 @@
+package document
+
+import (
+	"archive/zip"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTestZip creates a zip archive holding the given entries in order
+func writeTestZip(t *testing.T, path string, entries ...[2]string) {
+	t.Helper()
+
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	zw := zip.NewWriter(file)
+	for _, entry := range entries {
+		w, err := zw.Create(entry[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(entry[1])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRTFText(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"paragraphs", `{\rtf1\ansi{\fonttbl{\f0 Arial;}}\f0 Hello\par World}`, "Hello\nWorld"},
+		{"hex escapes", `{\rtf1\ansi\ansicpg1252 Caf\'e9 \'80 5}`, "Café € 5"},
+		{"code page", `{\rtf1\ansi\ansicpg1251 \'cf\'f0\'e8\'e2\'e5\'f2}`, "Привет"},
+		{"unicode with fallback", `{\rtf1\uc1 Z\u252?rich \u-3913?}`, "Zürich "},
+		{"surrogate pair", `{\rtf1\uc0 \u-10179\u-8704}`, "😀"},
+		{"fields keep result only", `{\rtf1 See {\field{\*\fldinst HYPERLINK "http://example.com"}{\fldrslt site}}.}`, "See site."},
+		{"ignorable destination", `{\rtf1 A{\*\generator Writer;}B}`, "AB"},
+		{"escaped braces", `{\rtf1 \{x\}\\}`, `{x}\`},
+		{"binary data", "{\\rtf1 a\\bin3 xyzb}", "ab"},
+		{"oversized binary", "{\\rtf1 a\\bin999999 xyz", "a"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := rtfText([]byte(test.input)); got != test.expected {
+				t.Errorf("rtfText(%q) = %q, want %q", test.input, got, test.expected)
+			}
+		})
+	}
+}
+
+func TestExtractRTFText(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "letter.rtf")
+	content := `{\rtf1\ansi{\info{\title Draft}}{\stylesheet{\s0 Normal;}}Dear team,\par The budget is {\b approved}.\par}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	text, err := NewManager().ExtractText(context.Background(), path)
+	if err != nil {
+		t.Fatalf("ExtractText failed: %v", err)
+	}
+	if text != "Dear team, The budget is approved." {
+		t.Errorf("Unexpected text: %q", text)
+	}
+}
+
+func TestExtractODTText(t *testing.T) {
+	// Saved without the .odt extension to check the mimetype entry is sniffed
+	path := filepath.Join(t.TempDir(), "minutes")
+	writeTestZip(t, path,
+		[2]string{"mimetype", string(odtMimeType)},
+		[2]string{"content.xml", `<?xml version="1.0" encoding="UTF-8"?><office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0"><office:body><office:text><text:h>Minutes</text:h><text:p>Attendance was full.</text:p></office:text></office:body></office:document-content>`},
+	)
+
+	manager := NewManager()
+	if fileType := manager.detectFileType(path); fileType != DocumentTypeODT {
+		t.Fatalf("Expected ODT detection, got %v", fileType)
+	}
+	text, err := manager.ExtractText(context.Background(), path)
+	if err != nil {
+		t.Fatalf("ExtractText failed: %v", err)
+	}
+	if !strings.Contains(text, "Minutes") || !strings.Contains(text, "Attendance was full.") {
+		t.Errorf("Unexpected text: %q", text)
+	}
+}
+
+func TestExtractEPUBText(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "book.epub")
+	writeTestZip(t, path,
+		[2]string{"mimetype", string(epubMimeType)},
+		[2]string{"META-INF/container.xml", `<?xml version="1.0"?><container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container"><rootfiles><rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/></rootfiles></container>`},
+		[2]string{"OEBPS/content.opf", `<?xml version="1.0"?><package xmlns="http://www.idpf.org/2007/opf" version="3.0"><manifest>
+			<item id="ch2" href="text/two.xhtml" media-type="application/xhtml+xml"/>
+			<item id="ch1" href="text/one.xhtml" media-type="application/xhtml+xml"/>
+			<item id="css" href="style.css" media-type="text/css"/>
+		</manifest><spine><itemref idref="ch1"/><itemref idref="css"/><itemref idref="ch2"/></spine></package>`},
+		// Chapter two is stored first; the spine decides the reading order
+		[2]string{"OEBPS/text/two.xhtml", `<html><body><h1>Chapter Two</h1><p>The end.</p></body></html>`},
+		[2]string{"OEBPS/text/one.xhtml", `<html><head><title>Ignored</title><style>p { color: red }</style></head><body><h1>Chapter One</h1><p>It was a <em>dark</em> night.</p><script>alert(1)</script></body></html>`},
+	)
+
+	text, err := NewManager().ExtractText(context.Background(), path)
+	if err != nil {
+		t.Fatalf("ExtractText failed: %v", err)
+	}
+	if text != "Chapter One It was a dark night.\n\nChapter Two The end." {
+		t.Errorf("Unexpected text: %q", text)
+	}
+}
+
+func TestExtractEPUBTextWithoutContainer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "loose.epub")
+	writeTestZip(t, path,
+		[2]string{"mimetype", string(epubMimeType)},
+		[2]string{"b.html", `<p>Second</p>`},
+		[2]string{"a.html", `<p>First</p>`},
+	)
+
+	text, err := NewManager().ExtractText(context.Background(), path)
+	if err != nil {
+		t.Fatalf("ExtractText failed: %v", err)
+	}
+	if text != "First\n\nSecond" {
+		t.Errorf("Unexpected text: %q", text)
+	}
+}
+
+func TestHTMLText(t *testing.T) {
+	text, err := htmlText(strings.NewReader(`<table><tr><td>a</td><td>b</td></tr></table><p>x &amp; y<br/>z</p>`), false)
+	if err != nil {
+		t.Fatalf("htmlText failed: %v", err)
+	}
+	if !strings.Contains(text, "a\t") || !strings.Contains(text, "x & y\nz") {
+		t.Errorf("Unexpected text: %q", text)
+	}
+}
+
+func TestRegisterFormat(t *testing.T) {
+	manager := NewManager()
+
+	var names []string
+	for _, format := range manager.Formats() {
+		names = append(names, format.Name)
+	}
+	if got := strings.Join(names, ","); got != "doc,docx,epub,html,markdown,odt,pdf,ppt,pptx,rtf,text" {
+		t.Errorf("Unexpected built-in formats: %s", got)
+	}
+	if !manager.isSupportedPath("/tmp/Report.RTF") || manager.isSupportedPath("/tmp/archive.zip") {
+		t.Error("isSupportedPath should follow the registered extensions case-insensitively")
+	}
+
+	// Replacing a built-in extractor takes effect for ExtractText
+	manager.RegisterFormat(Format{Type: DocumentTypeRTF, Name: "rtf", Extensions: []string{".rtf"},
+		Extract: func(_ context.Context, filePath string) (string, error) { return "custom", nil }})
+	path := filepath.Join(t.TempDir(), "x.rtf")
+	if err := os.WriteFile(path, []byte(`{\rtf1 original}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if text, err := manager.ExtractText(context.Background(), path); err != nil || text != "custom" {
+		t.Errorf("Expected the replacement extractor, got %q, %v", text, err)
+	}
+}
+
+func TestExtractHTMLText(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "page.htm")
+	content := `<!DOCTYPE html><html><head><title>Release Notes</title><meta name="author" content="Docs Team"><style>h1{}</style></head>
+<body><h1>Release   Notes</h1><p>See the <a href="https://example.com/changelog">changelog</a> or
+<a href="https://example.com">https://example.com</a>.</p><h2>Fixes</h2><ul><li>Faster <a href="#sync">sync</a></li></ul><script>track()</script></body></html>`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	manager := NewManager()
+	text, err := manager.ExtractText(context.Background(), path)
+	if err != nil {
+		t.Fatalf("ExtractText failed: %v", err)
+	}
+	expected := "# Release Notes\n\nSee the changelog (https://example.com/changelog) or\nhttps://example.com.\n\n## Fixes\n\nFaster sync"
+	if text != expected {
+		t.Errorf("Unexpected text:\n%q\nwant\n%q", text, expected)
+	}
+
+	info, err := manager.GetDocumentInfo(context.Background(), path)
+	if err != nil {
+		t.Fatalf("GetDocumentInfo failed: %v", err)
+	}
+	if info.Metadata["title"] != "Release Notes" || info.Metadata["author"] != "Docs Team" {
+		t.Errorf("Unexpected metadata: %v", info.Metadata)
+	}
+}
+
+func TestDetectHTMLWithoutExtension(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "export")
+	if err := os.WriteFile(path, []byte("\xEF\xBB\xBF\n<HTML><body>Hi</body></HTML>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if fileType := NewManager().detectFileType(path); fileType != DocumentTypeHTML {
+		t.Errorf("Expected HTML detection, got %v", fileType)
+	}
+}
+
+func TestExtractMarkdownText(t *testing.T) {
+	dir := t.TempDir()
+	manager := NewManager()
+
+	path := filepath.Join(dir, "wiki.md")
+	content := "---\r\ntitle: \"Onboarding Guide\"\r\ntags: [hr, new-starters]\r\nnested:\r\n  key: ignored\r\n---\r\n# Welcome\r\n\r\n    indented code\r\n\r\nSee <https://wiki.example.com>.\r\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	text, err := manager.ExtractText(context.Background(), path)
+	if err != nil {
+		t.Fatalf("ExtractText failed: %v", err)
+	}
+	if text != "# Welcome\n\n    indented code\n\nSee <https://wiki.example.com>." {
+		t.Errorf("Unexpected text: %q", text)
+	}
+
+	info, err := manager.GetDocumentInfo(context.Background(), path)
+	if err != nil {
+		t.Fatalf("GetDocumentInfo failed: %v", err)
+	}
+	if info.Metadata["title"] != "Onboarding Guide" || info.Metadata["tags"] != "[hr, new-starters]" || info.Metadata["key"] != "" {
+		t.Errorf("Unexpected metadata: %v", info.Metadata)
+	}
+
+	// Without front matter the first heading is the title; a short file is still text
+	path = filepath.Join(dir, "notes.markdown")
+	if err := os.WriteFile(path, []byte("Intro\n# Agenda\n---\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if info, err = manager.GetDocumentInfo(context.Background(), path); err != nil || info.Metadata["title"] != "Agenda" {
+		t.Errorf("Expected the heading as title, got %v, %v", info, err)
+	}
+	short := filepath.Join(dir, "x.md")
+	if err := os.WriteFile(short, []byte("Hi"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if text, err := manager.ExtractText(context.Background(), short); err != nil || text != "Hi" {
+		t.Errorf("Expected a short Markdown file to extract, got %q, %v", text, err)
+	}
+}
+
+func TestSplitFrontMatter(t *testing.T) {
+	tests := []struct {
+		input, frontMatter, body string
+	}{
+		{"---\na: 1\n---\nbody", "a: 1\n", "body"},
+		{"---\na: 1\n...\n", "a: 1\n", ""},
+		{"---\nunterminated", "", "---\nunterminated"},
+		{"no front matter\n---\n", "", "no front matter\n---\n"},
+	}
+	for _, test := range tests {
+		frontMatter, body := splitFrontMatter(test.input)
+		if frontMatter != test.frontMatter || body != test.body {
+			t.Errorf("splitFrontMatter(%q) = %q, %q", test.input, frontMatter, body)
+		}
+	}
+}
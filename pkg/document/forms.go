@@ -0,0 +1,252 @@
+package document
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// maxFormFields bounds field tree traversal, since malformed PDFs can link a
+// field back to its ancestors
+const maxFormFields = 10000
+
+// Field flags of PDF 32000-1:2008, §12.7.3.1 and §12.7.4
+const (
+	pdfFieldReadOnly   = 1 << 0
+	pdfFieldRequired   = 1 << 1
+	pdfFieldRadio      = 1 << 15
+	pdfFieldPushButton = 1 << 16
+	pdfFieldCombo      = 1 << 17
+)
+
+// FormField is an interactive form field of a PDF. Value is the text of text
+// fields, the chosen option of choice fields (several joined with "; "), and
+// the export value of the selected check box or radio button, "Off" when none
+// is selected.
+type FormField struct {
+	Name     string   `json:"name"` // Fully qualified: parent names joined with "."
+	Type     string   `json:"type"` // "text", "checkbox", "radio", "button", "combo", "list" or "signature"
+	Value    string   `json:"value"`
+	Options  []string `json:"options,omitempty"` // Choices, or the export values of check boxes and radio buttons
+	Required bool     `json:"required,omitempty"`
+	ReadOnly bool     `json:"read_only,omitempty"`
+	Page     int      `json:"page,omitempty"`
+}
+
+// FormFields lists the fields of a PDF form and which of them are still empty
+type FormFields struct {
+	FilePath        string      `json:"file_path"`
+	Fields          []FormField `json:"fields"`
+	Filled          int         `json:"filled"`
+	Unfilled        []string    `json:"unfilled"`         // Fields that take input but have no value
+	MissingRequired []string    `json:"missing_required"` // Required fields that have no value
+}
+
+// IsFilled reports whether a field has a value; unchecked buttons and push
+// buttons don't
+func (f FormField) IsFilled() bool {
+	return f.Value != "" && f.Value != "Off"
+}
+
+// GetFormFields lists the AcroForm fields of a PDF with their type, current
+// value and options, and reports the fields left empty
+func (m *Manager) GetFormFields(filePath string) (*FormFields, error) {
+	if err := m.checkFileSize(filePath); err != nil {
+		return nil, err
+	}
+	if m.detectFileType(filePath) != DocumentTypePDF {
+		return nil, fmt.Errorf("form field extraction supports .pdf files only: %s", filePath)
+	}
+
+	fields, err := pdfFormFields(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &FormFields{FilePath: filePath, Fields: fields, Unfilled: []string{}, MissingRequired: []string{}}
+	for _, field := range fields {
+		switch {
+		case field.IsFilled():
+			result.Filled++
+		case field.Type == "button":
+			// Push buttons take no input
+		default:
+			result.Unfilled = append(result.Unfilled, field.Name)
+			if field.Required {
+				result.MissingRequired = append(result.MissingRequired, field.Name)
+			}
+		}
+	}
+	return result, nil
+}
+
+// pdfFormFields walks the field tree of a PDF's interactive form. Fields
+// inherit their type, flags and value from their ancestors, and their widget
+// annotations place them on pages.
+func pdfFormFields(filePath string) ([]FormField, error) {
+	var fields []FormField
+	err := withPDF(filePath, "", func(reader *pdf.Reader) error {
+		// Widgets are matched to the pages listing them by their dictionary, as
+		// the reader doesn't expose object identity
+		widgetPages := make(map[string]int)
+		for number := 1; number <= reader.NumPage(); number++ {
+			annotations := reader.Page(number).V.Key("Annots")
+			for i := 0; i < annotations.Len(); i++ {
+				if annotation := annotations.Index(i); annotation.Key("Subtype").Name() == "Widget" {
+					widgetPages[annotation.String()] = number
+				}
+			}
+		}
+
+		type inherited struct {
+			name, fieldType string
+			flags           int64
+			value           pdf.Value
+		}
+		var walk func(field pdf.Value, parent inherited, depth int)
+		walk = func(field pdf.Value, parent inherited, depth int) {
+			if field.Kind() != pdf.Dict || len(fields) >= maxFormFields || depth > 32 {
+				return
+			}
+
+			current := parent
+			if name := field.Key("T").Text(); name != "" {
+				current.name = strings.TrimPrefix(parent.name+"."+name, ".")
+			}
+			if fieldType := field.Key("FT").Name(); fieldType != "" {
+				current.fieldType = fieldType
+			}
+			if flags := field.Key("Ff"); flags.Kind() == pdf.Integer {
+				current.flags = flags.Int64()
+			}
+			if value := field.Key("V"); !value.IsNull() {
+				current.value = value
+			}
+
+			// Kids with names are fields of their own; kids without are the
+			// widgets of this field
+			kids := field.Key("Kids")
+			var widgets []pdf.Value
+			hasFieldKids := false
+			for i := 0; i < kids.Len(); i++ {
+				if kid := kids.Index(i); kid.Key("T").Kind() == pdf.String {
+					hasFieldKids = true
+					walk(kid, current, depth+1)
+				} else {
+					widgets = append(widgets, kid)
+				}
+			}
+			if hasFieldKids || current.fieldType == "" {
+				return
+			}
+			if len(widgets) == 0 {
+				widgets = []pdf.Value{field} // Field and widget merged in one dictionary
+			}
+
+			formField := FormField{
+				Name:     current.name,
+				Type:     pdfFieldType(current.fieldType, current.flags),
+				Value:    pdfFieldValue(current.value),
+				Required: current.flags&pdfFieldRequired != 0,
+				ReadOnly: current.flags&pdfFieldReadOnly != 0,
+				Options:  pdfFieldOptions(current.fieldType, field, widgets),
+			}
+			for _, widget := range widgets {
+				if page, ok := widgetPages[widget.String()]; ok {
+					formField.Page = page
+					break
+				}
+			}
+			fields = append(fields, formField)
+		}
+
+		acroForm := reader.Trailer().Key("Root").Key("AcroForm")
+		topLevel := acroForm.Key("Fields")
+		for i := 0; i < topLevel.Len(); i++ {
+			walk(topLevel.Index(i), inherited{}, 0)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// pdfFieldType names a field's type from its FT entry and flags
+func pdfFieldType(fieldType string, flags int64) string {
+	switch fieldType {
+	case "Tx":
+		return "text"
+	case "Btn":
+		switch {
+		case flags&pdfFieldPushButton != 0:
+			return "button"
+		case flags&pdfFieldRadio != 0:
+			return "radio"
+		}
+		return "checkbox"
+	case "Ch":
+		if flags&pdfFieldCombo != 0 {
+			return "combo"
+		}
+		return "list"
+	case "Sig":
+		return "signature"
+	}
+	return strings.ToLower(fieldType)
+}
+
+// pdfFieldValue renders a field value: text, a name for buttons, an array of
+// selected options, or a signature dictionary
+func pdfFieldValue(value pdf.Value) string {
+	switch value.Kind() {
+	case pdf.String:
+		return strings.TrimSpace(value.Text())
+	case pdf.Name:
+		return value.Name()
+	case pdf.Array:
+		var values []string
+		for i := 0; i < value.Len(); i++ {
+			if text := pdfFieldValue(value.Index(i)); text != "" {
+				values = append(values, text)
+			}
+		}
+		return strings.Join(values, "; ")
+	case pdf.Dict:
+		return "signed" // A signature field's value is its signature dictionary
+	}
+	return ""
+}
+
+// pdfFieldOptions lists the choices of a choice field (their display text)
+// or the export values of a button field's widgets, from their appearances
+func pdfFieldOptions(fieldType string, field pdf.Value, widgets []pdf.Value) []string {
+	var options []string
+	if fieldType != "Ch" && fieldType != "Btn" {
+		return nil
+	}
+	opt := field.Key("Opt")
+	for i := 0; i < opt.Len(); i++ {
+		option := opt.Index(i)
+		if option.Kind() == pdf.Array && option.Len() == 2 {
+			option = option.Index(1) // [export value, display text]
+		}
+		options = append(options, option.Text())
+	}
+	if len(options) > 0 || fieldType == "Ch" {
+		return options
+	}
+
+	seen := make(map[string]bool)
+	for _, widget := range widgets {
+		for _, state := range widget.Key("AP").Key("N").Keys() {
+			if state != "Off" && !seen[state] {
+				seen[state] = true
+				options = append(options, state)
+			}
+		}
+	}
+	return options
+}
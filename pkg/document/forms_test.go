@@ -0,0 +1,58 @@
+package document
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGetFormFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "application.pdf")
+	// The page is object 4; form fields start at object 6
+	writeTestPDFObjects(t, path, testPDF{
+		Catalog: "/AcroForm << /Fields [6 0 R 7 0 R 8 0 R 10 0 R 12 0 R 13 0 R] >>",
+		Page:    "/Annots [6 0 R 7 0 R 9 0 R 11 0 R 12 0 R] ",
+		Extra: []string{
+			"<< /Type /Annot /Subtype /Widget /FT /Tx /T (name) /Ff 2 /V (Jane Doe) /Rect [72 700 300 720] >>",
+			"<< /Type /Annot /Subtype /Widget /FT /Tx /T (email) /Ff 2 /Rect [72 650 300 670] >>",
+			"<< /T (address) /FT /Tx /Kids [9 0 R] >>",
+			"<< /Type /Annot /Subtype /Widget /T (city) /Parent 8 0 R /V (Oslo) /Rect [72 600 300 620] >>",
+			"<< /FT /Btn /T (agree) /V /Off /Kids [11 0 R] >>",
+			"<< /Type /Annot /Subtype /Widget /Parent 10 0 R /AS /Off /AP << /N << /Yes << >> /Off << >> >> >> /Rect [72 550 90 570] >>",
+			"<< /Type /Annot /Subtype /Widget /FT /Ch /T (country) /Ff 131072 /Opt [(Norway) [(SE) (Sweden)]] /V (SE) /Rect [72 500 300 520] >>",
+			"<< /FT /Btn /T (submit) /Ff 65536 >>",
+		},
+	}, "BT /F1 12 Tf 72 740 Td (Application form) Tj ET")
+
+	form, err := NewManager().GetFormFields(path)
+	if err != nil {
+		t.Fatalf("GetFormFields failed: %v", err)
+	}
+
+	expected := []FormField{
+		{Name: "name", Type: "text", Value: "Jane Doe", Required: true, Page: 1},
+		{Name: "email", Type: "text", Required: true, Page: 1},
+		{Name: "address.city", Type: "text", Value: "Oslo", Page: 1},
+		{Name: "agree", Type: "checkbox", Value: "Off", Options: []string{"Yes"}, Page: 1},
+		{Name: "country", Type: "combo", Value: "SE", Options: []string{"Norway", "Sweden"}, Page: 1},
+		{Name: "submit", Type: "button"},
+	}
+	if !reflect.DeepEqual(form.Fields, expected) {
+		t.Errorf("Fields =\n%+v\nwant\n%+v", form.Fields, expected)
+	}
+	if form.Filled != 3 || !reflect.DeepEqual(form.Unfilled, []string{"email", "agree"}) || !reflect.DeepEqual(form.MissingRequired, []string{"email"}) {
+		t.Errorf("Unexpected summary: filled %d, unfilled %v, missing %v", form.Filled, form.Unfilled, form.MissingRequired)
+	}
+}
+
+func TestGetFormFieldsUnsupportedFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("plain"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewManager().GetFormFields(path); err == nil || !strings.Contains(err.Error(), "supports .pdf files only") {
+		t.Errorf("Expected an unsupported format error, got %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+package document
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// FuzzExtractCleanTextFromXML exercises the DOCX XML cleaner with arbitrary input,
+// since document bodies are attacker-controllable (email attachments, downloads).
+func FuzzExtractCleanTextFromXML(f *testing.F) {
+	seeds := []string{
+		`<w:p><w:r><w:t>Hello World</w:t></w:r></w:p>`,
+		`<w:p><w:r><w:t>Unclosed`,
+		`<!DOCTYPE x [<!ENTITY a "aaaa">]><x>&a;&a;&unknown;</x>`,
+		`<?xml version="1.0" encoding="latin1"?><x>caf\xe9</x>`,
+		"<x>\x00\x01text\x1f</x>",
+		`<<<>>>`,
+		``,
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	manager := NewManager()
+	f.Fuzz(func(t *testing.T, input string) {
+		result, err := manager.extractCleanTextFromXML(input)
+		if err != nil {
+			return
+		}
+		assertCleanText(t, input, result)
+	})
+}
+
+// FuzzCleanExtractedText checks the text normalization invariants on arbitrary input
+func FuzzCleanExtractedText(f *testing.F) {
+	f.Add("Text   with    multiple   spaces")
+	f.Add("Text with <remaining>tags</remaining> here")
+	f.Add("Text\x00with\x08control\x1Fcharacters")
+	f.Add("<a<b>>c")
+
+	manager := NewManager()
+	f.Fuzz(func(t *testing.T, input string) {
+		assertCleanText(t, input, manager.cleanExtractedText(input))
+	})
+}
+
+func assertCleanText(t *testing.T, input, result string) {
+	t.Helper()
+
+	if result != strings.TrimSpace(result) {
+		t.Errorf("result has surrounding whitespace for input %q: %q", input, result)
+	}
+	if controlPattern.MatchString(result) {
+		t.Errorf("result contains control characters for input %q: %q", input, result)
+	}
+	if strings.Contains(result, "  ") {
+		t.Errorf("result contains repeated spaces for input %q: %q", input, result)
+	}
+	if utf8.ValidString(input) && !utf8.ValidString(result) {
+		t.Errorf("valid UTF-8 input produced invalid UTF-8 output: %q", result)
+	}
+}
+
+// FuzzPptCollectText walks arbitrary PowerPoint record streams; record lengths
+// come straight from the file and must never be trusted
+func FuzzPptCollectText(f *testing.F) {
+	f.Add([]byte{0x0F, 0x00, 0xE8, 0x03, 0x10, 0x00, 0x00, 0x00, 0x00, 0x00, 0xA8, 0x0F, 0x02, 0x00, 0x00, 0x00, 'h', 'i'})
+	f.Add([]byte{0x0F, 0x00, 0xE8, 0x03, 0xFF, 0xFF, 0xFF, 0xFF})
+	f.Add([]byte{})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var text strings.Builder
+		pptCollectText(data, 0, &text)
+	})
+}
+
+// FuzzDocPieceTable parses arbitrary Clx structures from the DOC table stream
+func FuzzDocPieceTable(f *testing.F) {
+	f.Add([]byte{0x02, 0x10, 0x00, 0x00, 0x00, 0, 0, 0, 0, 5, 0, 0, 0, 0, 0, 0, 4, 0, 0, 0, 0, 0})
+	f.Add([]byte{0x01, 0xFF, 0xFF})
+	f.Add([]byte{0x01, 0x05, 0x00})
+
+	f.Fuzz(func(t *testing.T, clx []byte) {
+		docPieceTable(clx)
+	})
+}
+
+// FuzzRTFText interprets arbitrary RTF; control word parameters come straight
+// from the file and must never be trusted
+func FuzzRTFText(f *testing.F) {
+	f.Add([]byte(`{\rtf1\ansi{\fonttbl{\f0 Arial;}}Hello\par \'e9\u252?}`))
+	f.Add([]byte(`{\rtf1 \bin99999999999999999999 x}`))
+	f.Add([]byte(`\u-1\uc-5\'`))
+	f.Add([]byte{})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		rtfText(data)
+	})
+}
+
+// FuzzDecodeText checks that any byte sequence decodes to valid UTF-8
+func FuzzDecodeText(f *testing.F) {
+	f.Add([]byte("plain text"))
+	f.Add([]byte{0xFF, 0xFE, 'h', 0, 'i'})
+	f.Add([]byte{0xFF, 0xFE, 0x00, 0x00, 0x00, 0xD8})
+	f.Add([]byte{'a', 0, 'b'})
+	f.Add([]byte{0xE9, 0x80})
+
+	f.Fuzz(func(t *testing.T, content []byte) {
+		text, _, err := decodeText(content)
+		if err == nil && !utf8.ValidString(text) {
+			t.Errorf("decodeText produced invalid UTF-8: %q", text)
+		}
+	})
+}
+
+// FuzzChunkText checks that chunking always terminates, respects the size
+// limit and covers all text up to trailing whitespace
+func FuzzChunkText(f *testing.F) {
+	f.Add("One. Two three.\n\nFour", 8, 3)
+	f.Add("nospaceshere", 3, 2)
+	f.Add("", 10, 0)
+
+	f.Fuzz(func(t *testing.T, text string, size, overlap int) {
+		if size <= 0 || size > 1000 || overlap < 0 || overlap >= size {
+			t.Skip()
+		}
+		chunks := chunkText(text, size, overlap)
+		for i, chunk := range chunks {
+			if chunk.End-chunk.Start > size || chunk.End <= chunk.Start {
+				t.Fatalf("chunk %d has invalid bounds %d-%d", i, chunk.Start, chunk.End)
+			}
+		}
+		runes := []rune(text)
+		covered := 0
+		if len(chunks) > 0 {
+			covered = chunks[len(chunks)-1].End
+		}
+		if strings.TrimSpace(string(runes[covered:])) != "" {
+			t.Fatalf("chunks stop at %d of %d characters", covered, len(runes))
+		}
+	})
+}
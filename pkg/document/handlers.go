@@ -2,13 +2,21 @@ package document
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
 
+	"github.com/kevsmith/my-mcp/pkg/shared"
+	"github.com/kevsmith/my-mcp/pkg/shared/sandbox"
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
 )
 
 type Handlers struct {
 	documentManager *Manager
+	paths           *sandbox.Sandbox // Nil when any path may be read
 }
 
 func NewHandlers(documentManager *Manager) *Handlers {
@@ -17,52 +25,817 @@ func NewHandlers(documentManager *Manager) *Handlers {
 	}
 }
 
+// NewHandlersWithRoots creates handlers that only accept paths under the
+// allowed roots; with no roots any path is accepted
+func NewHandlersWithRoots(documentManager *Manager, allowedRoots []string) (*Handlers, error) {
+	paths, err := sandbox.Optional(allowedRoots)
+	if err != nil {
+		return nil, err
+	}
+	handlers := NewHandlers(documentManager)
+	handlers.paths = paths
+	return handlers, nil
+}
+
 func (h *Handlers) ExtractText(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	filePath := request.GetString("file_path", "")
 	if filePath == "" {
-		return mcp.NewToolResultError("file_path parameter is required"), nil
+		return shared.ErrorResultf(shared.CodeInvalidArgument, "file_path parameter is required"), nil
+	}
+	if err := h.paths.Check(filePath); err != nil {
+		return shared.ErrorResult(err), nil
+	}
+	if password := request.GetString("password", ""); password != "" {
+		ctx = WithPassword(ctx, password)
+	}
+	if notify := progressNotifier(ctx, request); notify != nil {
+		ctx = withPageProgress(ctx, notify)
+	}
+
+	options := ChunkOptions{
+		MaxChars:  request.GetInt("max_chars", 0),
+		MaxTokens: request.GetInt("max_tokens", 0),
+		Overlap:   request.GetInt("overlap", 0),
+	}
+	includeReview := request.GetBool("include_review", false)
+	if options.MaxChars != 0 || options.MaxTokens != 0 {
+		if includeReview {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "include_review cannot be combined with chunking; use extract_review"), nil
+		}
+		return h.extractChunks(ctx, filePath, options, request.GetInt("chunk_index", -1))
+	}
+
+	text, err := h.documentManager.ExtractText(ctx, filePath)
+	if err != nil {
+		return shared.ErrorResult(err), nil
+	}
+
+	review := ""
+	if includeReview {
+		content, err := h.documentManager.ExtractReview(filePath)
+		if err != nil {
+			return shared.ErrorResult(err), nil
+		}
+		review = formatReview(content)
+	}
+
+	if text == "" && review == "" {
+		return mcp.NewToolResultText("No text content found in the document"), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Extracted text from %s:\n\n%s%s", filePath, text, review)), nil
+}
+
+// extractChunks returns every chunk of a document, or only the one at chunkIndex
+func (h *Handlers) extractChunks(ctx context.Context, filePath string, options ChunkOptions, chunkIndex int) (*mcp.CallToolResult, error) {
+	chunks, err := h.documentManager.ExtractChunks(ctx, filePath, options)
+	if err != nil {
+		return shared.ErrorResult(err), nil
+	}
+	if len(chunks) == 0 {
+		return mcp.NewToolResultText("No text content found in the document"), nil
+	}
+
+	var result any = chunks
+	if chunkIndex >= 0 {
+		if chunkIndex >= len(chunks) {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "chunk_index %d is out of range: document has %d chunks", chunkIndex, len(chunks)), nil
+		}
+		result = chunks[chunkIndex]
+	}
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return shared.ErrorResultFromErr("Failed to marshal chunks", err), nil
+	}
+
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
+
+func (h *Handlers) ConvertToMarkdown(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	filePath := request.GetString("file_path", "")
+	if filePath == "" {
+		return shared.ErrorResultf(shared.CodeInvalidArgument, "file_path parameter is required"), nil
+	}
+	if err := h.paths.Check(filePath); err != nil {
+		return shared.ErrorResult(err), nil
+	}
+
+	markdown, err := h.documentManager.ConvertToMarkdown(ctx, filePath)
+	if err != nil {
+		return shared.ErrorResult(err), nil
+	}
+	if markdown == "" {
+		return mcp.NewToolResultText("No text content found in the document"), nil
+	}
+
+	return mcp.NewToolResultText(markdown), nil
+}
+
+func (h *Handlers) AnalyzeDocument(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	filePath := request.GetString("file_path", "")
+	if filePath == "" {
+		return shared.ErrorResultf(shared.CodeInvalidArgument, "file_path parameter is required"), nil
+	}
+	if err := h.paths.Check(filePath); err != nil {
+		return shared.ErrorResult(err), nil
 	}
 
-	text, err := h.documentManager.ExtractText(filePath)
+	analysis, err := h.documentManager.AnalyzeDocument(ctx, filePath, request.GetBool("include_text", true))
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return shared.ErrorResult(err), nil
+	}
+
+	analysisJSON, err := json.MarshalIndent(analysis, "", "  ")
+	if err != nil {
+		return shared.ErrorResultFromErr("Failed to marshal analysis", err), nil
+	}
+	return mcp.NewToolResultText(string(analysisJSON)), nil
+}
+
+func (h *Handlers) ExtractFromURL(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	rawURL := request.GetString("url", "")
+	if rawURL == "" {
+		return shared.ErrorResultf(shared.CodeInvalidArgument, "url parameter is required"), nil
+	}
+	if password := request.GetString("password", ""); password != "" {
+		ctx = WithPassword(ctx, password)
 	}
 
+	text, err := h.documentManager.ExtractFromURL(ctx, rawURL)
+	if err != nil {
+		return shared.ErrorResult(err), nil
+	}
 	if text == "" {
 		return mcp.NewToolResultText("No text content found in the document"), nil
 	}
 
-	return mcp.NewToolResultText(fmt.Sprintf("Extracted text from %s:\n\n%s", filePath, text)), nil
+	return mcp.NewToolResultText(fmt.Sprintf("Extracted text from %s:\n\n%s", rawURL, text)), nil
+}
+
+func (h *Handlers) GetFormFields(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	filePath := request.GetString("file_path", "")
+	if filePath == "" {
+		return shared.ErrorResultf(shared.CodeInvalidArgument, "file_path parameter is required"), nil
+	}
+	if err := h.paths.Check(filePath); err != nil {
+		return shared.ErrorResult(err), nil
+	}
+
+	form, err := h.documentManager.GetFormFields(filePath)
+	if err != nil {
+		return shared.ErrorResult(err), nil
+	}
+	if len(form.Fields) == 0 {
+		return mcp.NewToolResultText("No form fields found in the document"), nil
+	}
+
+	formJSON, err := json.MarshalIndent(form, "", "  ")
+	if err != nil {
+		return shared.ErrorResultFromErr("Failed to marshal form fields", err), nil
+	}
+	return mcp.NewToolResultText(string(formJSON)), nil
+}
+
+func (h *Handlers) StartExtraction(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	filePath := request.GetString("file_path", "")
+	if filePath == "" {
+		return shared.ErrorResultf(shared.CodeInvalidArgument, "file_path parameter is required"), nil
+	}
+	if err := h.paths.Check(filePath); err != nil {
+		return shared.ErrorResult(err), nil
+	}
+	if password := request.GetString("password", ""); password != "" {
+		ctx = WithPassword(ctx, password)
+	}
+
+	status, err := h.documentManager.StartExtraction(ctx, filePath)
+	if err != nil {
+		return shared.ErrorResult(err), nil
+	}
+	return extractionJSON(status, "extraction status")
+}
+
+func (h *Handlers) GetExtractionStatus(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	jobID := request.GetString("job_id", "")
+	if jobID == "" {
+		return shared.ErrorResultf(shared.CodeInvalidArgument, "job_id parameter is required"), nil
+	}
+
+	status, err := h.documentManager.GetExtractionStatus(jobID)
+	if err != nil {
+		return shared.ErrorResult(err), nil
+	}
+	return extractionJSON(status, "extraction status")
+}
+
+func (h *Handlers) GetExtractionChunk(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	jobID := request.GetString("job_id", "")
+	if jobID == "" {
+		return shared.ErrorResultf(shared.CodeInvalidArgument, "job_id parameter is required"), nil
+	}
+	index := request.GetInt("chunk_index", -1)
+	if index < 0 {
+		return shared.ErrorResultf(shared.CodeInvalidArgument, "chunk_index parameter is required"), nil
+	}
+
+	chunk, err := h.documentManager.GetExtractionChunk(jobID, index)
+	if err != nil {
+		return shared.ErrorResult(err), nil
+	}
+	return extractionJSON(chunk, "extraction chunk")
+}
+
+// extractionJSON renders a background extraction result
+func extractionJSON(value any, what string) (*mcp.CallToolResult, error) {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return shared.ErrorResultFromErr("Failed to marshal "+what, err), nil
+	}
+	return mcp.NewToolResultText(string(data)), nil
+}
+
+// progressNotifier returns a page callback sending an MCP progress
+// notification every ProgressPageInterval pages when the client asked for
+// progress with a token, else nil
+func progressNotifier(ctx context.Context, request mcp.CallToolRequest) pageFunc {
+	if request.Params.Meta == nil || request.Params.Meta.ProgressToken == nil {
+		return nil
+	}
+	mcpServer := server.ServerFromContext(ctx)
+	if mcpServer == nil {
+		return nil
+	}
+	token := request.Params.Meta.ProgressToken
+	return func(page, total int, _ string) {
+		if page%ProgressPageInterval != 0 && page != total {
+			return
+		}
+		// Progress is advisory; a client that went away doesn't stop extraction
+		_ = mcpServer.SendNotificationToClient(ctx, "notifications/progress", map[string]any{
+			"progressToken": token,
+			"progress":      page,
+			"total":         total,
+			"message":       fmt.Sprintf("Extracted page %d of %d", page, total),
+		})
+	}
+}
+
+func (h *Handlers) GetEmbeddedWorkbooks(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	filePath := request.GetString("file_path", "")
+	if filePath == "" {
+		return shared.ErrorResultf(shared.CodeInvalidArgument, "file_path parameter is required"), nil
+	}
+	if err := h.paths.Check(filePath); err != nil {
+		return shared.ErrorResult(err), nil
+	}
+	outputDir := request.GetString("output_dir", "")
+	if outputDir != "" {
+		if err := h.paths.Check(outputDir); err != nil {
+			return shared.ErrorResult(err), nil
+		}
+	}
+
+	workbooks, err := h.documentManager.GetEmbeddedWorkbooks(filePath, request.GetInt("max_rows", 0), outputDir)
+	if err != nil {
+		return shared.ErrorResult(err), nil
+	}
+	if len(workbooks.Workbooks) == 0 {
+		return mcp.NewToolResultText("No embedded workbooks found in the document"), nil
+	}
+
+	workbooksJSON, err := json.MarshalIndent(workbooks, "", "  ")
+	if err != nil {
+		return shared.ErrorResultFromErr("Failed to marshal embedded workbooks", err), nil
+	}
+	return mcp.NewToolResultText(string(workbooksJSON)), nil
+}
+
+func (h *Handlers) ExtractReview(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	filePath := request.GetString("file_path", "")
+	if filePath == "" {
+		return shared.ErrorResultf(shared.CodeInvalidArgument, "file_path parameter is required"), nil
+	}
+	if err := h.paths.Check(filePath); err != nil {
+		return shared.ErrorResult(err), nil
+	}
+
+	review, err := h.documentManager.ExtractReview(filePath)
+	if err != nil {
+		return shared.ErrorResult(err), nil
+	}
+	if review.IsEmpty() {
+		return mcp.NewToolResultText("No comments, notes or tracked changes found in the document"), nil
+	}
+
+	reviewJSON, err := json.MarshalIndent(review, "", "  ")
+	if err != nil {
+		return shared.ErrorResultFromErr("Failed to marshal review content", err), nil
+	}
+	return mcp.NewToolResultText(string(reviewJSON)), nil
 }
 
 func (h *Handlers) GetDocumentInfo(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	filePath := request.GetString("file_path", "")
 	if filePath == "" {
-		return mcp.NewToolResultError("file_path parameter is required"), nil
+		return shared.ErrorResultf(shared.CodeInvalidArgument, "file_path parameter is required"), nil
+	}
+	if err := h.paths.Check(filePath); err != nil {
+		return shared.ErrorResult(err), nil
+	}
+	if password := request.GetString("password", ""); password != "" {
+		ctx = WithPassword(ctx, password)
 	}
 
-	info, err := h.documentManager.GetDocumentInfo(filePath)
+	info, err := h.documentManager.GetDocumentInfo(ctx, filePath)
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return shared.ErrorResult(err), nil
 	}
 
 	supportedText := "No"
 	if info.IsSupported {
 		supportedText = "Yes"
 	}
+	encryptedText := "No"
+	if info.Encrypted {
+		encryptedText = "Yes"
+	}
+	signedText := "No"
+	if len(info.Signatures) > 0 {
+		signedText = "Yes"
+	}
 
 	result := fmt.Sprintf(`Document Information:
 File: %s
 Size: %d bytes
 Modified: %s
 Extension: %s
-Supported: %s`,
+Supported: %s
+Encrypted: %s
+Signed: %s`,
 		info.FilePath,
 		info.FileSize,
 		info.ModTime.Format("2006-01-02 15:04:05"),
 		info.Extension,
 		supportedText,
+		encryptedText,
+		signedText,
+	)
+	result += formatDocumentSecurity(info.DocumentSecurity)
+	result += formatDocumentProperties(info.DocumentProperties)
+
+	keys := make([]string, 0, len(info.Metadata))
+	for key := range info.Metadata {
+		if key != "title" && key != "author" { // Shown with the document properties
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		result += fmt.Sprintf("\n%s: %s", key, info.Metadata[key])
+	}
+
+	return mcp.NewToolResultText(result), nil
+}
+
+func (h *Handlers) ExtractPages(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	filePath := request.GetString("file_path", "")
+	if filePath == "" {
+		return shared.ErrorResultf(shared.CodeInvalidArgument, "file_path parameter is required"), nil
+	}
+	if err := h.paths.Check(filePath); err != nil {
+		return shared.ErrorResult(err), nil
+	}
+
+	extraction, err := h.documentManager.ExtractPages(ctx, filePath, request.GetString("pages", ""))
+	if err != nil {
+		return shared.ErrorResult(err), nil
+	}
+
+	return mcp.NewToolResultText(formatPageExtraction(extraction)), nil
+}
+
+func (h *Handlers) ExtractSlides(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	filePath := request.GetString("file_path", "")
+	if filePath == "" {
+		return shared.ErrorResultf(shared.CodeInvalidArgument, "file_path parameter is required"), nil
+	}
+	if err := h.paths.Check(filePath); err != nil {
+		return shared.ErrorResult(err), nil
+	}
+
+	extraction, err := h.documentManager.ExtractSlides(filePath, request.GetString("slides", ""), request.GetBool("include_hidden", false))
+	if err != nil {
+		return shared.ErrorResult(err), nil
+	}
+
+	return mcp.NewToolResultText(formatSlideExtraction(extraction)), nil
+}
+
+func (h *Handlers) ExtractTables(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	filePath := request.GetString("file_path", "")
+	if filePath == "" {
+		return shared.ErrorResultf(shared.CodeInvalidArgument, "file_path parameter is required"), nil
+	}
+	if err := h.paths.Check(filePath); err != nil {
+		return shared.ErrorResult(err), nil
+	}
+
+	format := request.GetString("format", "json")
+	if format != "json" && format != "csv" {
+		return shared.ErrorResultf(shared.CodeInvalidArgument, "unsupported format: %s (use json or csv)", format), nil
+	}
+
+	tables, err := h.documentManager.ExtractTables(filePath, request.GetString("pages", ""))
+	if err != nil {
+		return shared.ErrorResult(err), nil
+	}
+	if len(tables) == 0 {
+		return mcp.NewToolResultText("No tables found in the document"), nil
+	}
+
+	if format == "csv" {
+		return mcp.NewToolResultText(formatTables(tables)), nil
+	}
+	tablesJSON, err := json.MarshalIndent(tables, "", "  ")
+	if err != nil {
+		return shared.ErrorResultFromErr("Failed to marshal tables", err), nil
+	}
+	return mcp.NewToolResultText(string(tablesJSON)), nil
+}
+
+func (h *Handlers) GetOutline(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	filePath := request.GetString("file_path", "")
+	if filePath == "" {
+		return shared.ErrorResultf(shared.CodeInvalidArgument, "file_path parameter is required"), nil
+	}
+	if err := h.paths.Check(filePath); err != nil {
+		return shared.ErrorResult(err), nil
+	}
+
+	outline, err := h.documentManager.GetOutline(filePath)
+	if err != nil {
+		return shared.ErrorResult(err), nil
+	}
+
+	return mcp.NewToolResultText(formatOutline(outline)), nil
+}
+
+func (h *Handlers) SearchDocument(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	filePath := request.GetString("file_path", "")
+	if filePath == "" {
+		return shared.ErrorResultf(shared.CodeInvalidArgument, "file_path parameter is required"), nil
+	}
+	if err := h.paths.Check(filePath); err != nil {
+		return shared.ErrorResult(err), nil
+	}
+	query := request.GetString("query", "")
+	if query == "" {
+		return shared.ErrorResultf(shared.CodeInvalidArgument, "query parameter is required"), nil
+	}
+
+	result, err := h.documentManager.SearchDocument(ctx, filePath, SearchOptions{
+		Query:         query,
+		Regex:         request.GetBool("regex", false),
+		CaseSensitive: request.GetBool("case_sensitive", false),
+		Context:       request.GetInt("context_chars", 0),
+		MaxResults:    request.GetInt("max_results", 0),
+	})
+	if err != nil {
+		return shared.ErrorResult(err), nil
+	}
+
+	return mcp.NewToolResultText(formatSearchResult(result)), nil
+}
+
+func (h *Handlers) ListImages(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	filePath := request.GetString("file_path", "")
+	if filePath == "" {
+		return shared.ErrorResultf(shared.CodeInvalidArgument, "file_path parameter is required"), nil
+	}
+	if err := h.paths.Check(filePath); err != nil {
+		return shared.ErrorResult(err), nil
+	}
+
+	list, err := h.documentManager.ListImages(filePath)
+	if err != nil {
+		return shared.ErrorResult(err), nil
+	}
+	if len(list.Images) == 0 {
+		return mcp.NewToolResultText("No images found in the document"), nil
+	}
+
+	listJSON, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return shared.ErrorResultFromErr("Failed to marshal images", err), nil
+	}
+	return mcp.NewToolResultText(string(listJSON)), nil
+}
+
+func (h *Handlers) ExtractImage(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	filePath := request.GetString("file_path", "")
+	if filePath == "" {
+		return shared.ErrorResultf(shared.CodeInvalidArgument, "file_path parameter is required"), nil
+	}
+	if err := h.paths.Check(filePath); err != nil {
+		return shared.ErrorResult(err), nil
+	}
+	outputPath := request.GetString("output_path", "")
+	if outputPath == "" {
+		return shared.ErrorResultf(shared.CodeInvalidArgument, "output_path parameter is required"), nil
+	}
+	if err := h.paths.Check(outputPath); err != nil {
+		return shared.ErrorResult(err), nil
+	}
+	index := request.GetInt("index", 0)
+	if index < 1 {
+		return shared.ErrorResultf(shared.CodeInvalidArgument, "index parameter is required and numbered from 1"), nil
+	}
+
+	info, writtenPath, err := h.documentManager.ExtractImage(filePath, index, outputPath)
+	if err != nil {
+		return shared.ErrorResult(err), nil
+	}
+
+	return mcp.NewToolResultText(formatExtractedImage(info, writtenPath)), nil
+}
+
+func (h *Handlers) ConvertCorpus(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	sourceDir := request.GetString("source_dir", "")
+	if sourceDir == "" {
+		return shared.ErrorResultf(shared.CodeInvalidArgument, "source_dir parameter is required"), nil
+	}
+	if err := h.paths.Check(sourceDir); err != nil {
+		return shared.ErrorResult(err), nil
+	}
+
+	outputDir := request.GetString("output_dir", "")
+	if outputDir == "" {
+		return shared.ErrorResultf(shared.CodeInvalidArgument, "output_dir parameter is required"), nil
+	}
+	if err := h.paths.Check(outputDir); err != nil {
+		return shared.ErrorResult(err), nil
+	}
+
+	index, err := h.documentManager.ConvertCorpus(ctx, sourceDir, outputDir)
+	if err != nil {
+		return shared.ErrorResult(err), nil
+	}
+
+	totalWords := 0
+	for _, doc := range index.Documents {
+		totalWords += doc.WordCount
+	}
+
+	result := fmt.Sprintf(`Corpus Conversion Complete:
+Source: %s
+Output: %s
+Index: %s
+Converted: %d documents (%d words)
+Failed: %d documents`,
+		index.SourceDir,
+		index.OutputDir,
+		filepath.Join(index.OutputDir, corpusIndexFile),
+		len(index.Documents),
+		totalWords,
+		len(index.Failures),
 	)
 
+	for _, failure := range index.Failures {
+		result += fmt.Sprintf("\n- %s: %s", failure.SourcePath, failure.Error)
+	}
+
 	return mcp.NewToolResultText(result), nil
 }
+
+// Helper function to format the embedded properties of a document, skipping empty ones
+func formatDocumentProperties(props DocumentProperties) string {
+	var result strings.Builder
+	for _, field := range []struct{ label, value string }{
+		{"Title", props.Title},
+		{"Author", props.Author},
+		{"Subject", props.Subject},
+		{"Keywords", props.Keywords},
+	} {
+		if field.value != "" {
+			result.WriteString(fmt.Sprintf("\n%s: %s", field.label, field.value))
+		}
+	}
+	if !props.Created.IsZero() {
+		result.WriteString(fmt.Sprintf("\nCreated: %s", props.Created.Format("2006-01-02 15:04:05 -0700")))
+	}
+	if !props.Modified.IsZero() {
+		result.WriteString(fmt.Sprintf("\nLast saved: %s", props.Modified.Format("2006-01-02 15:04:05 -0700")))
+	}
+	if props.Pages > 0 {
+		unit := props.PageUnit
+		if unit == "" {
+			unit = "pages"
+		}
+		result.WriteString(fmt.Sprintf("\n%s: %d", strings.ToUpper(unit[:1])+unit[1:], props.Pages))
+	}
+	if props.WordCount > 0 {
+		result.WriteString(fmt.Sprintf("\nWords: %d", props.WordCount))
+	}
+	return result.String()
+}
+
+// Helper function to format signatures and restrictions
+func formatDocumentSecurity(security DocumentSecurity) string {
+	var result strings.Builder
+	for _, signature := range security.Signatures {
+		signer := signature.Signer
+		if signer == "" {
+			signer = "unknown signer"
+		}
+		result.WriteString(fmt.Sprintf("\nSignature: %s", signer))
+		if signature.Issuer != "" {
+			result.WriteString(fmt.Sprintf(", issued by %s", signature.Issuer))
+		}
+		if !signature.SignedAt.IsZero() {
+			result.WriteString(fmt.Sprintf(", signed %s", signature.SignedAt.Format("2006-01-02 15:04:05 -0700")))
+		}
+		if signature.Reason != "" {
+			result.WriteString(fmt.Sprintf(" (%s)", signature.Reason))
+		}
+	}
+	if len(security.Restrictions) > 0 {
+		result.WriteString(fmt.Sprintf("\nRestrictions: %s", strings.Join(security.Restrictions, ", ")))
+	}
+	return result.String()
+}
+
+// Helper function to format extracted pages with a header per page
+func formatPageExtraction(extraction *PageExtraction) string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Extracted %d of %d %ss from %s\n", len(extraction.Pages), extraction.TotalPages, extraction.Unit, extraction.FilePath))
+
+	label := "Page"
+	if extraction.Unit == "slide" {
+		label = "Slide"
+	}
+	for _, page := range extraction.Pages {
+		result.WriteString(fmt.Sprintf("\n--- %s %d ---\n", label, page.Number))
+		if page.Text == "" {
+			result.WriteString("(no text)\n")
+			continue
+		}
+		result.WriteString(page.Text)
+		result.WriteString("\n")
+	}
+
+	return result.String()
+}
+
+// Helper function to format slides with their speaker notes
+func formatSlideExtraction(extraction *SlideExtraction) string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Extracted %d of %d slides from %s", len(extraction.Slides), extraction.TotalSlides, extraction.FilePath))
+	if extraction.SkippedHidden > 0 {
+		result.WriteString(fmt.Sprintf(" (%d hidden skipped)", extraction.SkippedHidden))
+	}
+	result.WriteString("\n")
+
+	for _, slide := range extraction.Slides {
+		header := fmt.Sprintf("Slide %d", slide.Number)
+		if slide.Title != "" {
+			header += ": " + slide.Title
+		}
+		if slide.Hidden {
+			header += " (hidden)"
+		}
+		result.WriteString(fmt.Sprintf("\n--- %s ---\n", header))
+		if slide.Text == "" {
+			result.WriteString("(no text)\n")
+		} else {
+			result.WriteString(slide.Text)
+			result.WriteString("\n")
+		}
+		if slide.Notes != "" {
+			result.WriteString("\nNotes:\n")
+			result.WriteString(slide.Notes)
+			result.WriteString("\n")
+		}
+	}
+
+	return result.String()
+}
+
+// Helper function to format review content as sections following the document text
+func formatReview(review *DocxReview) string {
+	var result strings.Builder
+
+	if len(review.Comments) > 0 {
+		result.WriteString("\n\n--- Comments ---")
+		for _, comment := range review.Comments {
+			result.WriteString(fmt.Sprintf("\n[%s]", comment.ID))
+			if comment.Author != "" {
+				result.WriteString(" " + comment.Author)
+			}
+			if comment.Anchor != "" {
+				result.WriteString(fmt.Sprintf(" on %q", comment.Anchor))
+			}
+			result.WriteString(": " + comment.Text)
+		}
+	}
+	for _, notes := range []struct {
+		title string
+		notes []DocxNote
+	}{{"Footnotes", review.Footnotes}, {"Endnotes", review.Endnotes}} {
+		if len(notes.notes) == 0 {
+			continue
+		}
+		result.WriteString(fmt.Sprintf("\n\n--- %s ---", notes.title))
+		for _, note := range notes.notes {
+			result.WriteString(fmt.Sprintf("\n[%s] %s", note.ID, note.Text))
+		}
+	}
+	if len(review.Revisions) > 0 {
+		result.WriteString("\n\n--- Tracked changes ---")
+		for _, revision := range review.Revisions {
+			action := "Inserted"
+			if revision.Type == "deletion" {
+				action = "Deleted"
+			}
+			if revision.Author != "" {
+				action += " by " + revision.Author
+			}
+			result.WriteString(fmt.Sprintf("\n%s: %s", action, revision.Text))
+		}
+	}
+
+	return result.String()
+}
+
+// Helper function to format tables as CSV blocks under a header each
+func formatTables(tables []Table) string {
+	var result strings.Builder
+	for i, table := range tables {
+		if i > 0 {
+			result.WriteString("\n")
+		}
+		result.WriteString(fmt.Sprintf("Table %d", table.Index))
+		if table.Page > 0 {
+			result.WriteString(fmt.Sprintf(" (page %d)", table.Page))
+		}
+		result.WriteString(fmt.Sprintf(": %d rows x %d columns\n", len(table.Rows), table.Columns))
+		result.WriteString(table.CSV())
+	}
+	return result.String()
+}
+
+// Helper function to format an outline as an indented list with page anchors
+func formatOutline(outline *DocumentOutline) string {
+	if len(outline.Entries) == 0 {
+		return fmt.Sprintf("No %s found in %s\n", outline.Source, outline.FilePath)
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Outline of %s (%d entries from %s)\n\n", outline.FilePath, len(outline.Entries), outline.Source))
+	for _, entry := range outline.Entries {
+		result.WriteString(strings.Repeat("  ", max(entry.Level-1, 0)))
+		result.WriteString("- ")
+		result.WriteString(entry.Title)
+		if entry.Page > 0 {
+			result.WriteString(fmt.Sprintf(" (%s %d)", outline.Unit, entry.Page))
+		}
+		result.WriteString("\n")
+	}
+	return result.String()
+}
+
+// Helper function to format search matches with their location and context
+func formatSearchResult(result *SearchResult) string {
+	if result.TotalMatches == 0 {
+		return fmt.Sprintf("No matches for %q in %s\n", result.Query, result.FilePath)
+	}
+
+	var output strings.Builder
+	output.WriteString(fmt.Sprintf("Found %d matches for %q in %s", result.TotalMatches, result.Query, result.FilePath))
+	if len(result.Matches) < result.TotalMatches {
+		output.WriteString(fmt.Sprintf(" (showing the first %d)", len(result.Matches)))
+	}
+	output.WriteString("\n")
+
+	for _, match := range result.Matches {
+		location := fmt.Sprintf("line %d", match.Line)
+		if match.Page > 0 {
+			location = fmt.Sprintf("%s %d, %s", result.Unit, match.Page, location)
+		}
+		output.WriteString(fmt.Sprintf("\n[%s] %s\n", location, match.Context))
+	}
+
+	return output.String()
+}
+
+// Helper function to format the result of saving an image
+func formatExtractedImage(info *ImageInfo, writtenPath string) string {
+	details := []string{info.Format}
+	if info.Width > 0 && info.Height > 0 {
+		details = append(details, fmt.Sprintf("%dx%d", info.Width, info.Height))
+	}
+	if info.Page > 0 {
+		details = append(details, fmt.Sprintf("page %d", info.Page))
+	}
+	return fmt.Sprintf("Saved image %d (%s) to %s\n", info.Index, strings.Join(details, ", "), writtenPath)
+}
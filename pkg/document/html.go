@@ -0,0 +1,189 @@
+package document
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// htmlBlockElements start a new line in the extracted text
+var htmlBlockElements = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Br: true, atom.Li: true, atom.Tr: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Section: true, atom.Article: true, atom.Blockquote: true, atom.Pre: true,
+	atom.Ul: true, atom.Ol: true, atom.Hr: true, atom.Table: true,
+}
+
+// htmlSkippedElements hold content that is never displayed as document text
+var htmlSkippedElements = map[atom.Atom]bool{
+	atom.Head: true, atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Template: true,
+}
+
+// htmlHeadingLevels maps heading elements to their Markdown level
+var htmlHeadingLevels = map[atom.Atom]int{
+	atom.H1: 1, atom.H2: 2, atom.H3: 3, atom.H4: 4, atom.H5: 5, atom.H6: 6,
+}
+
+// htmlMetaNames are the <meta name> values reported as document metadata
+var htmlMetaNames = map[string]bool{
+	"author": true, "description": true, "keywords": true, "generator": true,
+}
+
+func (m *Manager) extractHTMLText(_ context.Context, filePath string) (string, error) {
+	content, err := readTextFile(filePath, "HTML")
+	if err != nil {
+		return "", err
+	}
+
+	text, err := htmlText(strings.NewReader(content), true)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	return cleanStructuredText(text), nil
+}
+
+// htmlMetadata returns the title and descriptive <meta> tags of an HTML document
+func htmlMetadata(filePath string) (map[string]string, error) {
+	content, err := readTextFile(filePath, "HTML")
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := make(map[string]string)
+	tokenizer := html.NewTokenizer(strings.NewReader(content))
+	inTitle := false
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			if err := tokenizer.Err(); !errors.Is(err, io.EOF) {
+				return nil, err
+			}
+			return metadata, nil
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := tokenizer.TagName()
+			switch atom.Lookup(name) {
+			case atom.Title:
+				inTitle = true
+			case atom.Meta:
+				var metaName, content string
+				for hasAttr {
+					var key, value []byte
+					key, value, hasAttr = tokenizer.TagAttr()
+					switch string(key) {
+					case "name":
+						metaName = strings.ToLower(string(value))
+					case "content":
+						content = strings.TrimSpace(string(value))
+					}
+				}
+				if htmlMetaNames[metaName] && content != "" {
+					metadata[metaName] = content
+				}
+			case atom.Body:
+				return metadata, nil // Metadata lives in the head
+			}
+		case html.EndTagToken:
+			if name, _ := tokenizer.TagName(); atom.Lookup(name) == atom.Title {
+				inTitle = false
+			}
+		case html.TextToken:
+			if inTitle {
+				if title := strings.TrimSpace(string(tokenizer.Text())); title != "" {
+					metadata["title"] = title
+				}
+			}
+		}
+	}
+}
+
+// htmlText returns the visible text of an HTML or XHTML document, with block
+// elements on their own lines and table cells separated by tabs. With
+// keepStructure, headings are marked up as Markdown headings and links are
+// followed by their target in parentheses.
+func htmlText(r io.Reader, keepStructure bool) (string, error) {
+	var text strings.Builder
+	tokenizer := html.NewTokenizer(r)
+	skipDepth := 0
+	var linkTarget string
+	linkStart := 0
+
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			if err := tokenizer.Err(); !errors.Is(err, io.EOF) {
+				return "", err
+			}
+			return text.String(), nil
+		case html.StartTagToken:
+			name, hasAttr := tokenizer.TagName()
+			element := atom.Lookup(name)
+			if htmlSkippedElements[element] {
+				skipDepth++
+			}
+			writeHTMLBreak(&text, element)
+			if !keepStructure || skipDepth > 0 {
+				continue
+			}
+			if level, ok := htmlHeadingLevels[element]; ok {
+				text.WriteString(strings.Repeat("#", level) + " ")
+			}
+			if element == atom.A {
+				linkTarget, linkStart = "", text.Len()
+				for hasAttr {
+					var key, value []byte
+					key, value, hasAttr = tokenizer.TagAttr()
+					if string(key) == "href" {
+						linkTarget = strings.TrimSpace(string(value))
+					}
+				}
+			}
+		case html.SelfClosingTagToken:
+			name, _ := tokenizer.TagName()
+			writeHTMLBreak(&text, atom.Lookup(name))
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			element := atom.Lookup(name)
+			if htmlSkippedElements[element] && skipDepth > 0 {
+				skipDepth--
+			}
+			if element == atom.A && isExternalLink(linkTarget) {
+				// Bare URLs already show their target
+				if anchor := strings.TrimSpace(text.String()[linkStart:]); anchor != linkTarget {
+					text.WriteString(" (" + linkTarget + ")")
+				}
+				linkTarget = ""
+			}
+			writeHTMLBreak(&text, element)
+			if _, ok := htmlHeadingLevels[element]; ok && keepStructure {
+				text.WriteString("\n") // Blank line after a heading
+			}
+		case html.TextToken:
+			if skipDepth == 0 {
+				text.Write(tokenizer.Text())
+			}
+		}
+	}
+}
+
+// writeHTMLBreak separates block elements and table cells from their neighbours
+func writeHTMLBreak(text *strings.Builder, element atom.Atom) {
+	switch {
+	case htmlBlockElements[element]:
+		text.WriteString("\n")
+	case element == atom.Td || element == atom.Th:
+		text.WriteString("\t")
+	}
+}
+
+// isExternalLink reports whether a link target is worth keeping in the text;
+// fragment and script links are dropped
+func isExternalLink(target string) bool {
+	lower := strings.ToLower(target)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") || strings.HasPrefix(lower, "mailto:")
+}
@@ -0,0 +1,454 @@
+package document
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image"
+	_ "image/gif" // Register decoders for image.DecodeConfig
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kevsmith/my-mcp/pkg/shared"
+	"github.com/ledongthuc/pdf"
+)
+
+// imageExtensions are the file extensions of images stored in document packages
+var imageExtensions = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".bmp": true, ".tif": true, ".tiff": true,
+	".svg": true, ".emf": true, ".wmf": true, ".webp": true, ".wdp": true,
+}
+
+// ImageInfo describes an image embedded in a document
+type ImageInfo struct {
+	Index  int    `json:"index"`          // 1-based position in the document
+	Page   int    `json:"page,omitempty"` // Page or slide the image is on, when known
+	Name   string `json:"name"`           // Path in the package, or PDF resource name
+	Format string `json:"format"`         // Format of the extracted file: png, jpeg, gif, emf, ...
+	Width  int    `json:"width,omitempty"`
+	Height int    `json:"height,omitempty"`
+	Size   int64  `json:"size"` // Stored size in bytes
+}
+
+// ImageList is the result of listing the images of a document
+type ImageList struct {
+	FilePath string      `json:"file_path"`
+	Unit     string      `json:"unit,omitempty"` // "page" or "slide" when images have page numbers
+	Images   []ImageInfo `json:"images"`
+}
+
+// ListImages returns the images embedded in a document: per page for PDF
+// (image XObjects drawn by the page), per slide for PPTX, and in document
+// order for DOCX, ODT and EPUB
+func (m *Manager) ListImages(filePath string) (*ImageList, error) {
+	if err := m.checkFileSize(filePath); err != nil {
+		return nil, err
+	}
+
+	list := &ImageList{FilePath: filePath}
+	var err error
+
+	switch docType := m.detectFileType(filePath); docType {
+	case DocumentTypePDF:
+		list.Unit = "page"
+		list.Images, err = pdfImages(filePath)
+	case DocumentTypePPTX:
+		list.Unit = "slide"
+		list.Images, err = packageImages(filePath, docType)
+	case DocumentTypeDOCX, DocumentTypeODT, DocumentTypeEPUB:
+		list.Images, err = packageImages(filePath, docType)
+	default:
+		return nil, fmt.Errorf("image listing supports .pdf, .docx, .pptx, .odt and .epub files only: %s", filePath)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range list.Images {
+		list.Images[i].Index = i + 1
+	}
+	return list, nil
+}
+
+// ExtractImage saves the image with the given index (as numbered by
+// ListImages) to outputPath. When outputPath is a directory the file is named
+// after the image. It returns the image and the path written.
+func (m *Manager) ExtractImage(filePath string, index int, outputPath string) (*ImageInfo, string, error) {
+	list, err := m.ListImages(filePath)
+	if err != nil {
+		return nil, "", err
+	}
+	if index < 1 || index > len(list.Images) {
+		return nil, "", fmt.Errorf("image %d is out of range: document has %d images", index, len(list.Images))
+	}
+	info := list.Images[index-1]
+
+	var data []byte
+	if list.Unit == "page" {
+		data, err = pdfImageData(filePath, info)
+	} else {
+		data, err = packageImageData(filePath, info.Name)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	if stat, err := os.Stat(outputPath); err == nil && stat.IsDir() {
+		outputPath = filepath.Join(outputPath, imageFileName(info))
+	}
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return nil, "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if err := os.WriteFile(outputPath, data, 0644); err != nil {
+		return nil, "", fmt.Errorf("failed to write image: %w", err)
+	}
+
+	return &info, outputPath, nil
+}
+
+// imageFileName names an extracted image after its package path, or after
+// its page and resource name for PDF images
+func imageFileName(info ImageInfo) string {
+	extension := "." + info.Format
+	if info.Format == "jpeg" {
+		extension = ".jpg"
+	}
+	if strings.Contains(info.Name, "/") {
+		return strings.TrimSuffix(path.Base(info.Name), path.Ext(info.Name)) + extension
+	}
+	return fmt.Sprintf("page%d-%s%s", info.Page, info.Name, extension)
+}
+
+// packageImages lists the images of a zip-based document. DOCX images are in
+// the order the body references them, followed by images used elsewhere
+// (headers, footers); PPTX images are listed for each slide that shows them.
+func packageImages(filePath string, docType DocumentType) ([]ImageInfo, error) {
+	reader, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open document package: %w", err)
+	}
+	defer reader.Close()
+
+	files := make(map[string]*zip.File, len(reader.File))
+	for _, f := range reader.File {
+		files[f.Name] = f
+	}
+
+	var images []ImageInfo
+	listed := make(map[string]bool)
+	add := func(name string, page int) {
+		if f := files[name]; f != nil {
+			images = append(images, packageImageInfo(f, page))
+			listed[name] = true
+		}
+	}
+
+	switch docType {
+	case DocumentTypePPTX:
+		for i, slide := range pptxSlideOrder(files) {
+			for _, name := range partImages(files, slide) {
+				add(name, i+1)
+			}
+		}
+		return images, nil
+	case DocumentTypeDOCX:
+		for _, name := range partImages(files, "word/document.xml") {
+			add(name, 0)
+		}
+	}
+
+	// Remaining images in the package, by name
+	var remaining []string
+	for _, f := range reader.File {
+		if !listed[f.Name] && imageExtensions[strings.ToLower(path.Ext(f.Name))] && !strings.HasSuffix(f.Name, "/") {
+			remaining = append(remaining, f.Name)
+		}
+	}
+	sort.Strings(remaining)
+	for _, name := range remaining {
+		add(name, 0)
+	}
+	return images, nil
+}
+
+// partImages returns the package paths of the images a part references, in
+// the order of their first reference (r:embed on DrawingML pictures, r:id on
+// legacy VML image data)
+func partImages(files map[string]*zip.File, part string) []string {
+	targets := make(map[string]string)
+	for _, rel := range partRelationships(files, part) {
+		if strings.HasSuffix(rel.Type, "/image") {
+			targets[rel.ID] = rel.Target
+		}
+	}
+	if len(targets) == 0 || files[part] == nil {
+		return nil
+	}
+	content, err := readZipFile(files[part])
+	if err != nil {
+		return nil
+	}
+
+	var names []string
+	seen := make(map[string]bool)
+	decoder := xml.NewDecoder(strings.NewReader(content))
+	decoder.Strict = false
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return names
+		}
+		element, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		for _, attr := range element.Attr {
+			isReference := attr.Name.Local == "embed" || (attr.Name.Local == "id" && element.Name.Local == "imagedata")
+			if target, ok := targets[attr.Value]; isReference && ok && !seen[target] {
+				seen[target] = true
+				names = append(names, target)
+			}
+		}
+	}
+}
+
+// packageRelationship is a relationship from a package part to another part
+type packageRelationship struct {
+	ID     string
+	Type   string
+	Target string // Path of the target part in the package
+}
+
+// partRelationships reads the internal relationships of a package part from
+// its _rels/<part>.rels file
+func partRelationships(files map[string]*zip.File, part string) []packageRelationship {
+	var relationships struct {
+		Relationships []struct {
+			ID         string `xml:"Id,attr"`
+			Type       string `xml:"Type,attr"`
+			Target     string `xml:"Target,attr"`
+			TargetMode string `xml:"TargetMode,attr"`
+		} `xml:"Relationship"`
+	}
+	relsPath := path.Join(path.Dir(part), "_rels", path.Base(part)+".rels")
+	if decodeZipXML(files[relsPath], &relationships) != nil {
+		return nil
+	}
+
+	var result []packageRelationship
+	for _, rel := range relationships.Relationships {
+		if rel.TargetMode == "External" {
+			continue
+		}
+		// Targets are relative to the part's directory unless they start at the package root
+		target := path.Join(path.Dir(part), rel.Target)
+		if strings.HasPrefix(rel.Target, "/") {
+			target = strings.TrimPrefix(rel.Target, "/")
+		}
+		result = append(result, packageRelationship{ID: rel.ID, Type: rel.Type, Target: target})
+	}
+	return result
+}
+
+// packageImageInfo describes an image file in a package, reading only its
+// header for the dimensions
+func packageImageInfo(f *zip.File, page int) ImageInfo {
+	info := ImageInfo{
+		Page:   page,
+		Name:   f.Name,
+		Format: strings.TrimPrefix(strings.ToLower(path.Ext(f.Name)), "."),
+		Size:   int64(f.UncompressedSize64),
+	}
+	if info.Format == "jpg" {
+		info.Format = "jpeg"
+	}
+
+	if rc, err := f.Open(); err == nil {
+		if config, format, err := image.DecodeConfig(rc); err == nil {
+			info.Format, info.Width, info.Height = format, config.Width, config.Height
+		}
+		rc.Close()
+	}
+	return info
+}
+
+func packageImageData(filePath, name string) ([]byte, error) {
+	reader, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open document package: %w", err)
+	}
+	defer reader.Close()
+
+	for _, f := range reader.File {
+		if f.Name != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read image %s: %w", name, err)
+		}
+		defer rc.Close()
+		return io.ReadAll(rc)
+	}
+	return nil, shared.NewError(shared.CodeNotFound, "image %s not found in document", name)
+}
+
+// pdfImages lists the image XObjects in the resources of each page. JPEG and
+// JPEG 2000 images are extracted as stored; other images are converted to PNG.
+func pdfImages(filePath string) ([]ImageInfo, error) {
+	var images []ImageInfo
+	err := withPDF(filePath, "", func(reader *pdf.Reader) error {
+		for number := 1; number <= reader.NumPage(); number++ {
+			page := reader.Page(number)
+			if page.V.IsNull() {
+				continue
+			}
+			objects := page.Resources().Key("XObject")
+			names := objects.Keys()
+			sort.Strings(names)
+			for _, name := range names {
+				object := objects.Key(name)
+				if object.Key("Subtype").Name() != "Image" {
+					continue
+				}
+				format := "png"
+				switch pdfImageFilter(object) {
+				case "DCTDecode":
+					format = "jpeg"
+				case "JPXDecode":
+					format = "jp2"
+				}
+				images = append(images, ImageInfo{
+					Page:   number,
+					Name:   name,
+					Format: format,
+					Width:  int(object.Key("Width").Int64()),
+					Height: int(object.Key("Height").Int64()),
+					Size:   object.Key("Length").Int64(),
+				})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return images, nil
+}
+
+// pdfImageFilter returns the last filter applied to an image stream, which
+// determines the image encoding
+func pdfImageFilter(object pdf.Value) string {
+	filter := object.Key("Filter")
+	if filter.Kind() == pdf.Array {
+		if filter.Len() == 0 {
+			return ""
+		}
+		return filter.Index(filter.Len() - 1).Name()
+	}
+	return filter.Name()
+}
+
+// pdfImageData returns the encoded image listed as info
+func pdfImageData(filePath string, info ImageInfo) (data []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			data, err = nil, shared.NewError(shared.CodeUnsupportedFormat, "unsupported image encoding: %v", r)
+		}
+	}()
+
+	file, reader, err := pdf.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PDF file: %w", err)
+	}
+	defer file.Close()
+
+	object := reader.Page(info.Page).Resources().Key("XObject").Key(info.Name)
+	if object.Kind() != pdf.Stream {
+		return nil, shared.NewError(shared.CodeNotFound, "image %s not found on page %d", info.Name, info.Page)
+	}
+
+	if info.Format == "png" {
+		return pdfImagePNG(object)
+	}
+
+	// The reader can't decode JPEG streams, but their content is the image
+	// file itself: copy the stored bytes
+	if filter := object.Key("Filter"); filter.Kind() == pdf.Array && filter.Len() > 1 {
+		return nil, shared.NewError(shared.CodeUnsupportedFormat, "unsupported image encoding: %v", filter)
+	}
+	if !reader.Trailer().Key("Encrypt").IsNull() {
+		return nil, shared.NewError(shared.CodeUnsupportedFormat, "cannot extract %s images from an encrypted PDF", info.Format)
+	}
+	// The reader exposes the stream offset only in its string form, "<<...>>@offset"
+	description := object.String()
+	offset, err := strconv.ParseInt(description[strings.LastIndex(description, "@")+1:], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate image data: %w", err)
+	}
+	data = make([]byte, object.Key("Length").Int64())
+	if _, err := file.ReadAt(data, offset); err != nil {
+		return nil, fmt.Errorf("failed to read image data: %w", err)
+	}
+	return data, nil
+}
+
+// pdfImagePNG decodes an 8-bit gray, RGB or CMYK image stream and encodes it as PNG
+func pdfImagePNG(object pdf.Value) ([]byte, error) {
+	width, height := int(object.Key("Width").Int64()), int(object.Key("Height").Int64())
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("invalid image dimensions %dx%d", width, height)
+	}
+	if bits := object.Key("BitsPerComponent").Int64(); bits != 8 {
+		return nil, shared.NewError(shared.CodeUnsupportedFormat, "unsupported image encoding: %d bits per component", bits)
+	}
+
+	colorSpace := object.Key("ColorSpace")
+	components := 0
+	switch colorSpace.Kind() {
+	case pdf.Name:
+		components = map[string]int{"DeviceGray": 1, "DeviceRGB": 3, "DeviceCMYK": 4}[colorSpace.Name()]
+	case pdf.Array:
+		if colorSpace.Index(0).Name() == "ICCBased" {
+			components = int(colorSpace.Index(1).Key("N").Int64())
+		}
+	}
+	if components != 1 && components != 3 && components != 4 {
+		return nil, shared.NewError(shared.CodeUnsupportedFormat, "unsupported image color space: %v", colorSpace)
+	}
+
+	rc := object.Reader()
+	defer rc.Close()
+	pixels := make([]byte, width*height*components)
+	if _, err := io.ReadFull(rc, pixels); err != nil {
+		return nil, fmt.Errorf("failed to decode image data: %w", err)
+	}
+
+	var img image.Image
+	switch components {
+	case 1:
+		img = &image.Gray{Pix: pixels, Stride: width, Rect: image.Rect(0, 0, width, height)}
+	case 3:
+		rgba := image.NewRGBA(image.Rect(0, 0, width, height))
+		for i := 0; i < width*height; i++ {
+			rgba.Pix[i*4], rgba.Pix[i*4+1], rgba.Pix[i*4+2], rgba.Pix[i*4+3] = pixels[i*3], pixels[i*3+1], pixels[i*3+2], 0xff
+		}
+		img = rgba
+	case 4:
+		img = &image.CMYK{Pix: pixels, Stride: width * 4, Rect: image.Rect(0, 0, width, height)}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
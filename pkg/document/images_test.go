@@ -0,0 +1,181 @@
+package document
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testImage encodes a width x height image as "png" or "jpeg"
+func testImage(t *testing.T, format string, width, height int) string {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		img.Set(x, 0, color.RGBA{R: 200, A: 255})
+	}
+	var buf bytes.Buffer
+	var err error
+	if format == "png" {
+		err = png.Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, img, nil)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestListDocxImages(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.docx")
+	writeTestZip(t, path,
+		[2]string{"word/document.xml", `<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><w:body>` +
+			`<w:p><w:r><w:drawing><a:blip r:embed="rId2"/></w:drawing></w:r></w:p>` +
+			`<w:p><w:r><w:drawing><a:blip r:embed="rId1"/></w:drawing></w:r></w:p>` +
+			`<w:p><w:r><w:drawing><a:blip r:embed="rId2"/></w:drawing></w:r></w:p>` +
+			`</w:body></w:document>`},
+		[2]string{"word/_rels/document.xml.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="media/image1.jpeg"/>` +
+			`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="media/image2.png"/>` +
+			`<Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+			`</Relationships>`},
+		[2]string{"word/media/image1.jpeg", testImage(t, "jpeg", 8, 4)},
+		[2]string{"word/media/image2.png", testImage(t, "png", 3, 2)},
+		[2]string{"word/media/logo.emf", "not decoded"},
+	)
+
+	list, err := NewManager().ListImages(path)
+	if err != nil {
+		t.Fatalf("ListImages failed: %v", err)
+	}
+	if len(list.Images) != 3 || list.Unit != "" {
+		t.Fatalf("Unexpected image list: %+v", list)
+	}
+	expected := []string{"1 word/media/image2.png png 3x2", "2 word/media/image1.jpeg jpeg 8x4", "3 word/media/logo.emf emf 0x0"}
+	for i, img := range list.Images {
+		if got := fmt.Sprintf("%d %s %s %dx%d", img.Index, img.Name, img.Format, img.Width, img.Height); got != expected[i] {
+			t.Errorf("Image %d = %q, want %q", i, got, expected[i])
+		}
+	}
+
+	// Saving into a directory names the file after the image
+	dir := t.TempDir()
+	info, written, err := NewManager().ExtractImage(path, 2, dir)
+	if err != nil {
+		t.Fatalf("ExtractImage failed: %v", err)
+	}
+	if info.Name != "word/media/image1.jpeg" || written != filepath.Join(dir, "image1.jpg") {
+		t.Errorf("Unexpected extraction: %+v to %s", info, written)
+	}
+	if data, err := os.ReadFile(written); err != nil || string(data) != testImage(t, "jpeg", 8, 4) {
+		t.Errorf("Extracted image differs from the stored one: %v", err)
+	}
+
+	if _, _, err := NewManager().ExtractImage(path, 4, dir); err == nil || !strings.Contains(err.Error(), "out of range") {
+		t.Errorf("Expected an out of range error, got %v", err)
+	}
+}
+
+func TestListPptxImages(t *testing.T) {
+	picture := func(id string) string {
+		return `<p:pic><p:blipFill><a:blip xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships" r:embed="` + id + `"/></p:blipFill></p:pic>`
+	}
+	rels := func(target string) string {
+		return `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId7" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="` + target + `"/></Relationships>`
+	}
+
+	path := filepath.Join(t.TempDir(), "deck.pptx")
+	// The slide stored second is shown first
+	writeTestPptxShapes(t, path, []int{2, 1}, [][2]string{
+		{"ppt/slides/_rels/slide1.xml.rels", rels("../media/chart.png")},
+		{"ppt/slides/_rels/slide2.xml.rels", rels("../media/photo.png")},
+		{"ppt/media/chart.png", testImage(t, "png", 5, 5)},
+		{"ppt/media/photo.png", testImage(t, "png", 6, 4)},
+	}, picture("rId7"), picture("rId7"))
+
+	list, err := NewManager().ListImages(path)
+	if err != nil {
+		t.Fatalf("ListImages failed: %v", err)
+	}
+	if list.Unit != "slide" || len(list.Images) != 2 {
+		t.Fatalf("Unexpected image list: %+v", list)
+	}
+	if list.Images[0].Name != "ppt/media/photo.png" || list.Images[0].Page != 1 || list.Images[1].Name != "ppt/media/chart.png" || list.Images[1].Page != 2 {
+		t.Errorf("Unexpected images: %+v", list.Images)
+	}
+}
+
+func TestListPDFImages(t *testing.T) {
+	photo := testImage(t, "jpeg", 4, 2)
+	pixels := "\xff\x00\x00\x00\xff\x00" // Red and green RGB pixels
+
+	path := filepath.Join(t.TempDir(), "scan.pdf")
+	writeTestPDFObjects(t, path, testPDF{
+		Resources: "/XObject << /Im1 7 0 R /Im0 6 0 R >>",
+		Extra: []string{
+			fmt.Sprintf("<< /Type /XObject /Subtype /Image /Width 4 /Height 2 /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /DCTDecode /Length %d >>\nstream\n%s\nendstream", len(photo), photo),
+			fmt.Sprintf("<< /Type /XObject /Subtype /Image /Width 2 /Height 1 /ColorSpace /DeviceRGB /BitsPerComponent 8 /Length %d >>\nstream\n%s\nendstream", len(pixels), pixels),
+		},
+	}, "BT /F1 12 Tf 72 720 Td (Figure) Tj ET")
+
+	list, err := NewManager().ListImages(path)
+	if err != nil {
+		t.Fatalf("ListImages failed: %v", err)
+	}
+	if list.Unit != "page" || len(list.Images) != 2 {
+		t.Fatalf("Unexpected image list: %+v", list)
+	}
+	jpegInfo, pngInfo := list.Images[0], list.Images[1]
+	if jpegInfo.Name != "Im0" || jpegInfo.Format != "jpeg" || jpegInfo.Page != 1 || jpegInfo.Width != 4 || pngInfo.Format != "png" || pngInfo.Height != 1 {
+		t.Errorf("Unexpected images: %+v", list.Images)
+	}
+
+	// JPEG images are copied as stored
+	dir := t.TempDir()
+	_, written, err := NewManager().ExtractImage(path, 1, dir)
+	if err != nil {
+		t.Fatalf("ExtractImage failed: %v", err)
+	}
+	if data, _ := os.ReadFile(written); written != filepath.Join(dir, "page1-Im0.jpg") || string(data) != photo {
+		t.Errorf("Unexpected JPEG extraction to %s", written)
+	}
+
+	// Raw pixels are converted to PNG
+	output := filepath.Join(dir, "figures", "pixels.png")
+	if _, _, err := NewManager().ExtractImage(path, 2, output); err != nil {
+		t.Fatalf("ExtractImage failed: %v", err)
+	}
+	file, err := os.Open(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	img, err := png.Decode(file)
+	if err != nil {
+		t.Fatalf("Extracted image is not a PNG: %v", err)
+	}
+	if r, g, _, _ := img.At(0, 0).RGBA(); img.Bounds().Dx() != 2 || r != 0xffff || g != 0 {
+		t.Errorf("Unexpected pixels: %v, %v", img.Bounds(), img.At(0, 0))
+	}
+	if _, g, _, _ := img.At(1, 0).RGBA(); g != 0xffff {
+		t.Errorf("Unexpected second pixel: %v", img.At(1, 0))
+	}
+}
+
+func TestListImagesUnsupportedFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.md")
+	if err := os.WriteFile(path, []byte("# Notes"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewManager().ListImages(path); err == nil || !strings.Contains(err.Error(), "image listing supports") {
+		t.Errorf("Expected an unsupported format error, got %v", err)
+	}
+}
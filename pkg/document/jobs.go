@@ -0,0 +1,289 @@
+package document
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kevsmith/my-mcp/pkg/shared"
+)
+
+// ProgressPageInterval is the number of pages between progress notifications
+// and the number of pages in each chunk of a background extraction
+const ProgressPageInterval = 10
+
+const (
+	jobRetention = 30 * time.Minute // How long finished jobs keep their text
+	maxJobs      = 32               // Running and retained jobs per manager
+)
+
+// Extraction job states
+const (
+	JobRunning   = "running"
+	JobCompleted = "completed"
+	JobFailed    = "failed"
+)
+
+// pageFunc receives each page of a PDF as it is extracted: its 1-based
+// number, the page count and its text, empty for pages that can't be read
+type pageFunc func(page, total int, text string)
+
+type pageFuncKey struct{}
+
+// withPageProgress returns a context whose PDF extractions report each page
+// to fn. fn runs on the extraction goroutine and must not block.
+func withPageProgress(ctx context.Context, fn pageFunc) context.Context {
+	return context.WithValue(ctx, pageFuncKey{}, fn)
+}
+
+// reportPage passes an extracted page to the callback set on ctx, if any
+func reportPage(ctx context.Context, page, total int, text string) {
+	if fn, ok := ctx.Value(pageFuncKey{}).(pageFunc); ok {
+		fn(page, total, text)
+	}
+}
+
+// ExtractionStatus is the progress of a background extraction. Chunks are
+// available as soon as their pages are extracted; when a job fails, the
+// pages extracted before the failure stay available.
+type ExtractionStatus struct {
+	JobID      string    `json:"job_id"`
+	FilePath   string    `json:"file_path"`
+	Status     string    `json:"status"` // JobRunning, JobCompleted or JobFailed
+	PagesDone  int       `json:"pages_done"`
+	TotalPages int       `json:"total_pages"` // 0 until the page count is known
+	Chunks     int       `json:"chunks_available"`
+	ChunkPages int       `json:"chunk_pages"`
+	Error      string    `json:"error,omitempty"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at,omitzero"`
+}
+
+// ExtractionChunk is the text of a run of pages from a background extraction
+type ExtractionChunk struct {
+	JobID     string `json:"job_id"`
+	Index     int    `json:"chunk_index"`
+	FirstPage int    `json:"first_page"`
+	LastPage  int    `json:"last_page"`
+	Text      string `json:"text"`
+}
+
+// extractionJob collects the pages of a background extraction
+type extractionJob struct {
+	mu         sync.Mutex
+	id         string
+	filePath   string
+	pages      []string
+	total      int
+	status     string
+	err        error
+	startedAt  time.Time
+	finishedAt time.Time
+	cancel     context.CancelFunc
+}
+
+// StartExtraction extracts the text of a document in the background and
+// returns its job, whose pages can be read with GetExtractionChunk while the
+// extraction runs. The job outlives ctx but keeps its values, such as the
+// password set with WithPassword, and stops at the configured timeout.
+func (m *Manager) StartExtraction(ctx context.Context, filePath string) (*ExtractionStatus, error) {
+	if _, err := os.Stat(filePath); err != nil {
+		return nil, fmt.Errorf("failed to access file: %w", err)
+	}
+	if err := m.checkFileSize(filePath); err != nil {
+		return nil, err
+	}
+
+	id, err := newJobID()
+	if err != nil {
+		return nil, err
+	}
+	jobCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	job := &extractionJob{
+		id:        id,
+		filePath:  filePath,
+		status:    JobRunning,
+		startedAt: time.Now(),
+		cancel:    cancel,
+	}
+
+	m.jobsMu.Lock()
+	m.pruneJobs(job.startedAt)
+	if len(m.jobs) >= maxJobs {
+		m.jobsMu.Unlock()
+		cancel()
+		return nil, fmt.Errorf("too many extraction jobs: at most %d may run or be retained at once", maxJobs)
+	}
+	m.jobs[id] = job
+	m.jobsMu.Unlock()
+
+	go func() {
+		defer cancel()
+		text, err := withTimeout(withPageProgress(jobCtx, job.addPage), m.limits.Timeout, func(ctx context.Context) (string, error) {
+			return m.extractUnlocked(ctx, filePath)
+		})
+		job.finish(text, err)
+	}()
+
+	return job.snapshot(), nil
+}
+
+// GetExtractionStatus reports the progress of a background extraction
+func (m *Manager) GetExtractionStatus(jobID string) (*ExtractionStatus, error) {
+	job, err := m.job(jobID)
+	if err != nil {
+		return nil, err
+	}
+	return job.snapshot(), nil
+}
+
+// GetExtractionChunk returns the text of the 0-based chunk of a background
+// extraction. Each chunk holds ProgressPageInterval pages; the last one may
+// hold fewer and is only available once the job has finished.
+func (m *Manager) GetExtractionChunk(jobID string, index int) (*ExtractionChunk, error) {
+	job, err := m.job(jobID)
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("chunk index must not be negative: %d", index)
+	}
+
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	available := job.chunks()
+	if index >= available {
+		if job.status == JobRunning {
+			return nil, fmt.Errorf("chunk %d is not available yet: %d of %s pages extracted", index, len(job.pages), job.totalText())
+		}
+		return nil, fmt.Errorf("chunk %d does not exist: the extraction has %d chunks", index, available)
+	}
+
+	first := index * ProgressPageInterval
+	last := min(first+ProgressPageInterval, len(job.pages))
+	return &ExtractionChunk{
+		JobID:     job.id,
+		Index:     index,
+		FirstPage: first + 1,
+		LastPage:  last,
+		Text:      strings.TrimSpace(strings.Join(job.pages[first:last], "\n")),
+	}, nil
+}
+
+// job looks up a background extraction by ID
+func (m *Manager) job(jobID string) (*extractionJob, error) {
+	m.jobsMu.Lock()
+	defer m.jobsMu.Unlock()
+	m.pruneJobs(time.Now())
+	job, ok := m.jobs[jobID]
+	if !ok {
+		return nil, shared.NewError(shared.CodeNotFound, "extraction job not found: %s", jobID)
+	}
+	return job, nil
+}
+
+// pruneJobs drops jobs that finished more than jobRetention before now. The
+// caller holds jobsMu.
+func (m *Manager) pruneJobs(now time.Time) {
+	for id, job := range m.jobs {
+		job.mu.Lock()
+		expired := job.status != JobRunning && now.Sub(job.finishedAt) > jobRetention
+		job.mu.Unlock()
+		if expired {
+			delete(m.jobs, id)
+		}
+	}
+}
+
+// cancelJobs stops running extractions and drops all jobs
+func (m *Manager) cancelJobs() {
+	m.jobsMu.Lock()
+	defer m.jobsMu.Unlock()
+	for id, job := range m.jobs {
+		job.cancel()
+		delete(m.jobs, id)
+	}
+}
+
+// addPage records an extracted page; pages reported after the job finished,
+// by an extractor that outlived its timeout, are dropped
+func (j *extractionJob) addPage(_, total int, text string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.status != JobRunning {
+		return
+	}
+	j.total = total
+	j.pages = append(j.pages, text)
+}
+
+// finish records the outcome of the extraction. Formats without pages become
+// a single page holding the whole text.
+func (j *extractionJob) finish(text string, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.finishedAt = time.Now()
+	if err != nil {
+		j.status, j.err = JobFailed, err
+		return
+	}
+	j.status = JobCompleted
+	if len(j.pages) == 0 {
+		j.pages = []string{text}
+	}
+	j.total = len(j.pages)
+}
+
+// snapshot returns the current status of the job
+func (j *extractionJob) snapshot() *ExtractionStatus {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	status := &ExtractionStatus{
+		JobID:      j.id,
+		FilePath:   j.filePath,
+		Status:     j.status,
+		PagesDone:  len(j.pages),
+		TotalPages: j.total,
+		Chunks:     j.chunks(),
+		ChunkPages: ProgressPageInterval,
+		StartedAt:  j.startedAt,
+		FinishedAt: j.finishedAt,
+	}
+	if j.err != nil {
+		status.Error = j.err.Error()
+	}
+	return status
+}
+
+// chunks counts the chunks that can be read: the full ones, plus the partial
+// last one once the job has finished. The caller holds j.mu.
+func (j *extractionJob) chunks() int {
+	chunks := len(j.pages) / ProgressPageInterval
+	if j.status != JobRunning && len(j.pages)%ProgressPageInterval != 0 {
+		chunks++
+	}
+	return chunks
+}
+
+// totalText is the page count for messages, "?" while it is unknown. The
+// caller holds j.mu.
+func (j *extractionJob) totalText() string {
+	if j.total == 0 {
+		return "?"
+	}
+	return fmt.Sprint(j.total)
+}
+
+// newJobID returns a random identifier for a background extraction
+func newJobID() (string, error) {
+	var id [8]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return "", fmt.Errorf("failed to create job ID: %w", err)
+	}
+	return hex.EncodeToString(id[:]), nil
+}
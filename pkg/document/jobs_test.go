@@ -0,0 +1,100 @@
+package document
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// waitForJob polls a background extraction until it finishes
+func waitForJob(t *testing.T, m *Manager, jobID string) *ExtractionStatus {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		status, err := m.GetExtractionStatus(jobID)
+		if err != nil {
+			t.Fatalf("GetExtractionStatus failed: %v", err)
+		}
+		if status.Status != JobRunning {
+			return status
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Extraction job %s did not finish", jobID)
+	return nil
+}
+
+func TestBackgroundExtraction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manual.pdf")
+	pages := make([]string, 25)
+	for i := range pages {
+		pages[i] = fmt.Sprintf("Page%d", i+1)
+	}
+	writeTestPDF(t, path, pages...)
+
+	m := NewManager()
+	defer m.Close()
+	started, err := m.StartExtraction(context.Background(), path)
+	if err != nil {
+		t.Fatalf("StartExtraction failed: %v", err)
+	}
+	status := waitForJob(t, m, started.JobID)
+	if status.Status != JobCompleted || status.PagesDone != 25 || status.TotalPages != 25 || status.Chunks != 3 || status.FinishedAt.IsZero() {
+		t.Fatalf("Unexpected status: %+v", status)
+	}
+
+	chunk, err := m.GetExtractionChunk(started.JobID, 2)
+	if err != nil {
+		t.Fatalf("GetExtractionChunk failed: %v", err)
+	}
+	if chunk.FirstPage != 21 || chunk.LastPage != 25 || !strings.HasPrefix(chunk.Text, "Page21") || !strings.HasSuffix(chunk.Text, "Page25") {
+		t.Errorf("Unexpected chunk: %+v", chunk)
+	}
+	if _, err := m.GetExtractionChunk(started.JobID, 3); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("Expected a missing chunk error, got %v", err)
+	}
+	if _, err := m.GetExtractionStatus("unknown"); err == nil || !strings.Contains(err.Error(), "job not found") {
+		t.Errorf("Expected an unknown job error, got %v", err)
+	}
+}
+
+func TestBackgroundExtractionWithoutPages(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.md")
+	if err := os.WriteFile(path, []byte("# Notes\n\nShort text."), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewManager()
+	defer m.Close()
+	started, err := m.StartExtraction(context.Background(), path)
+	if err != nil {
+		t.Fatalf("StartExtraction failed: %v", err)
+	}
+	if status := waitForJob(t, m, started.JobID); status.Status != JobCompleted || status.Chunks != 1 {
+		t.Fatalf("Unexpected status: %+v", status)
+	}
+	chunk, err := m.GetExtractionChunk(started.JobID, 0)
+	if err != nil || !strings.Contains(chunk.Text, "Short text.") {
+		t.Errorf("GetExtractionChunk = %+v, %v", chunk, err)
+	}
+}
+
+func TestPageProgress(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manual.pdf")
+	writeTestPDF(t, path, "One", "Two", "Three")
+
+	var reported []string
+	ctx := withPageProgress(context.Background(), func(page, total int, text string) {
+		reported = append(reported, fmt.Sprintf("%d/%d %s", page, total, strings.TrimSpace(text)))
+	})
+	if _, err := NewManager().ExtractText(ctx, path); err != nil {
+		t.Fatalf("ExtractText failed: %v", err)
+	}
+	if strings.Join(reported, ", ") != "1/3 One, 2/3 Two, 3/3 Three" {
+		t.Errorf("Reported pages = %v", reported)
+	}
+}
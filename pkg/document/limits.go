@@ -0,0 +1,115 @@
+package document
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/kevsmith/my-mcp/pkg/shared"
+)
+
+// Limits bounds the work done for a single document
+type Limits struct {
+	MaxFileSize int64         // Largest file in bytes accepted for extraction; 0 disables the check
+	Timeout     time.Duration // Longest a single extraction may run; 0 disables the timeout
+
+	AllowInternalURLs bool // Let extract_from_url fetch loopback, private and link-local addresses
+}
+
+// GetLimits returns extraction limits from environment variables or defaults.
+// Setting a variable to 0 disables that limit.
+func GetLimits() Limits {
+	limits := Limits{
+		MaxFileSize: 100 << 20,        // Default 100 MB
+		Timeout:     60 * time.Second, // Default 60 second timeout
+	}
+
+	if sizeStr := os.Getenv("DOCUMENT_MAX_FILE_SIZE_MB"); sizeStr != "" {
+		if sizeMB, err := strconv.ParseInt(sizeStr, 10, 64); err == nil && sizeMB >= 0 {
+			limits.MaxFileSize = sizeMB << 20
+		}
+	}
+
+	if timeoutStr := os.Getenv("DOCUMENT_EXTRACT_TIMEOUT_SECONDS"); timeoutStr != "" {
+		if seconds, err := strconv.Atoi(timeoutStr); err == nil && seconds >= 0 {
+			limits.Timeout = time.Duration(seconds) * time.Second
+		}
+	}
+
+	if allow, err := strconv.ParseBool(os.Getenv("DOCUMENT_ALLOW_INTERNAL_URLS")); err == nil {
+		limits.AllowInternalURLs = allow
+	}
+
+	return limits
+}
+
+// SetLimits replaces the manager's extraction limits
+func (m *Manager) SetLimits(limits Limits) {
+	m.limits = limits
+}
+
+// checkFileSize rejects files larger than the configured maximum. Files that
+// can't be stat'ed pass so that extraction reports the real problem.
+func (m *Manager) checkFileSize(filePath string) error {
+	if m.limits.MaxFileSize <= 0 {
+		return nil
+	}
+	stat, err := os.Stat(filePath)
+	if err != nil {
+		return nil
+	}
+	if stat.Size() > m.limits.MaxFileSize {
+		return shared.NewError(shared.CodeTooLarge, "file is too large: %d bytes exceeds the limit of %d bytes", stat.Size(), m.limits.MaxFileSize)
+	}
+	return nil
+}
+
+// withTimeout runs fn under the manager's extraction timeout and returns as
+// soon as ctx is done. Extractors check ctx between pages or chapters and stop
+// early; those built on libraries that can't be interrupted finish in the
+// background and their result is discarded.
+func withTimeout[T any](ctx context.Context, timeout time.Duration, fn func(context.Context) (T, error)) (T, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	type outcome struct {
+		value T
+		err   error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		// A panic here would take down the whole server
+		defer func() {
+			if r := recover(); r != nil {
+				done <- outcome{err: fmt.Errorf("extraction failed: %v", r)}
+			}
+		}()
+		value, err := fn(ctx)
+		done <- outcome{value, err}
+	}()
+
+	select {
+	case result := <-done:
+		if result.err != nil && ctx.Err() != nil {
+			return result.value, stoppedError(ctx, timeout)
+		}
+		return result.value, result.err
+	case <-ctx.Done():
+		var zero T
+		return zero, stoppedError(ctx, timeout)
+	}
+}
+
+// stoppedError describes why an extraction stopped before finishing
+func stoppedError(ctx context.Context, timeout time.Duration) error {
+	if timeout > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("extraction timed out after %s: %w", timeout, ctx.Err())
+	}
+	return fmt.Errorf("extraction cancelled: %w", ctx.Err())
+}
@@ -0,0 +1,116 @@
+package document
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetLimits(t *testing.T) {
+	t.Setenv("DOCUMENT_MAX_FILE_SIZE_MB", "")
+	t.Setenv("DOCUMENT_EXTRACT_TIMEOUT_SECONDS", "")
+	if limits := GetLimits(); limits.MaxFileSize != 100<<20 || limits.Timeout != time.Minute {
+		t.Errorf("Unexpected default limits: %+v", limits)
+	}
+
+	t.Setenv("DOCUMENT_MAX_FILE_SIZE_MB", "5")
+	t.Setenv("DOCUMENT_EXTRACT_TIMEOUT_SECONDS", "0")
+	if limits := GetLimits(); limits.MaxFileSize != 5<<20 || limits.Timeout != 0 {
+		t.Errorf("Unexpected limits from environment: %+v", limits)
+	}
+
+	// Invalid values keep the defaults
+	t.Setenv("DOCUMENT_MAX_FILE_SIZE_MB", "-1")
+	t.Setenv("DOCUMENT_EXTRACT_TIMEOUT_SECONDS", "soon")
+	if limits := GetLimits(); limits.MaxFileSize != 100<<20 || limits.Timeout != time.Minute {
+		t.Errorf("Expected defaults for invalid values, got %+v", limits)
+	}
+}
+
+func TestFileSizeLimit(t *testing.T) {
+	dir := t.TempDir()
+	textPath := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(textPath, []byte(strings.Repeat("word ", 100)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	pdfPath := filepath.Join(dir, "report.pdf")
+	writeTestPDF(t, pdfPath, "Page one")
+
+	manager := NewManager()
+	manager.SetLimits(Limits{MaxFileSize: 64})
+
+	if _, err := manager.ExtractText(context.Background(), textPath); err == nil || !strings.Contains(err.Error(), "file is too large") {
+		t.Errorf("Expected a file size error, got %v", err)
+	}
+	if _, err := manager.ExtractPages(context.Background(), pdfPath, ""); err == nil || !strings.Contains(err.Error(), "file is too large") {
+		t.Errorf("Expected a file size error, got %v", err)
+	}
+
+	// Document info still reports the file, without reading its content
+	info, err := manager.GetDocumentInfo(context.Background(), textPath)
+	if err != nil {
+		t.Fatalf("GetDocumentInfo failed: %v", err)
+	}
+	if info.FileSize != 500 || info.WordCount != 0 {
+		t.Errorf("Unexpected info for an oversized file: %+v", info)
+	}
+}
+
+func TestExtractionTimeout(t *testing.T) {
+	manager := NewManager()
+	manager.SetLimits(Limits{Timeout: 20 * time.Millisecond})
+	manager.RegisterFormat(Format{Type: DocumentTypeText, Name: "text", Extensions: []string{".txt"}, Extract: func(ctx context.Context, filePath string) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	}})
+
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("slow"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := manager.ExtractText(context.Background(), path)
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "timed out after 20ms") {
+		t.Errorf("Expected a timeout error, got %v", err)
+	}
+}
+
+func TestExtractionCancelled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.pdf")
+	writeTestPDF(t, path, "Page one", "Page two")
+
+	manager := NewManager()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := manager.ExtractText(ctx, path); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a cancellation error, got %v", err)
+	}
+	if _, err := manager.ExtractPages(ctx, path, ""); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a cancellation error, got %v", err)
+	}
+
+	// Cancelled extractions are not cached
+	if text, err := manager.ExtractText(context.Background(), path); err != nil || !strings.Contains(text, "Page two") {
+		t.Errorf("ExtractText = %q, %v", text, err)
+	}
+}
+
+func TestExtractionPanicRecovered(t *testing.T) {
+	manager := NewManager()
+	manager.RegisterFormat(Format{Type: DocumentTypeText, Name: "text", Extensions: []string{".txt"}, Extract: func(_ context.Context, filePath string) (string, error) {
+		panic("malformed object")
+	}})
+
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("text"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := manager.ExtractText(context.Background(), path); err == nil || !strings.Contains(err.Error(), "malformed object") {
+		t.Errorf("Expected the panic to become an error, got %v", err)
+	}
+}
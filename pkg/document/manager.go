@@ -1,16 +1,21 @@
 package document
 
 import (
+	"bytes"
+	"context"
 	"encoding/xml"
+	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"code.sajari.com/docconv"
-	"github.com/ledongthuc/pdf"
+	"github.com/kevsmith/my-mcp/pkg/shared"
 	"github.com/nguyenthenguyen/docx"
 )
 
@@ -26,6 +31,13 @@ var (
 	pdfMagic = []byte{0x25, 0x50, 0x44, 0x46}             // %PDF
 	zipMagic = []byte{0x50, 0x4B, 0x03, 0x04}             // PK.. (ZIP-based formats)
 	oleDoc   = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1} // Old DOC/PPT files
+	rtfMagic = []byte(`{\rtf`)
+)
+
+// MIME types stored uncompressed at the start of OpenDocument and EPUB archives
+var (
+	odtMimeType  = []byte("application/vnd.oasis.opendocument.text")
+	epubMimeType = []byte("application/epub+zip")
 )
 
 // DocumentType represents the detected file type
@@ -38,16 +50,85 @@ const (
 	DocumentTypePPTX
 	DocumentTypeDOC
 	DocumentTypePPT
+	DocumentTypeRTF
+	DocumentTypeODT
+	DocumentTypeEPUB
+	DocumentTypeHTML
+	DocumentTypeMarkdown
+	DocumentTypeText
 )
 
-type Manager struct{}
+// documentTypeExternal is the first type assigned to external converters
+const documentTypeExternal = DocumentTypeText + 1
+
+type Manager struct {
+	formats     map[DocumentType]Format
+	cache       *ResultCache
+	stopCleanup func()
+	limits      Limits
+
+	jobsMu sync.Mutex
+	jobs   map[string]*extractionJob // Background extractions by job ID
+}
 
 func NewManager() *Manager {
-	return &Manager{}
+	return NewManagerWithConfig(GetCacheConfig())
+}
+
+// NewManagerWithConfig creates a document manager with a custom result cache config
+func NewManagerWithConfig(config CacheConfig) *Manager {
+	cache := NewResultCache(config)
+
+	m := &Manager{
+		formats: make(map[DocumentType]Format),
+		cache:   cache,
+		limits:  GetLimits(),
+		jobs:    make(map[string]*extractionJob),
+	}
+	m.registerBuiltinFormats()
+
+	// Start cleanup ticker to remove expired entries every minute
+	m.stopCleanup = cache.StartCleanupTicker(time.Minute)
+
+	return m
+}
+
+// Close stops the cache cleanup and background extractions and drops cached
+// results
+func (m *Manager) Close() {
+	if m.stopCleanup != nil {
+		m.stopCleanup()
+	}
+	if m.cache != nil {
+		m.cache.Clear()
+	}
+	m.cancelJobs()
 }
 
-// detectFileType detects file type using magic numbers for better accuracy
+// CacheStats returns the result cache's hit and miss counts and size
+func (m *Manager) CacheStats() shared.CacheStats {
+	if m.cache == nil {
+		return shared.CacheStats{}
+	}
+	return m.cache.Stats()
+}
+
+// detectFileType detects file type using magic numbers for better accuracy.
+// Files whose content isn't recognized go to the external converter claiming
+// their extension, if any.
 func (m *Manager) detectFileType(filePath string) DocumentType {
+	documentType := m.detectContentType(filePath)
+	if documentType == DocumentTypeUnknown {
+		if format, ok := m.formatForExtension(filepath.Ext(filePath)); ok && format.Type >= documentTypeExternal {
+			return format.Type
+		}
+	}
+	return documentType
+}
+
+// detectContentType detects the built-in format of a file from its magic
+// number, and from its extension for text and container formats
+func (m *Manager) detectContentType(filePath string) DocumentType {
 	file, err := os.Open(filePath)
 	if err != nil {
 		return DocumentTypeUnknown
@@ -58,7 +139,7 @@ func (m *Manager) detectFileType(filePath string) DocumentType {
 	buffer := make([]byte, 512)
 	n, err := file.Read(buffer)
 	if err != nil || n < 4 {
-		return DocumentTypeUnknown
+		return textDocumentType(filePath, buffer[:n])
 	}
 
 	// Check for PDF magic number
@@ -66,7 +147,12 @@ func (m *Manager) detectFileType(filePath string) DocumentType {
 		return DocumentTypePDF
 	}
 
-	// Check for ZIP-based formats (DOCX, PPTX)
+	// Check for RTF, which starts with its opening group
+	if bytes.HasPrefix(buffer[:n], rtfMagic) {
+		return DocumentTypeRTF
+	}
+
+	// Check for ZIP-based formats (DOCX, PPTX, ODT, EPUB)
 	if len(buffer) >= len(zipMagic) && bytesEqual(buffer[:len(zipMagic)], zipMagic) {
 		// Differentiate between DOCX and PPTX by checking internal structure
 		ext := strings.ToLower(filepath.Ext(filePath))
@@ -76,6 +162,13 @@ func (m *Manager) detectFileType(filePath string) DocumentType {
 		case ".pptx":
 			return DocumentTypePPTX
 		}
+		// OpenDocument and EPUB archives begin with an uncompressed mimetype entry
+		switch {
+		case bytes.Contains(buffer[:n], odtMimeType):
+			return DocumentTypeODT
+		case bytes.Contains(buffer[:n], epubMimeType):
+			return DocumentTypeEPUB
+		}
 		return DocumentTypeUnknown
 	}
 
@@ -91,7 +184,7 @@ func (m *Manager) detectFileType(filePath string) DocumentType {
 		return DocumentTypeUnknown
 	}
 
-	return DocumentTypeUnknown
+	return textDocumentType(filePath, buffer[:n])
 }
 
 // bytesEqual compares two byte slices
@@ -113,59 +206,146 @@ type DocumentInfo struct {
 	ModTime     time.Time
 	Extension   string
 	IsSupported bool
+	Encrypted   bool // Password protected; properties are only read with the password
+	DocumentProperties
+	DocumentSecurity
+	Metadata map[string]string // Format-specific properties, when the format provides them
 }
 
-func (m *Manager) ExtractText(filePath string) (string, error) {
-	// Use magic number detection for more accurate file type identification
-	docType := m.detectFileType(filePath)
-
-	switch docType {
-	case DocumentTypePDF:
-		return m.extractPDFText(filePath)
-	case DocumentTypeDOCX:
-		return m.extractDocxText(filePath)
-	case DocumentTypePPTX:
-		return m.extractPptxText(filePath)
-	case DocumentTypeDOC:
-		return "", fmt.Errorf("DOC files are not yet supported, please convert to DOCX format")
-	case DocumentTypePPT:
-		return "", fmt.Errorf("PPT files are not yet supported, please convert to PPTX format")
-	default:
-		// Fall back to extension-based detection if magic number fails
-		ext := strings.ToLower(filepath.Ext(filePath))
-		switch ext {
-		case ".pdf", ".docx", ".pptx":
-			return "", fmt.Errorf("file appears to be corrupted or invalid %s format", ext)
-		case ".doc":
-			return "", fmt.Errorf("DOC files are not yet supported, please convert to DOCX format")
-		case ".ppt":
-			return "", fmt.Errorf("PPT files are not yet supported, please convert to PPTX format")
-		default:
-			return "", fmt.Errorf("unsupported file format: %s", ext)
+// ExtractText detects the format of a file from its content and returns its
+// clean text using the extractor registered for that format. Extraction stops
+// when ctx is done or the configured timeout expires. Encrypted PDF and
+// Office documents are opened with the password set with WithPassword.
+func (m *Manager) ExtractText(ctx context.Context, filePath string) (string, error) {
+	if err := m.checkFileSize(filePath); err != nil {
+		return "", err
+	}
+
+	// Text decrypted with a password must not be served to callers without it
+	key, cacheable := m.cacheKey(filePath)
+	cacheable = cacheable && passwordFromContext(ctx) == ""
+	if cacheable {
+		if cached, found := m.cache.Get(key); found && cached.HasText {
+			return cached.Text, nil
 		}
 	}
+
+	text, err := withTimeout(ctx, m.limits.Timeout, func(ctx context.Context) (string, error) {
+		return m.extractUnlocked(ctx, filePath)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if cacheable {
+		m.cache.Update(key, func(result *CachedResult) {
+			result.Text, result.HasText = text, true
+		})
+	}
+	return text, nil
 }
 
-func (m *Manager) GetDocumentInfo(filePath string) (*DocumentInfo, error) {
+// extractUnlocked extracts the text of a file, decrypting Office packages
+// with the password in ctx first
+func (m *Manager) extractUnlocked(ctx context.Context, filePath string) (string, error) {
+	document, err := m.unlock(ctx, filePath)
+	if err != nil {
+		return "", err
+	}
+	defer document.Close()
+	return m.extract(ctx, document.Path)
+}
+
+func (m *Manager) GetDocumentInfo(ctx context.Context, filePath string) (*DocumentInfo, error) {
 	stat, err := os.Stat(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get file info: %w", err)
 	}
 
 	ext := strings.ToLower(filepath.Ext(filePath))
-	isSupported := ext == ".pdf" || ext == ".docx" || ext == ".pptx" || ext == ".doc" || ext == ".ppt"
+	// Content detection also covers files whose name hides their format
+	format, detected := m.formats[m.detectFileType(filePath)]
+	isSupported := detected || m.isSupportedPath(filePath)
 
-	return &DocumentInfo{
+	info := &DocumentInfo{
 		FilePath:    filePath,
 		FileSize:    stat.Size(),
 		ModTime:     stat.ModTime(),
 		Extension:   ext,
 		IsSupported: isSupported,
-	}, nil
+	}
+
+	// Reading the properties of an oversized file is as costly as extracting it
+	if !isSupported || m.checkFileSize(filePath) != nil {
+		return info, nil
+	}
+
+	// Without the password an encrypted document only shows its file stats
+	password := passwordFromContext(ctx)
+	document, err := m.unlock(ctx, filePath)
+	if err == nil && format.Type == DocumentTypePDF {
+		document.Encrypted, err = pdfEncrypted(filePath, password)
+	}
+	var passwordErr *PasswordError
+	if errors.As(err, &passwordErr) && !passwordErr.Missing {
+		return nil, err
+	}
+	if err != nil {
+		info.Encrypted = true
+		return info, nil
+	}
+	defer document.Close()
+	info.Encrypted = document.Encrypted
+	if document.Path != filePath {
+		format, detected = m.formats[m.detectFileType(document.Path)]
+	}
+
+	// Properties read with a password must not be served to callers without it
+	var cached CachedResult
+	key, cacheable := m.cacheKey(filePath)
+	cacheable = cacheable && password == ""
+	if cacheable {
+		cached, _ = m.cache.Get(key)
+	}
+
+	if cached.Properties != nil {
+		info.DocumentProperties = *cached.Properties
+		info.Metadata = maps.Clone(cached.Metadata)
+	} else {
+		// Properties are best effort; unreadable ones don't hide the file stats
+		info.DocumentProperties = m.documentProperties(ctx, document.Path)
+		if detected && format.Metadata != nil {
+			if metadata, err := format.Metadata(document.Path); err == nil && len(metadata) > 0 {
+				info.Metadata = metadata
+			}
+		}
+
+		if cacheable {
+			properties, metadata := info.DocumentProperties, maps.Clone(info.Metadata)
+			m.cache.Update(key, func(result *CachedResult) {
+				result.Properties, result.Metadata = &properties, metadata
+			})
+		}
+	}
+
+	info.DocumentSecurity = m.documentSecurity(document.Path, password)
+
+	if info.Title == "" {
+		info.Title = info.Metadata["title"]
+	}
+	if info.Author == "" {
+		info.Author = info.Metadata["author"]
+	}
+
+	return info, nil
 }
 
-func (m *Manager) extractPDFText(filePath string) (string, error) {
-	file, reader, err := pdf.Open(filePath)
+func (m *Manager) extractPDFText(ctx context.Context, filePath string) (string, error) {
+	file, reader, err := openPDF(filePath, passwordFromContext(ctx))
+	var passwordErr *PasswordError
+	if errors.As(err, &passwordErr) {
+		return "", err
+	}
 	if err != nil {
 		return "", fmt.Errorf("failed to open PDF file: %w", err)
 	}
@@ -177,24 +357,26 @@ func (m *Manager) extractPDFText(filePath string) (string, error) {
 	text.Grow(totalPages * 2048)
 
 	for pageIndex := 1; pageIndex <= totalPages; pageIndex++ {
-		page := reader.Page(pageIndex)
-		if page.V.IsNull() {
-			continue
+		if err := ctx.Err(); err != nil {
+			return "", err
 		}
 
-		pageText, err := page.GetPlainText(nil)
-		if err != nil {
-			continue // Skip pages that can't be read
+		pageText := ""
+		if page := reader.Page(pageIndex); !page.V.IsNull() {
+			// Pages that can't be read are skipped
+			if extracted, err := page.GetPlainText(nil); err == nil {
+				pageText = extracted
+				text.WriteString(pageText)
+				text.WriteString("\n")
+			}
 		}
-
-		text.WriteString(pageText)
-		text.WriteString("\n")
+		reportPage(ctx, pageIndex, totalPages, pageText)
 	}
 
 	return strings.TrimSpace(text.String()), nil
 }
 
-func (m *Manager) extractDocxText(filePath string) (string, error) {
+func (m *Manager) extractDocxText(_ context.Context, filePath string) (string, error) {
 	reader, err := docx.ReadDocxFile(filePath)
 	if err != nil {
 		return "", fmt.Errorf("failed to open DOCX file: %w", err)
@@ -213,7 +395,7 @@ func (m *Manager) extractDocxText(filePath string) (string, error) {
 	return strings.TrimSpace(cleanText), nil
 }
 
-func (m *Manager) extractPptxText(filePath string) (string, error) {
+func (m *Manager) extractPptxText(_ context.Context, filePath string) (string, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
 		return "", fmt.Errorf("failed to open PPTX file: %w", err)
@@ -232,10 +414,29 @@ func (m *Manager) extractPptxText(filePath string) (string, error) {
 	return strings.TrimSpace(cleanText), nil
 }
 
+func (m *Manager) extractODTText(_ context.Context, filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open ODT file: %w", err)
+	}
+	defer file.Close()
+
+	// Use docconv to extract text from the content.xml part
+	plainText, _, err := docconv.ConvertODT(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to extract text from ODT: %w", err)
+	}
+
+	return strings.TrimSpace(m.cleanExtractedText(plainText)), nil
+}
+
 // extractCleanTextFromXML parses XML content and extracts only the readable text
 func (m *Manager) extractCleanTextFromXML(xmlContent string) (string, error) {
 	var result strings.Builder
 	decoder := xml.NewDecoder(strings.NewReader(xmlContent))
+	// Documents arrive from untrusted sources; tolerate unknown entities and
+	// unbalanced tags instead of silently dropping everything after them
+	decoder.Strict = false
 
 	for {
 		token, err := decoder.Token()
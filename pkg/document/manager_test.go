@@ -1,6 +1,7 @@
 package document
 
 import (
+	"context"
 	"os"
 	"testing"
 )
@@ -14,7 +15,7 @@ func TestNewManager(t *testing.T) {
 
 func TestGetDocumentInfo_NonExistentFile(t *testing.T) {
 	manager := NewManager()
-	_, err := manager.GetDocumentInfo("nonexistent.pdf")
+	_, err := manager.GetDocumentInfo(context.Background(), "nonexistent.pdf")
 	if err == nil {
 		t.Fatal("Expected error for non-existent file")
 	}
@@ -22,22 +23,22 @@ func TestGetDocumentInfo_NonExistentFile(t *testing.T) {
 
 func TestExtractText_UnsupportedFormat(t *testing.T) {
 	manager := NewManager()
-	_, err := manager.ExtractText("test.txt")
+	_, err := manager.ExtractText(context.Background(), "test.xyz")
 	if err == nil {
 		t.Fatal("Expected error for unsupported format")
 	}
-	if err.Error() != "unsupported file format: .txt" {
+	if err.Error() != "unsupported file format: .xyz" {
 		t.Fatalf("Unexpected error message: %s", err.Error())
 	}
 }
 
 func TestExtractText_DocFormat(t *testing.T) {
 	manager := NewManager()
-	_, err := manager.ExtractText("test.doc")
+	_, err := manager.ExtractText(context.Background(), "test.doc")
 	if err == nil {
-		t.Fatal("Expected error for DOC format")
+		t.Fatal("Expected error for missing DOC file")
 	}
-	expectedMsg := "DOC files are not yet supported, please convert to DOCX format"
+	expectedMsg := "file appears to be corrupted or invalid .doc format"
 	if err.Error() != expectedMsg {
 		t.Fatalf("Unexpected error message: %s", err.Error())
 	}
@@ -45,11 +46,11 @@ func TestExtractText_DocFormat(t *testing.T) {
 
 func TestExtractText_PptFormat(t *testing.T) {
 	manager := NewManager()
-	_, err := manager.ExtractText("test.ppt")
+	_, err := manager.ExtractText(context.Background(), "test.ppt")
 	if err == nil {
-		t.Fatal("Expected error for PPT format")
+		t.Fatal("Expected error for missing PPT file")
 	}
-	expectedMsg := "PPT files are not yet supported, please convert to PPTX format"
+	expectedMsg := "file appears to be corrupted or invalid .ppt format"
 	if err.Error() != expectedMsg {
 		t.Fatalf("Unexpected error message: %s", err.Error())
 	}
@@ -65,7 +66,7 @@ func TestGetDocumentInfo_PptxSupported(t *testing.T) {
 	defer tmpfile.Close()
 
 	manager := NewManager()
-	info, err := manager.GetDocumentInfo(tmpfile.Name())
+	info, err := manager.GetDocumentInfo(context.Background(), tmpfile.Name())
 	if err != nil {
 		t.Fatalf("Failed to get document info: %v", err)
 	}
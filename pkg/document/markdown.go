@@ -0,0 +1,96 @@
+package document
+
+import (
+	"context"
+	"strings"
+)
+
+func (m *Manager) extractMarkdownText(_ context.Context, filePath string) (string, error) {
+	content, err := readTextFile(filePath, "Markdown")
+	if err != nil {
+		return "", err
+	}
+
+	// Markdown is already readable prose; only the front matter is dropped
+	// (it is reported by get_document_info) and stray control characters removed
+	_, body := splitFrontMatter(content)
+	body = strings.ReplaceAll(body, "\r\n", "\n")
+	return strings.TrimSpace(controlPattern.ReplaceAllString(body, "")), nil
+}
+
+// markdownMetadata returns the front matter fields of a Markdown document and,
+// when it has no title field, the text of its first level-one heading
+func markdownMetadata(filePath string) (map[string]string, error) {
+	content, err := readTextFile(filePath, "Markdown")
+	if err != nil {
+		return nil, err
+	}
+
+	frontMatter, body := splitFrontMatter(content)
+	metadata := parseFrontMatter(frontMatter)
+	if _, ok := metadata["title"]; !ok {
+		for _, line := range strings.Split(body, "\n") {
+			if title, ok := strings.CutPrefix(strings.TrimSpace(line), "# "); ok {
+				metadata["title"] = strings.TrimSpace(title)
+				break
+			}
+		}
+	}
+
+	return metadata, nil
+}
+
+// splitFrontMatter separates a leading YAML front matter block, delimited by
+// "---" lines, from the rest of a Markdown document
+func splitFrontMatter(content string) (string, string) {
+	normalized := strings.ReplaceAll(content, "\r\n", "\n")
+	if !strings.HasPrefix(normalized, "---\n") {
+		return "", content
+	}
+
+	rest := normalized[len("---\n"):]
+	for offset := 0; offset < len(rest); {
+		end := strings.IndexByte(rest[offset:], '\n')
+		line := rest[offset:]
+		if end >= 0 {
+			line = rest[offset : offset+end]
+		}
+		if trimmed := strings.TrimSpace(line); trimmed == "---" || trimmed == "..." {
+			body := ""
+			if end >= 0 {
+				body = rest[offset+end+1:]
+			}
+			return rest[:offset], body
+		}
+		if end < 0 {
+			break
+		}
+		offset += end + 1
+	}
+
+	return "", content // Unterminated block: treat it as content
+}
+
+// parseFrontMatter reads the top-level "key: value" pairs of a front matter
+// block; nested structures are not interpreted and list values are kept as written
+func parseFrontMatter(frontMatter string) map[string]string {
+	metadata := make(map[string]string)
+	for _, line := range strings.Split(frontMatter, "\n") {
+		if line == "" || line[0] == ' ' || line[0] == '\t' || line[0] == '#' {
+			continue // Blank, nested or comment line
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		if key != "" && value != "" {
+			metadata[key] = value
+		}
+	}
+	return metadata
+}
@@ -0,0 +1,385 @@
+package document
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/kevsmith/my-mcp/pkg/shared"
+	"github.com/richardlehane/mscfb"
+	"github.com/richardlehane/msoleps"
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Legacy .doc and .ppt files are OLE compound files: a small FAT file system
+// holding binary streams. Text is read natively from those streams so that no
+// external converter (wvText, LibreOffice) has to be installed.
+
+// Streams and markers of the Word 97-2003 binary format ([MS-DOC])
+const (
+	docWordStream  = "WordDocument"
+	docFibIdent    = 0xA5EC     // wIdent of the File Information Block
+	docEncrypted   = 0x0100     // FibBase.fEncrypted
+	docWhichTable  = 0x0200     // FibBase.fWhichTblStm: 1Table instead of 0Table
+	docClxPair     = 33         // Index of fcClx/lcbClx in FibRgFcLcb97
+	docCompressed  = 0x40000000 // FcCompressed.fCompressed: 8-bit cp1252 text at fc/2
+	docFcMask      = 0x3FFFFFFF
+	docFieldBegin  = 0x13
+	docFieldSep    = 0x14
+	docFieldEnd    = 0x15
+	docPieceLength = 8 // Size of a PCD in the piece table
+)
+
+// Streams and record types of the PowerPoint 97-2003 binary format ([MS-PPT])
+const (
+	pptDocumentStream    = "PowerPoint Document"
+	pptContainerVersion  = 0xF
+	pptSlideListWithText = 0x0FF0
+	pptNotes             = 0x03F0
+	pptMainMaster        = 0x03F8
+	pptHandout           = 0x0FC9
+	pptTextCharsAtom     = 0x0FA0
+	pptTextBytesAtom     = 0x0FA8
+	pptMaxDepth          = 32 // Deeper nesting only occurs in corrupt files
+)
+
+// readOLEStreams returns the named top-level streams of a compound file
+func readOLEStreams(filePath string, names ...string) (map[string][]byte, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	reader, err := mscfb.New(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OLE compound file: %w", err)
+	}
+
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+
+	streams := make(map[string][]byte)
+	for _, entry := range reader.File {
+		if len(entry.Path) != 0 || !wanted[entry.Name] {
+			continue
+		}
+		data, err := io.ReadAll(entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s stream: %w", entry.Name, err)
+		}
+		streams[entry.Name] = data
+	}
+
+	return streams, nil
+}
+
+// oleProperties reads the summary information property sets (title, author,
+// page and slide counts) of a compound file, keyed by property name
+func oleProperties(filePath string) map[string]string {
+	properties := make(map[string]string)
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return properties
+	}
+	defer file.Close()
+
+	reader, err := mscfb.New(file)
+	if err != nil {
+		return properties
+	}
+
+	props := msoleps.New()
+	for _, entry := range reader.File {
+		if len(entry.Path) != 0 || !msoleps.IsMSOLEPS(entry.Initial) {
+			continue
+		}
+		if err := props.Reset(entry); err != nil {
+			continue
+		}
+		for _, prop := range props.Property {
+			properties[prop.Name] = prop.String()
+		}
+	}
+
+	return properties
+}
+
+// olePageCount returns the page count of a .doc or the slide count of a .ppt
+// as recorded in its summary information, or 0 if unknown
+func olePageCount(filePath string, docType DocumentType) int {
+	name := "PageCount"
+	if docType == DocumentTypePPT {
+		name = "Slide count"
+	}
+
+	count, err := strconv.Atoi(oleProperties(filePath)[name])
+	if err != nil || count < 0 {
+		return 0
+	}
+	return count
+}
+
+func (m *Manager) extractDocText(_ context.Context, filePath string) (string, error) {
+	streams, err := readOLEStreams(filePath, docWordStream, "0Table", "1Table")
+	if err != nil {
+		return "", fmt.Errorf("failed to open DOC file: %w", err)
+	}
+
+	text, err := docMainText(streams)
+	if err != nil {
+		return "", fmt.Errorf("failed to extract text from DOC: %w", err)
+	}
+
+	return strings.TrimSpace(m.cleanExtractedText(text)), nil
+}
+
+// docMainText reassembles the main document text from the piece table. Word
+// stores text in pieces that are either cp1252 bytes or UTF-16 code units;
+// the piece table in the table stream maps character positions to them.
+func docMainText(streams map[string][]byte) (string, error) {
+	word := streams[docWordStream]
+	if len(word) < 34 || binary.LittleEndian.Uint16(word) != docFibIdent {
+		return "", fmt.Errorf("not a Word 97-2003 document")
+	}
+
+	flags := binary.LittleEndian.Uint16(word[0x0A:])
+	if flags&docEncrypted != 0 {
+		return "", shared.NewError(shared.CodeUnsupportedFormat, "encrypted DOC files are not supported")
+	}
+	tableName := "0Table"
+	if flags&docWhichTable != 0 {
+		tableName = "1Table"
+	}
+	table := streams[tableName]
+	if table == nil {
+		return "", fmt.Errorf("%s stream is missing", tableName)
+	}
+
+	// The FIB is a FibBase followed by three variable-length arrays
+	pos := 32
+	csw := int(binary.LittleEndian.Uint16(word[pos:]))
+	pos += 2 + csw*2
+	if pos+2 > len(word) {
+		return "", fmt.Errorf("truncated file information block")
+	}
+	cslw := int(binary.LittleEndian.Uint16(word[pos:]))
+	fibRgLw := pos + 2
+	pos = fibRgLw + cslw*4
+	if cslw < 4 || pos+2 > len(word) {
+		return "", fmt.Errorf("truncated file information block")
+	}
+	ccpText := int(binary.LittleEndian.Uint32(word[fibRgLw+12:]))
+	cbRgFcLcb := int(binary.LittleEndian.Uint16(word[pos:]))
+	fibRgFcLcb := pos + 2
+	clxOffset := fibRgFcLcb + docClxPair*8
+	if cbRgFcLcb <= docClxPair || clxOffset+8 > len(word) {
+		return "", fmt.Errorf("truncated file information block")
+	}
+	fcClx := int64(binary.LittleEndian.Uint32(word[clxOffset:]))
+	lcbClx := int64(binary.LittleEndian.Uint32(word[clxOffset+4:]))
+	if fcClx+lcbClx > int64(len(table)) {
+		return "", fmt.Errorf("piece table lies outside the %s stream", tableName)
+	}
+
+	pieceTable, err := docPieceTable(table[fcClx : fcClx+lcbClx])
+	if err != nil {
+		return "", err
+	}
+
+	var text strings.Builder
+	pieces := (len(pieceTable) - 4) / (4 + docPieceLength)
+	pcds := pieceTable[(pieces+1)*4:]
+	for i := 0; i < pieces; i++ {
+		cpStart := int(binary.LittleEndian.Uint32(pieceTable[i*4:]))
+		cpEnd := int(binary.LittleEndian.Uint32(pieceTable[(i+1)*4:]))
+		if cpStart >= ccpText {
+			break
+		}
+		if cpEnd > ccpText {
+			cpEnd = ccpText // Footnotes, headers and comments follow the main text
+		}
+		if cpEnd <= cpStart {
+			continue
+		}
+		count := cpEnd - cpStart
+
+		fc := binary.LittleEndian.Uint32(pcds[i*docPieceLength+2:])
+		if fc&docCompressed != 0 {
+			offset := int(fc&docFcMask) / 2
+			if offset+count > len(word) {
+				return "", fmt.Errorf("text piece lies outside the WordDocument stream")
+			}
+			decoded, err := charmap.Windows1252.NewDecoder().Bytes(word[offset : offset+count])
+			if err != nil {
+				return "", fmt.Errorf("failed to decode text: %w", err)
+			}
+			text.Write(decoded)
+		} else {
+			offset := int(fc & docFcMask)
+			if offset+count*2 > len(word) {
+				return "", fmt.Errorf("text piece lies outside the WordDocument stream")
+			}
+			units := make([]uint16, count)
+			for j := range units {
+				units[j] = binary.LittleEndian.Uint16(word[offset+j*2:])
+			}
+			text.WriteString(string(utf16.Decode(units)))
+		}
+	}
+
+	return docVisibleText(text.String()), nil
+}
+
+// docPieceTable finds the PlcPcd inside a Clx, skipping the formatting
+// (Prc) records that may precede it
+func docPieceTable(clx []byte) ([]byte, error) {
+	for pos := 0; pos < len(clx); {
+		switch clx[pos] {
+		case 0x01: // Prc: cbGrpprl followed by the property modifiers
+			if pos+3 > len(clx) {
+				return nil, fmt.Errorf("truncated piece table")
+			}
+			size := int(int16(binary.LittleEndian.Uint16(clx[pos+1:])))
+			if size < 0 {
+				return nil, fmt.Errorf("invalid piece table")
+			}
+			pos += 3 + size
+		case 0x02: // Pcdt: lcb followed by the PlcPcd
+			if pos+5 > len(clx) {
+				return nil, fmt.Errorf("truncated piece table")
+			}
+			size := int(binary.LittleEndian.Uint32(clx[pos+1:]))
+			start := pos + 5
+			if size < 4 || start+size > len(clx) || (size-4)%(4+docPieceLength) != 0 {
+				return nil, fmt.Errorf("invalid piece table")
+			}
+			return clx[start : start+size], nil
+		default:
+			return nil, fmt.Errorf("invalid piece table")
+		}
+	}
+	return nil, fmt.Errorf("piece table not found")
+}
+
+// docVisibleText drops field instructions (keeping field results) and maps
+// Word's control characters for paragraphs, cells and breaks to whitespace
+func docVisibleText(raw string) string {
+	var text strings.Builder
+	// Each open field records whether its instruction part is still running
+	var fields []bool
+	for _, r := range raw {
+		switch r {
+		case docFieldBegin:
+			fields = append(fields, true)
+			continue
+		case docFieldSep:
+			if len(fields) > 0 {
+				fields[len(fields)-1] = false
+			}
+			continue
+		case docFieldEnd:
+			if len(fields) > 0 {
+				fields = fields[:len(fields)-1]
+			}
+			continue
+		}
+
+		inInstruction := false
+		for _, instruction := range fields {
+			inInstruction = inInstruction || instruction
+		}
+		if inInstruction {
+			continue
+		}
+
+		switch r {
+		case '\r', 0x0B, 0x0C: // Paragraph, line and page breaks
+			text.WriteRune('\n')
+		case 0x07: // End of table cell or row
+			text.WriteRune('\t')
+		case 0x1E: // Non-breaking hyphen
+			text.WriteRune('-')
+		case 0x1F: // Optional hyphen
+		default:
+			text.WriteRune(r)
+		}
+	}
+	return text.String()
+}
+
+func (m *Manager) extractPptText(_ context.Context, filePath string) (string, error) {
+	streams, err := readOLEStreams(filePath, pptDocumentStream)
+	if err != nil {
+		return "", fmt.Errorf("failed to open PPT file: %w", err)
+	}
+
+	data := streams[pptDocumentStream]
+	if data == nil {
+		return "", fmt.Errorf("failed to extract text from PPT: not a PowerPoint 97-2003 presentation")
+	}
+
+	var text strings.Builder
+	pptCollectText(data, 0, &text)
+
+	return strings.TrimSpace(m.cleanExtractedText(text.String())), nil
+}
+
+// pptCollectText walks a PowerPoint record tree and collects the text atoms
+// of slides in stream order: placeholder text from the slide list first, then
+// free text boxes drawn on the slides. Masters, notes and handouts are skipped.
+func pptCollectText(data []byte, depth int, text *strings.Builder) {
+	if depth > pptMaxDepth {
+		return
+	}
+
+	for pos := 0; pos+8 <= len(data); {
+		verInstance := binary.LittleEndian.Uint16(data[pos:])
+		recType := binary.LittleEndian.Uint16(data[pos+2:])
+		recLen := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		body := data[pos+8:]
+		if recLen < 0 || recLen > len(body) {
+			recLen = len(body) // Truncated record: use what is there and stop
+		}
+		body = body[:recLen]
+		pos += 8 + recLen
+
+		version := verInstance & 0x000F
+		instance := verInstance >> 4
+		switch {
+		case recType == pptNotes || recType == pptMainMaster || recType == pptHandout:
+		case recType == pptSlideListWithText && instance != 0:
+			// Instance 1 lists master text, 2 notes text
+		case version == pptContainerVersion:
+			pptCollectText(body, depth+1, text)
+		case recType == pptTextCharsAtom:
+			units := make([]uint16, len(body)/2)
+			for i := range units {
+				units[i] = binary.LittleEndian.Uint16(body[i*2:])
+			}
+			text.WriteString(pptVisibleText(string(utf16.Decode(units))))
+			text.WriteString("\n")
+		case recType == pptTextBytesAtom:
+			// Each byte is the low byte of a UTF-16 code unit whose high byte is zero
+			runes := make([]rune, len(body))
+			for i, b := range body {
+				runes[i] = rune(b)
+			}
+			text.WriteString(pptVisibleText(string(runes)))
+			text.WriteString("\n")
+		}
+	}
+}
+
+// pptVisibleText maps PowerPoint's paragraph and line break characters to newlines
+func pptVisibleText(raw string) string {
+	return strings.NewReplacer("\r", "\n", "\x0b", "\n").Replace(raw)
+}
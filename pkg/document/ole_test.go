@@ -0,0 +1,303 @@
+package document
+
+import (
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// oleStream is a named stream written by writeTestOLE
+type oleStream struct {
+	name string
+	data []byte
+}
+
+// writeTestOLE writes a version 3 compound file holding the given top-level
+// streams. Streams are padded to the 4096-byte mini stream cutoff so that all
+// of them live in regular sectors.
+func writeTestOLE(t *testing.T, path string, streams ...oleStream) {
+	t.Helper()
+
+	const sectorSize = 512
+	const (
+		endOfChain = 0xFFFFFFFE
+		fatSect    = 0xFFFFFFFD
+		freeSect   = 0xFFFFFFFF
+		noStream   = 0xFFFFFFFF
+	)
+	if len(streams) > 3 {
+		t.Fatal("writeTestOLE supports at most 3 streams")
+	}
+
+	// Sector 0 holds the FAT, sector 1 the directory, stream data follows
+	fat := []uint32{fatSect, endOfChain}
+	var data []byte
+	starts := make([]uint32, len(streams))
+	sizes := make([]int, len(streams))
+	for i, stream := range streams {
+		padded := stream.data
+		if len(padded) < 4096 {
+			padded = append(append([]byte{}, padded...), make([]byte, 4096-len(padded))...)
+		}
+		if rem := len(padded) % sectorSize; rem != 0 {
+			padded = append(padded, make([]byte, sectorSize-rem)...)
+		}
+		starts[i] = uint32(len(fat))
+		sizes[i] = len(padded)
+		count := len(padded) / sectorSize
+		for j := 0; j < count; j++ {
+			next := uint32(len(fat) + 1)
+			if j == count-1 {
+				next = endOfChain
+			}
+			fat = append(fat, next)
+		}
+		data = append(data, padded...)
+	}
+	if len(fat) > sectorSize/4 {
+		t.Fatal("writeTestOLE streams exceed one FAT sector")
+	}
+
+	header := make([]byte, sectorSize)
+	copy(header, []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1})
+	binary.LittleEndian.PutUint16(header[24:], 0x003E)
+	binary.LittleEndian.PutUint16(header[26:], 0x0003)
+	binary.LittleEndian.PutUint16(header[28:], 0xFFFE)
+	binary.LittleEndian.PutUint16(header[30:], 9)
+	binary.LittleEndian.PutUint16(header[32:], 6)
+	binary.LittleEndian.PutUint32(header[44:], 1) // FAT sectors
+	binary.LittleEndian.PutUint32(header[48:], 1) // First directory sector
+	binary.LittleEndian.PutUint32(header[56:], 4096)
+	binary.LittleEndian.PutUint32(header[60:], endOfChain)
+	binary.LittleEndian.PutUint32(header[68:], endOfChain)
+	for i := 76; i < sectorSize; i += 4 {
+		binary.LittleEndian.PutUint32(header[i:], freeSect)
+	}
+	binary.LittleEndian.PutUint32(header[76:], 0) // The FAT is sector 0
+
+	fatSector := make([]byte, sectorSize)
+	for i := 0; i < sectorSize/4; i++ {
+		value := uint32(freeSect)
+		if i < len(fat) {
+			value = fat[i]
+		}
+		binary.LittleEndian.PutUint32(fatSector[i*4:], value)
+	}
+
+	// The root's children form a chain of right siblings
+	directory := make([]byte, sectorSize)
+	entry := func(index int, name string, objectType byte, child, right, start uint32, size int) {
+		e := directory[index*128:]
+		units := utf16.Encode([]rune(name))
+		for i, unit := range units {
+			binary.LittleEndian.PutUint16(e[i*2:], unit)
+		}
+		binary.LittleEndian.PutUint16(e[64:], uint16((len(units)+1)*2))
+		e[66] = objectType
+		e[67] = 1 // Black
+		binary.LittleEndian.PutUint32(e[68:], noStream)
+		binary.LittleEndian.PutUint32(e[72:], right)
+		binary.LittleEndian.PutUint32(e[76:], child)
+		binary.LittleEndian.PutUint32(e[116:], start)
+		binary.LittleEndian.PutUint32(e[120:], uint32(size))
+	}
+	child := uint32(noStream)
+	if len(streams) > 0 {
+		child = 1
+	}
+	entry(0, "Root Entry", 5, child, noStream, endOfChain, 0)
+	for i, stream := range streams {
+		right := uint32(noStream)
+		if i+1 < len(streams) {
+			right = uint32(i + 2)
+		}
+		entry(i+1, stream.name, 2, noStream, right, starts[i], sizes[i])
+	}
+
+	content := append(append(append(header, fatSector...), directory...), data...)
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// testDocStreams builds the WordDocument and 1Table streams of a .doc whose
+// main text is text, followed by trailing story text (footnotes and the like)
+func testDocStreams(text, trailing string, compressed bool) []oleStream {
+	const textOffset = 1024
+
+	word := make([]byte, textOffset)
+	binary.LittleEndian.PutUint16(word[0:], 0xA5EC)
+	binary.LittleEndian.PutUint16(word[2:], 0x00C1)
+	binary.LittleEndian.PutUint16(word[0x0A:], 0x0200) // fWhichTblStm: 1Table
+	binary.LittleEndian.PutUint16(word[32:], 14)       // csw
+	binary.LittleEndian.PutUint16(word[62:], 22)       // cslw
+	binary.LittleEndian.PutUint32(word[76:], uint32(len([]rune(text))))
+	binary.LittleEndian.PutUint16(word[152:], 93) // cbRgFcLcb
+
+	all := text + trailing
+	var fc uint32
+	if compressed {
+		encoded, err := charmap.Windows1252.NewEncoder().Bytes([]byte(all))
+		if err != nil {
+			panic(err)
+		}
+		word = append(word, encoded...)
+		fc = textOffset*2 | 0x40000000
+	} else {
+		for _, unit := range utf16.Encode([]rune(all)) {
+			word = binary.LittleEndian.AppendUint16(word, unit)
+		}
+		fc = textOffset
+	}
+
+	// Clx holding one Prc (ignored) and a single-piece PlcPcd
+	clx := []byte{0x01, 0x02, 0x00, 0xAA, 0xBB, 0x02}
+	clx = binary.LittleEndian.AppendUint32(clx, 4*2+8)
+	clx = binary.LittleEndian.AppendUint32(clx, 0)
+	clx = binary.LittleEndian.AppendUint32(clx, uint32(len([]rune(all))))
+	clx = append(clx, 0, 0)
+	clx = binary.LittleEndian.AppendUint32(clx, fc)
+	clx = append(clx, 0, 0)
+
+	table := append(make([]byte, 16), clx...)
+	binary.LittleEndian.PutUint32(word[154+33*8:], 16)
+	binary.LittleEndian.PutUint32(word[154+33*8+4:], uint32(len(clx)))
+
+	return []oleStream{{"WordDocument", word}, {"1Table", table}}
+}
+
+// pptRecord encodes a PowerPoint record header and body
+func pptRecord(verInstance, recType uint16, body ...[]byte) []byte {
+	var content []byte
+	for _, part := range body {
+		content = append(content, part...)
+	}
+	record := binary.LittleEndian.AppendUint16(nil, verInstance)
+	record = binary.LittleEndian.AppendUint16(record, recType)
+	record = binary.LittleEndian.AppendUint32(record, uint32(len(content)))
+	return append(record, content...)
+}
+
+func pptChars(text string) []byte {
+	var body []byte
+	for _, unit := range utf16.Encode([]rune(text)) {
+		body = binary.LittleEndian.AppendUint16(body, unit)
+	}
+	return pptRecord(0, pptTextCharsAtom, body)
+}
+
+func pptBytes(text string) []byte {
+	return pptRecord(0, pptTextBytesAtom, []byte(text))
+}
+
+func TestExtractDocText(t *testing.T) {
+	dir := t.TempDir()
+	manager := NewManager()
+
+	unicodePath := filepath.Join(dir, "review.doc")
+	writeTestOLE(t, unicodePath, testDocStreams(
+		"Quarterly review\r\x13 HYPERLINK \"http://example.com\" \x14Example site\x15 – Zürich\rQ1\x07Q2\x07\x07",
+		"Footnote text\r", false)...)
+
+	text, err := manager.ExtractText(context.Background(), unicodePath)
+	if err != nil {
+		t.Fatalf("ExtractText failed: %v", err)
+	}
+	if text != "Quarterly review Example site – Zürich Q1 Q2" {
+		t.Errorf("Unexpected text: %q", text)
+	}
+
+	compressedPath := filepath.Join(dir, "menu.doc")
+	writeTestOLE(t, compressedPath, testDocStreams("Café menu €5\r", "", true)...)
+
+	text, err = manager.ExtractText(context.Background(), compressedPath)
+	if err != nil {
+		t.Fatalf("ExtractText failed: %v", err)
+	}
+	if text != "Café menu €5" {
+		t.Errorf("Unexpected cp1252 text: %q", text)
+	}
+}
+
+func TestExtractDocTextEncrypted(t *testing.T) {
+	streams := testDocStreams("Secret\r", "", false)
+	binary.LittleEndian.PutUint16(streams[0].data[0x0A:], 0x0300)
+
+	path := filepath.Join(t.TempDir(), "secret.doc")
+	writeTestOLE(t, path, streams...)
+
+	_, err := NewManager().ExtractText(context.Background(), path)
+	if err == nil || !strings.Contains(err.Error(), "encrypted") {
+		t.Errorf("Expected an encrypted document error, got %v", err)
+	}
+}
+
+func TestExtractPptText(t *testing.T) {
+	stream := append(
+		pptRecord(0x000F, 0x03E8, // Document
+			pptRecord(0x000F, pptSlideListWithText, pptChars("Welcome"), pptBytes("Agenda\rBudget\x0bHiring")),
+			pptRecord(0x001F, pptSlideListWithText, pptChars("Click to edit Master title style")),
+		),
+		append(
+			pptRecord(0x000F, pptMainMaster, pptChars("Master text")),
+			append(
+				pptRecord(0x000F, 0x03EE, pptRecord(0x000F, 0xF00D, pptChars("Free text box"))), // Slide with a text box
+				pptRecord(0x000F, pptNotes, pptChars("Speaker notes"))...,
+			)...,
+		)...,
+	)
+
+	path := filepath.Join(t.TempDir(), "deck.ppt")
+	writeTestOLE(t, path, oleStream{pptDocumentStream, stream})
+
+	text, err := NewManager().ExtractText(context.Background(), path)
+	if err != nil {
+		t.Fatalf("ExtractText failed: %v", err)
+	}
+	if text != "Welcome Agenda Budget Hiring Free text box" {
+		t.Errorf("Unexpected text: %q", text)
+	}
+}
+
+func TestExtractLegacyInvalidFiles(t *testing.T) {
+	dir := t.TempDir()
+	manager := NewManager()
+
+	// OLE signature but not a Word document
+	path := filepath.Join(dir, "other.doc")
+	writeTestOLE(t, path, oleStream{"Contents", []byte("hello")})
+	if _, err := manager.ExtractText(context.Background(), path); err == nil {
+		t.Error("Expected error for a compound file without a WordDocument stream")
+	}
+
+	path = filepath.Join(dir, "other.ppt")
+	writeTestOLE(t, path, oleStream{"Contents", []byte("hello")})
+	if _, err := manager.ExtractText(context.Background(), path); err == nil {
+		t.Error("Expected error for a compound file without a PowerPoint Document stream")
+	}
+
+	// Truncated records must not panic
+	var text strings.Builder
+	pptCollectText(pptRecord(0x000F, 0x03E8, pptChars("Cut off"))[:20], 0, &text)
+}
+
+func TestConvertCorpusLegacyFormats(t *testing.T) {
+	sourceDir := t.TempDir()
+	writeTestOLE(t, filepath.Join(sourceDir, "minutes.doc"), testDocStreams("Board minutes\r", "", false)...)
+	writeTestOLE(t, filepath.Join(sourceDir, "deck.ppt"), oleStream{pptDocumentStream, pptRecord(0x000F, 0x03E8,
+		pptRecord(0x000F, pptSlideListWithText, pptChars("Roadmap")))})
+
+	index, err := NewManager().ConvertCorpus(context.Background(), sourceDir, t.TempDir())
+	if err != nil {
+		t.Fatalf("ConvertCorpus failed: %v", err)
+	}
+	if len(index.Documents) != 2 || len(index.Failures) != 0 {
+		t.Errorf("Expected both legacy documents converted, got %+v and failures %+v", index.Documents, index.Failures)
+	}
+}
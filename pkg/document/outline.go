@@ -0,0 +1,419 @@
+package document
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// maxOutlineEntries bounds outline traversal, since malformed PDF outlines can
+// link back to themselves
+const maxOutlineEntries = 10000
+
+// headingStylePattern matches the built-in heading style names ("heading 1")
+// and the style IDs Word derives from them ("Heading1")
+var headingStylePattern = regexp.MustCompile(`(?i)^heading ?([1-9])$`)
+
+// OutlineEntry is a heading of a document. Page is the page or slide the
+// heading is on, or 0 when the document doesn't record it.
+type OutlineEntry struct {
+	Level int    `json:"level"` // 1 for top-level headings
+	Title string `json:"title"`
+	Page  int    `json:"page,omitempty"`
+}
+
+// DocumentOutline is the heading structure of a document in reading order
+type DocumentOutline struct {
+	FilePath string         `json:"file_path"`
+	Source   string         `json:"source"` // "bookmarks", "headings" or "slide titles"
+	Unit     string         `json:"unit"`   // "page" or "slide"
+	Entries  []OutlineEntry `json:"entries"`
+}
+
+// GetOutline returns the outline of a PDF (its bookmarks), a DOCX (paragraphs
+// with heading styles) or a PPTX (slide titles)
+func (m *Manager) GetOutline(filePath string) (*DocumentOutline, error) {
+	if err := m.checkFileSize(filePath); err != nil {
+		return nil, err
+	}
+
+	outline := &DocumentOutline{FilePath: filePath, Unit: "page"}
+	var err error
+
+	switch m.detectFileType(filePath) {
+	case DocumentTypePDF:
+		outline.Source = "bookmarks"
+		outline.Entries, err = pdfOutline(filePath)
+	case DocumentTypeDOCX:
+		outline.Source = "headings"
+		outline.Entries, err = docxOutline(filePath)
+	case DocumentTypePPTX:
+		outline.Source, outline.Unit = "slide titles", "slide"
+		outline.Entries, err = pptxOutline(filePath)
+	default:
+		return nil, fmt.Errorf("outline extraction supports .pdf, .docx and .pptx files only: %s", filePath)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return outline, nil
+}
+
+// pdfOutline walks the bookmark tree of a PDF, resolving each bookmark's
+// destination to a page number
+func pdfOutline(filePath string) ([]OutlineEntry, error) {
+	var entries []OutlineEntry
+	err := withPDF(filePath, "", func(reader *pdf.Reader) error {
+		// Destinations reference page objects; the reader doesn't expose object
+		// identity, so pages are matched by their dictionary, which includes the
+		// references to their own content streams
+		pages := make(map[string]int, reader.NumPage())
+		for number := 1; number <= reader.NumPage(); number++ {
+			if page := reader.Page(number); !page.V.IsNull() {
+				pages[page.V.String()] = number
+			}
+		}
+
+		root := reader.Trailer().Key("Root")
+		var walk func(parent pdf.Value, level int)
+		walk = func(parent pdf.Value, level int) {
+			for item := parent.Key("First"); item.Kind() == pdf.Dict; item = item.Key("Next") {
+				if len(entries) >= maxOutlineEntries {
+					return
+				}
+				entries = append(entries, OutlineEntry{
+					Level: level,
+					Title: strings.TrimSpace(item.Key("Title").Text()),
+					Page:  pages[pdfDestinationPage(root, item).String()],
+				})
+				walk(item, level+1)
+			}
+		}
+		walk(root.Key("Outlines"), 1)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// pdfDestinationPage returns the page object a bookmark points to, following
+// GoTo actions and named destinations; the result is null when the bookmark
+// has no page destination
+func pdfDestinationPage(root, item pdf.Value) pdf.Value {
+	destination := item.Key("Dest")
+	if destination.IsNull() && item.Key("A").Key("S").Name() == "GoTo" {
+		destination = item.Key("A").Key("D")
+	}
+
+	switch destination.Kind() {
+	case pdf.Name:
+		destination = root.Key("Dests").Key(destination.Name())
+	case pdf.String:
+		destination = pdfNameTreeLookup(root.Key("Names").Key("Dests"), destination.RawString(), 0)
+	}
+	if destination.Kind() == pdf.Dict {
+		destination = destination.Key("D")
+	}
+
+	if destination.Kind() != pdf.Array || destination.Len() == 0 {
+		return pdf.Value{}
+	}
+	return destination.Index(0)
+}
+
+// pdfNameTreeLookup finds a value in a PDF name tree
+func pdfNameTreeLookup(node pdf.Value, key string, depth int) pdf.Value {
+	if node.Kind() != pdf.Dict || depth > 32 {
+		return pdf.Value{}
+	}
+
+	names := node.Key("Names")
+	for i := 0; i+1 < names.Len(); i += 2 {
+		if names.Index(i).RawString() == key {
+			return names.Index(i + 1)
+		}
+	}
+
+	kids := node.Key("Kids")
+	for i := 0; i < kids.Len(); i++ {
+		if value := pdfNameTreeLookup(kids.Index(i), key, depth+1); !value.IsNull() {
+			return value
+		}
+	}
+	return pdf.Value{}
+}
+
+// docxOutline returns the paragraphs of a Word document that have an outline
+// level, either from their style (Heading 1-9 or a custom style with an
+// outline level) or set on the paragraph itself.
+//
+// DOCX files don't store pagination, so pages are estimated from the page
+// breaks Word records when it saves (w:lastRenderedPageBreak), or else from
+// explicit page breaks; documents with neither get no page numbers.
+func docxOutline(filePath string) ([]OutlineEntry, error) {
+	reader, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open DOCX file: %w", err)
+	}
+	defer reader.Close()
+
+	files := make(map[string]*zip.File, len(reader.File))
+	for _, f := range reader.File {
+		files[f.Name] = f
+	}
+	if files["word/document.xml"] == nil {
+		return nil, fmt.Errorf("failed to read DOCX file: word/document.xml not found")
+	}
+	rc, err := files["word/document.xml"].Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DOCX file: %w", err)
+	}
+	defer rc.Close()
+
+	styleLevels := docxStyleLevels(files["word/styles.xml"])
+
+	type heading struct {
+		entry                   OutlineEntry
+		renderedPage, breakPage int
+	}
+	var headings []heading
+	renderedBreaks, explicitBreaks := 0, 0
+
+	var current *heading
+	depth, level := 0, 0 // Paragraph nesting (text boxes hold paragraphs) and outline level
+	var text strings.Builder
+	inText, located := false, false
+
+	decoder := xml.NewDecoder(rc)
+	decoder.Strict = false
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "p":
+				if depth++; depth == 1 {
+					level, located = 0, false
+					text.Reset()
+					current = &heading{}
+				}
+			case "pStyle":
+				if depth == 1 && level == 0 {
+					level = styleLevels[xmlAttr(t, "val")]
+					if level == 0 {
+						if match := headingStylePattern.FindStringSubmatch(xmlAttr(t, "val")); match != nil {
+							level = atoiOrZero(match[1])
+						}
+					}
+				}
+			case "outlineLvl":
+				// Outline levels are 0-based; 9 is body text
+				if n := atoiOrZero(xmlAttr(t, "val")); depth == 1 && n < 9 {
+					level = n + 1
+				}
+			case "pageBreakBefore":
+				if val := xmlAttr(t, "val"); val != "0" && val != "false" {
+					explicitBreaks++
+				}
+			case "lastRenderedPageBreak":
+				renderedBreaks++
+			case "br":
+				if xmlAttr(t, "type") == "page" {
+					explicitBreaks++
+				}
+			case "t":
+				inText = true
+			case "tab":
+				text.WriteString(" ")
+			}
+		case xml.CharData:
+			if inText && depth > 0 {
+				// The heading is on the page where its text starts
+				if !located && current != nil {
+					current.renderedPage, current.breakPage = renderedBreaks+1, explicitBreaks+1
+					located = true
+				}
+				text.Write(t)
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				if depth--; depth == 0 && current != nil {
+					title := strings.Join(strings.Fields(text.String()), " ")
+					if level > 0 && title != "" && len(headings) < maxOutlineEntries {
+						current.entry = OutlineEntry{Level: level, Title: title}
+						headings = append(headings, *current)
+					}
+					current = nil
+				}
+				depth = max(depth, 0)
+			}
+		}
+	}
+
+	entries := make([]OutlineEntry, len(headings))
+	for i, h := range headings {
+		entries[i] = h.entry
+		switch {
+		case renderedBreaks > 0:
+			entries[i].Page = h.renderedPage
+		case explicitBreaks > 0:
+			entries[i].Page = h.breakPage
+		}
+	}
+	return entries, nil
+}
+
+// docxStyleLevels maps paragraph style IDs to outline levels (1-9), from the
+// style's own outline level, its name, or the style it is based on
+func docxStyleLevels(file *zip.File) map[string]int {
+	var styles struct {
+		Styles []struct {
+			Type    string `xml:"type,attr"`
+			ID      string `xml:"styleId,attr"`
+			Name    xmlVal `xml:"name"`
+			BasedOn xmlVal `xml:"basedOn"`
+			Outline xmlVal `xml:"pPr>outlineLvl"`
+		} `xml:"style"`
+	}
+	levels := make(map[string]int)
+	if decodeZipXML(file, &styles) != nil {
+		return levels
+	}
+
+	basedOn := make(map[string]string)
+	for _, style := range styles.Styles {
+		if style.Type != "" && style.Type != "paragraph" {
+			continue
+		}
+		switch {
+		case style.Outline.Val != "":
+			if n := atoiOrZero(style.Outline.Val); n < 9 {
+				levels[style.ID] = n + 1
+			}
+		case headingStylePattern.MatchString(style.Name.Val):
+			levels[style.ID] = atoiOrZero(headingStylePattern.FindStringSubmatch(style.Name.Val)[1])
+		default:
+			basedOn[style.ID] = style.BasedOn.Val
+		}
+	}
+
+	// Inherit levels along basedOn chains, bounded in case of cycles
+	for id, parent := range basedOn {
+		for i := 0; i < 10 && parent != ""; i++ {
+			if level, ok := levels[parent]; ok {
+				levels[id] = level
+				break
+			}
+			parent = basedOn[parent]
+		}
+	}
+	return levels
+}
+
+// xmlVal is an element whose value is in its val attribute, such as <w:name w:val="heading 1"/>
+type xmlVal struct {
+	Val string `xml:"val,attr"`
+}
+
+// pptxOutline returns the title of each slide in presentation order; slides
+// without a title placeholder are left out
+func pptxOutline(filePath string) ([]OutlineEntry, error) {
+	reader, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PPTX file: %w", err)
+	}
+	defer reader.Close()
+
+	files := make(map[string]*zip.File, len(reader.File))
+	for _, f := range reader.File {
+		files[f.Name] = f
+	}
+
+	var entries []OutlineEntry
+	for i, slide := range pptxSlideOrder(files) {
+		content, err := readZipFile(files[slide])
+		if err != nil {
+			return nil, fmt.Errorf("failed to read slide %d: %w", i+1, err)
+		}
+		if title := pptxSlideTitle(content); title != "" {
+			entries = append(entries, OutlineEntry{Level: 1, Title: title, Page: i + 1})
+		}
+	}
+	return entries, nil
+}
+
+// pptxSlideTitle returns the text of the title placeholder of a slide
+func pptxSlideTitle(content string) string {
+	return strings.Join(strings.Fields(strings.Join(pptxPlaceholderText(content, "title", "ctrTitle"), " ")), " ")
+}
+
+// pptxPlaceholderText returns the paragraphs of the first shape on a slide
+// that is a placeholder of one of the given types
+func pptxPlaceholderText(content string, kinds ...string) []string {
+	decoder := xml.NewDecoder(strings.NewReader(content))
+	decoder.Strict = false
+
+	depth := 0 // Shape nesting
+	matched, inText := false, false
+	var paragraphs []string
+	var text strings.Builder
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "sp":
+				if depth++; depth == 1 {
+					matched, paragraphs = false, nil
+				}
+			case "ph":
+				if depth == 1 && slices.Contains(kinds, xmlAttr(t, "type")) {
+					matched = true
+				}
+			case "p":
+				text.Reset()
+			case "t":
+				inText = true
+			case "br":
+				text.WriteString(" ")
+			}
+		case xml.CharData:
+			if inText && depth > 0 {
+				text.Write(t)
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				if line := strings.TrimSpace(text.String()); depth > 0 && line != "" {
+					paragraphs = append(paragraphs, line)
+				}
+			case "sp":
+				if depth--; depth == 0 && matched && len(paragraphs) > 0 {
+					return paragraphs
+				}
+				depth = max(depth, 0)
+			}
+		}
+	}
+}
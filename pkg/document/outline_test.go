@@ -0,0 +1,140 @@
+package document
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPDFOutline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.pdf")
+	// Pages are objects 4, 6 and 8; the outline starts at object 10
+	writeTestPDFObjects(t, path, testPDF{
+		Catalog: "/Outlines 10 0 R /Names << /Dests << /Names [(results) [8 0 R /Fit]] >> >>",
+		Extra: []string{
+			"<< /Type /Outlines /First 11 0 R /Last 13 0 R /Count 3 >>",
+			"<< /Title (Introduction) /Parent 10 0 R /Next 13 0 R /First 12 0 R /Last 12 0 R /Dest [4 0 R /Fit] >>",
+			"<< /Title (Background) /Parent 11 0 R /A << /S /GoTo /D [6 0 R /XYZ 0 792 0] >> >>",
+			"<< /Title (Results) /Parent 10 0 R /Prev 11 0 R /Dest (results) >>",
+		},
+	}, "BT /F1 12 Tf 72 720 Td (One) Tj ET", "BT /F1 12 Tf 72 720 Td (Two) Tj ET", "BT /F1 12 Tf 72 720 Td (Three) Tj ET")
+
+	outline, err := NewManager().GetOutline(path)
+	if err != nil {
+		t.Fatalf("GetOutline failed: %v", err)
+	}
+	expected := []OutlineEntry{
+		{Level: 1, Title: "Introduction", Page: 1},
+		{Level: 2, Title: "Background", Page: 2},
+		{Level: 1, Title: "Results", Page: 3},
+	}
+	if outline.Source != "bookmarks" || outline.Unit != "page" || !reflect.DeepEqual(outline.Entries, expected) {
+		t.Errorf("Unexpected outline: %+v", outline)
+	}
+
+	// A PDF without bookmarks has an empty outline
+	plain := filepath.Join(t.TempDir(), "plain.pdf")
+	writeTestPDF(t, plain, "Text")
+	if outline, err := NewManager().GetOutline(plain); err != nil || len(outline.Entries) != 0 {
+		t.Errorf("Expected an empty outline, got %+v, %v", outline, err)
+	}
+}
+
+func TestDocxOutline(t *testing.T) {
+	paragraph := func(style, content string) string {
+		properties := ""
+		if style != "" {
+			properties = `<w:pPr><w:pStyle w:val="` + style + `"/></w:pPr>`
+		}
+		return `<w:p>` + properties + `<w:r>` + content + `</w:r></w:p>`
+	}
+
+	path := filepath.Join(t.TempDir(), "report.docx")
+	writeTestZip(t, path,
+		[2]string{"word/styles.xml", `<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` +
+			`<w:style w:type="paragraph" w:styleId="Titre1"><w:name w:val="heading 1"/></w:style>` +
+			`<w:style w:type="paragraph" w:styleId="Custom"><w:name w:val="Section"/><w:pPr><w:outlineLvl w:val="1"/></w:pPr></w:style>` +
+			`<w:style w:type="paragraph" w:styleId="Appendix"><w:name w:val="Appendix"/><w:basedOn w:val="Titre1"/></w:style>` +
+			`</w:styles>`},
+		[2]string{"word/document.xml", `<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>` +
+			paragraph("Titre1", `<w:t>Overview</w:t>`) +
+			paragraph("", `<w:t>Body text</w:t>`) +
+			paragraph("", `<w:lastRenderedPageBreak/><w:t>More text</w:t>`) +
+			paragraph("Custom", `<w:t>Details</w:t>`) +
+			`<w:p><w:pPr><w:outlineLvl w:val="2"/></w:pPr><w:r><w:t>Inline</w:t></w:r></w:p>` +
+			paragraph("Custom", `<w:t xml:space="preserve">  </w:t>`) +
+			paragraph("Appendix", `<w:lastRenderedPageBreak/><w:t>Glossary</w:t>`) +
+			`</w:body></w:document>`},
+	)
+
+	outline, err := NewManager().GetOutline(path)
+	if err != nil {
+		t.Fatalf("GetOutline failed: %v", err)
+	}
+	expected := []OutlineEntry{
+		{Level: 1, Title: "Overview", Page: 1},
+		{Level: 2, Title: "Details", Page: 2},
+		{Level: 3, Title: "Inline", Page: 2},
+		{Level: 1, Title: "Glossary", Page: 3},
+	}
+	if outline.Source != "headings" || !reflect.DeepEqual(outline.Entries, expected) {
+		t.Errorf("Unexpected outline: %+v", outline.Entries)
+	}
+}
+
+func TestDocxOutlineWithoutPageBreaks(t *testing.T) {
+	// Without styles.xml, heading style IDs are recognised by name; without
+	// page breaks, pages are unknown
+	path := filepath.Join(t.TempDir(), "generated.docx")
+	writeTestZip(t, path, [2]string{"word/document.xml", `<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>` +
+		`<w:p><w:pPr><w:pStyle w:val="Heading2"/></w:pPr><w:r><w:t>Scope</w:t></w:r></w:p></w:body></w:document>`})
+
+	outline, err := NewManager().GetOutline(path)
+	if err != nil {
+		t.Fatalf("GetOutline failed: %v", err)
+	}
+	if expected := []OutlineEntry{{Level: 2, Title: "Scope"}}; !reflect.DeepEqual(outline.Entries, expected) {
+		t.Errorf("Unexpected outline: %+v", outline.Entries)
+	}
+}
+
+func TestPptxOutline(t *testing.T) {
+	title := func(kind, text string) string {
+		return `<p:sp><p:nvSpPr><p:nvPr><p:ph type="` + kind + `"/></p:nvPr></p:nvSpPr><p:txBody><a:p><a:r><a:t>` + text + `</a:t></a:r></a:p></p:txBody></p:sp>`
+	}
+	body := `<p:sp><p:nvSpPr><p:nvPr><p:ph idx="1"/></p:nvPr></p:nvSpPr><p:txBody><a:p><a:r><a:t>Bullet</a:t></a:r></a:p></p:txBody></p:sp>`
+
+	path := filepath.Join(t.TempDir(), "deck.pptx")
+	// The slide stored third is shown first
+	writeTestPptxShapes(t, path, []int{3, 1, 2, 4}, nil,
+		body+title("title", "Budget"),
+		body,
+		title("ctrTitle", "Quarterly")+title("subTitle", "Review"),
+		`<p:sp><p:nvSpPr><p:nvPr><p:ph type="title"/></p:nvPr></p:nvSpPr><p:txBody><a:p><a:r><a:t>Next</a:t></a:r></a:p><a:p><a:r><a:t>steps</a:t></a:r></a:p></p:txBody></p:sp>`,
+	)
+
+	outline, err := NewManager().GetOutline(path)
+	if err != nil {
+		t.Fatalf("GetOutline failed: %v", err)
+	}
+	expected := []OutlineEntry{
+		{Level: 1, Title: "Quarterly", Page: 1},
+		{Level: 1, Title: "Budget", Page: 2},
+		{Level: 1, Title: "Next steps", Page: 4},
+	}
+	if outline.Unit != "slide" || !reflect.DeepEqual(outline.Entries, expected) {
+		t.Errorf("Unexpected outline: %+v", outline)
+	}
+}
+
+func TestGetOutlineUnsupportedFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.rtf")
+	if err := os.WriteFile(path, []byte(`{\rtf1 Notes}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewManager().GetOutline(path); err == nil || !strings.Contains(err.Error(), "supports .pdf, .docx and .pptx") {
+		t.Errorf("Expected an unsupported format error, got %v", err)
+	}
+}
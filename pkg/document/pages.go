@@ -0,0 +1,231 @@
+package document
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// PageText is the text of one page or slide
+type PageText struct {
+	Number int    `json:"number"`
+	Text   string `json:"text"`
+}
+
+// PageExtraction is the result of extracting a range of pages or slides
+type PageExtraction struct {
+	FilePath   string     `json:"file_path"`
+	Unit       string     `json:"unit"` // "page" or "slide"
+	TotalPages int        `json:"total_pages"`
+	Pages      []PageText `json:"pages"`
+}
+
+// ExtractPages returns the text of selected PDF pages or PPTX slides. Pages
+// are given as a comma-separated list of 1-based numbers and ranges such as
+// "1-5,8,12-"; an empty selection means every page.
+func (m *Manager) ExtractPages(ctx context.Context, filePath, selection string) (*PageExtraction, error) {
+	var extract func(context.Context, string, string) (*PageExtraction, error)
+	switch m.detectFileType(filePath) {
+	case DocumentTypePDF:
+		extract = m.extractPDFPages
+	case DocumentTypePPTX:
+		extract = m.extractPptxSlides
+	default:
+		return nil, fmt.Errorf("page extraction supports .pdf and .pptx files only: %s", filePath)
+	}
+
+	if err := m.checkFileSize(filePath); err != nil {
+		return nil, err
+	}
+	return withTimeout(ctx, m.limits.Timeout, func(ctx context.Context) (*PageExtraction, error) {
+		return extract(ctx, filePath, selection)
+	})
+}
+
+func (m *Manager) extractPDFPages(ctx context.Context, filePath, selection string) (*PageExtraction, error) {
+	file, reader, err := pdf.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PDF file: %w", err)
+	}
+	defer file.Close()
+
+	numbers, err := parsePageSelection(selection, reader.NumPage())
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PageExtraction{FilePath: filePath, Unit: "page", TotalPages: reader.NumPage()}
+	for _, number := range numbers {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		page := reader.Page(number)
+		text := ""
+		if !page.V.IsNull() {
+			if pageText, err := page.GetPlainText(nil); err == nil {
+				text = m.cleanExtractedText(pageText)
+			}
+		}
+		result.Pages = append(result.Pages, PageText{Number: number, Text: text})
+	}
+
+	return result, nil
+}
+
+func (m *Manager) extractPptxSlides(ctx context.Context, filePath, selection string) (*PageExtraction, error) {
+	reader, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PPTX file: %w", err)
+	}
+	defer reader.Close()
+
+	files := make(map[string]*zip.File, len(reader.File))
+	for _, f := range reader.File {
+		files[f.Name] = f
+	}
+
+	slides := pptxSlideOrder(files)
+	numbers, err := parsePageSelection(selection, len(slides))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PageExtraction{FilePath: filePath, Unit: "slide", TotalPages: len(slides)}
+	for _, number := range numbers {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		content, err := readZipFile(files[slides[number-1]])
+		if err != nil {
+			return nil, fmt.Errorf("failed to read slide %d: %w", number, err)
+		}
+		text, err := m.extractCleanTextFromXML(content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse slide %d: %w", number, err)
+		}
+		result.Pages = append(result.Pages, PageText{Number: number, Text: text})
+	}
+
+	return result, nil
+}
+
+// pptxSlideOrder returns the archive paths of a presentation's slides in
+// presentation order, as listed in presentation.xml. Packages without a
+// usable slide list fall back to the numeric order of the slide file names.
+func pptxSlideOrder(files map[string]*zip.File) []string {
+	var presentation struct {
+		Slides []struct {
+			RelID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sldIdLst>sldId"`
+	}
+	var relationships struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+
+	var ordered []string
+	if decodeZipXML(files["ppt/presentation.xml"], &presentation) == nil &&
+		decodeZipXML(files["ppt/_rels/presentation.xml.rels"], &relationships) == nil {
+		targets := make(map[string]string, len(relationships.Relationships))
+		for _, rel := range relationships.Relationships {
+			// Targets are relative to ppt/ unless they start at the package root
+			if strings.HasPrefix(rel.Target, "/") {
+				targets[rel.ID] = strings.TrimPrefix(rel.Target, "/")
+			} else {
+				targets[rel.ID] = path.Join("ppt", rel.Target)
+			}
+		}
+		for _, slide := range presentation.Slides {
+			if target, ok := targets[slide.RelID]; ok && files[target] != nil {
+				ordered = append(ordered, target)
+			}
+		}
+	}
+	if len(ordered) > 0 {
+		return ordered
+	}
+
+	for name := range files {
+		if pptxSlidePattern.MatchString(name) {
+			ordered = append(ordered, name)
+		}
+	}
+	sort.Slice(ordered, func(i, j int) bool { return pptxSlideNumber(ordered[i]) < pptxSlideNumber(ordered[j]) })
+	return ordered
+}
+
+// pptxSlideNumber returns N for ppt/slides/slideN.xml
+func pptxSlideNumber(name string) int {
+	number, _ := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(name, "ppt/slides/slide"), ".xml"))
+	return number
+}
+
+// readZipFile returns the content of a file in a zip archive
+func readZipFile(file *zip.File) (string, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+
+	content, err := io.ReadAll(rc)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// parsePageSelection expands a selection such as "1-5,8,12-" into sorted,
+// distinct page numbers; open-ended ranges run to the last page
+func parsePageSelection(selection string, total int) ([]int, error) {
+	if total == 0 {
+		return nil, fmt.Errorf("document has no pages")
+	}
+	if strings.TrimSpace(selection) == "" {
+		selection = "1-"
+	}
+
+	seen := make(map[int]bool)
+	var numbers []int
+	for _, part := range strings.Split(selection, ",") {
+		part = strings.TrimSpace(part)
+		startText, endText, isRange := strings.Cut(part, "-")
+
+		start, err := strconv.Atoi(strings.TrimSpace(startText))
+		if err != nil || start < 1 {
+			return nil, fmt.Errorf("invalid page selection %q: pages are numbered from 1", part)
+		}
+		end := start
+		if isRange {
+			if endText = strings.TrimSpace(endText); endText == "" {
+				end = total
+			} else if end, err = strconv.Atoi(endText); err != nil || end < start {
+				return nil, fmt.Errorf("invalid page range %q", part)
+			}
+		}
+		if start > total {
+			return nil, fmt.Errorf("page %d is out of range: document has %d pages", start, total)
+		}
+
+		for number := start; number <= min(end, total); number++ {
+			if !seen[number] {
+				seen[number] = true
+				numbers = append(numbers, number)
+			}
+		}
+	}
+
+	sort.Ints(numbers)
+	return numbers, nil
+}
@@ -0,0 +1,202 @@
+package document
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeTestPDF writes a minimal PDF with one line of Helvetica text per page
+func writeTestPDF(t *testing.T, path string, pages ...string) {
+	t.Helper()
+	writeTestPDFWithInfo(t, path, "", pages...)
+}
+
+// writeTestPDFWithInfo writes a minimal PDF whose document information
+// dictionary has the given entries, e.g. "/Title (Report)"
+func writeTestPDFWithInfo(t *testing.T, path, info string, pages ...string) {
+	t.Helper()
+
+	streams := make([]string, len(pages))
+	for i, text := range pages {
+		streams[i] = fmt.Sprintf("BT /F1 12 Tf 72 720 Td (%s) Tj ET", text)
+	}
+	writeTestPDFStreams(t, path, info, streams...)
+}
+
+// writeTestPDFStreams writes a PDF with one content stream per page. The
+// font /F1 is Helvetica with every printable character 500 units wide.
+func writeTestPDFStreams(t *testing.T, path, info string, streams ...string) {
+	t.Helper()
+	writeTestPDFObjects(t, path, testPDF{Info: info}, streams...)
+}
+
+// testPDF holds the optional parts of a test PDF. Extra objects are numbered
+// after the pages (page i is object 4+2i, counting from 0).
+type testPDF struct {
+	Info      string   // Document information entries, e.g. "/Title (Report)"
+	Catalog   string   // Extra catalog entries, e.g. "/Outlines 10 0 R"
+	Resources string   // Extra page resources, e.g. "/XObject << /Im0 10 0 R >>"
+	Page      string   // Extra entries of every page, e.g. "/Annots [10 0 R]"
+	Extra     []string // Extra objects
+}
+
+// writeTestPDFObjects writes a PDF like writeTestPDFStreams with the parts in doc
+func writeTestPDFObjects(t *testing.T, path string, doc testPDF, streams ...string) {
+	t.Helper()
+
+	var objects []string
+	kids := make([]string, len(streams))
+	for i := range streams {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+i*2)
+	}
+	widths := strings.TrimSpace(strings.Repeat("500 ", 126-32+1))
+	objects = append(objects,
+		"<< /Type /Catalog /Pages 2 0 R "+doc.Catalog+" >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(streams)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding /FirstChar 32 /LastChar 126 /Widths ["+widths+"] >>",
+	)
+	for i, stream := range streams {
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 3 0 R >> %s >> /Contents %d 0 R %s>>", doc.Resources, 5+i*2, doc.Page),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(stream), stream),
+		)
+	}
+	objects = append(objects, doc.Extra...)
+
+	trailerInfo := ""
+	if doc.Info != "" {
+		objects = append(objects, "<< "+doc.Info+" >>")
+		trailerInfo = fmt.Sprintf(" /Info %d 0 R", len(objects))
+	}
+
+	var content strings.Builder
+	content.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = content.Len()
+		fmt.Fprintf(&content, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := content.Len()
+	fmt.Fprintf(&content, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&content, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&content, "trailer\n<< /Size %d /Root 1 0 R%s >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, trailerInfo, xref)
+
+	if err := os.WriteFile(path, []byte(content.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeTestPptx writes a presentation whose slide list orders the slide parts;
+// slides[i] is stored as ppt/slides/slide{i+1}.xml
+func writeTestPptx(t *testing.T, path string, order []int, slides ...string) {
+	t.Helper()
+
+	shapes := make([]string, len(slides))
+	for i, text := range slides {
+		shapes[i] = `<p:sp><p:txBody><a:p><a:r><a:t>` + text + `</a:t></a:r></a:p></p:txBody></p:sp>`
+	}
+	writeTestPptxShapes(t, path, order, nil, shapes...)
+}
+
+// writeTestPptxShapes writes a presentation like writeTestPptx, with slides
+// given as the XML of their shape trees (or as whole slide parts when they
+// start with "<p:sld") and extra package entries
+func writeTestPptxShapes(t *testing.T, path string, order []int, extra [][2]string, slides ...string) {
+	t.Helper()
+
+	var ids, rels strings.Builder
+	for i, number := range order {
+		fmt.Fprintf(&ids, `<p:sldId id="%d" r:id="rId%d"/>`, 256+i, number)
+	}
+	entries := append([][2]string{}, extra...)
+	for i, shapes := range slides {
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/slide" Target="slides/slide%d.xml"/>`, i+1, i+1)
+		if !strings.HasPrefix(shapes, "<p:sld") {
+			shapes = `<p:sld xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main" xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main"><p:cSld><p:spTree>` + shapes + `</p:spTree></p:cSld></p:sld>`
+		}
+		entries = append(entries, [2]string{fmt.Sprintf("ppt/slides/slide%d.xml", i+1), shapes})
+	}
+	entries = append(entries,
+		[2]string{"ppt/presentation.xml", `<p:presentation xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><p:sldIdLst>` + ids.String() + `</p:sldIdLst></p:presentation>`},
+		[2]string{"ppt/_rels/presentation.xml.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` + rels.String() + `</Relationships>`},
+	)
+	writeTestZip(t, path, entries...)
+}
+
+func TestParsePageSelection(t *testing.T) {
+	tests := []struct {
+		selection string
+		expected  []int
+		err       string
+	}{
+		{"", []int{1, 2, 3, 4, 5}, ""},
+		{"2", []int{2}, ""},
+		{"4-", []int{4, 5}, ""},
+		{" 3-4, 1 ,4-9", []int{1, 3, 4, 5}, ""},
+		{"6", nil, "page 6 is out of range: document has 5 pages"},
+		{"0-2", nil, "pages are numbered from 1"},
+		{"3-1", nil, "invalid page range"},
+		{"x", nil, "invalid page selection"},
+	}
+
+	for _, test := range tests {
+		numbers, err := parsePageSelection(test.selection, 5)
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("parsePageSelection(%q) error = %v, want %q", test.selection, err, test.err)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(numbers, test.expected) {
+			t.Errorf("parsePageSelection(%q) = %v, %v, want %v", test.selection, numbers, err, test.expected)
+		}
+	}
+}
+
+func TestExtractPDFPages(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.pdf")
+	writeTestPDF(t, path, "Introduction", "Method", "Results")
+
+	extraction, err := NewManager().ExtractPages(context.Background(), path, "2-")
+	if err != nil {
+		t.Fatalf("ExtractPages failed: %v", err)
+	}
+	if extraction.Unit != "page" || extraction.TotalPages != 3 || len(extraction.Pages) != 2 {
+		t.Fatalf("Unexpected extraction: %+v", extraction)
+	}
+	if extraction.Pages[0].Number != 2 || extraction.Pages[0].Text != "Method" || extraction.Pages[1].Text != "Results" {
+		t.Errorf("Unexpected pages: %+v", extraction.Pages)
+	}
+}
+
+func TestExtractPptxSlides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deck.pptx")
+	// The slide stored second is shown first
+	writeTestPptx(t, path, []int{2, 1, 3}, "Budget", "Agenda", "Questions")
+
+	extraction, err := NewManager().ExtractPages(context.Background(), path, "1,3")
+	if err != nil {
+		t.Fatalf("ExtractPages failed: %v", err)
+	}
+	expected := []PageText{{Number: 1, Text: "Agenda"}, {Number: 3, Text: "Questions"}}
+	if extraction.Unit != "slide" || extraction.TotalPages != 3 || !reflect.DeepEqual(extraction.Pages, expected) {
+		t.Errorf("Unexpected extraction: %+v", extraction)
+	}
+}
+
+func TestExtractPagesUnsupportedFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.md")
+	if err := os.WriteFile(path, []byte("# Notes"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewManager().ExtractPages(context.Background(), path, ""); err == nil || !strings.Contains(err.Error(), "supports .pdf and .pptx") {
+		t.Errorf("Expected an unsupported format error, got %v", err)
+	}
+}
@@ -0,0 +1,518 @@
+package document
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"hash"
+	"os"
+	"path/filepath"
+	"unicode/utf16"
+
+	"github.com/kevsmith/my-mcp/pkg/shared"
+	"github.com/ledongthuc/pdf"
+)
+
+// Encrypted Office documents ([MS-OFFCRYPTO]) are compound files holding the
+// encryption parameters and the encrypted OOXML package
+const (
+	encryptionInfoStream   = "EncryptionInfo"
+	encryptedPackageStream = "EncryptedPackage"
+	agileSegmentSize       = 4096
+	standardSpinCount      = 50000
+	maxSpinCount           = 10000000 // Limit set by [MS-OFFCRYPTO]
+)
+
+// Block keys of the agile encryption key derivation
+var (
+	agileVerifierInputBlock = []byte{0xfe, 0xa7, 0xd2, 0x76, 0x3b, 0x4b, 0x9e, 0x79}
+	agileVerifierHashBlock  = []byte{0xd7, 0xaa, 0x0f, 0x6d, 0x30, 0x61, 0x34, 0x4e}
+	agileKeyValueBlock      = []byte{0x14, 0x6e, 0x0b, 0xe7, 0xab, 0xac, 0xd0, 0xd6}
+)
+
+// PasswordError reports a document that is encrypted with a password that
+// was not given or is wrong
+type PasswordError struct {
+	FilePath string
+	Missing  bool // No password was given, as opposed to a wrong one
+}
+
+func (e *PasswordError) Error() string {
+	if e.Missing {
+		return fmt.Sprintf("document is password protected, a password is required: %s", e.FilePath)
+	}
+	return fmt.Sprintf("incorrect password for document: %s", e.FilePath)
+}
+
+// ErrorCode reports password errors as ACCESS_DENIED in error results
+func (e *PasswordError) ErrorCode() string {
+	return shared.CodeAccessDenied
+}
+
+type passwordKey struct{}
+
+// WithPassword returns a context carrying the password used to open
+// encrypted documents read with it
+func WithPassword(ctx context.Context, password string) context.Context {
+	return context.WithValue(ctx, passwordKey{}, password)
+}
+
+// passwordFromContext returns the password set with WithPassword, or ""
+func passwordFromContext(ctx context.Context) string {
+	password, _ := ctx.Value(passwordKey{}).(string)
+	return password
+}
+
+// openPDF opens a PDF, decrypting it with password when it is encrypted.
+// PDFs protected by an owner password only open without one.
+func openPDF(filePath, password string) (*os.File, *pdf.Reader, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+
+	tried := false
+	reader, err := pdf.NewReaderEncrypted(file, stat.Size(), func() string {
+		if tried {
+			return ""
+		}
+		tried = true
+		return password
+	})
+	if err != nil {
+		file.Close()
+		if errors.Is(err, pdf.ErrInvalidPassword) {
+			return nil, nil, &PasswordError{FilePath: filePath, Missing: password == ""}
+		}
+		return nil, nil, err
+	}
+	return file, reader, nil
+}
+
+// withPDF opens a PDF like openPDF and calls fn with its reader. The reader
+// panics on malformed objects; the panic is returned as an error.
+func withPDF(filePath, password string, fn func(reader *pdf.Reader) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("failed to parse PDF: %v", r)
+		}
+	}()
+
+	file, reader, err := openPDF(filePath, password)
+	var passwordErr *PasswordError
+	if errors.As(err, &passwordErr) {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open PDF file: %w", err)
+	}
+	defer file.Close()
+	return fn(reader)
+}
+
+// unlockedDocument is a document ready to be read: the file itself, or a
+// decrypted temporary copy of an encrypted Office document
+type unlockedDocument struct {
+	Path      string
+	Encrypted bool
+	temporary bool
+}
+
+// Close removes the decrypted copy, if any
+func (d *unlockedDocument) Close() {
+	if d.temporary {
+		os.Remove(d.Path)
+	}
+}
+
+// unlock decrypts an encrypted Office document to a temporary file readable
+// only by the current user, so that the extractors can read it like any
+// other; other files are returned as they are. PDFs are decrypted by the PDF
+// reader itself. Failures other than a missing or wrong password leave the
+// file to the extractors to report.
+func (m *Manager) unlock(ctx context.Context, filePath string) (*unlockedDocument, error) {
+	document := &unlockedDocument{Path: filePath}
+	password := passwordFromContext(ctx)
+
+	if !fileHasPrefix(filePath, oleDoc) {
+		return document, nil
+	}
+	streams, err := readOLEStreams(filePath, encryptionInfoStream, encryptedPackageStream)
+	if err != nil || streams[encryptionInfoStream] == nil || streams[encryptedPackageStream] == nil {
+		return document, nil
+	}
+	document.Encrypted = true
+	if password == "" {
+		return nil, &PasswordError{FilePath: filePath, Missing: true}
+	}
+
+	data, err := decryptOfficePackage(streams[encryptionInfoStream], streams[encryptedPackageStream], password)
+	if errors.Is(err, errWrongPassword) {
+		return nil, &PasswordError{FilePath: filePath}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt document: %w", err)
+	}
+
+	temp, err := os.CreateTemp("", "decrypted-*"+ooxmlPackageExtension(data, filePath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create decrypted copy: %w", err)
+	}
+	_, writeErr := temp.Write(data)
+	if err := errors.Join(writeErr, temp.Close()); err != nil {
+		os.Remove(temp.Name())
+		return nil, fmt.Errorf("failed to write decrypted copy: %w", err)
+	}
+	document.Path, document.temporary = temp.Name(), true
+	return document, nil
+}
+
+// pdfEncrypted reports whether a PDF is encrypted, returning a
+// PasswordError when password doesn't open it
+func pdfEncrypted(filePath, password string) (bool, error) {
+	encrypted := false
+	err := withPDF(filePath, password, func(reader *pdf.Reader) error {
+		encrypted = reader.Trailer().Key("Encrypt").Kind() != pdf.Null
+		return nil
+	})
+	var passwordErr *PasswordError
+	if errors.As(err, &passwordErr) {
+		return true, err
+	}
+	return encrypted, nil
+}
+
+// fileHasPrefix reports whether a file starts with prefix
+func fileHasPrefix(filePath string, prefix []byte) bool {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	buffer := make([]byte, len(prefix))
+	n, _ := file.Read(buffer)
+	return bytes.Equal(buffer[:n], prefix)
+}
+
+// ooxmlPackageExtension names a decrypted package by the type of its main
+// part, falling back to the extension of the encrypted file
+func ooxmlPackageExtension(data []byte, filePath string) string {
+	if reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data))); err == nil {
+		for _, f := range reader.File {
+			switch f.Name {
+			case "word/document.xml":
+				return ".docx"
+			case "ppt/presentation.xml":
+				return ".pptx"
+			}
+		}
+	}
+	return filepath.Ext(filePath)
+}
+
+var errWrongPassword = errors.New("wrong password")
+
+// decryptOfficePackage decrypts the EncryptedPackage stream of an Office
+// document with agile (Office 2010 and later) or standard (Office 2007)
+// encryption
+func decryptOfficePackage(info, encrypted []byte, password string) ([]byte, error) {
+	if len(info) < 8 || len(encrypted) < 8 {
+		return nil, fmt.Errorf("invalid encryption info")
+	}
+	major, minor := binary.LittleEndian.Uint16(info), binary.LittleEndian.Uint16(info[2:])
+	size := binary.LittleEndian.Uint64(encrypted)
+	encrypted = encrypted[8:]
+	if size > uint64(len(encrypted)) {
+		return nil, fmt.Errorf("encrypted package is truncated")
+	}
+
+	var data []byte
+	var err error
+	switch {
+	case major == 4 && minor == 4:
+		data, err = decryptAgile(info[8:], encrypted, password)
+	case (major == 3 || major == 4) && minor == 2:
+		data, err = decryptStandard(info[8:], encrypted, password)
+	default:
+		return nil, shared.NewError(shared.CodeUnsupportedFormat, "unsupported encryption version %d.%d", major, minor)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if size > uint64(len(data)) {
+		return nil, fmt.Errorf("encrypted package is truncated")
+	}
+	return data[:size], nil
+}
+
+// agileEncryption is the XML descriptor of agile encryption
+type agileEncryption struct {
+	KeyData       agileKeyParams `xml:"keyData"`
+	KeyEncryptors []struct {
+		URI          string         `xml:"uri,attr"`
+		EncryptedKey agileKeyParams `xml:"encryptedKey"`
+	} `xml:"keyEncryptors>keyEncryptor"`
+}
+
+type agileKeyParams struct {
+	SpinCount                  int    `xml:"spinCount,attr"`
+	BlockSize                  int    `xml:"blockSize,attr"`
+	KeyBits                    int    `xml:"keyBits,attr"`
+	HashSize                   int    `xml:"hashSize,attr"`
+	CipherAlgorithm            string `xml:"cipherAlgorithm,attr"`
+	CipherChaining             string `xml:"cipherChaining,attr"`
+	HashAlgorithm              string `xml:"hashAlgorithm,attr"`
+	SaltValue                  string `xml:"saltValue,attr"`
+	EncryptedVerifierHashInput string `xml:"encryptedVerifierHashInput,attr"`
+	EncryptedVerifierHashValue string `xml:"encryptedVerifierHashValue,attr"`
+	EncryptedKeyValue          string `xml:"encryptedKeyValue,attr"`
+}
+
+// decryptAgile derives the key encryption keys from the password, checks the
+// password against the verifier and decrypts the package key, then the
+// package in 4096-byte segments
+func decryptAgile(descriptor, encrypted []byte, password string) ([]byte, error) {
+	var encryption agileEncryption
+	if err := xml.Unmarshal(bytes.TrimRight(descriptor, "\x00"), &encryption); err != nil {
+		return nil, fmt.Errorf("invalid encryption descriptor: %w", err)
+	}
+
+	var params *agileKeyParams
+	for i := range encryption.KeyEncryptors {
+		if encryption.KeyEncryptors[i].URI == "http://schemas.microsoft.com/office/2006/keyEncryptor/password" {
+			params = &encryption.KeyEncryptors[i].EncryptedKey
+		}
+	}
+	if params == nil {
+		return nil, fmt.Errorf("document is not encrypted with a password")
+	}
+	for _, p := range []*agileKeyParams{params, &encryption.KeyData} {
+		if p.CipherAlgorithm != "AES" || p.CipherChaining != "ChainingModeCBC" {
+			return nil, shared.NewError(shared.CodeUnsupportedFormat, "unsupported cipher %s %s", p.CipherAlgorithm, p.CipherChaining)
+		}
+		if p.KeyBits != 128 && p.KeyBits != 192 && p.KeyBits != 256 || p.BlockSize != aes.BlockSize {
+			return nil, shared.NewError(shared.CodeUnsupportedFormat, "unsupported key size %d", p.KeyBits)
+		}
+	}
+	if params.SpinCount < 0 || params.SpinCount > maxSpinCount {
+		return nil, fmt.Errorf("invalid spin count %d", params.SpinCount)
+	}
+
+	newHash, err := agileHash(params.HashAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	salt, err := base64.StdEncoding.DecodeString(params.SaltValue)
+	if err != nil {
+		return nil, fmt.Errorf("invalid salt: %w", err)
+	}
+
+	// H0 = H(salt + password), then Hn = H(iterator + Hn-1)
+	digest := hashOf(newHash, salt, utf16LE(password))
+	iterator := make([]byte, 4)
+	for i := range params.SpinCount {
+		binary.LittleEndian.PutUint32(iterator, uint32(i))
+		digest = hashOf(newHash, iterator, digest)
+	}
+	decryptValue := func(block []byte, value string) ([]byte, error) {
+		key := padKey(hashOf(newHash, digest, block), params.KeyBits/8, 0x36)
+		data, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid encrypted key: %w", err)
+		}
+		return decryptCBC(key, padKey(salt, params.BlockSize, 0x36), data)
+	}
+
+	verifierInput, err := decryptValue(agileVerifierInputBlock, params.EncryptedVerifierHashInput)
+	if err != nil {
+		return nil, err
+	}
+	verifierHash, err := decryptValue(agileVerifierHashBlock, params.EncryptedVerifierHashValue)
+	if err != nil {
+		return nil, err
+	}
+	expected := hashOf(newHash, verifierInput[:min(len(salt), len(verifierInput))])
+	if len(verifierHash) < len(expected) || subtle.ConstantTimeCompare(expected, verifierHash[:len(expected)]) != 1 {
+		return nil, errWrongPassword
+	}
+
+	keyValue, err := decryptValue(agileKeyValueBlock, params.EncryptedKeyValue)
+	if err != nil {
+		return nil, err
+	}
+	if len(keyValue) < encryption.KeyData.KeyBits/8 {
+		return nil, fmt.Errorf("invalid encrypted key")
+	}
+	key := keyValue[:encryption.KeyData.KeyBits/8]
+
+	dataHash, err := agileHash(encryption.KeyData.HashAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	dataSalt, err := base64.StdEncoding.DecodeString(encryption.KeyData.SaltValue)
+	if err != nil {
+		return nil, fmt.Errorf("invalid salt: %w", err)
+	}
+
+	// Each segment's IV is the hash of the salt and the segment index
+	data := make([]byte, 0, len(encrypted))
+	segment := make([]byte, 4)
+	for i := 0; i*agileSegmentSize < len(encrypted); i++ {
+		chunk := encrypted[i*agileSegmentSize : min((i+1)*agileSegmentSize, len(encrypted))]
+		chunk = chunk[:len(chunk)-len(chunk)%aes.BlockSize]
+		binary.LittleEndian.PutUint32(segment, uint32(i))
+		iv := padKey(hashOf(dataHash, dataSalt, segment), aes.BlockSize, 0x36)
+		plain, err := decryptCBC(key, iv, chunk)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, plain...)
+	}
+	return data, nil
+}
+
+// decryptStandard decrypts a package with standard encryption: an AES key
+// derived from the password with SHA-1 and the package in ECB mode
+func decryptStandard(info, encrypted []byte, password string) ([]byte, error) {
+	if len(info) < 4 {
+		return nil, fmt.Errorf("invalid encryption info")
+	}
+	headerSize := int(binary.LittleEndian.Uint32(info))
+	if headerSize < 32 || len(info) < 4+headerSize+40 {
+		return nil, fmt.Errorf("invalid encryption header")
+	}
+	header := info[4 : 4+headerSize]
+	keyBits := int(binary.LittleEndian.Uint32(header[16:]))
+	if keyBits != 128 && keyBits != 192 && keyBits != 256 {
+		return nil, shared.NewError(shared.CodeUnsupportedFormat, "unsupported key size %d", keyBits)
+	}
+
+	// The verifier follows the header: salt size, salt, encrypted verifier,
+	// verifier hash size and the encrypted verifier hash
+	verifier := info[4+headerSize:]
+	saltSize := int(binary.LittleEndian.Uint32(verifier))
+	if saltSize != 16 || len(verifier) < 4+saltSize+16+4+32 {
+		return nil, fmt.Errorf("invalid encryption verifier")
+	}
+	salt := verifier[4 : 4+saltSize]
+	encryptedVerifier := verifier[4+saltSize : 4+saltSize+16]
+	encryptedVerifierHash := verifier[4+saltSize+16+4 : 4+saltSize+16+4+32]
+
+	digest := hashOf(sha1.New, salt, utf16LE(password))
+	iterator := make([]byte, 4)
+	for i := range standardSpinCount {
+		binary.LittleEndian.PutUint32(iterator, uint32(i))
+		digest = hashOf(sha1.New, iterator, digest)
+	}
+	digest = hashOf(sha1.New, digest, make([]byte, 4)) // Block 0
+
+	// Stretch the hash to the key size as CryptDeriveKey does
+	stretch := func(fill byte) []byte {
+		buffer := bytes.Repeat([]byte{fill}, 64)
+		for i := range digest {
+			buffer[i] ^= digest[i]
+		}
+		return hashOf(sha1.New, buffer)
+	}
+	key := append(stretch(0x36), stretch(0x5c)...)[:keyBits/8]
+
+	plainVerifier, err := decryptECB(key, encryptedVerifier)
+	if err != nil {
+		return nil, err
+	}
+	plainHash, err := decryptECB(key, encryptedVerifierHash)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare(hashOf(sha1.New, plainVerifier), plainHash[:sha1.Size]) != 1 {
+		return nil, errWrongPassword
+	}
+
+	return decryptECB(key, encrypted[:len(encrypted)-len(encrypted)%aes.BlockSize])
+}
+
+// agileHash returns the hash function named in an agile encryption descriptor
+func agileHash(name string) (func() hash.Hash, error) {
+	switch name {
+	case "SHA1":
+		return sha1.New, nil
+	case "SHA256":
+		return sha256.New, nil
+	case "SHA384":
+		return sha512.New384, nil
+	case "SHA512":
+		return sha512.New, nil
+	}
+	return nil, shared.NewError(shared.CodeUnsupportedFormat, "unsupported hash algorithm %q", name)
+}
+
+func hashOf(newHash func() hash.Hash, parts ...[]byte) []byte {
+	h := newHash()
+	for _, part := range parts {
+		h.Write(part)
+	}
+	return h.Sum(nil)
+}
+
+// padKey truncates value to size bytes or pads it with fill
+func padKey(value []byte, size int, fill byte) []byte {
+	if len(value) >= size {
+		return value[:size]
+	}
+	return append(append([]byte{}, value...), bytes.Repeat([]byte{fill}, size-len(value))...)
+}
+
+func decryptCBC(key, iv, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("encrypted data is not a multiple of the block size")
+	}
+	plain := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plain, data)
+	return plain, nil
+}
+
+func decryptECB(key, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("encrypted data is not a multiple of the block size")
+	}
+	plain := make([]byte, len(data))
+	for i := 0; i < len(data); i += aes.BlockSize {
+		block.Decrypt(plain[i:i+aes.BlockSize], data[i:i+aes.BlockSize])
+	}
+	return plain, nil
+}
+
+// utf16LE encodes a password as Office hashes it
+func utf16LE(s string) []byte {
+	units := utf16.Encode([]rune(s))
+	encoded := make([]byte, 2*len(units))
+	for i, unit := range units {
+		binary.LittleEndian.PutUint16(encoded[2*i:], unit)
+	}
+	return encoded
+}
@@ -0,0 +1,348 @@
+package document
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/md5"
+	"crypto/rc4"
+	"crypto/sha1"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// encryptTestAgile encrypts an OOXML package with agile encryption
+// (SHA-512, AES-256) and returns the EncryptionInfo and EncryptedPackage streams
+func encryptTestAgile(t *testing.T, data []byte, password string) (info, encrypted []byte) {
+	t.Helper()
+
+	const spinCount = 1000
+	passwordSalt := bytes.Repeat([]byte{0x11}, 16)
+	dataSalt := bytes.Repeat([]byte{0x22}, 16)
+	secretKey := bytes.Repeat([]byte{0x33}, 32)
+	verifierInput := bytes.Repeat([]byte{0x44}, 16)
+
+	digest := hashOf(sha512.New, passwordSalt, utf16LE(password))
+	iterator := make([]byte, 4)
+	for i := range spinCount {
+		binary.LittleEndian.PutUint32(iterator, uint32(i))
+		digest = hashOf(sha512.New, iterator, digest)
+	}
+	encryptValue := func(block, value []byte) string {
+		return base64.StdEncoding.EncodeToString(testEncryptCBC(t, hashOf(sha512.New, digest, block)[:32], passwordSalt, value))
+	}
+
+	var pkg []byte
+	segment := make([]byte, 4)
+	for i := 0; i*agileSegmentSize < len(data); i++ {
+		chunk := data[i*agileSegmentSize : min((i+1)*agileSegmentSize, len(data))]
+		binary.LittleEndian.PutUint32(segment, uint32(i))
+		iv := hashOf(sha512.New, dataSalt, segment)[:aes.BlockSize]
+		pkg = append(pkg, testEncryptCBC(t, secretKey, iv, chunk)...)
+	}
+
+	params := `blockSize="16" keyBits="256" hashSize="64" cipherAlgorithm="AES" cipherChaining="ChainingModeCBC" hashAlgorithm="SHA512"`
+	descriptor := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`+
+		`<encryption xmlns="http://schemas.microsoft.com/office/2006/encryption" xmlns:p="http://schemas.microsoft.com/office/2006/keyEncryptor/password">`+
+		`<keyData saltSize="16" %s saltValue="%s"/>`+
+		`<keyEncryptors><keyEncryptor uri="http://schemas.microsoft.com/office/2006/keyEncryptor/password">`+
+		`<p:encryptedKey spinCount="%d" saltSize="16" %s saltValue="%s" encryptedVerifierHashInput="%s" encryptedVerifierHashValue="%s" encryptedKeyValue="%s"/>`+
+		`</keyEncryptor></keyEncryptors></encryption>`,
+		params, base64.StdEncoding.EncodeToString(dataSalt), spinCount, params,
+		base64.StdEncoding.EncodeToString(passwordSalt),
+		encryptValue(agileVerifierInputBlock, verifierInput),
+		encryptValue(agileVerifierHashBlock, hashOf(sha512.New, verifierInput)),
+		encryptValue(agileKeyValueBlock, secretKey))
+
+	info = append([]byte{4, 0, 4, 0, 0x40, 0, 0, 0}, descriptor...)
+	return info, testPackageStream(data, pkg)
+}
+
+// encryptTestStandard encrypts an OOXML package with standard encryption
+// (AES-128) and returns the EncryptionInfo and EncryptedPackage streams
+func encryptTestStandard(t *testing.T, data []byte, password string) (info, encrypted []byte) {
+	t.Helper()
+
+	salt := bytes.Repeat([]byte{0x55}, 16)
+	verifier := bytes.Repeat([]byte{0x66}, 16)
+
+	digest := hashOf(sha1.New, salt, utf16LE(password))
+	iterator := make([]byte, 4)
+	for i := range standardSpinCount {
+		binary.LittleEndian.PutUint32(iterator, uint32(i))
+		digest = hashOf(sha1.New, iterator, digest)
+	}
+	digest = hashOf(sha1.New, digest, make([]byte, 4))
+	buffer := bytes.Repeat([]byte{0x36}, 64)
+	for i := range digest {
+		buffer[i] ^= digest[i]
+	}
+	key := hashOf(sha1.New, buffer)[:16]
+
+	header := make([]byte, 32)
+	binary.LittleEndian.PutUint32(header, 0x24)        // Flags: AES, CryptoAPI
+	binary.LittleEndian.PutUint32(header[8:], 0x660E)  // AES-128
+	binary.LittleEndian.PutUint32(header[12:], 0x8004) // SHA-1
+	binary.LittleEndian.PutUint32(header[16:], 128)
+	binary.LittleEndian.PutUint32(header[20:], 0x18) // RSA AES provider
+
+	info = []byte{4, 0, 2, 0, 0x24, 0, 0, 0}
+	info = binary.LittleEndian.AppendUint32(info, uint32(len(header)))
+	info = append(info, header...)
+	info = binary.LittleEndian.AppendUint32(info, uint32(len(salt)))
+	info = append(info, salt...)
+	info = append(info, testEncryptECB(t, key, verifier)...)
+	info = binary.LittleEndian.AppendUint32(info, sha1.Size)
+	info = append(info, testEncryptECB(t, key, hashOf(sha1.New, verifier))...)
+
+	return info, testPackageStream(data, testEncryptECB(t, key, data))
+}
+
+// testPackageStream prefixes an encrypted package with its decrypted size
+func testPackageStream(data, encrypted []byte) []byte {
+	return append(binary.LittleEndian.AppendUint64(nil, uint64(len(data))), encrypted...)
+}
+
+func testEncryptCBC(t *testing.T, key, iv, data []byte) []byte {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	padded := append(append([]byte{}, data...), make([]byte, (aes.BlockSize-len(data)%aes.BlockSize)%aes.BlockSize)...)
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(padded, padded)
+	return padded
+}
+
+func testEncryptECB(t *testing.T, key, data []byte) []byte {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	padded := append(append([]byte{}, data...), make([]byte, (aes.BlockSize-len(data)%aes.BlockSize)%aes.BlockSize)...)
+	for i := 0; i < len(padded); i += aes.BlockSize {
+		block.Encrypt(padded[i:i+aes.BlockSize], padded[i:i+aes.BlockSize])
+	}
+	return padded
+}
+
+// writeTestEncryptedDocx writes a DOCX encrypted with password by encrypt
+func writeTestEncryptedDocx(t *testing.T, path, password string, encrypt func(*testing.T, []byte, string) ([]byte, []byte)) {
+	t.Helper()
+
+	plain := filepath.Join(t.TempDir(), "plain.docx")
+	writeTestZip(t, plain,
+		[2]string{"word/document.xml", `<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>` +
+			`<w:p><w:r><w:t>Salary review for the board</w:t></w:r></w:p></w:body></w:document>`},
+		[2]string{"word/_rels/document.xml.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"></Relationships>`},
+		[2]string{"docProps/core.xml", `<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/">` +
+			`<dc:title>Salaries 2026</dc:title></cp:coreProperties>`},
+	)
+	data, err := os.ReadFile(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, encrypted := encrypt(t, data, password)
+	writeTestOLE(t, path, oleStream{encryptionInfoStream, info}, oleStream{encryptedPackageStream, encrypted})
+}
+
+// testPDFPasswordPad pads passwords in the PDF standard security handler
+var testPDFPasswordPad = []byte{
+	0x28, 0xBF, 0x4E, 0x5E, 0x4E, 0x75, 0x8A, 0x41, 0x64, 0x00, 0x4E, 0x56, 0xFF, 0xFA, 0x01, 0x08,
+	0x2E, 0x2E, 0x00, 0xB6, 0xD0, 0x68, 0x3E, 0x80, 0x2F, 0x0C, 0xA9, 0xFE, 0x64, 0x53, 0x69, 0x7A,
+}
+
+// writeTestEncryptedPDF writes a one-page PDF encrypted with 128-bit RC4
+// (revision 3), the given user password and permission bits
+func writeTestEncryptedPDF(t *testing.T, path, password string, permissions uint32, text string) {
+	t.Helper()
+
+	rc4XOR := func(key, data []byte) []byte {
+		c, err := rc4.NewCipher(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := make([]byte, len(data))
+		c.XORKeyStream(out, data)
+		return out
+	}
+
+	id := []byte("0123456789abcdef")
+	o := bytes.Repeat([]byte{0x77}, 32) // Only readers checking the owner password use it
+	padded := append([]byte(password), testPDFPasswordPad[:32-len(password)]...)
+	key := md5.Sum(bytes.Join([][]byte{padded, o, binary.LittleEndian.AppendUint32(nil, permissions), id}, nil))
+	for range 50 {
+		key = md5.Sum(key[:])
+	}
+
+	// U is the hash of the padding and the ID, encrypted 20 times
+	digest := md5.Sum(append(append([]byte{}, testPDFPasswordPad...), id...))
+	u := digest[:]
+	for i := range 20 {
+		iterationKey := make([]byte, len(key))
+		for j := range key {
+			iterationKey[j] = key[j] ^ byte(i)
+		}
+		u = rc4XOR(iterationKey, u)
+	}
+	u = append(u, make([]byte, 16)...)
+
+	// The content stream (object 4) is encrypted with its object key
+	objectKey := md5.Sum(append(append([]byte{}, key[:]...), 4, 0, 0, 0, 0))
+	content := rc4XOR(objectKey[:], []byte("BT /F1 12 Tf 72 720 Td ("+text+") Tj ET"))
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 5 0 R >> >> /Contents 4 0 R >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding /FirstChar 32 /LastChar 126 /Widths [" +
+			strings.TrimSpace(strings.Repeat("500 ", 126-32+1)) + "] >>",
+		fmt.Sprintf("<< /Filter /Standard /V 2 /R 3 /Length 128 /O <%x> /U <%x> /P %d >>", o, u, int32(permissions)),
+	}
+
+	var pdfContent strings.Builder
+	pdfContent.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = pdfContent.Len()
+		fmt.Fprintf(&pdfContent, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := pdfContent.Len()
+	fmt.Fprintf(&pdfContent, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&pdfContent, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&pdfContent, "trailer\n<< /Size %d /Root 1 0 R /Encrypt 6 0 R /ID [<%x> <%x>] >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, id, id, xref)
+
+	if err := os.WriteFile(path, []byte(pdfContent.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// assertPasswordError checks that err is a PasswordError for a missing or
+// wrong password
+func assertPasswordError(t *testing.T, err error, missing bool) {
+	t.Helper()
+	var passwordErr *PasswordError
+	if !errors.As(err, &passwordErr) || passwordErr.Missing != missing {
+		t.Errorf("Expected a PasswordError with Missing=%v, got %v", missing, err)
+	}
+}
+
+func TestEncryptedDocx(t *testing.T) {
+	encryptions := map[string]func(*testing.T, []byte, string) ([]byte, []byte){
+		"agile":    encryptTestAgile,
+		"standard": encryptTestStandard,
+	}
+	for name, encrypt := range encryptions {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "salaries.docx")
+			writeTestEncryptedDocx(t, path, "s3cret", encrypt)
+			m := NewManager()
+
+			_, err := m.ExtractText(context.Background(), path)
+			assertPasswordError(t, err, true)
+			_, err = m.ExtractText(WithPassword(context.Background(), "wrong"), path)
+			assertPasswordError(t, err, false)
+
+			text, err := m.ExtractText(WithPassword(context.Background(), "s3cret"), path)
+			if err != nil {
+				t.Fatalf("ExtractText failed: %v", err)
+			}
+			if !strings.Contains(text, "Salary review for the board") {
+				t.Errorf("Expected the decrypted text, got %q", text)
+			}
+
+			// The decrypted text is not cached for callers without the password
+			_, err = m.ExtractText(context.Background(), path)
+			assertPasswordError(t, err, true)
+		})
+	}
+}
+
+func TestEncryptedDocxInfo(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "salaries.docx")
+	writeTestEncryptedDocx(t, path, "s3cret", encryptTestAgile)
+	m := NewManager()
+
+	info, err := m.GetDocumentInfo(context.Background(), path)
+	if err != nil {
+		t.Fatalf("GetDocumentInfo failed: %v", err)
+	}
+	if !info.Encrypted || info.Title != "" {
+		t.Errorf("Expected an encrypted document without properties, got %+v", info)
+	}
+
+	_, err = m.GetDocumentInfo(WithPassword(context.Background(), "wrong"), path)
+	assertPasswordError(t, err, false)
+
+	info, err = m.GetDocumentInfo(WithPassword(context.Background(), "s3cret"), path)
+	if err != nil {
+		t.Fatalf("GetDocumentInfo failed: %v", err)
+	}
+	if !info.Encrypted || info.Title != "Salaries 2026" || info.WordCount != 5 {
+		t.Errorf("Expected the properties of the decrypted document, got %+v", info)
+	}
+}
+
+func TestEncryptedPDF(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "salaries.pdf")
+	writeTestEncryptedPDF(t, path, "s3cret", 0xFFFFFFFC, "Salary review")
+	m := NewManager()
+
+	_, err := m.ExtractText(context.Background(), path)
+	assertPasswordError(t, err, true)
+	_, err = m.ExtractText(WithPassword(context.Background(), "wrong"), path)
+	assertPasswordError(t, err, false)
+
+	text, err := m.ExtractText(WithPassword(context.Background(), "s3cret"), path)
+	if err != nil {
+		t.Fatalf("ExtractText failed: %v", err)
+	}
+	if !strings.Contains(text, "Salary review") {
+		t.Errorf("Expected the decrypted text, got %q", text)
+	}
+
+	info, err := m.GetDocumentInfo(context.Background(), path)
+	if err != nil || !info.Encrypted || info.Pages != 0 {
+		t.Errorf("Expected an encrypted PDF without properties, got %+v, %v", info, err)
+	}
+	info, err = m.GetDocumentInfo(WithPassword(context.Background(), "s3cret"), path)
+	if err != nil || !info.Encrypted || info.Pages != 1 {
+		t.Errorf("Expected the properties of the decrypted PDF, got %+v, %v", info, err)
+	}
+}
+
+func TestWithPDF(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "plain.pdf")
+	writeTestPDF(t, path, "Hello")
+
+	// A panic of the reader is returned as an error
+	err := withPDF(path, "", func(*pdf.Reader) error { panic("malformed object") })
+	if err == nil || !strings.Contains(err.Error(), "failed to parse PDF: malformed object") {
+		t.Errorf("Expected the panic as an error, got %v", err)
+	}
+
+	if err := withPDF(filepath.Join(dir, "missing.pdf"), "", func(*pdf.Reader) error { return nil }); err == nil || !strings.Contains(err.Error(), "failed to open PDF file") {
+		t.Errorf("Expected an open error, got %v", err)
+	}
+
+	encrypted := filepath.Join(dir, "locked.pdf")
+	writeTestEncryptedPDF(t, encrypted, "secret", 0xFFFFFFFC, "Locked")
+	var passwordErr *PasswordError
+	if err := withPDF(encrypted, "", func(*pdf.Reader) error { return nil }); !errors.As(err, &passwordErr) || !passwordErr.Missing {
+		t.Errorf("Expected a missing password error, got %v", err)
+	}
+}
@@ -0,0 +1,325 @@
+package document
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// pdfDateLayouts are the accepted forms of a PDF date after its "D:" prefix,
+// from most to least precise
+var pdfDateLayouts = []string{"20060102150405-07'00'", "20060102150405-07'00", "20060102150405-0700", "20060102150405Z", "20060102150405", "200601021504", "2006010215", "20060102", "200601", "2006"}
+
+// xmlDateLayouts are the W3C date-time forms used by OOXML, ODF and XMP
+var xmlDateLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02", "2006-01", "2006"}
+
+// DocumentProperties are the descriptive properties embedded in a document.
+// Fields the format doesn't record are left empty.
+type DocumentProperties struct {
+	Title     string
+	Author    string
+	Subject   string
+	Keywords  string
+	Created   time.Time
+	Modified  time.Time
+	Pages     int    // Page count, or slide count for presentations
+	PageUnit  string // "pages" or "slides"
+	WordCount int
+}
+
+// documentProperties reads the embedded properties of a document; it is best
+// effort and returns whatever could be read
+func (m *Manager) documentProperties(ctx context.Context, filePath string) DocumentProperties {
+	var props DocumentProperties
+
+	switch m.detectFileType(filePath) {
+	case DocumentTypePDF:
+		props = pdfProperties(filePath, passwordFromContext(ctx))
+	case DocumentTypeDOCX:
+		props = ooxmlProperties(filePath)
+		props.PageUnit = "pages"
+	case DocumentTypePPTX:
+		props = ooxmlProperties(filePath)
+		props.PageUnit = "slides"
+		if props.Pages == 0 {
+			props.Pages = m.countPages(filePath)
+		}
+	case DocumentTypeDOC:
+		props = oleDocumentProperties(filePath, "PageCount")
+		props.PageUnit = "pages"
+	case DocumentTypePPT:
+		props = oleDocumentProperties(filePath, "Slide count")
+		props.PageUnit = "slides"
+	case DocumentTypeODT:
+		props = odfProperties(filePath)
+		props.PageUnit = "pages"
+	}
+
+	// Count words in the text when the document doesn't record them
+	if props.WordCount == 0 {
+		if text, err := m.ExtractText(ctx, filePath); err == nil {
+			props.WordCount = len(strings.Fields(text))
+		}
+	}
+
+	return props
+}
+
+// pdfProperties reads the document information dictionary of a PDF, falling
+// back to its XMP metadata stream for fields the dictionary lacks
+func pdfProperties(filePath, password string) DocumentProperties {
+	props := DocumentProperties{PageUnit: "pages"}
+	err := withPDF(filePath, password, func(reader *pdf.Reader) error {
+		props.Pages = reader.NumPage()
+		info := reader.Trailer().Key("Info")
+		props.Title = strings.TrimSpace(info.Key("Title").Text())
+		props.Author = strings.TrimSpace(info.Key("Author").Text())
+		props.Subject = strings.TrimSpace(info.Key("Subject").Text())
+		props.Keywords = strings.TrimSpace(info.Key("Keywords").Text())
+		props.Created = parsePDFDate(info.Key("CreationDate").Text())
+		props.Modified = parsePDFDate(info.Key("ModDate").Text())
+
+		metadata := reader.Trailer().Key("Root").Key("Metadata")
+		if metadata.Kind() == pdf.Stream {
+			rc := metadata.Reader()
+			defer rc.Close()
+			mergeProperties(&props, xmpProperties(rc))
+		}
+		return nil
+	})
+	if err != nil {
+		props.Title, props.Author, props.Subject, props.Keywords = "", "", "", ""
+	}
+	return props
+}
+
+// parsePDFDate parses a PDF date string such as "D:20240131093000+01'00'"
+func parsePDFDate(value string) time.Time {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "D:")
+	value = strings.Replace(value, "Z00'00'", "Z", 1)
+	for _, layout := range pdfDateLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed
+		}
+	}
+	return time.Time{}
+}
+
+// parseXMLDate parses the W3C date-time forms used by OOXML, ODF and XMP
+func parseXMLDate(value string) time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range xmlDateLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed
+		}
+	}
+	return time.Time{}
+}
+
+// xmlElementTexts collects the text of elements by local name, so that the
+// same reader handles the namespaced property formats of OOXML, ODF and XMP.
+// For container elements such as dc:creator, the text of their rdf:li items is
+// collected under the container's name.
+func xmlElementTexts(r io.Reader) map[string][]string {
+	texts := make(map[string][]string)
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+
+	var path []string
+	var current strings.Builder
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return texts
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			path = append(path, t.Name.Local)
+			current.Reset()
+			// ODF records statistics as attributes
+			for _, attr := range t.Attr {
+				texts[t.Name.Local+"@"+attr.Name.Local] = append(texts[t.Name.Local+"@"+attr.Name.Local], attr.Value)
+			}
+		case xml.CharData:
+			current.Write(t)
+		case xml.EndElement:
+			if len(path) == 0 {
+				continue
+			}
+			name := path[len(path)-1]
+			path = path[:len(path)-1]
+			text := strings.TrimSpace(current.String())
+			current.Reset()
+			if text == "" {
+				continue
+			}
+			if name == "li" && len(path) >= 2 {
+				name = path[len(path)-2] // dc:creator > rdf:Seq > rdf:li
+			}
+			texts[name] = append(texts[name], text)
+		}
+	}
+}
+
+// xmpProperties reads Dublin Core and XMP basic properties from an XMP packet
+func xmpProperties(r io.Reader) DocumentProperties {
+	texts := xmlElementTexts(r)
+	return DocumentProperties{
+		Title:    first(texts["title"]),
+		Author:   strings.Join(texts["creator"], "; "),
+		Subject:  first(texts["description"]),
+		Keywords: first(texts["Keywords"]),
+		Created:  parseXMLDate(first(texts["CreateDate"])),
+		Modified: parseXMLDate(first(texts["ModifyDate"])),
+	}
+}
+
+// ooxmlProperties reads docProps/core.xml and docProps/app.xml of a Word or
+// PowerPoint package
+func ooxmlProperties(filePath string) DocumentProperties {
+	var props DocumentProperties
+
+	reader, err := zip.OpenReader(filePath)
+	if err != nil {
+		return props
+	}
+	defer reader.Close()
+
+	for _, f := range reader.File {
+		if f.Name != "docProps/core.xml" && f.Name != "docProps/app.xml" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			continue
+		}
+		texts := xmlElementTexts(rc)
+		rc.Close()
+
+		mergeProperties(&props, DocumentProperties{
+			Title:     first(texts["title"]),
+			Author:    first(texts["creator"]),
+			Subject:   first(texts["subject"]),
+			Keywords:  first(texts["keywords"]),
+			Created:   parseXMLDate(first(texts["created"])),
+			Modified:  parseXMLDate(first(texts["modified"])),
+			Pages:     atoiOrZero(first(texts["Pages"])) + atoiOrZero(first(texts["Slides"])),
+			WordCount: atoiOrZero(first(texts["Words"])),
+		})
+	}
+
+	return props
+}
+
+// odfProperties reads meta.xml of an OpenDocument package
+func odfProperties(filePath string) DocumentProperties {
+	reader, err := zip.OpenReader(filePath)
+	if err != nil {
+		return DocumentProperties{}
+	}
+	defer reader.Close()
+
+	for _, f := range reader.File {
+		if f.Name != "meta.xml" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return DocumentProperties{}
+		}
+		defer rc.Close()
+
+		texts := xmlElementTexts(rc)
+		author := first(texts["initial-creator"])
+		if author == "" {
+			author = first(texts["creator"])
+		}
+		return DocumentProperties{
+			Title:     first(texts["title"]),
+			Author:    author,
+			Subject:   first(texts["subject"]),
+			Keywords:  strings.Join(texts["keyword"], ", "),
+			Created:   parseXMLDate(first(texts["creation-date"])),
+			Modified:  parseXMLDate(first(texts["date"])),
+			Pages:     atoiOrZero(first(texts["document-statistic@page-count"])),
+			WordCount: atoiOrZero(first(texts["document-statistic@word-count"])),
+		}
+	}
+
+	return DocumentProperties{}
+}
+
+// oleDocumentProperties reads the summary information of a .doc or .ppt;
+// pageProperty names the property holding the page or slide count
+func oleDocumentProperties(filePath, pageProperty string) DocumentProperties {
+	properties := oleProperties(filePath)
+	return DocumentProperties{
+		Title:     strings.TrimSpace(properties["Title"]),
+		Author:    strings.TrimSpace(properties["Author"]),
+		Subject:   strings.TrimSpace(properties["Subject"]),
+		Keywords:  strings.TrimSpace(properties["Keywords"]),
+		Created:   parseOLETime(properties["CreateTime"]),
+		Modified:  parseOLETime(properties["LastSaveTime"]),
+		Pages:     atoiOrZero(properties[pageProperty]),
+		WordCount: atoiOrZero(properties["WordCount"]),
+	}
+}
+
+// parseOLETime parses the string form of an OLE FILETIME property
+func parseOLETime(value string) time.Time {
+	parsed, err := time.Parse("2006-01-02 15:04:05 -0700 MST", value)
+	if err != nil || parsed.Year() <= 1601 {
+		return time.Time{} // Unset FILETIMEs are the 1601 epoch
+	}
+	return parsed
+}
+
+// mergeProperties fills the empty fields of props from extra
+func mergeProperties(props *DocumentProperties, extra DocumentProperties) {
+	if props.Title == "" {
+		props.Title = extra.Title
+	}
+	if props.Author == "" {
+		props.Author = extra.Author
+	}
+	if props.Subject == "" {
+		props.Subject = extra.Subject
+	}
+	if props.Keywords == "" {
+		props.Keywords = extra.Keywords
+	}
+	if props.Created.IsZero() {
+		props.Created = extra.Created
+	}
+	if props.Modified.IsZero() {
+		props.Modified = extra.Modified
+	}
+	if props.Pages == 0 {
+		props.Pages = extra.Pages
+	}
+	if props.WordCount == 0 {
+		props.WordCount = extra.WordCount
+	}
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func atoiOrZero(value string) int {
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
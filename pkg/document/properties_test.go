@@ -0,0 +1,123 @@
+package document
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParsePDFDate(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Time
+	}{
+		{"D:20240131093000+01'00'", time.Date(2024, 1, 31, 8, 30, 0, 0, time.UTC)},
+		{"D:20240131093000Z", time.Date(2024, 1, 31, 9, 30, 0, 0, time.UTC)},
+		{"D:20240131093000Z00'00'", time.Date(2024, 1, 31, 9, 30, 0, 0, time.UTC)},
+		{"D:20240131", time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)},
+		{"2024", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"yesterday", time.Time{}},
+	}
+
+	for _, test := range tests {
+		if got := parsePDFDate(test.input); !got.Equal(test.expected) {
+			t.Errorf("parsePDFDate(%q) = %v, want %v", test.input, got, test.expected)
+		}
+	}
+}
+
+func TestPDFProperties(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.pdf")
+	writeTestPDFWithInfo(t, path, "/Title (Annual Report) /Author (Finance Team) /CreationDate (D:20240131093000Z)",
+		"Revenue grew", "Costs fell")
+
+	info, err := NewManager().GetDocumentInfo(context.Background(), path)
+	if err != nil {
+		t.Fatalf("GetDocumentInfo failed: %v", err)
+	}
+	if info.Title != "Annual Report" || info.Author != "Finance Team" || info.Pages != 2 || info.PageUnit != "pages" {
+		t.Errorf("Unexpected properties: %+v", info.DocumentProperties)
+	}
+	if !info.Created.Equal(time.Date(2024, 1, 31, 9, 30, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected creation date: %v", info.Created)
+	}
+	// No recorded word count: words are counted in the text
+	if info.WordCount != 4 {
+		t.Errorf("Expected 4 words, got %d", info.WordCount)
+	}
+}
+
+func TestOOXMLProperties(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.docx")
+	writeTestZip(t, path,
+		[2]string{"word/document.xml", `<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body><w:p><w:r><w:t>Plan</w:t></w:r></w:p></w:body></w:document>`},
+		[2]string{"docProps/core.xml", `<cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:dcterms="http://purl.org/dc/terms/">
+			<dc:title>Project Plan</dc:title><dc:creator>Ana</dc:creator><dc:subject>Q3</dc:subject><cp:keywords>plan, q3</cp:keywords>
+			<dcterms:created>2024-03-01T10:00:00Z</dcterms:created><dcterms:modified>2024-03-05T16:30:00Z</dcterms:modified></cp:coreProperties>`},
+		[2]string{"docProps/app.xml", `<Properties xmlns="http://schemas.openxmlformats.org/officeDocument/2006/extended-properties"><Pages>12</Pages><Words>3400</Words></Properties>`},
+	)
+
+	props := NewManager().documentProperties(context.Background(), path)
+	expected := DocumentProperties{
+		Title: "Project Plan", Author: "Ana", Subject: "Q3", Keywords: "plan, q3",
+		Created:  time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+		Modified: time.Date(2024, 3, 5, 16, 30, 0, 0, time.UTC),
+		Pages:    12, PageUnit: "pages", WordCount: 3400,
+	}
+	if props != expected {
+		t.Errorf("Unexpected properties:\n%+v\nwant\n%+v", props, expected)
+	}
+}
+
+func TestODFProperties(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "minutes.odt")
+	writeTestZip(t, path,
+		[2]string{"mimetype", string(odtMimeType)},
+		[2]string{"meta.xml", `<office:document-meta xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:meta="urn:oasis:names:tc:opendocument:xmlns:meta:1.0" xmlns:dc="http://purl.org/dc/elements/1.1/"><office:meta>
+			<meta:initial-creator>Ben</meta:initial-creator><dc:creator>Cleo</dc:creator><dc:title>Minutes</dc:title>
+			<meta:keyword>board</meta:keyword><meta:keyword>2024</meta:keyword><meta:creation-date>2024-02-01T09:00:00</meta:creation-date>
+			<meta:document-statistic meta:page-count="3" meta:word-count="812"/></office:meta></office:document-meta>`},
+	)
+
+	props := odfProperties(path)
+	if props.Title != "Minutes" || props.Author != "Ben" || props.Keywords != "board, 2024" || props.Pages != 3 || props.WordCount != 812 {
+		t.Errorf("Unexpected properties: %+v", props)
+	}
+	if !props.Created.Equal(time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected creation date: %v", props.Created)
+	}
+}
+
+func TestXMPProperties(t *testing.T) {
+	packet := `<x:xmpmeta xmlns:x="adobe:ns:meta/"><rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">
+		<rdf:Description xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:xmp="http://ns.adobe.com/xap/1.0/">
+		<dc:title><rdf:Alt><rdf:li xml:lang="x-default">Field Guide</rdf:li></rdf:Alt></dc:title>
+		<dc:creator><rdf:Seq><rdf:li>Dee</rdf:li><rdf:li>Eli</rdf:li></rdf:Seq></dc:creator>
+		<xmp:CreateDate>2023-11-20T08:15:00+02:00</xmp:CreateDate></rdf:Description></rdf:RDF></x:xmpmeta>`
+
+	props := xmpProperties(strings.NewReader(packet))
+	if props.Title != "Field Guide" || props.Author != "Dee; Eli" {
+		t.Errorf("Unexpected properties: %+v", props)
+	}
+	if !props.Created.Equal(time.Date(2023, 11, 20, 6, 15, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected creation date: %v", props.Created)
+	}
+}
+
+func TestGetDocumentInfoUnsupportedSkipsProperties(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.bin")
+	if err := os.WriteFile(path, []byte{0, 1, 2, 3, 4}, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := NewManager().GetDocumentInfo(context.Background(), path)
+	if err != nil {
+		t.Fatalf("GetDocumentInfo failed: %v", err)
+	}
+	if info.IsSupported || info.DocumentProperties != (DocumentProperties{}) {
+		t.Errorf("Expected no properties for an unsupported file: %+v", info)
+	}
+}
@@ -0,0 +1,99 @@
+package document
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kevsmith/my-mcp/pkg/shared"
+)
+
+// Extractor returns the clean text of a document of one format. Extractors
+// should stop and return ctx.Err() once ctx is done.
+type Extractor func(ctx context.Context, filePath string) (string, error)
+
+// MetadataReader returns descriptive fields (title, author, ...) embedded in a document
+type MetadataReader func(filePath string) (map[string]string, error)
+
+// Format describes a document format the manager can extract text from
+type Format struct {
+	Type       DocumentType
+	Name       string   // Short name used in messages, e.g. "pdf"
+	Extensions []string // Lower-case file extensions including the dot
+	Extract    Extractor
+	Metadata   MetadataReader // Optional
+}
+
+// registerBuiltinFormats registers the extractors shipped with the package
+func (m *Manager) registerBuiltinFormats() {
+	m.RegisterFormat(Format{Type: DocumentTypePDF, Name: "pdf", Extensions: []string{".pdf"}, Extract: m.extractPDFText})
+	m.RegisterFormat(Format{Type: DocumentTypeDOCX, Name: "docx", Extensions: []string{".docx"}, Extract: m.extractDocxText})
+	m.RegisterFormat(Format{Type: DocumentTypePPTX, Name: "pptx", Extensions: []string{".pptx"}, Extract: m.extractPptxText})
+	m.RegisterFormat(Format{Type: DocumentTypeDOC, Name: "doc", Extensions: []string{".doc"}, Extract: m.extractDocText})
+	m.RegisterFormat(Format{Type: DocumentTypePPT, Name: "ppt", Extensions: []string{".ppt"}, Extract: m.extractPptText})
+	m.RegisterFormat(Format{Type: DocumentTypeRTF, Name: "rtf", Extensions: []string{".rtf"}, Extract: m.extractRTFText})
+	m.RegisterFormat(Format{Type: DocumentTypeODT, Name: "odt", Extensions: []string{".odt"}, Extract: m.extractODTText})
+	m.RegisterFormat(Format{Type: DocumentTypeEPUB, Name: "epub", Extensions: []string{".epub"}, Extract: m.extractEPUBText})
+	m.RegisterFormat(Format{Type: DocumentTypeHTML, Name: "html", Extensions: []string{".html", ".htm", ".xhtml"},
+		Extract: m.extractHTMLText, Metadata: htmlMetadata})
+	m.RegisterFormat(Format{Type: DocumentTypeMarkdown, Name: "markdown", Extensions: []string{".md", ".markdown"},
+		Extract: m.extractMarkdownText, Metadata: markdownMetadata})
+	m.RegisterFormat(Format{Type: DocumentTypeText, Name: "text", Extensions: []string{".txt", ".text", ".log"},
+		Extract: m.extractPlainText, Metadata: plainTextMetadata})
+}
+
+// RegisterFormat adds the extractor for a document type, replacing any
+// extractor previously registered for that type
+func (m *Manager) RegisterFormat(format Format) {
+	m.formats[format.Type] = format
+
+	// Results cached from the replaced extractor are stale
+	if m.cache != nil {
+		m.cache.Clear()
+	}
+}
+
+// Formats returns the registered formats ordered by name
+func (m *Manager) Formats() []Format {
+	formats := make([]Format, 0, len(m.formats))
+	for _, format := range m.formats {
+		formats = append(formats, format)
+	}
+	sort.Slice(formats, func(i, j int) bool { return formats[i].Name < formats[j].Name })
+	return formats
+}
+
+// formatForExtension returns the registered format claiming a file extension
+func (m *Manager) formatForExtension(ext string) (Format, bool) {
+	ext = strings.ToLower(ext)
+	for _, format := range m.formats {
+		for _, candidate := range format.Extensions {
+			if candidate == ext {
+				return format, true
+			}
+		}
+	}
+	return Format{}, false
+}
+
+// isSupportedPath reports whether a file's extension belongs to a registered format
+func (m *Manager) isSupportedPath(filePath string) bool {
+	_, ok := m.formatForExtension(filepath.Ext(filePath))
+	return ok
+}
+
+// extract runs the extractor registered for the detected type of filePath
+func (m *Manager) extract(ctx context.Context, filePath string) (string, error) {
+	if format, ok := m.formats[m.detectFileType(filePath)]; ok {
+		return format.Extract(ctx, filePath)
+	}
+
+	// Fall back to extension-based messages if magic number detection fails
+	ext := strings.ToLower(filepath.Ext(filePath))
+	if _, ok := m.formatForExtension(ext); ok {
+		return "", fmt.Errorf("file appears to be corrupted or invalid %s format", ext)
+	}
+	return "", shared.NewError(shared.CodeUnsupportedFormat, "unsupported file format: %s", ext)
+}
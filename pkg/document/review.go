@@ -0,0 +1,270 @@
+package document
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DocxComment is a reviewer comment. Anchor is the document text the
+// comment is attached to.
+type DocxComment struct {
+	ID     string `json:"id"`
+	Author string `json:"author,omitempty"`
+	Date   string `json:"date,omitempty"`
+	Text   string `json:"text"`
+	Anchor string `json:"anchor,omitempty"`
+}
+
+// DocxNote is a footnote or endnote
+type DocxNote struct {
+	ID   string `json:"id"`
+	Text string `json:"text"`
+}
+
+// DocxRevision is a tracked insertion or deletion in the document body
+type DocxRevision struct {
+	Type   string `json:"type"` // "insertion" or "deletion"
+	Author string `json:"author,omitempty"`
+	Date   string `json:"date,omitempty"`
+	Text   string `json:"text"`
+}
+
+// DocxReview is the content of a Word document that lives outside its main
+// text: comments, notes and tracked changes
+type DocxReview struct {
+	FilePath  string         `json:"file_path"`
+	Comments  []DocxComment  `json:"comments"`
+	Footnotes []DocxNote     `json:"footnotes"`
+	Endnotes  []DocxNote     `json:"endnotes"`
+	Revisions []DocxRevision `json:"revisions"`
+}
+
+// IsEmpty reports whether the document has no review content
+func (r *DocxReview) IsEmpty() bool {
+	return len(r.Comments) == 0 && len(r.Footnotes) == 0 && len(r.Endnotes) == 0 && len(r.Revisions) == 0
+}
+
+// ExtractReview returns the comments, footnotes, endnotes and tracked
+// changes of a DOCX document
+func (m *Manager) ExtractReview(filePath string) (*DocxReview, error) {
+	if m.detectFileType(filePath) != DocumentTypeDOCX {
+		return nil, fmt.Errorf("review content extraction supports .docx files only: %s", filePath)
+	}
+	if err := m.checkFileSize(filePath); err != nil {
+		return nil, err
+	}
+
+	reader, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open DOCX file: %w", err)
+	}
+	defer reader.Close()
+
+	files := make(map[string]*zip.File, len(reader.File))
+	for _, f := range reader.File {
+		files[f.Name] = f
+	}
+	if files["word/document.xml"] == nil {
+		return nil, fmt.Errorf("failed to read DOCX file: word/document.xml not found")
+	}
+
+	review := &DocxReview{FilePath: filePath}
+	var anchors map[string]string
+	parts := []struct {
+		name  string
+		parse func(io.Reader)
+	}{
+		{"word/document.xml", func(r io.Reader) { anchors, review.Revisions = docxBodyReview(r) }},
+		{"word/comments.xml", func(r io.Reader) { review.Comments = docxComments(r) }},
+		{"word/footnotes.xml", func(r io.Reader) { review.Footnotes = docxNotes(r, "footnote") }},
+		{"word/endnotes.xml", func(r io.Reader) { review.Endnotes = docxNotes(r, "endnote") }},
+	}
+	for _, part := range parts {
+		if files[part.name] == nil {
+			continue
+		}
+		rc, err := files[part.name].Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", part.name, err)
+		}
+		part.parse(rc)
+		rc.Close()
+	}
+
+	for i := range review.Comments {
+		review.Comments[i].Anchor = anchors[review.Comments[i].ID]
+	}
+	return review, nil
+}
+
+// docxBodyReview collects the text of each commented range and the tracked
+// insertions (w:ins, w:moveTo) and deletions (w:del, w:moveFrom) of the body
+func docxBodyReview(r io.Reader) (map[string]string, []DocxRevision) {
+	anchors := make(map[string]*strings.Builder)
+	var open []string // Comment ranges being read
+	var revisions []DocxRevision
+	var revision *DocxRevision
+	var revisionText strings.Builder
+	inText := false
+
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "commentRangeStart":
+				id := xmlAttr(t, "id")
+				anchors[id] = &strings.Builder{}
+				open = append(open, id)
+			case "commentRangeEnd":
+				for i, id := range open {
+					if id == xmlAttr(t, "id") {
+						open = append(open[:i], open[i+1:]...)
+						break
+					}
+				}
+			case "ins", "moveTo", "del", "moveFrom":
+				kind := "insertion"
+				if t.Name.Local == "del" || t.Name.Local == "moveFrom" {
+					kind = "deletion"
+				}
+				revision = &DocxRevision{Type: kind, Author: xmlAttr(t, "author"), Date: xmlAttr(t, "date")}
+				revisionText.Reset()
+			case "t", "delText":
+				inText = true
+			case "tab":
+				revisionText.WriteString(" ")
+			}
+		case xml.CharData:
+			if !inText {
+				continue
+			}
+			if revision != nil {
+				revisionText.Write(t)
+			}
+			for _, id := range open {
+				anchors[id].Write(t)
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t", "delText":
+				inText = false
+			case "p":
+				for _, id := range open {
+					anchors[id].WriteString(" ")
+				}
+			case "ins", "moveTo", "del", "moveFrom":
+				// Paragraph mark revisions (inside w:rPr) have no text
+				if text := strings.Join(strings.Fields(revisionText.String()), " "); revision != nil && text != "" {
+					revision.Text = text
+					revisions = append(revisions, *revision)
+				}
+				revision = nil
+			}
+		}
+	}
+
+	texts := make(map[string]string, len(anchors))
+	for id, anchor := range anchors {
+		texts[id] = strings.Join(strings.Fields(anchor.String()), " ")
+	}
+	return texts, revisions
+}
+
+// docxComments reads word/comments.xml
+func docxComments(r io.Reader) []DocxComment {
+	var comments []DocxComment
+	for _, element := range docxParagraphElements(r, "comment") {
+		comments = append(comments, DocxComment{
+			ID:     xmlAttr(element.start, "id"),
+			Author: xmlAttr(element.start, "author"),
+			Date:   xmlAttr(element.start, "date"),
+			Text:   element.text,
+		})
+	}
+	return comments
+}
+
+// docxNotes reads word/footnotes.xml or word/endnotes.xml, skipping the
+// separator notes Word stores alongside the real ones
+func docxNotes(r io.Reader, name string) []DocxNote {
+	var notes []DocxNote
+	for _, element := range docxParagraphElements(r, name) {
+		if kind := xmlAttr(element.start, "type"); kind != "" && kind != "normal" {
+			continue
+		}
+		if element.text != "" {
+			notes = append(notes, DocxNote{ID: xmlAttr(element.start, "id"), Text: element.text})
+		}
+	}
+	return notes
+}
+
+// docxElement is an element of a WordprocessingML part with its text, one
+// line per paragraph
+type docxElement struct {
+	start xml.StartElement
+	text  string
+}
+
+// docxParagraphElements returns the elements with the given local name and
+// their text
+func docxParagraphElements(r io.Reader, name string) []docxElement {
+	var elements []docxElement
+	var current *docxElement
+	var paragraphs []string
+	var paragraph strings.Builder
+	inText := false
+
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return elements
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case name:
+				current = &docxElement{start: t.Copy()}
+				paragraphs = nil
+			case "p":
+				paragraph.Reset()
+			case "t":
+				inText = true
+			case "tab":
+				paragraph.WriteString(" ")
+			}
+		case xml.CharData:
+			if inText && current != nil {
+				paragraph.Write(t)
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				if line := strings.TrimSpace(paragraph.String()); current != nil && line != "" {
+					paragraphs = append(paragraphs, line)
+				}
+			case name:
+				if current != nil {
+					current.text = strings.Join(paragraphs, "\n")
+					elements = append(elements, *current)
+				}
+				current = nil
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package document
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtractReview(t *testing.T) {
+	const namespace = `xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"`
+
+	path := filepath.Join(t.TempDir(), "review.docx")
+	writeTestZip(t, path,
+		[2]string{"word/document.xml", `<w:document ` + namespace + `><w:body>` +
+			`<w:p><w:r><w:t xml:space="preserve">The </w:t></w:r><w:commentRangeStart w:id="0"/><w:r><w:t>budget</w:t></w:r>` +
+			`<w:ins w:id="5" w:author="Alice" w:date="2024-03-01T10:00:00Z"><w:r><w:t xml:space="preserve"> for 2025</w:t></w:r></w:ins>` +
+			`<w:commentRangeEnd w:id="0"/><w:r><w:commentReference w:id="0"/></w:r>` +
+			`<w:del w:id="6" w:author="Bob"><w:r><w:delText>was approved</w:delText></w:r></w:del>` +
+			`<w:r><w:footnoteReference w:id="1"/></w:r></w:p>` +
+			`<w:p><w:pPr><w:rPr><w:ins w:id="7" w:author="Alice"/></w:rPr></w:pPr><w:r><w:t>is pending.</w:t></w:r></w:p>` +
+			`</w:body></w:document>`},
+		[2]string{"word/comments.xml", `<w:comments ` + namespace + `>` +
+			`<w:comment w:id="0" w:author="Carol" w:date="2024-03-02T09:00:00Z"><w:p><w:r><w:t>Which year?</w:t></w:r></w:p><w:p><w:r><w:t>Please confirm.</w:t></w:r></w:p></w:comment>` +
+			`</w:comments>`},
+		[2]string{"word/footnotes.xml", `<w:footnotes ` + namespace + `>` +
+			`<w:footnote w:type="separator" w:id="-1"><w:p><w:r><w:separator/></w:r></w:p></w:footnote>` +
+			`<w:footnote w:id="1"><w:p><w:r><w:t>Source: finance team.</w:t></w:r></w:p></w:footnote>` +
+			`</w:footnotes>`},
+	)
+
+	review, err := NewManager().ExtractReview(path)
+	if err != nil {
+		t.Fatalf("ExtractReview failed: %v", err)
+	}
+
+	expectedComments := []DocxComment{{ID: "0", Author: "Carol", Date: "2024-03-02T09:00:00Z", Text: "Which year?\nPlease confirm.", Anchor: "budget for 2025"}}
+	if !reflect.DeepEqual(review.Comments, expectedComments) {
+		t.Errorf("Comments = %+v, want %+v", review.Comments, expectedComments)
+	}
+	if expected := []DocxNote{{ID: "1", Text: "Source: finance team."}}; !reflect.DeepEqual(review.Footnotes, expected) {
+		t.Errorf("Footnotes = %+v, want %+v", review.Footnotes, expected)
+	}
+	if len(review.Endnotes) != 0 {
+		t.Errorf("Expected no endnotes, got %+v", review.Endnotes)
+	}
+	expectedRevisions := []DocxRevision{
+		{Type: "insertion", Author: "Alice", Date: "2024-03-01T10:00:00Z", Text: "for 2025"},
+		{Type: "deletion", Author: "Bob", Text: "was approved"},
+	}
+	if !reflect.DeepEqual(review.Revisions, expectedRevisions) {
+		t.Errorf("Revisions = %+v, want %+v", review.Revisions, expectedRevisions)
+	}
+}
+
+func TestExtractReviewUnsupportedFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.pdf")
+	writeTestPDF(t, path, "Text")
+	if _, err := NewManager().ExtractReview(path); err == nil || !strings.Contains(err.Error(), "supports .docx files only") {
+		t.Errorf("Expected an unsupported format error, got %v", err)
+	}
+}
@@ -0,0 +1,202 @@
+package document
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// rtfSkippedDestinations are groups that hold formatting tables, metadata or
+// embedded objects rather than document text
+var rtfSkippedDestinations = map[string]bool{
+	"fonttbl": true, "colortbl": true, "stylesheet": true, "info": true, "pict": true,
+	"object": true, "fldinst": true, "listtable": true, "listoverridetable": true,
+	"revtbl": true, "rsidtbl": true, "generator": true, "xmlnstbl": true,
+	"header": true, "headerl": true, "headerr": true, "headerf": true,
+	"footer": true, "footerl": true, "footerr": true, "footerf": true,
+	"themedata": true, "colorschememapping": true, "datastore": true, "latentstyles": true,
+}
+
+// rtfCodePages maps \ansicpg values to single-byte decoders; other code pages
+// fall back to Windows-1252
+var rtfCodePages = map[int]*charmap.Charmap{
+	437:   charmap.CodePage437,
+	850:   charmap.CodePage850,
+	1250:  charmap.Windows1250,
+	1251:  charmap.Windows1251,
+	1252:  charmap.Windows1252,
+	1253:  charmap.Windows1253,
+	1254:  charmap.Windows1254,
+	1255:  charmap.Windows1255,
+	1256:  charmap.Windows1256,
+	1257:  charmap.Windows1257,
+	1258:  charmap.Windows1258,
+	10000: charmap.Macintosh,
+}
+
+// rtfSymbols maps control words that stand for a single character
+var rtfSymbols = map[string]string{
+	"par": "\n", "line": "\n", "sect": "\n", "page": "\n", "row": "\n",
+	"tab": "\t", "cell": "\t",
+	"emdash": "—", "endash": "–", "bullet": "•",
+	"lquote": "‘", "rquote": "’", "ldblquote": "“", "rdblquote": "”",
+	"emspace": " ", "enspace": " ", "qmspace": " ",
+}
+
+// rtfState is the formatting state that RTF groups save and restore
+type rtfState struct {
+	skip        bool // Inside a destination that is not document text
+	unicodeSkip int  // Fallback characters following each \uN (\ucN)
+}
+
+func (m *Manager) extractRTFText(_ context.Context, filePath string) (string, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open RTF file: %w", err)
+	}
+
+	return strings.TrimSpace(m.cleanExtractedText(rtfText(content))), nil
+}
+
+// rtfText interprets an RTF document and returns its visible text
+func rtfText(content []byte) string {
+	var text strings.Builder
+	state := rtfState{unicodeSkip: 1}
+	var stack []rtfState
+	codePage := charmap.Windows1252
+	pendingSkip := 0 // Fallback characters still to drop after a \uN
+	var highSurrogate rune
+
+	emitByte := func(b byte) {
+		if pendingSkip > 0 {
+			pendingSkip--
+			return
+		}
+		if !state.skip {
+			text.WriteRune(codePage.DecodeByte(b))
+		}
+	}
+
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch c {
+		case '{':
+			stack = append(stack, state)
+			pendingSkip = 0
+		case '}':
+			if len(stack) > 0 {
+				state = stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+			}
+			pendingSkip = 0
+		case '\r', '\n':
+			// Line breaks in the file are not significant
+		case '\\':
+			if i+1 >= len(content) {
+				continue
+			}
+			next := content[i+1]
+			if !isASCIILetter(next) {
+				i++
+				switch next {
+				case '\'':
+					if i+2 < len(content) {
+						if value, err := strconv.ParseUint(string(content[i+1:i+3]), 16, 8); err == nil {
+							emitByte(byte(value))
+						}
+						i += 2
+					}
+				case '*':
+					state.skip = true // Ignorable destination this reader doesn't know
+				case '~':
+					emitByte(' ')
+				case '_':
+					emitByte('-')
+				case '\r', '\n':
+					if !state.skip {
+						text.WriteString("\n")
+					}
+				case '-':
+					// Optional hyphen
+				default:
+					emitByte(next) // Escaped \, { and }
+				}
+				continue
+			}
+
+			// Control word: letters, an optional signed number and an optional space delimiter
+			start := i + 1
+			end := start
+			for end < len(content) && isASCIILetter(content[end]) {
+				end++
+			}
+			word := string(content[start:end])
+			paramStart := end
+			if end < len(content) && content[end] == '-' {
+				end++
+			}
+			for end < len(content) && content[end] >= '0' && content[end] <= '9' {
+				end++
+			}
+			param, hasParam := 0, false
+			if end > paramStart {
+				if value, err := strconv.Atoi(string(content[paramStart:end])); err == nil {
+					param, hasParam = value, true
+				}
+			}
+			if end < len(content) && content[end] == ' ' {
+				end++
+			}
+			i = end - 1
+
+			switch {
+			case rtfSkippedDestinations[word]:
+				state.skip = true
+			case word == "ansicpg" && hasParam:
+				if decoder, ok := rtfCodePages[param]; ok {
+					codePage = decoder
+				}
+			case word == "uc" && hasParam && param >= 0:
+				state.unicodeSkip = param
+			case word == "u" && hasParam:
+				if param < 0 {
+					param += 65536
+				}
+				r := rune(param)
+				switch {
+				case utf16.IsSurrogate(r) && r < 0xDC00:
+					highSurrogate = r // Characters outside the BMP arrive as two \u words
+				case utf16.IsSurrogate(r):
+					r = utf16.DecodeRune(highSurrogate, r)
+					highSurrogate = 0
+					fallthrough
+				default:
+					if !state.skip {
+						text.WriteRune(r)
+					}
+				}
+				pendingSkip = state.unicodeSkip
+			case word == "bin" && hasParam && param > 0:
+				i += min(param, len(content)-i) // Raw binary data
+			default:
+				if symbol, ok := rtfSymbols[word]; ok && !state.skip {
+					text.WriteString(symbol)
+				}
+			}
+		default:
+			emitByte(c)
+		}
+	}
+
+	return text.String()
+}
+
+// isASCIILetter reports whether b starts or continues an RTF control word
+func isASCIILetter(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
@@ -0,0 +1,149 @@
+package document
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Search defaults
+const (
+	defaultSearchContext    = 80 // Characters of context on each side of a match
+	defaultSearchMaxResults = 50
+)
+
+// SearchOptions controls a search within a document. Zero values for Context
+// and MaxResults select the defaults.
+type SearchOptions struct {
+	Query         string
+	Regex         bool // Query is a regular expression rather than literal text
+	CaseSensitive bool
+	Context       int
+	MaxResults    int
+}
+
+// SearchMatch is one occurrence of the query. Offset is the character offset
+// of the match in the text of its page, or of the whole document for formats
+// without pages.
+type SearchMatch struct {
+	Page    int    `json:"page,omitempty"`
+	Line    int    `json:"line"`
+	Offset  int    `json:"offset"`
+	Match   string `json:"match"`
+	Context string `json:"context"`
+}
+
+// SearchResult holds the matches of a search; TotalMatches counts every
+// match even when only the first MaxResults are returned
+type SearchResult struct {
+	FilePath     string        `json:"file_path"`
+	Query        string        `json:"query"`
+	Unit         string        `json:"unit,omitempty"` // "page" or "slide" when matches have page numbers
+	TotalMatches int           `json:"total_matches"`
+	Matches      []SearchMatch `json:"matches"`
+}
+
+// SearchDocument finds the query in the extracted text of a document. PDF
+// pages and PPTX slides are searched one by one so that matches carry their
+// page or slide number.
+func (m *Manager) SearchDocument(ctx context.Context, filePath string, options SearchOptions) (*SearchResult, error) {
+	pattern, err := options.compile()
+	if err != nil {
+		return nil, err
+	}
+	if options.Context <= 0 {
+		options.Context = defaultSearchContext
+	}
+	if options.MaxResults <= 0 {
+		options.MaxResults = defaultSearchMaxResults
+	}
+
+	var pages []PageText
+	result := &SearchResult{FilePath: filePath, Query: options.Query}
+	switch m.detectFileType(filePath) {
+	case DocumentTypePDF, DocumentTypePPTX:
+		extraction, err := m.ExtractPages(ctx, filePath, "")
+		if err != nil {
+			return nil, err
+		}
+		result.Unit, pages = extraction.Unit, extraction.Pages
+	default:
+		text, err := m.ExtractText(ctx, filePath)
+		if err != nil {
+			return nil, err
+		}
+		pages = []PageText{{Text: text}}
+	}
+
+	for _, page := range pages {
+		for _, match := range searchText(page.Text, pattern, options.Context) {
+			result.TotalMatches++
+			if len(result.Matches) < options.MaxResults {
+				match.Page = page.Number
+				result.Matches = append(result.Matches, match)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// compile turns the query into a regular expression
+func (o SearchOptions) compile() (*regexp.Regexp, error) {
+	if o.Query == "" {
+		return nil, fmt.Errorf("search query must not be empty")
+	}
+
+	expression := o.Query
+	if !o.Regex {
+		expression = regexp.QuoteMeta(expression)
+	}
+	if !o.CaseSensitive {
+		expression = "(?i)" + expression
+	}
+
+	pattern, err := regexp.Compile(expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression: %w", err)
+	}
+	return pattern, nil
+}
+
+// searchText returns the non-empty matches of pattern in text with up to
+// context characters on each side, line breaks in the context folded into spaces
+func searchText(text string, pattern *regexp.Regexp, context int) []SearchMatch {
+	var matches []SearchMatch
+
+	line, offset, scanned := 1, 0, 0 // Line and character offset at byte position scanned
+	for _, location := range pattern.FindAllStringIndex(text, -1) {
+		start, end := location[0], location[1]
+		if start == end {
+			continue
+		}
+		line += strings.Count(text[scanned:start], "\n")
+		offset += len([]rune(text[scanned:start]))
+		scanned = start
+
+		// Decode only a window around the match that is sure to hold the context
+		window := (context + 1) * utf8.UTFMax
+		before := []rune(text[max(start-window, 0):start])
+		after := []rune(text[end:min(end+window, len(text))])
+		prefix, suffix := "", ""
+		if len(before) > context {
+			before, prefix = before[len(before)-context:], "..."
+		}
+		if len(after) > context {
+			after, suffix = after[:context], "..."
+		}
+
+		matches = append(matches, SearchMatch{
+			Line:    line,
+			Offset:  offset,
+			Match:   text[start:end],
+			Context: prefix + strings.Join(strings.Fields(string(before)+text[start:end]+string(after)), " ") + suffix,
+		})
+	}
+	return matches
+}
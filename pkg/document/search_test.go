@@ -0,0 +1,89 @@
+package document
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestSearchText(t *testing.T) {
+	text := "Revenue grew.\nCosts fell and revenue\nrose again."
+	matches := searchText(text, regexp.MustCompile(`(?i)revenue`), 10)
+
+	expected := []SearchMatch{
+		{Line: 1, Offset: 0, Match: "Revenue", Context: "Revenue grew. Cos..."},
+		{Line: 2, Offset: 29, Match: "revenue", Context: "...fell and revenue rose agai..."},
+	}
+	if !reflect.DeepEqual(matches, expected) {
+		t.Errorf("searchText() = %+v, want %+v", matches, expected)
+	}
+
+	// Empty matches are skipped
+	if matches := searchText(text, regexp.MustCompile(`x*`), 10); len(matches) != 0 {
+		t.Errorf("Expected no matches for an empty pattern, got %+v", matches)
+	}
+}
+
+func TestSearchTextMultibyteContext(t *testing.T) {
+	text := strings.Repeat("é", 20) + "Zürich" + strings.Repeat("ü", 20)
+	matches := searchText(text, regexp.MustCompile("Zürich"), 3)
+	if len(matches) != 1 || matches[0].Offset != 20 || matches[0].Context != "...éééZürichüüü..." {
+		t.Errorf("Unexpected matches: %+v", matches)
+	}
+}
+
+func TestSearchPDFDocument(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.pdf")
+	writeTestPDF(t, path, "Budget overview", "Travel budget", "Summary")
+
+	result, err := NewManager().SearchDocument(context.Background(), path, SearchOptions{Query: "budget"})
+	if err != nil {
+		t.Fatalf("SearchDocument failed: %v", err)
+	}
+	if result.Unit != "page" || result.TotalMatches != 2 || len(result.Matches) != 2 {
+		t.Fatalf("Unexpected result: %+v", result)
+	}
+	if result.Matches[0].Page != 1 || result.Matches[1].Page != 2 || result.Matches[1].Match != "budget" {
+		t.Errorf("Unexpected matches: %+v", result.Matches)
+	}
+
+	// A result limit still counts every match
+	result, err = NewManager().SearchDocument(context.Background(), path, SearchOptions{Query: `\b[Bb]udget\b`, Regex: true, MaxResults: 1})
+	if err != nil {
+		t.Fatalf("SearchDocument failed: %v", err)
+	}
+	if result.TotalMatches != 2 || len(result.Matches) != 1 || result.Matches[0].Match != "Budget" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	result, err = NewManager().SearchDocument(context.Background(), path, SearchOptions{Query: "budget", CaseSensitive: true})
+	if err != nil || result.TotalMatches != 1 || result.Matches[0].Page != 2 {
+		t.Errorf("Unexpected case-sensitive result: %+v, %v", result, err)
+	}
+}
+
+func TestSearchTextDocument(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.md")
+	if err := os.WriteFile(path, []byte("# Notes\n\nCall Alice.\nEmail Bob.\nCall Carol.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := NewManager().SearchDocument(context.Background(), path, SearchOptions{Query: "call", MaxResults: 1})
+	if err != nil {
+		t.Fatalf("SearchDocument failed: %v", err)
+	}
+	if result.Unit != "" || result.TotalMatches != 2 || len(result.Matches) != 1 || result.Matches[0].Page != 0 || result.Matches[0].Line != 3 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+
+	if _, err := NewManager().SearchDocument(context.Background(), path, SearchOptions{Query: "(", Regex: true}); err == nil || !strings.Contains(err.Error(), "invalid regular expression") {
+		t.Errorf("Expected an invalid regular expression error, got %v", err)
+	}
+	if _, err := NewManager().SearchDocument(context.Background(), path, SearchOptions{}); err == nil {
+		t.Error("Expected an error for an empty query")
+	}
+}
@@ -0,0 +1,260 @@
+package document
+
+import (
+	"archive/zip"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// PDF permission bits of the standard security handler (PDF 32000-1:2008,
+// table 22) with the restriction reported when a bit is cleared
+var pdfPermissions = []struct {
+	bit         int64
+	restriction string
+}{
+	{1 << 2, "printing"},
+	{1 << 3, "modifying"},
+	{1 << 4, "copying"},
+	{1 << 5, "annotating"},
+	{1 << 8, "filling forms"},
+	{1 << 10, "assembling"},
+}
+
+// Signature is a digital signature found in a document. Signatures are
+// reported as recorded; their certificates and digests are not validated.
+type Signature struct {
+	Signer   string    `json:"signer"` // Name recorded with the signature, else the certificate's common name
+	SignedAt time.Time `json:"signed_at,omitempty"`
+	Reason   string    `json:"reason,omitempty"`
+	Issuer   string    `json:"issuer,omitempty"` // Common name of the certificate issuer
+}
+
+// DocumentSecurity is the signature and restriction status of a document
+type DocumentSecurity struct {
+	Signatures   []Signature
+	Restrictions []string // What the document forbids, e.g. "copying" or "editing (read only)"
+}
+
+// documentSecurity reads the signatures and restrictions of a PDF or OOXML
+// document; like the properties it is best effort
+func (m *Manager) documentSecurity(filePath, password string) DocumentSecurity {
+	switch m.detectFileType(filePath) {
+	case DocumentTypePDF:
+		return pdfSecurity(filePath, password)
+	case DocumentTypeDOCX, DocumentTypePPTX:
+		return ooxmlSecurity(filePath)
+	}
+	return DocumentSecurity{}
+}
+
+// pdfSecurity reads the signature fields of a PDF's form and the permissions
+// of its encryption dictionary
+func pdfSecurity(filePath, password string) DocumentSecurity {
+	var security DocumentSecurity
+	err := withPDF(filePath, password, func(reader *pdf.Reader) error {
+		encrypt := reader.Trailer().Key("Encrypt")
+		if permissions := encrypt.Key("P"); permissions.Kind() == pdf.Integer {
+			for _, permission := range pdfPermissions {
+				if permissions.Int64()&permission.bit == 0 {
+					security.Restrictions = append(security.Restrictions, permission.restriction)
+				}
+			}
+		}
+
+		var walk func(field pdf.Value, fieldType string, depth int)
+		walk = func(field pdf.Value, fieldType string, depth int) {
+			if field.Kind() != pdf.Dict || depth > 32 || len(security.Signatures) >= maxFormFields {
+				return
+			}
+			if ft := field.Key("FT").Name(); ft != "" {
+				fieldType = ft
+			}
+			if value := field.Key("V"); fieldType == "Sig" && value.Kind() == pdf.Dict {
+				security.Signatures = append(security.Signatures, pdfSignature(value))
+			}
+			kids := field.Key("Kids")
+			for i := 0; i < kids.Len(); i++ {
+				walk(kids.Index(i), fieldType, depth+1)
+			}
+		}
+		fields := reader.Trailer().Key("Root").Key("AcroForm").Key("Fields")
+		for i := 0; i < fields.Len(); i++ {
+			walk(fields.Index(i), "", 0)
+		}
+		return nil
+	})
+	if err != nil {
+		return DocumentSecurity{}
+	}
+	return security
+}
+
+// pdfSignature reads a signature dictionary, naming the signer from its
+// certificate when the dictionary doesn't
+func pdfSignature(value pdf.Value) Signature {
+	signature := Signature{
+		Signer:   strings.TrimSpace(value.Key("Name").Text()),
+		SignedAt: parsePDFDate(value.Key("M").Text()),
+		Reason:   strings.TrimSpace(value.Key("Reason").Text()),
+	}
+	if certificate := pkcs7SignerCertificate([]byte(value.Key("Contents").RawString())); certificate != nil {
+		if signature.Signer == "" {
+			signature.Signer = certificateName(certificate.Subject.CommonName, certificate.Subject.Organization)
+		}
+		signature.Issuer = certificateName(certificate.Issuer.CommonName, certificate.Issuer.Organization)
+	}
+	return signature
+}
+
+// pkcs7SignerCertificate returns the signer's certificate from a detached
+// PKCS #7 signature: the first certificate that isn't a CA. PDF signatures
+// are padded with zeros after the DER data, which is ignored.
+func pkcs7SignerCertificate(der []byte) *x509.Certificate {
+	var contentInfo struct {
+		ContentType asn1.ObjectIdentifier
+		Content     asn1.RawValue `asn1:"explicit,tag:0"`
+	}
+	if _, err := asn1.Unmarshal(der, &contentInfo); err != nil {
+		return nil
+	}
+	var signedData struct {
+		Version          int
+		DigestAlgorithms asn1.RawValue
+		ContentInfo      asn1.RawValue
+		Certificates     asn1.RawValue `asn1:"optional,tag:0"`
+	}
+	if _, err := asn1.Unmarshal(contentInfo.Content.Bytes, &signedData); err != nil {
+		return nil
+	}
+	certificates, err := x509.ParseCertificates(signedData.Certificates.Bytes)
+	if err != nil || len(certificates) == 0 {
+		return nil
+	}
+	for _, certificate := range certificates {
+		if !certificate.IsCA {
+			return certificate
+		}
+	}
+	return certificates[0]
+}
+
+// certificateName names a certificate subject or issuer by its common name,
+// else its organization
+func certificateName(commonName string, organization []string) string {
+	if commonName != "" {
+		return commonName
+	}
+	return first(organization)
+}
+
+// ooxmlSecurity reads the XML signatures of an Office package and the editing
+// restrictions of Word and PowerPoint documents
+func ooxmlSecurity(filePath string) DocumentSecurity {
+	var security DocumentSecurity
+	reader, err := zip.OpenReader(filePath)
+	if err != nil {
+		return security
+	}
+	defer reader.Close()
+
+	for _, f := range reader.File {
+		name := strings.ToLower(f.Name)
+		switch {
+		case strings.HasPrefix(name, "_xmlsignatures/") && strings.HasSuffix(name, ".xml"):
+			if texts := zipXMLTexts(f); texts["SignatureValue"] != nil {
+				security.Signatures = append(security.Signatures, xmlSignature(texts))
+			}
+		case name == "word/settings.xml":
+			texts := zipXMLTexts(f)
+			// Protection is only enforced when w:enforcement is set
+			edit, enforced := first(texts["documentProtection@edit"]), first(texts["documentProtection@enforcement"])
+			if edit != "" && edit != "none" && (enforced == "1" || enforced == "true" || enforced == "on") {
+				description, known := docxProtectionNames[edit]
+				if !known {
+					description = edit
+				}
+				security.Restrictions = append(security.Restrictions, fmt.Sprintf("editing (%s)", description))
+			}
+		case name == "ppt/presentation.xml":
+			if texts := zipXMLTexts(f); texts["modifyVerifier@spinCount"] != nil || texts["modifyVerifier@hashData"] != nil || texts["modifyVerifier@cryptSpinCount"] != nil {
+				security.Restrictions = append(security.Restrictions, "editing (password to modify)")
+			}
+		case name == "docprops/custom.xml":
+			if markedFinal(f) {
+				security.Restrictions = append(security.Restrictions, "marked as final")
+			}
+		}
+	}
+	return security
+}
+
+// docxProtectionNames describes the w:edit values of Word document protection
+var docxProtectionNames = map[string]string{
+	"readOnly":       "read only",
+	"comments":       "comments only",
+	"trackedChanges": "tracked changes only",
+	"forms":          "filling in forms only",
+}
+
+// zipXMLTexts collects the element texts and attributes of a package part
+func zipXMLTexts(f *zip.File) map[string][]string {
+	rc, err := f.Open()
+	if err != nil {
+		return nil
+	}
+	defer rc.Close()
+	return xmlElementTexts(rc)
+}
+
+// xmlSignature reads an XML-DSig signature part: the signer certificate and
+// the signing time Office records in its signature properties
+func xmlSignature(texts map[string][]string) Signature {
+	var signature Signature
+	signature.SignedAt = parseXMLDate(first(texts["Value"])) // mdssi:SignatureTime > mdssi:Value
+	signature.Reason = first(texts["SignatureComments"])
+
+	for _, encoded := range texts["X509Certificate"] {
+		der, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(encoded), ""))
+		if err != nil {
+			continue
+		}
+		if certificate, err := x509.ParseCertificate(der); err == nil && !certificate.IsCA {
+			signature.Signer = certificateName(certificate.Subject.CommonName, certificate.Subject.Organization)
+			signature.Issuer = certificateName(certificate.Issuer.CommonName, certificate.Issuer.Organization)
+			break
+		}
+	}
+	return signature
+}
+
+// markedFinal reports whether Office's "Mark as Final" custom property is set
+func markedFinal(f *zip.File) bool {
+	rc, err := f.Open()
+	if err != nil {
+		return false
+	}
+	defer rc.Close()
+
+	var custom struct {
+		Properties []struct {
+			Name string `xml:"name,attr"`
+			Bool string `xml:"bool"`
+		} `xml:"property"`
+	}
+	if err := xml.NewDecoder(rc).Decode(&custom); err != nil {
+		return false
+	}
+	for _, property := range custom.Properties {
+		if property.Name == "_MarkAsFinal" && property.Bool == "true" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package document
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testCertificate creates a DER certificate for subject issued by issuer
+func testCertificate(t *testing.T, subject, issuer string) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: subject},
+		Issuer:       pkix.Name{CommonName: issuer},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	parent := &x509.Certificate{SerialNumber: big.NewInt(2), Subject: pkix.Name{CommonName: issuer}}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return der
+}
+
+// testPKCS7 wraps a certificate in a PKCS #7 SignedData structure without
+// signer infos, which is all the signer lookup reads
+func testPKCS7(t *testing.T, certificate []byte) []byte {
+	t.Helper()
+	signedData, err := asn1.Marshal(struct {
+		Version          int
+		DigestAlgorithms asn1.RawValue
+		ContentInfo      struct{ ContentType asn1.ObjectIdentifier }
+		Certificates     asn1.RawValue
+		SignerInfos      asn1.RawValue
+	}{
+		Version:          1,
+		DigestAlgorithms: asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true},
+		ContentInfo:      struct{ ContentType asn1.ObjectIdentifier }{asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}},
+		Certificates:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: certificate},
+		SignerInfos:      asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	contentInfo, err := asn1.Marshal(struct {
+		ContentType asn1.ObjectIdentifier
+		Content     asn1.RawValue
+	}{
+		ContentType: asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2},
+		Content:     asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: signedData},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return contentInfo
+}
+
+func TestSignedPDFInfo(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "contract.pdf")
+	signature := testPKCS7(t, testCertificate(t, "Jane Doe", "Example CA"))
+	// Signatures are padded with zeros to the space reserved for them
+	contents := fmt.Sprintf("%x%0512x", signature, 0)
+	writeTestPDFObjects(t, path, testPDF{
+		Catalog: "/AcroForm << /Fields [6 0 R] /SigFlags 3 >>",
+		Extra: []string{
+			"<< /FT /Sig /T (Signature1) /V 7 0 R /Type /Annot /Subtype /Widget /Rect [0 0 0 0] >>",
+			"<< /Type /Sig /Filter /Adobe.PPKLite /SubFilter /adbe.pkcs7.detached /M (D:20260301120000Z) /Reason (Approved) /Contents <" + contents + "> >>",
+		},
+	}, "BT /F1 12 Tf 72 720 Td (Contract) Tj ET")
+
+	info, err := NewManager().GetDocumentInfo(context.Background(), path)
+	if err != nil {
+		t.Fatalf("GetDocumentInfo failed: %v", err)
+	}
+	expected := []Signature{{
+		Signer:   "Jane Doe",
+		SignedAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		Reason:   "Approved",
+		Issuer:   "Example CA",
+	}}
+	if len(info.Signatures) != 1 || !info.Signatures[0].SignedAt.Equal(expected[0].SignedAt) {
+		t.Fatalf("Signatures = %+v, want %+v", info.Signatures, expected)
+	}
+	info.Signatures[0].SignedAt = expected[0].SignedAt
+	if !reflect.DeepEqual(info.Signatures, expected) || info.Restrictions != nil {
+		t.Errorf("Unexpected security status: %+v", info.DocumentSecurity)
+	}
+}
+
+func TestRestrictedPDFInfo(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.pdf")
+	// Only an owner password: anyone can open it, copying and printing are denied
+	writeTestEncryptedPDF(t, path, "", 0xFFFFFFFC&^(1<<2|1<<4), "Report")
+
+	info, err := NewManager().GetDocumentInfo(context.Background(), path)
+	if err != nil {
+		t.Fatalf("GetDocumentInfo failed: %v", err)
+	}
+	if !info.Encrypted || !reflect.DeepEqual(info.Restrictions, []string{"printing", "copying"}) || info.Signatures != nil {
+		t.Errorf("Unexpected security status: encrypted %v, %+v", info.Encrypted, info.DocumentSecurity)
+	}
+}
+
+func TestSignedDocxInfo(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.docx")
+	certificate := base64.StdEncoding.EncodeToString(testCertificate(t, "John Roe", "Example CA"))
+	writeTestZip(t, path,
+		[2]string{"word/document.xml", `<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body><w:p><w:r><w:t>Policy</w:t></w:r></w:p></w:body></w:document>`},
+		[2]string{"word/_rels/document.xml.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"></Relationships>`},
+		[2]string{"word/settings.xml", `<w:settings xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:documentProtection w:edit="readOnly" w:enforcement="1"/></w:settings>`},
+		[2]string{"docProps/custom.xml", `<Properties xmlns="http://schemas.openxmlformats.org/officeDocument/2006/custom-properties" xmlns:vt="http://schemas.openxmlformats.org/officeDocument/2006/docPropsVTypes">` +
+			`<property fmtid="{D5CDD505-2E9C-101B-9397-08002B2CF9AE}" pid="2" name="_MarkAsFinal"><vt:bool>true</vt:bool></property></Properties>`},
+		[2]string{"_xmlsignatures/origin.sigs", ""},
+		[2]string{"_xmlsignatures/sig1.xml", `<Signature xmlns="http://www.w3.org/2000/09/xmldsig#"><SignedInfo/><SignatureValue>c2lnbmF0dXJl</SignatureValue>` +
+			`<KeyInfo><X509Data><X509Certificate>` + certificate + `</X509Certificate></X509Data></KeyInfo>` +
+			`<Object><SignatureProperties><SignatureProperty><mdssi:SignatureTime xmlns:mdssi="http://schemas.openxmlformats.org/package/2006/digital-signature">` +
+			`<mdssi:Format>YYYY-MM-DDThh:mm:ssTZD</mdssi:Format><mdssi:Value>2026-03-02T09:30:00Z</mdssi:Value></mdssi:SignatureTime></SignatureProperty></SignatureProperties>` +
+			`<SignatureInfoV1 xmlns="http://schemas.microsoft.com/office/2006/digsig"><SignatureComments>Final review</SignatureComments></SignatureInfoV1></Object></Signature>`},
+	)
+
+	info, err := NewManager().GetDocumentInfo(context.Background(), path)
+	if err != nil {
+		t.Fatalf("GetDocumentInfo failed: %v", err)
+	}
+	expected := DocumentSecurity{
+		Signatures:   []Signature{{Signer: "John Roe", SignedAt: time.Date(2026, 3, 2, 9, 30, 0, 0, time.UTC), Reason: "Final review", Issuer: "Example CA"}},
+		Restrictions: []string{"editing (read only)", "marked as final"},
+	}
+	if !reflect.DeepEqual(info.DocumentSecurity, expected) {
+		t.Errorf("DocumentSecurity = %+v, want %+v", info.DocumentSecurity, expected)
+	}
+}
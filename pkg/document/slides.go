@@ -0,0 +1,122 @@
+package document
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// SlideText is the content of one PPTX slide. Number is the slide's position
+// in the presentation, counting hidden slides.
+type SlideText struct {
+	Number int    `json:"number"`
+	Title  string `json:"title,omitempty"`
+	Text   string `json:"text"`
+	Notes  string `json:"notes,omitempty"`
+	Hidden bool   `json:"hidden,omitempty"`
+}
+
+// SlideExtraction is the result of extracting slides with their speaker notes
+type SlideExtraction struct {
+	FilePath      string      `json:"file_path"`
+	TotalSlides   int         `json:"total_slides"`
+	SkippedHidden int         `json:"skipped_hidden,omitempty"` // Hidden slides left out of the selection
+	Slides        []SlideText `json:"slides"`
+}
+
+// ExtractSlides returns the body text and speaker notes of the selected slides
+// of a presentation (see ExtractPages for the selection syntax). Slides hidden
+// in the slide show are skipped unless includeHidden is set.
+func (m *Manager) ExtractSlides(filePath, selection string, includeHidden bool) (*SlideExtraction, error) {
+	if m.detectFileType(filePath) != DocumentTypePPTX {
+		return nil, fmt.Errorf("slide extraction supports .pptx files only: %s", filePath)
+	}
+	if err := m.checkFileSize(filePath); err != nil {
+		return nil, err
+	}
+
+	reader, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PPTX file: %w", err)
+	}
+	defer reader.Close()
+
+	files := make(map[string]*zip.File, len(reader.File))
+	for _, f := range reader.File {
+		files[f.Name] = f
+	}
+
+	slides := pptxSlideOrder(files)
+	numbers, err := parsePageSelection(selection, len(slides))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SlideExtraction{FilePath: filePath, TotalSlides: len(slides)}
+	for _, number := range numbers {
+		part := slides[number-1]
+		content, err := readZipFile(files[part])
+		if err != nil {
+			return nil, fmt.Errorf("failed to read slide %d: %w", number, err)
+		}
+
+		hidden := pptxSlideHidden(content)
+		if hidden && !includeHidden {
+			result.SkippedHidden++
+			continue
+		}
+
+		text, err := m.extractCleanTextFromXML(content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse slide %d: %w", number, err)
+		}
+		notes, err := pptxSlideNotes(files, part)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read notes of slide %d: %w", number, err)
+		}
+
+		result.Slides = append(result.Slides, SlideText{
+			Number: number,
+			Title:  pptxSlideTitle(content),
+			Text:   text,
+			Notes:  notes,
+			Hidden: hidden,
+		})
+	}
+
+	return result, nil
+}
+
+// pptxSlideHidden reports whether a slide is hidden in the slide show
+// (<p:sld show="0">)
+func pptxSlideHidden(content string) bool {
+	decoder := xml.NewDecoder(strings.NewReader(content))
+	decoder.Strict = false
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return false
+		}
+		if element, ok := token.(xml.StartElement); ok {
+			show := xmlAttr(element, "show")
+			return show == "0" || show == "false"
+		}
+	}
+}
+
+// pptxSlideNotes returns the speaker notes of a slide: the body placeholder of
+// the notes slide it links to, one line per paragraph
+func pptxSlideNotes(files map[string]*zip.File, slide string) (string, error) {
+	for _, rel := range partRelationships(files, slide) {
+		if !strings.HasSuffix(rel.Type, "/notesSlide") || files[rel.Target] == nil {
+			continue
+		}
+		content, err := readZipFile(files[rel.Target])
+		if err != nil {
+			return "", err
+		}
+		return strings.Join(pptxPlaceholderText(content, "body"), "\n"), nil
+	}
+	return "", nil
+}
@@ -0,0 +1,67 @@
+package document
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtractSlides(t *testing.T) {
+	shape := func(kind, text string) string {
+		return `<p:sp><p:nvSpPr><p:nvPr><p:ph type="` + kind + `"/></p:nvPr></p:nvSpPr><p:txBody><a:p><a:r><a:t>` + text + `</a:t></a:r></a:p></p:txBody></p:sp>`
+	}
+	notes := func(paragraphs ...string) string {
+		body := `<p:sp><p:nvSpPr><p:nvPr><p:ph type="body" idx="1"/></p:nvPr></p:nvSpPr><p:txBody>`
+		for _, paragraph := range paragraphs {
+			body += `<a:p><a:r><a:t>` + paragraph + `</a:t></a:r></a:p>`
+		}
+		body += `</p:txBody></p:sp>`
+		return `<p:notes xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main" xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main"><p:cSld><p:spTree>` +
+			shape("sldNum", "1") + body + `</p:spTree></p:cSld></p:notes>`
+	}
+	notesRel := func(target string) string {
+		return `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/notesSlide" Target="` + target + `"/></Relationships>`
+	}
+
+	path := filepath.Join(t.TempDir(), "deck.pptx")
+	writeTestPptxShapes(t, path, []int{1, 2, 3}, [][2]string{
+		{"ppt/slides/_rels/slide1.xml.rels", notesRel("../notesSlides/notesSlide1.xml")},
+		{"ppt/notesSlides/notesSlide1.xml", notes("Welcome everyone.", "Mention the budget.")},
+		{"ppt/slides/_rels/slide2.xml.rels", notesRel("../notesSlides/notesSlide2.xml")},
+		{"ppt/notesSlides/notesSlide2.xml", notes("Backup material")},
+	},
+		shape("title", "Agenda")+shape("body", "Budget"),
+		`<p:sld xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main" xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" show="0"><p:cSld><p:spTree>`+shape("title", "Appendix")+`</p:spTree></p:cSld></p:sld>`,
+		shape("title", "Questions"),
+	)
+
+	extraction, err := NewManager().ExtractSlides(path, "", false)
+	if err != nil {
+		t.Fatalf("ExtractSlides failed: %v", err)
+	}
+	expected := []SlideText{
+		{Number: 1, Title: "Agenda", Text: "Agenda Budget", Notes: "Welcome everyone.\nMention the budget."},
+		{Number: 3, Title: "Questions", Text: "Questions"},
+	}
+	if extraction.TotalSlides != 3 || extraction.SkippedHidden != 1 || !reflect.DeepEqual(extraction.Slides, expected) {
+		t.Errorf("Unexpected extraction: %+v", extraction)
+	}
+
+	extraction, err = NewManager().ExtractSlides(path, "2", true)
+	if err != nil {
+		t.Fatalf("ExtractSlides failed: %v", err)
+	}
+	expected = []SlideText{{Number: 2, Title: "Appendix", Text: "Appendix", Notes: "Backup material", Hidden: true}}
+	if extraction.SkippedHidden != 0 || !reflect.DeepEqual(extraction.Slides, expected) {
+		t.Errorf("Unexpected extraction: %+v", extraction)
+	}
+}
+
+func TestExtractSlidesUnsupportedFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.pdf")
+	writeTestPDF(t, path, "Text")
+	if _, err := NewManager().ExtractSlides(path, "", false); err == nil || !strings.Contains(err.Error(), "supports .pptx files only") {
+		t.Errorf("Expected an unsupported format error, got %v", err)
+	}
+}
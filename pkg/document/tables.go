@@ -0,0 +1,383 @@
+package document
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// PDF table detection thresholds, as multiples of the font size
+const (
+	pdfLineTolerance = 0.5 // Glyphs closer than this vertically share a line
+	pdfWordGap       = 0.2 // A wider horizontal gap separates words
+	pdfColumnGap     = 1.5 // A wider horizontal gap separates cells
+)
+
+// Table is a table found in a document. Rows may have fewer cells than the
+// widest row when trailing cells are empty.
+type Table struct {
+	Index   int        `json:"index"`          // 1-based position in the document
+	Page    int        `json:"page,omitempty"` // PDF page the table is on
+	Columns int        `json:"columns"`
+	Rows    [][]string `json:"rows"`
+}
+
+// CSV renders the table as comma-separated values
+func (t Table) CSV() string {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	for _, row := range t.Rows {
+		padded := make([]string, t.Columns)
+		copy(padded, row)
+		writer.Write(padded)
+	}
+	writer.Flush()
+	return buf.String()
+}
+
+// ExtractTables returns the tables of a DOCX document, or the tables detected
+// from text layout on the selected pages of a PDF (see ExtractPages for the
+// selection syntax)
+func (m *Manager) ExtractTables(filePath, selection string) ([]Table, error) {
+	if err := m.checkFileSize(filePath); err != nil {
+		return nil, err
+	}
+
+	var tables []Table
+	var err error
+
+	switch m.detectFileType(filePath) {
+	case DocumentTypeDOCX:
+		if strings.TrimSpace(selection) != "" {
+			return nil, fmt.Errorf("page selection applies to PDF files only")
+		}
+		tables, err = docxTables(filePath)
+	case DocumentTypePDF:
+		tables, err = pdfTables(filePath, selection)
+	default:
+		return nil, fmt.Errorf("table extraction supports .docx and .pdf files only: %s", filePath)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range tables {
+		tables[i].Index = i + 1
+		for _, row := range tables[i].Rows {
+			tables[i].Columns = max(tables[i].Columns, len(row))
+		}
+	}
+	return tables, nil
+}
+
+// docxTables reads the w:tbl elements of word/document.xml in document order.
+// Merged cells keep the grid: a horizontally merged cell is followed by empty
+// cells for the columns it spans, and vertically merged continuation cells are
+// empty. Nested tables are returned as tables of their own.
+func docxTables(filePath string) ([]Table, error) {
+	reader, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open DOCX file: %w", err)
+	}
+	defer reader.Close()
+
+	var document *zip.File
+	for _, f := range reader.File {
+		if f.Name == "word/document.xml" {
+			document = f
+		}
+	}
+	if document == nil {
+		return nil, fmt.Errorf("failed to read DOCX file: word/document.xml not found")
+	}
+	rc, err := document.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DOCX file: %w", err)
+	}
+	defer rc.Close()
+
+	type openTable struct {
+		slot   int // Index in tables, reserved when the table opens
+		rows   [][]string
+		cell   strings.Builder
+		span   int
+		inCell bool
+	}
+	var tables []Table
+	var stack []*openTable
+	inText := false
+
+	decoder := xml.NewDecoder(rc)
+	decoder.Strict = false
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		var top *openTable
+		if len(stack) > 0 {
+			top = stack[len(stack)-1]
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "tbl":
+				// Reserve the slot now so outer tables come before the tables nested in them
+				stack = append(stack, &openTable{slot: len(tables)})
+				tables = append(tables, Table{})
+			case "tr":
+				if top != nil {
+					top.rows = append(top.rows, nil)
+				}
+			case "tc":
+				if top != nil {
+					top.cell.Reset()
+					top.span, top.inCell = 1, true
+				}
+			case "gridSpan":
+				if top != nil {
+					top.span = max(1, atoiOrZero(xmlAttr(t, "val")))
+				}
+			case "t":
+				inText = true
+			case "tab":
+				if top != nil && top.inCell {
+					top.cell.WriteString(" ")
+				}
+			case "br", "cr":
+				if top != nil && top.inCell {
+					top.cell.WriteString("\n")
+				}
+			}
+		case xml.CharData:
+			if inText && top != nil && top.inCell {
+				top.cell.Write(t)
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				if top != nil && top.inCell {
+					top.cell.WriteString("\n")
+				}
+			case "tc":
+				if top != nil && len(top.rows) > 0 {
+					row := &top.rows[len(top.rows)-1]
+					*row = append(*row, strings.TrimSpace(top.cell.String()))
+					for i := 1; i < top.span; i++ {
+						*row = append(*row, "")
+					}
+					top.inCell = false
+				}
+			case "tbl":
+				if top != nil {
+					stack = stack[:len(stack)-1]
+					tables[top.slot].Rows = top.rows
+				}
+			}
+		}
+	}
+
+	// Drop tables without rows (and any left open by a truncated document)
+	result := tables[:0]
+	for _, table := range tables {
+		if len(table.Rows) > 0 {
+			result = append(result, table)
+		}
+	}
+	return result, nil
+}
+
+// xmlAttr returns the value of an attribute by local name
+func xmlAttr(element xml.StartElement, name string) string {
+	for _, attr := range element.Attr {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// pdfSegment is a run of text on a PDF line, separated from its neighbours by
+// a gap wide enough to be a column gutter
+type pdfSegment struct {
+	x0, x1 float64
+	text   strings.Builder
+}
+
+// pdfTables detects tables on the selected pages of a PDF from the layout of
+// its text: runs of two or more consecutive lines that split into several
+// cells whose edges line up in columns
+func pdfTables(filePath, selection string) ([]Table, error) {
+	var tables []Table
+	err := withPDF(filePath, "", func(reader *pdf.Reader) error {
+		numbers, err := parsePageSelection(selection, reader.NumPage())
+		if err != nil {
+			return err
+		}
+
+		for _, number := range numbers {
+			page := reader.Page(number)
+			if page.V.IsNull() {
+				continue
+			}
+			for _, rows := range detectPDFTables(pdfLines(page.Content().Text)) {
+				tables = append(tables, Table{Page: number, Rows: rows})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tables, nil
+}
+
+// pdfLines groups glyphs into lines from top to bottom, each split into
+// segments at column-sized gaps
+func pdfLines(glyphs []pdf.Text) [][]*pdfSegment {
+	sorted := make([]pdf.Text, 0, len(glyphs))
+	for _, glyph := range glyphs {
+		if glyph.S != "" {
+			sorted = append(sorted, glyph)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Y > sorted[j].Y })
+
+	// Cluster by baseline, then order each line left to right
+	var lines [][]pdf.Text
+	for _, glyph := range sorted {
+		n := len(lines)
+		if n > 0 && math.Abs(lines[n-1][0].Y-glyph.Y) <= pdfLineTolerance*fontSize(glyph) {
+			lines[n-1] = append(lines[n-1], glyph)
+		} else {
+			lines = append(lines, []pdf.Text{glyph})
+		}
+	}
+
+	result := make([][]*pdfSegment, 0, len(lines))
+	for _, line := range lines {
+		sort.SliceStable(line, func(i, j int) bool { return line[i].X < line[j].X })
+
+		var segments []*pdfSegment
+		var current *pdfSegment
+		for _, glyph := range line {
+			size := fontSize(glyph)
+			width := glyph.W
+			if width <= 0 {
+				width = size * 0.5 // Fonts without width tables
+			}
+			if current != nil {
+				gap := glyph.X - current.x1
+				switch {
+				case gap > pdfColumnGap*size:
+					current = nil
+				case gap > pdfWordGap*size && !strings.HasSuffix(current.text.String(), " "):
+					current.text.WriteString(" ")
+				}
+			}
+			if current == nil {
+				current = &pdfSegment{x0: glyph.X}
+				segments = append(segments, current)
+			}
+			current.text.WriteString(glyph.S)
+			current.x1 = glyph.X + width
+		}
+
+		// Whitespace-only segments (e.g. runs of spaces) don't count as cells
+		kept := segments[:0]
+		for _, segment := range segments {
+			if strings.TrimSpace(segment.text.String()) != "" {
+				kept = append(kept, segment)
+			}
+		}
+		result = append(result, kept)
+	}
+	return result
+}
+
+func fontSize(glyph pdf.Text) float64 {
+	if glyph.FontSize <= 0 {
+		return 10
+	}
+	return math.Abs(glyph.FontSize)
+}
+
+// detectPDFTables finds runs of at least two consecutive multi-cell lines and
+// lays their cells out in columns
+func detectPDFTables(lines [][]*pdfSegment) [][][]string {
+	var tables [][][]string
+
+	for start := 0; start < len(lines); {
+		if len(lines[start]) < 2 {
+			start++
+			continue
+		}
+		end := start
+		for end < len(lines) && len(lines[end]) >= 2 {
+			end++
+		}
+		if end-start >= 2 {
+			if rows := layoutPDFTable(lines[start:end]); rows != nil {
+				tables = append(tables, rows)
+			}
+		}
+		start = end
+	}
+	return tables
+}
+
+// layoutPDFTable assigns the cells of a run of lines to columns. A column is
+// the horizontal extent covered by overlapping cells across all lines, so
+// left-aligned text and right-aligned numbers both line up; cells separated by
+// a gutter on every line fall in different columns.
+func layoutPDFTable(lines [][]*pdfSegment) [][]string {
+	var spans [][2]float64
+	for _, line := range lines {
+		for _, segment := range line {
+			spans = append(spans, [2]float64{segment.x0, segment.x1})
+		}
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i][0] < spans[j][0] })
+
+	var columns [][2]float64
+	for _, span := range spans {
+		if n := len(columns); n > 0 && span[0] <= columns[n-1][1] {
+			columns[n-1][1] = max(columns[n-1][1], span[1])
+		} else {
+			columns = append(columns, span)
+		}
+	}
+	if len(columns) < 2 {
+		return nil // Cells overlap across the gutters: not a grid
+	}
+
+	rows := make([][]string, 0, len(lines))
+	for _, line := range lines {
+		row := make([]string, len(columns))
+		for _, segment := range line {
+			column := sort.Search(len(columns), func(i int) bool { return columns[i][1] >= segment.x0 })
+			text := strings.TrimSpace(segment.text.String())
+			if row[column] != "" {
+				text = row[column] + " " + text
+			}
+			row[column] = text
+		}
+		// Trim trailing empty cells
+		last := len(row)
+		for last > 0 && row[last-1] == "" {
+			last--
+		}
+		rows = append(rows, row[:last])
+	}
+	return rows
+}
@@ -0,0 +1,88 @@
+package document
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// pdfTextAt is a content stream fragment drawing text at a position in 10pt Helvetica
+func pdfTextAt(x, y float64, text string) string {
+	return fmt.Sprintf("BT /F1 10 Tf 1 0 0 1 %g %g Tm (%s) Tj ET\n", x, y, text)
+}
+
+func TestExtractPDFTables(t *testing.T) {
+	// Glyphs are 5pt wide: the Region column is left-aligned, the figures right-aligned at x=300
+	var stream strings.Builder
+	stream.WriteString(pdfTextAt(72, 750, "Quarterly results by region"))
+	for i, row := range [][]string{{"Region", "Units", "Revenue"}, {"North", "1,200", "15,350"}, {"South", "80", "920"}} {
+		y := float64(700 - i*15)
+		stream.WriteString(pdfTextAt(72, y, row[0]))
+		stream.WriteString(pdfTextAt(200-float64(len(row[1])*5), y, row[1]))
+		stream.WriteString(pdfTextAt(300-float64(len(row[2])*5), y, row[2]))
+	}
+	stream.WriteString(pdfTextAt(72, 640, "Figures are unaudited."))
+
+	path := filepath.Join(t.TempDir(), "results.pdf")
+	writeTestPDFStreams(t, path, "", pdfTextAt(72, 750, "Cover page"), stream.String())
+
+	tables, err := NewManager().ExtractTables(path, "")
+	if err != nil {
+		t.Fatalf("ExtractTables failed: %v", err)
+	}
+	if len(tables) != 1 {
+		t.Fatalf("Expected 1 table, got %d: %+v", len(tables), tables)
+	}
+	expected := [][]string{{"Region", "Units", "Revenue"}, {"North", "1,200", "15,350"}, {"South", "80", "920"}}
+	if table := tables[0]; table.Page != 2 || table.Columns != 3 || !reflect.DeepEqual(table.Rows, expected) {
+		t.Errorf("Unexpected table: %+v", table)
+	}
+
+	// The page selection limits the scan
+	if tables, err := NewManager().ExtractTables(path, "1"); err != nil || len(tables) != 0 {
+		t.Errorf("Expected no tables on page 1, got %+v, %v", tables, err)
+	}
+}
+
+func TestExtractDocxTables(t *testing.T) {
+	cell := func(text string, props string) string {
+		return `<w:tc><w:tcPr>` + props + `</w:tcPr><w:p><w:r><w:t>` + text + `</w:t></w:r></w:p></w:tc>`
+	}
+	inner := `<w:tbl><w:tr>` + cell("a", "") + cell("b", "") + `</w:tr></w:tbl>`
+	document := `<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+		<w:p><w:r><w:t>Intro</w:t></w:r></w:p>
+		<w:tbl>
+			<w:tr>` + cell("Name", "") + cell("Contact", `<w:gridSpan w:val="2"/>`) + `</w:tr>
+			<w:tr>` + cell("Ana", `<w:vMerge w:val="restart"/>`) + cell("ana@example.com", "") + `<w:tc><w:p><w:r><w:t>555</w:t></w:r><w:r><w:tab/><w:t>0100</w:t></w:r></w:p><w:p><w:r><w:t>ext 2</w:t></w:r></w:p></w:tc></w:tr>
+			<w:tr><w:tc><w:tcPr><w:vMerge/></w:tcPr><w:p/></w:tc>` + cell("ana@work.example", "") + `<w:tc>` + inner + `<w:p/></w:tc></w:tr>
+		</w:tbl>
+	</w:body></w:document>`
+
+	path := filepath.Join(t.TempDir(), "contacts.docx")
+	writeTestZip(t, path, [2]string{"word/document.xml", document})
+
+	tables, err := NewManager().ExtractTables(path, "")
+	if err != nil {
+		t.Fatalf("ExtractTables failed: %v", err)
+	}
+	if len(tables) != 2 {
+		t.Fatalf("Expected the outer and nested tables, got %+v", tables)
+	}
+	expected := [][]string{{"Name", "Contact", ""}, {"Ana", "ana@example.com", "555 0100\next 2"}, {"", "ana@work.example", ""}}
+	if tables[0].Index != 1 || tables[0].Columns != 3 || !reflect.DeepEqual(tables[0].Rows, expected) {
+		t.Errorf("Unexpected outer table: %+v", tables[0])
+	}
+	if tables[1].Index != 2 || !reflect.DeepEqual(tables[1].Rows, [][]string{{"a", "b"}}) {
+		t.Errorf("Unexpected nested table: %+v", tables[1])
+	}
+
+	if csv := tables[0].CSV(); !strings.HasPrefix(csv, "Name,Contact,\nAna,ana@example.com,\"555 0100\next 2\"\n") {
+		t.Errorf("Unexpected CSV: %q", csv)
+	}
+
+	if _, err := NewManager().ExtractTables(path, "1"); err == nil {
+		t.Error("Expected an error for a page selection on a DOCX file")
+	}
+}
@@ -0,0 +1,110 @@
+package document
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	utf8BOM           = []byte{0xEF, 0xBB, 0xBF}
+	blankLinesPattern = regexp.MustCompile(`\n{3,}`)
+	lineSpacePattern  = regexp.MustCompile(`[ \t\f\v]+`)
+	rotatedLogPattern = regexp.MustCompile(`(?i)\.log\.\d+$`) // app.log.1
+)
+
+// textDocumentType identifies formats stored as plain text, which carry no
+// magic number: the extension decides once the content looks like text, and
+// HTML is also recognised by its opening tag
+func textDocumentType(filePath string, head []byte) DocumentType {
+	if !isTextContent(head) {
+		return DocumentTypeUnknown // Binary content
+	}
+
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".html", ".htm", ".xhtml":
+		return DocumentTypeHTML
+	case ".md", ".markdown":
+		return DocumentTypeMarkdown
+	case ".txt", ".text", ".log":
+		return DocumentTypeText
+	}
+	if rotatedLogPattern.MatchString(filePath) {
+		return DocumentTypeText
+	}
+
+	if looksLikeHTML(head) {
+		return DocumentTypeHTML
+	}
+	return DocumentTypeUnknown
+}
+
+// looksLikeHTML reports whether content opens with an HTML doctype or root element
+func looksLikeHTML(head []byte) bool {
+	head = bytes.TrimLeft(bytes.TrimPrefix(head, utf8BOM), " \t\r\n")
+	if len(head) > 64 {
+		head = head[:64]
+	}
+	lower := bytes.ToLower(head)
+	return bytes.HasPrefix(lower, []byte("<!doctype html")) || bytes.HasPrefix(lower, []byte("<html"))
+}
+
+// readTextFile reads a text document and converts it to UTF-8 from its detected encoding
+func readTextFile(filePath, kind string) (string, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s file: %w", kind, err)
+	}
+	text, _, err := decodeText(content)
+	return text, err
+}
+
+func (m *Manager) extractPlainText(_ context.Context, filePath string) (string, error) {
+	text, err := readTextFile(filePath, "text")
+	if err != nil {
+		return "", err
+	}
+
+	// Line structure matters in logs and notes, so lines are kept
+	return cleanStructuredText(text), nil
+}
+
+// plainTextMetadata reports the detected encoding and line count of a text file
+func plainTextMetadata(filePath string) (map[string]string, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	text, encodingName, err := decodeText(content)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.Count(text, "\n")
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		lines++
+	}
+	return map[string]string{"encoding": encodingName, "lines": strconv.Itoa(lines)}, nil
+}
+
+// cleanStructuredText tidies text whose line structure is meaningful: line
+// endings are normalized, control characters removed, runs of spaces within a
+// line collapsed and blank lines limited to one between blocks
+func cleanStructuredText(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	text = controlPattern.ReplaceAllString(text, "")
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(lineSpacePattern.ReplaceAllString(line, " "))
+	}
+	text = blankLinesPattern.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+
+	return strings.TrimSpace(text)
+}
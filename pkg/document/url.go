@@ -0,0 +1,204 @@
+package document
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/kevsmith/my-mcp/pkg/shared"
+)
+
+// urlContentTypes maps the media types accepted from a URL to the extension
+// the downloaded file is given, so the format is detected as for local files
+var urlContentTypes = map[string]string{
+	"application/pdf":    ".pdf",
+	"application/msword": ".doc",
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document":   ".docx",
+	"application/vnd.ms-powerpoint":                                             ".ppt",
+	"application/vnd.openxmlformats-officedocument.presentationml.presentation": ".pptx",
+	"application/vnd.oasis.opendocument.text":                                   ".odt",
+	"application/rtf":       ".rtf",
+	"text/rtf":              ".rtf",
+	"application/epub+zip":  ".epub",
+	"text/html":             ".html",
+	"application/xhtml+xml": ".html",
+	"text/markdown":         ".md",
+	"text/plain":            ".txt",
+}
+
+// maxURLRedirects is the most redirects followed for one download
+const maxURLRedirects = 5
+
+// URL clients download documents; the request context bounds each download.
+// publicURLClient refuses to connect to internal addresses and ignores proxy
+// settings, which would hide the address actually reached.
+var (
+	publicURLClient   = newURLClient(false)
+	internalURLClient = newURLClient(true)
+)
+
+// newURLClient returns a client that follows up to maxURLRedirects redirects
+// and, unless allowInternal, only connects to public addresses
+func newURLClient(allowInternal bool) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !allowInternal {
+		transport.Proxy = nil
+		transport.DialContext = (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+			Control:   refuseInternal,
+		}).DialContext
+	}
+	return &http.Client{
+		Transport: transport,
+		CheckRedirect: func(request *http.Request, via []*http.Request) error {
+			if len(via) >= maxURLRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxURLRedirects)
+			}
+			return nil
+		},
+	}
+}
+
+// publicAddress reports whether addr is on the public internet rather than
+// loopback, private, link-local, multicast or unspecified. It is a variable
+// so tests can stand a local server in for a public one.
+var publicAddress = func(addr netip.AddrPort) bool {
+	ip := addr.Addr().Unmap()
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !ip.IsLoopback() && !ip.IsLinkLocalUnicast()
+}
+
+// refuseInternal is a dialer control that refuses connections to addresses
+// that aren't public, checked after DNS resolution and for every redirect
+func refuseInternal(network, address string, _ syscall.RawConn) error {
+	addr, err := netip.ParseAddrPort(address)
+	if err != nil || !publicAddress(addr) {
+		return shared.NewError(shared.CodeAccessDenied, "%s is not a public address (set DOCUMENT_ALLOW_INTERNAL_URLS to fetch internal hosts)", address)
+	}
+	return nil
+}
+
+// ExtractFromURL downloads a document over HTTP or HTTPS into a temporary
+// file and extracts its text like ExtractText. The download is bound by the
+// file size limit, the download and the extraction together by the extraction
+// timeout, and only document content types are accepted; generic binary
+// responses are accepted when the URL path has a supported extension. Hosts
+// that resolve to internal addresses are refused unless the limits allow them.
+func (m *Manager) ExtractFromURL(ctx context.Context, rawURL string) (string, error) {
+	if m.limits.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.limits.Timeout)
+		defer cancel()
+	}
+
+	tempPath, err := m.download(ctx, rawURL)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tempPath)
+
+	return m.ExtractText(ctx, tempPath)
+}
+
+// download saves the document at rawURL to a temporary file and returns its path
+func (m *Manager) download(ctx context.Context, rawURL string) (string, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", fmt.Errorf("invalid URL: %s (only http and https URLs are supported)", rawURL)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, parsed.String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+	request.Header.Set("User-Agent", "document-mcp")
+
+	client := publicURLClient
+	if m.limits.AllowInternalURLs {
+		client = internalURLClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return "", m.downloadError(ctx, rawURL, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return "", fmt.Errorf("failed to download %s: %s", rawURL, response.Status)
+	}
+
+	ext, err := urlExtension(response.Header.Get("Content-Type"), parsed.Path)
+	if err != nil {
+		return "", fmt.Errorf("%w from %s", err, rawURL)
+	}
+
+	maxSize := m.limits.MaxFileSize
+	if maxSize > 0 && response.ContentLength > maxSize {
+		return "", shared.NewError(shared.CodeTooLarge, "file is too large: %d bytes exceeds the limit of %d bytes", response.ContentLength, maxSize)
+	}
+
+	temp, err := os.CreateTemp("", "download-*"+ext)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+
+	// Read one byte past the limit to detect oversized bodies without a length
+	body := io.Reader(response.Body)
+	if maxSize > 0 {
+		body = io.LimitReader(response.Body, maxSize+1)
+	}
+	written, copyErr := io.Copy(temp, body)
+	if err := errors.Join(copyErr, temp.Close()); err != nil {
+		os.Remove(temp.Name())
+		return "", m.downloadError(ctx, rawURL, err)
+	}
+	if maxSize > 0 && written > maxSize {
+		os.Remove(temp.Name())
+		return "", shared.NewError(shared.CodeTooLarge, "file is too large: more than %d bytes", maxSize)
+	}
+
+	return temp.Name(), nil
+}
+
+// downloadError describes a failed download, naming the timeout when it expired
+func (m *Manager) downloadError(ctx context.Context, rawURL string, err error) error {
+	if m.limits.Timeout > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return shared.NewError(shared.CodeTimeout, "download timed out after %s: %s", m.limits.Timeout, rawURL)
+	}
+	return fmt.Errorf("failed to download %s: %w", rawURL, err)
+}
+
+// urlExtension validates the content type of a response and returns the
+// extension of the document it holds
+func urlExtension(contentType, urlPath string) (string, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = ""
+	}
+	if ext, ok := urlContentTypes[mediaType]; ok {
+		return ext, nil
+	}
+
+	// Servers often send documents as generic binary data
+	if mediaType == "" || mediaType == "application/octet-stream" {
+		ext := strings.ToLower(path.Ext(urlPath))
+		for _, supported := range urlContentTypes {
+			if ext == supported || ext == ".htm" || ext == ".log" {
+				return ext, nil
+			}
+		}
+	}
+
+	return "", shared.NewError(shared.CodeUnsupportedFormat, "unsupported content type %q", contentType)
+}
@@ -0,0 +1,155 @@
+package document
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kevsmith/my-mcp/pkg/shared"
+)
+
+func TestExtractFromURL(t *testing.T) {
+	pdfPath := filepath.Join(t.TempDir(), "report.pdf")
+	writeTestPDF(t, pdfPath, "BT /F1 12 Tf 72 720 Td (Quarterly report) Tj ET")
+	pdfData, err := os.ReadFile(pdfPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/report", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Write(pdfData)
+	})
+	mux.HandleFunc("/files/report.pdf", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write(pdfData)
+	})
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte("<html><body><h1>Status</h1><p>All systems normal.</p></body></html>"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tests := map[string]string{
+		"/report":           "Quarterly report",
+		"/files/report.pdf": "Quarterly report",
+		"/page":             "All systems normal.",
+	}
+	m := NewManager()
+	m.SetLimits(Limits{Timeout: time.Minute, AllowInternalURLs: true})
+	for urlPath, expected := range tests {
+		text, err := m.ExtractFromURL(context.Background(), server.URL+urlPath)
+		if err != nil {
+			t.Errorf("ExtractFromURL(%s) failed: %v", urlPath, err)
+			continue
+		}
+		if !strings.Contains(text, expected) {
+			t.Errorf("ExtractFromURL(%s) = %q, want it to contain %q", urlPath, text, expected)
+		}
+	}
+}
+
+func TestExtractFromURLErrors(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/image", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("\x89PNG"))
+	})
+	mux.HandleFunc("/large", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(strings.Repeat("a", 2048)))
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	})
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	m := NewManager()
+	m.SetLimits(Limits{MaxFileSize: 1024, Timeout: 200 * time.Millisecond, AllowInternalURLs: true})
+
+	tests := map[string]string{
+		"ftp://example.com/report.pdf": "only http and https URLs",
+		server.URL + "/missing":        "404 Not Found",
+		server.URL + "/image":          `unsupported content type "image/png"`,
+		server.URL + "/large":          "file is too large",
+		server.URL + "/slow":           "download timed out",
+		server.URL + "/loop":           "stopped after 5 redirects",
+	}
+	for rawURL, expected := range tests {
+		if _, err := m.ExtractFromURL(context.Background(), rawURL); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("ExtractFromURL(%s) error = %v, want %q", rawURL, err, expected)
+		}
+	}
+}
+
+func TestExtractFromURLInternalHosts(t *testing.T) {
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("instance credentials"))
+	}))
+	defer internal.Close()
+
+	// Stands in for a public host that redirects to the internal one
+	redirector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, internal.URL+"/latest/meta-data", http.StatusFound)
+	}))
+	defer redirector.Close()
+
+	redirectorAddr := netip.MustParseAddrPort(redirector.Listener.Addr().String())
+	original := publicAddress
+	publicAddress = func(addr netip.AddrPort) bool { return addr == redirectorAddr }
+	defer func() { publicAddress = original }()
+
+	m := NewManager()
+	m.SetLimits(Limits{Timeout: 5 * time.Second})
+	for _, rawURL := range []string{internal.URL + "/latest/meta-data", redirector.URL} {
+		_, err := m.ExtractFromURL(context.Background(), rawURL)
+		if err == nil || shared.AsToolError(err).Code != shared.CodeAccessDenied || !strings.Contains(err.Error(), "not a public address") {
+			t.Errorf("ExtractFromURL(%s) error = %v, want a refused internal address", rawURL, err)
+		}
+	}
+
+	m.SetLimits(Limits{Timeout: 5 * time.Second, AllowInternalURLs: true})
+	if text, err := m.ExtractFromURL(context.Background(), redirector.URL); err != nil || !strings.Contains(text, "instance credentials") {
+		t.Errorf("ExtractFromURL(%s) with internal URLs allowed = %q, %v", redirector.URL, text, err)
+	}
+}
+
+func TestPublicAddress(t *testing.T) {
+	tests := map[string]bool{
+		"93.184.216.34:443":           true,
+		"[2606:4700::1111]:443":       true,
+		"127.0.0.1:80":                false,
+		"10.0.0.5:80":                 false,
+		"172.16.3.4:80":               false,
+		"192.168.1.1:80":              false,
+		"169.254.169.254:80":          false,
+		"0.0.0.0:80":                  false,
+		"224.0.0.1:80":                false,
+		"[::1]:80":                    false,
+		"[fe80::1]:80":                false,
+		"[fd00::1]:80":                false,
+		"[::ffff:127.0.0.1]:80":       false,
+		"[::ffff:169.254.169.254]:80": false,
+	}
+	for address, expected := range tests {
+		if got := publicAddress(netip.MustParseAddrPort(address)); got != expected {
+			t.Errorf("publicAddress(%s) = %v, want %v", address, got, expected)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package excel
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Kinds of audit findings
+const (
+	AuditErrorValue          = "error_value"          // The cell shows an error such as #DIV/0!
+	AuditVolatileFunction    = "volatile_function"    // The formula recalculates on every change
+	AuditHardcodedConstant   = "hardcoded_constant"   // The formula embeds a number instead of referring to a cell
+	AuditInconsistentFormula = "inconsistent_formula" // The formula differs from most formulas in its column
+)
+
+// minConsistentFormulas is how many formulas a column needs before its odd
+// ones out are flagged
+const minConsistentFormulas = 3
+
+// errorValues are the error values Excel shows in cells
+var errorValues = []string{"#NULL!", "#DIV/0!", "#VALUE!", "#REF!", "#NAME?", "#NUM!", "#N/A", "#SPILL!", "#CALC!", "#GETTING_DATA"}
+
+// volatileFunctions recalculate whenever anything in the workbook changes
+var volatileFunctions = []string{"NOW", "TODAY", "RAND", "RANDBETWEEN", "RANDARRAY", "OFFSET", "INDIRECT", "CELL", "INFO"}
+
+// constantFunctions are functions whose plain number arguments are data
+// rather than structure, unlike the digits of ROUND or the column of VLOOKUP
+var constantFunctions = []string{"SUM", "AVERAGE", "MIN", "MAX", "PRODUCT"}
+
+// AuditFinding is one risk found in a cell
+type AuditFinding struct {
+	Cell    string `json:"cell"`
+	Kind    string `json:"kind"`
+	Detail  string `json:"detail"`
+	Formula string `json:"formula,omitempty"`
+	col     int
+	row     int
+}
+
+// SheetAudit is the result of auditing a sheet
+type SheetAudit struct {
+	Sheet    string         `json:"sheet"`
+	Formulas int            `json:"formulas"` // Formula cells audited
+	Counts   map[string]int `json:"counts"`   // Findings by kind
+	Findings []AuditFinding `json:"findings"` // In row order
+}
+
+// auditedFormula is a formula cell kept for the column consistency check
+type auditedFormula struct {
+	cell    string
+	formula string
+	shape   string // The formula with references relative to its cell
+	row     int
+}
+
+// AuditSheet flags cells with error values, volatile functions, constants
+// hardcoded in formulas and formulas inconsistent with their column
+func (m *Manager) AuditSheet(filePath, sheetName string) (*SheetAudit, error) {
+	file, err := m.OpenFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	rows, err := file.GetRows(sheetName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows for sheet %s: %w", sheetName, err)
+	}
+
+	audit := &SheetAudit{Sheet: sheetName, Counts: make(map[string]int), Findings: []AuditFinding{}}
+	add := func(col, row int, cell, kind, detail, formula string) {
+		audit.Findings = append(audit.Findings, AuditFinding{Cell: cell, Kind: kind, Detail: detail, Formula: formula, col: col, row: row})
+		audit.Counts[kind]++
+	}
+
+	columns := make(map[int][]auditedFormula)
+	for rowIndex, row := range rows {
+		for colIndex, value := range row {
+			col, rowNum := colIndex+1, rowIndex+1
+			cell, err := excelize.CoordinatesToCellName(col, rowNum)
+			if err != nil {
+				continue
+			}
+			formula, err := file.GetCellFormula(sheetName, cell)
+			if err != nil {
+				formula = ""
+			}
+
+			if slices.Contains(errorValues, strings.TrimSpace(value)) {
+				add(col, rowNum, cell, AuditErrorValue, "shows "+strings.TrimSpace(value), formula)
+			} else if strings.Contains(strings.ToUpper(formula), "#REF!") {
+				add(col, rowNum, cell, AuditErrorValue, "refers to a deleted cell (#REF!)", formula)
+			}
+			if formula == "" {
+				continue
+			}
+			audit.Formulas++
+
+			tokens, err := tokenizeFormula(formula)
+			if err != nil {
+				continue
+			}
+			if volatile := volatileCalls(tokens); len(volatile) > 0 {
+				add(col, rowNum, cell, AuditVolatileFunction, "recalculates on every change: "+strings.Join(volatile, ", "), formula)
+			}
+			if root, err := (&formulaParser{tokens: tokens}).parse(); err == nil {
+				if constants := hardcodedConstants(formula, root, nil); len(constants) > 0 {
+					add(col, rowNum, cell, AuditHardcodedConstant, "hardcoded "+strings.Join(constants, ", "), formula)
+				}
+			}
+			columns[col] = append(columns[col], auditedFormula{cell: cell, formula: formula, shape: formulaShape(tokens, col, rowNum), row: rowNum})
+		}
+	}
+
+	for col, formulas := range columns {
+		for _, odd := range inconsistentFormulas(formulas) {
+			add(col, odd.row, odd.cell, AuditInconsistentFormula, odd.detail, odd.formula)
+		}
+	}
+
+	slices.SortStableFunc(audit.Findings, func(a, b AuditFinding) int {
+		return cmp.Or(cmp.Compare(a.row, b.row), cmp.Compare(a.col, b.col))
+	})
+	return audit, nil
+}
+
+// volatileCalls returns the volatile functions a formula calls
+func volatileCalls(tokens []formulaToken) []string {
+	var found []string
+	for _, token := range tokens {
+		name := strings.ToUpper(strings.TrimPrefix(token.text, "_xlfn."))
+		if token.kind == tokenFunction && slices.Contains(volatileFunctions, name) && !slices.Contains(found, name) {
+			found = append(found, name)
+		}
+	}
+	return found
+}
+
+// hardcodedConstants returns the numbers written into a formula's
+// calculations, other than 0 and 1. Numbers that configure a function, such
+// as ROUND's digits, aren't counted.
+func hardcodedConstants(formula string, n, parent *formulaNode) []string {
+	if isLiteral(n) {
+		text := formula[n.start:n.end]
+		number, err := strconv.ParseFloat(strings.TrimLeft(strings.TrimRight(text, "%"), "+-"), 64)
+		if err != nil || number == 0 || number == 1 || parent == nil {
+			return nil
+		}
+		inCalculation := parent.kind == nodeBinary && parent.text != ":"
+		inAggregate := parent.kind == nodeCall && slices.Contains(constantFunctions, strings.ToUpper(parent.text))
+		if inCalculation || inAggregate {
+			return []string{text}
+		}
+		return nil
+	}
+
+	var constants []string
+	for _, arg := range n.args {
+		constants = append(constants, hardcodedConstants(formula, arg, n)...)
+	}
+	return constants
+}
+
+// isLiteral reports whether n is a value, possibly signed or a percentage
+func isLiteral(n *formulaNode) bool {
+	if n.kind == nodePercent || n.kind == nodeUnary {
+		return isLiteral(n.args[0])
+	}
+	return n.kind == nodeValue
+}
+
+// formulaShape rewrites a formula's references relative to its cell, in the
+// spirit of R1C1 notation, so copies of one formula down a column compare equal
+func formulaShape(tokens []formulaToken, col, row int) string {
+	var shape strings.Builder
+	for _, token := range tokens {
+		if token.kind != tokenReference {
+			shape.WriteString(token.text)
+			continue
+		}
+		match := cellRefRegex.FindStringSubmatchIndex(token.text)
+		if match == nil {
+			shape.WriteString(token.text)
+			continue
+		}
+		shape.WriteString(strings.ToLower(token.text[:match[8]]))
+		shape.WriteString(relativeCell(token.text[match[8]:match[9]], col, row))
+		if match[10] >= 0 {
+			shape.WriteString(":" + relativeCell(token.text[match[10]:match[11]], col, row))
+		}
+	}
+	return shape.String()
+}
+
+// relativeCell writes a cell reference as R[rows]C[columns] from col and row,
+// keeping absolute parts absolute
+func relativeCell(ref string, col, row int) string {
+	refCol, refRow, err := excelize.CellNameToCoordinates(strings.ReplaceAll(ref, "$", ""))
+	if err != nil {
+		return ref
+	}
+	rowPart := fmt.Sprintf("R[%d]", refRow-row)
+	if strings.LastIndex(ref, "$") > 0 {
+		rowPart = fmt.Sprintf("R%d", refRow)
+	}
+	colPart := fmt.Sprintf("C[%d]", refCol-col)
+	if strings.HasPrefix(ref, "$") {
+		colPart = fmt.Sprintf("C%d", refCol)
+	}
+	return rowPart + colPart
+}
+
+// oddFormula is a formula that differs from the usual one in its column
+type oddFormula struct {
+	cell    string
+	formula string
+	detail  string
+	row     int
+}
+
+// inconsistentFormulas returns the formulas in a column that differ from the
+// shape most of them share
+func inconsistentFormulas(formulas []auditedFormula) []oddFormula {
+	if len(formulas) < minConsistentFormulas {
+		return nil
+	}
+
+	counts := make(map[string]int)
+	example := make(map[string]auditedFormula)
+	for _, f := range formulas {
+		if counts[f.shape] == 0 {
+			example[f.shape] = f
+		}
+		counts[f.shape]++
+	}
+	var usual string
+	for _, f := range formulas { // In row order, so ties go to the first shape
+		if counts[f.shape] > counts[usual] {
+			usual = f.shape
+		}
+	}
+	if counts[usual]*2 <= len(formulas) {
+		return nil // No shape is shared by most formulas, so none is odd
+	}
+
+	var odd []oddFormula
+	for _, f := range formulas {
+		if f.shape != usual {
+			detail := fmt.Sprintf("differs from %d of %d formulas in its column, such as %s: =%s", counts[usual], len(formulas), example[usual].cell, example[usual].formula)
+			odd = append(odd, oddFormula{cell: f.cell, formula: f.formula, detail: detail, row: f.row})
+		}
+	}
+	return odd
+}
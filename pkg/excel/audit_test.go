@@ -0,0 +1,113 @@
+package excel
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestAuditSheet(t *testing.T) {
+	f := excelize.NewFile()
+	for cell, value := range map[string]any{
+		"A1": "Item", "B1": "Amount", "C1": "Tax", "D1": "Note", "F1": 0.2,
+		"B2": 100, "B3": 200, "B4": 300, "B5": 400,
+		"D2": "x", "D3": "x", "D4": "x", "D5": "x",
+		"E3": "#DIV/0!",
+	} {
+		f.SetCellValue("Sheet1", cell, value)
+	}
+	for cell, formula := range map[string]string{
+		"C2": "B2*$F$1", "C3": "B3*$F$1", "C4": "B4*0.2", "C5": "B5*$F$1",
+		"E2": "NOW()",
+		"E4": "#REF!*2",
+	} {
+		f.SetCellFormula("Sheet1", cell, formula)
+	}
+	path := filepath.Join(t.TempDir(), "audit.xlsx")
+	if err := f.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	manager := NewManager()
+	defer manager.Close()
+	audit, err := manager.AuditSheet(path, "Sheet1")
+	if err != nil {
+		t.Fatalf("AuditSheet failed: %v", err)
+	}
+
+	found := make(map[[2]string]AuditFinding)
+	for _, finding := range audit.Findings {
+		found[[2]string{finding.Cell, finding.Kind}] = finding
+	}
+	for _, want := range [][2]string{
+		{"C4", AuditHardcodedConstant},
+		{"C4", AuditInconsistentFormula},
+		{"E2", AuditVolatileFunction},
+		{"E3", AuditErrorValue},
+		{"E4", AuditErrorValue},
+	} {
+		if _, ok := found[want]; !ok {
+			t.Errorf("Expected a %s finding for %s in %+v", want[1], want[0], audit.Findings)
+		}
+	}
+	for _, cell := range []string{"C2", "C3", "C5"} {
+		for _, kind := range []string{AuditHardcodedConstant, AuditInconsistentFormula} {
+			if finding, ok := found[[2]string{cell, kind}]; ok {
+				t.Errorf("Unexpected finding for the consistent formula in %s: %+v", cell, finding)
+			}
+		}
+	}
+	if detail := found[[2]string{"C4", AuditHardcodedConstant}].Detail; detail != "hardcoded 0.2" {
+		t.Errorf("Hardcoded constant detail = %q", detail)
+	}
+	if audit.Formulas != 6 || audit.Counts[AuditErrorValue] != 2 {
+		t.Errorf("Audit counted %d formulas and %v, want 6 formulas and 2 error values", audit.Formulas, audit.Counts)
+	}
+
+	// The tool pages findings in row order
+	handlers := NewHandlers(manager)
+	result, err := handlers.AuditSheet(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{
+		Name:      "audit_sheet",
+		Arguments: map[string]any{"file_path": path, "limit": 1},
+	}})
+	if err != nil || result.IsError {
+		t.Fatalf("audit_sheet failed: %v %+v", err, result)
+	}
+	var page auditPage
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &page); err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Findings) != 1 || page.Findings[0].Cell != "E2" || page.NextOffset != 1 || page.Total != len(audit.Findings) {
+		t.Errorf("Unexpected first page: %+v", page)
+	}
+}
+
+func TestHardcodedConstants(t *testing.T) {
+	tests := map[string]int{
+		"B2*1.08":           1,
+		"SUM(B2:B5,500)":    1,
+		"ROUND(B2*C2,2)":    0, // ROUND's digits configure the function
+		"VLOOKUP(A2,D:F,3)": 0,
+		"B2*-5%+1":          1,
+		"IF(B2>1000,B2,0)":  1,
+		`CONCAT("Q",3)`:     0,
+	}
+	for formula, want := range tests {
+		tokens, err := tokenizeFormula(formula)
+		if err != nil {
+			t.Fatalf("tokenizeFormula(%q) failed: %v", formula, err)
+		}
+		root, err := (&formulaParser{tokens: tokens}).parse()
+		if err != nil {
+			t.Fatalf("parse(%q) failed: %v", formula, err)
+		}
+		if got := hardcodedConstants(formula, root, nil); len(got) != want {
+			t.Errorf("hardcodedConstants(%q) = %v, want %d", formula, got, want)
+		}
+	}
+}
@@ -5,8 +5,10 @@ import (
 	"os"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/kevsmith/my-mcp/pkg/shared"
 	"github.com/xuri/excelize/v2"
 )
 
@@ -24,6 +26,8 @@ type FileCache struct {
 	lruList    *list.List
 	maxSize    int
 	defaultTTL time.Duration
+	hits       atomic.Int64
+	misses     atomic.Int64
 }
 
 // CacheConfig holds cache configuration parameters
@@ -71,6 +75,7 @@ func (fc *FileCache) Get(filePath string) (*excelize.File, bool) {
 	entry, exists := fc.cache[filePath]
 	if !exists {
 		fc.mutex.RUnlock()
+		fc.misses.Add(1)
 		return nil, false
 	}
 
@@ -85,6 +90,7 @@ func (fc *FileCache) Get(filePath string) (*excelize.File, bool) {
 			fc.removeEntry(filePath, entry)
 		}
 		fc.mutex.Unlock()
+		fc.misses.Add(1)
 		return nil, false
 	}
 
@@ -99,6 +105,7 @@ func (fc *FileCache) Get(filePath string) (*excelize.File, bool) {
 	}
 	fc.mutex.Unlock()
 
+	fc.hits.Add(1)
 	return file, true
 }
 
@@ -173,6 +180,11 @@ func (fc *FileCache) Size() int {
 	return len(fc.cache)
 }
 
+// Stats returns the cache's hit and miss counts and current size
+func (fc *FileCache) Stats() shared.CacheStats {
+	return shared.CacheStats{Hits: fc.hits.Load(), Misses: fc.misses.Load(), Entries: fc.Size()}
+}
+
 // removeEntry removes an entry from both cache map and LRU list
 func (fc *FileCache) removeEntry(filePath string, entry *CacheEntry) {
 	if entry.file != nil {
@@ -197,13 +209,28 @@ func (fc *FileCache) evictOldest() {
 	}
 }
 
-// StartCleanupTicker starts a background goroutine to periodically clean expired entries
-func (fc *FileCache) StartCleanupTicker(interval time.Duration) *time.Ticker {
+// StartCleanupTicker starts a background goroutine to periodically clean
+// expired entries. Calling the returned function stops it; later calls do
+// nothing.
+func (fc *FileCache) StartCleanupTicker(interval time.Duration) (stop func()) {
 	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
 	go func() {
-		for range ticker.C {
-			fc.CleanExpired()
+		for {
+			select {
+			case <-ticker.C:
+				fc.CleanExpired()
+			case <-done:
+				return
+			}
 		}
 	}()
-	return ticker
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			ticker.Stop()
+			close(done)
+		})
+	}
 }
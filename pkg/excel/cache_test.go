@@ -85,6 +85,10 @@ func TestFileCacheBasicOperations(t *testing.T) {
 	if cache.Size() != 1 {
 		t.Errorf("Expected cache size 1, got %d", cache.Size())
 	}
+
+	if stats := cache.Stats(); stats.Hits != 1 || stats.Misses != 1 || stats.Entries != 1 {
+		t.Errorf("Expected 1 hit, 1 miss and 1 entry, got %+v", stats)
+	}
 }
 
 func TestFileCacheTTLExpiration(t *testing.T) {
@@ -194,6 +198,34 @@ func TestFileCacheCleanExpired(t *testing.T) {
 	}
 }
 
+func TestFileCacheCleanupTicker(t *testing.T) {
+	config := CacheConfig{MaxSize: 5, DefaultTTL: 10 * time.Millisecond}
+	cache := NewFileCache(config)
+	defer cache.Clear()
+
+	filePath := createTestExcelFile(t)
+	defer os.Remove(filePath)
+
+	file, err := OpenTestFile(filePath)
+	if err != nil {
+		t.Fatalf("Failed to open test file: %v", err)
+	}
+	cache.Put(filePath, file)
+
+	stop := cache.StartCleanupTicker(5 * time.Millisecond)
+	deadline := time.Now().Add(2 * time.Second)
+	for cache.Size() != 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if cache.Size() != 0 {
+		t.Error("Expected the ticker to clean the expired entry")
+	}
+
+	// Stopping twice, as Close after a shutdown does, is harmless
+	stop()
+	stop()
+}
+
 func TestManagerFlushCache(t *testing.T) {
 	// Create manager with small cache for testing
 	config := CacheConfig{MaxSize: 3, DefaultTTL: time.Hour}
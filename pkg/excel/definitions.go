@@ -43,6 +43,9 @@ func GetToolDefinitions() []mcp.Tool {
 			mcp.WithString("sheet_name",
 				mcp.Description("Name of the sheet (optional, defaults to current or first sheet)"),
 			),
+			mcp.WithBoolean("include_format",
+				mcp.Description("Return JSON with each cell's raw value, its value formatted by the cell's number format (e.g. '$1,234.50', '12%') and the format code (default: false)"),
+			),
 		),
 		mcp.NewTool("get_range_values",
 			mcp.WithDescription("Get values from a range of cells in an Excel spreadsheet"),
@@ -58,6 +61,12 @@ func GetToolDefinitions() []mcp.Tool {
 			mcp.WithString("sheet_name",
 				mcp.Description("Name of the sheet (optional, defaults to current or first sheet)"),
 			),
+			mcp.WithBoolean("include_format",
+				mcp.Description("Return JSON with each cell's raw value, its value formatted by the cell's number format (e.g. '$1,234.50', '12%') and the format code (default: false)"),
+			),
+			mcp.WithBoolean("include_hidden",
+				mcp.Description("Include hidden rows and columns, which are skipped by default (default: false)"),
+			),
 		),
 		mcp.NewTool("list_sheets",
 			mcp.WithDescription("List all available sheets in an Excel spreadsheet"),
@@ -93,6 +102,9 @@ func GetToolDefinitions() []mcp.Tool {
 			mcp.WithString("sheet_name",
 				mcp.Description("Name of the sheet (optional, defaults to current or first sheet)"),
 			),
+			mcp.WithBoolean("include_hidden",
+				mcp.Description("Include values in hidden rows, and allow reading a hidden column (default: false)"),
+			),
 		),
 		mcp.NewTool("get_row",
 			mcp.WithDescription("Get all values in a specific row from an Excel spreadsheet"),
@@ -108,6 +120,9 @@ func GetToolDefinitions() []mcp.Tool {
 			mcp.WithString("sheet_name",
 				mcp.Description("Name of the sheet (optional, defaults to current or first sheet)"),
 			),
+			mcp.WithBoolean("include_hidden",
+				mcp.Description("Include values in hidden columns, and allow reading a hidden row (default: false)"),
+			),
 		),
 		mcp.NewTool("get_sheet_stats",
 			mcp.WithDescription("Get statistical summary of an Excel sheet including row count, column count, non-empty cells, and data types"),
@@ -138,6 +153,90 @@ func GetToolDefinitions() []mcp.Tool {
 			mcp.WithString("sheet_name",
 				mcp.Description("Name of the sheet (optional, defaults to current or first sheet)"),
 			),
+			mcp.WithBoolean("summary",
+				mcp.Description("Also describe what the formula does in plain English, with nested functions broken into steps (default: false)"),
+			),
+		),
+		mcp.NewTool("explain_formulas_sheet",
+			mcp.WithDescription("Extract and explain every formula in an Excel sheet as JSON, translating cell references to human-readable names based on headers; results are paged with offset and limit for sheets with many formulas"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("file_path",
+				mcp.Description("Path to the Excel file"),
+				mcp.Required(),
+			),
+			mcp.WithString("sheet_name",
+				mcp.Description("Name of the sheet (optional, defaults to current or first sheet)"),
+			),
+			mcp.WithNumber("offset",
+				mcp.Description("Number of formulas to skip, in row order (default: 0)"),
+				mcp.Min(0),
+			),
+			mcp.WithNumber("limit",
+				mcp.Description("Maximum formulas to return (default: 100, maximum: 1000)"),
+				mcp.Min(1),
+				mcp.Max(1000),
+			),
+		),
+		mcp.NewTool("audit_sheet",
+			mcp.WithDescription("Audit an Excel sheet for spreadsheet risks: cells showing error values such as #REF! and #DIV/0!, volatile functions such as NOW and RAND, numbers hardcoded in formulas, and formulas that differ from the rest of their column"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("file_path",
+				mcp.Description("Path to the Excel file"),
+				mcp.Required(),
+			),
+			mcp.WithString("sheet_name",
+				mcp.Description("Name of the sheet (optional, defaults to current or first sheet)"),
+			),
+			mcp.WithNumber("offset",
+				mcp.Description("Number of findings to skip, in row order (default: 0)"),
+				mcp.Min(0),
+			),
+			mcp.WithNumber("limit",
+				mcp.Description("Maximum findings to return (default: 100, maximum: 1000)"),
+				mcp.Min(1),
+				mcp.Max(1000),
+			),
+		),
+		mcp.NewTool("get_sheet_layout",
+			mcp.WithDescription("Get the layout of an Excel sheet: frozen rows and columns, print areas, hidden rows and columns, and column widths. Other reads skip hidden rows and columns unless include_hidden is set"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("file_path",
+				mcp.Description("Path to the Excel file"),
+				mcp.Required(),
+			),
+			mcp.WithString("sheet_name",
+				mcp.Description("Name of the sheet (optional, defaults to current or first sheet)"),
+			),
+		),
+		mcp.NewTool("get_protection",
+			mcp.WithDescription("Report whether an Excel workbook's structure and sheets are protected, what protected sheets still allow, and which cells are locked or left editable. Locked cells on a protected sheet can't be changed until the sheet is unprotected"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("file_path",
+				mcp.Description("Path to the Excel file"),
+				mcp.Required(),
+			),
+			mcp.WithString("sheet_name",
+				mcp.Description("Name of the sheet (optional, defaults to every sheet)"),
+			),
+		),
+		mcp.NewTool("export_range_as_resource",
+			mcp.WithDescription("Export a range of cells as an MCP resource (CSV or JSON) that clients can fetch on demand instead of receiving the data inline"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("file_path",
+				mcp.Description("Path to the Excel file"),
+				mcp.Required(),
+			),
+			mcp.WithString("range",
+				mcp.Description("Range reference (e.g., 'A1:C3', 'B2:D10')"),
+				mcp.Required(),
+			),
+			mcp.WithString("sheet_name",
+				mcp.Description("Name of the sheet (optional, defaults to current or first sheet)"),
+			),
+			mcp.WithString("format",
+				mcp.Description("Resource content type: 'csv' (text/csv) or 'json' (application/json) (default: csv)"),
+				mcp.Enum("csv", "json"),
+			),
 		),
 	}
 }
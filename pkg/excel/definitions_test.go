@@ -1,14 +1,17 @@
 package excel
 
 import (
+	"slices"
 	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
 )
 
 func TestGetToolDefinitions(t *testing.T) {
 	tools := GetToolDefinitions()
 
-	if len(tools) != 11 {
-		t.Errorf("Expected 11 tools, got %d", len(tools))
+	if len(tools) != 16 {
+		t.Errorf("Expected 16 tools, got %d", len(tools))
 	}
 
 	expectedTools := []string{
@@ -23,6 +26,11 @@ func TestGetToolDefinitions(t *testing.T) {
 		"get_sheet_stats",
 		"flush_cache",
 		"explain_formula",
+		"explain_formulas_sheet",
+		"audit_sheet",
+		"get_sheet_layout",
+		"get_protection",
+		"export_range_as_resource",
 	}
 
 	for i, expectedName := range expectedTools {
@@ -62,6 +70,11 @@ func TestToolNamesAndDescriptions(t *testing.T) {
 		{8, "get_sheet_stats", "Get statistical summary of an Excel sheet including row count, column count, non-empty cells, and data types"},
 		{9, "flush_cache", "Flush the Excel file cache, closing all open files and freeing memory"},
 		{10, "explain_formula", "Extract and explain a specific formula from an Excel cell, translating cell references to human-readable names based on headers"},
+		{11, "explain_formulas_sheet", "Extract and explain every formula in an Excel sheet as JSON, translating cell references to human-readable names based on headers; results are paged with offset and limit for sheets with many formulas"},
+		{12, "audit_sheet", "Audit an Excel sheet for spreadsheet risks: cells showing error values such as #REF! and #DIV/0!, volatile functions such as NOW and RAND, numbers hardcoded in formulas, and formulas that differ from the rest of their column"},
+		{13, "get_sheet_layout", "Get the layout of an Excel sheet: frozen rows and columns, print areas, hidden rows and columns, and column widths. Other reads skip hidden rows and columns unless include_hidden is set"},
+		{14, "get_protection", "Report whether an Excel workbook's structure and sheets are protected, what protected sheets still allow, and which cells are locked or left editable. Locked cells on a protected sheet can't be changed until the sheet is unprotected"},
+		{15, "export_range_as_resource", "Export a range of cells as an MCP resource (CSV or JSON) that clients can fetch on demand instead of receiving the data inline"},
 	}
 
 	for _, tc := range testCases {
@@ -80,3 +93,54 @@ func TestToolNamesAndDescriptions(t *testing.T) {
 		}
 	}
 }
+
+func TestStatsAndFormulaToolSchemas(t *testing.T) {
+	tools := make(map[string]mcp.Tool)
+	for _, tool := range GetToolDefinitions() {
+		tools[tool.Name] = tool
+	}
+
+	testCases := []struct {
+		name       string
+		required   []string
+		properties []string
+		readOnly   bool
+	}{
+		{"get_range_values", []string{"file_path", "range"}, []string{"file_path", "range", "sheet_name", "include_format", "include_hidden"}, true},
+		{"get_column", []string{"file_path", "column"}, []string{"file_path", "column", "sheet_name", "include_hidden"}, true},
+		{"get_row", []string{"file_path", "row_number"}, []string{"file_path", "row_number", "sheet_name", "include_hidden"}, true},
+		{"get_sheet_stats", []string{"file_path"}, []string{"file_path", "sheet_name"}, true},
+		{"flush_cache", nil, nil, false},
+		{"explain_formula", []string{"file_path", "cell"}, []string{"file_path", "cell", "sheet_name", "summary"}, true},
+		{"explain_formulas_sheet", []string{"file_path"}, []string{"file_path", "sheet_name", "offset", "limit"}, true},
+		{"audit_sheet", []string{"file_path"}, []string{"file_path", "sheet_name", "offset", "limit"}, true},
+		{"get_protection", []string{"file_path"}, []string{"file_path", "sheet_name"}, true},
+	}
+
+	for _, tc := range testCases {
+		tool, ok := tools[tc.name]
+		if !ok {
+			t.Errorf("Tool '%s' is not defined", tc.name)
+			continue
+		}
+		if !slices.Equal(tool.InputSchema.Required, tc.required) {
+			t.Errorf("Tool '%s' requires %v, want %v", tc.name, tool.InputSchema.Required, tc.required)
+		}
+		if len(tool.InputSchema.Properties) != len(tc.properties) {
+			t.Errorf("Tool '%s' has %d properties, want %d", tc.name, len(tool.InputSchema.Properties), len(tc.properties))
+		}
+		for _, property := range tc.properties {
+			if _, ok := tool.InputSchema.Properties[property]; !ok {
+				t.Errorf("Tool '%s' is missing property '%s'", tc.name, property)
+			}
+		}
+		if hint := tool.Annotations.ReadOnlyHint; hint == nil || *hint != tc.readOnly {
+			t.Errorf("Tool '%s' read-only hint = %v, want %v", tc.name, hint, tc.readOnly)
+		}
+	}
+
+	limit := tools["explain_formulas_sheet"].InputSchema.Properties["limit"].(map[string]any)
+	if limit["type"] != "number" || limit["maximum"] != 1000.0 {
+		t.Errorf("Unexpected limit schema: %v", limit)
+	}
+}
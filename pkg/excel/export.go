@@ -0,0 +1,134 @@
+package excel
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/kevsmith/my-mcp/pkg/shared"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// ExportFormat identifies the content type of an exported range
+type ExportFormat string
+
+const (
+	ExportFormatCSV  ExportFormat = "csv"
+	ExportFormatJSON ExportFormat = "json"
+)
+
+// maxExports bounds how many exported ranges are kept as resources; the oldest
+// export is unregistered when the limit is exceeded
+const maxExports = 20
+
+// exportURIPrefix is the URI scheme used for exported range resources
+const exportURIPrefix = "excel://exports/"
+
+// ResourceRegistrar is the subset of the MCP server used to publish exports
+type ResourceRegistrar interface {
+	AddResource(resource mcp.Resource, handler server.ResourceHandlerFunc)
+	RemoveResource(uri string)
+}
+
+// RangeExport is a snapshot of range values published as an MCP resource
+type RangeExport struct {
+	URI      string
+	Name     string
+	MIMEType string
+	Rows     int
+	Content  string
+}
+
+// exportRangeJSON is the JSON document served for json exports
+type exportRangeJSON struct {
+	File   string     `json:"file"`
+	Sheet  string     `json:"sheet"`
+	Range  string     `json:"range"`
+	Values [][]string `json:"values"`
+}
+
+// exportRegistry tracks published exports so old ones can be retired
+type exportRegistry struct {
+	mu        sync.Mutex
+	registrar ResourceRegistrar
+	nextID    int
+	uris      []string
+}
+
+// newExportRegistry creates an export registry publishing to registrar
+func newExportRegistry(registrar ResourceRegistrar) *exportRegistry {
+	return &exportRegistry{registrar: registrar}
+}
+
+// publish registers values as a new resource and returns its description
+func (r *exportRegistry) publish(filePath, sheetName, rangeRef string, values [][]string, format ExportFormat) (*RangeExport, error) {
+	content, mimeType, err := encodeRangeValues(filePath, sheetName, rangeRef, values, format)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	export := &RangeExport{
+		URI:      fmt.Sprintf("%s%d.%s", exportURIPrefix, r.nextID, format),
+		Name:     fmt.Sprintf("%s!%s (%s)", sheetName, rangeRef, filepath.Base(filePath)),
+		MIMEType: mimeType,
+		Rows:     len(values),
+		Content:  content,
+	}
+
+	resource := mcp.NewResource(export.URI, export.Name,
+		mcp.WithResourceDescription(fmt.Sprintf("Snapshot of range %s in sheet %s of %s", rangeRef, sheetName, filePath)),
+		mcp.WithMIMEType(mimeType),
+	)
+	r.registrar.AddResource(resource, func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return []mcp.ResourceContents{
+			mcp.TextResourceContents{
+				URI:      export.URI,
+				MIMEType: export.MIMEType,
+				Text:     export.Content,
+			},
+		}, nil
+	})
+
+	r.uris = append(r.uris, export.URI)
+	for len(r.uris) > maxExports {
+		r.registrar.RemoveResource(r.uris[0])
+		r.uris = r.uris[1:]
+	}
+
+	return export, nil
+}
+
+// encodeRangeValues renders range values in the requested format
+func encodeRangeValues(filePath, sheetName, rangeRef string, values [][]string, format ExportFormat) (string, string, error) {
+	switch format {
+	case ExportFormatCSV:
+		var buf bytes.Buffer
+		writer := csv.NewWriter(&buf)
+		if err := writer.WriteAll(values); err != nil {
+			return "", "", fmt.Errorf("failed to encode CSV: %v", err)
+		}
+		return buf.String(), "text/csv", nil
+	case ExportFormatJSON:
+		data, err := json.Marshal(exportRangeJSON{
+			File:   filePath,
+			Sheet:  sheetName,
+			Range:  rangeRef,
+			Values: values,
+		})
+		if err != nil {
+			return "", "", fmt.Errorf("failed to encode JSON: %v", err)
+		}
+		return string(data), "application/json", nil
+	default:
+		return "", "", shared.NewError(shared.CodeInvalidArgument, "unsupported export format: %s (expected csv or json)", format)
+	}
+}
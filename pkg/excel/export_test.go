@@ -0,0 +1,177 @@
+package excel
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// fakeRegistrar records resources published by the export registry
+type fakeRegistrar struct {
+	handlers map[string]server.ResourceHandlerFunc
+	order    []string
+}
+
+func newFakeRegistrar() *fakeRegistrar {
+	return &fakeRegistrar{handlers: make(map[string]server.ResourceHandlerFunc)}
+}
+
+func (f *fakeRegistrar) AddResource(resource mcp.Resource, handler server.ResourceHandlerFunc) {
+	f.handlers[resource.URI] = handler
+	f.order = append(f.order, resource.URI)
+}
+
+func (f *fakeRegistrar) RemoveResource(uri string) {
+	delete(f.handlers, uri)
+}
+
+func (f *fakeRegistrar) read(t *testing.T, uri string) mcp.TextResourceContents {
+	t.Helper()
+
+	handler, ok := f.handlers[uri]
+	if !ok {
+		t.Fatalf("Resource %s not registered", uri)
+	}
+
+	contents, err := handler(context.Background(), mcp.ReadResourceRequest{})
+	if err != nil {
+		t.Fatalf("Reading resource failed: %v", err)
+	}
+	if len(contents) != 1 {
+		t.Fatalf("Expected 1 resource content, got %d", len(contents))
+	}
+
+	text, ok := contents[0].(mcp.TextResourceContents)
+	if !ok {
+		t.Fatalf("Expected text resource contents, got %T", contents[0])
+	}
+	return text
+}
+
+func TestExportRangeAsResource(t *testing.T) {
+	manager := NewManager()
+	handlers := NewHandlers(manager)
+	registrar := newFakeRegistrar()
+	handlers.EnableResourceExports(registrar)
+	filePath := createTestExcelFileForHandlers(t)
+	ctx := context.Background()
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Arguments: map[string]interface{}{
+				"file_path": filePath,
+				"range":     "A1:C2",
+			},
+		},
+	}
+
+	result, err := handlers.ExportRangeAsResource(ctx, request)
+	if err != nil {
+		t.Fatalf("ExportRangeAsResource failed: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("Expected success, got error: %v", result.Content)
+	}
+	if len(result.Content) != 2 {
+		t.Fatalf("Expected text and resource link content, got %d items", len(result.Content))
+	}
+
+	link, ok := result.Content[1].(mcp.ResourceLink)
+	if !ok {
+		t.Fatalf("Expected resource link, got %T", result.Content[1])
+	}
+	if link.MIMEType != "text/csv" {
+		t.Errorf("Expected text/csv, got %s", link.MIMEType)
+	}
+
+	csvContent := registrar.read(t, link.URI)
+	if csvContent.Text != "Name,Age,City\nJohn,30,New York\n" {
+		t.Errorf("Unexpected CSV content: %q", csvContent.Text)
+	}
+
+	// JSON export
+	request.Params.Arguments = map[string]interface{}{
+		"file_path": filePath,
+		"range":     "A1:B2",
+		"format":    "json",
+	}
+	result, err = handlers.ExportRangeAsResource(ctx, request)
+	if err != nil || result.IsError {
+		t.Fatalf("JSON export failed: %v %v", err, result.Content)
+	}
+
+	link = result.Content[1].(mcp.ResourceLink)
+	if link.URI == registrar.order[0] {
+		t.Error("Each export should get a distinct URI")
+	}
+
+	var doc exportRangeJSON
+	if err := json.Unmarshal([]byte(registrar.read(t, link.URI).Text), &doc); err != nil {
+		t.Fatalf("JSON export is not valid JSON: %v", err)
+	}
+	if doc.Sheet != "Sheet1" || doc.Range != "A1:B2" || len(doc.Values) != 2 || doc.Values[1][0] != "John" {
+		t.Errorf("Unexpected JSON export: %+v", doc)
+	}
+
+	// Unsupported format
+	request.Params.Arguments = map[string]interface{}{
+		"file_path": filePath,
+		"range":     "A1:B2",
+		"format":    "xml",
+	}
+	result, _ = handlers.ExportRangeAsResource(ctx, request)
+	if !result.IsError {
+		t.Error("Expected error for unsupported format")
+	}
+}
+
+func TestExportRangeAsResourceDisabled(t *testing.T) {
+	handlers := NewHandlers(NewManager())
+	filePath := createTestExcelFileForHandlers(t)
+
+	request := mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Arguments: map[string]interface{}{
+				"file_path": filePath,
+				"range":     "A1:C2",
+			},
+		},
+	}
+
+	result, err := handlers.ExportRangeAsResource(context.Background(), request)
+	if err != nil {
+		t.Fatalf("ExportRangeAsResource failed: %v", err)
+	}
+	if !result.IsError {
+		t.Fatal("Expected error when resource exports are not enabled")
+	}
+	if text, ok := mcp.AsTextContent(result.Content[0]); !ok || !strings.Contains(text.Text, "not enabled") {
+		t.Errorf("Unexpected error message: %v", result.Content[0])
+	}
+}
+
+func TestExportRegistryEvictsOldest(t *testing.T) {
+	registrar := newFakeRegistrar()
+	registry := newExportRegistry(registrar)
+	values := [][]string{{"a"}}
+
+	for i := 0; i < maxExports+2; i++ {
+		if _, err := registry.publish("book.xlsx", "Sheet1", "A1", values, ExportFormatCSV); err != nil {
+			t.Fatalf("publish failed: %v", err)
+		}
+	}
+
+	if len(registrar.handlers) != maxExports {
+		t.Errorf("Expected %d registered exports, got %d", maxExports, len(registrar.handlers))
+	}
+	if _, ok := registrar.handlers[registrar.order[0]]; ok {
+		t.Error("Oldest export should have been removed")
+	}
+	if _, ok := registrar.handlers[registrar.order[len(registrar.order)-1]]; !ok {
+		t.Error("Newest export should still be registered")
+	}
+}
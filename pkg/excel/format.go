@@ -0,0 +1,164 @@
+package excel
+
+import (
+	"fmt"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// builtInNumberFormats are the format codes of the number formats every
+// workbook has without defining them (ECMA-376 part 1, 18.8.30)
+var builtInNumberFormats = map[int]string{
+	1:  "0",
+	2:  "0.00",
+	3:  "#,##0",
+	4:  "#,##0.00",
+	9:  "0%",
+	10: "0.00%",
+	11: "0.00E+00",
+	12: "# ?/?",
+	13: "# ??/??",
+	14: "mm-dd-yy",
+	15: "d-mmm-yy",
+	16: "d-mmm",
+	17: "mmm-yy",
+	18: "h:mm AM/PM",
+	19: "h:mm:ss AM/PM",
+	20: "h:mm",
+	21: "h:mm:ss",
+	22: "m/d/yy h:mm",
+	37: "#,##0 ;(#,##0)",
+	38: "#,##0 ;[Red](#,##0)",
+	39: "#,##0.00;(#,##0.00)",
+	40: "#,##0.00;[Red](#,##0.00)",
+	45: "mm:ss",
+	46: "[h]:mm:ss",
+	47: "mmss.0",
+	48: "##0.0E+0",
+	49: "@",
+}
+
+// CellValue is a cell's value as stored and as displayed through its number
+// format, so figures can be reported the way the workbook's author intended
+type CellValue struct {
+	Cell         string `json:"cell"`
+	Raw          string `json:"raw"`                     // The stored value, e.g. 1234.5
+	Formatted    string `json:"formatted"`               // The displayed value, e.g. $1,234.50
+	NumberFormat string `json:"number_format,omitempty"` // Format code; omitted for General
+}
+
+// formatReader reads formatted cell values from one sheet, looking each
+// style's number format up once
+type formatReader struct {
+	file    *excelize.File
+	sheet   string
+	formats map[int]string
+}
+
+func newFormatReader(file *excelize.File, sheet string) *formatReader {
+	return &formatReader{file: file, sheet: sheet, formats: make(map[int]string)}
+}
+
+// cell returns the raw and formatted values of a cell
+func (r *formatReader) cell(cell string) (CellValue, error) {
+	formatted, err := r.file.GetCellValue(r.sheet, cell)
+	if err != nil {
+		return CellValue{}, fmt.Errorf("failed to get cell value: %w", err)
+	}
+	raw, err := r.file.GetCellValue(r.sheet, cell, excelize.Options{RawCellValue: true})
+	if err != nil {
+		return CellValue{}, fmt.Errorf("failed to get cell value: %w", err)
+	}
+	return CellValue{Cell: cell, Raw: raw, Formatted: formatted, NumberFormat: r.numberFormat(cell)}, nil
+}
+
+// numberFormat returns the format code of a cell's style, or an empty string
+// for General
+func (r *formatReader) numberFormat(cell string) string {
+	styleID, err := r.file.GetCellStyle(r.sheet, cell)
+	if err != nil || styleID == 0 {
+		return ""
+	}
+	if code, ok := r.formats[styleID]; ok {
+		return code
+	}
+
+	var code string
+	if style, err := r.file.GetStyle(styleID); err == nil {
+		if style.CustomNumFmt != nil {
+			code = *style.CustomNumFmt
+		} else {
+			code = builtInNumberFormats[style.NumFmt]
+		}
+	}
+	r.formats[styleID] = code
+	return code
+}
+
+// GetFormattedCellValue returns a cell's raw value, its value formatted by
+// the cell's number format, and the format code
+func (m *Manager) GetFormattedCellValue(filePath, cell, sheetName string) (*CellValue, error) {
+	file, err := m.OpenFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	if sheetName == "" {
+		sheetName, err = m.GetCurrentSheet(filePath, file)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	value, err := newFormatReader(file, sheetName).cell(cell)
+	if err != nil {
+		return nil, err
+	}
+	return &value, nil
+}
+
+// GetFormattedRangeValues returns the raw and formatted values of every cell
+// in a range, row by row, skipping hidden rows and columns unless
+// includeHidden is set
+func (m *Manager) GetFormattedRangeValues(filePath, rangeRef, sheetName string, includeHidden bool) ([][]CellValue, error) {
+	file, err := m.OpenFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	if sheetName == "" {
+		sheetName, err = m.GetCurrentSheet(filePath, file)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	startCol, startRow, endCol, endRow, err := parseRangeRef(rangeRef)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := newFormatReader(file, sheetName)
+	hidden := newVisibility(file, sheetName)
+	values := make([][]CellValue, 0, endRow-startRow+1)
+	for row := startRow; row <= endRow; row++ {
+		if !includeHidden && hidden.row(row) {
+			continue
+		}
+		rowValues := make([]CellValue, 0, endCol-startCol+1)
+		for col := startCol; col <= endCol; col++ {
+			if !includeHidden && hidden.column(col) {
+				continue
+			}
+			cellName, _ := excelize.CoordinatesToCellName(col, row)
+			value, err := reader.cell(cellName)
+			if err != nil {
+				return nil, err
+			}
+			rowValues = append(rowValues, value)
+		}
+		values = append(values, rowValues)
+	}
+
+	return values, nil
+}
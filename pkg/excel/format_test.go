@@ -0,0 +1,68 @@
+package excel
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestFormattedValues(t *testing.T) {
+	f := excelize.NewFile()
+	currency := "$#,##0.00"
+	currencyStyle, _ := f.NewStyle(&excelize.Style{CustomNumFmt: &currency})
+	percentStyle, _ := f.NewStyle(&excelize.Style{NumFmt: 9})
+	f.SetCellValue("Sheet1", "A1", 1234.5)
+	f.SetCellStyle("Sheet1", "A1", "A1", currencyStyle)
+	f.SetCellValue("Sheet1", "B1", 0.12)
+	f.SetCellStyle("Sheet1", "B1", "B1", percentStyle)
+	f.SetCellValue("Sheet1", "C1", "plain")
+	path := filepath.Join(t.TempDir(), "formats.xlsx")
+	if err := f.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	manager := NewManager()
+	defer manager.Close()
+
+	value, err := manager.GetFormattedCellValue(path, "A1", "")
+	if err != nil {
+		t.Fatalf("GetFormattedCellValue failed: %v", err)
+	}
+	if value.Raw != "1234.5" || value.Formatted != "$1,234.50" || value.NumberFormat != currency {
+		t.Errorf("A1 = %+v, want raw 1234.5 shown as $1,234.50", value)
+	}
+
+	rows, err := manager.GetFormattedRangeValues(path, "A1:C1", "Sheet1", false)
+	if err != nil || len(rows) != 1 || len(rows[0]) != 3 {
+		t.Fatalf("GetFormattedRangeValues = %+v, %v", rows, err)
+	}
+	want := []CellValue{
+		{Cell: "A1", Raw: "1234.5", Formatted: "$1,234.50", NumberFormat: currency},
+		{Cell: "B1", Raw: "0.12", Formatted: "12%", NumberFormat: "0%"},
+		{Cell: "C1", Raw: "plain", Formatted: "plain"},
+	}
+	for i, cell := range rows[0] {
+		if cell != want[i] {
+			t.Errorf("%s = %+v, want %+v", want[i].Cell, cell, want[i])
+		}
+	}
+
+	// The tools return the same values as JSON when asked
+	handlers := NewHandlers(manager)
+	result, err := handlers.GetCellValue(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{
+		Name:      "get_cell_value",
+		Arguments: map[string]any{"file_path": path, "cell": "B1", "include_format": true},
+	}})
+	if err != nil || result.IsError {
+		t.Fatalf("get_cell_value failed: %v %+v", err, result)
+	}
+	var cell CellValue
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &cell); err != nil || cell.Formatted != "12%" {
+		t.Errorf("get_cell_value with include_format = %+v, %v", cell, err)
+	}
+}
@@ -0,0 +1,566 @@
+package excel
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// anchoredRefRegex matches a reference at the start of the remaining formula
+var anchoredRefRegex = regexp.MustCompile(`^(?:` + cellRefRegex.String() + `)`)
+
+// Formula tokens
+type formulaTokenKind int
+
+const (
+	tokenEnd formulaTokenKind = iota
+	tokenValue
+	tokenReference
+	tokenFunction
+	tokenName
+	tokenOperator
+	tokenOpen
+	tokenClose
+	tokenSeparator
+)
+
+type formulaToken struct {
+	kind       formulaTokenKind
+	text       string
+	start, end int
+}
+
+// Parsed formula expressions
+type formulaNodeKind int
+
+const (
+	nodeValue formulaNodeKind = iota
+	nodeReference
+	nodeName
+	nodeCall
+	nodeUnary
+	nodePercent
+	nodeBinary
+)
+
+type formulaNode struct {
+	kind       formulaNodeKind
+	text       string // Value, reference, name, function or operator
+	args       []*formulaNode
+	start, end int // Span in the formula
+}
+
+// binaryLevels lists Excel's binary operators from lowest to highest
+// precedence; ':' binds tighter than all of them and is parsed separately
+var binaryLevels = [][]string{
+	{"=", "<>", "<", ">", "<=", ">="},
+	{"&"},
+	{"+", "-"},
+	{"*", "/"},
+	{"^"},
+}
+
+var binaryPhrases = map[string]string{
+	"=":  "equals",
+	"<>": "is not",
+	"<":  "is less than",
+	">":  "is greater than",
+	"<=": "is at most",
+	">=": "is at least",
+	"&":  "joined with",
+	"+":  "plus",
+	"-":  "minus",
+	"*":  "times",
+	"/":  "divided by",
+	"^":  "to the power of",
+}
+
+// summarizeFormula describes what a formula does in plain English and, for
+// nested functions, breaks it into steps in evaluation order. Formulas it
+// can't parse get no summary.
+func (fe *FormulaExtractor) summarizeFormula(sheetName, formula string) (string, []string) {
+	tokens, err := tokenizeFormula(formula)
+	if err != nil {
+		return "", nil
+	}
+	parser := &formulaParser{tokens: tokens}
+	root, err := parser.parse()
+	if err != nil {
+		return "", nil
+	}
+
+	summary := "Returns " + (&formulaDescriber{fe: fe, sheet: sheetName}).describe(root)
+
+	// Each function call becomes a step that later steps refer back to
+	d := &formulaDescriber{fe: fe, sheet: sheetName, steps: make(map[*formulaNode]int)}
+	var steps []string
+	var walk func(n *formulaNode)
+	walk = func(n *formulaNode) {
+		for _, arg := range n.args {
+			walk(arg)
+		}
+		if n.kind == nodeCall || (n == root && len(steps) > 0) {
+			steps = append(steps, fmt.Sprintf("%s: %s", strings.TrimSpace(formula[n.start:n.end]), d.describe(n)))
+			d.steps[n] = len(steps)
+		}
+	}
+	walk(root)
+	if len(steps) < 2 {
+		steps = nil // A single step only repeats the summary
+	}
+	return summary, steps
+}
+
+// tokenizeFormula splits a formula into tokens
+func tokenizeFormula(formula string) ([]formulaToken, error) {
+	var tokens []formulaToken
+	emit := func(kind formulaTokenKind, start, end int) {
+		tokens = append(tokens, formulaToken{kind: kind, text: formula[start:end], start: start, end: end})
+	}
+
+	i := 0
+	if strings.HasPrefix(formula, "=") {
+		i = 1
+	}
+	for i < len(formula) {
+		c := formula[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+			continue
+		case c == '"':
+			end := i + 1
+			for ; end < len(formula); end++ {
+				if formula[end] == '"' {
+					if end+1 < len(formula) && formula[end+1] == '"' {
+						end++
+						continue
+					}
+					break
+				}
+			}
+			if end >= len(formula) {
+				return nil, fmt.Errorf("unterminated string at %d", i)
+			}
+			emit(tokenValue, i, end+1)
+			i = end + 1
+			continue
+		case c == '{':
+			end := strings.IndexByte(formula[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated array at %d", i)
+			}
+			emit(tokenValue, i, i+end+1)
+			i += end + 1
+			continue
+		case c == '#':
+			end := i + 1
+			for end < len(formula) && strings.IndexByte("ABCDEFGHIJKLMNOPQRSTUVWXYZ/0!?", formula[end]) >= 0 {
+				end++
+			}
+			emit(tokenValue, i, end)
+			i = end
+			continue
+		}
+
+		if match := anchoredRefRegex.FindStringIndex(formula[i:]); match != nil && isReferenceBoundary(formula, i, i+match[1]) {
+			emit(tokenReference, i, i+match[1])
+			i += match[1]
+			continue
+		}
+
+		switch {
+		case isDigit(c) || (c == '.' && i+1 < len(formula) && isDigit(formula[i+1])):
+			end := i
+			for end < len(formula) && (isDigit(formula[end]) || formula[end] == '.') {
+				end++
+			}
+			if end < len(formula) && (formula[end] == 'E' || formula[end] == 'e') {
+				exp := end + 1
+				if exp < len(formula) && (formula[exp] == '+' || formula[exp] == '-') {
+					exp++
+				}
+				if exp < len(formula) && isDigit(formula[exp]) {
+					end = exp
+					for end < len(formula) && isDigit(formula[end]) {
+						end++
+					}
+				}
+			}
+			emit(tokenValue, i, end)
+			i = end
+		case isNameChar(c) || c == '\\':
+			end := i
+			for end < len(formula) && (isNameChar(formula[end]) || formula[end] == '\\') {
+				end++
+			}
+			next := end
+			for next < len(formula) && formula[next] == ' ' {
+				next++
+			}
+			if next < len(formula) && formula[next] == '(' {
+				emit(tokenFunction, i, end)
+			} else {
+				emit(tokenName, i, end)
+			}
+			i = end
+		case strings.HasPrefix(formula[i:], "<>") || strings.HasPrefix(formula[i:], "<=") || strings.HasPrefix(formula[i:], ">="):
+			emit(tokenOperator, i, i+2)
+			i += 2
+		case strings.IndexByte("+-*/^&=<>%:", c) >= 0:
+			emit(tokenOperator, i, i+1)
+			i++
+		case c == '(':
+			emit(tokenOpen, i, i+1)
+			i++
+		case c == ')':
+			emit(tokenClose, i, i+1)
+			i++
+		case c == ',' || c == ';':
+			emit(tokenSeparator, i, i+1)
+			i++
+		default:
+			return nil, fmt.Errorf("unexpected %q at %d", c, i)
+		}
+	}
+	tokens = append(tokens, formulaToken{kind: tokenEnd, start: len(formula), end: len(formula)})
+	return tokens, nil
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// formulaParser builds an expression tree from formula tokens
+type formulaParser struct {
+	tokens []formulaToken
+	pos    int
+}
+
+func (p *formulaParser) peek() formulaToken {
+	return p.tokens[p.pos]
+}
+
+func (p *formulaParser) next() formulaToken {
+	token := p.tokens[p.pos]
+	if token.kind != tokenEnd {
+		p.pos++
+	}
+	return token
+}
+
+func (p *formulaParser) isOperator(ops ...string) bool {
+	token := p.peek()
+	return token.kind == tokenOperator && slices.Contains(ops, token.text)
+}
+
+func (p *formulaParser) parse() (*formulaNode, error) {
+	root, err := p.parseBinary(0)
+	if err != nil {
+		return nil, err
+	}
+	if token := p.peek(); token.kind != tokenEnd {
+		return nil, fmt.Errorf("unexpected %q at %d", token.text, token.start)
+	}
+	return root, nil
+}
+
+func (p *formulaParser) parseBinary(level int) (*formulaNode, error) {
+	if level == len(binaryLevels) {
+		return p.parseUnary()
+	}
+	left, err := p.parseBinary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator(binaryLevels[level]...) {
+		op := p.next()
+		right, err := p.parseBinary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = &formulaNode{kind: nodeBinary, text: op.text, args: []*formulaNode{left, right}, start: left.start, end: right.end}
+	}
+	return left, nil
+}
+
+func (p *formulaParser) parseUnary() (*formulaNode, error) {
+	if p.isOperator("+", "-") {
+		op := p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &formulaNode{kind: nodeUnary, text: op.text, args: []*formulaNode{operand}, start: op.start, end: operand.end}, nil
+	}
+
+	operand, err := p.parseRange()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("%") {
+		op := p.next()
+		operand = &formulaNode{kind: nodePercent, text: op.text, args: []*formulaNode{operand}, start: operand.start, end: op.end}
+	}
+	return operand, nil
+}
+
+func (p *formulaParser) parseRange() (*formulaNode, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator(":") {
+		op := p.next()
+		right, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		left = &formulaNode{kind: nodeBinary, text: op.text, args: []*formulaNode{left, right}, start: left.start, end: right.end}
+	}
+	return left, nil
+}
+
+func (p *formulaParser) parsePrimary() (*formulaNode, error) {
+	token := p.next()
+	switch token.kind {
+	case tokenValue:
+		return &formulaNode{kind: nodeValue, text: token.text, start: token.start, end: token.end}, nil
+	case tokenReference:
+		return &formulaNode{kind: nodeReference, text: token.text, start: token.start, end: token.end}, nil
+	case tokenName:
+		return &formulaNode{kind: nodeName, text: token.text, start: token.start, end: token.end}, nil
+	case tokenOpen:
+		inner, err := p.parseBinary(0)
+		if err != nil {
+			return nil, err
+		}
+		if p.next().kind != tokenClose {
+			return nil, fmt.Errorf("missing ')' after %d", token.start)
+		}
+		return inner, nil
+	case tokenFunction:
+		return p.parseCall(token)
+	}
+	return nil, fmt.Errorf("unexpected %q at %d", token.text, token.start)
+}
+
+func (p *formulaParser) parseCall(name formulaToken) (*formulaNode, error) {
+	p.next() // The '(' the tokenizer saw after the name
+	call := &formulaNode{kind: nodeCall, text: name.text, start: name.start}
+	if p.peek().kind == tokenClose {
+		call.end = p.next().end
+		return call, nil
+	}
+	for {
+		// Arguments may be left out, as in IF(A1,,B1)
+		if kind := p.peek().kind; kind == tokenSeparator || kind == tokenClose {
+			call.args = append(call.args, &formulaNode{kind: nodeValue, start: p.peek().start, end: p.peek().start})
+		} else {
+			arg, err := p.parseBinary(0)
+			if err != nil {
+				return nil, err
+			}
+			call.args = append(call.args, arg)
+		}
+
+		switch token := p.next(); token.kind {
+		case tokenSeparator:
+		case tokenClose:
+			call.end = token.end
+			return call, nil
+		default:
+			return nil, fmt.Errorf("missing ')' in %s", name.text)
+		}
+	}
+}
+
+// formulaDescriber turns an expression tree into English
+type formulaDescriber struct {
+	fe    *FormulaExtractor
+	sheet string
+	steps map[*formulaNode]int // Calls described by earlier steps
+}
+
+func (d *formulaDescriber) describe(n *formulaNode) string {
+	if step, ok := d.steps[n]; ok {
+		return fmt.Sprintf("the result of step %d", step)
+	}
+	switch n.kind {
+	case nodeReference:
+		return d.reference(n.text)
+	case nodeCall:
+		return d.call(n)
+	case nodeUnary:
+		if n.text == "-" {
+			return "negative " + d.operand(n, n.args[0])
+		}
+		return d.describe(n.args[0])
+	case nodePercent:
+		return d.operand(n, n.args[0]) + " percent"
+	case nodeBinary:
+		if n.text == ":" {
+			return d.describe(n.args[0]) + " through " + d.describe(n.args[1])
+		}
+		return fmt.Sprintf("%s %s %s", d.operand(n, n.args[0]), binaryPhrases[n.text], d.operand(n, n.args[1]))
+	}
+	return n.text
+}
+
+// operand describes an operand of parent, in parentheses when it is itself
+// an operation that would read ambiguously
+func (d *formulaDescriber) operand(parent, n *formulaNode) string {
+	text := d.describe(n)
+	if _, described := d.steps[n]; !described && n.kind == nodeBinary && n.text != ":" && n.text != parent.text {
+		return "(" + text + ")"
+	}
+	return text
+}
+
+// reference describes a cell or range by its headers
+func (d *formulaDescriber) reference(ref string) string {
+	match := cellRefRegex.FindStringSubmatchIndex(ref)
+	if match == nil || match[10] < 0 {
+		return d.fe.translateFormula(d.sheet, ref)
+	}
+
+	// Translate both ends of a range against its sheet, naming the sheet once
+	prefix := ref[match[0]:match[8]]
+	first := d.fe.translateFormula(d.sheet, ref[:match[9]])
+	last := d.fe.translateFormula(d.sheet, prefix+ref[match[10]:match[11]])
+	if first == last {
+		return first
+	}
+	if strings.HasPrefix(last, prefix) && strings.HasPrefix(first, prefix) {
+		last = last[len(prefix):]
+	}
+	return first + " through " + last
+}
+
+// call describes a function call, falling back to the function's name for
+// functions without a phrase
+func (d *formulaDescriber) call(n *formulaNode) string {
+	name := strings.ToUpper(strings.TrimPrefix(n.text, "_xlfn."))
+	args := make([]string, len(n.args))
+	for i, arg := range n.args {
+		args[i] = d.describe(arg)
+		// Arithmetic reads ambiguously inside phrases such as "X rounded to 2"
+		if _, described := d.steps[arg]; !described && arg.kind == nodeBinary && strings.Contains("+-*/^&", arg.text) {
+			args[i] = "(" + args[i] + ")"
+		}
+	}
+	if phrase, ok := functionPhrases[name]; ok && len(args) >= phrase.minArgs {
+		return phrase.describe(args)
+	}
+	if len(args) == 0 {
+		return name + "()"
+	}
+	return fmt.Sprintf("%s of %s", name, joinPhrases(args))
+}
+
+// functionPhrase describes calls to one function with at least minArgs arguments
+type functionPhrase struct {
+	minArgs  int
+	describe func(args []string) string
+}
+
+// aggregate describes functions that combine all of their arguments
+func aggregate(prefix string) functionPhrase {
+	return functionPhrase{1, func(args []string) string { return prefix + joinPhrases(args) }}
+}
+
+// conditional describes SUMIFS-style functions: a value range followed by
+// range and criteria pairs
+func conditional(prefix string) functionPhrase {
+	return functionPhrase{3, func(args []string) string {
+		var conditions []string
+		for i := 1; i+1 < len(args); i += 2 {
+			conditions = append(conditions, fmt.Sprintf("%s matches %s", args[i], args[i+1]))
+		}
+		return fmt.Sprintf("%s%s where %s", prefix, args[0], strings.Join(conditions, " and "))
+	}}
+}
+
+var functionPhrases = map[string]functionPhrase{
+	"SUM":         aggregate("the sum of "),
+	"AVERAGE":     aggregate("the average of "),
+	"MIN":         aggregate("the smallest of "),
+	"MAX":         aggregate("the largest of "),
+	"MEDIAN":      aggregate("the median of "),
+	"PRODUCT":     aggregate("the product of "),
+	"COUNT":       aggregate("the number of numeric values in "),
+	"COUNTA":      aggregate("the number of non-empty cells in "),
+	"COUNTBLANK":  aggregate("the number of empty cells in "),
+	"CONCAT":      aggregate("the text of "),
+	"CONCATENATE": aggregate("the text of "),
+	"AND":         {1, func(a []string) string { return "whether " + strings.Join(a, " and ") }},
+	"OR":          {1, func(a []string) string { return "whether " + strings.Join(a, " or ") }},
+	"NOT":         {1, func(a []string) string { return "the opposite of " + a[0] }},
+	"ABS":         {1, func(a []string) string { return "the absolute value of " + a[0] }},
+	"SQRT":        {1, func(a []string) string { return "the square root of " + a[0] }},
+	"LEN":         {1, func(a []string) string { return "the length of " + a[0] }},
+	"TRIM":        {1, func(a []string) string { return a[0] + " without extra spaces" }},
+	"UPPER":       {1, func(a []string) string { return a[0] + " in upper case" }},
+	"LOWER":       {1, func(a []string) string { return a[0] + " in lower case" }},
+	"ROUND":       {2, func(a []string) string { return fmt.Sprintf("%s rounded to %s decimal places", a[0], a[1]) }},
+	"ROUNDUP":     {2, func(a []string) string { return fmt.Sprintf("%s rounded up to %s decimal places", a[0], a[1]) }},
+	"ROUNDDOWN":   {2, func(a []string) string { return fmt.Sprintf("%s rounded down to %s decimal places", a[0], a[1]) }},
+	"IF": {2, func(a []string) string {
+		if len(a) < 3 || a[2] == "" {
+			return fmt.Sprintf("%s if %s", a[1], a[0])
+		}
+		return fmt.Sprintf("%s if %s, otherwise %s", a[1], a[0], a[2])
+	}},
+	"IFERROR": {2, func(a []string) string { return fmt.Sprintf("%s, or %s if that is an error", a[0], a[1]) }},
+	"SUMIF": {2, func(a []string) string {
+		values := a[0]
+		if len(a) > 2 && a[2] != "" {
+			values = a[2]
+		}
+		return fmt.Sprintf("the sum of %s where %s matches %s", values, a[0], a[1])
+	}},
+	"AVERAGEIF": {2, func(a []string) string {
+		values := a[0]
+		if len(a) > 2 && a[2] != "" {
+			values = a[2]
+		}
+		return fmt.Sprintf("the average of %s where %s matches %s", values, a[0], a[1])
+	}},
+	"COUNTIF":    {2, func(a []string) string { return fmt.Sprintf("the number of cells in %s matching %s", a[0], a[1]) }},
+	"SUMIFS":     conditional("the sum of "),
+	"AVERAGEIFS": conditional("the average of "),
+	"MAXIFS":     conditional("the largest of "),
+	"MINIFS":     conditional("the smallest of "),
+	"COUNTIFS": {2, func(a []string) string {
+		var conditions []string
+		for i := 0; i+1 < len(a); i += 2 {
+			conditions = append(conditions, fmt.Sprintf("%s matches %s", a[i], a[i+1]))
+		}
+		return "the number of rows where " + strings.Join(conditions, " and ")
+	}},
+	"VLOOKUP": {3, func(a []string) string {
+		return fmt.Sprintf("the value in column %s of %s on the row whose first column matches %s", a[2], a[1], a[0])
+	}},
+	"HLOOKUP": {3, func(a []string) string {
+		return fmt.Sprintf("the value in row %s of %s in the column whose first row matches %s", a[2], a[1], a[0])
+	}},
+	"XLOOKUP": {3, func(a []string) string { return fmt.Sprintf("the value of %s where %s matches %s", a[2], a[1], a[0]) }},
+	"MATCH":   {2, func(a []string) string { return fmt.Sprintf("the position of %s in %s", a[0], a[1]) }},
+	"INDEX": {2, func(a []string) string {
+		if len(a) > 2 && a[2] != "" {
+			return fmt.Sprintf("the value at row %s, column %s of %s", a[1], a[2], a[0])
+		}
+		return fmt.Sprintf("the value at position %s of %s", a[1], a[0])
+	}},
+	"TODAY": {0, func([]string) string { return "today's date" }},
+	"NOW":   {0, func([]string) string { return "the current date and time" }},
+}
+
+// joinPhrases lists phrases as "a, b and c"
+func joinPhrases(phrases []string) string {
+	if len(phrases) < 2 {
+		return strings.Join(phrases, "")
+	}
+	return strings.Join(phrases[:len(phrases)-1], ", ") + " and " + phrases[len(phrases)-1]
+}
@@ -0,0 +1,67 @@
+package excel
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestSummarizeFormula(t *testing.T) {
+	f := excelize.NewFile()
+	defer f.Close()
+	for cell, value := range map[string]any{
+		"A1": "Region", "B1": "Q1", "C1": "Q2", "D1": "Q3", "E1": "Q4", "F1": "Headcount",
+		"A2": "North", "B2": 10, "C2": 20, "D2": 30, "E2": 40, "F2": 5,
+	} {
+		f.SetCellValue("Sheet1", cell, value)
+	}
+	fe := NewFormulaExtractor(f)
+
+	tests := []struct {
+		formula string
+		summary string
+		steps   []string
+	}{
+		{
+			formula: "SUM(B2:E2)/F2",
+			summary: "Returns the sum of Q1 through Q4 divided by Headcount",
+			steps: []string{
+				"SUM(B2:E2): the sum of Q1 through Q4",
+				"SUM(B2:E2)/F2: the result of step 1 divided by Headcount",
+			},
+		},
+		{
+			formula: `IF(F2>0,ROUND(SUM(B2:E2)/F2,1),"n/a")`,
+			summary: `Returns (the sum of Q1 through Q4 divided by Headcount) rounded to 1 decimal places if Headcount is greater than 0, otherwise "n/a"`,
+			steps: []string{
+				"SUM(B2:E2): the sum of Q1 through Q4",
+				"ROUND(SUM(B2:E2)/F2,1): (the result of step 1 divided by Headcount) rounded to 1 decimal places",
+				`IF(F2>0,ROUND(SUM(B2:E2)/F2,1),"n/a"): the result of step 2 if Headcount is greater than 0, otherwise "n/a"`,
+			},
+		},
+		{
+			formula: "(B2+C2)*-10%",
+			summary: "Returns (Q1 plus Q2) times negative 10 percent",
+		},
+		{
+			formula: "AVERAGE(B2:E2)",
+			summary: "Returns the average of Q1 through Q4", // One call needs no steps
+		},
+	}
+	for _, test := range tests {
+		summary, steps := fe.summarizeFormula("Sheet1", test.formula)
+		if summary != test.summary {
+			t.Errorf("summary of %s = %q, want %q", test.formula, summary, test.summary)
+		}
+		if !reflect.DeepEqual(steps, test.steps) {
+			t.Errorf("steps of %s = %q, want %q", test.formula, steps, test.steps)
+		}
+	}
+
+	for _, formula := range []string{"SUM(B2", `"unterminated`, "B2 @ C2"} {
+		if summary, steps := fe.summarizeFormula("Sheet1", formula); summary != "" || steps != nil {
+			t.Errorf("Expected no summary for %q, got %q %q", formula, summary, steps)
+		}
+	}
+}
@@ -11,7 +11,11 @@ import (
 
 // Precompiled regex patterns for performance
 var (
-	cellRefRegex = regexp.MustCompile(`\$?[A-Z]+\$?\d+`)
+	// cellRefRegex matches a cell or range reference with an optional sheet
+	// prefix. Groups: 1 quoted sheet ('Q1 Data', possibly with a workbook),
+	// 2 workbook of an unquoted sheet ([Budget.xlsx] or [1]), 3 unquoted
+	// sheet, 4 cell, 5 range end.
+	cellRefRegex = regexp.MustCompile(`(?:(?:'((?:[^']|'')+)'|(\[[^\]]+\])?([A-Za-z_][A-Za-z0-9_.]*))!)?(\$?[A-Z]{1,3}\$?\d+)(?::(\$?[A-Z]{1,3}\$?\d+))?`)
 )
 
 // Constants for header search optimization
@@ -21,12 +25,14 @@ const (
 
 // FormulaInfo represents a formula with its translation and context
 type FormulaInfo struct {
-	Sheet             string `json:"sheet"`
-	Cell              string `json:"cell"`
-	Formula           string `json:"formula"`
-	Value             string `json:"value"`
-	TranslatedFormula string `json:"translated_formula"`
-	Label             string `json:"label"`
+	Sheet             string   `json:"sheet"`
+	Cell              string   `json:"cell"`
+	Formula           string   `json:"formula"`
+	Value             string   `json:"value"`
+	TranslatedFormula string   `json:"translated_formula"`
+	Label             string   `json:"label"`
+	Summary           string   `json:"summary,omitempty"` // What the formula does, in summary mode
+	Steps             []string `json:"steps,omitempty"`   // Nested functions in evaluation order, in summary mode
 }
 
 // FormulaExtractor handles formula extraction and translation
@@ -108,19 +114,9 @@ func (fe *FormulaExtractor) ExtractFormulasFromSheet(sheetName string) ([]Formul
 func (fe *FormulaExtractor) ExtractFormulasFromRange(sheetName, rangeRef string) ([]FormulaInfo, error) {
 	var formulas []FormulaInfo
 
-	rangeParts := strings.Split(rangeRef, ":")
-	if len(rangeParts) != 2 {
-		return nil, fmt.Errorf("invalid range format, expected 'A1:C3'")
-	}
-
-	startCol, startRow, err := excelize.CellNameToCoordinates(rangeParts[0])
-	if err != nil {
-		return nil, fmt.Errorf("invalid start cell: %v", err)
-	}
-
-	endCol, endRow, err := excelize.CellNameToCoordinates(rangeParts[1])
+	startCol, startRow, endCol, endRow, err := parseRangeRef(rangeRef)
 	if err != nil {
-		return nil, fmt.Errorf("invalid end cell: %v", err)
+		return nil, err
 	}
 
 	for row := startRow; row <= endRow; row++ {
@@ -247,17 +243,97 @@ func (fe *FormulaExtractor) getCellLabel(sheetName, cellName string) string {
 	return rowHeader
 }
 
-// translateFormula translates cell references in a formula to human-readable names
+// translateFormula translates cell references in a formula to human-readable
+// names. References to other sheets are looked up on those sheets and keep
+// their sheet prefix; references to other workbooks can't be looked up and
+// are labelled as external instead.
 func (fe *FormulaExtractor) translateFormula(sheetName, formula string) string {
-	return cellRefRegex.ReplaceAllStringFunc(formula, func(cellRef string) string {
-		cleanRef := strings.ReplaceAll(strings.ReplaceAll(cellRef, "$", ""), " ", "")
-		header := fe.getCellHeaderByReference(sheetName, cleanRef)
-		if header != "" {
-			return header
+	var result strings.Builder
+	last := 0
+	for _, match := range cellRefRegex.FindAllStringSubmatchIndex(formula, -1) {
+		start, end := match[0], match[1]
+		if !isReferenceBoundary(formula, start, end) || inStringLiteral(formula, start) {
+			continue
 		}
+		result.WriteString(formula[last:start])
+		result.WriteString(fe.translateReference(sheetName, formula, match))
+		last = end
+	}
+	result.WriteString(formula[last:])
+	return result.String()
+}
 
-		return cellRef
-	})
+// translateReference translates one reference matched by cellRefRegex
+func (fe *FormulaExtractor) translateReference(sheetName, formula string, match []int) string {
+	group := func(i int) string {
+		if match[2*i] < 0 {
+			return ""
+		}
+		return formula[match[2*i]:match[2*i+1]]
+	}
+	original := formula[match[0]:match[1]]
+	prefix := formula[match[0]:match[8]] // Sheet and workbook, including the "!"
+
+	workbook, sheet := strings.Trim(group(2), "[]"), group(3)
+	if quoted := group(1); quoted != "" {
+		workbook, sheet = splitExternalSheet(strings.ReplaceAll(quoted, "''", "'"))
+	}
+	if workbook != "" {
+		return fmt.Sprintf("[external %s]%s!%s", workbook, sheet, formula[match[8]:match[1]])
+	}
+
+	target := sheetName
+	if sheet != "" {
+		if index, err := fe.file.GetSheetIndex(sheet); err != nil || index < 0 {
+			return original
+		}
+		target = sheet
+	}
+
+	translated := fe.translateCell(target, group(4))
+	if end := group(5); end != "" {
+		translated += ":" + fe.translateCell(target, end)
+	}
+	return prefix + translated
+}
+
+// translateCell returns the header of a cell on sheetName, or the cell
+// reference itself when it has none
+func (fe *FormulaExtractor) translateCell(sheetName, cellRef string) string {
+	if header := fe.getCellHeaderByReference(sheetName, strings.ReplaceAll(cellRef, "$", "")); header != "" {
+		return header
+	}
+	return cellRef
+}
+
+// splitExternalSheet splits a quoted sheet name such as
+// "C:\Reports\[Budget.xlsx]Q1" into its workbook and sheet; the workbook is
+// empty for a sheet of this workbook
+func splitExternalSheet(name string) (workbook, sheet string) {
+	start, end := strings.Index(name, "["), strings.Index(name, "]")
+	if start < 0 || end < start {
+		return "", name
+	}
+	return name[:start] + name[start+1:end], name[end+1:]
+}
+
+// isReferenceBoundary reports whether a match stands alone rather than being
+// part of a longer name, such as LOG10 in LOG10(A1)
+func isReferenceBoundary(formula string, start, end int) bool {
+	if start > 0 && isNameChar(formula[start-1]) {
+		return false
+	}
+	return end >= len(formula) || (formula[end] != '(' && !isNameChar(formula[end]))
+}
+
+func isNameChar(c byte) bool {
+	return c == '_' || c == '.' || ('0' <= c && c <= '9') || ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z')
+}
+
+// inStringLiteral reports whether position i of a formula is inside a
+// double-quoted string, where an escaped quote ("") toggles twice
+func inStringLiteral(formula string, i int) bool {
+	return strings.Count(formula[:i], `"`)%2 == 1
 }
 
 // getCellHeaderByReference gets the header for a cell reference
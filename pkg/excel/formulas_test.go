@@ -0,0 +1,41 @@
+package excel
+
+import (
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestTranslateFormula(t *testing.T) {
+	f := excelize.NewFile()
+	defer f.Close()
+	f.SetCellValue("Sheet1", "B1", "Price")
+	f.SetCellValue("Sheet1", "B2", 10)
+	f.SetCellValue("Sheet1", "C1", "Quantity")
+	f.SetCellValue("Sheet1", "C2", 3)
+	f.NewSheet("Q1 Data")
+	f.SetCellValue("Q1 Data", "A4", "Revenue")
+	f.SetCellValue("Q1 Data", "B4", 1200)
+	f.NewSheet("Rates")
+	f.SetCellValue("Rates", "A1", "Tax")
+	f.SetCellValue("Rates", "A2", 0.2)
+
+	fe := NewFormulaExtractor(f)
+	tests := map[string]string{
+		"B2*C2":                                "Price*Quantity",
+		"$B$2*Rates!A2":                        "Price*Rates!Tax",
+		"'Q1 Data'!B4/2":                       "'Q1 Data'!Revenue/2",
+		"SUM(Rates!A2:A2)":                     "SUM(Rates!Tax:Tax)",
+		"[Budget.xlsx]Q1!A1+B2":                "[external Budget.xlsx]Q1!A1+Price",
+		"'C:\\Books\\[Budget.xlsx]Q1 Data'!B4": "[external C:\\Books\\Budget.xlsx]Q1 Data!B4",
+		"[1]Rates!A2":                          "[external 1]Rates!A2",
+		"Missing!B2":                           "Missing!B2", // Unknown sheets are left alone
+		"LOG10(B2)":                            "LOG10(Price)",
+		`IF(B2>1,"B2 is high",C2)`:             `IF(Price>1,"B2 is high",Quantity)`,
+	}
+	for formula, want := range tests {
+		if got := fe.translateFormula("Sheet1", formula); got != want {
+			t.Errorf("translateFormula(%q) = %q, want %q", formula, got, want)
+		}
+	}
+}
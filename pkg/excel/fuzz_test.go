@@ -0,0 +1,56 @@
+package excel
+
+import (
+	"testing"
+)
+
+// FuzzParseRangeRef ensures range parsing never panics and always yields a
+// normalized, bounded range for tool arguments supplied by the client.
+func FuzzParseRangeRef(f *testing.F) {
+	seeds := []string{
+		"A1:C3",
+		"C3:A1",
+		" B2 : D10 ",
+		"A1",
+		"A1:B2:C3",
+		"A0:B1",
+		"XFD1048576:A1",
+		"$A$1:$B$2",
+		":",
+		"",
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, rangeRef string) {
+		startCol, startRow, endCol, endRow, err := parseRangeRef(rangeRef)
+		if err != nil {
+			return
+		}
+
+		if startCol < 1 || startRow < 1 {
+			t.Fatalf("range %q produced non-positive start %d,%d", rangeRef, startCol, startRow)
+		}
+		if startCol > endCol || startRow > endRow {
+			t.Fatalf("range %q was not normalized: %d,%d:%d,%d", rangeRef, startCol, startRow, endCol, endRow)
+		}
+		if cells := (endCol - startCol + 1) * (endRow - startRow + 1); cells > maxRangeCells {
+			t.Fatalf("range %q exceeds cell limit with %d cells", rangeRef, cells)
+		}
+	})
+}
+
+func TestParseRangeRefHardening(t *testing.T) {
+	startCol, startRow, endCol, endRow, err := parseRangeRef("C3:A1")
+	if err != nil {
+		t.Fatalf("Expected reversed range to be accepted, got %v", err)
+	}
+	if startCol != 1 || startRow != 1 || endCol != 3 || endRow != 3 {
+		t.Errorf("Expected normalized range 1,1:3,3, got %d,%d:%d,%d", startCol, startRow, endCol, endRow)
+	}
+
+	if _, _, _, _, err := parseRangeRef("A1:XFD1048576"); err == nil {
+		t.Error("Expected error for range exceeding the cell limit")
+	}
+}
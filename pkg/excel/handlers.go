@@ -5,12 +5,15 @@ import (
 	"fmt"
 
 	"github.com/kevsmith/my-mcp/pkg/shared"
+	"github.com/kevsmith/my-mcp/pkg/shared/sandbox"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
 // Handlers contains all MCP tool handlers
 type Handlers struct {
 	excelManager *Manager
+	exports      *exportRegistry
+	paths        *sandbox.Sandbox // Nil when any workbook may be opened
 }
 
 // NewHandlers creates a new handlers instance
@@ -20,30 +23,50 @@ func NewHandlers(excelManager *Manager) *Handlers {
 	}
 }
 
+// NewHandlersWithRoots creates handlers that only open workbooks under the
+// allowed roots; with no roots any path is accepted
+func NewHandlersWithRoots(excelManager *Manager, allowedRoots []string) (*Handlers, error) {
+	paths, err := sandbox.Optional(allowedRoots)
+	if err != nil {
+		return nil, err
+	}
+	handlers := NewHandlers(excelManager)
+	handlers.paths = paths
+	return handlers, nil
+}
+
+// EnableResourceExports allows export_range_as_resource to publish ranges as MCP resources
+func (h *Handlers) EnableResourceExports(registrar ResourceRegistrar) {
+	h.exports = newExportRegistry(registrar)
+}
+
 // EnumerateColumns handles the enumerate_columns tool
 func (h *Handlers) EnumerateColumns(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	filePath := request.GetString("file_path", "")
 	if filePath == "" {
-		return mcp.NewToolResultError("file_path parameter is required"), nil
+		return shared.ErrorResultf(shared.CodeInvalidArgument, "file_path parameter is required"), nil
+	}
+	if err := h.paths.Check(filePath); err != nil {
+		return shared.ErrorResult(err), nil
 	}
 
 	sheetName := request.GetString("sheet_name", "")
 
 	columns, err := h.excelManager.GetColumns(filePath, sheetName)
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return shared.ErrorResult(err), nil
 	}
 
 	// Get the actual sheet name used
 	file, err := h.excelManager.OpenFile(filePath)
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return shared.ErrorResult(err), nil
 	}
 
 	if sheetName == "" {
 		sheetName, err = h.excelManager.GetCurrentSheet(filePath, file)
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return shared.ErrorResult(err), nil
 		}
 	}
 
@@ -54,26 +77,29 @@ func (h *Handlers) EnumerateColumns(ctx context.Context, request mcp.CallToolReq
 func (h *Handlers) EnumerateRows(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	filePath := request.GetString("file_path", "")
 	if filePath == "" {
-		return mcp.NewToolResultError("file_path parameter is required"), nil
+		return shared.ErrorResultf(shared.CodeInvalidArgument, "file_path parameter is required"), nil
+	}
+	if err := h.paths.Check(filePath); err != nil {
+		return shared.ErrorResult(err), nil
 	}
 
 	sheetName := request.GetString("sheet_name", "")
 
 	rowCount, err := h.excelManager.GetRowCount(filePath, sheetName)
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return shared.ErrorResult(err), nil
 	}
 
 	// Get the actual sheet name used
 	file, err := h.excelManager.OpenFile(filePath)
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return shared.ErrorResult(err), nil
 	}
 
 	if sheetName == "" {
 		sheetName, err = h.excelManager.GetCurrentSheet(filePath, file)
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return shared.ErrorResult(err), nil
 		}
 	}
 
@@ -93,16 +119,31 @@ func (h *Handlers) getCellValueHandler(ctx context.Context, hctx *HandlerContext
 		return errResult, nil
 	}
 
+	if hctx.Request.GetBool("include_format", false) {
+		value, err := hctx.Manager.GetFormattedCellValue(hctx.FilePath, cell, hctx.SheetName)
+		if err != nil {
+			return shared.ErrorResult(err), nil
+		}
+		return NewJSONResponse(value)
+	}
+
 	// Get cell value using cached file and resolved sheet
 	value, err := hctx.Manager.GetCellValue(hctx.FilePath, cell, hctx.SheetName)
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return shared.ErrorResult(err), nil
 	}
 
 	// Return formatted response
 	return NewFormattedTextResponse("Cell %s in sheet %s: %s", cell, hctx.SheetName, value)
 }
 
+// formattedRange is a range's raw and formatted values, row by row
+type formattedRange struct {
+	Sheet string        `json:"sheet"`
+	Range string        `json:"range"`
+	Rows  [][]CellValue `json:"rows"`
+}
+
 // GetRangeValues handles the get_range_values tool
 func (h *Handlers) GetRangeValues(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return h.withMiddleware(h.getRangeValuesHandler)(ctx, request)
@@ -116,10 +157,19 @@ func (h *Handlers) getRangeValuesHandler(ctx context.Context, hctx *HandlerConte
 		return errResult, nil
 	}
 
+	includeHidden := hctx.Request.GetBool("include_hidden", false)
+	if hctx.Request.GetBool("include_format", false) {
+		rows, err := hctx.Manager.GetFormattedRangeValues(hctx.FilePath, rangeRef, hctx.SheetName, includeHidden)
+		if err != nil {
+			return shared.ErrorResult(err), nil
+		}
+		return NewJSONResponse(formattedRange{Sheet: hctx.SheetName, Range: rangeRef, Rows: rows})
+	}
+
 	// Get range values using cached file and resolved sheet
-	values, err := hctx.Manager.GetRangeValues(hctx.FilePath, rangeRef, hctx.SheetName)
+	values, err := hctx.Manager.GetRangeValues(hctx.FilePath, rangeRef, hctx.SheetName, includeHidden)
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return shared.ErrorResult(err), nil
 	}
 
 	// Return formatted response
@@ -136,28 +186,34 @@ func (h *Handlers) listSheetsHandler(ctx context.Context, hctx *HandlerContext)
 	// Get sheets using cached file
 	sheets, err := hctx.Manager.GetSheetList(hctx.FilePath)
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return shared.ErrorResult(err), nil
 	}
 
-	// Return formatted response
-	return NewFormattedTextResponse("Available sheets: %v", sheets)
+	// Return formatted response, noting when a locked file had to be read from a copy
+	if strategy := hctx.Manager.GetOpenStrategy(hctx.FilePath); strategy == OpenStrategyTempCopy {
+		return NewFormattedTextResponse("Available sheets: %v (file is locked by another process; read from a temporary copy)", sheets)
+	}
+	return NewFormattedTextResponse("Available sheets: %v (opened %s)", sheets, OpenStrategyReadOnly)
 }
 
 // SetCurrentSheet handles the set_current_sheet tool
 func (h *Handlers) SetCurrentSheet(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	filePath := request.GetString("file_path", "")
 	if filePath == "" {
-		return mcp.NewToolResultError("file_path parameter is required"), nil
+		return shared.ErrorResultf(shared.CodeInvalidArgument, "file_path parameter is required"), nil
+	}
+	if err := h.paths.Check(filePath); err != nil {
+		return shared.ErrorResult(err), nil
 	}
 
 	sheetName := request.GetString("sheet_name", "")
 	if sheetName == "" {
-		return mcp.NewToolResultError("sheet_name parameter is required"), nil
+		return shared.ErrorResultf(shared.CodeInvalidArgument, "sheet_name parameter is required"), nil
 	}
 
 	err := h.excelManager.SetCurrentSheet(filePath, sheetName)
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return shared.ErrorResult(err), nil
 	}
 
 	return mcp.NewToolResultText(fmt.Sprintf("Current sheet set to '%s' for file %s", sheetName, filePath)), nil
@@ -167,31 +223,35 @@ func (h *Handlers) SetCurrentSheet(ctx context.Context, request mcp.CallToolRequ
 func (h *Handlers) GetColumn(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	filePath := request.GetString("file_path", "")
 	if filePath == "" {
-		return mcp.NewToolResultError("file_path parameter is required"), nil
+		return shared.ErrorResultf(shared.CodeInvalidArgument, "file_path parameter is required"), nil
+	}
+	if err := h.paths.Check(filePath); err != nil {
+		return shared.ErrorResult(err), nil
 	}
 
 	column := request.GetString("column", "")
 	if column == "" {
-		return mcp.NewToolResultError("column parameter is required (e.g., 'A', 'B', 'Z')"), nil
+		return shared.ErrorResultf(shared.CodeInvalidArgument, "column parameter is required (e.g., 'A', 'B', 'Z')"), nil
 	}
 
 	sheetName := request.GetString("sheet_name", "")
+	includeHidden := request.GetBool("include_hidden", false)
 
-	values, err := h.excelManager.GetColumnValues(filePath, column, sheetName)
+	values, err := h.excelManager.GetColumnValues(filePath, column, sheetName, includeHidden)
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return shared.ErrorResult(err), nil
 	}
 
 	// Get the actual sheet name used
 	file, err := h.excelManager.OpenFile(filePath)
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return shared.ErrorResult(err), nil
 	}
 
 	if sheetName == "" {
 		sheetName, err = h.excelManager.GetCurrentSheet(filePath, file)
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return shared.ErrorResult(err), nil
 		}
 	}
 
@@ -202,31 +262,35 @@ func (h *Handlers) GetColumn(ctx context.Context, request mcp.CallToolRequest) (
 func (h *Handlers) GetRow(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	filePath := request.GetString("file_path", "")
 	if filePath == "" {
-		return mcp.NewToolResultError("file_path parameter is required"), nil
+		return shared.ErrorResultf(shared.CodeInvalidArgument, "file_path parameter is required"), nil
+	}
+	if err := h.paths.Check(filePath); err != nil {
+		return shared.ErrorResult(err), nil
 	}
 
 	rowNumber := request.GetInt("row_number", 0)
 	if rowNumber == 0 {
-		return mcp.NewToolResultError("row_number parameter is required (1-based)"), nil
+		return shared.ErrorResultf(shared.CodeInvalidArgument, "row_number parameter is required (1-based)"), nil
 	}
 
 	sheetName := request.GetString("sheet_name", "")
+	includeHidden := request.GetBool("include_hidden", false)
 
-	values, err := h.excelManager.GetRowValues(filePath, int(rowNumber), sheetName)
+	values, err := h.excelManager.GetRowValues(filePath, int(rowNumber), sheetName, includeHidden)
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return shared.ErrorResult(err), nil
 	}
 
 	// Get the actual sheet name used
 	file, err := h.excelManager.OpenFile(filePath)
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return shared.ErrorResult(err), nil
 	}
 
 	if sheetName == "" {
 		sheetName, err = h.excelManager.GetCurrentSheet(filePath, file)
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return shared.ErrorResult(err), nil
 		}
 	}
 
@@ -237,33 +301,36 @@ func (h *Handlers) GetRow(ctx context.Context, request mcp.CallToolRequest) (*mc
 func (h *Handlers) GetSheetStats(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	filePath := request.GetString("file_path", "")
 	if filePath == "" {
-		return mcp.NewToolResultError("file_path parameter is required"), nil
+		return shared.ErrorResultf(shared.CodeInvalidArgument, "file_path parameter is required"), nil
+	}
+	if err := h.paths.Check(filePath); err != nil {
+		return shared.ErrorResult(err), nil
 	}
 
 	sheetName := request.GetString("sheet_name", "")
 
 	stats, err := h.excelManager.GetSheetStats(filePath, sheetName)
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return shared.ErrorResult(err), nil
 	}
 
 	// Get the actual sheet name used
 	file, err := h.excelManager.OpenFile(filePath)
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return shared.ErrorResult(err), nil
 	}
 
 	if sheetName == "" {
 		sheetName, err = h.excelManager.GetCurrentSheet(filePath, file)
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return shared.ErrorResult(err), nil
 		}
 	}
 
 	// Format the response as JSON for better readability using optimized marshaling
 	statsJSON, err := shared.OptimizedMarshalIndent(stats, "", "  ")
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to format stats: %v", err)), nil
+		return shared.ErrorResultFromErr("failed to format stats", err), nil
 	}
 
 	return mcp.NewToolResultText(fmt.Sprintf("Sheet '%s' statistics:\n%s", sheetName, string(statsJSON))), nil
@@ -273,7 +340,7 @@ func (h *Handlers) GetSheetStats(ctx context.Context, request mcp.CallToolReques
 func (h *Handlers) FlushCache(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	filesCleared, err := h.excelManager.FlushCache()
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return shared.ErrorResult(err), nil
 	}
 
 	return mcp.NewToolResultText(fmt.Sprintf("Cache flushed successfully. %d files were closed and removed from cache.", filesCleared)), nil
@@ -283,26 +350,178 @@ func (h *Handlers) FlushCache(ctx context.Context, request mcp.CallToolRequest)
 func (h *Handlers) ExplainFormula(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	filePath := request.GetString("file_path", "")
 	if filePath == "" {
-		return mcp.NewToolResultError("file_path parameter is required"), nil
+		return shared.ErrorResultf(shared.CodeInvalidArgument, "file_path parameter is required"), nil
+	}
+	if err := h.paths.Check(filePath); err != nil {
+		return shared.ErrorResult(err), nil
 	}
 
 	cell := request.GetString("cell", "")
 	if cell == "" {
-		return mcp.NewToolResultError("cell parameter is required (e.g., 'A1')"), nil
+		return shared.ErrorResultf(shared.CodeInvalidArgument, "cell parameter is required (e.g., 'A1')"), nil
 	}
 
 	sheetName := request.GetString("sheet_name", "")
 
-	formula, err := h.excelManager.ExplainFormula(filePath, cell, sheetName)
+	formula, err := h.excelManager.ExplainFormula(filePath, cell, sheetName, request.GetBool("summary", false))
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return shared.ErrorResult(err), nil
 	}
 
 	// Format the response as JSON for better readability using optimized marshaling
 	formulaJSON, err := shared.OptimizedMarshalIndent(formula, "", "  ")
 	if err != nil {
-		return mcp.NewToolResultError(fmt.Sprintf("failed to format formula: %v", err)), nil
+		return shared.ErrorResultFromErr("failed to format formula", err), nil
 	}
 
 	return mcp.NewToolResultText(fmt.Sprintf("Formula explanation for cell %s:\n%s", cell, string(formulaJSON))), nil
 }
+
+// formulaPage is one page of a sheet's explained formulas
+type formulaPage struct {
+	Sheet      string        `json:"sheet"`
+	Total      int           `json:"total"`
+	Offset     int           `json:"offset"`
+	Formulas   []FormulaInfo `json:"formulas"`
+	NextOffset int           `json:"next_offset,omitempty"` // Offset of the next page, when there is one
+}
+
+// ExplainFormulasSheet handles the explain_formulas_sheet tool
+func (h *Handlers) ExplainFormulasSheet(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return h.withMiddleware(h.explainFormulasSheetHandler)(ctx, request)
+}
+
+// explainFormulasSheetHandler explains a page of the formulas in a sheet
+func (h *Handlers) explainFormulasSheetHandler(ctx context.Context, hctx *HandlerContext) (*mcp.CallToolResult, error) {
+	offset := hctx.Request.GetInt("offset", 0)
+	limit := hctx.Request.GetInt("limit", 100)
+	if offset < 0 {
+		return shared.ErrorResultf(shared.CodeInvalidArgument, "offset must not be negative"), nil
+	}
+	if limit < 1 || limit > 1000 {
+		return shared.ErrorResultf(shared.CodeInvalidArgument, "limit must be between 1 and 1000"), nil
+	}
+
+	formulas, err := h.excelManager.ExplainFormulasFromSheet(hctx.FilePath, hctx.SheetName)
+	if err != nil {
+		return shared.ErrorResult(err), nil
+	}
+
+	page := formulaPage{Sheet: hctx.SheetName, Total: len(formulas), Offset: offset, Formulas: []FormulaInfo{}}
+	if offset < len(formulas) {
+		end := min(offset+limit, len(formulas))
+		page.Formulas = formulas[offset:end]
+		if end < len(formulas) {
+			page.NextOffset = end
+		}
+	}
+	return NewJSONResponse(page)
+}
+
+// auditPage is one page of a sheet audit's findings
+type auditPage struct {
+	Sheet      string         `json:"sheet"`
+	Formulas   int            `json:"formulas"`
+	Counts     map[string]int `json:"counts"`
+	Total      int            `json:"total"`
+	Offset     int            `json:"offset"`
+	Findings   []AuditFinding `json:"findings"`
+	NextOffset int            `json:"next_offset,omitempty"` // Offset of the next page, when there is one
+}
+
+// AuditSheet handles the audit_sheet tool
+func (h *Handlers) AuditSheet(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return h.withMiddleware(h.auditSheetHandler)(ctx, request)
+}
+
+// auditSheetHandler audits a sheet and returns a page of its findings
+func (h *Handlers) auditSheetHandler(ctx context.Context, hctx *HandlerContext) (*mcp.CallToolResult, error) {
+	offset := hctx.Request.GetInt("offset", 0)
+	limit := hctx.Request.GetInt("limit", 100)
+	if offset < 0 {
+		return shared.ErrorResultf(shared.CodeInvalidArgument, "offset must not be negative"), nil
+	}
+	if limit < 1 || limit > 1000 {
+		return shared.ErrorResultf(shared.CodeInvalidArgument, "limit must be between 1 and 1000"), nil
+	}
+
+	audit, err := h.excelManager.AuditSheet(hctx.FilePath, hctx.SheetName)
+	if err != nil {
+		return shared.ErrorResult(err), nil
+	}
+
+	page := auditPage{Sheet: audit.Sheet, Formulas: audit.Formulas, Counts: audit.Counts, Total: len(audit.Findings), Offset: offset, Findings: []AuditFinding{}}
+	if offset < len(audit.Findings) {
+		end := min(offset+limit, len(audit.Findings))
+		page.Findings = audit.Findings[offset:end]
+		if end < len(audit.Findings) {
+			page.NextOffset = end
+		}
+	}
+	return NewJSONResponse(page)
+}
+
+// GetSheetLayout handles the get_sheet_layout tool
+func (h *Handlers) GetSheetLayout(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return h.withMiddleware(h.getSheetLayoutHandler)(ctx, request)
+}
+
+// getSheetLayoutHandler reports a sheet's panes, print areas and hidden rows and columns
+func (h *Handlers) getSheetLayoutHandler(ctx context.Context, hctx *HandlerContext) (*mcp.CallToolResult, error) {
+	layout, err := hctx.Manager.GetSheetLayout(hctx.FilePath, hctx.SheetName)
+	if err != nil {
+		return shared.ErrorResult(err), nil
+	}
+	return NewJSONResponse(layout)
+}
+
+// GetProtection handles the get_protection tool
+func (h *Handlers) GetProtection(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return h.withMiddlewareNoSheet(h.getProtectionHandler)(ctx, request)
+}
+
+// getProtectionHandler reports workbook and sheet protection, for every sheet unless one is named
+func (h *Handlers) getProtectionHandler(ctx context.Context, hctx *HandlerContext) (*mcp.CallToolResult, error) {
+	report, err := hctx.Manager.GetProtection(hctx.FilePath, hctx.Request.GetString("sheet_name", ""))
+	if err != nil {
+		return shared.ErrorResult(err), nil
+	}
+	return NewJSONResponse(report)
+}
+
+// ExportRangeAsResource handles the export_range_as_resource tool
+func (h *Handlers) ExportRangeAsResource(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return h.withMiddleware(h.exportRangeAsResourceHandler)(ctx, request)
+}
+
+// exportRangeAsResourceHandler publishes range values as a resource instead of inlining them
+func (h *Handlers) exportRangeAsResourceHandler(ctx context.Context, hctx *HandlerContext) (*mcp.CallToolResult, error) {
+	if h.exports == nil {
+		return shared.ErrorResultf(shared.CodeAccessDenied, "resource exports are not enabled on this server"), nil
+	}
+
+	rangeRef, errResult := ValidateRequiredParamWithExample(hctx, "range", "A1:C3")
+	if errResult != nil {
+		return errResult, nil
+	}
+
+	format := ExportFormat(hctx.Request.GetString("format", string(ExportFormatCSV)))
+
+	values, err := hctx.Manager.GetRangeValues(hctx.FilePath, rangeRef, hctx.SheetName, true)
+	if err != nil {
+		return shared.ErrorResult(err), nil
+	}
+
+	export, err := h.exports.publish(hctx.FilePath, hctx.SheetName, rangeRef, values, format)
+	if err != nil {
+		return shared.ErrorResult(err), nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			mcp.NewTextContent(fmt.Sprintf("Exported range %s from sheet %s (%d rows) as resource %s (%s). Read the resource to fetch the data.",
+				rangeRef, hctx.SheetName, export.Rows, export.URI, export.MIMEType)),
+			mcp.NewResourceLink(export.URI, export.Name, "Exported Excel range", export.MIMEType),
+		},
+	}, nil
+}
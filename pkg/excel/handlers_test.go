@@ -2,9 +2,13 @@ package excel
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/kevsmith/my-mcp/pkg/shared"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/xuri/excelize/v2"
 )
@@ -486,3 +490,91 @@ func TestGetRow(t *testing.T) {
 		t.Error("Expected error for non-existent row number")
 	}
 }
+
+func TestExplainFormulasSheet(t *testing.T) {
+	file := excelize.NewFile()
+	file.SetCellValue("Sheet1", "A1", "Price")
+	file.SetCellValue("Sheet1", "B1", "Total")
+	for row := 2; row <= 6; row++ {
+		file.SetCellValue("Sheet1", fmt.Sprintf("A%d", row), row*10)
+		file.SetCellFormula("Sheet1", fmt.Sprintf("B%d", row), fmt.Sprintf("A%d*2", row))
+	}
+	filePath := filepath.Join(t.TempDir(), "formulas.xlsx")
+	if err := file.SaveAs(filePath); err != nil {
+		t.Fatalf("Failed to save test file: %v", err)
+	}
+	file.Close()
+
+	handlers := NewHandlers(NewManager())
+	explain := func(arguments map[string]interface{}) (formulaPage, *mcp.CallToolResult) {
+		t.Helper()
+		arguments["file_path"] = filePath
+		result, err := handlers.ExplainFormulasSheet(context.Background(), mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: arguments}})
+		if err != nil {
+			t.Fatalf("ExplainFormulasSheet failed: %v", err)
+		}
+		var page formulaPage
+		if !result.IsError {
+			if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &page); err != nil {
+				t.Fatalf("Result is not a formula page: %v", err)
+			}
+		}
+		return page, result
+	}
+
+	page, _ := explain(map[string]interface{}{"limit": float64(2)})
+	if page.Sheet != "Sheet1" || page.Total != 5 || len(page.Formulas) != 2 || page.NextOffset != 2 {
+		t.Errorf("Unexpected first page: %+v", page)
+	}
+	if page.Formulas[0].Cell != "B2" || page.Formulas[0].Formula != "A2*2" {
+		t.Errorf("Unexpected first formula: %+v", page.Formulas[0])
+	}
+
+	page, _ = explain(map[string]interface{}{"offset": float64(4), "limit": float64(2)})
+	if len(page.Formulas) != 1 || page.Formulas[0].Cell != "B6" || page.NextOffset != 0 {
+		t.Errorf("Unexpected last page: %+v", page)
+	}
+
+	page, _ = explain(map[string]interface{}{"offset": float64(10)})
+	if page.Total != 5 || len(page.Formulas) != 0 {
+		t.Errorf("Expected an empty page past the end, got %+v", page)
+	}
+
+	if _, result := explain(map[string]interface{}{"limit": float64(0)}); !result.IsError {
+		t.Error("Expected an error for a zero limit")
+	}
+}
+
+func TestHandlersWithRoots(t *testing.T) {
+	filePath := createTestExcelFileForHandlers(t)
+	outside := createTestExcelFileForHandlers(t)
+
+	handlers, err := NewHandlersWithRoots(NewManager(), []string{filepath.Dir(filePath)})
+	if err != nil {
+		t.Fatalf("NewHandlersWithRoots failed: %v", err)
+	}
+	defer handlers.excelManager.Close()
+
+	calls := map[string]func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error){
+		"list_sheets":       handlers.ListSheets,
+		"get_cell_value":    handlers.GetCellValue,
+		"enumerate_columns": handlers.EnumerateColumns,
+		"get_sheet_stats":   handlers.GetSheetStats,
+	}
+	for name, call := range calls {
+		request := func(path string) mcp.CallToolRequest {
+			return mcp.CallToolRequest{Params: mcp.CallToolParams{Arguments: map[string]interface{}{"file_path": path, "cell": "A1"}}}
+		}
+		if result, _ := call(context.Background(), request(filePath)); result.IsError {
+			t.Errorf("%s: expected a workbook under the root to open, got %s", name, result.Content[0].(mcp.TextContent).Text)
+		}
+		result, _ := call(context.Background(), request(outside))
+		if !result.IsError || !strings.Contains(result.Content[0].(mcp.TextContent).Text, shared.CodeAccessDenied) {
+			t.Errorf("%s: expected ACCESS_DENIED outside the roots, got %+v", name, result)
+		}
+	}
+
+	if _, err := NewHandlersWithRoots(NewManager(), []string{filepath.Join(t.TempDir(), "missing")}); err == nil {
+		t.Error("Expected an error for a missing root")
+	}
+}
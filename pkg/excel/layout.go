@@ -0,0 +1,146 @@
+package excel
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ColumnWidth is the width of a column in characters
+type ColumnWidth struct {
+	Column string  `json:"column"`
+	Width  float64 `json:"width"`
+}
+
+// SheetLayout describes how a sheet is laid out for people rather than what
+// it holds. Reads skip hidden rows and columns unless asked to include them,
+// so this is where to find what they left out.
+type SheetLayout struct {
+	Sheet         string        `json:"sheet"`
+	Hidden        bool          `json:"hidden"`              // The sheet itself is hidden
+	Dimension     string        `json:"dimension,omitempty"` // Used range, e.g. A1:F20
+	FrozenRows    int           `json:"frozen_rows"`
+	FrozenColumns int           `json:"frozen_columns"`
+	PrintAreas    []string      `json:"print_areas"`
+	PrintTitles   string        `json:"print_titles,omitempty"` // Rows or columns repeated on every printed page
+	HiddenRows    []int         `json:"hidden_rows"`
+	HiddenColumns []string      `json:"hidden_columns"`
+	ColumnWidths  []ColumnWidth `json:"column_widths"` // Columns of the used range
+}
+
+// GetSheetLayout returns a sheet's frozen panes, print areas, hidden rows and
+// columns, and column widths
+func (m *Manager) GetSheetLayout(filePath, sheetName string) (*SheetLayout, error) {
+	file, err := m.OpenFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	if sheetName == "" {
+		sheetName, err = m.GetCurrentSheet(filePath, file)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	layout := &SheetLayout{Sheet: sheetName, PrintAreas: []string{}, HiddenRows: []int{}, HiddenColumns: []string{}, ColumnWidths: []ColumnWidth{}}
+
+	visible, err := file.GetSheetVisible(sheetName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sheet visibility: %w", err)
+	}
+	layout.Hidden = !visible
+
+	panes, err := file.GetPanes(sheetName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get panes: %w", err)
+	}
+	if panes.Freeze {
+		layout.FrozenRows, layout.FrozenColumns = panes.YSplit, panes.XSplit
+	}
+
+	for _, name := range file.GetDefinedName() {
+		if !strings.EqualFold(name.Scope, sheetName) {
+			continue
+		}
+		switch name.Name {
+		case "_xlnm.Print_Area":
+			layout.PrintAreas = append(layout.PrintAreas, strings.Split(name.RefersTo, ",")...)
+		case "_xlnm.Print_Titles":
+			layout.PrintTitles = name.RefersTo
+		}
+	}
+
+	// The stored dimension is often stale, so the used range comes from the rows
+	rows, err := file.GetRows(sheetName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows: %w", err)
+	}
+	lastRow, lastCol := len(rows), 0
+	for _, row := range rows {
+		lastCol = max(lastCol, len(row))
+	}
+	if lastRow > 0 && lastCol > 0 {
+		end, _ := excelize.CoordinatesToCellName(lastCol, lastRow)
+		layout.Dimension = "A1:" + end
+	}
+
+	hidden := newVisibility(file, sheetName)
+	for row := 1; row <= lastRow; row++ {
+		if hidden.row(row) {
+			layout.HiddenRows = append(layout.HiddenRows, row)
+		}
+	}
+	for col := 1; col <= lastCol; col++ {
+		name, err := excelize.ColumnNumberToName(col)
+		if err != nil {
+			continue
+		}
+		if hidden.column(col) {
+			layout.HiddenColumns = append(layout.HiddenColumns, name)
+		}
+		if width, err := file.GetColWidth(sheetName, name); err == nil {
+			layout.ColumnWidths = append(layout.ColumnWidths, ColumnWidth{Column: name, Width: width})
+		}
+	}
+
+	return layout, nil
+}
+
+// visibility reports which rows and columns of a sheet are hidden, looking
+// each one up once
+type visibility struct {
+	file    *excelize.File
+	sheet   string
+	rows    map[int]bool
+	columns map[int]bool
+}
+
+func newVisibility(file *excelize.File, sheet string) *visibility {
+	return &visibility{file: file, sheet: sheet, rows: make(map[int]bool), columns: make(map[int]bool)}
+}
+
+// row reports whether a row is hidden
+func (v *visibility) row(row int) bool {
+	hidden, ok := v.rows[row]
+	if !ok {
+		visible, err := v.file.GetRowVisible(v.sheet, row)
+		hidden = err == nil && !visible
+		v.rows[row] = hidden
+	}
+	return hidden
+}
+
+// column reports whether a column is hidden
+func (v *visibility) column(col int) bool {
+	hidden, ok := v.columns[col]
+	if !ok {
+		if name, err := excelize.ColumnNumberToName(col); err == nil {
+			visible, err := v.file.GetColVisible(v.sheet, name)
+			hidden = err == nil && !visible
+		}
+		v.columns[col] = hidden
+	}
+	return hidden
+}
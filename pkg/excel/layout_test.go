@@ -0,0 +1,103 @@
+package excel
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestGetSheetLayout(t *testing.T) {
+	f := excelize.NewFile()
+	for row := 1; row <= 6; row++ {
+		for _, col := range []string{"A", "B", "C", "D"} {
+			f.SetCellValue("Sheet1", col+string(rune('0'+row)), row)
+		}
+	}
+	if err := f.SetPanes("Sheet1", &excelize.Panes{Freeze: true, XSplit: 1, YSplit: 2, TopLeftCell: "B3", ActivePane: "bottomRight"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.SetDefinedName(&excelize.DefinedName{Name: "_xlnm.Print_Area", RefersTo: "Sheet1!$A$1:$D$6", Scope: "Sheet1"}); err != nil {
+		t.Fatal(err)
+	}
+	f.SetRowVisible("Sheet1", 4, false)
+	f.SetColVisible("Sheet1", "C", false)
+	f.SetColWidth("Sheet1", "B", "B", 24)
+	path := filepath.Join(t.TempDir(), "layout.xlsx")
+	if err := f.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	manager := NewManager()
+	defer manager.Close()
+	layout, err := manager.GetSheetLayout(path, "")
+	if err != nil {
+		t.Fatalf("GetSheetLayout failed: %v", err)
+	}
+
+	if layout.Sheet != "Sheet1" || layout.Hidden || layout.Dimension != "A1:D6" {
+		t.Errorf("Unexpected sheet details: %+v", layout)
+	}
+	if layout.FrozenRows != 2 || layout.FrozenColumns != 1 {
+		t.Errorf("Frozen %d rows and %d columns, want 2 and 1", layout.FrozenRows, layout.FrozenColumns)
+	}
+	if !slices.Equal(layout.PrintAreas, []string{"Sheet1!$A$1:$D$6"}) {
+		t.Errorf("Print areas = %v", layout.PrintAreas)
+	}
+	if !slices.Equal(layout.HiddenRows, []int{4}) || !slices.Equal(layout.HiddenColumns, []string{"C"}) {
+		t.Errorf("Hidden rows %v and columns %v, want [4] and [C]", layout.HiddenRows, layout.HiddenColumns)
+	}
+	if len(layout.ColumnWidths) != 4 || layout.ColumnWidths[1] != (ColumnWidth{Column: "B", Width: 24}) {
+		t.Errorf("Column widths = %+v", layout.ColumnWidths)
+	}
+}
+
+func TestReadsSkipHidden(t *testing.T) {
+	f := excelize.NewFile()
+	f.SetSheetRow("Sheet1", "A1", &[]any{"Name", "Internal", "Amount"})
+	f.SetSheetRow("Sheet1", "A2", &[]any{"North", "x", 10})
+	f.SetSheetRow("Sheet1", "A3", &[]any{"Check", "y", 99})
+	f.SetSheetRow("Sheet1", "A4", &[]any{"South", "z", 20})
+	f.SetRowVisible("Sheet1", 3, false)
+	f.SetColVisible("Sheet1", "B", false)
+	path := filepath.Join(t.TempDir(), "hidden.xlsx")
+	if err := f.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	manager := NewManager()
+	defer manager.Close()
+
+	column, err := manager.GetColumnValues(path, "C", "", false)
+	if err != nil || !slices.Equal(column, []string{"Amount", "10", "20"}) {
+		t.Errorf("GetColumnValues = %v, %v, want hidden row 3 skipped", column, err)
+	}
+	if column, _ := manager.GetColumnValues(path, "C", "", true); len(column) != 4 {
+		t.Errorf("GetColumnValues with hidden = %v, want 4 values", column)
+	}
+	if _, err := manager.GetColumnValues(path, "B", "", false); err == nil {
+		t.Error("Expected an error reading hidden column B")
+	}
+
+	row, err := manager.GetRowValues(path, 2, "", false)
+	if err != nil || !slices.Equal(row, []string{"North", "10"}) {
+		t.Errorf("GetRowValues = %v, %v, want hidden column B skipped", row, err)
+	}
+	if _, err := manager.GetRowValues(path, 3, "", false); err == nil {
+		t.Error("Expected an error reading hidden row 3")
+	}
+	if row, _ := manager.GetRowValues(path, 3, "", true); !slices.Equal(row, []string{"Check", "y", "99"}) {
+		t.Errorf("GetRowValues with hidden = %v", row)
+	}
+
+	values, err := manager.GetRangeValues(path, "A1:C4", "", false)
+	if err != nil || len(values) != 3 || !slices.Equal(values[2], []string{"South", "20"}) {
+		t.Errorf("GetRangeValues = %v, %v, want 3 rows of 2 values", values, err)
+	}
+	if values, _ := manager.GetRangeValues(path, "A1:C4", "", true); len(values) != 4 || len(values[0]) != 3 {
+		t.Errorf("GetRangeValues with hidden = %v", values)
+	}
+}
@@ -6,14 +6,16 @@ import (
 	"strings"
 	"time"
 
+	"github.com/kevsmith/my-mcp/pkg/shared"
 	"github.com/xuri/excelize/v2"
 )
 
 // Manager handles Excel file operations and maintains file state
 type Manager struct {
-	cache         *FileCache
-	currentSheet  map[string]string
-	cleanupTicker *time.Ticker
+	cache        *FileCache
+	currentSheet map[string]string
+	openStrategy map[string]OpenStrategy
+	stopCleanup  func()
 }
 
 // NewManager creates a new Excel file manager
@@ -24,10 +26,11 @@ func NewManager() *Manager {
 	manager := &Manager{
 		cache:        cache,
 		currentSheet: make(map[string]string),
+		openStrategy: make(map[string]OpenStrategy),
 	}
 
 	// Start cleanup ticker to remove expired entries every minute
-	manager.cleanupTicker = cache.StartCleanupTicker(time.Minute)
+	manager.stopCleanup = cache.StartCleanupTicker(time.Minute)
 
 	return manager
 }
@@ -39,24 +42,33 @@ func NewManagerWithConfig(config CacheConfig) *Manager {
 	manager := &Manager{
 		cache:        cache,
 		currentSheet: make(map[string]string),
+		openStrategy: make(map[string]OpenStrategy),
 	}
 
 	// Start cleanup ticker to remove expired entries every minute
-	manager.cleanupTicker = cache.StartCleanupTicker(time.Minute)
+	manager.stopCleanup = cache.StartCleanupTicker(time.Minute)
 
 	return manager
 }
 
 // Close closes the manager and cleans up resources
 func (m *Manager) Close() {
-	if m.cleanupTicker != nil {
-		m.cleanupTicker.Stop()
+	if m.stopCleanup != nil {
+		m.stopCleanup()
 	}
 	if m.cache != nil {
 		m.cache.Clear()
 	}
 }
 
+// CacheStats returns the file cache's hit and miss counts and size
+func (m *Manager) CacheStats() shared.CacheStats {
+	if m.cache == nil {
+		return shared.CacheStats{}
+	}
+	return m.cache.Stats()
+}
+
 // FlushCache flushes the file cache and returns cache statistics
 func (m *Manager) FlushCache() (int, error) {
 	if m.cache == nil {
@@ -69,8 +81,9 @@ func (m *Manager) FlushCache() (int, error) {
 	// Clear the cache (closes all files)
 	m.cache.Clear()
 
-	// Also clear current sheet mappings since files are closed
+	// Also clear current sheet mappings and open strategies since files are closed
 	m.currentSheet = make(map[string]string)
+	m.openStrategy = make(map[string]OpenStrategy)
 
 	return cacheSize, nil
 }
@@ -79,7 +92,7 @@ func (m *Manager) FlushCache() (int, error) {
 func (m *Manager) ExplainFormulas(filePath string) ([]FormulaInfo, error) {
 	file, err := m.OpenFile(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open file: %v", err)
+		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
 
 	extractor := NewFormulaExtractor(file)
@@ -90,18 +103,19 @@ func (m *Manager) ExplainFormulas(filePath string) ([]FormulaInfo, error) {
 func (m *Manager) ExplainFormulasFromSheet(filePath, sheetName string) ([]FormulaInfo, error) {
 	file, err := m.OpenFile(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open file: %v", err)
+		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
 
 	extractor := NewFormulaExtractor(file)
 	return extractor.ExtractFormulasFromSheet(sheetName)
 }
 
-// ExplainFormula extracts and explains a formula from a specific cell
-func (m *Manager) ExplainFormula(filePath, cell, sheetName string) (*FormulaInfo, error) {
+// ExplainFormula extracts and explains a formula from a specific cell. With
+// summarize it also describes what the formula does in plain English.
+func (m *Manager) ExplainFormula(filePath, cell, sheetName string, summarize bool) (*FormulaInfo, error) {
 	file, err := m.OpenFile(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open file: %v", err)
+		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
 
 	if sheetName == "" {
@@ -114,7 +128,7 @@ func (m *Manager) ExplainFormula(filePath, cell, sheetName string) (*FormulaInfo
 	// Get the formula from the cell
 	formula, err := file.GetCellFormula(sheetName, cell)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get cell formula: %v", err)
+		return nil, fmt.Errorf("failed to get cell formula: %w", err)
 	}
 
 	if formula == "" {
@@ -132,34 +146,45 @@ func (m *Manager) ExplainFormula(filePath, cell, sheetName string) (*FormulaInfo
 	translatedFormula := extractor.translateFormula(sheetName, formula)
 	label := extractor.getCellLabel(sheetName, cell)
 
-	return &FormulaInfo{
+	info := &FormulaInfo{
 		Sheet:             sheetName,
 		Cell:              cell,
 		Formula:           formula,
 		Value:             value,
 		TranslatedFormula: translatedFormula,
 		Label:             label,
-	}, nil
+	}
+	if summarize {
+		info.Summary, info.Steps = extractor.summarizeFormula(sheetName, formula)
+	}
+	return info, nil
 }
 
-// OpenFile opens an Excel file and caches it for future operations
+// OpenFile opens an Excel file read-only and caches it for future operations.
+// Files locked by another process are read from a temporary copy instead.
 func (m *Manager) OpenFile(filePath string) (*excelize.File, error) {
 	// Try to get from cache first
 	if file, found := m.cache.Get(filePath); found {
 		return file, nil
 	}
 
-	// Open the file
-	file, err := excelize.OpenFile(filePath)
+	// Open the file without holding a handle that could conflict with Excel
+	file, strategy, err := openWorkbook(filePath)
 	if err != nil {
 		return nil, err
 	}
 
 	// Store in cache
 	m.cache.Put(filePath, file)
+	m.openStrategy[filePath] = strategy
 	return file, nil
 }
 
+// GetOpenStrategy returns how a file was last opened, or an empty strategy if it was never opened
+func (m *Manager) GetOpenStrategy(filePath string) OpenStrategy {
+	return m.openStrategy[filePath]
+}
+
 // GetCurrentSheet returns the current sheet for a file, or the first sheet if none is set
 func (m *Manager) GetCurrentSheet(filePath string, file *excelize.File) (string, error) {
 	if currentSheet, exists := m.currentSheet[filePath]; exists {
@@ -178,7 +203,7 @@ func (m *Manager) GetCurrentSheet(filePath string, file *excelize.File) (string,
 func (m *Manager) SetCurrentSheet(filePath, sheetName string) error {
 	file, err := m.OpenFile(filePath)
 	if err != nil {
-		return fmt.Errorf("failed to open file: %v", err)
+		return fmt.Errorf("failed to open file: %w", err)
 	}
 
 	sheets := file.GetSheetList()
@@ -191,7 +216,7 @@ func (m *Manager) SetCurrentSheet(filePath, sheetName string) error {
 	}
 
 	if !found {
-		return fmt.Errorf("sheet '%s' not found. Available sheets: %v", sheetName, sheets)
+		return shared.NewError(shared.CodeNotFound, "sheet '%s' not found. Available sheets: %v", sheetName, sheets)
 	}
 
 	m.currentSheet[filePath] = sheetName
@@ -202,7 +227,7 @@ func (m *Manager) SetCurrentSheet(filePath, sheetName string) error {
 func (m *Manager) GetColumns(filePath, sheetName string) ([]string, error) {
 	file, err := m.OpenFile(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open file: %v", err)
+		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
 
 	if sheetName == "" {
@@ -214,7 +239,7 @@ func (m *Manager) GetColumns(filePath, sheetName string) ([]string, error) {
 
 	rows, err := file.GetRows(sheetName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get rows: %v", err)
+		return nil, fmt.Errorf("failed to get rows: %w", err)
 	}
 
 	if len(rows) == 0 {
@@ -234,7 +259,7 @@ func (m *Manager) GetColumns(filePath, sheetName string) ([]string, error) {
 func (m *Manager) GetRowCount(filePath, sheetName string) (int, error) {
 	file, err := m.OpenFile(filePath)
 	if err != nil {
-		return 0, fmt.Errorf("failed to open file: %v", err)
+		return 0, fmt.Errorf("failed to open file: %w", err)
 	}
 
 	if sheetName == "" {
@@ -246,7 +271,7 @@ func (m *Manager) GetRowCount(filePath, sheetName string) (int, error) {
 
 	rows, err := file.GetRows(sheetName)
 	if err != nil {
-		return 0, fmt.Errorf("failed to get rows: %v", err)
+		return 0, fmt.Errorf("failed to get rows: %w", err)
 	}
 
 	return len(rows), nil
@@ -256,7 +281,7 @@ func (m *Manager) GetRowCount(filePath, sheetName string) (int, error) {
 func (m *Manager) GetCellValue(filePath, cell, sheetName string) (string, error) {
 	file, err := m.OpenFile(filePath)
 	if err != nil {
-		return "", fmt.Errorf("failed to open file: %v", err)
+		return "", fmt.Errorf("failed to open file: %w", err)
 	}
 
 	if sheetName == "" {
@@ -268,17 +293,18 @@ func (m *Manager) GetCellValue(filePath, cell, sheetName string) (string, error)
 
 	value, err := file.GetCellValue(sheetName, cell)
 	if err != nil {
-		return "", fmt.Errorf("failed to get cell value: %v", err)
+		return "", fmt.Errorf("failed to get cell value: %w", err)
 	}
 
 	return value, nil
 }
 
-// GetRangeValues returns values from a range of cells
-func (m *Manager) GetRangeValues(filePath, rangeRef, sheetName string) ([][]string, error) {
+// GetRangeValues returns values from a range of cells. Hidden rows and
+// columns are skipped unless includeHidden is set.
+func (m *Manager) GetRangeValues(filePath, rangeRef, sheetName string, includeHidden bool) ([][]string, error) {
 	file, err := m.OpenFile(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open file: %v", err)
+		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
 
 	if sheetName == "" {
@@ -288,19 +314,9 @@ func (m *Manager) GetRangeValues(filePath, rangeRef, sheetName string) ([][]stri
 		}
 	}
 
-	rangeParts := strings.Split(rangeRef, ":")
-	if len(rangeParts) != 2 {
-		return nil, fmt.Errorf("invalid range format, expected 'A1:C3'")
-	}
-
-	startCol, startRow, err := excelize.CellNameToCoordinates(rangeParts[0])
+	startCol, startRow, endCol, endRow, err := parseRangeRef(rangeRef)
 	if err != nil {
-		return nil, fmt.Errorf("invalid start cell: %v", err)
-	}
-
-	endCol, endRow, err := excelize.CellNameToCoordinates(rangeParts[1])
-	if err != nil {
-		return nil, fmt.Errorf("invalid end cell: %v", err)
+		return nil, err
 	}
 
 	// Pre-allocate slices with known capacity for performance
@@ -308,9 +324,16 @@ func (m *Manager) GetRangeValues(filePath, rangeRef, sheetName string) ([][]stri
 	colCount := endCol - startCol + 1
 	values := make([][]string, 0, rowCount)
 
+	hidden := newVisibility(file, sheetName)
 	for row := startRow; row <= endRow; row++ {
+		if !includeHidden && hidden.row(row) {
+			continue
+		}
 		rowValues := make([]string, 0, colCount)
 		for col := startCol; col <= endCol; col++ {
+			if !includeHidden && hidden.column(col) {
+				continue
+			}
 			cellName, _ := excelize.CoordinatesToCellName(col, row)
 			value, _ := file.GetCellValue(sheetName, cellName)
 			rowValues = append(rowValues, value)
@@ -321,11 +344,47 @@ func (m *Manager) GetRangeValues(filePath, rangeRef, sheetName string) ([][]stri
 	return values, nil
 }
 
+// maxRangeCells bounds the number of cells a single range request may cover
+const maxRangeCells = 1_000_000
+
+// parseRangeRef parses a range reference like "A1:C3" into normalized coordinates.
+// Reversed ranges ("C3:A1") are normalized so that start <= end, and ranges
+// larger than maxRangeCells are rejected before any allocation happens.
+func parseRangeRef(rangeRef string) (startCol, startRow, endCol, endRow int, err error) {
+	rangeParts := strings.Split(strings.TrimSpace(rangeRef), ":")
+	if len(rangeParts) != 2 {
+		return 0, 0, 0, 0, shared.NewError(shared.CodeInvalidArgument, "invalid range format, expected 'A1:C3'")
+	}
+
+	startCol, startRow, err = excelize.CellNameToCoordinates(strings.TrimSpace(rangeParts[0]))
+	if err != nil {
+		return 0, 0, 0, 0, shared.NewError(shared.CodeInvalidArgument, "invalid start cell: %v", err)
+	}
+
+	endCol, endRow, err = excelize.CellNameToCoordinates(strings.TrimSpace(rangeParts[1]))
+	if err != nil {
+		return 0, 0, 0, 0, shared.NewError(shared.CodeInvalidArgument, "invalid end cell: %v", err)
+	}
+
+	if startCol > endCol {
+		startCol, endCol = endCol, startCol
+	}
+	if startRow > endRow {
+		startRow, endRow = endRow, startRow
+	}
+
+	if cells := (endCol - startCol + 1) * (endRow - startRow + 1); cells > maxRangeCells {
+		return 0, 0, 0, 0, fmt.Errorf("range %s covers %d cells, maximum is %d", rangeRef, cells, maxRangeCells)
+	}
+
+	return startCol, startRow, endCol, endRow, nil
+}
+
 // GetSheetList returns all available sheets in a file
 func (m *Manager) GetSheetList(filePath string) ([]string, error) {
 	file, err := m.OpenFile(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open file: %v", err)
+		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
 
 	sheets := file.GetSheetList()
@@ -336,11 +395,13 @@ func (m *Manager) GetSheetList(filePath string) ([]string, error) {
 	return sheets, nil
 }
 
-// GetColumnValues returns all values in a specific column
-func (m *Manager) GetColumnValues(filePath, column, sheetName string) ([]string, error) {
+// GetColumnValues returns all values in a specific column. Values in hidden
+// rows are skipped, and a hidden column can't be read, unless includeHidden
+// is set.
+func (m *Manager) GetColumnValues(filePath, column, sheetName string, includeHidden bool) ([]string, error) {
 	file, err := m.OpenFile(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open file: %v", err)
+		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
 
 	if sheetName == "" {
@@ -352,17 +413,25 @@ func (m *Manager) GetColumnValues(filePath, column, sheetName string) ([]string,
 
 	colNum, err := excelize.ColumnNameToNumber(column)
 	if err != nil {
-		return nil, fmt.Errorf("invalid column name '%s': %v", column, err)
+		return nil, shared.NewError(shared.CodeInvalidArgument, "invalid column name '%s': %v", column, err)
+	}
+
+	hidden := newVisibility(file, sheetName)
+	if !includeHidden && hidden.column(colNum) {
+		return nil, shared.NewError(shared.CodeInvalidArgument, "column %s is hidden in sheet %s; set include_hidden to read it", column, sheetName)
 	}
 
 	rows, err := file.GetRows(sheetName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get rows: %v", err)
+		return nil, fmt.Errorf("failed to get rows: %w", err)
 	}
 
 	// Pre-allocate slice with known capacity
 	values := make([]string, 0, len(rows))
-	for _, row := range rows {
+	for i, row := range rows {
+		if !includeHidden && hidden.row(i+1) {
+			continue
+		}
 		if colNum <= len(row) {
 			values = append(values, row[colNum-1])
 		} else {
@@ -373,11 +442,13 @@ func (m *Manager) GetColumnValues(filePath, column, sheetName string) ([]string,
 	return values, nil
 }
 
-// GetRowValues returns all values in a specific row
-func (m *Manager) GetRowValues(filePath string, rowNum int, sheetName string) ([]string, error) {
+// GetRowValues returns all values in a specific row. Values in hidden
+// columns are skipped, and a hidden row can't be read, unless includeHidden
+// is set.
+func (m *Manager) GetRowValues(filePath string, rowNum int, sheetName string, includeHidden bool) ([]string, error) {
 	file, err := m.OpenFile(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open file: %v", err)
+		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
 
 	if sheetName == "" {
@@ -393,14 +464,27 @@ func (m *Manager) GetRowValues(filePath string, rowNum int, sheetName string) ([
 
 	rows, err := file.GetRows(sheetName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get rows: %v", err)
+		return nil, fmt.Errorf("failed to get rows: %w", err)
 	}
 
 	if rowNum > len(rows) {
 		return nil, fmt.Errorf("row %d does not exist (sheet has %d rows)", rowNum, len(rows))
 	}
+	if includeHidden {
+		return rows[rowNum-1], nil
+	}
 
-	return rows[rowNum-1], nil
+	hidden := newVisibility(file, sheetName)
+	if hidden.row(rowNum) {
+		return nil, shared.NewError(shared.CodeInvalidArgument, "row %d is hidden in sheet %s; set include_hidden to read it", rowNum, sheetName)
+	}
+	values := make([]string, 0, len(rows[rowNum-1]))
+	for i, value := range rows[rowNum-1] {
+		if !hidden.column(i + 1) {
+			values = append(values, value)
+		}
+	}
+	return values, nil
 }
 
 // SheetStats represents statistical information about an Excel sheet
@@ -420,7 +504,7 @@ type SheetStats struct {
 func (m *Manager) GetSheetStats(filePath, sheetName string) (*SheetStats, error) {
 	file, err := m.OpenFile(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open file: %v", err)
+		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
 
 	if sheetName == "" {
@@ -432,7 +516,7 @@ func (m *Manager) GetSheetStats(filePath, sheetName string) (*SheetStats, error)
 
 	rows, err := file.GetRows(sheetName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get rows: %v", err)
+		return nil, fmt.Errorf("failed to get rows: %w", err)
 	}
 
 	stats := &SheetStats{
@@ -55,7 +55,7 @@ func TestNewManager(t *testing.T) {
 	if manager.currentSheet == nil {
 		t.Error("currentSheet map not initialized")
 	}
-	if manager.cleanupTicker == nil {
+	if manager.stopCleanup == nil {
 		t.Error("cleanup ticker not started")
 	}
 
@@ -187,7 +187,7 @@ func TestGetRangeValues(t *testing.T) {
 	manager := NewManager()
 	filePath := createTestExcelFile(t)
 
-	values, err := manager.GetRangeValues(filePath, "A1:C2", "Sheet1")
+	values, err := manager.GetRangeValues(filePath, "A1:C2", "Sheet1", false)
 	if err != nil {
 		t.Fatalf("Failed to get range values: %v", err)
 	}
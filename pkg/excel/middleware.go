@@ -32,13 +32,16 @@ func (h *Handlers) withMiddleware(handler HandlerFunc) func(context.Context, mcp
 		// Validate and extract file path
 		hctx.FilePath = request.GetString("file_path", "")
 		if hctx.FilePath == "" {
-			return mcp.NewToolResultError("file_path parameter is required"), nil
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "file_path parameter is required"), nil
+		}
+		if err := h.paths.Check(hctx.FilePath); err != nil {
+			return shared.ErrorResult(err), nil
 		}
 
 		// Open file once for reuse
 		file, err := h.excelManager.OpenFile(hctx.FilePath)
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return shared.ErrorResult(err), nil
 		}
 		hctx.File = file
 
@@ -47,7 +50,7 @@ func (h *Handlers) withMiddleware(handler HandlerFunc) func(context.Context, mcp
 		if hctx.SheetName == "" {
 			resolvedSheet, err := h.excelManager.GetCurrentSheet(hctx.FilePath, file)
 			if err != nil {
-				return mcp.NewToolResultError(err.Error()), nil
+				return shared.ErrorResult(err), nil
 			}
 			hctx.SheetName = resolvedSheet
 		}
@@ -69,13 +72,16 @@ func (h *Handlers) withMiddlewareNoSheet(handler HandlerFunc) func(context.Conte
 		// Validate and extract file path
 		hctx.FilePath = request.GetString("file_path", "")
 		if hctx.FilePath == "" {
-			return mcp.NewToolResultError("file_path parameter is required"), nil
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "file_path parameter is required"), nil
+		}
+		if err := h.paths.Check(hctx.FilePath); err != nil {
+			return shared.ErrorResult(err), nil
 		}
 
 		// Open file once for reuse
 		file, err := h.excelManager.OpenFile(hctx.FilePath)
 		if err != nil {
-			return mcp.NewToolResultError(err.Error()), nil
+			return shared.ErrorResult(err), nil
 		}
 		hctx.File = file
 
@@ -101,7 +107,7 @@ func NewFormattedTextResponse(format string, args ...interface{}) (*mcp.CallTool
 func ValidateRequiredParam(hctx *HandlerContext, paramName string) (string, *mcp.CallToolResult) {
 	value := hctx.Request.GetString(paramName, "")
 	if value == "" {
-		return "", mcp.NewToolResultError(paramName + " parameter is required")
+		return "", shared.ErrorResultf(shared.CodeInvalidArgument, "%s parameter is required", paramName)
 	}
 	return value, nil
 }
@@ -110,7 +116,7 @@ func ValidateRequiredParam(hctx *HandlerContext, paramName string) (string, *mcp
 func ValidateRequiredParamWithExample(hctx *HandlerContext, paramName, example string) (string, *mcp.CallToolResult) {
 	value := hctx.Request.GetString(paramName, "")
 	if value == "" {
-		return "", mcp.NewToolResultError(paramName + " parameter is required (e.g., '" + example + "')")
+		return "", shared.ErrorResultf(shared.CodeInvalidArgument, "%s parameter is required (e.g., '%s')", paramName, example)
 	}
 	return value, nil
 }
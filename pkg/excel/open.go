@@ -0,0 +1,116 @@
+package excel
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// OpenStrategy describes how a workbook was read from disk
+type OpenStrategy string
+
+const (
+	// OpenStrategyReadOnly reads the workbook directly through a read-only, fully shared handle
+	OpenStrategyReadOnly OpenStrategy = "read-only"
+	// OpenStrategyTempCopy reads a temporary copy because the original is locked by another process
+	OpenStrategyTempCopy OpenStrategy = "temp-copy"
+)
+
+// Constants for lock avoidance when copying locked workbooks
+const (
+	tempCopyAttempts = 3                      // Attempts to copy a locked workbook
+	tempCopyBackoff  = 200 * time.Millisecond // Delay between copy attempts, doubled each retry
+)
+
+// openWorkbook opens a workbook without ever holding a write handle on it.
+// The file is read fully into memory and the handle released immediately, so
+// Excel (or any other process) can keep editing and saving the original. If the
+// file cannot be read because another process holds a conflicting lock, the
+// workbook is copied to a temporary location and read from there instead.
+func openWorkbook(filePath string) (*excelize.File, OpenStrategy, error) {
+	file, err := openWorkbookReadOnly(filePath)
+	if err == nil {
+		return file, OpenStrategyReadOnly, nil
+	}
+	if !isSharingViolation(err) {
+		return nil, "", err
+	}
+
+	file, copyErr := openWorkbookViaTempCopy(filePath)
+	if copyErr != nil {
+		return nil, "", fmt.Errorf("file is locked by another process and could not be copied: %w", copyErr)
+	}
+	return file, OpenStrategyTempCopy, nil
+}
+
+// openWorkbookReadOnly reads a workbook through a read-only handle
+func openWorkbookReadOnly(filePath string) (*excelize.File, error) {
+	handle, err := openSharedReadOnly(filepath.Clean(filePath))
+	if err != nil {
+		return nil, err
+	}
+	defer handle.Close()
+
+	file, err := excelize.OpenReader(handle)
+	if err != nil {
+		return nil, err
+	}
+	file.Path = filePath
+	return file, nil
+}
+
+// openWorkbookViaTempCopy copies a locked workbook to a temporary file and reads the copy.
+// Lock conflicts are usually transient (Excel locks byte ranges while saving), so the
+// copy is retried with backoff before giving up.
+func openWorkbookViaTempCopy(filePath string) (*excelize.File, error) {
+	tmpFile, err := os.CreateTemp("", "excel-mcp-*"+filepath.Ext(filePath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+	defer os.Remove(tmpPath)
+
+	backoff := tempCopyBackoff
+	for attempt := 1; ; attempt++ {
+		err = copyShared(filePath, tmpFile)
+		if err == nil || attempt == tempCopyAttempts || !isSharingViolation(err) {
+			break
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+	tmpFile.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := openWorkbookReadOnly(tmpPath)
+	if err != nil {
+		return nil, err
+	}
+	file.Path = filePath
+	return file, nil
+}
+
+// copyShared copies the source file into dst from the start, truncating previous attempts
+func copyShared(srcPath string, dst *os.File) error {
+	src, err := openSharedReadOnly(filepath.Clean(srcPath))
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	if err := dst.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := dst.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	_, err = io.Copy(dst, src)
+	return err
+}
@@ -0,0 +1,16 @@
+//go:build !windows
+
+package excel
+
+import "os"
+
+// openSharedReadOnly opens a file for reading; POSIX systems use advisory locks only
+func openSharedReadOnly(filePath string) (*os.File, error) {
+	return os.OpenFile(filePath, os.O_RDONLY, 0)
+}
+
+// isSharingViolation reports whether err is a lock conflict with another process.
+// Mandatory sharing violations only exist on Windows.
+func isSharingViolation(err error) bool {
+	return false
+}
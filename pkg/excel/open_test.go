@@ -0,0 +1,83 @@
+package excel
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpenWorkbookReadOnly(t *testing.T) {
+	filePath := createTestExcelFile(t)
+
+	file, strategy, err := openWorkbook(filePath)
+	if err != nil {
+		t.Fatalf("openWorkbook failed: %v", err)
+	}
+	defer file.Close()
+
+	if strategy != OpenStrategyReadOnly {
+		t.Errorf("Expected strategy %q, got %q", OpenStrategyReadOnly, strategy)
+	}
+	if file.Path != filePath {
+		t.Errorf("Expected file path %q, got %q", filePath, file.Path)
+	}
+
+	// The handle must be released after loading so the original can be replaced
+	renamed := filePath + ".moved"
+	if err := os.Rename(filePath, renamed); err != nil {
+		t.Errorf("Expected workbook to be movable after open, got %v", err)
+	}
+}
+
+func TestOpenWorkbookViaTempCopy(t *testing.T) {
+	filePath := createTestExcelFile(t)
+
+	file, err := openWorkbookViaTempCopy(filePath)
+	if err != nil {
+		t.Fatalf("openWorkbookViaTempCopy failed: %v", err)
+	}
+	defer file.Close()
+
+	if file.Path != filePath {
+		t.Errorf("Expected copy to report original path %q, got %q", filePath, file.Path)
+	}
+
+	value, err := file.GetCellValue("Sheet1", "A2")
+	if err != nil || value != "John" {
+		t.Errorf("Expected 'John' from copied workbook, got %q (%v)", value, err)
+	}
+}
+
+func TestOpenWorkbookMissingFile(t *testing.T) {
+	_, _, err := openWorkbook(filepath.Join(t.TempDir(), "missing.xlsx"))
+	if err == nil {
+		t.Fatal("Expected error for missing workbook")
+	}
+	if isSharingViolation(err) {
+		t.Error("Missing file should not be reported as a sharing violation")
+	}
+}
+
+func TestManagerRecordsOpenStrategy(t *testing.T) {
+	manager := NewManager()
+	defer manager.Close()
+	filePath := createTestExcelFile(t)
+
+	if strategy := manager.GetOpenStrategy(filePath); strategy != "" {
+		t.Errorf("Expected no strategy before opening, got %q", strategy)
+	}
+
+	if _, err := manager.OpenFile(filePath); err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+	if strategy := manager.GetOpenStrategy(filePath); strategy != OpenStrategyReadOnly {
+		t.Errorf("Expected strategy %q, got %q", OpenStrategyReadOnly, strategy)
+	}
+
+	if _, err := manager.FlushCache(); err != nil {
+		t.Fatalf("FlushCache failed: %v", err)
+	}
+	if strategy := manager.GetOpenStrategy(filePath); strategy != "" {
+		t.Errorf("Expected strategy to be cleared after flush, got %q", strategy)
+	}
+}
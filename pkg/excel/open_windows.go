@@ -0,0 +1,42 @@
+//go:build windows
+
+package excel
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// Win32 error codes returned when another process holds a conflicting lock
+const (
+	errorSharingViolation syscall.Errno = 32 // ERROR_SHARING_VIOLATION
+	errorLockViolation    syscall.Errno = 33 // ERROR_LOCK_VIOLATION
+)
+
+// openSharedReadOnly opens a file for reading while allowing other processes to
+// read, write and delete it, so an open Excel instance is never blocked by us
+func openSharedReadOnly(filePath string) (*os.File, error) {
+	pathPtr, err := syscall.UTF16PtrFromString(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	handle, err := syscall.CreateFile(pathPtr,
+		syscall.GENERIC_READ,
+		syscall.FILE_SHARE_READ|syscall.FILE_SHARE_WRITE|syscall.FILE_SHARE_DELETE,
+		nil,
+		syscall.OPEN_EXISTING,
+		syscall.FILE_ATTRIBUTE_NORMAL,
+		0)
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: filePath, Err: err}
+	}
+
+	return os.NewFile(uintptr(handle), filePath), nil
+}
+
+// isSharingViolation reports whether err is a lock conflict with another process
+func isSharingViolation(err error) bool {
+	return errors.Is(err, errorSharingViolation) || errors.Is(err, errorLockViolation)
+}
@@ -0,0 +1,374 @@
+package excel
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/xml"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/kevsmith/my-mcp/pkg/shared"
+	"github.com/xuri/excelize/v2"
+)
+
+// WorkbookProtection is the protection of a workbook's structure
+type WorkbookProtection struct {
+	Structure bool `json:"structure"` // Sheets can't be added, removed, renamed or moved
+	Windows   bool `json:"windows"`   // Window sizes and positions are fixed
+	Password  bool `json:"password"`  // A password is needed to unprotect
+}
+
+// SheetProtection is the protection of one sheet. Cells are locked unless
+// their style unlocks them, but locking only takes effect while the sheet is
+// protected.
+type SheetProtection struct {
+	Sheet          string   `json:"sheet"`
+	Protected      bool     `json:"protected"`
+	Password       bool     `json:"password"`        // A password is needed to unprotect
+	Allowed        []string `json:"allowed"`         // Actions still permitted while protected, e.g. format_cells
+	LockedRanges   []string `json:"locked_ranges"`   // Locked cells of the used range
+	UnlockedRanges []string `json:"unlocked_ranges"` // Cells left editable, such as input cells
+}
+
+// ProtectionReport is the protection of a workbook and its sheets
+type ProtectionReport struct {
+	Workbook WorkbookProtection `json:"workbook"`
+	Sheets   []SheetProtection  `json:"sheets"`
+}
+
+// sheetProtectionElement is the sheetProtection element of a worksheet part.
+// Its action attributes are true when the action is locked, so they are kept
+// as strings to tell unset from false.
+type sheetProtectionElement struct {
+	Password  string     `xml:"password,attr"`
+	HashValue string     `xml:"hashValue,attr"`
+	Sheet     string     `xml:"sheet,attr"`
+	Attrs     []xml.Attr `xml:",any,attr"`
+}
+
+// protectionActions are the actions a protected sheet can still allow, by
+// attribute name, and whether each is locked when the attribute is unset
+var protectionActions = []struct {
+	attr   string
+	name   string
+	locked bool
+}{
+	{"selectLockedCells", "select_locked_cells", false},
+	{"selectUnlockedCells", "select_unlocked_cells", false},
+	{"formatCells", "format_cells", true},
+	{"formatColumns", "format_columns", true},
+	{"formatRows", "format_rows", true},
+	{"insertColumns", "insert_columns", true},
+	{"insertRows", "insert_rows", true},
+	{"insertHyperlinks", "insert_hyperlinks", true},
+	{"deleteColumns", "delete_columns", true},
+	{"deleteRows", "delete_rows", true},
+	{"sort", "sort", true},
+	{"autoFilter", "auto_filter", true},
+	{"pivotTables", "pivot_tables", true},
+	{"objects", "edit_objects", false},
+	{"scenarios", "edit_scenarios", false},
+}
+
+// GetProtection reports whether a workbook and its sheets are protected and
+// which cells are locked. With a sheet name, only that sheet is reported.
+func (m *Manager) GetProtection(filePath, sheetName string) (*ProtectionReport, error) {
+	file, err := m.OpenFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	report := &ProtectionReport{Sheets: []SheetProtection{}}
+	if file.WorkBook != nil && file.WorkBook.WorkbookProtection != nil {
+		p := file.WorkBook.WorkbookProtection
+		report.Workbook = WorkbookProtection{Structure: p.LockStructure, Windows: p.LockWindows, Password: p.WorkbookHashValue != ""}
+	}
+
+	sheets := file.GetSheetList()
+	if sheetName != "" {
+		if !slices.Contains(sheets, sheetName) {
+			return nil, shared.NewError(shared.CodeNotFound, "sheet %s does not exist", sheetName)
+		}
+		sheets = []string{sheetName}
+	}
+	for _, sheet := range sheets {
+		protection, err := sheetProtection(file, sheet)
+		if err != nil {
+			return nil, err
+		}
+		report.Sheets = append(report.Sheets, *protection)
+	}
+	return report, nil
+}
+
+// CheckWritable returns an access denied error if any cell of a range is
+// locked on a protected sheet, so writes can fail before touching the file
+func (m *Manager) CheckWritable(filePath, rangeRef, sheetName string) error {
+	file, err := m.OpenFile(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+
+	if sheetName == "" {
+		sheetName, err = m.GetCurrentSheet(filePath, file)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !strings.Contains(rangeRef, ":") {
+		rangeRef += ":" + rangeRef
+	}
+	startCol, startRow, endCol, endRow, err := parseRangeRef(rangeRef)
+	if err != nil {
+		return err
+	}
+
+	element, err := readSheetProtection(file, sheetName)
+	if err != nil {
+		return err
+	}
+	if element == nil || !isTrue(element.Sheet) {
+		return nil
+	}
+
+	locks := newLockReader(file, sheetName)
+	for row := startRow; row <= endRow; row++ {
+		for col := startCol; col <= endCol; col++ {
+			if cell, _ := excelize.CoordinatesToCellName(col, row); locks.locked(cell) {
+				return shared.NewError(shared.CodeAccessDenied, "sheet %s is protected and cell %s is locked; unprotect the sheet in Excel before writing to it", sheetName, cell)
+			}
+		}
+	}
+	return nil
+}
+
+// sheetProtection reads a sheet's protection and the locked and unlocked
+// ranges of its used range
+func sheetProtection(file *excelize.File, sheet string) (*SheetProtection, error) {
+	protection := &SheetProtection{Sheet: sheet, Allowed: []string{}, LockedRanges: []string{}, UnlockedRanges: []string{}}
+
+	element, err := readSheetProtection(file, sheet)
+	if err != nil {
+		return nil, err
+	}
+	if element != nil && isTrue(element.Sheet) {
+		protection.Protected = true
+		protection.Password = element.Password != "" || element.HashValue != ""
+		attrs := make(map[string]string)
+		for _, attr := range element.Attrs {
+			attrs[attr.Name.Local] = attr.Value
+		}
+		for _, action := range protectionActions {
+			locked := action.locked
+			if value, ok := attrs[action.attr]; ok {
+				locked = isTrue(value)
+			}
+			if !locked {
+				protection.Allowed = append(protection.Allowed, action.name)
+			}
+		}
+	}
+
+	rows, err := file.GetRows(sheet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows for sheet %s: %w", sheet, err)
+	}
+	lastCol := 0
+	for _, row := range rows {
+		lastCol = max(lastCol, len(row))
+	}
+	locks := newLockReader(file, sheet)
+	grid := make([][]bool, len(rows))
+	for r := range grid {
+		grid[r] = make([]bool, lastCol)
+		for c := range grid[r] {
+			cell, _ := excelize.CoordinatesToCellName(c+1, r+1)
+			grid[r][c] = locks.locked(cell)
+		}
+	}
+	protection.LockedRanges = append(protection.LockedRanges, cellRanges(grid, true)...)
+	protection.UnlockedRanges = append(protection.UnlockedRanges, cellRanges(grid, false)...)
+	return protection, nil
+}
+
+// readSheetProtection returns a sheet's sheetProtection element, or nil if
+// it has none. It reads the worksheet part excelize keeps from opening the
+// file, since excelize has no getter for sheet protection.
+func readSheetProtection(file *excelize.File, sheet string) (*sheetProtectionElement, error) {
+	partName, err := worksheetPart(file, sheet)
+	if err != nil {
+		return nil, err
+	}
+	part := packagePart(file, partName)
+	if part == nil {
+		return nil, fmt.Errorf("failed to read worksheet %s of sheet %s", partName, sheet)
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(part))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, nil // No sheetProtection element before the end of the part
+		}
+		if start, ok := token.(xml.StartElement); ok && start.Name.Local == "sheetProtection" {
+			var element sheetProtectionElement
+			if err := decoder.DecodeElement(&element, &start); err != nil {
+				return nil, fmt.Errorf("failed to read protection of sheet %s: %w", sheet, err)
+			}
+			return &element, nil
+		}
+	}
+}
+
+// worksheetPart returns the package path of a sheet's worksheet part, such as
+// xl/worksheets/sheet1.xml
+func worksheetPart(file *excelize.File, sheet string) (string, error) {
+	var relID string
+	if file.WorkBook != nil {
+		for _, s := range file.WorkBook.Sheets.Sheet {
+			if strings.EqualFold(s.Name, sheet) {
+				relID = s.ID
+			}
+		}
+	}
+	if relID == "" {
+		return "", shared.NewError(shared.CodeNotFound, "sheet %s does not exist", sheet)
+	}
+
+	workbookPart := "xl/workbook.xml"
+	for _, rel := range readRelationships(file, "_rels/.rels") {
+		if strings.HasSuffix(rel.Type, "/officeDocument") {
+			workbookPart = strings.TrimPrefix(rel.Target, "/")
+		}
+	}
+	dir := path.Dir(workbookPart)
+	for _, rel := range readRelationships(file, path.Join(dir, "_rels", path.Base(workbookPart)+".rels")) {
+		if rel.ID != relID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/"), nil
+		}
+		return path.Join(dir, rel.Target), nil
+	}
+	return "", fmt.Errorf("failed to find the worksheet of sheet %s", sheet)
+}
+
+// packageRelationship is a Relationship element of a package part's rels
+type packageRelationship struct {
+	ID     string `xml:"Id,attr"`
+	Type   string `xml:"Type,attr"`
+	Target string `xml:"Target,attr"`
+}
+
+// readRelationships returns the relationships in a rels part
+func readRelationships(file *excelize.File, name string) []packageRelationship {
+	var rels struct {
+		Relationships []packageRelationship `xml:"Relationship"`
+	}
+	if part := packagePart(file, name); part != nil {
+		_ = xml.Unmarshal(part, &rels)
+	}
+	return rels.Relationships
+}
+
+// packagePart returns the raw content of a part of an opened workbook, or nil
+// if excelize didn't keep it in memory
+func packagePart(file *excelize.File, name string) []byte {
+	content, ok := file.Pkg.Load(name)
+	if !ok {
+		return nil
+	}
+	part, _ := content.([]byte)
+	return part
+}
+
+// isTrue reports whether an XML boolean attribute is true
+func isTrue(value string) bool {
+	return value == "1" || value == "true"
+}
+
+// lockReader reports which cells of a sheet are locked, looking each style's
+// protection up once
+type lockReader struct {
+	file   *excelize.File
+	sheet  string
+	styles map[int]bool
+}
+
+func newLockReader(file *excelize.File, sheet string) *lockReader {
+	return &lockReader{file: file, sheet: sheet, styles: make(map[int]bool)}
+}
+
+// locked reports whether a cell is locked. Cells are locked unless their
+// style says otherwise.
+func (r *lockReader) locked(cell string) bool {
+	styleID, err := r.file.GetCellStyle(r.sheet, cell)
+	if err != nil {
+		return true
+	}
+	if locked, ok := r.styles[styleID]; ok {
+		return locked
+	}
+
+	locked := true
+	if style, err := r.file.GetStyle(styleID); err == nil && style.Protection != nil {
+		locked = style.Protection.Locked
+	}
+	r.styles[styleID] = locked
+	return locked
+}
+
+// cellRanges returns the cells of a grid equal to want as ranges, joining
+// runs of cells in a row with matching runs in the rows below
+func cellRanges(grid [][]bool, want bool) []string {
+	type area struct{ startCol, endCol, startRow, endRow int }
+	var areas []area
+	open := make(map[[2]int]*area)
+	for r, row := range grid {
+		next := make(map[[2]int]*area)
+		for c := 0; c < len(row); {
+			if row[c] != want {
+				c++
+				continue
+			}
+			start := c
+			for c < len(row) && row[c] == want {
+				c++
+			}
+			span := [2]int{start, c - 1}
+			if a, ok := open[span]; ok {
+				a.endRow = r
+				next[span] = a
+				delete(open, span)
+			} else {
+				next[span] = &area{startCol: start, endCol: c - 1, startRow: r, endRow: r}
+			}
+		}
+		for _, a := range open {
+			areas = append(areas, *a)
+		}
+		open = next
+	}
+	for _, a := range open {
+		areas = append(areas, *a)
+	}
+
+	slices.SortFunc(areas, func(a, b area) int {
+		return cmp.Or(cmp.Compare(a.startRow, b.startRow), cmp.Compare(a.startCol, b.startCol))
+	})
+	ranges := make([]string, 0, len(areas))
+	for _, a := range areas {
+		start, _ := excelize.CoordinatesToCellName(a.startCol+1, a.startRow+1)
+		end, _ := excelize.CoordinatesToCellName(a.endCol+1, a.endRow+1)
+		if start == end {
+			ranges = append(ranges, start)
+		} else {
+			ranges = append(ranges, start+":"+end)
+		}
+	}
+	return ranges
+}
@@ -0,0 +1,76 @@
+package excel
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/kevsmith/my-mcp/pkg/shared"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestGetProtection(t *testing.T) {
+	f := excelize.NewFile()
+	f.NewSheet("Open")
+	for row := 1; row <= 3; row++ {
+		f.SetSheetRow("Sheet1", "A"+string(rune('0'+row)), &[]any{"label", 1, 2})
+	}
+	unlocked, _ := f.NewStyle(&excelize.Style{Protection: &excelize.Protection{Locked: false}})
+	f.SetCellStyle("Sheet1", "B2", "C3", unlocked)
+	if err := f.ProtectSheet("Sheet1", &excelize.SheetProtectionOptions{Password: "secret", FormatCells: true, SelectLockedCells: true, SelectUnlockedCells: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.ProtectWorkbook(&excelize.WorkbookProtectionOptions{LockStructure: true}); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "protected.xlsx")
+	if err := f.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	manager := NewManager()
+	defer manager.Close()
+
+	report, err := manager.GetProtection(path, "")
+	if err != nil {
+		t.Fatalf("GetProtection failed: %v", err)
+	}
+	if !report.Workbook.Structure || report.Workbook.Password {
+		t.Errorf("Workbook protection = %+v, want structure locked without a password", report.Workbook)
+	}
+	if len(report.Sheets) != 2 {
+		t.Fatalf("Expected 2 sheets, got %+v", report.Sheets)
+	}
+
+	sheet := report.Sheets[0]
+	if !sheet.Protected || !sheet.Password {
+		t.Errorf("Sheet1 = %+v, want protected with a password", sheet)
+	}
+	if !slices.Equal(sheet.Allowed, []string{"select_locked_cells", "select_unlocked_cells", "format_cells"}) {
+		t.Errorf("Allowed = %v", sheet.Allowed)
+	}
+	if !slices.Equal(sheet.LockedRanges, []string{"A1:C1", "A2:A3"}) || !slices.Equal(sheet.UnlockedRanges, []string{"B2:C3"}) {
+		t.Errorf("Locked %v and unlocked %v, want [A1:C1 A2:A3] and [B2:C3]", sheet.LockedRanges, sheet.UnlockedRanges)
+	}
+	if report.Sheets[1].Protected {
+		t.Errorf("Sheet Open should not be protected: %+v", report.Sheets[1])
+	}
+
+	if _, err := manager.GetProtection(path, "Missing"); err == nil {
+		t.Error("Expected an error for a missing sheet")
+	}
+
+	// Writes to locked cells of a protected sheet are refused up front
+	var toolErr *shared.ToolError
+	if err := manager.CheckWritable(path, "A2", "Sheet1"); !errors.As(err, &toolErr) || toolErr.Code != shared.CodeAccessDenied {
+		t.Errorf("CheckWritable(A2) = %v, want access denied", err)
+	}
+	if err := manager.CheckWritable(path, "B2:C3", "Sheet1"); err != nil {
+		t.Errorf("CheckWritable(B2:C3) = %v, want unlocked cells writable", err)
+	}
+	if err := manager.CheckWritable(path, "A1", "Open"); err != nil {
+		t.Errorf("CheckWritable on an unprotected sheet = %v", err)
+	}
+}
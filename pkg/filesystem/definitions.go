@@ -2,12 +2,19 @@ package filesystem
 
 import "github.com/mark3labs/mcp-go/mcp"
 
+// GetToolDefinitions returns the filesystem tools. The navigation tools only
+// move the server's working directory, so they are annotated as neither
+// destructive nor reaching outside the allowed roots.
 func GetToolDefinitions() []mcp.Tool {
 	return []mcp.Tool{
 		// Navigation tools
 		mcp.NewTool("change_directory",
-			mcp.WithDescription("Change current working directory (like 'cd' command)"),
+			mcp.WithDescription("Change current working directory (like 'cd' command); relative paths in later calls resolve against it"),
+			mcp.WithTitleAnnotation("Change Directory"),
 			mcp.WithReadOnlyHintAnnotation(false), // Changes internal state
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithIdempotentHintAnnotation(true),
+			mcp.WithOpenWorldHintAnnotation(false),
 			mcp.WithString("path",
 				mcp.Description("Directory path to change to (relative to CWD or absolute within allowed roots)"),
 				mcp.Required(),
@@ -15,11 +22,17 @@ func GetToolDefinitions() []mcp.Tool {
 		),
 		mcp.NewTool("get_current_directory",
 			mcp.WithDescription("Get current working directory (like 'pwd' command)"),
+			mcp.WithTitleAnnotation("Current Directory"),
 			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithOpenWorldHintAnnotation(false),
 		),
 		mcp.NewTool("get_directory_info",
 			mcp.WithDescription("Get current directory and list of allowed root directories"),
+			mcp.WithTitleAnnotation("Directory Info"),
 			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithOpenWorldHintAnnotation(false),
 		),
 
 		// File operations
@@ -45,6 +58,9 @@ func GetToolDefinitions() []mcp.Tool {
 				mcp.Description("File or directory path to get info for"),
 				mcp.Required(),
 			),
+			mcp.WithBoolean("include_extended_attributes",
+				mcp.Description("Also list extended attributes (macOS and Linux) or alternate data streams (Windows), where quarantine flags and download origins are kept, with values up to 4 KB (default: false)"),
+			),
 		),
 		mcp.NewTool("glob",
 			mcp.WithDescription("Find files matching a wildcard pattern (like shell globbing)"),
@@ -54,5 +70,211 @@ func GetToolDefinitions() []mcp.Tool {
 				mcp.Required(),
 			),
 		),
+
+		mcp.NewTool("get_image_info",
+			mcp.WithDescription("Get an image's format, dimensions and basic EXIF data (camera, capture time, exposure, GPS location), and optionally a small thumbnail returned as image content for previewing. Reads PNG, JPEG, GIF, WebP, BMP and TIFF"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("path",
+				mcp.Description("Image file path (relative to CWD or absolute within allowed roots)"),
+				mcp.Required(),
+			),
+			mcp.WithBoolean("thumbnail",
+				mcp.Description("Return a downscaled thumbnail, turned upright by the EXIF orientation (default: false); its bytes count against the read quota"),
+			),
+			mcp.WithNumber("thumbnail_size",
+				mcp.Description("Longest edge of the thumbnail in pixels (default: 256, max: 1024)"),
+				mcp.Min(16),
+				mcp.Max(maxThumbnailSize),
+			),
+		),
+		mcp.NewTool("read_structured",
+			mcp.WithDescription("Parse a CSV, TSV, JSON, YAML or TOML file and return typed data instead of raw text. CSV rows become records keyed by the header; 'select' picks part of the data and arrays are paged"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("path",
+				mcp.Description("File path (relative to CWD or absolute within allowed roots)"),
+				mcp.Required(),
+			),
+			mcp.WithString("format",
+				mcp.Description("File format (optional, defaults to the file extension)"),
+				mcp.Enum(StructuredCSV, StructuredTSV, StructuredJSON, StructuredYAML, StructuredTOML),
+			),
+			mcp.WithString("select",
+				mcp.Description("JSONPath-like selection (e.g., '$.servers[0].host', 'dependencies.*', '[*].amount', \"['key.with.dots']\"); wildcards return an array"),
+			),
+			mcp.WithBoolean("header",
+				mcp.Description("Whether the first CSV/TSV row names the columns (default: true); without one, rows are arrays"),
+			),
+			mcp.WithNumber("offset",
+				mcp.Description("First array element to return (default: 0)"),
+				mcp.Min(0),
+			),
+			mcp.WithNumber("limit",
+				mcp.Description("Array elements to return (default: 100, max: 1000)"),
+				mcp.Min(1),
+				mcp.Max(maxStructuredLimit),
+			),
+		),
+		mcp.NewTool("parse_log",
+			mcp.WithDescription("Read a log file as structured entries (time, level, message, fields) and return the most recent ones. Understands JSON lines, logfmt, syslog and plain 'timestamp LEVEL message' lines; stack traces stay with their entry"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("path",
+				mcp.Description("Log file path (relative to CWD or absolute within allowed roots)"),
+				mcp.Required(),
+			),
+			mcp.WithString("format",
+				mcp.Description("Log format (default: auto, detected line by line)"),
+				mcp.Enum(LogFormatAuto, LogFormatJSON, LogFormatLogfmt, LogFormatSyslog, LogFormatText),
+			),
+			mcp.WithString("level",
+				mcp.Description("Minimum level to return; entries without a level are left out"),
+				mcp.Enum(logLevels...),
+			),
+			mcp.WithString("since",
+				mcp.Description("Only entries at or after this RFC 3339 time (e.g., '2024-05-01T09:00:00Z')"),
+			),
+			mcp.WithString("until",
+				mcp.Description("Only entries at or before this RFC 3339 time"),
+			),
+			mcp.WithNumber("limit",
+				mcp.Description("Number of most recent entries to return (default: 100, max: 1000)"),
+				mcp.Min(1),
+				mcp.Max(maxLogLimit),
+			),
+		),
+
+		// Change tracking
+		mcp.NewTool("snapshot_directory",
+			mcp.WithDescription("Record the size and SHA-256 hash of every file under a directory, so diff_snapshot can later report what a build or script changed. Snapshots last for the session; the last 10 are kept"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("path",
+				mcp.Description("Directory to snapshot (optional, defaults to current directory)"),
+			),
+			mcp.WithArray("exclude",
+				mcp.Description("File or directory names to skip, with wildcards (e.g., ['.git', 'node_modules', '*.log'])"),
+				mcp.WithStringItems(),
+			),
+		),
+		mcp.NewTool("diff_snapshot",
+			mcp.WithDescription("Compare a directory with a snapshot taken earlier and list the files created, modified and deleted since"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("snapshot_id",
+				mcp.Description("ID returned by snapshot_directory"),
+				mcp.Required(),
+			),
+		),
+
+		// Session limits
+		mcp.NewTool("quota_status",
+			mcp.WithDescription("Get the session's read quota: bytes of file content returned and distinct files read, against the configured limits"),
+			mcp.WithReadOnlyHintAnnotation(true),
+		),
+	}
+}
+
+// GetWriteToolDefinitions returns the tools that write under the allowed
+// roots. They are only registered when fs-mcp is started with --allow-write.
+func GetWriteToolDefinitions() []mcp.Tool {
+	return []mcp.Tool{
+		// Workspace tools
+		mcp.NewTool("create_workspace",
+			mcp.WithDescription("Create an empty scratch directory inside an allowed root for temporary files, removed with its contents when its time to live runs out. get_directory_info lists live workspaces by label"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithOpenWorldHintAnnotation(false),
+			mcp.WithString("label",
+				mcp.Description("Name for the workspace, unique in the session: letters, digits, '-' and '_' (default: scratch)"),
+			),
+			mcp.WithString("parent",
+				mcp.Description("Directory to create the workspace in (optional, defaults to the first allowed root)"),
+			),
+			mcp.WithNumber("ttl_minutes",
+				mcp.Description("Minutes until the workspace is removed (default: 60, max: 1440)"),
+				mcp.Min(1),
+				mcp.Max(1440),
+			),
+		),
+
+		// Edit tools
+		mcp.NewTool("replace_in_file",
+			mcp.WithDescription("Replace occurrences of a string or regular expression in a file, returning its SHA-256 before and after. Use expected_count to make sure only the intended matches change"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(true),
+			mcp.WithOpenWorldHintAnnotation(false),
+			mcp.WithString("path",
+				mcp.Description("File to edit (relative to CWD or absolute within allowed roots)"),
+				mcp.Required(),
+			),
+			mcp.WithString("search",
+				mcp.Description("Text to find, matched exactly unless regex is set"),
+				mcp.Required(),
+			),
+			mcp.WithString("replace",
+				mcp.Description("Replacement text; with regex, $1 or ${name} insert capture groups"),
+				mcp.Required(),
+			),
+			mcp.WithBoolean("regex",
+				mcp.Description("Treat search as a Go regular expression (default: false)"),
+			),
+			mcp.WithNumber("max_replacements",
+				mcp.Description("Replace at most this many occurrences, from the start of the file (default: all)"),
+				mcp.Min(1),
+			),
+			mcp.WithNumber("expected_count",
+				mcp.Description("Change nothing unless exactly this many occurrences are found"),
+				mcp.Min(0),
+			),
+			mcp.WithString("expected_sha256",
+				mcp.Description("Change nothing unless the file's SHA-256 is this, e.g. after_sha256 from an earlier edit"),
+			),
+			mcp.WithBoolean("dry_run",
+				mcp.Description("Report what would change without writing (default: false)"),
+			),
+		),
+		mcp.NewTool("apply_patch",
+			mcp.WithDescription("Apply a unified diff (as from git diff or diff -u) to one or more files, which may create or delete files. Every hunk must match before any file is written; hunks may have moved a few lines. Returns each file's SHA-256 before and after"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(true),
+			mcp.WithOpenWorldHintAnnotation(false),
+			mcp.WithString("patch",
+				mcp.Description("Unified diff with ---/+++ file headers and @@ hunks; paths are relative to CWD, and git's a/ and b/ prefixes are removed"),
+				mcp.Required(),
+			),
+			mcp.WithString("path",
+				mcp.Description("File to patch instead of the one named in a single-file diff (optional)"),
+			),
+			mcp.WithBoolean("dry_run",
+				mcp.Description("Check that the patch applies and report the result without writing (default: false)"),
+			),
+		),
+		mcp.NewTool("sync_paths",
+			mcp.WithDescription("Copy a directory tree to another directory, within or between allowed roots: files missing from the destination or differing in size or modification time are copied, keeping their permissions and times. Mirror mode also deletes destination files that aren't in the source. Use dry_run to preview; when the request carries a progress token, progress notifications report bytes copied"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(true),
+			mcp.WithIdempotentHintAnnotation(true),
+			mcp.WithOpenWorldHintAnnotation(false),
+			mcp.WithString("source",
+				mcp.Description("Directory to copy from (relative to CWD or absolute within allowed roots)"),
+				mcp.Required(),
+			),
+			mcp.WithString("destination",
+				mcp.Description("Directory to copy to, created if missing; must not be inside the source or contain it"),
+				mcp.Required(),
+			),
+			mcp.WithString("mode",
+				mcp.Description("copy keeps files only in the destination; mirror deletes them (default: copy)"),
+				mcp.Enum(SyncCopy, SyncMirror),
+			),
+			mcp.WithArray("include",
+				mcp.Description("Only sync files whose name or relative path matches one of these wildcards (e.g., ['*.go', 'docs/*.md'])"),
+				mcp.WithStringItems(),
+			),
+			mcp.WithArray("exclude",
+				mcp.Description("Skip files and directories whose name or relative path matches one of these wildcards (e.g., ['.git', 'node_modules']); mirror mode leaves them in the destination"),
+				mcp.WithStringItems(),
+			),
+			mcp.WithBoolean("dry_run",
+				mcp.Description("List the changes without making them (default: false)"),
+			),
+		),
 	}
 }
@@ -0,0 +1,96 @@
+package filesystem
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestGetToolDefinitions(t *testing.T) {
+	tools := GetToolDefinitions()
+
+	expectedTools := []string{
+		"change_directory",
+		"get_current_directory",
+		"get_directory_info",
+		"list_directory",
+		"read_file",
+		"get_file_info",
+		"glob",
+		"get_image_info",
+		"read_structured",
+		"parse_log",
+		"snapshot_directory",
+		"diff_snapshot",
+		"quota_status",
+	}
+	if len(tools) != len(expectedTools) {
+		t.Errorf("Expected %d tools, got %d", len(expectedTools), len(tools))
+	}
+	for i, expectedName := range expectedTools {
+		if i >= len(tools) {
+			t.Errorf("Missing tool: %s", expectedName)
+			continue
+		}
+		if tools[i].Name != expectedName {
+			t.Errorf("Expected tool name '%s' at index %d, got '%s'", expectedName, i, tools[i].Name)
+		}
+		if tools[i].Description == "" {
+			t.Errorf("Tool '%s' has empty description", expectedName)
+		}
+	}
+}
+
+func TestGetWriteToolDefinitions(t *testing.T) {
+	tools := GetWriteToolDefinitions()
+
+	expectedTools := []string{"create_workspace", "replace_in_file", "apply_patch", "sync_paths"}
+	if len(tools) != len(expectedTools) {
+		t.Fatalf("Expected %d tools, got %d", len(expectedTools), len(tools))
+	}
+	for i, expectedName := range expectedTools {
+		if tools[i].Name != expectedName {
+			t.Errorf("Expected tool name '%s' at index %d, got '%s'", expectedName, i, tools[i].Name)
+		}
+		if *tools[i].Annotations.ReadOnlyHint {
+			t.Errorf("Tool '%s' is marked read-only", expectedName)
+		}
+	}
+}
+
+func TestNavigationToolDefinitions(t *testing.T) {
+	tools := GetToolDefinitions()
+
+	testCases := []struct {
+		index       int
+		name        string
+		required    []string
+		readOnly    bool
+		destructive bool
+	}{
+		{0, "change_directory", []string{"path"}, false, false},
+		{1, "get_current_directory", nil, true, false},
+		{2, "get_directory_info", nil, true, false},
+	}
+
+	for _, tc := range testCases {
+		tool := tools[tc.index]
+		if tool.Name != tc.name {
+			t.Fatalf("Expected tool '%s' at index %d, got '%s'", tc.name, tc.index, tool.Name)
+		}
+		if !slices.Equal(tool.InputSchema.Required, tc.required) {
+			t.Errorf("Tool '%s' requires %v, want %v", tc.name, tool.InputSchema.Required, tc.required)
+		}
+		if tool.Annotations.Title == "" {
+			t.Errorf("Tool '%s' has no title", tc.name)
+		}
+		annotations := tool.Annotations
+		if *annotations.ReadOnlyHint != tc.readOnly || *annotations.DestructiveHint != tc.destructive || *annotations.OpenWorldHint {
+			t.Errorf("Tool '%s' has annotations read-only %v, destructive %v, open world %v",
+				tc.name, *annotations.ReadOnlyHint, *annotations.DestructiveHint, *annotations.OpenWorldHint)
+		}
+	}
+
+	if _, ok := tools[0].InputSchema.Properties["path"]; !ok {
+		t.Error("change_directory is missing its path property")
+	}
+}
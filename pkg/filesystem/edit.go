@@ -0,0 +1,167 @@
+package filesystem
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/kevsmith/my-mcp/pkg/shared"
+)
+
+// maxEditFileBytes caps the files replace_in_file and apply_patch rewrite,
+// which are read whole
+const maxEditFileBytes = 16 << 20
+
+// WriteEnabled reports whether the handler was started with writing allowed
+func (h *Handler) WriteEnabled() bool {
+	return h.allowWrite
+}
+
+// checkWriteEnabled rejects writes unless the server was started with --allow-write
+func (h *Handler) checkWriteEnabled() error {
+	if !h.allowWrite {
+		return shared.NewError(shared.CodeAccessDenied, "writing is disabled; start fs-mcp with --allow-write to enable it")
+	}
+	return nil
+}
+
+// readForEdit reads a regular file that an edit will rewrite
+func readForEdit(path string) ([]byte, os.FileMode, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get file info: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		return nil, 0, shared.NewError(shared.CodeInvalidArgument, "not a regular file: %s", path)
+	}
+	if info.Size() > maxEditFileBytes {
+		return nil, 0, shared.NewError(shared.CodeTooLarge, "file is %d bytes; files up to %d bytes can be edited", info.Size(), maxEditFileBytes)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read file content: %w", err)
+	}
+	return content, info.Mode().Perm(), nil
+}
+
+// writeFileAtomic replaces path's content by renaming a temporary file over
+// it, so readers never see a partly written file
+func writeFileAtomic(path string, content []byte, perm os.FileMode) error {
+	temp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	defer os.Remove(temp.Name()) // Fails harmlessly once renamed
+
+	if _, err := temp.Write(content); err != nil {
+		temp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Chmod(temp.Name(), perm); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(temp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// contentSHA256 returns the hex SHA-256 of content
+func contentSHA256(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// checkExpectedHash rejects an edit when the file changed since the caller
+// last saw it
+func checkExpectedHash(path string, content []byte, expected string) error {
+	if expected != "" && !strings.EqualFold(expected, contentSHA256(content)) {
+		return shared.NewError(shared.CodeInvalidArgument, "%s has changed: its SHA-256 is no longer %s", path, expected)
+	}
+	return nil
+}
+
+// ReplaceInFile replaces occurrences of a literal string or regular
+// expression in a file. Replacements are made from the start of the file,
+// at most MaxReplacements of them when set; with ExpectedCount set, the file
+// is left alone unless exactly that many occurrences are found.
+func (h *Handler) ReplaceInFile(args ReplaceInFileArgs) (*EditResult, error) {
+	if err := h.checkWriteEnabled(); err != nil {
+		return nil, err
+	}
+	if args.Search == "" {
+		return nil, shared.NewError(shared.CodeInvalidArgument, "search must not be empty")
+	}
+	limit := -1
+	if args.MaxReplacements != nil && *args.MaxReplacements > 0 {
+		limit = *args.MaxReplacements
+	}
+
+	fullPath, err := h.resolvePath(args.Path)
+	if err != nil {
+		return nil, err
+	}
+	content, perm, err := readForEdit(fullPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkExpectedHash(fullPath, content, args.ExpectedSHA256); err != nil {
+		return nil, err
+	}
+
+	pattern := regexp.QuoteMeta(args.Search)
+	if args.Regex {
+		pattern = args.Search
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, shared.NewError(shared.CodeInvalidArgument, "invalid regular expression: %v", err)
+	}
+
+	matches := re.FindAllSubmatchIndex(content, -1)
+	if args.ExpectedCount != nil && len(matches) != *args.ExpectedCount {
+		return nil, shared.NewError(shared.CodeInvalidArgument, "found %d occurrences in %s, expected %d; nothing was replaced", len(matches), fullPath, *args.ExpectedCount)
+	}
+	if len(matches) == 0 {
+		return nil, shared.NewError(shared.CodeNotFound, "no occurrences of %q in %s", args.Search, fullPath)
+	}
+	if limit >= 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	var updated bytes.Buffer
+	last := 0
+	for _, match := range matches {
+		updated.Write(content[last:match[0]])
+		if args.Regex {
+			updated.Write(re.Expand(nil, []byte(args.Replace), content, match))
+		} else {
+			updated.WriteString(args.Replace)
+		}
+		last = match[1]
+	}
+	updated.Write(content[last:])
+
+	result := &EditResult{
+		Path:         fullPath,
+		Replacements: len(matches),
+		BeforeSHA256: contentSHA256(content),
+		AfterSHA256:  contentSHA256(updated.Bytes()),
+		Size:         int64(updated.Len()),
+		DryRun:       args.DryRun,
+	}
+	if !args.DryRun {
+		if err := writeFileAtomic(fullPath, updated.Bytes(), perm); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,88 @@
+package filesystem
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kevsmith/my-mcp/pkg/shared"
+)
+
+func TestReplaceInFile(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+	t.Setenv("FS_ALLOW_WRITE", "true")
+
+	path := filepath.Join(tmpDir, "main.go")
+	original := "func a() { log(1) }\nfunc b() { log(2) }\nfunc c() { log(3) }\n"
+	if err := os.WriteFile(path, []byte(original), 0600); err != nil {
+		t.Fatal(err)
+	}
+	handler, err := NewHandler([]string{tmpDir})
+	if err != nil {
+		t.Fatalf("Failed to create handler: %v", err)
+	}
+	content := func() string {
+		t.Helper()
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	ptr := func(n int) *int { return &n }
+
+	// A dry run reports the hashes without writing
+	result, err := handler.ReplaceInFile(ReplaceInFileArgs{Path: "main.go", Search: "log(", Replace: "logf(", DryRun: true})
+	if err != nil {
+		t.Fatalf("Dry run failed: %v", err)
+	}
+	if result.Replacements != 3 || result.BeforeSHA256 != contentSHA256([]byte(original)) || result.AfterSHA256 == result.BeforeSHA256 || !result.DryRun {
+		t.Errorf("Unexpected dry run result: %+v", result)
+	}
+	if content() != original {
+		t.Error("Dry run changed the file")
+	}
+
+	// An unexpected number of occurrences changes nothing
+	var toolErr *shared.ToolError
+	if _, err := handler.ReplaceInFile(ReplaceInFileArgs{Path: "main.go", Search: "log(", Replace: "logf(", ExpectedCount: ptr(1)}); !errors.As(err, &toolErr) || toolErr.Code != shared.CodeInvalidArgument {
+		t.Errorf("Expected a count mismatch error, got %v", err)
+	}
+
+	// Literal replacements stop at the limit
+	result, err = handler.ReplaceInFile(ReplaceInFileArgs{Path: "main.go", Search: "log(", Replace: "logf(", MaxReplacements: ptr(2)})
+	if err != nil {
+		t.Fatalf("ReplaceInFile failed: %v", err)
+	}
+	want := "func a() { logf(1) }\nfunc b() { logf(2) }\nfunc c() { log(3) }\n"
+	if result.Replacements != 2 || content() != want || result.AfterSHA256 != contentSHA256([]byte(want)) || result.Size != int64(len(want)) {
+		t.Errorf("Unexpected result %+v, content %q", result, content())
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("File mode not kept: %v", info.Mode())
+	}
+
+	// Regular expressions expand capture groups, and a stale hash is refused
+	if _, err := handler.ReplaceInFile(ReplaceInFileArgs{Path: "main.go", Search: "x", Replace: "y", ExpectedSHA256: result.BeforeSHA256}); err == nil {
+		t.Error("Expected an error for a stale expected_sha256")
+	}
+	_, err = handler.ReplaceInFile(ReplaceInFileArgs{Path: "main.go", Search: `func (\w)\(\)`, Replace: "func ${1}2()", Regex: true, ExpectedSHA256: result.AfterSHA256})
+	if err != nil {
+		t.Fatalf("Regex replace failed: %v", err)
+	}
+	if got := content(); got != "func a2() { logf(1) }\nfunc b2() { logf(2) }\nfunc c2() { log(3) }\n" {
+		t.Errorf("Regex replace gave %q", got)
+	}
+
+	// Missing text and disabled writes are errors
+	if _, err := handler.ReplaceInFile(ReplaceInFileArgs{Path: "main.go", Search: "absent", Replace: ""}); !errors.As(err, &toolErr) || toolErr.Code != shared.CodeNotFound {
+		t.Errorf("Expected not found, got %v", err)
+	}
+	t.Setenv("FS_ALLOW_WRITE", "")
+	readOnly, _ := NewHandler([]string{tmpDir})
+	if _, err := readOnly.ReplaceInFile(ReplaceInFileArgs{Path: "main.go", Search: "logf", Replace: "log"}); !errors.As(err, &toolErr) || toolErr.Code != shared.CodeAccessDenied {
+		t.Errorf("Expected access denied, got %v", err)
+	}
+}
@@ -1,9 +1,13 @@
 package filesystem
 
 import (
+	"context"
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func setupTestDir(t *testing.T) (string, func()) {
@@ -132,12 +136,12 @@ func TestPathTraversalPrevention(t *testing.T) {
 	}
 
 	for _, attackPath := range attackPaths {
-		_, err := handler.ReadFile(attackPath)
+		_, err := handler.ReadFile(context.Background(), attackPath)
 		if err == nil {
 			t.Errorf("Expected path traversal to be blocked for: %s", attackPath)
 		}
 
-		_, err = handler.GetFileInfo(attackPath)
+		_, err = handler.GetFileInfo(attackPath, FileInfoOptions{})
 		if err == nil {
 			t.Errorf("Expected path traversal to be blocked for: %s", attackPath)
 		}
@@ -197,7 +201,7 @@ func TestReadFile(t *testing.T) {
 	}
 
 	// Read file with relative path
-	content, err := handler.ReadFile("test.txt")
+	content, err := handler.ReadFile(context.Background(), "test.txt")
 	if err != nil {
 		t.Fatalf("Failed to read file: %v", err)
 	}
@@ -208,7 +212,7 @@ func TestReadFile(t *testing.T) {
 	}
 
 	// Read file in subdirectory
-	content, err = handler.ReadFile("subdir/sub.txt")
+	content, err = handler.ReadFile(context.Background(), "subdir/sub.txt")
 	if err != nil {
 		t.Fatalf("Failed to read subdirectory file: %v", err)
 	}
@@ -219,7 +223,7 @@ func TestReadFile(t *testing.T) {
 	}
 
 	// Try to read directory - should fail
-	_, err = handler.ReadFile("subdir")
+	_, err = handler.ReadFile(context.Background(), "subdir")
 	if err == nil {
 		t.Error("Expected error when reading directory as file")
 	}
@@ -278,7 +282,7 @@ func TestMultipleRoots(t *testing.T) {
 
 	// Should be able to access second root with absolute path
 	absPath := filepath.Join(tmpDir2, "test.txt")
-	content, err := handler.ReadFile(absPath)
+	content, err := handler.ReadFile(context.Background(), absPath)
 	if err != nil {
 		t.Fatalf("Failed to read file from second root: %v", err)
 	}
@@ -307,7 +311,7 @@ func TestGetDirectoryInfo(t *testing.T) {
 		t.Fatalf("Failed to create handler: %v", err)
 	}
 
-	info := handler.GetDirectoryInfo()
+	info := handler.GetDirectoryInfo(context.Background())
 
 	if info.CurrentDirectory != tmpDir {
 		t.Errorf("Expected current directory %s, got %s", tmpDir, info.CurrentDirectory)
@@ -321,3 +325,33 @@ func TestGetDirectoryInfo(t *testing.T) {
 		t.Errorf("Expected allowed root %s, got %s", tmpDir, info.AllowedRoots[0])
 	}
 }
+
+func TestCreationTime(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+	handler, err := NewHandler([]string{tmpDir})
+	if err != nil {
+		t.Fatalf("Failed to create handler: %v", err)
+	}
+
+	info, err := handler.GetFileInfo("test.txt", FileInfoOptions{})
+	if err != nil {
+		t.Fatalf("GetFileInfo failed: %v", err)
+	}
+	if info.CreatedAvailable {
+		if age := time.Since(info.Created); age < -time.Minute || age > time.Minute {
+			t.Errorf("Created = %v, want about now", info.Created)
+		}
+	} else if !info.Created.IsZero() {
+		t.Errorf("Created = %v though it isn't available", info.Created)
+	}
+
+	// An unknown creation time is left out rather than sent as year 1
+	data, err := json.Marshal(FileInfo{Name: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), `"created"`) || !strings.Contains(string(data), `"created_available":false`) {
+		t.Errorf("Unexpected JSON: %s", data)
+	}
+}
@@ -0,0 +1,49 @@
+package filesystem
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// FuzzResolvePath verifies that no input path can resolve outside the allowed roots
+func FuzzResolvePath(f *testing.F) {
+	seeds := []string{
+		"test.txt",
+		"subdir/../test.txt",
+		"../../../etc/passwd",
+		"/etc/passwd",
+		"subdir/./../../",
+		"a\x00b",
+		"..",
+		"",
+		strings.Repeat("../", 64),
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	root := f.TempDir()
+	handler, err := NewHandler([]string{root})
+	if err != nil {
+		f.Fatalf("Failed to create handler: %v", err)
+	}
+	cleanRoot := handler.allowedRoots[0]
+
+	f.Fuzz(func(t *testing.T, input string) {
+		resolved, err := handler.resolvePath(input)
+		if err != nil {
+			return
+		}
+
+		if !filepath.IsAbs(resolved) {
+			t.Fatalf("input %q resolved to relative path %q", input, resolved)
+		}
+		if strings.ContainsRune(resolved, 0) {
+			t.Fatalf("input %q resolved to path containing NUL: %q", input, resolved)
+		}
+		if resolved != cleanRoot && !strings.HasPrefix(resolved, cleanRoot+string(filepath.Separator)) {
+			t.Fatalf("input %q escaped root %q: %q", input, cleanRoot, resolved)
+		}
+	})
+}
@@ -1,118 +1,65 @@
 package filesystem
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"sort"
-	"strings"
+	"sync"
+
+	"github.com/kevsmith/my-mcp/pkg/shared"
+	"github.com/kevsmith/my-mcp/pkg/shared/sandbox"
 )
 
 type Handler struct {
 	allowedRoots []string // Pre-cleaned absolute paths (stored without trailing separators)
-	rootPrefixes []string // Pre-computed roots with trailing separators for efficient matching
-	currentWD    string   // Current working directory (absolute)
+	paths        *sandbox.Sandbox
+	currentWD    string // Current working directory (absolute)
+	quotaConfig  QuotaConfig
+	allowWrite   bool // FS_ALLOW_WRITE
+
+	mu       sync.Mutex
+	sessions map[string]*sessionState // By client session ID
 }
 
+// NewHandler creates a handler with quotas taken from the environment
 func NewHandler(allowedRoots []string) (*Handler, error) {
-	if len(allowedRoots) == 0 {
-		return nil, fmt.Errorf("at least one allowed root directory is required")
-	}
-
-	// Clean and validate all allowed roots, pre-compute prefixes
-	var cleanRoots []string
-	var rootPrefixes []string
-	for _, root := range allowedRoots {
-		absRoot, err := filepath.Abs(filepath.Clean(root))
-		if err != nil {
-			return nil, fmt.Errorf("invalid root path %s: %w", root, err)
-		}
-
-		// Verify root exists and is a directory
-		info, err := os.Stat(absRoot)
-		if err != nil {
-			return nil, fmt.Errorf("root path %s does not exist: %w", absRoot, err)
-		}
-		if !info.IsDir() {
-			return nil, fmt.Errorf("root path %s is not a directory", absRoot)
-		}
-
-		cleanRoots = append(cleanRoots, absRoot)
+	return NewHandlerWithQuota(allowedRoots, GetQuotaConfig())
+}
 
-		// Pre-compute prefix with trailing separator for efficient matching
-		rootPrefix := absRoot
-		if !strings.HasSuffix(rootPrefix, string(filepath.Separator)) {
-			rootPrefix += string(filepath.Separator)
-		}
-		rootPrefixes = append(rootPrefixes, rootPrefix)
+// NewHandlerWithQuota creates a handler that enforces the given per-session quota
+func NewHandlerWithQuota(allowedRoots []string, quota QuotaConfig) (*Handler, error) {
+	// Clean and validate all allowed roots
+	paths, err := sandbox.New(allowedRoots)
+	if err != nil {
+		return nil, err
 	}
 
 	// Start in the first allowed root
-	initialWD := cleanRoots[0]
+	initialWD := paths.Roots()[0]
 
 	return &Handler{
-		allowedRoots: cleanRoots,
-		rootPrefixes: rootPrefixes,
+		allowedRoots: paths.Roots(),
+		paths:        paths,
 		currentWD:    initialWD,
+		quotaConfig:  quota,
+		allowWrite:   GetWriteEnabled(),
+		sessions:     make(map[string]*sessionState),
 	}, nil
 }
 
 // Core security function - resolves and validates any path
-// Optimized with pre-cleaning and efficient validation
 func (h *Handler) resolvePath(inputPath string) (string, error) {
-	var resolvedPath string
-
-	if filepath.IsAbs(inputPath) {
-		// Absolute path - use as-is but validate
-		resolvedPath = inputPath
-	} else {
-		// Relative path - resolve from CWD
-		resolvedPath = filepath.Join(h.currentWD, inputPath)
-	}
-
-	// Critical: Clean the path to resolve all ./ ../ shenanigans
-	cleanPath := filepath.Clean(resolvedPath)
-
-	// Make it absolute to handle any remaining edge cases
-	absPath, err := filepath.Abs(cleanPath)
-	if err != nil {
-		return "", fmt.Errorf("failed to resolve absolute path: %w", err)
-	}
-
-	// Optimized validation against allowed roots
-	if !h.isPathAllowedOptimized(absPath) {
-		return "", fmt.Errorf("access denied: path outside allowed roots")
-	}
-
-	return absPath, nil
+	// Relative paths resolve from the CWD; the sandbox cleans ./ ../
+	// shenanigans and checks symlink targets
+	return h.paths.Resolve(h.currentWD, inputPath)
 }
 
 // Legacy method for backward compatibility
 func (h *Handler) isPathAllowed(path string) bool {
-	return h.isPathAllowedOptimized(path)
-}
-
-// Optimized path validation with pre-computed prefixes
-func (h *Handler) isPathAllowedOptimized(path string) bool {
-	// Pre-clean path once
-	cleanPath := filepath.Clean(path)
-
-	// First check for exact root matches (most common case)
-	for _, root := range h.allowedRoots {
-		if cleanPath == root {
-			return true
-		}
-	}
-
-	// Check if path is under any allowed root using pre-computed prefixes
-	for _, rootPrefix := range h.rootPrefixes {
-		if strings.HasPrefix(cleanPath, rootPrefix) {
-			return true
-		}
-	}
-
-	return false
+	return h.paths.Allowed(path)
 }
 
 // Get relative path for display purposes
@@ -138,7 +85,7 @@ func (h *Handler) ChangeDirectory(path string) error {
 		return fmt.Errorf("directory does not exist: %w", err)
 	}
 	if !info.IsDir() {
-		return fmt.Errorf("not a directory: %s", newWD)
+		return shared.NewError(shared.CodeInvalidArgument, "not a directory: %s", newWD)
 	}
 
 	// Safe to change
@@ -150,10 +97,15 @@ func (h *Handler) GetCurrentDirectory() string {
 	return h.currentWD
 }
 
-func (h *Handler) GetDirectoryInfo() DirectoryInfo {
+func (h *Handler) GetQuotaStatus(ctx context.Context) QuotaStatus {
+	return h.sessionFor(ctx).quota.status()
+}
+
+func (h *Handler) GetDirectoryInfo(ctx context.Context) DirectoryInfo {
 	return DirectoryInfo{
 		CurrentDirectory: h.currentWD,
 		AllowedRoots:     h.allowedRoots,
+		Workspaces:       h.sessionFor(ctx).workspaces.list(),
 	}
 }
 
@@ -250,9 +202,7 @@ func (h *Handler) ListDirectoryOptimized(path *string, limit *int, skip *int) (*
 				Modified:     info.ModTime(),
 			}
 
-			if stat := info.Sys(); stat != nil {
-				fileInfo.Created = extractCreationTime(stat)
-			}
+			fileInfo.Created, fileInfo.CreatedAvailable = creationTime(absPath, info)
 
 			files = append(files, fileInfo)
 			processedCount++
@@ -309,7 +259,7 @@ func (h *Handler) Glob(pattern string) (*GlobResult, error) {
 
 	var files []FileInfo
 	for _, match := range matches {
-		if !h.isPathAllowedOptimized(match) {
+		if !h.isPathAllowed(match) {
 			continue // Skip matches outside allowed roots
 		}
 
@@ -327,9 +277,7 @@ func (h *Handler) Glob(pattern string) (*GlobResult, error) {
 			Modified:     info.ModTime(),
 		}
 
-		if stat := info.Sys(); stat != nil {
-			fileInfo.Created = extractCreationTime(stat)
-		}
+		fileInfo.Created, fileInfo.CreatedAvailable = creationTime(match, info)
 
 		files = append(files, fileInfo)
 	}
@@ -344,7 +292,9 @@ func (h *Handler) Glob(pattern string) (*GlobResult, error) {
 	}, nil
 }
 
-func (h *Handler) GetFileInfo(path string) (*FileInfo, error) {
+// GetFileInfo returns a file's or directory's metadata, with the optional
+// platform-specific metadata opts asks for
+func (h *Handler) GetFileInfo(path string, opts FileInfoOptions) (*FileInfo, error) {
 	fullPath, err := h.resolvePath(path)
 	if err != nil {
 		return nil, err
@@ -364,14 +314,21 @@ func (h *Handler) GetFileInfo(path string) (*FileInfo, error) {
 		Modified:     info.ModTime(),
 	}
 
-	if stat := info.Sys(); stat != nil {
-		fileInfo.Created = extractCreationTime(stat)
+	fileInfo.Created, fileInfo.CreatedAvailable = creationTime(fullPath, info)
+
+	if opts.ExtendedAttributes {
+		attrs, err := listExtendedAttributes(fullPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list extended attributes: %w", err)
+		}
+		fileInfo.ExtendedAttributes = attrs
 	}
 
 	return fileInfo, nil
 }
 
-func (h *Handler) ReadFile(path string) (string, error) {
+func (h *Handler) ReadFile(ctx context.Context, path string) (string, error) {
+	quota := h.sessionFor(ctx).quota
 	fullPath, err := h.resolvePath(path)
 	if err != nil {
 		return "", err
@@ -389,13 +346,23 @@ func (h *Handler) ReadFile(path string) (string, error) {
 	}
 
 	if info.IsDir() {
-		return "", fmt.Errorf("cannot read directory as file")
+		return "", shared.NewError(shared.CodeInvalidArgument, "cannot read directory as file")
+	}
+	// Devices and FIFOs report no size and may never end
+	if !info.Mode().IsRegular() {
+		return "", shared.NewError(shared.CodeInvalidArgument, "not a regular file: %s", path)
 	}
 
-	content, err := io.ReadAll(file)
+	if err := quota.checkRead(fullPath, info.Size()); err != nil {
+		return "", err
+	}
+
+	content, err := quota.readLimited(file)
 	if err != nil {
-		return "", fmt.Errorf("failed to read file content: %w", err)
+		return "", err
 	}
 
+	quota.recordRead(fullPath, int64(len(content)))
+
 	return string(content), nil
 }
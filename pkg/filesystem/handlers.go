@@ -2,11 +2,14 @@ package filesystem
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/kevsmith/my-mcp/pkg/shared"
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
 )
 
 // Navigation handlers
@@ -14,12 +17,12 @@ func ChangeDirectoryHandler(handler *Handler) func(ctx context.Context, request
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		var args ChangeDirectoryArgs
 		if err := shared.OptimizedUnmarshalRequest(request, &args); err != nil {
-			return mcp.NewToolResultError("Invalid arguments: " + err.Error()), nil
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments: %v", err), nil
 		}
 
 		err := handler.ChangeDirectory(args.Path)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to change directory: %v", err)), nil
+			return shared.ErrorResultFromErr("Failed to change directory", err), nil
 		}
 
 		return mcp.NewToolResultText(fmt.Sprintf("Changed directory to: %s", handler.GetCurrentDirectory())), nil
@@ -35,23 +38,234 @@ func GetCurrentDirectoryHandler(handler *Handler) func(ctx context.Context, requ
 
 func GetDirectoryInfoHandler(handler *Handler) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		dirInfo := handler.GetDirectoryInfo()
+		dirInfo := handler.GetDirectoryInfo(ctx)
 
 		return shared.OptimizedToolResultJSON(dirInfo)
 	}
 }
 
+func CreateWorkspaceHandler(handler *Handler) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args CreateWorkspaceArgs
+		if err := shared.OptimizedUnmarshalRequest(request, &args); err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments: %v", err), nil
+		}
+
+		var ttl time.Duration
+		if args.TTLMinutes != nil {
+			ttl = time.Duration(*args.TTLMinutes) * time.Minute
+		}
+
+		workspace, err := handler.CreateWorkspace(ctx, args.Label, args.Parent, ttl)
+		if err != nil {
+			return shared.ErrorResultFromErr("Failed to create workspace", err), nil
+		}
+		return shared.OptimizedToolResultJSON(workspace)
+	}
+}
+
+// Edit handlers
+func ReplaceInFileHandler(handler *Handler) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args ReplaceInFileArgs
+		if err := shared.OptimizedUnmarshalRequest(request, &args); err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments: %v", err), nil
+		}
+		if args.Path == "" {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "path parameter is required"), nil
+		}
+
+		result, err := handler.ReplaceInFile(args)
+		if err != nil {
+			return shared.ErrorResultFromErr("Failed to replace in file", err), nil
+		}
+		return shared.OptimizedToolResultJSON(result)
+	}
+}
+
+func ApplyPatchHandler(handler *Handler) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args ApplyPatchArgs
+		if err := shared.OptimizedUnmarshalRequest(request, &args); err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments: %v", err), nil
+		}
+		if args.Patch == "" {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "patch parameter is required"), nil
+		}
+
+		result, err := handler.ApplyPatch(args)
+		if err != nil {
+			return shared.ErrorResultFromErr("Failed to apply patch", err), nil
+		}
+		return shared.OptimizedToolResultJSON(result)
+	}
+}
+
+func SyncPathsHandler(handler *Handler) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args SyncPathsArgs
+		if err := shared.OptimizedUnmarshalRequest(request, &args); err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments: %v", err), nil
+		}
+		if args.Source == "" || args.Destination == "" {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "source and destination parameters are required"), nil
+		}
+
+		result, err := handler.SyncPaths(ctx, args, syncProgressNotifier(ctx, request))
+		if err != nil {
+			return shared.ErrorResultFromErr("Failed to sync paths", err), nil
+		}
+		return shared.OptimizedToolResultJSON(result)
+	}
+}
+
+// syncProgressNotifier returns a callback sending an MCP progress
+// notification when the client asked for progress with a token, else nil
+func syncProgressNotifier(ctx context.Context, request mcp.CallToolRequest) SyncProgressFunc {
+	if request.Params.Meta == nil || request.Params.Meta.ProgressToken == nil {
+		return nil
+	}
+	mcpServer := server.ServerFromContext(ctx)
+	if mcpServer == nil {
+		return nil
+	}
+	token := request.Params.Meta.ProgressToken
+	return func(done, total int64) {
+		// Progress is advisory; a client that went away doesn't stop the copy
+		_ = mcpServer.SendNotificationToClient(ctx, "notifications/progress", map[string]any{
+			"progressToken": token,
+			"progress":      done,
+			"total":         total,
+			"message":       fmt.Sprintf("Copied %d of %d bytes", done, total),
+		})
+	}
+}
+
+func QuotaStatusHandler(handler *Handler) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return shared.OptimizedToolResultJSON(handler.GetQuotaStatus(ctx))
+	}
+}
+
+func GetImageInfoHandler(handler *Handler) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args GetImageInfoArgs
+		if err := shared.OptimizedUnmarshalRequest(request, &args); err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments: %v", err), nil
+		}
+		if args.Path == "" {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "path parameter is required"), nil
+		}
+
+		thumbnailSize := 0
+		if args.Thumbnail {
+			thumbnailSize = defaultThumbnailSize
+			if args.ThumbnailSize != nil && *args.ThumbnailSize > 0 {
+				thumbnailSize = *args.ThumbnailSize
+			}
+		}
+
+		info, err := handler.GetImageInfo(ctx, args.Path, thumbnailSize)
+		if err != nil {
+			return shared.ErrorResultFromErr("Failed to get image info", err), nil
+		}
+		if info.Thumbnail == nil {
+			return shared.OptimizedToolResultJSON(info)
+		}
+
+		content, err := json.Marshal(info)
+		if err != nil {
+			return shared.ErrorResultFromErr("Failed to serialize results", err), nil
+		}
+		return mcp.NewToolResultImage(string(content), base64.StdEncoding.EncodeToString(info.ThumbnailData), info.Thumbnail.MIMEType), nil
+	}
+}
+
+func ReadStructuredHandler(handler *Handler) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args ReadStructuredArgs
+		if err := shared.OptimizedUnmarshalRequest(request, &args); err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments: %v", err), nil
+		}
+		if args.Path == "" {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "path parameter is required"), nil
+		}
+
+		result, err := handler.ReadStructured(ctx, args)
+		if err != nil {
+			return shared.ErrorResultFromErr("Failed to read structured file", err), nil
+		}
+
+		return shared.OptimizedToolResultJSON(result)
+	}
+}
+
+func ParseLogHandler(handler *Handler) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args ParseLogArgs
+		if err := shared.OptimizedUnmarshalRequest(request, &args); err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments: %v", err), nil
+		}
+		if args.Path == "" {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "path parameter is required"), nil
+		}
+
+		result, err := handler.ParseLog(ctx, args)
+		if err != nil {
+			return shared.ErrorResultFromErr("Failed to parse log", err), nil
+		}
+
+		return shared.OptimizedToolResultJSON(result)
+	}
+}
+
+// Change tracking handlers
+func SnapshotDirectoryHandler(handler *Handler) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args SnapshotDirectoryArgs
+		if err := shared.OptimizedUnmarshalRequest(request, &args); err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments: %v", err), nil
+		}
+
+		info, err := handler.SnapshotDirectory(ctx, args.Path, args.Exclude)
+		if err != nil {
+			return shared.ErrorResultFromErr("Failed to snapshot directory", err), nil
+		}
+
+		return shared.OptimizedToolResultJSON(info)
+	}
+}
+
+func DiffSnapshotHandler(handler *Handler) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args DiffSnapshotArgs
+		if err := shared.OptimizedUnmarshalRequest(request, &args); err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments: %v", err), nil
+		}
+		if args.SnapshotID == "" {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "snapshot_id parameter is required"), nil
+		}
+
+		diff, err := handler.DiffSnapshot(ctx, args.SnapshotID)
+		if err != nil {
+			return shared.ErrorResultFromErr("Failed to diff snapshot", err), nil
+		}
+
+		return shared.OptimizedToolResultJSON(diff)
+	}
+}
+
 // File operation handlers
 func ListDirectoryHandler(handler *Handler) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		var args ListDirectoryArgs
 		if err := shared.OptimizedUnmarshalRequest(request, &args); err != nil {
-			return mcp.NewToolResultError("Invalid arguments: " + err.Error()), nil
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments: %v", err), nil
 		}
 
 		files, err := handler.ListDirectory(args.Path)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to list directory: %v", err)), nil
+			return shared.ErrorResultFromErr("Failed to list directory", err), nil
 		}
 
 		return shared.OptimizedToolResultJSON(files)
@@ -63,20 +277,20 @@ func GlobHandler(handler *Handler) func(ctx context.Context, request mcp.CallToo
 		var args GlobArgs
 		argBytes, err := json.Marshal(request.Params.Arguments)
 		if err != nil {
-			return mcp.NewToolResultError("Failed to marshal arguments"), nil
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Failed to marshal arguments"), nil
 		}
 		if err := json.Unmarshal(argBytes, &args); err != nil {
-			return mcp.NewToolResultError("Invalid arguments"), nil
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments"), nil
 		}
 
 		result, err := handler.Glob(args.Pattern)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to glob pattern: %v", err)), nil
+			return shared.ErrorResultFromErr("Failed to glob pattern", err), nil
 		}
 
 		content, err := json.Marshal(result)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to serialize results: %v", err)), nil
+			return shared.ErrorResultFromErr("Failed to serialize results", err), nil
 		}
 
 		return mcp.NewToolResultText(string(content)), nil
@@ -88,20 +302,20 @@ func GetFileInfoHandler(handler *Handler) func(ctx context.Context, request mcp.
 		var args GetFileInfoArgs
 		argBytes, err := json.Marshal(request.Params.Arguments)
 		if err != nil {
-			return mcp.NewToolResultError("Failed to marshal arguments"), nil
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Failed to marshal arguments"), nil
 		}
 		if err := json.Unmarshal(argBytes, &args); err != nil {
-			return mcp.NewToolResultError("Invalid arguments"), nil
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments"), nil
 		}
 
-		fileInfo, err := handler.GetFileInfo(args.Path)
+		fileInfo, err := handler.GetFileInfo(args.Path, FileInfoOptions{ExtendedAttributes: args.IncludeExtendedAttributes})
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to get file info: %v", err)), nil
+			return shared.ErrorResultFromErr("Failed to get file info", err), nil
 		}
 
 		content, err := json.Marshal(fileInfo)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to serialize results: %v", err)), nil
+			return shared.ErrorResultFromErr("Failed to serialize results", err), nil
 		}
 
 		return mcp.NewToolResultText(string(content)), nil
@@ -113,15 +327,15 @@ func ReadFileHandler(handler *Handler) func(ctx context.Context, request mcp.Cal
 		var args ReadFileArgs
 		argBytes, err := json.Marshal(request.Params.Arguments)
 		if err != nil {
-			return mcp.NewToolResultError("Failed to marshal arguments"), nil
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Failed to marshal arguments"), nil
 		}
 		if err := json.Unmarshal(argBytes, &args); err != nil {
-			return mcp.NewToolResultError("Invalid arguments"), nil
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments"), nil
 		}
 
-		content, err := handler.ReadFile(args.Path)
+		content, err := handler.ReadFile(ctx, args.Path)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to read file: %v", err)), nil
+			return shared.ErrorResultFromErr("Failed to read file", err), nil
 		}
 
 		return mcp.NewToolResultText(content), nil
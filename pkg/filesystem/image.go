@@ -0,0 +1,390 @@
+package filesystem
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"image"
+	_ "image/gif" // Registers the GIF decoder
+	"image/jpeg"
+	"image/png"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/kevsmith/my-mcp/pkg/shared"
+	_ "golang.org/x/image/bmp" // Registers the BMP decoder
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/tiff" // Registers the TIFF decoder
+	_ "golang.org/x/image/webp" // Registers the WebP decoder
+)
+
+// Limits on get_image_info
+const (
+	defaultThumbnailSize = 256
+	maxThumbnailSize     = 1024
+	maxImageFileBytes    = 64 << 20    // Images are read whole
+	maxThumbnailPixels   = 100_000_000 // Larger images aren't decoded for thumbnails
+)
+
+// GetImageInfo returns an image's format, dimensions and basic EXIF data,
+// and with thumbnailSize above zero, a thumbnail that fits in a square of
+// that many pixels, turned upright by the EXIF orientation
+func (h *Handler) GetImageInfo(ctx context.Context, path string, thumbnailSize int) (*ImageInfo, error) {
+	quota := h.sessionFor(ctx).quota
+	fullPath, err := h.resolvePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	fileInfo, err := os.Stat(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file info: %w", err)
+	}
+	if fileInfo.IsDir() {
+		return nil, shared.NewError(shared.CodeInvalidArgument, "cannot read directory as file")
+	}
+	if fileInfo.Size() > maxImageFileBytes {
+		return nil, shared.NewError(shared.CodeTooLarge, "image is %d bytes; get_image_info reads images up to %d bytes", fileInfo.Size(), maxImageFileBytes)
+	}
+
+	content, err := os.ReadFile(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file content: %w", err)
+	}
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return nil, shared.NewError(shared.CodeUnsupportedFormat, "not a PNG, JPEG, GIF, WebP, BMP or TIFF image: %v", err)
+	}
+	info := &ImageInfo{Path: fullPath, Format: format, Width: config.Width, Height: config.Height, Size: fileInfo.Size()}
+
+	switch format {
+	case "jpeg":
+		if tiffData := jpegEXIF(content); tiffData != nil {
+			info.EXIF = parseEXIF(tiffData)
+		}
+	case "tiff":
+		info.EXIF = parseEXIF(content)
+	}
+
+	if thumbnailSize <= 0 {
+		return info, nil
+	}
+	if config.Width*config.Height > maxThumbnailPixels {
+		return nil, shared.NewError(shared.CodeTooLarge, "image is %dx%d; thumbnails are made for images up to %d pixels", config.Width, config.Height, maxThumbnailPixels)
+	}
+	if err := quota.checkRead(fullPath, 0); err != nil {
+		return nil, err
+	}
+
+	decoded, _, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	orientation := 0
+	if info.EXIF != nil {
+		orientation = info.EXIF.Orientation
+	}
+	thumb := orient(scaleToFit(decoded, min(thumbnailSize, maxThumbnailSize)), orientation)
+
+	var encoded bytes.Buffer
+	info.Thumbnail = &ThumbnailInfo{Width: thumb.Bounds().Dx(), Height: thumb.Bounds().Dy(), MIMEType: "image/jpeg"}
+	if thumb.Opaque() {
+		err = jpeg.Encode(&encoded, thumb, &jpeg.Options{Quality: 80})
+	} else {
+		info.Thumbnail.MIMEType = "image/png" // Keeps transparency
+		err = png.Encode(&encoded, thumb)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode thumbnail: %w", err)
+	}
+	if err := quota.checkRead(fullPath, int64(encoded.Len())); err != nil {
+		return nil, err
+	}
+	quota.recordRead(fullPath, int64(encoded.Len()))
+	info.ThumbnailData = encoded.Bytes()
+
+	return info, nil
+}
+
+// scaleToFit scales an image down to fit in a size by size square, keeping
+// its aspect ratio; smaller images keep their size
+func scaleToFit(src image.Image, size int) *image.RGBA {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if scale := float64(size) / float64(max(width, height)); scale < 1 {
+		width = max(1, int(math.Round(float64(width)*scale)))
+		height = max(1, int(math.Round(float64(height)*scale)))
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Src, nil)
+	return dst
+}
+
+// orient turns an image upright according to an EXIF orientation (1 to 8)
+func orient(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+	bounds := src.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w // Orientations 5 to 8 turn the image a quarter
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // Mirrored
+				dx, dy = w-1-x, y
+			case 3: // Upside down
+				dx, dy = w-1-x, h-1-y
+			case 4: // Mirrored upside down
+				dx, dy = x, h-1-y
+			case 5: // Mirrored and turned left
+				dx, dy = y, x
+			case 6: // Turned left, so rotate clockwise
+				dx, dy = h-1-y, x
+			case 7: // Mirrored and turned right
+				dx, dy = h-1-y, w-1-x
+			case 8: // Turned right, so rotate counterclockwise
+				dx, dy = y, w-1-x
+			}
+			dst.SetRGBA(dx, dy, src.RGBAAt(bounds.Min.X+x, bounds.Min.Y+y))
+		}
+	}
+	return dst
+}
+
+// jpegEXIF returns the TIFF-format EXIF data in a JPEG's APP1 segment, or nil
+func jpegEXIF(content []byte) []byte {
+	if len(content) < 4 || content[0] != 0xFF || content[1] != 0xD8 {
+		return nil
+	}
+	for i := 2; i+4 <= len(content); {
+		if content[i] != 0xFF {
+			return nil
+		}
+		marker := content[i+1]
+		if marker == 0xDA || marker == 0xD9 { // Image data follows; metadata comes before it
+			return nil
+		}
+		length := int(binary.BigEndian.Uint16(content[i+2:]))
+		end := i + 2 + length
+		if length < 2 || end > len(content) {
+			return nil
+		}
+		if segment := content[i+4 : end]; marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return segment[6:]
+		}
+		i = end
+	}
+	return nil
+}
+
+// EXIF and TIFF tags read by parseEXIF
+const (
+	tagMake             = 0x010F
+	tagModel            = 0x0110
+	tagOrientation      = 0x0112
+	tagSoftware         = 0x0131
+	tagDateTime         = 0x0132
+	tagExifIFD          = 0x8769
+	tagGPSIFD           = 0x8825
+	tagExposureTime     = 0x829A
+	tagFNumber          = 0x829D
+	tagISO              = 0x8827
+	tagDateTimeOriginal = 0x9003
+	tagFocalLength      = 0x920A
+	tagGPSLatitudeRef   = 0x0001
+	tagGPSLatitude      = 0x0002
+	tagGPSLongitudeRef  = 0x0003
+	tagGPSLongitude     = 0x0004
+)
+
+// tiffReader reads IFD entries from TIFF-format data
+type tiffReader struct {
+	data  []byte
+	order binary.ByteOrder
+}
+
+// ifdEntry is a tag's type, count and value or value offset
+type ifdEntry struct {
+	kind  uint16
+	count uint32
+	value []byte // The entry's 4 value bytes
+}
+
+// parseEXIF reads the camera, capture time, exposure and location from
+// TIFF-format EXIF data, or returns nil if there are none
+func parseEXIF(data []byte) *ImageEXIF {
+	if len(data) < 8 {
+		return nil
+	}
+	r := &tiffReader{data: data}
+	switch string(data[:2]) {
+	case "II":
+		r.order = binary.LittleEndian
+	case "MM":
+		r.order = binary.BigEndian
+	default:
+		return nil
+	}
+	if r.order.Uint16(data[2:]) != 42 {
+		return nil
+	}
+
+	ifd0 := r.ifd(r.order.Uint32(data[4:]))
+	exif := &ImageEXIF{
+		Make:     r.ascii(ifd0[tagMake]),
+		Model:    r.ascii(ifd0[tagModel]),
+		Software: r.ascii(ifd0[tagSoftware]),
+		DateTime: r.ascii(ifd0[tagDateTime]),
+	}
+	if values := r.uints(ifd0[tagOrientation]); len(values) > 0 {
+		exif.Orientation = int(values[0])
+	}
+
+	if entry, ok := ifd0[tagExifIFD]; ok {
+		if offsets := r.uints(entry); len(offsets) > 0 {
+			sub := r.ifd(uint32(offsets[0]))
+			if original := r.ascii(sub[tagDateTimeOriginal]); original != "" {
+				exif.DateTime = original
+			}
+			if num, den, ok := r.rational(sub[tagExposureTime]); ok && num > 0 {
+				if num < den {
+					exif.ExposureTime = fmt.Sprintf("1/%d s", int(math.Round(float64(den)/float64(num))))
+				} else {
+					exif.ExposureTime = fmt.Sprintf("%g s", float64(num)/float64(den))
+				}
+			}
+			if num, den, ok := r.rational(sub[tagFNumber]); ok {
+				exif.FNumber = math.Round(float64(num)/float64(den)*10) / 10
+			}
+			if values := r.uints(sub[tagISO]); len(values) > 0 {
+				exif.ISO = int(values[0])
+			}
+			if num, den, ok := r.rational(sub[tagFocalLength]); ok {
+				exif.FocalLength = math.Round(float64(num)/float64(den)*10) / 10
+			}
+		}
+	}
+
+	if entry, ok := ifd0[tagGPSIFD]; ok {
+		if offsets := r.uints(entry); len(offsets) > 0 {
+			gps := r.ifd(uint32(offsets[0]))
+			exif.Latitude = r.coordinate(gps[tagGPSLatitude], r.ascii(gps[tagGPSLatitudeRef]), "S")
+			exif.Longitude = r.coordinate(gps[tagGPSLongitude], r.ascii(gps[tagGPSLongitudeRef]), "W")
+		}
+	}
+
+	if *exif == (ImageEXIF{}) {
+		return nil
+	}
+	return exif
+}
+
+// ifd reads the entries of the IFD at offset
+func (r *tiffReader) ifd(offset uint32) map[uint16]ifdEntry {
+	entries := make(map[uint16]ifdEntry)
+	if int(offset)+2 > len(r.data) {
+		return entries
+	}
+	count := int(r.order.Uint16(r.data[offset:]))
+	for i := 0; i < count; i++ {
+		start := int(offset) + 2 + i*12
+		if start+12 > len(r.data) {
+			break
+		}
+		entry := r.data[start : start+12]
+		entries[r.order.Uint16(entry)] = ifdEntry{kind: r.order.Uint16(entry[2:]), count: r.order.Uint32(entry[4:]), value: entry[8:12]}
+	}
+	return entries
+}
+
+// bytes returns an entry's value bytes, which are stored elsewhere when they
+// don't fit in the entry
+func (r *tiffReader) bytes(entry ifdEntry, size int) []byte {
+	length := int(entry.count) * size
+	if length <= 4 {
+		return entry.value[:length]
+	}
+	offset := int(r.order.Uint32(entry.value))
+	if offset < 0 || offset+length > len(r.data) {
+		return nil
+	}
+	return r.data[offset : offset+length]
+}
+
+// ascii returns an ASCII entry's text
+func (r *tiffReader) ascii(entry ifdEntry) string {
+	if entry.kind != 2 {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimRight(string(r.bytes(entry, 1)), "\x00"))
+}
+
+// uints returns a SHORT or LONG entry's values
+func (r *tiffReader) uints(entry ifdEntry) []uint32 {
+	var values []uint32
+	switch entry.kind {
+	case 3:
+		raw := r.bytes(entry, 2)
+		for i := 0; i+2 <= len(raw); i += 2 {
+			values = append(values, uint32(r.order.Uint16(raw[i:])))
+		}
+	case 4:
+		raw := r.bytes(entry, 4)
+		for i := 0; i+4 <= len(raw); i += 4 {
+			values = append(values, r.order.Uint32(raw[i:]))
+		}
+	}
+	return values
+}
+
+// rationals returns a RATIONAL entry's numerator and denominator pairs
+func (r *tiffReader) rationals(entry ifdEntry) [][2]uint32 {
+	if entry.kind != 5 {
+		return nil
+	}
+	raw := r.bytes(entry, 8)
+	var values [][2]uint32
+	for i := 0; i+8 <= len(raw); i += 8 {
+		values = append(values, [2]uint32{r.order.Uint32(raw[i:]), r.order.Uint32(raw[i+4:])})
+	}
+	return values
+}
+
+// rational returns a RATIONAL entry's first value
+func (r *tiffReader) rational(entry ifdEntry) (uint32, uint32, bool) {
+	values := r.rationals(entry)
+	if len(values) == 0 || values[0][1] == 0 {
+		return 0, 0, false
+	}
+	return values[0][0], values[0][1], true
+}
+
+// coordinate converts a GPS degrees, minutes and seconds entry to decimal
+// degrees, negative for the given hemisphere reference
+func (r *tiffReader) coordinate(entry ifdEntry, ref, negative string) *float64 {
+	values := r.rationals(entry)
+	if len(values) != 3 {
+		return nil
+	}
+	var degrees float64
+	for i, unit := range []float64{1, 60, 3600} {
+		if values[i][1] == 0 {
+			return nil
+		}
+		degrees += float64(values[i][0]) / float64(values[i][1]) / unit
+	}
+	if strings.EqualFold(ref, negative) {
+		degrees = -degrees
+	}
+	degrees = math.Round(degrees*1e6) / 1e6
+	return &degrees
+}
@@ -0,0 +1,156 @@
+package filesystem
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kevsmith/my-mcp/pkg/shared"
+)
+
+// testEXIF builds little-endian TIFF-format EXIF data with a camera make,
+// orientation 6, an f-number of 2.8 and ISO 200
+func testEXIF() []byte {
+	var buf bytes.Buffer
+	write := func(values ...any) {
+		for _, value := range values {
+			binary.Write(&buf, binary.LittleEndian, value)
+		}
+	}
+	write([]byte("II"), uint16(42), uint32(8))
+	// IFD0 at 8: three entries, ending at 50
+	write(uint16(3))
+	write(uint16(tagMake), uint16(2), uint32(5), uint32(50))
+	write(uint16(tagOrientation), uint16(3), uint32(1), uint16(6), uint16(0))
+	write(uint16(tagExifIFD), uint16(4), uint32(1), uint32(56))
+	write(uint32(0))
+	write([]byte("Acme\x00\x00"))
+	// Exif IFD at 56: two entries, ending at 86
+	write(uint16(2))
+	write(uint16(tagFNumber), uint16(5), uint32(1), uint32(86))
+	write(uint16(tagISO), uint16(3), uint32(1), uint16(200), uint16(0))
+	write(uint32(0))
+	write(uint32(28), uint32(10))
+	return buf.Bytes()
+}
+
+func TestGetImageInfo(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	photo := image.NewRGBA(image.Rect(0, 0, 100, 50))
+	for y := 0; y < 50; y++ {
+		for x := 0; x < 100; x++ {
+			photo.Set(x, y, color.RGBA{R: uint8(x * 2), G: uint8(y * 4), B: 128, A: 255})
+		}
+	}
+	var encoded bytes.Buffer
+	if err := jpeg.Encode(&encoded, photo, nil); err != nil {
+		t.Fatal(err)
+	}
+	// Insert an APP1 EXIF segment after the start-of-image marker
+	exif := append([]byte("Exif\x00\x00"), testEXIF()...)
+	segment := append([]byte{0xFF, 0xE1, byte((len(exif) + 2) >> 8), byte(len(exif) + 2)}, exif...)
+	jpegData := append(append(append([]byte{}, encoded.Bytes()[:2]...), segment...), encoded.Bytes()[2:]...)
+	if err := os.WriteFile(filepath.Join(tmpDir, "photo.jpg"), jpegData, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	icon := image.NewNRGBA(image.Rect(0, 0, 20, 20))
+	icon.Set(5, 5, color.NRGBA{R: 255, A: 255})
+	encoded.Reset()
+	if err := png.Encode(&encoded, icon); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "icon.png"), encoded.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	handler, err := NewHandler([]string{tmpDir})
+	if err != nil {
+		t.Fatalf("Failed to create handler: %v", err)
+	}
+
+	// Metadata alone comes without a thumbnail
+	info, err := handler.GetImageInfo(context.Background(), "photo.jpg", 0)
+	if err != nil {
+		t.Fatalf("GetImageInfo failed: %v", err)
+	}
+	if info.Format != "jpeg" || info.Width != 100 || info.Height != 50 || info.Size != int64(len(jpegData)) || info.Thumbnail != nil {
+		t.Errorf("Unexpected info: %+v", info)
+	}
+	if info.EXIF == nil || info.EXIF.Make != "Acme" || info.EXIF.Orientation != 6 || info.EXIF.FNumber != 2.8 || info.EXIF.ISO != 200 {
+		t.Errorf("Unexpected EXIF: %+v", info.EXIF)
+	}
+
+	// The thumbnail is scaled down and turned upright
+	info, err = handler.GetImageInfo(context.Background(), "photo.jpg", 40)
+	if err != nil {
+		t.Fatalf("GetImageInfo with thumbnail failed: %v", err)
+	}
+	if info.Thumbnail == nil || info.Thumbnail.Width != 20 || info.Thumbnail.Height != 40 || info.Thumbnail.MIMEType != "image/jpeg" {
+		t.Fatalf("Unexpected thumbnail: %+v", info.Thumbnail)
+	}
+	if thumb, err := jpeg.Decode(bytes.NewReader(info.ThumbnailData)); err != nil || thumb.Bounds().Dx() != 20 || thumb.Bounds().Dy() != 40 {
+		t.Errorf("Thumbnail data doesn't decode to 20x40: %v", err)
+	}
+
+	// Transparent images keep their alpha as PNG, and small ones aren't enlarged
+	info, err = handler.GetImageInfo(context.Background(), "icon.png", 64)
+	if err != nil {
+		t.Fatalf("GetImageInfo on PNG failed: %v", err)
+	}
+	if info.Format != "png" || info.EXIF != nil || info.Thumbnail == nil || info.Thumbnail.MIMEType != "image/png" || info.Thumbnail.Width != 20 {
+		t.Errorf("Unexpected PNG info: %+v, thumbnail %+v", info, info.Thumbnail)
+	}
+
+	// Files that aren't images are rejected
+	if err := os.WriteFile(filepath.Join(tmpDir, "notes.txt"), []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var toolErr *shared.ToolError
+	if _, err := handler.GetImageInfo(context.Background(), "notes.txt", 0); !errors.As(err, &toolErr) || toolErr.Code != shared.CodeUnsupportedFormat {
+		t.Errorf("Expected unsupported format error, got %v", err)
+	}
+}
+
+func TestOrient(t *testing.T) {
+	// A 2x1 image, red then blue
+	src := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	red, blue := color.RGBA{R: 255, A: 255}, color.RGBA{B: 255, A: 255}
+	src.SetRGBA(0, 0, red)
+	src.SetRGBA(1, 0, blue)
+
+	tests := []struct {
+		orientation int
+		want        [][]color.RGBA // Rows of the result
+	}{
+		{1, [][]color.RGBA{{red, blue}}},
+		{2, [][]color.RGBA{{blue, red}}},
+		{3, [][]color.RGBA{{blue, red}}},
+		{6, [][]color.RGBA{{red}, {blue}}},
+		{8, [][]color.RGBA{{blue}, {red}}},
+	}
+	for _, tt := range tests {
+		got := orient(src, tt.orientation)
+		if got.Bounds().Dy() != len(tt.want) || got.Bounds().Dx() != len(tt.want[0]) {
+			t.Errorf("orientation %d: size %v", tt.orientation, got.Bounds())
+			continue
+		}
+		for y, row := range tt.want {
+			for x, want := range row {
+				if pixel := got.RGBAAt(x, y); pixel != want {
+					t.Errorf("orientation %d: pixel (%d,%d) = %v, want %v", tt.orientation, x, y, pixel, want)
+				}
+			}
+		}
+	}
+}
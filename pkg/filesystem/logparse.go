@@ -0,0 +1,457 @@
+package filesystem
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kevsmith/my-mcp/pkg/shared"
+)
+
+// Log formats parse_log understands
+const (
+	LogFormatAuto   = "auto" // Detect the format of each line
+	LogFormatJSON   = "json" // One JSON object per line
+	LogFormatLogfmt = "logfmt"
+	LogFormatSyslog = "syslog" // RFC 3164 or RFC 5424
+	LogFormatText   = "text"   // A timestamp and level followed by the message
+
+	logFormatUnknown = "unknown" // Lines before the first entry, or in a log of no known format
+)
+
+// Limits on parse_log results
+const (
+	defaultLogLimit = 100
+	maxLogLimit     = 1000
+	maxLogLineBytes = 1 << 20
+)
+
+// logLevels are the normalized levels from least to most severe
+var logLevels = []string{"trace", "debug", "info", "warn", "error", "fatal"}
+
+// levelAliases maps level names used by common loggers and syslog to the
+// normalized levels
+var levelAliases = map[string]string{
+	"trace": "trace", "debug": "debug", "dbg": "debug",
+	"info": "info", "information": "info", "informational": "info", "notice": "info",
+	"warn": "warn", "warning": "warn",
+	"error": "error", "err": "error",
+	"fatal": "fatal", "panic": "fatal", "critical": "fatal", "crit": "fatal", "alert": "fatal", "emerg": "fatal", "emergency": "fatal",
+}
+
+// syslogSeverities are the levels of syslog's severity codes 0 to 7
+var syslogSeverities = []string{"fatal", "fatal", "fatal", "error", "warn", "info", "info", "debug"}
+
+// Keys that hold an entry's time, level and message in JSON and logfmt lines
+var (
+	timeKeys    = []string{"time", "timestamp", "ts", "@timestamp", "datetime", "date"}
+	levelKeys   = []string{"level", "lvl", "severity", "loglevel", "log.level"}
+	messageKeys = []string{"msg", "message", "@message", "text"}
+)
+
+var (
+	syslog5424Regex = regexp.MustCompile(`^<(\d{1,3})>1 (\S+) (\S+) (\S+) (\S+) (\S+) (-|\[.*?\]) ?(.*)$`)
+	syslog3164Regex = regexp.MustCompile(`^(?:<(\d{1,3})>)?([A-Z][a-z]{2} [ \d]\d \d{2}:\d{2}:\d{2}) (\S+) ([^:\[\s]+)(?:\[(\d+)\])?: ?(.*)$`)
+	textLogRegex    = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:[.,]\d+)?(?:Z|[+-]\d{2}:?\d{2})?)\s+\[?([A-Za-z]+)\]?:?\s+(.*)$`)
+)
+
+// timeLayouts are the timestamp layouts tried, most specific first
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999Z0700",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05,999999999",
+}
+
+// ParseLog reads a log file and returns its last entries, optionally only
+// those at or above a level and within a time range. Lines that don't start
+// an entry, such as stack traces, are appended to the entry before them.
+// Logs in no known format come back a line per entry.
+func (h *Handler) ParseLog(ctx context.Context, args ParseLogArgs) (*LogParseResult, error) {
+	quota := h.sessionFor(ctx).quota
+	format := LogFormatAuto
+	if args.Format != nil && *args.Format != "" {
+		format = strings.ToLower(*args.Format)
+	}
+	if !slices.Contains([]string{LogFormatAuto, LogFormatJSON, LogFormatLogfmt, LogFormatSyslog, LogFormatText}, format) {
+		return nil, shared.NewError(shared.CodeInvalidArgument, "unknown format %q (use auto, json, logfmt, syslog or text)", format)
+	}
+
+	minLevel := -1
+	if args.Level != nil && *args.Level != "" {
+		level, ok := levelAliases[strings.ToLower(*args.Level)]
+		if !ok {
+			return nil, shared.NewError(shared.CodeInvalidArgument, "unknown level %q (use trace, debug, info, warn, error or fatal)", *args.Level)
+		}
+		minLevel = slices.Index(logLevels, level)
+	}
+
+	var since, until time.Time
+	for _, bound := range []struct {
+		name  string
+		value *string
+		dest  *time.Time
+	}{{"since", args.Since, &since}, {"until", args.Until, &until}} {
+		if bound.value == nil || *bound.value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, *bound.value)
+		if err != nil {
+			return nil, shared.NewError(shared.CodeInvalidArgument, "%s must be an RFC 3339 time such as 2024-05-01T09:00:00Z: %v", bound.name, err)
+		}
+		*bound.dest = parsed
+	}
+
+	limit := defaultLogLimit
+	if args.Limit != nil && *args.Limit > 0 {
+		limit = min(*args.Limit, maxLogLimit)
+	}
+
+	fullPath, err := h.resolvePath(args.Path)
+	if err != nil {
+		return nil, err
+	}
+	if err := quota.checkRead(fullPath, 0); err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+	if info, err := file.Stat(); err == nil && info.IsDir() {
+		return nil, shared.NewError(shared.CodeInvalidArgument, "cannot read directory as file")
+	}
+
+	matches := func(entry *LogEntry) bool {
+		if minLevel >= 0 && slices.Index(logLevels, entry.Level) < minLevel {
+			return false // Entries without a level can't be placed, so a level filter drops them
+		}
+		if !since.IsZero() && (entry.Time == nil || entry.Time.Before(since)) {
+			return false
+		}
+		if !until.IsZero() && (entry.Time == nil || entry.Time.After(until)) {
+			return false
+		}
+		return true
+	}
+
+	result := &LogParseResult{Path: fullPath, Entries: []LogEntry{}}
+	var current *LogEntry
+	flush := func() {
+		if current == nil || !matches(current) {
+			return
+		}
+		result.Total++
+		result.Entries = append(result.Entries, *current)
+		if len(result.Entries) >= 2*limit {
+			result.Entries = slices.Clone(result.Entries[len(result.Entries)-limit:])
+		}
+	}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), maxLogLineBytes)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		entry, ok := parseLogLine(line, format)
+		if !ok && current != nil && current.Format != logFormatUnknown {
+			current.Message += "\n" + line
+			continue
+		}
+		flush()
+		if !ok {
+			entry = &LogEntry{Format: logFormatUnknown, Message: line}
+		}
+		entry.Line = lineNumber
+		current = entry
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read log: %w", err)
+	}
+	flush()
+
+	if len(result.Entries) > limit {
+		result.Entries = result.Entries[len(result.Entries)-limit:]
+	}
+	result.Returned = len(result.Entries)
+	result.Truncated = result.Total > result.Returned
+
+	var size int64
+	for _, entry := range result.Entries {
+		size += int64(len(entry.Message))
+		for key, value := range entry.Fields {
+			size += int64(len(key) + len(value))
+		}
+	}
+	if err := quota.checkRead(fullPath, size); err != nil {
+		return nil, err
+	}
+	quota.recordRead(fullPath, size)
+
+	return result, nil
+}
+
+// parseLogLine parses a line that starts an entry in the given format, or in
+// any format for auto
+func parseLogLine(line, format string) (*LogEntry, bool) {
+	parsers := []struct {
+		format string
+		parse  func(string) (*LogEntry, bool)
+	}{
+		{LogFormatJSON, parseJSONLine},
+		{LogFormatSyslog, parseSyslogLine},
+		{LogFormatText, parseTextLine},
+		{LogFormatLogfmt, parseLogfmtLine}, // Last, since other formats' messages often hold key=value pairs
+	}
+	for _, parser := range parsers {
+		if format != LogFormatAuto && format != parser.format {
+			continue
+		}
+		if entry, ok := parser.parse(line); ok {
+			entry.Format = parser.format
+			return entry, true
+		}
+	}
+	return nil, false
+}
+
+// parseJSONLine parses a JSON object line
+func parseJSONLine(line string) (*LogEntry, bool) {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, "{") {
+		return nil, false
+	}
+	var object map[string]any
+	if err := json.Unmarshal([]byte(trimmed), &object); err != nil {
+		return nil, false
+	}
+
+	fields := make(map[string]string, len(object))
+	for key, value := range object {
+		switch v := value.(type) {
+		case string:
+			fields[key] = v
+		case float64:
+			fields[key] = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			encoded, _ := json.Marshal(v)
+			fields[key] = string(encoded)
+		}
+	}
+	entry := entryFromFields(fields)
+
+	// Loggers such as pino and bunyan write numeric levels and epoch times
+	for _, key := range levelKeys {
+		if number, ok := object[key].(float64); ok {
+			entry.Level = numericLevel(number)
+		}
+	}
+	for _, key := range timeKeys {
+		if number, ok := object[key].(float64); ok && entry.Time == nil {
+			entry.Time = epochTime(number)
+		}
+	}
+	return entry, true
+}
+
+// parseLogfmtLine parses a line of key=value pairs that names at least a
+// level or message
+func parseLogfmtLine(line string) (*LogEntry, bool) {
+	fields, ok := parseLogfmt(line)
+	if !ok {
+		return nil, false
+	}
+	for _, key := range slices.Concat(levelKeys, messageKeys) {
+		if _, ok := fields[key]; ok {
+			return entryFromFields(fields), true
+		}
+	}
+	return nil, false
+}
+
+// parseLogfmt splits a logfmt line into its pairs; quoted values may hold
+// spaces and escapes. Every word must be a key or a key=value pair.
+func parseLogfmt(line string) (map[string]string, bool) {
+	fields := make(map[string]string)
+	for i := 0; i < len(line); {
+		if line[i] == ' ' || line[i] == '\t' {
+			i++
+			continue
+		}
+		start := i
+		for i < len(line) && line[i] != '=' && line[i] != ' ' && line[i] != '"' {
+			i++
+		}
+		key := line[start:i]
+		if key == "" {
+			return nil, false
+		}
+		if i >= len(line) || line[i] != '=' {
+			if i < len(line) && line[i] == '"' {
+				return nil, false
+			}
+			fields[key] = "true"
+			continue
+		}
+		i++ // Skip '='
+
+		if i < len(line) && line[i] == '"' {
+			end := i + 1
+			for end < len(line) && line[end] != '"' {
+				if line[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(line) {
+				return nil, false
+			}
+			value, err := strconv.Unquote(line[i : end+1])
+			if err != nil {
+				return nil, false
+			}
+			fields[key] = value
+			i = end + 1
+			continue
+		}
+		start = i
+		for i < len(line) && line[i] != ' ' && line[i] != '\t' {
+			i++
+		}
+		fields[key] = line[start:i]
+	}
+	return fields, len(fields) > 0
+}
+
+// parseSyslogLine parses an RFC 5424 or RFC 3164 syslog line
+func parseSyslogLine(line string) (*LogEntry, bool) {
+	if match := syslog5424Regex.FindStringSubmatch(line); match != nil {
+		entry := &LogEntry{Level: syslogLevel(match[1]), Message: match[8], Fields: map[string]string{}}
+		if parsed, err := time.Parse(time.RFC3339Nano, match[2]); err == nil {
+			entry.Time = &parsed
+		}
+		for i, key := range []string{"host", "app", "pid", "msgid"} {
+			if value := match[3+i]; value != "-" {
+				entry.Fields[key] = value
+			}
+		}
+		return entry, true
+	}
+
+	if match := syslog3164Regex.FindStringSubmatch(line); match != nil {
+		entry := &LogEntry{Level: syslogLevel(match[1]), Message: match[6], Fields: map[string]string{"host": match[3], "app": match[4]}}
+		if match[5] != "" {
+			entry.Fields["pid"] = match[5]
+		}
+		// RFC 3164 times have no year; assume the most recent one that isn't in the future
+		if parsed, err := time.ParseInLocation("Jan _2 15:04:05", match[2], time.Local); err == nil {
+			now := time.Now()
+			parsed = parsed.AddDate(now.Year(), 0, 0)
+			if parsed.After(now.Add(24 * time.Hour)) {
+				parsed = parsed.AddDate(-1, 0, 0)
+			}
+			entry.Time = &parsed
+		}
+		return entry, true
+	}
+	return nil, false
+}
+
+// parseTextLine parses a plain line that starts with a timestamp and a level
+func parseTextLine(line string) (*LogEntry, bool) {
+	match := textLogRegex.FindStringSubmatch(line)
+	if match == nil {
+		return nil, false
+	}
+	level, ok := levelAliases[strings.ToLower(match[2])]
+	if !ok {
+		return nil, false
+	}
+	return &LogEntry{Time: parseLogTime(match[1]), Level: level, Message: match[3]}, true
+}
+
+// entryFromFields builds an entry from parsed key/value fields, taking the
+// time, level and message out of them
+func entryFromFields(fields map[string]string) *LogEntry {
+	entry := &LogEntry{}
+	take := func(keys []string) string {
+		for _, key := range keys {
+			if value, ok := fields[key]; ok {
+				delete(fields, key)
+				return value
+			}
+		}
+		return ""
+	}
+	if value := take(timeKeys); value != "" {
+		entry.Time = parseLogTime(value)
+	}
+	entry.Level = levelAliases[strings.ToLower(take(levelKeys))]
+	entry.Message = take(messageKeys)
+	if len(fields) > 0 {
+		entry.Fields = fields
+	}
+	return entry
+}
+
+// parseLogTime parses a timestamp in any of timeLayouts, or as epoch seconds
+// or milliseconds
+func parseLogTime(value string) *time.Time {
+	for _, layout := range timeLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return &parsed
+		}
+	}
+	if number, err := strconv.ParseFloat(value, 64); err == nil {
+		return epochTime(number)
+	}
+	return nil
+}
+
+// epochTime converts epoch seconds, or milliseconds for values too large to
+// be seconds, to a time
+func epochTime(number float64) *time.Time {
+	if number <= 0 {
+		return nil
+	}
+	if number > 1e11 {
+		number /= 1000
+	}
+	seconds, fraction := math.Modf(number)
+	parsed := time.Unix(int64(seconds), int64(fraction*1e9)).UTC()
+	return &parsed
+}
+
+// numericLevel maps pino/bunyan numeric levels (10 trace to 60 fatal)
+func numericLevel(number float64) string {
+	index := int(number)/10 - 1
+	if index < 0 || index >= len(logLevels) {
+		return ""
+	}
+	return logLevels[index]
+}
+
+// syslogLevel maps a syslog priority to a level by its severity
+func syslogLevel(priority string) string {
+	value, err := strconv.Atoi(priority)
+	if err != nil {
+		return ""
+	}
+	return syslogSeverities[value%8]
+}
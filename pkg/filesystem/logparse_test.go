@@ -0,0 +1,114 @@
+package filesystem
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLog(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	log := strings.Join([]string{
+		`{"time":"2024-05-01T09:00:00Z","level":"info","msg":"server started","port":8080}`,
+		`{"time":1714554060000,"level":40,"msg":"slow request"}`,
+		`time=2024-05-01T09:02:00Z level=error msg="upstream failed" service=billing`,
+		`<11>1 2024-05-01T09:03:00Z web01 api 4242 - - connection reset`,
+		`2024-05-01 09:04:00,123 ERROR Unhandled exception`,
+		`Traceback (most recent call last):`,
+		`  File "app.py", line 3, in <module>`,
+	}, "\n")
+	path := filepath.Join(tmpDir, "app.log")
+	if err := os.WriteFile(path, []byte(log), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	handler, err := NewHandler([]string{tmpDir})
+	if err != nil {
+		t.Fatalf("Failed to create handler: %v", err)
+	}
+
+	result, err := handler.ParseLog(context.Background(), ParseLogArgs{Path: "app.log"})
+	if err != nil {
+		t.Fatalf("ParseLog failed: %v", err)
+	}
+	if result.Total != 5 || len(result.Entries) != 5 {
+		t.Fatalf("Expected 5 entries, got %d: %+v", result.Total, result.Entries)
+	}
+
+	want := []struct {
+		format, level, message string
+		minute                 int
+	}{
+		{LogFormatJSON, "info", "server started", 0},
+		{LogFormatJSON, "warn", "slow request", 1},
+		{LogFormatLogfmt, "error", "upstream failed", 2},
+		{LogFormatSyslog, "error", "connection reset", 3},
+		{LogFormatText, "error", "Unhandled exception\nTraceback (most recent call last):\n  File \"app.py\", line 3, in <module>", 4},
+	}
+	for i, w := range want {
+		entry := result.Entries[i]
+		if entry.Format != w.format || entry.Level != w.level || entry.Message != w.message {
+			t.Errorf("Entry %d = %s/%s/%q, want %s/%s/%q", i, entry.Format, entry.Level, entry.Message, w.format, w.level, w.message)
+		}
+		if entry.Time == nil || entry.Time.UTC().Minute() != w.minute {
+			t.Errorf("Entry %d time = %v, want minute %d", i, entry.Time, w.minute)
+		}
+	}
+	if result.Entries[0].Fields["port"] != "8080" || result.Entries[2].Fields["service"] != "billing" || result.Entries[3].Fields["host"] != "web01" {
+		t.Errorf("Unexpected fields: %v %v %v", result.Entries[0].Fields, result.Entries[2].Fields, result.Entries[3].Fields)
+	}
+
+	// Level and time filters, keeping the most recent entries
+	level, since, limit := "error", "2024-05-01T09:01:30Z", 2
+	result, err = handler.ParseLog(context.Background(), ParseLogArgs{Path: "app.log", Level: &level, Since: &since, Limit: &limit})
+	if err != nil {
+		t.Fatalf("ParseLog with filters failed: %v", err)
+	}
+	if result.Total != 3 || result.Returned != 2 || !result.Truncated || result.Entries[0].Line != 4 {
+		t.Errorf("Filtered result = %+v", result)
+	}
+
+	until := "2024-05-01T09:00:30Z"
+	result, _ = handler.ParseLog(context.Background(), ParseLogArgs{Path: "app.log", Until: &until})
+	if result.Total != 1 || result.Entries[0].Message != "server started" {
+		t.Errorf("Until filter = %+v", result.Entries)
+	}
+
+	bad := "verbose"
+	if _, err := handler.ParseLog(context.Background(), ParseLogArgs{Path: "app.log", Level: &bad}); err == nil {
+		t.Error("Expected an error for an unknown level")
+	}
+}
+
+func TestParseLogUnknownFormat(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+	os.WriteFile(filepath.Join(tmpDir, "notes.log"), []byte("first line\nsecond line\n"), 0644)
+
+	handler, _ := NewHandler([]string{tmpDir})
+	result, err := handler.ParseLog(context.Background(), ParseLogArgs{Path: "notes.log"})
+	if err != nil {
+		t.Fatalf("ParseLog failed: %v", err)
+	}
+	if len(result.Entries) != 2 || result.Entries[1].Message != "second line" || result.Entries[1].Format != "unknown" {
+		t.Errorf("Expected a line per entry, got %+v", result.Entries)
+	}
+}
+
+func TestParseSyslog3164(t *testing.T) {
+	entry, ok := parseSyslogLine("<34>Oct 11 22:14:15 mymachine su[230]: 'su root' failed for lonvick")
+	if !ok {
+		t.Fatal("Expected an RFC 3164 line to parse")
+	}
+	if entry.Level != "fatal" || entry.Fields["app"] != "su" || entry.Fields["pid"] != "230" || entry.Message != "'su root' failed for lonvick" {
+		t.Errorf("Unexpected entry: %+v", entry)
+	}
+	if entry.Time == nil || entry.Time.After(time.Now().Add(24*time.Hour)) || entry.Time.Month() != time.October {
+		t.Errorf("Unexpected time: %v", entry.Time)
+	}
+}
@@ -0,0 +1,375 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/kevsmith/my-mcp/pkg/shared"
+)
+
+// Statuses of a file changed by apply_patch
+const (
+	PatchModified = "modified"
+	PatchCreated  = "created"
+	PatchDeleted  = "deleted"
+)
+
+// hunkHeaderPattern matches "@@ -start,count +start,count @@"; counts of 1
+// may be left out
+var hunkHeaderPattern = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// filePatch is one file's section of a unified diff
+type filePatch struct {
+	oldPath string // Empty for /dev/null, when the file is created
+	newPath string // Empty for /dev/null, when the file is deleted
+	hunks   []*hunk
+}
+
+// hunk is a run of context, removed and added lines
+type hunk struct {
+	header   string
+	oldStart int
+	oldLines int
+	newStart int
+	newLines int
+	lines    []hunkLine
+	oldNoEOL bool // The old side's last line has no newline
+	newNoEOL bool // The new side's last line has no newline
+}
+
+type hunkLine struct {
+	op   byte // ' ', '-' or '+'
+	text string
+}
+
+// before returns the lines the hunk expects to find
+func (h *hunk) before() []string {
+	var lines []string
+	for _, line := range h.lines {
+		if line.op != '+' {
+			lines = append(lines, line.text)
+		}
+	}
+	return lines
+}
+
+// after returns the lines the hunk leaves in their place
+func (h *hunk) after() []string {
+	var lines []string
+	for _, line := range h.lines {
+		if line.op != '-' {
+			lines = append(lines, line.text)
+		}
+	}
+	return lines
+}
+
+// parsePatch splits a unified diff into files and hunks. Lines other than
+// file headers and hunks, such as git's "diff --git" and "index", are skipped.
+func parsePatch(patch string) ([]*filePatch, error) {
+	lines := strings.Split(strings.ReplaceAll(patch, "\r\n", "\n"), "\n")
+	var files []*filePatch
+	var current *filePatch
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if strings.HasPrefix(line, "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ ") {
+			current = &filePatch{oldPath: patchPath(line[4:]), newPath: patchPath(lines[i+1][4:])}
+			if current.oldPath == "" && current.newPath == "" {
+				return nil, shared.NewError(shared.CodeInvalidArgument, "line %d: both file headers are /dev/null", i+1)
+			}
+			stripGitPrefixes(current)
+			files = append(files, current)
+			i++
+			continue
+		}
+
+		match := hunkHeaderPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		if current == nil {
+			return nil, shared.NewError(shared.CodeInvalidArgument, "line %d: hunk before any ---/+++ file header", i+1)
+		}
+		h := &hunk{header: line}
+		h.oldStart, h.oldLines = hunkRange(match[1], match[2])
+		h.newStart, h.newLines = hunkRange(match[3], match[4])
+
+		oldLeft, newLeft := h.oldLines, h.newLines
+		for oldLeft > 0 || newLeft > 0 {
+			i++
+			if i >= len(lines) {
+				return nil, shared.NewError(shared.CodeInvalidArgument, "hunk %q ends early", h.header)
+			}
+			body := lines[i]
+			if strings.HasPrefix(body, `\`) {
+				h.markNoEOL()
+				continue
+			}
+			if body == "" {
+				body = " " // Editors often strip the space from blank context lines
+			}
+			switch body[0] {
+			case ' ':
+				oldLeft--
+				newLeft--
+			case '-':
+				oldLeft--
+			case '+':
+				newLeft--
+			default:
+				return nil, shared.NewError(shared.CodeInvalidArgument, "line %d: unexpected %q in hunk %q", i+1, body, h.header)
+			}
+			if oldLeft < 0 || newLeft < 0 {
+				return nil, shared.NewError(shared.CodeInvalidArgument, "line %d: hunk %q has more lines than its header counts", i+1, h.header)
+			}
+			h.lines = append(h.lines, hunkLine{op: body[0], text: body[1:]})
+		}
+		if i+1 < len(lines) && strings.HasPrefix(lines[i+1], `\`) {
+			i++
+			h.markNoEOL()
+		}
+		current.hunks = append(current.hunks, h)
+	}
+
+	for _, file := range files {
+		if len(file.hunks) == 0 && file.oldPath != "" && file.newPath != "" {
+			return nil, shared.NewError(shared.CodeInvalidArgument, "no hunks for %s", file.newPath)
+		}
+	}
+	return files, nil
+}
+
+// markNoEOL applies a "\ No newline at end of file" marker to the side of
+// the line before it
+func (h *hunk) markNoEOL() {
+	if len(h.lines) == 0 {
+		return
+	}
+	switch h.lines[len(h.lines)-1].op {
+	case ' ':
+		h.oldNoEOL, h.newNoEOL = true, true
+	case '-':
+		h.oldNoEOL = true
+	case '+':
+		h.newNoEOL = true
+	}
+}
+
+// hunkRange parses a hunk header's start and optional count
+func hunkRange(start, count string) (int, int) {
+	s, _ := strconv.Atoi(start)
+	if count == "" {
+		return s, 1
+	}
+	c, _ := strconv.Atoi(count)
+	return s, c
+}
+
+// patchPath returns the path in a ---/+++ header, without any timestamp, or
+// "" for /dev/null
+func patchPath(header string) string {
+	path, _, _ := strings.Cut(header, "\t")
+	path = strings.TrimSpace(path)
+	if path == "/dev/null" {
+		return ""
+	}
+	return path
+}
+
+// stripGitPrefixes removes the a/ and b/ prefixes git puts on paths
+func stripGitPrefixes(file *filePatch) {
+	if (file.oldPath == "" || strings.HasPrefix(file.oldPath, "a/")) && (file.newPath == "" || strings.HasPrefix(file.newPath, "b/")) {
+		file.oldPath = strings.TrimPrefix(file.oldPath, "a/")
+		file.newPath = strings.TrimPrefix(file.newPath, "b/")
+	}
+}
+
+// applyHunks applies hunks to content in order. A hunk whose lines aren't at
+// the position its header gives is looked for nearby, as patch does, but
+// its lines must match exactly. CRLF line endings are kept.
+func applyHunks(content string, hunks []*hunk) (string, error) {
+	ending := "\n"
+	if strings.Contains(content, "\r\n") {
+		ending = "\r\n"
+	}
+	finalNewline := strings.HasSuffix(content, "\n")
+	var lines []string
+	if content != "" {
+		lines = strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	}
+	for i := range lines {
+		lines[i] = strings.TrimSuffix(lines[i], "\r")
+	}
+
+	var result []string
+	pos := 0 // Lines before pos have been copied or replaced
+	for _, h := range hunks {
+		old := h.before()
+		expected := h.oldStart - 1
+		if len(old) == 0 {
+			expected = h.oldStart // Pure insertions name the line they follow
+		}
+		start := findLines(lines, old, max(expected, pos), pos)
+		if start < 0 {
+			return "", shared.NewError(shared.CodeInvalidArgument, "hunk %q doesn't match the file", h.header)
+		}
+		result = append(result, lines[pos:start]...)
+		result = append(result, h.after()...)
+		pos = start + len(old)
+		if pos == len(lines) {
+			finalNewline = !h.newNoEOL
+		}
+	}
+	result = append(result, lines[pos:]...)
+
+	if len(result) == 0 {
+		return "", nil
+	}
+	text := strings.Join(result, ending)
+	if finalNewline {
+		text += ending
+	}
+	return text, nil
+}
+
+// findLines returns where want occurs in lines at or after floor, trying
+// the expected position first and then ever further from it, or -1
+func findLines(lines, want []string, expected, floor int) int {
+	matches := func(start int) bool {
+		if start < floor || start+len(want) > len(lines) {
+			return false
+		}
+		for i, line := range want {
+			if lines[start+i] != line {
+				return false
+			}
+		}
+		return true
+	}
+	for delta := 0; expected-delta >= floor || expected+delta+len(want) <= len(lines); delta++ {
+		if matches(expected + delta) {
+			return expected + delta
+		}
+		if delta > 0 && matches(expected-delta) {
+			return expected - delta
+		}
+	}
+	return -1
+}
+
+// patchTarget is a file apply_patch will write once every file's hunks apply
+type patchTarget struct {
+	path    string
+	status  string
+	content string
+	perm    os.FileMode
+}
+
+// ApplyPatch applies a unified diff, such as git diff or diff -u produce,
+// to the files it names relative to the current directory. Every hunk of
+// every file must apply before any file is written.
+func (h *Handler) ApplyPatch(args ApplyPatchArgs) (*PatchResult, error) {
+	if err := h.checkWriteEnabled(); err != nil {
+		return nil, err
+	}
+	files, err := parsePatch(args.Patch)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, shared.NewError(shared.CodeInvalidArgument, "patch has no ---/+++ file headers")
+	}
+	if args.Path != nil && *args.Path != "" && len(files) > 1 {
+		return nil, shared.NewError(shared.CodeInvalidArgument, "path can only be given for a patch to one file; this one changes %d", len(files))
+	}
+
+	result := &PatchResult{Files: make([]PatchedFile, 0, len(files)), DryRun: args.DryRun}
+	targets := make([]patchTarget, 0, len(files))
+	seen := make(map[string]bool)
+	for _, file := range files {
+		name := file.newPath
+		status := PatchModified
+		switch {
+		case file.oldPath == "":
+			status = PatchCreated
+		case file.newPath == "":
+			name, status = file.oldPath, PatchDeleted
+		case file.oldPath != file.newPath:
+			return nil, shared.NewError(shared.CodeInvalidArgument, "renaming %s to %s isn't supported", file.oldPath, file.newPath)
+		}
+		if args.Path != nil && *args.Path != "" {
+			name = *args.Path
+		}
+
+		fullPath, err := h.resolvePath(name)
+		if err != nil {
+			return nil, err
+		}
+		if seen[fullPath] {
+			return nil, shared.NewError(shared.CodeInvalidArgument, "patch changes %s more than once", fullPath)
+		}
+		seen[fullPath] = true
+
+		patched := PatchedFile{Path: fullPath, Status: status, Hunks: len(file.hunks)}
+		target := patchTarget{path: fullPath, status: status, perm: 0o644}
+		var before []byte
+		if status == PatchCreated {
+			if _, err := os.Lstat(fullPath); err == nil {
+				return nil, shared.NewError(shared.CodeInvalidArgument, "%s already exists; the patch creates it", fullPath)
+			}
+		} else {
+			if before, target.perm, err = readForEdit(fullPath); err != nil {
+				return nil, err
+			}
+			patched.BeforeSHA256 = contentSHA256(before)
+		}
+
+		target.content, err = applyHunks(string(before), file.hunks)
+		if err != nil {
+			return nil, shared.NewError(shared.CodeInvalidArgument, "%s: %w", fullPath, err)
+		}
+		if status == PatchDeleted {
+			if target.content != "" {
+				return nil, shared.NewError(shared.CodeInvalidArgument, "%s: the patch deletes the file but leaves lines in it", fullPath)
+			}
+		} else {
+			patched.AfterSHA256 = contentSHA256([]byte(target.content))
+		}
+		for _, fileHunk := range file.hunks {
+			for _, line := range fileHunk.lines {
+				switch line.op {
+				case '+':
+					patched.LinesAdded++
+				case '-':
+					patched.LinesRemoved++
+				}
+			}
+		}
+		targets = append(targets, target)
+		result.Files = append(result.Files, patched)
+	}
+
+	if args.DryRun {
+		return result, nil
+	}
+	for _, target := range targets {
+		switch target.status {
+		case PatchDeleted:
+			err = os.Remove(target.path)
+		case PatchCreated:
+			if err = os.MkdirAll(filepath.Dir(target.path), 0o755); err == nil {
+				err = writeFileAtomic(target.path, []byte(target.content), target.perm)
+			}
+		default:
+			err = writeFileAtomic(target.path, []byte(target.content), target.perm)
+		}
+		if err != nil {
+			return nil, shared.NewError(shared.CodeInternal, "patch was only partly applied: %w", err)
+		}
+	}
+	return result, nil
+}
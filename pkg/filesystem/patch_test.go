@@ -0,0 +1,154 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApplyPatch(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+	t.Setenv("FS_ALLOW_WRITE", "true")
+
+	files := map[string]string{
+		"main.go":    "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"hi\")\n}\n",
+		"old.txt":    "obsolete\n",
+		"notes.txt":  "one\r\ntwo\r\nthree\r\n",
+		"config.ini": "a=1\nb=2",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	handler, err := NewHandler([]string{tmpDir})
+	if err != nil {
+		t.Fatalf("Failed to create handler: %v", err)
+	}
+	read := func(name string) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(tmpDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	// A git diff that modifies, creates and deletes files. The main.go hunk
+	// claims line 3 though its lines start at line 5.
+	patch := `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -3,3 +3,4 @@
+ func main() {
+-	fmt.Println("hi")
++	fmt.Println("hello")
++	fmt.Println("bye")
+ }
+diff --git a/docs/new.md b/docs/new.md
+new file mode 100644
+--- /dev/null
++++ b/docs/new.md
+@@ -0,0 +1,2 @@
++# New
++text
+--- a/old.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-obsolete
+--- notes.txt
++++ notes.txt
+@@ -1,3 +1,3 @@
+ one
+-two
++2
+ three
+--- config.ini
++++ config.ini
+@@ -1,2 +1,3 @@
+ a=1
+-b=2
+\ No newline at end of file
++b=3
++c=4
+`
+	result, err := handler.ApplyPatch(ApplyPatchArgs{Patch: patch, DryRun: true})
+	if err != nil {
+		t.Fatalf("Dry run failed: %v", err)
+	}
+	if len(result.Files) != 5 || read("main.go") != files["main.go"] {
+		t.Fatalf("Dry run wrote files or missed some: %+v", result)
+	}
+
+	result, err = handler.ApplyPatch(ApplyPatchArgs{Patch: patch})
+	if err != nil {
+		t.Fatalf("ApplyPatch failed: %v", err)
+	}
+	statuses := map[string]string{}
+	for _, file := range result.Files {
+		statuses[filepath.Base(file.Path)] = file.Status
+	}
+	if statuses["main.go"] != PatchModified || statuses["new.md"] != PatchCreated || statuses["old.txt"] != PatchDeleted {
+		t.Errorf("Unexpected statuses: %v", statuses)
+	}
+	main := result.Files[0]
+	if main.LinesAdded != 2 || main.LinesRemoved != 1 || main.BeforeSHA256 != contentSHA256([]byte(files["main.go"])) || main.AfterSHA256 != contentSHA256([]byte(read("main.go"))) {
+		t.Errorf("Unexpected main.go result: %+v", main)
+	}
+	if got := read("main.go"); got != "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"hello\")\n\tfmt.Println(\"bye\")\n}\n" {
+		t.Errorf("main.go = %q", got)
+	}
+	if got := read("docs/new.md"); got != "# New\ntext\n" {
+		t.Errorf("docs/new.md = %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "old.txt")); !os.IsNotExist(err) {
+		t.Errorf("old.txt wasn't deleted: %v", err)
+	}
+	if got := read("notes.txt"); got != "one\r\n2\r\nthree\r\n" {
+		t.Errorf("notes.txt lost its CRLF endings: %q", got)
+	}
+	if got := read("config.ini"); got != "a=1\nb=3\nc=4\n" {
+		t.Errorf("config.ini = %q", got)
+	}
+
+	// If any hunk fails, no file is written
+	bad := `--- main.go
++++ main.go
+@@ -1 +1 @@
+-package main
++package app
+--- notes.txt
++++ notes.txt
+@@ -1 +1 @@
+-missing
++found
+`
+	if _, err := handler.ApplyPatch(ApplyPatchArgs{Patch: bad}); err == nil {
+		t.Error("Expected an error for a hunk that doesn't match")
+	}
+	if got := read("main.go"); got[:12] != "package main" {
+		t.Error("main.go was changed by a failed patch")
+	}
+
+	// path redirects a single-file diff
+	other := "copy.go"
+	if err := os.WriteFile(filepath.Join(tmpDir, other), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := handler.ApplyPatch(ApplyPatchArgs{Patch: bad[:strings.Index(bad, "--- notes.txt")], Path: &other}); err != nil {
+		t.Fatalf("ApplyPatch with path failed: %v", err)
+	}
+	if got := read(other); got != "package app\n" {
+		t.Errorf("copy.go = %q", got)
+	}
+
+	// Malformed patches are rejected
+	for _, patch := range []string{"", "not a diff", "@@ -1 +1 @@\n-a\n+b\n", "--- a\n+++ a\n@@ -1,2 +1,2 @@\n-x\n"} {
+		if _, err := handler.ApplyPatch(ApplyPatchArgs{Patch: patch}); err == nil {
+			t.Errorf("Expected an error for patch %q", patch)
+		}
+	}
+}
@@ -3,13 +3,15 @@
 package filesystem
 
 import (
+	"os"
 	"syscall"
 	"time"
 )
 
-func extractCreationTime(stat interface{}) time.Time {
-	if sysStat, ok := stat.(*syscall.Stat_t); ok {
-		return time.Unix(sysStat.Birthtimespec.Sec, sysStat.Birthtimespec.Nsec)
+// creationTime returns a file's birth time, which APFS and HFS+ record
+func creationTime(_ string, info os.FileInfo) (time.Time, bool) {
+	if sysStat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return time.Unix(sysStat.Birthtimespec.Sec, sysStat.Birthtimespec.Nsec), true
 	}
-	return time.Time{}
+	return time.Time{}, false
 }
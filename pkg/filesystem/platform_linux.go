@@ -3,14 +3,23 @@
 package filesystem
 
 import (
-	"syscall"
+	"os"
 	"time"
+
+	"golang.org/x/sys/unix"
 )
 
-func extractCreationTime(stat interface{}) time.Time {
-	if sysStat, ok := stat.(*syscall.Stat_t); ok {
-		// Linux doesn't have birth time, use ctime (status change time) as fallback
-		return time.Unix(sysStat.Ctim.Sec, sysStat.Ctim.Nsec)
+// creationTime returns a file's birth time from statx, which needs Linux
+// 4.11 and a filesystem that records it (ext4, btrfs, xfs and tmpfs do). It
+// reports false rather than guessing when the time isn't available.
+func creationTime(path string, info os.FileInfo) (time.Time, bool) {
+	flags := 0
+	if info.Mode()&os.ModeSymlink != 0 {
+		flags = unix.AT_SYMLINK_NOFOLLOW // Describe the link, as info does
+	}
+	var stx unix.Statx_t
+	if err := unix.Statx(unix.AT_FDCWD, path, flags, unix.STATX_BTIME, &stx); err != nil || stx.Mask&unix.STATX_BTIME == 0 {
+		return time.Time{}, false
 	}
-	return time.Time{}
+	return time.Unix(stx.Btime.Sec, int64(stx.Btime.Nsec)), true
 }
@@ -3,13 +3,15 @@
 package filesystem
 
 import (
+	"os"
 	"syscall"
 	"time"
 )
 
-func extractCreationTime(stat interface{}) time.Time {
-	if winStat, ok := stat.(*syscall.Win32FileAttributeData); ok {
-		return time.Unix(0, winStat.CreationTime.Nanoseconds())
+// creationTime returns a file's creation time, which NTFS always records
+func creationTime(_ string, info os.FileInfo) (time.Time, bool) {
+	if winStat, ok := info.Sys().(*syscall.Win32FileAttributeData); ok {
+		return time.Unix(0, winStat.CreationTime.Nanoseconds()), true
 	}
-	return time.Time{}
+	return time.Time{}, false
 }
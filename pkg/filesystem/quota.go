@@ -0,0 +1,137 @@
+package filesystem
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/kevsmith/my-mcp/pkg/shared"
+)
+
+// QuotaConfig limits how much file content a session may pull into the model context.
+// Zero values mean unlimited.
+type QuotaConfig struct {
+	MaxBytes int64 // Maximum total bytes of file content returned
+	MaxFiles int   // Maximum number of distinct files read
+}
+
+// GetQuotaConfig returns quota configuration from environment variables or defaults
+func GetQuotaConfig() QuotaConfig {
+	var config QuotaConfig
+
+	if maxBytesStr := os.Getenv("FS_QUOTA_MAX_BYTES"); maxBytesStr != "" {
+		if maxBytes, err := strconv.ParseInt(maxBytesStr, 10, 64); err == nil && maxBytes > 0 {
+			config.MaxBytes = maxBytes
+		}
+	}
+
+	if maxFilesStr := os.Getenv("FS_QUOTA_MAX_FILES"); maxFilesStr != "" {
+		if maxFiles, err := strconv.Atoi(maxFilesStr); err == nil && maxFiles > 0 {
+			config.MaxFiles = maxFiles
+		}
+	}
+
+	return config
+}
+
+// QuotaStatus reports quota limits and usage for the current session
+type QuotaStatus struct {
+	MaxBytes       int64  `json:"max_bytes"`                 // 0 means unlimited
+	BytesUsed      int64  `json:"bytes_used"`                // Bytes of file content returned so far
+	BytesRemaining *int64 `json:"bytes_remaining,omitempty"` // Omitted when unlimited
+	MaxFiles       int    `json:"max_files"`                 // 0 means unlimited
+	FilesUsed      int    `json:"files_used"`                // Distinct files read so far
+	FilesRemaining *int   `json:"files_remaining,omitempty"` // Omitted when unlimited
+	Exhausted      bool   `json:"exhausted"`                 // True when a limit has been reached
+}
+
+// sessionQuota tracks usage against a QuotaConfig; safe for concurrent use
+type sessionQuota struct {
+	mu        sync.Mutex
+	config    QuotaConfig
+	bytesUsed int64
+	files     map[string]struct{}
+}
+
+func newSessionQuota(config QuotaConfig) *sessionQuota {
+	return &sessionQuota{
+		config: config,
+		files:  make(map[string]struct{}),
+	}
+}
+
+// checkRead verifies that reading size bytes from path fits in the remaining quota
+func (q *sessionQuota) checkRead(path string, size int64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, seen := q.files[path]; !seen && q.config.MaxFiles > 0 && len(q.files) >= q.config.MaxFiles {
+		return shared.NewError(shared.CodeQuotaExceeded, "quota exceeded: session may read at most %d files", q.config.MaxFiles)
+	}
+
+	if q.config.MaxBytes > 0 && q.bytesUsed+size > q.config.MaxBytes {
+		return shared.NewError(shared.CodeQuotaExceeded, "quota exceeded: reading %d bytes would exceed the session limit of %d bytes (%d remaining)",
+			size, q.config.MaxBytes, q.config.MaxBytes-q.bytesUsed)
+	}
+
+	return nil
+}
+
+// readLimited reads r to the end, failing once it yields more than the
+// remaining byte quota. Files can report a smaller size than they yield, so
+// the size checked by checkRead doesn't bound the read.
+func (q *sessionQuota) readLimited(r io.Reader) ([]byte, error) {
+	q.mu.Lock()
+	remaining := q.config.MaxBytes - q.bytesUsed
+	q.mu.Unlock()
+	if q.config.MaxBytes > 0 {
+		r = io.LimitReader(r, remaining+1)
+	}
+
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file content: %w", err)
+	}
+	if q.config.MaxBytes > 0 && int64(len(content)) > remaining {
+		return nil, shared.NewError(shared.CodeQuotaExceeded, "quota exceeded: file yields more than the %d bytes remaining of the session limit of %d bytes",
+			remaining, q.config.MaxBytes)
+	}
+	return content, nil
+}
+
+// recordRead charges a completed read against the quota
+func (q *sessionQuota) recordRead(path string, size int64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.files[path] = struct{}{}
+	q.bytesUsed += size
+}
+
+// status returns a snapshot of quota usage
+func (q *sessionQuota) status() QuotaStatus {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	status := QuotaStatus{
+		MaxBytes:  q.config.MaxBytes,
+		BytesUsed: q.bytesUsed,
+		MaxFiles:  q.config.MaxFiles,
+		FilesUsed: len(q.files),
+	}
+
+	if q.config.MaxBytes > 0 {
+		remaining := max(0, q.config.MaxBytes-q.bytesUsed)
+		status.BytesRemaining = &remaining
+		status.Exhausted = remaining == 0
+	}
+	if q.config.MaxFiles > 0 {
+		remaining := max(0, q.config.MaxFiles-len(q.files))
+		status.FilesRemaining = &remaining
+		status.Exhausted = status.Exhausted || remaining == 0
+	}
+
+	return status
+}
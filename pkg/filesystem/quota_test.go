@@ -0,0 +1,159 @@
+package filesystem
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestQuotaMaxBytes(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	// "test content" is 12 bytes, "sub content" is 11 bytes
+	handler, err := NewHandlerWithQuota([]string{tmpDir}, QuotaConfig{MaxBytes: 20})
+	if err != nil {
+		t.Fatalf("Failed to create handler: %v", err)
+	}
+
+	if _, err := handler.ReadFile(context.Background(), "test.txt"); err != nil {
+		t.Fatalf("First read should fit in quota: %v", err)
+	}
+
+	_, err = handler.ReadFile(context.Background(), "subdir/sub.txt")
+	if err == nil || !strings.Contains(err.Error(), "quota exceeded") {
+		t.Fatalf("Expected quota exceeded error, got: %v", err)
+	}
+
+	status := handler.GetQuotaStatus(context.Background())
+	if status.BytesUsed != 12 {
+		t.Errorf("Expected 12 bytes used, got %d", status.BytesUsed)
+	}
+	if status.BytesRemaining == nil || *status.BytesRemaining != 8 {
+		t.Errorf("Expected 8 bytes remaining, got %v", status.BytesRemaining)
+	}
+	if status.FilesRemaining != nil {
+		t.Error("Files remaining should be omitted when unlimited")
+	}
+	if status.Exhausted {
+		t.Error("Quota should not be exhausted yet")
+	}
+}
+
+func TestQuotaMaxFiles(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	handler, err := NewHandlerWithQuota([]string{tmpDir}, QuotaConfig{MaxFiles: 1})
+	if err != nil {
+		t.Fatalf("Failed to create handler: %v", err)
+	}
+
+	if _, err := handler.ReadFile(context.Background(), "test.txt"); err != nil {
+		t.Fatalf("First read should fit in quota: %v", err)
+	}
+
+	// Re-reading the same file does not count as a new file
+	if _, err := handler.ReadFile(context.Background(), filepath.Join(tmpDir, "test.txt")); err != nil {
+		t.Fatalf("Re-reading a file should not consume file quota: %v", err)
+	}
+
+	_, err = handler.ReadFile(context.Background(), "subdir/sub.txt")
+	if err == nil || !strings.Contains(err.Error(), "at most 1 files") {
+		t.Fatalf("Expected file quota error, got: %v", err)
+	}
+
+	status := handler.GetQuotaStatus(context.Background())
+	if status.FilesUsed != 1 || status.BytesUsed != 24 {
+		t.Errorf("Unexpected usage: %+v", status)
+	}
+	if !status.Exhausted {
+		t.Error("Quota should be exhausted after reaching the file limit")
+	}
+}
+
+func TestQuotaUnlimitedByDefault(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	t.Setenv("FS_QUOTA_MAX_BYTES", "")
+	t.Setenv("FS_QUOTA_MAX_FILES", "")
+
+	handler, err := NewHandler([]string{tmpDir})
+	if err != nil {
+		t.Fatalf("Failed to create handler: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := handler.ReadFile(context.Background(), "test.txt"); err != nil {
+			t.Fatalf("Read should succeed without quota: %v", err)
+		}
+	}
+
+	status := handler.GetQuotaStatus(context.Background())
+	if status.MaxBytes != 0 || status.MaxFiles != 0 || status.Exhausted {
+		t.Errorf("Expected unlimited quota, got %+v", status)
+	}
+}
+
+func TestGetQuotaConfig(t *testing.T) {
+	t.Setenv("FS_QUOTA_MAX_BYTES", "1048576")
+	t.Setenv("FS_QUOTA_MAX_FILES", "25")
+
+	config := GetQuotaConfig()
+	if config.MaxBytes != 1048576 || config.MaxFiles != 25 {
+		t.Errorf("Unexpected config from environment: %+v", config)
+	}
+
+	t.Setenv("FS_QUOTA_MAX_BYTES", "-5")
+	t.Setenv("FS_QUOTA_MAX_FILES", "lots")
+
+	config = GetQuotaConfig()
+	if config.MaxBytes != 0 || config.MaxFiles != 0 {
+		t.Errorf("Invalid values should leave quota unlimited, got %+v", config)
+	}
+}
+
+func TestQuotaRejectsOversizedFileBeforeReading(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	bigFile := filepath.Join(tmpDir, "big.txt")
+	if err := os.WriteFile(bigFile, make([]byte, 1024), 0644); err != nil {
+		t.Fatalf("Failed to create big file: %v", err)
+	}
+
+	handler, err := NewHandlerWithQuota([]string{tmpDir}, QuotaConfig{MaxBytes: 100})
+	if err != nil {
+		t.Fatalf("Failed to create handler: %v", err)
+	}
+
+	if _, err := handler.ReadFile(context.Background(), "big.txt"); err == nil {
+		t.Fatal("Expected oversized read to be rejected")
+	}
+
+	status := handler.GetQuotaStatus(context.Background())
+	if status.BytesUsed != 0 || status.FilesUsed != 0 {
+		t.Errorf("Rejected read should not be charged, got %+v", status)
+	}
+}
+
+func TestQuotaBoundsReadsBeyondReportedSize(t *testing.T) {
+	quota := newSessionQuota(QuotaConfig{MaxBytes: 100})
+	quota.recordRead("seen.txt", 40)
+
+	// A file reporting size 0 can still yield more than the quota allows
+	if _, err := quota.readLimited(strings.NewReader(strings.Repeat("x", 61))); err == nil || !strings.Contains(err.Error(), "quota exceeded") {
+		t.Errorf("Expected quota exceeded error, got: %v", err)
+	}
+	if content, err := quota.readLimited(strings.NewReader(strings.Repeat("x", 60))); err != nil || len(content) != 60 {
+		t.Errorf("Expected the remaining 60 bytes to be read, got %d bytes, %v", len(content), err)
+	}
+
+	unlimited := newSessionQuota(QuotaConfig{})
+	if content, err := unlimited.readLimited(strings.NewReader(strings.Repeat("x", 1000))); err != nil || len(content) != 1000 {
+		t.Errorf("Unlimited read returned %d bytes, %v", len(content), err)
+	}
+}
@@ -0,0 +1,45 @@
+//go:build linux || darwin
+
+package filesystem
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestReadFileRefusesFIFO(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+	if err := unix.Mkfifo(filepath.Join(tmpDir, "pipe"), 0644); err != nil {
+		t.Skipf("FIFOs aren't supported here: %v", err)
+	}
+
+	handler, err := NewHandlerWithQuota([]string{tmpDir}, QuotaConfig{MaxBytes: 100})
+	if err != nil {
+		t.Fatalf("Failed to create handler: %v", err)
+	}
+
+	// Opening a FIFO blocks until a writer appears, so open it for
+	// writing too to keep the read from hanging when the check fails
+	done := make(chan error, 1)
+	go func() {
+		_, err := handler.ReadFile(context.Background(), "pipe")
+		done <- err
+	}()
+	writer, err := unix.Open(filepath.Join(tmpDir, "pipe"), unix.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unix.Close(writer)
+
+	if err := <-done; err == nil || !strings.Contains(err.Error(), "not a regular file") {
+		t.Errorf("Expected a non-regular file error, got: %v", err)
+	}
+	if status := handler.GetQuotaStatus(context.Background()); status.FilesUsed != 0 {
+		t.Errorf("Refused read should not be charged, got %+v", status)
+	}
+}
@@ -0,0 +1,60 @@
+package filesystem
+
+import (
+	"context"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// sessionState is what a Handler keeps for one client session: its read
+// quota, snapshots and workspaces
+type sessionState struct {
+	quota      *sessionQuota
+	snapshots  snapshotStore
+	workspaces workspaceStore
+}
+
+// sessionFor returns the state of the client session of a call, creating it
+// on first use. Calls without a client session share one state.
+func (h *Handler) sessionFor(ctx context.Context) *sessionState {
+	id := ""
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		id = session.SessionID()
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	state, ok := h.sessions[id]
+	if !ok {
+		state = &sessionState{quota: newSessionQuota(h.quotaConfig)}
+		h.sessions[id] = state
+	}
+	return state
+}
+
+// EndSession drops a client session's quota usage and snapshots and removes
+// its workspaces; the server calls it when the client disconnects
+func (h *Handler) EndSession(id string) {
+	h.mu.Lock()
+	state, ok := h.sessions[id]
+	delete(h.sessions, id)
+	h.mu.Unlock()
+
+	if ok {
+		state.workspaces.close()
+	}
+}
+
+// Close removes every session's workspaces ahead of their expiry
+func (h *Handler) Close() {
+	h.mu.Lock()
+	states := make([]*sessionState, 0, len(h.sessions))
+	for _, state := range h.sessions {
+		states = append(states, state)
+	}
+	h.mu.Unlock()
+
+	for _, state := range states {
+		state.workspaces.close()
+	}
+}
@@ -0,0 +1,225 @@
+package filesystem
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kevsmith/my-mcp/pkg/shared"
+)
+
+// Snapshot limits
+const (
+	maxSnapshots     = 10      // Snapshots kept per session; the oldest is dropped first
+	maxSnapshotFiles = 100_000 // Files a single snapshot may record
+)
+
+// snapshotEntry is one file recorded in a snapshot
+type snapshotEntry struct {
+	size     int64
+	modified time.Time
+	sha256   string
+}
+
+// snapshot is a directory manifest: every regular file under root by
+// slash-separated relative path
+type snapshot struct {
+	id      string
+	root    string
+	exclude []string
+	taken   time.Time
+	files   map[string]snapshotEntry
+}
+
+// snapshotStore holds a session's snapshots; safe for concurrent use
+type snapshotStore struct {
+	mu        sync.Mutex
+	next      int
+	snapshots []*snapshot // Oldest first
+}
+
+func (s *snapshotStore) add(snap *snapshot) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.next++
+	snap.id = strconv.Itoa(s.next)
+	s.snapshots = append(s.snapshots, snap)
+	if len(s.snapshots) > maxSnapshots {
+		s.snapshots = s.snapshots[1:]
+	}
+}
+
+func (s *snapshotStore) get(id string) (*snapshot, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, snap := range s.snapshots {
+		if snap.id == id {
+			return snap, true
+		}
+	}
+	return nil, false
+}
+
+// SnapshotDirectory records the size and SHA-256 of every file under a
+// directory so a later DiffSnapshot can report what changed
+func (h *Handler) SnapshotDirectory(ctx context.Context, path *string, exclude []string) (*SnapshotInfo, error) {
+	root := h.currentWD
+	if path != nil && *path != "" {
+		resolved, err := h.resolvePath(*path)
+		if err != nil {
+			return nil, err
+		}
+		root = resolved
+	}
+	for _, pattern := range exclude {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, shared.NewError(shared.CodeInvalidArgument, "invalid exclude pattern %q: %v", pattern, err)
+		}
+	}
+
+	files, err := scanDirectory(root, exclude, nil)
+	if err != nil {
+		return nil, err
+	}
+	snap := &snapshot{root: root, exclude: exclude, taken: time.Now(), files: files}
+	h.sessionFor(ctx).snapshots.add(snap)
+
+	info := &SnapshotInfo{ID: snap.id, Root: root, Files: len(files), Taken: snap.taken}
+	for _, entry := range files {
+		info.TotalBytes += entry.size
+	}
+	return info, nil
+}
+
+// DiffSnapshot compares a snapshot's directory with its current contents
+func (h *Handler) DiffSnapshot(ctx context.Context, id string) (*SnapshotDiff, error) {
+	snap, ok := h.sessionFor(ctx).snapshots.get(id)
+	if !ok {
+		return nil, shared.NewError(shared.CodeNotFound, "snapshot %s does not exist (only the last %d are kept)", id, maxSnapshots)
+	}
+	if !h.isPathAllowed(snap.root) {
+		return nil, shared.NewError(shared.CodeAccessDenied, "snapshot root %s is outside the allowed roots", snap.root)
+	}
+
+	current, err := scanDirectory(snap.root, snap.exclude, snap.files)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &SnapshotDiff{ID: snap.id, Root: snap.root, Taken: snap.taken, Created: []string{}, Modified: []FileChange{}, Deleted: []string{}}
+	for path, entry := range current {
+		old, existed := snap.files[path]
+		switch {
+		case !existed:
+			diff.Created = append(diff.Created, path)
+		case old.sha256 != entry.sha256:
+			diff.Modified = append(diff.Modified, FileChange{Path: path, OldSize: old.size, NewSize: entry.size})
+		default:
+			diff.Unchanged++
+		}
+	}
+	for path := range snap.files {
+		if _, exists := current[path]; !exists {
+			diff.Deleted = append(diff.Deleted, path)
+		}
+	}
+
+	sort.Strings(diff.Created)
+	sort.Strings(diff.Deleted)
+	sort.Slice(diff.Modified, func(i, j int) bool {
+		return diff.Modified[i].Path < diff.Modified[j].Path
+	})
+	return diff, nil
+}
+
+// scanDirectory hashes every regular file under root, skipping files and
+// directories whose names match an exclude pattern. Files whose size and
+// modification time match previous are assumed unchanged and not re-read.
+func scanDirectory(root string, exclude []string, previous map[string]snapshotEntry) (map[string]snapshotEntry, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, fmt.Errorf("directory does not exist: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, shared.NewError(shared.CodeInvalidArgument, "not a directory: %s", root)
+	}
+
+	files := make(map[string]snapshotEntry)
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			return nil // Unreadable entries are left out rather than failing the scan
+		}
+		if path != root && excluded(d.Name(), exclude) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil // Directories are implied by their files; links aren't followed
+		}
+		if len(files) >= maxSnapshotFiles {
+			return shared.NewError(shared.CodeTooLarge, "directory has more than %d files; snapshot a subdirectory or exclude some", maxSnapshotFiles)
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+
+		entry := snapshotEntry{size: info.Size(), modified: info.ModTime()}
+		if old, ok := previous[rel]; ok && old.size == entry.size && old.modified.Equal(entry.modified) {
+			entry.sha256 = old.sha256
+		} else if entry.sha256, err = hashFile(path); err != nil {
+			return nil
+		}
+		files[rel] = entry
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// excluded reports whether a file or directory name matches any pattern
+func excluded(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// hashFile returns the hex SHA-256 of a file's content
+func hashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
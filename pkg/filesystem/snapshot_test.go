@@ -0,0 +1,66 @@
+package filesystem
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSnapshotDiff(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+	if err := os.MkdirAll(filepath.Join(tmpDir, ".git", "objects"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(tmpDir, ".git", "objects", "ab"), []byte("object"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "unchanged.txt"), []byte("same"), 0644)
+
+	handler, err := NewHandler([]string{tmpDir})
+	if err != nil {
+		t.Fatalf("Failed to create handler: %v", err)
+	}
+
+	info, err := handler.SnapshotDirectory(context.Background(), nil, []string{".git"})
+	if err != nil {
+		t.Fatalf("SnapshotDirectory failed: %v", err)
+	}
+	if info.ID != "1" || info.Files != 3 || info.TotalBytes != int64(len("test content")+len("sub content")+len("same")) {
+		t.Errorf("Unexpected snapshot: %+v", info)
+	}
+
+	// Change the tree the way a build would
+	os.WriteFile(filepath.Join(tmpDir, "test.txt"), []byte("rewritten content"), 0644)
+	os.Remove(filepath.Join(tmpDir, "subdir", "sub.txt"))
+	os.WriteFile(filepath.Join(tmpDir, "subdir", "output.bin"), []byte{1, 2, 3}, 0644)
+	os.WriteFile(filepath.Join(tmpDir, ".git", "objects", "cd"), []byte("ignored"), 0644)
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(filepath.Join(tmpDir, "unchanged.txt"), later, later) // Touched but not changed
+
+	diff, err := handler.DiffSnapshot(context.Background(), info.ID)
+	if err != nil {
+		t.Fatalf("DiffSnapshot failed: %v", err)
+	}
+	if !slices.Equal(diff.Created, []string{"subdir/output.bin"}) {
+		t.Errorf("Created = %v", diff.Created)
+	}
+	if len(diff.Modified) != 1 || diff.Modified[0] != (FileChange{Path: "test.txt", OldSize: 12, NewSize: 17}) {
+		t.Errorf("Modified = %+v", diff.Modified)
+	}
+	if !slices.Equal(diff.Deleted, []string{"subdir/sub.txt"}) {
+		t.Errorf("Deleted = %v", diff.Deleted)
+	}
+	if diff.Unchanged != 1 {
+		t.Errorf("Unchanged = %d, want 1", diff.Unchanged)
+	}
+
+	if _, err := handler.DiffSnapshot(context.Background(), "99"); err == nil {
+		t.Error("Expected an error for an unknown snapshot")
+	}
+	outside := "/etc"
+	if _, err := handler.SnapshotDirectory(context.Background(), &outside, nil); err == nil {
+		t.Error("Expected an error snapshotting outside the allowed roots")
+	}
+}
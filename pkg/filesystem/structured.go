@@ -0,0 +1,442 @@
+package filesystem
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/kevsmith/my-mcp/pkg/shared"
+	"gopkg.in/yaml.v3"
+)
+
+// Structured file formats read_structured understands
+const (
+	StructuredCSV  = "csv"
+	StructuredTSV  = "tsv"
+	StructuredJSON = "json"
+	StructuredYAML = "yaml"
+	StructuredTOML = "toml"
+)
+
+// Limits on read_structured
+const (
+	defaultStructuredLimit = 100
+	maxStructuredLimit     = 1000
+	maxStructuredFileBytes = 32 << 20 // Files are parsed whole, so larger ones are refused
+)
+
+// structuredExtensions maps file extensions to formats
+var structuredExtensions = map[string]string{
+	".csv":  StructuredCSV,
+	".tsv":  StructuredTSV,
+	".json": StructuredJSON,
+	".yaml": StructuredYAML,
+	".yml":  StructuredYAML,
+	".toml": StructuredTOML,
+}
+
+// ReadStructured parses a CSV, TSV, JSON, YAML or TOML file, optionally
+// selects part of it with a JSONPath-like expression, and pages arrays
+func (h *Handler) ReadStructured(ctx context.Context, args ReadStructuredArgs) (*StructuredResult, error) {
+	quota := h.sessionFor(ctx).quota
+	fullPath, err := h.resolvePath(args.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	format := structuredExtensions[strings.ToLower(filepath.Ext(fullPath))]
+	if args.Format != nil && *args.Format != "" {
+		format = strings.ToLower(*args.Format)
+	}
+	if !slices.Contains([]string{StructuredCSV, StructuredTSV, StructuredJSON, StructuredYAML, StructuredTOML}, format) {
+		if format == "" {
+			return nil, shared.NewError(shared.CodeUnsupportedFormat, "can't tell the format of %s from its extension; pass format (csv, tsv, json, yaml or toml)", filepath.Base(fullPath))
+		}
+		return nil, shared.NewError(shared.CodeInvalidArgument, "unknown format %q (use csv, tsv, json, yaml or toml)", format)
+	}
+
+	offset, limit := 0, defaultStructuredLimit
+	if args.Offset != nil && *args.Offset > 0 {
+		offset = *args.Offset
+	}
+	if args.Limit != nil && *args.Limit > 0 {
+		limit = min(*args.Limit, maxStructuredLimit)
+	}
+
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file info: %w", err)
+	}
+	if info.IsDir() {
+		return nil, shared.NewError(shared.CodeInvalidArgument, "cannot read directory as file")
+	}
+	if info.Size() > maxStructuredFileBytes {
+		return nil, shared.NewError(shared.CodeTooLarge, "file is %d bytes; read_structured parses files up to %d bytes", info.Size(), maxStructuredFileBytes)
+	}
+	if err := quota.checkRead(fullPath, 0); err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file content: %w", err)
+	}
+
+	result := &StructuredResult{Path: fullPath, Format: format}
+	var data any
+	switch format {
+	case StructuredCSV, StructuredTSV:
+		header := args.Header == nil || *args.Header
+		data, result.Columns, err = parseDelimited(content, format == StructuredTSV, header)
+	case StructuredJSON:
+		data, err = parseJSON(content)
+	case StructuredYAML:
+		data, err = parseYAML(content)
+	case StructuredTOML:
+		data, err = parseTOML(content)
+	}
+	if err != nil {
+		return nil, shared.NewError(shared.CodeInvalidArgument, "failed to parse %s as %s: %w", filepath.Base(fullPath), format, err)
+	}
+
+	if args.Select != nil && *args.Select != "" {
+		result.Select = *args.Select
+		if data, err = selectPath(data, *args.Select); err != nil {
+			return nil, err
+		}
+	}
+
+	result.Type = structuredType(data)
+	if items, ok := data.([]any); ok {
+		result.Total = len(items)
+		result.Offset = offset
+		start := min(offset, len(items))
+		end := min(start+limit, len(items))
+		data = items[start:end]
+		result.Returned = end - start
+		if end < len(items) {
+			result.NextOffset = &end
+		}
+	}
+	result.Data = data
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize data: %w", err)
+	}
+	if err := quota.checkRead(fullPath, int64(len(encoded))); err != nil {
+		return nil, err
+	}
+	quota.recordRead(fullPath, int64(len(encoded)))
+
+	return result, nil
+}
+
+// parseDelimited parses CSV or TSV. With a header row, rows become records
+// keyed by column; without one, they stay arrays. Cells are typed as numbers,
+// booleans or null where they clearly are.
+func parseDelimited(content []byte, tabs, header bool) ([]any, []string, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(content, []byte("\ufeff"))))
+	reader.FieldsPerRecord = -1
+	if tabs {
+		reader.Comma = '\t'
+		reader.LazyQuotes = true
+	}
+
+	var columns []string
+	rows := []any{}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if header && columns == nil {
+			columns = columnNames(record)
+			continue
+		}
+
+		if !header {
+			row := make([]any, len(record))
+			for i, cell := range record {
+				row[i] = typedCell(cell)
+			}
+			rows = append(rows, row)
+			continue
+		}
+		row := make(map[string]any, len(columns))
+		for i, column := range columns {
+			if i < len(record) {
+				row[column] = typedCell(record[i])
+			} else {
+				row[column] = nil
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, columns, nil
+}
+
+// columnNames names a header row's columns, filling in blanks and making
+// duplicates unique
+func columnNames(record []string) []string {
+	columns := make([]string, len(record))
+	seen := make(map[string]int)
+	for i, name := range record {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			name = fmt.Sprintf("column_%d", i+1)
+		}
+		seen[name]++
+		if seen[name] > 1 {
+			name = fmt.Sprintf("%s_%d", name, seen[name])
+		}
+		columns[i] = name
+	}
+	return columns
+}
+
+// typedCell converts a CSV cell to a number, boolean or null where it clearly
+// is one. Numbers with leading zeros, such as ZIP codes, stay text.
+func typedCell(cell string) any {
+	trimmed := strings.TrimSpace(cell)
+	switch strings.ToLower(trimmed) {
+	case "":
+		return nil
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	digits := strings.TrimLeft(trimmed, "+-")
+	if len(digits) > 1 && digits[0] == '0' && digits[1] != '.' {
+		return cell
+	}
+	if number, err := strconv.ParseInt(trimmed, 10, 64); err == nil {
+		return number
+	}
+	if number, err := strconv.ParseFloat(trimmed, 64); err == nil && !strings.ContainsAny(trimmed, "xXnN") {
+		return number // Excludes hex floats, Inf and NaN, which are text in a CSV
+	}
+	return cell
+}
+
+// parseJSON decodes JSON, keeping numbers exact
+func parseJSON(content []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	var data any
+	if err := decoder.Decode(&data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// parseYAML decodes YAML. A stream of several documents becomes an array.
+func parseYAML(content []byte) (any, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	var documents []any
+	for {
+		var document any
+		err := decoder.Decode(&document)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, normalizeYAML(document))
+	}
+	switch len(documents) {
+	case 0:
+		return nil, nil
+	case 1:
+		return documents[0], nil
+	default:
+		return documents, nil
+	}
+}
+
+// normalizeYAML converts maps with non-string keys, which YAML allows but
+// JSON doesn't, to maps keyed by the keys' text
+func normalizeYAML(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			v[key] = normalizeYAML(item)
+		}
+		return v
+	case map[any]any:
+		converted := make(map[string]any, len(v))
+		for key, item := range v {
+			converted[fmt.Sprint(key)] = normalizeYAML(item)
+		}
+		return converted
+	case []any:
+		for i, item := range v {
+			v[i] = normalizeYAML(item)
+		}
+		return v
+	default:
+		return value
+	}
+}
+
+// parseTOML decodes TOML into a table
+func parseTOML(content []byte) (any, error) {
+	var data map[string]any
+	if _, err := toml.NewDecoder(bytes.NewReader(content)).Decode(&data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// pathSegment is one step of a selection: an object key, an array index, or
+// every element or value
+type pathSegment struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// selectPath selects part of parsed data with a JSONPath-like expression such
+// as $.servers[0].host, servers[*].port or ['key.with.dots']. With a
+// wildcard, the matches are returned as an array.
+func selectPath(data any, expression string) (any, error) {
+	segments, err := parsePath(expression)
+	if err != nil {
+		return nil, shared.NewError(shared.CodeInvalidArgument, "invalid select expression %q: %v", expression, err)
+	}
+
+	values := []any{data}
+	wildcard := false
+	for _, segment := range segments {
+		var next []any
+		for _, value := range values {
+			switch {
+			case segment.wildcard:
+				wildcard = true
+				switch v := value.(type) {
+				case []any:
+					next = append(next, v...)
+				case map[string]any:
+					keys := make([]string, 0, len(v))
+					for key := range v {
+						keys = append(keys, key)
+					}
+					slices.Sort(keys)
+					for _, key := range keys {
+						next = append(next, v[key])
+					}
+				}
+			case segment.isIndex:
+				if items, ok := value.([]any); ok {
+					index := segment.index
+					if index < 0 {
+						index += len(items)
+					}
+					if index >= 0 && index < len(items) {
+						next = append(next, items[index])
+					}
+				}
+			default:
+				if object, ok := value.(map[string]any); ok {
+					if item, ok := object[segment.key]; ok {
+						next = append(next, item)
+					}
+				}
+			}
+		}
+		values = next
+	}
+
+	if wildcard {
+		if values == nil {
+			values = []any{}
+		}
+		return values, nil
+	}
+	if len(values) == 0 {
+		return nil, shared.NewError(shared.CodeNotFound, "select expression %q matched nothing", expression)
+	}
+	return values[0], nil
+}
+
+// parsePath splits a selection expression into segments
+func parsePath(expression string) ([]pathSegment, error) {
+	path := strings.TrimPrefix(strings.TrimSpace(expression), "$")
+	var segments []pathSegment
+	for i := 0; i < len(path); {
+		switch path[i] {
+		case '.':
+			i++
+			if i < len(path) && path[i] == '*' {
+				segments = append(segments, pathSegment{wildcard: true})
+				i++
+			}
+		case '[':
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed '['")
+			}
+			inner := strings.TrimSpace(path[i+1 : i+end])
+			i += end + 1
+			switch {
+			case inner == "*":
+				segments = append(segments, pathSegment{wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				segments = append(segments, pathSegment{key: inner[1 : len(inner)-1]})
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("%q is not an index, '*' or a quoted key", inner)
+				}
+				segments = append(segments, pathSegment{index: index, isIndex: true})
+			}
+		default:
+			end := i
+			for end < len(path) && path[end] != '.' && path[end] != '[' {
+				end++
+			}
+			segments = append(segments, pathSegment{key: path[i:end]})
+			i = end
+		}
+	}
+	return segments, nil
+}
+
+// structuredType names the JSON type of a value
+func structuredType(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case json.Number, int, int64, uint64, float64:
+		return "number"
+	case time.Time:
+		return "datetime" // TOML dates and YAML timestamps
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
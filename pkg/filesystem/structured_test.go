@@ -0,0 +1,100 @@
+package filesystem
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestReadStructured(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+	files := map[string]string{
+		"orders.csv":  "id,region,amount,zip,paid\n1,North,12.5,02134,true\n2,South,7,,false\n3,East,40,10001,true\n",
+		"config.json": `{"servers":[{"host":"a.example","port":8080},{"host":"b.example","port":9090}],"log.level":"debug"}`,
+		"app.yaml":    "name: app\nreplicas: 3\nports:\n  - 80\n  - 443\n",
+		"Cargo.toml":  "[package]\nname = \"demo\"\nversion = \"0.1.0\"\n\n[dependencies]\nserde = \"1\"\nrand = \"0.8\"\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	handler, err := NewHandler([]string{tmpDir})
+	if err != nil {
+		t.Fatalf("Failed to create handler: %v", err)
+	}
+	read := func(args ReadStructuredArgs) *StructuredResult {
+		t.Helper()
+		result, err := handler.ReadStructured(context.Background(), args)
+		if err != nil {
+			t.Fatalf("ReadStructured(%s) failed: %v", args.Path, err)
+		}
+		return result
+	}
+	text := func(value any) string {
+		encoded, _ := json.Marshal(value)
+		return string(encoded)
+	}
+	ptr := func(s string) *string { return &s }
+
+	// CSV rows become typed records, with ZIP codes kept as text
+	result := read(ReadStructuredArgs{Path: "orders.csv"})
+	if result.Format != StructuredCSV || result.Type != "array" || result.Total != 3 || !slices.Equal(result.Columns, []string{"id", "region", "amount", "zip", "paid"}) {
+		t.Errorf("Unexpected CSV result: %+v", result)
+	}
+	if got := text(result.Data.([]any)[0]); got != `{"amount":12.5,"id":1,"paid":true,"region":"North","zip":"02134"}` {
+		t.Errorf("First record = %s", got)
+	}
+
+	// Selection across records, paged
+	limit := 2
+	result = read(ReadStructuredArgs{Path: "orders.csv", Select: ptr("[*].amount"), Limit: &limit})
+	if text(result.Data) != "[12.5,7]" || result.NextOffset == nil || *result.NextOffset != 2 {
+		t.Errorf("Selected amounts = %s, next offset %v", text(result.Data), result.NextOffset)
+	}
+
+	result = read(ReadStructuredArgs{Path: "config.json", Select: ptr("$.servers[-1].port")})
+	if text(result.Data) != "9090" || result.Type != "number" {
+		t.Errorf("Last server port = %s (%s)", text(result.Data), result.Type)
+	}
+	result = read(ReadStructuredArgs{Path: "config.json", Select: ptr("['log.level']")})
+	if result.Data != "debug" {
+		t.Errorf("Quoted key = %v", result.Data)
+	}
+
+	result = read(ReadStructuredArgs{Path: "app.yaml", Select: ptr("ports")})
+	if text(result.Data) != "[80,443]" {
+		t.Errorf("YAML ports = %s", text(result.Data))
+	}
+
+	result = read(ReadStructuredArgs{Path: "Cargo.toml", Select: ptr("dependencies.*")})
+	if text(result.Data) != `["0.8","1"]` {
+		t.Errorf("TOML dependency versions = %s", text(result.Data))
+	}
+
+	if _, err := handler.ReadStructured(context.Background(), ReadStructuredArgs{Path: "config.json", Select: ptr("missing.key")}); err == nil {
+		t.Error("Expected an error when the selection matches nothing")
+	}
+	if _, err := handler.ReadStructured(context.Background(), ReadStructuredArgs{Path: "test.txt"}); err == nil {
+		t.Error("Expected an error for a file of unknown format")
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	segments, err := parsePath(`$.a["b c"][2].*`)
+	if err != nil {
+		t.Fatalf("parsePath failed: %v", err)
+	}
+	want := []pathSegment{{key: "a"}, {key: "b c"}, {index: 2, isIndex: true}, {wildcard: true}}
+	if !slices.Equal(segments, want) {
+		t.Errorf("parsePath = %+v, want %+v", segments, want)
+	}
+	if _, err := parsePath("a[1"); err == nil {
+		t.Error("Expected an error for an unclosed bracket")
+	}
+}
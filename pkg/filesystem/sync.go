@@ -0,0 +1,303 @@
+package filesystem
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kevsmith/my-mcp/pkg/shared"
+)
+
+// Sync modes
+const (
+	SyncCopy   = "copy"   // Copy new and changed files, keeping files only in the destination
+	SyncMirror = "mirror" // Also delete destination files that aren't in the source
+)
+
+// Sync limits
+const (
+	maxSyncFiles       = 100_000 // Files either tree may hold
+	maxReportedChanges = 1000    // Changes listed in a SyncResult; the counts cover all
+	syncProgressBytes  = 8 << 20 // Bytes copied between progress reports
+	syncModifyWindow   = time.Second
+)
+
+// SyncProgressFunc receives the bytes copied so far and the bytes to copy
+type SyncProgressFunc func(done, total int64)
+
+// syncFile is a regular file found by walkSyncTree
+type syncFile struct {
+	size    int64
+	modTime time.Time
+	perm    fs.FileMode
+}
+
+// syncTree is the files and directories under a root that pass the filters,
+// by slash-separated relative path
+type syncTree struct {
+	files map[string]syncFile
+	dirs  map[string]bool
+}
+
+// SyncPaths copies the files under one directory to another, creating the
+// destination if needed. Files are copied when missing from the destination
+// or when their size or modification time differ; in mirror mode,
+// destination files and directories that aren't in the source and pass the
+// same filters are deleted first. progress, when set, is called as bytes
+// are copied.
+func (h *Handler) SyncPaths(ctx context.Context, args SyncPathsArgs, progress SyncProgressFunc) (*SyncResult, error) {
+	if err := h.checkWriteEnabled(); err != nil {
+		return nil, err
+	}
+	mode := args.Mode
+	if mode == "" {
+		mode = SyncCopy
+	}
+	if mode != SyncCopy && mode != SyncMirror {
+		return nil, shared.NewError(shared.CodeInvalidArgument, "unknown mode %q: use %s or %s", mode, SyncCopy, SyncMirror)
+	}
+	for _, pattern := range append(append([]string{}, args.Include...), args.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, shared.NewError(shared.CodeInvalidArgument, "invalid pattern %q: %v", pattern, err)
+		}
+	}
+
+	source, err := h.resolvePath(args.Source)
+	if err != nil {
+		return nil, err
+	}
+	destination, err := h.resolvePath(args.Destination)
+	if err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(source); err != nil {
+		return nil, fmt.Errorf("source does not exist: %w", err)
+	} else if !info.IsDir() {
+		return nil, shared.NewError(shared.CodeInvalidArgument, "source is not a directory: %s", source)
+	}
+	if within(destination, source) || within(source, destination) {
+		return nil, shared.NewError(shared.CodeInvalidArgument, "source and destination must not contain each other")
+	}
+
+	src, err := walkSyncTree(source, args.Include, args.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	dst := &syncTree{files: map[string]syncFile{}, dirs: map[string]bool{}}
+	if info, err := os.Stat(destination); err == nil {
+		if !info.IsDir() {
+			return nil, shared.NewError(shared.CodeInvalidArgument, "destination is not a directory: %s", destination)
+		}
+		if dst, err = walkSyncTree(destination, args.Include, args.Exclude); err != nil {
+			return nil, err
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to get destination info: %w", err)
+	}
+
+	result := &SyncResult{Source: source, Destination: destination, Mode: mode, DryRun: args.DryRun, Changes: []SyncChange{}}
+	record := func(rel, action string) {
+		if len(result.Changes) < maxReportedChanges {
+			result.Changes = append(result.Changes, SyncChange{Path: rel, Action: action})
+		} else {
+			result.Truncated = true
+		}
+	}
+
+	// Plan every change before making any
+	var copies []string
+	var total int64
+	for rel, file := range src.files {
+		if dst.dirs[rel] {
+			return nil, shared.NewError(shared.CodeInvalidArgument, "%s is a file in the source but a directory in the destination", rel)
+		}
+		existing, exists := dst.files[rel]
+		switch {
+		case !exists:
+			result.Created++
+		case existing.size != file.size || existing.modTime.Sub(file.modTime).Abs() >= syncModifyWindow:
+			result.Updated++
+		default:
+			result.Unchanged++
+			continue
+		}
+		copies = append(copies, rel)
+		total += file.size
+	}
+	var deletes []string
+	if mode == SyncMirror {
+		for rel := range dst.files {
+			if _, ok := src.files[rel]; !ok {
+				deletes = append(deletes, rel)
+			}
+		}
+		for rel := range dst.dirs {
+			if !src.dirs[rel] {
+				deletes = append(deletes, rel)
+			}
+		}
+		result.Deleted = len(deletes)
+	}
+	sort.Strings(copies)
+	// Reverse order puts a directory's contents before the directory
+	sort.Sort(sort.Reverse(sort.StringSlice(deletes)))
+
+	for _, rel := range deletes {
+		record(rel, "delete")
+	}
+	for _, rel := range copies {
+		if _, exists := dst.files[rel]; exists {
+			record(rel, "update")
+		} else {
+			record(rel, "create")
+		}
+	}
+	result.BytesToCopy = total
+	if args.DryRun {
+		return result, nil
+	}
+
+	for _, rel := range deletes {
+		target := filepath.Join(destination, filepath.FromSlash(rel))
+		if dst.dirs[rel] {
+			os.Remove(target) // Left in place if it still holds excluded files
+		} else if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to delete %s: %w", target, err)
+		}
+	}
+	if err := os.MkdirAll(destination, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create destination: %w", err)
+	}
+
+	var reported int64
+	for _, rel := range copies {
+		if err := ctx.Err(); err != nil {
+			return nil, shared.NewError(shared.CodeCancelled, "sync cancelled after copying %d of %d bytes: %w", result.BytesCopied, total, err)
+		}
+		file := src.files[rel]
+		from := filepath.Join(source, filepath.FromSlash(rel))
+		to := filepath.Join(destination, filepath.FromSlash(rel))
+		if err := copyFile(from, to, file); err != nil {
+			return nil, err
+		}
+		result.BytesCopied += file.size
+		if progress != nil && (result.BytesCopied-reported >= syncProgressBytes || result.BytesCopied == total) {
+			progress(result.BytesCopied, total)
+			reported = result.BytesCopied
+		}
+	}
+	return result, nil
+}
+
+// walkSyncTree records the regular files and directories under root. A file
+// or directory is skipped when its name or relative path matches an
+// exclude pattern; with include patterns, only files matching one are kept.
+func walkSyncTree(root string, include, exclude []string) (*syncTree, error) {
+	tree := &syncTree{files: map[string]syncFile{}, dirs: map[string]bool{}}
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == root {
+				return err
+			}
+			return nil // Unreadable entries are left out rather than failing the sync
+		}
+		if p == root {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if matchesSyncPattern(rel, exclude) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			tree.dirs[rel] = true
+			return nil
+		}
+		if !d.Type().IsRegular() || (len(include) > 0 && !matchesSyncPattern(rel, include)) {
+			return nil // Links aren't followed
+		}
+		if len(tree.files) >= maxSyncFiles {
+			return shared.NewError(shared.CodeTooLarge, "%s has more than %d files; sync a subdirectory or exclude some", root, maxSyncFiles)
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		tree.files[rel] = syncFile{size: info.Size(), modTime: info.ModTime(), perm: info.Mode().Perm()}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// matchesSyncPattern reports whether a relative path, or its last element,
+// matches any pattern
+func matchesSyncPattern(rel string, patterns []string) bool {
+	name := path.Base(rel)
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+		if matched, _ := path.Match(pattern, rel); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// within reports whether path is dir or under it
+func within(path, dir string) bool {
+	return path == dir || strings.HasPrefix(path, dir+string(filepath.Separator))
+}
+
+// copyFile copies a file through a temporary file renamed into place, and
+// gives the copy the source's permissions and modification time
+func copyFile(from, to string, file syncFile) error {
+	if err := os.MkdirAll(filepath.Dir(to), 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(to), err)
+	}
+	in, err := os.Open(from)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", from, err)
+	}
+	defer in.Close()
+
+	temp, err := os.CreateTemp(filepath.Dir(to), "."+filepath.Base(to)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", to, err)
+	}
+	defer os.Remove(temp.Name()) // Fails harmlessly once renamed
+
+	if _, err := io.Copy(temp, in); err != nil {
+		temp.Close()
+		return fmt.Errorf("failed to copy %s: %w", from, err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", to, err)
+	}
+	if err := os.Chmod(temp.Name(), file.perm); err != nil {
+		return fmt.Errorf("failed to write %s: %w", to, err)
+	}
+	if err := os.Chtimes(temp.Name(), file.modTime, file.modTime); err != nil {
+		return fmt.Errorf("failed to write %s: %w", to, err)
+	}
+	if err := os.Rename(temp.Name(), to); err != nil {
+		return fmt.Errorf("failed to write %s: %w", to, err)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package filesystem
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSyncPaths(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+	t.Setenv("FS_ALLOW_WRITE", "true")
+
+	write := func(rel, content string) {
+		t.Helper()
+		full := filepath.Join(tmpDir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	exists := func(rel string) bool {
+		_, err := os.Stat(filepath.Join(tmpDir, filepath.FromSlash(rel)))
+		return err == nil
+	}
+	write("src/main.go", "package main\n")
+	write("src/docs/guide.md", "# Guide\n")
+	write("src/.git/HEAD", "ref: main\n")
+	write("dst/stale.txt", "old\n")
+	write("dst/.git/HEAD", "keep\n")
+
+	handler, err := NewHandler([]string{tmpDir})
+	if err != nil {
+		t.Fatalf("Failed to create handler: %v", err)
+	}
+	args := SyncPathsArgs{Source: "src", Destination: "dst", Exclude: []string{".git"}}
+
+	// A dry run lists the changes without making them
+	dry := args
+	dry.DryRun = true
+	result, err := handler.SyncPaths(context.Background(), dry, nil)
+	if err != nil {
+		t.Fatalf("Dry run failed: %v", err)
+	}
+	if result.Created != 2 || result.Deleted != 0 || len(result.Changes) != 2 || exists("dst/main.go") {
+		t.Errorf("Unexpected dry run: %+v", result)
+	}
+
+	// Copy mode copies files with their times and keeps destination extras
+	var reports [][2]int64
+	progress := func(done, total int64) { reports = append(reports, [2]int64{done, total}) }
+	result, err = handler.SyncPaths(context.Background(), args, progress)
+	if err != nil {
+		t.Fatalf("SyncPaths failed: %v", err)
+	}
+	if result.Created != 2 || result.BytesCopied != result.BytesToCopy || result.BytesCopied != 21 {
+		t.Errorf("Unexpected copy result: %+v", result)
+	}
+	if len(reports) != 1 || reports[0] != [2]int64{21, 21} {
+		t.Errorf("Progress reports = %v, want one final report", reports)
+	}
+	srcInfo, _ := os.Stat(filepath.Join(tmpDir, "src/main.go"))
+	dstInfo, err := os.Stat(filepath.Join(tmpDir, "dst/main.go"))
+	if err != nil || !dstInfo.ModTime().Equal(srcInfo.ModTime()) {
+		t.Errorf("Copy doesn't keep the modification time: %v", err)
+	}
+	if !exists("dst/docs/guide.md") || !exists("dst/stale.txt") || !exists("dst/.git/HEAD") {
+		t.Error("Copy mode changed the wrong files")
+	}
+
+	// Unchanged files are skipped; changed ones are updated
+	later := time.Now().Add(time.Hour)
+	write("src/main.go", "package main // v2\n")
+	os.Chtimes(filepath.Join(tmpDir, "src/main.go"), later, later)
+	result, err = handler.SyncPaths(context.Background(), args, nil)
+	if err != nil {
+		t.Fatalf("Second sync failed: %v", err)
+	}
+	if result.Updated != 1 || result.Unchanged != 1 || result.Created != 0 || result.Changes[0].Action != "update" {
+		t.Errorf("Unexpected second sync: %+v", result)
+	}
+
+	// Mirror mode deletes destination extras but leaves excluded ones
+	args.Mode = SyncMirror
+	result, err = handler.SyncPaths(context.Background(), args, nil)
+	if err != nil {
+		t.Fatalf("Mirror failed: %v", err)
+	}
+	if result.Deleted != 1 || exists("dst/stale.txt") || !exists("dst/.git/HEAD") {
+		t.Errorf("Unexpected mirror result: %+v", result)
+	}
+
+	// Include limits the files synced
+	result, err = handler.SyncPaths(context.Background(), SyncPathsArgs{Source: "src", Destination: "md", Include: []string{"*.md"}}, nil)
+	if err != nil {
+		t.Fatalf("Include sync failed: %v", err)
+	}
+	if result.Created != 1 || !exists("md/docs/guide.md") || exists("md/main.go") {
+		t.Errorf("Unexpected include result: %+v", result)
+	}
+
+	// Overlapping trees, bad modes and disabled writes are errors
+	for _, bad := range []SyncPathsArgs{
+		{Source: "src", Destination: "src/copy"},
+		{Source: "src", Destination: "."},
+		{Source: "src", Destination: "other", Mode: "move"},
+		{Source: "src/main.go", Destination: "other"},
+	} {
+		if _, err := handler.SyncPaths(context.Background(), bad, nil); err == nil {
+			t.Errorf("Expected an error for %+v", bad)
+		}
+	}
+	t.Setenv("FS_ALLOW_WRITE", "")
+	readOnly, _ := NewHandler([]string{tmpDir})
+	if _, err := readOnly.SyncPaths(context.Background(), SyncPathsArgs{Source: "src", Destination: "copy"}, nil); err == nil {
+		t.Error("Expected an error without write mode")
+	}
+}
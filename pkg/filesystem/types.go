@@ -18,27 +18,120 @@ type GlobArgs struct {
 }
 
 type GetFileInfoArgs struct {
-	Path string `json:"path"`
+	Path                      string `json:"path"`
+	IncludeExtendedAttributes bool   `json:"include_extended_attributes,omitempty"` // Optional, add xattrs or alternate data streams
 }
 
 type ReadFileArgs struct {
 	Path string `json:"path"`
 }
 
+type ReadStructuredArgs struct {
+	Path   string  `json:"path"`
+	Format *string `json:"format,omitempty"` // Optional, defaults to the file extension
+	Select *string `json:"select,omitempty"` // Optional, JSONPath-like expression
+	Header *bool   `json:"header,omitempty"` // Optional, CSV/TSV first row names columns (default true)
+	Offset *int    `json:"offset,omitempty"` // Optional, first array element returned
+	Limit  *int    `json:"limit,omitempty"`  // Optional, array elements returned
+}
+
+type GetImageInfoArgs struct {
+	Path          string `json:"path"`
+	Thumbnail     bool   `json:"thumbnail,omitempty"`      // Optional, return a downscaled preview
+	ThumbnailSize *int   `json:"thumbnail_size,omitempty"` // Optional, longest edge in pixels
+}
+
+type CreateWorkspaceArgs struct {
+	Label      string  `json:"label,omitempty"`       // Optional, defaults to scratch
+	Parent     *string `json:"parent,omitempty"`      // Optional, defaults to the first allowed root
+	TTLMinutes *int    `json:"ttl_minutes,omitempty"` // Optional, defaults to 60
+}
+
+type ReplaceInFileArgs struct {
+	Path            string `json:"path"`
+	Search          string `json:"search"`
+	Replace         string `json:"replace"`
+	Regex           bool   `json:"regex,omitempty"`            // Optional, search is a regular expression and replace may use $1
+	MaxReplacements *int   `json:"max_replacements,omitempty"` // Optional, defaults to every occurrence
+	ExpectedCount   *int   `json:"expected_count,omitempty"`   // Optional, fail unless exactly this many occurrences
+	ExpectedSHA256  string `json:"expected_sha256,omitempty"`  // Optional, fail if the file has changed
+	DryRun          bool   `json:"dry_run,omitempty"`
+}
+
+type ApplyPatchArgs struct {
+	Patch  string  `json:"patch"`
+	Path   *string `json:"path,omitempty"` // Optional, file to patch instead of the one a single-file diff names
+	DryRun bool    `json:"dry_run,omitempty"`
+}
+
+type SyncPathsArgs struct {
+	Source      string   `json:"source"`
+	Destination string   `json:"destination"`
+	Mode        string   `json:"mode,omitempty"`    // Optional, copy (default) or mirror
+	Include     []string `json:"include,omitempty"` // Optional, only files matching one are synced
+	Exclude     []string `json:"exclude,omitempty"` // Optional, files and directories to skip
+	DryRun      bool     `json:"dry_run,omitempty"`
+}
+
+type SnapshotDirectoryArgs struct {
+	Path    *string  `json:"path,omitempty"`    // Optional, defaults to CWD
+	Exclude []string `json:"exclude,omitempty"` // Optional, names to skip such as ".git"
+}
+
+type DiffSnapshotArgs struct {
+	SnapshotID string `json:"snapshot_id"`
+}
+
+type ParseLogArgs struct {
+	Path   string  `json:"path"`
+	Format *string `json:"format,omitempty"` // Optional, defaults to auto
+	Level  *string `json:"level,omitempty"`  // Optional, minimum level
+	Since  *string `json:"since,omitempty"`  // Optional, RFC 3339
+	Until  *string `json:"until,omitempty"`  // Optional, RFC 3339
+	Limit  *int    `json:"limit,omitempty"`  // Optional, number of most recent entries
+}
+
 // Response types
 type FileInfo struct {
-	Name         string    `json:"name"`
-	Path         string    `json:"path"`          // Always absolute
-	RelativePath string    `json:"relative_path"` // Relative to CWD for display
-	IsDir        bool      `json:"is_dir"`
-	Size         int64     `json:"size"`
-	Created      time.Time `json:"created"`
-	Modified     time.Time `json:"modified"`
+	Name             string    `json:"name"`
+	Path             string    `json:"path"`          // Always absolute
+	RelativePath     string    `json:"relative_path"` // Relative to CWD for display
+	IsDir            bool      `json:"is_dir"`
+	Size             int64     `json:"size"`
+	Created          time.Time `json:"created,omitzero"`  // Omitted when the platform or filesystem doesn't record it
+	CreatedAvailable bool      `json:"created_available"` // False when Created is unknown, as on older Linux kernels
+	Modified         time.Time `json:"modified"`
+
+	ExtendedAttributes []ExtendedAttribute `json:"extended_attributes,omitempty"` // Only when asked for
+}
+
+// ExtendedAttribute is an extended attribute on macOS and Linux, or an
+// alternate data stream on Windows. Values up to 4 KB are returned, as text
+// when they are printable UTF-8 and base64 otherwise.
+type ExtendedAttribute struct {
+	Name        string `json:"name"`
+	Size        int64  `json:"size"`
+	Value       string `json:"value,omitempty"`
+	ValueBase64 string `json:"value_base64,omitempty"`
+}
+
+// FileInfoOptions selects the optional metadata GetFileInfo returns
+type FileInfoOptions struct {
+	ExtendedAttributes bool // xattrs on macOS and Linux, alternate data streams on Windows
 }
 
 type DirectoryInfo struct {
-	CurrentDirectory string   `json:"current_directory"`
-	AllowedRoots     []string `json:"allowed_roots"`
+	CurrentDirectory string          `json:"current_directory"`
+	AllowedRoots     []string        `json:"allowed_roots"`
+	Workspaces       []WorkspaceInfo `json:"workspaces,omitempty"` // Scratch directories from create_workspace
+}
+
+// WorkspaceInfo describes a scratch directory made by create_workspace
+type WorkspaceInfo struct {
+	Label     string    `json:"label"`
+	Path      string    `json:"path"`
+	Created   time.Time `json:"created"`
+	ExpiresAt time.Time `json:"expires_at"` // The directory and its contents are removed then
 }
 
 type GlobResult struct {
@@ -54,3 +147,150 @@ type DirectoryListResult struct {
 	Skipped       int        `json:"skipped"`        // Number of entries skipped
 	HasMore       bool       `json:"has_more"`       // Whether there are more entries available
 }
+
+// SnapshotInfo describes a recorded directory snapshot
+type SnapshotInfo struct {
+	ID         string    `json:"snapshot_id"`
+	Root       string    `json:"root"`
+	Files      int       `json:"files"`
+	TotalBytes int64     `json:"total_bytes"`
+	Taken      time.Time `json:"taken"`
+}
+
+// FileChange is a file whose content changed since a snapshot
+type FileChange struct {
+	Path    string `json:"path"` // Relative to the snapshot root
+	OldSize int64  `json:"old_size"`
+	NewSize int64  `json:"new_size"`
+}
+
+// SnapshotDiff lists the files created, modified and deleted since a
+// snapshot, by path relative to its root
+type SnapshotDiff struct {
+	ID        string       `json:"snapshot_id"`
+	Root      string       `json:"root"`
+	Taken     time.Time    `json:"taken"`
+	Created   []string     `json:"created"`
+	Modified  []FileChange `json:"modified"` // Content changed; files only touched are unchanged
+	Deleted   []string     `json:"deleted"`
+	Unchanged int          `json:"unchanged"`
+}
+
+// LogEntry is one entry of a log file
+type LogEntry struct {
+	Line    int               `json:"line"` // Line the entry starts on
+	Time    *time.Time        `json:"time,omitempty"`
+	Level   string            `json:"level,omitempty"` // trace, debug, info, warn, error or fatal
+	Message string            `json:"message"`         // Continuation lines such as stack traces included
+	Format  string            `json:"format"`
+	Fields  map[string]string `json:"fields,omitempty"` // Other keys, or syslog's host, app and pid
+}
+
+// LogParseResult holds the most recent entries of a log that pass its filters
+type LogParseResult struct {
+	Path      string     `json:"path"`
+	Total     int        `json:"total"`     // Entries that passed the filters
+	Returned  int        `json:"returned"`  // The last of them, up to the limit
+	Truncated bool       `json:"truncated"` // Earlier entries were left out
+	Entries   []LogEntry `json:"entries"`
+}
+
+// StructuredResult is a parsed data file, or the part of it selected. Arrays
+// are paged; Total counts every element.
+type StructuredResult struct {
+	Path       string   `json:"path"`
+	Format     string   `json:"format"`
+	Select     string   `json:"select,omitempty"`
+	Type       string   `json:"type"`              // object, array, string, number, boolean, datetime or null
+	Columns    []string `json:"columns,omitempty"` // CSV/TSV header, in file order
+	Total      int      `json:"total,omitempty"`
+	Offset     int      `json:"offset,omitempty"`
+	Returned   int      `json:"returned,omitempty"`
+	NextOffset *int     `json:"next_offset,omitempty"` // Set when more elements follow
+	Data       any      `json:"data"`
+}
+
+// ImageInfo describes an image file. Width and height are as stored; an EXIF
+// orientation of 5 to 8 means the image displays turned a quarter.
+type ImageInfo struct {
+	Path          string         `json:"path"`
+	Format        string         `json:"format"` // png, jpeg, gif, webp, bmp or tiff
+	Width         int            `json:"width"`
+	Height        int            `json:"height"`
+	Size          int64          `json:"size"` // File size in bytes
+	EXIF          *ImageEXIF     `json:"exif,omitempty"`
+	Thumbnail     *ThumbnailInfo `json:"thumbnail,omitempty"`
+	ThumbnailData []byte         `json:"-"` // Encoded thumbnail, returned as image content
+}
+
+// ImageEXIF holds the basic EXIF fields of a photo
+type ImageEXIF struct {
+	Make         string   `json:"make,omitempty"`
+	Model        string   `json:"model,omitempty"`
+	Software     string   `json:"software,omitempty"`
+	DateTime     string   `json:"date_time,omitempty"` // When taken if recorded, else when last changed
+	Orientation  int      `json:"orientation,omitempty"`
+	ExposureTime string   `json:"exposure_time,omitempty"` // e.g. 1/125 s
+	FNumber      float64  `json:"f_number,omitempty"`
+	ISO          int      `json:"iso,omitempty"`
+	FocalLength  float64  `json:"focal_length_mm,omitempty"`
+	Latitude     *float64 `json:"latitude,omitempty"`
+	Longitude    *float64 `json:"longitude,omitempty"`
+}
+
+// ThumbnailInfo describes the thumbnail returned with an ImageInfo
+type ThumbnailInfo struct {
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	MIMEType string `json:"mime_type"`
+}
+
+// EditResult reports a replace_in_file edit. The hashes let a caller check
+// the file is the one it edited before changing it again.
+type EditResult struct {
+	Path         string `json:"path"`
+	Replacements int    `json:"replacements"`
+	BeforeSHA256 string `json:"before_sha256"`
+	AfterSHA256  string `json:"after_sha256"`
+	Size         int64  `json:"size"`              // Size after the edit
+	DryRun       bool   `json:"dry_run,omitempty"` // Nothing was written
+}
+
+// PatchResult reports the files an apply_patch call changed
+type PatchResult struct {
+	Files  []PatchedFile `json:"files"`
+	DryRun bool          `json:"dry_run,omitempty"` // Nothing was written
+}
+
+// PatchedFile is one file changed by apply_patch
+type PatchedFile struct {
+	Path         string `json:"path"`
+	Status       string `json:"status"` // modified, created or deleted
+	Hunks        int    `json:"hunks"`
+	LinesAdded   int    `json:"lines_added"`
+	LinesRemoved int    `json:"lines_removed"`
+	BeforeSHA256 string `json:"before_sha256,omitempty"` // Omitted for created files
+	AfterSHA256  string `json:"after_sha256,omitempty"`  // Omitted for deleted files
+}
+
+// SyncResult reports what sync_paths changed, or would change in a dry run
+type SyncResult struct {
+	Source      string       `json:"source"`
+	Destination string       `json:"destination"`
+	Mode        string       `json:"mode"`
+	DryRun      bool         `json:"dry_run,omitempty"`
+	Created     int          `json:"created"`
+	Updated     int          `json:"updated"`
+	Deleted     int          `json:"deleted"` // Mirror mode only
+	Unchanged   int          `json:"unchanged"`
+	BytesToCopy int64        `json:"bytes_to_copy"`
+	BytesCopied int64        `json:"bytes_copied"`
+	Changes     []SyncChange `json:"changes"`
+	Truncated   bool         `json:"truncated,omitempty"` // More changes were made than are listed
+}
+
+// SyncChange is one file or directory sync_paths created, updated or deleted
+type SyncChange struct {
+	Path   string `json:"path"`   // Relative to the source and destination
+	Action string `json:"action"` // create, update or delete
+}
@@ -0,0 +1,154 @@
+package filesystem
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kevsmith/my-mcp/pkg/shared"
+)
+
+// Workspace limits
+const (
+	defaultWorkspaceTTL   = time.Hour
+	maxWorkspaceTTL       = 24 * time.Hour
+	maxWorkspaces         = 10                // Live workspaces per session
+	workspaceParentDir    = ".mcp-workspaces" // Created in the parent directory to hold workspaces
+	defaultWorkspaceLabel = "scratch"
+)
+
+// workspaceLabelPattern is what create_workspace accepts as a label
+var workspaceLabelPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,39}$`)
+
+// GetWriteEnabled reports whether FS_ALLOW_WRITE enables the tools that
+// write under the allowed roots
+func GetWriteEnabled() bool {
+	enabled, err := strconv.ParseBool(os.Getenv("FS_ALLOW_WRITE"))
+	return err == nil && enabled
+}
+
+// workspace is a scratch directory removed when its TTL runs out
+type workspace struct {
+	info  WorkspaceInfo
+	timer *time.Timer
+}
+
+// workspaceStore holds a session's live workspaces by label; safe for
+// concurrent use
+type workspaceStore struct {
+	mu         sync.Mutex
+	workspaces map[string]*workspace
+}
+
+// list returns the live workspaces, oldest first
+func (s *workspaceStore) list() []WorkspaceInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	infos := make([]WorkspaceInfo, 0, len(s.workspaces))
+	for _, ws := range s.workspaces {
+		infos = append(infos, ws.info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Created.Before(infos[j].Created)
+	})
+	return infos
+}
+
+// remove deletes a workspace's directory if it is still the one stored
+// under its label. The lock is held throughout so a workspace being created
+// can't lose its parent directory.
+func (s *workspaceStore) remove(ws *workspace) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.workspaces[ws.info.Label] != ws {
+		return
+	}
+	delete(s.workspaces, ws.info.Label)
+
+	ws.timer.Stop()
+	os.RemoveAll(ws.info.Path)
+	os.Remove(filepath.Dir(ws.info.Path)) // Only succeeds once no workspaces are left in it
+}
+
+// CreateWorkspace creates an empty scratch directory under parent (the first
+// allowed root when nil) that is removed after ttl, or after an hour when
+// ttl is zero. The workspace is reported under its label by
+// GetDirectoryInfo until then.
+func (h *Handler) CreateWorkspace(ctx context.Context, label string, parent *string, ttl time.Duration) (*WorkspaceInfo, error) {
+	if err := h.checkWriteEnabled(); err != nil {
+		return nil, err
+	}
+	if label == "" {
+		label = defaultWorkspaceLabel
+	}
+	if !workspaceLabelPattern.MatchString(label) {
+		return nil, shared.NewError(shared.CodeInvalidArgument, "invalid label %q: use up to 40 letters, digits, '-' and '_'", label)
+	}
+	if ttl == 0 {
+		ttl = defaultWorkspaceTTL
+	}
+	if ttl < 0 || ttl > maxWorkspaceTTL {
+		return nil, shared.NewError(shared.CodeInvalidArgument, "ttl must be between 1 minute and %d hours", int(maxWorkspaceTTL.Hours()))
+	}
+
+	root := h.allowedRoots[0]
+	if parent != nil && *parent != "" {
+		resolved, err := h.resolvePath(*parent)
+		if err != nil {
+			return nil, err
+		}
+		if info, err := os.Stat(resolved); err != nil || !info.IsDir() {
+			return nil, shared.NewError(shared.CodeInvalidArgument, "not a directory: %s", resolved)
+		}
+		root = resolved
+	}
+
+	workspaces := &h.sessionFor(ctx).workspaces
+	workspaces.mu.Lock()
+	defer workspaces.mu.Unlock()
+	if _, exists := workspaces.workspaces[label]; exists {
+		return nil, shared.NewError(shared.CodeInvalidArgument, "a workspace labeled %q already exists", label)
+	}
+	if len(workspaces.workspaces) >= maxWorkspaces {
+		return nil, shared.NewError(shared.CodeQuotaExceeded, "session already has %d workspaces; wait for one to expire", maxWorkspaces)
+	}
+
+	base := filepath.Join(root, workspaceParentDir)
+	if err := os.MkdirAll(base, 0o755); err != nil {
+		return nil, shared.NewError(shared.CodeAccessDenied, "cannot create a workspace in %s: %w", root, err)
+	}
+	path, err := os.MkdirTemp(base, label+"-")
+	if err != nil {
+		return nil, shared.NewError(shared.CodeAccessDenied, "cannot create a workspace in %s: %w", root, err)
+	}
+
+	now := time.Now()
+	ws := &workspace{info: WorkspaceInfo{Label: label, Path: path, Created: now, ExpiresAt: now.Add(ttl)}}
+	ws.timer = time.AfterFunc(ttl, func() { workspaces.remove(ws) })
+	if workspaces.workspaces == nil {
+		workspaces.workspaces = make(map[string]*workspace)
+	}
+	workspaces.workspaces[label] = ws
+
+	info := ws.info
+	return &info, nil
+}
+
+// close removes the workspaces ahead of their expiry
+func (s *workspaceStore) close() {
+	s.mu.Lock()
+	live := make([]*workspace, 0, len(s.workspaces))
+	for _, ws := range s.workspaces {
+		live = append(live, ws)
+	}
+	s.mu.Unlock()
+
+	for _, ws := range live {
+		s.remove(ws)
+	}
+}
@@ -0,0 +1,128 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kevsmith/my-mcp/pkg/shared"
+)
+
+func TestCreateWorkspace(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+
+	// Writing is off unless FS_ALLOW_WRITE is set
+	t.Setenv("FS_ALLOW_WRITE", "")
+	readOnly, err := NewHandler([]string{tmpDir})
+	if err != nil {
+		t.Fatalf("Failed to create handler: %v", err)
+	}
+	var toolErr *shared.ToolError
+	if _, err := readOnly.CreateWorkspace(context.Background(), "", nil, 0); !errors.As(err, &toolErr) || toolErr.Code != shared.CodeAccessDenied {
+		t.Errorf("Expected access denied without write mode, got %v", err)
+	}
+
+	t.Setenv("FS_ALLOW_WRITE", "true")
+	handler, err := NewHandler([]string{tmpDir})
+	if err != nil {
+		t.Fatalf("Failed to create handler: %v", err)
+	}
+
+	ws, err := handler.CreateWorkspace(context.Background(), "", nil, 0)
+	if err != nil {
+		t.Fatalf("CreateWorkspace failed: %v", err)
+	}
+	if ws.Label != "scratch" || filepath.Dir(ws.Path) != filepath.Join(tmpDir, workspaceParentDir) || !strings.HasPrefix(filepath.Base(ws.Path), "scratch-") {
+		t.Errorf("Unexpected workspace: %+v", ws)
+	}
+	if got := ws.ExpiresAt.Sub(ws.Created); got != defaultWorkspaceTTL {
+		t.Errorf("TTL = %v, want %v", got, defaultWorkspaceTTL)
+	}
+	if err := os.WriteFile(filepath.Join(ws.Path, "out.txt"), []byte("data"), 0644); err != nil {
+		t.Errorf("Workspace isn't writable: %v", err)
+	}
+
+	// Labels are unique and validated, and the parent must be in the roots
+	if _, err := handler.CreateWorkspace(context.Background(), "scratch", nil, 0); err == nil {
+		t.Error("Expected an error for a duplicate label")
+	}
+	if _, err := handler.CreateWorkspace(context.Background(), "../escape", nil, 0); err == nil {
+		t.Error("Expected an error for an invalid label")
+	}
+	if _, err := handler.CreateWorkspace(context.Background(), "build", nil, 48*time.Hour); err == nil {
+		t.Error("Expected an error for a TTL over the maximum")
+	}
+	outside := "/"
+	if _, err := handler.CreateWorkspace(context.Background(), "outside", &outside, 0); err == nil {
+		t.Error("Expected an error for a parent outside the allowed roots")
+	}
+
+	// Workspaces are listed as labeled roots, and removed when they expire
+	parent := "subdir"
+	short, err := handler.CreateWorkspace(context.Background(), "build", &parent, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("CreateWorkspace in subdir failed: %v", err)
+	}
+	if filepath.Dir(filepath.Dir(short.Path)) != filepath.Join(tmpDir, "subdir") {
+		t.Errorf("Workspace %s isn't in subdir", short.Path)
+	}
+	if listed := handler.GetDirectoryInfo(context.Background()).Workspaces; len(listed) != 2 || listed[0].Label != "scratch" || listed[1].Label != "build" {
+		t.Errorf("Workspaces = %+v, want scratch then build", listed)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for len(handler.GetDirectoryInfo(context.Background()).Workspaces) != 1 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if listed := handler.GetDirectoryInfo(context.Background()).Workspaces; len(listed) != 1 || listed[0].Label != "scratch" {
+		t.Errorf("Workspaces after expiry = %+v, want scratch", listed)
+	}
+	if _, err := os.Stat(short.Path); !os.IsNotExist(err) {
+		t.Errorf("Expired workspace still exists: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "subdir", workspaceParentDir)); !os.IsNotExist(err) {
+		t.Errorf("Empty workspace directory left behind: %v", err)
+	}
+
+	// Close removes the rest
+	handler.Close()
+	if _, err := os.Stat(ws.Path); !os.IsNotExist(err) {
+		t.Errorf("Workspace still exists after Close: %v", err)
+	}
+	if len(handler.GetDirectoryInfo(context.Background()).Workspaces) != 0 {
+		t.Error("Workspaces still listed after Close")
+	}
+}
+
+func TestEndSessionRemovesWorkspaces(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+	t.Setenv("FS_ALLOW_WRITE", "true")
+	handler, err := NewHandler([]string{tmpDir})
+	if err != nil {
+		t.Fatalf("Failed to create handler: %v", err)
+	}
+
+	ctx := context.Background()
+	ws, err := handler.CreateWorkspace(ctx, "build", nil, 0)
+	if err != nil {
+		t.Fatalf("CreateWorkspace failed: %v", err)
+	}
+	handler.EndSession("")
+	if _, err := os.Stat(ws.Path); !os.IsNotExist(err) {
+		t.Errorf("Workspace survived the end of its session: %v", err)
+	}
+	if workspaces := handler.GetDirectoryInfo(ctx).Workspaces; len(workspaces) != 0 {
+		t.Errorf("Ended session's workspaces are still listed: %+v", workspaces)
+	}
+
+	// The label is free again in the next session
+	if _, err := handler.CreateWorkspace(ctx, "build", nil, 0); err != nil {
+		t.Errorf("CreateWorkspace after the session ended failed: %v", err)
+	}
+	handler.Close()
+}
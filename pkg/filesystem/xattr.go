@@ -0,0 +1,39 @@
+package filesystem
+
+import (
+	"encoding/base64"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxAttributeValueBytes caps the extended attribute values returned; larger
+// values are reported by size only
+const maxAttributeValueBytes = 4096
+
+// newExtendedAttribute describes an attribute or stream. value is nil when
+// it wasn't read; text values are returned as is and others as base64.
+func newExtendedAttribute(name string, size int64, value []byte) ExtendedAttribute {
+	attr := ExtendedAttribute{Name: name, Size: size}
+	switch {
+	case value == nil || len(value) > maxAttributeValueBytes:
+	case isPrintableText(value):
+		attr.Value = string(value)
+	default:
+		attr.ValueBase64 = base64.StdEncoding.EncodeToString(value)
+	}
+	return attr
+}
+
+// isPrintableText reports whether value is UTF-8 without control characters
+// other than whitespace
+func isPrintableText(value []byte) bool {
+	if !utf8.Valid(value) {
+		return false
+	}
+	for _, r := range string(value) {
+		if unicode.IsControl(r) && r != '\n' && r != '\r' && r != '\t' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,27 @@
+package filesystem
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewExtendedAttribute(t *testing.T) {
+	tests := []struct {
+		name       string
+		value      []byte
+		wantText   string
+		wantBase64 string
+	}{
+		{"com.apple.quarantine", []byte("0083;5f8a1b2c;Safari;"), "0083;5f8a1b2c;Safari;", ""},
+		{"Zone.Identifier", []byte("[ZoneTransfer]\r\nZoneId=3\r\n"), "[ZoneTransfer]\r\nZoneId=3\r\n", ""},
+		{"com.apple.metadata:kMDItemWhereFroms", []byte("bplist00\x01\x02"), "", "YnBsaXN0MDABAg=="},
+		{"user.large", []byte(strings.Repeat("x", maxAttributeValueBytes+1)), "", ""},
+		{"user.unread", nil, "", ""},
+	}
+	for _, tt := range tests {
+		attr := newExtendedAttribute(tt.name, 42, tt.value)
+		if attr.Name != tt.name || attr.Size != 42 || attr.Value != tt.wantText || attr.ValueBase64 != tt.wantBase64 {
+			t.Errorf("newExtendedAttribute(%s) = %+v", tt.name, attr)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+//go:build linux || darwin
+
+package filesystem
+
+import (
+	"errors"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// listExtendedAttributes returns a file's extended attributes, such as
+// com.apple.quarantine on macOS or user.xdg.origin.url on Linux
+func listExtendedAttributes(path string) ([]ExtendedAttribute, error) {
+	size, err := unix.Listxattr(path, nil)
+	if err != nil {
+		if errors.Is(err, unix.ENOTSUP) {
+			return nil, nil // The filesystem doesn't support them
+		}
+		return nil, err
+	}
+	if size == 0 {
+		return nil, nil
+	}
+	buf := make([]byte, size)
+	if size, err = unix.Listxattr(path, buf); err != nil {
+		return nil, err
+	}
+
+	var attrs []ExtendedAttribute
+	for _, name := range strings.Split(strings.TrimRight(string(buf[:size]), "\x00"), "\x00") {
+		valueSize, err := unix.Getxattr(path, name, nil)
+		if err != nil {
+			continue // Removed since listing, or unreadable to this user
+		}
+		var value []byte
+		if valueSize <= maxAttributeValueBytes {
+			value = make([]byte, valueSize)
+			if n, err := unix.Getxattr(path, name, value); err == nil {
+				value = value[:n]
+			} else {
+				value = nil
+			}
+		}
+		attrs = append(attrs, newExtendedAttribute(name, int64(valueSize), value))
+	}
+	return attrs, nil
+}
@@ -0,0 +1,45 @@
+//go:build linux || darwin
+
+package filesystem
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestGetFileInfoExtendedAttributes(t *testing.T) {
+	tmpDir, cleanup := setupTestDir(t)
+	defer cleanup()
+	path := filepath.Join(tmpDir, "test.txt")
+	if err := unix.Setxattr(path, "user.xdg.origin.url", []byte("https://example.com/test.txt"), 0); err != nil {
+		if errors.Is(err, unix.ENOTSUP) || errors.Is(err, unix.EPERM) {
+			t.Skipf("Extended attributes aren't supported here: %v", err)
+		}
+		t.Fatal(err)
+	}
+
+	handler, err := NewHandler([]string{tmpDir})
+	if err != nil {
+		t.Fatalf("Failed to create handler: %v", err)
+	}
+	info, err := handler.GetFileInfo("test.txt", FileInfoOptions{})
+	if err != nil || info.ExtendedAttributes != nil {
+		t.Fatalf("Attributes listed without being asked for: %+v, %v", info, err)
+	}
+	info, err = handler.GetFileInfo("test.txt", FileInfoOptions{ExtendedAttributes: true})
+	if err != nil {
+		t.Fatalf("GetFileInfo failed: %v", err)
+	}
+	for _, attr := range info.ExtendedAttributes {
+		if attr.Name == "user.xdg.origin.url" {
+			if attr.Value != "https://example.com/test.txt" || attr.Size != 28 {
+				t.Errorf("Unexpected attribute: %+v", attr)
+			}
+			return
+		}
+	}
+	t.Errorf("Attribute missing from %+v", info.ExtendedAttributes)
+}
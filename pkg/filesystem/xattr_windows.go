@@ -0,0 +1,74 @@
+//go:build windows
+
+package filesystem
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	modkernel32          = syscall.NewLazyDLL("kernel32.dll")
+	procFindFirstStreamW = modkernel32.NewProc("FindFirstStreamW")
+	procFindNextStreamW  = modkernel32.NewProc("FindNextStreamW")
+)
+
+// win32FindStreamData is WIN32_FIND_STREAM_DATA
+type win32FindStreamData struct {
+	StreamSize int64
+	StreamName [syscall.MAX_PATH + 36]uint16
+}
+
+// listExtendedAttributes returns a file's NTFS alternate data streams, such
+// as the Zone.Identifier stream marking downloaded files. The unnamed main
+// stream is left out.
+func listExtendedAttributes(path string) ([]ExtendedAttribute, error) {
+	pathPtr, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, err
+	}
+	var data win32FindStreamData
+	handle, _, callErr := procFindFirstStreamW.Call(uintptr(unsafe.Pointer(pathPtr)), 0, uintptr(unsafe.Pointer(&data)), 0) // 0 is FindStreamInfoStandard
+	if syscall.Handle(handle) == syscall.InvalidHandle {
+		if errors.Is(callErr, syscall.ERROR_HANDLE_EOF) {
+			return nil, nil // No streams, as for most directories
+		}
+		return nil, callErr
+	}
+	defer syscall.FindClose(syscall.Handle(handle))
+
+	var attrs []ExtendedAttribute
+	for {
+		// Stream names look like ":Zone.Identifier:$DATA"; "::$DATA" is the file itself
+		name := syscall.UTF16ToString(data.StreamName[:])
+		if name != "::$DATA" {
+			stream := strings.TrimSuffix(strings.TrimPrefix(name, ":"), ":$DATA")
+			var value []byte
+			if data.StreamSize <= maxAttributeValueBytes {
+				value, _ = readStream(path + ":" + stream)
+			}
+			attrs = append(attrs, newExtendedAttribute(stream, data.StreamSize, value))
+		}
+
+		if ok, _, callErr := procFindNextStreamW.Call(handle, uintptr(unsafe.Pointer(&data))); ok == 0 {
+			if errors.Is(callErr, syscall.ERROR_HANDLE_EOF) {
+				return attrs, nil
+			}
+			return attrs, callErr
+		}
+	}
+}
+
+// readStream reads a small alternate data stream
+func readStream(path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(io.LimitReader(file, maxAttributeValueBytes))
+}
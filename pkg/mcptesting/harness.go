@@ -0,0 +1,175 @@
+// Package mcptesting runs MCP servers in-process for integration tests. A
+// server is served over a stdio pipe exactly as the commands serve it, and a
+// real MCP client performs the initialize handshake and calls its tools, so
+// tests cover the setup wiring, middlewares and JSON-RPC encoding that
+// calling handlers directly skips.
+package mcptesting
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kevsmith/my-mcp/pkg/shared"
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// CallTimeout bounds each request a Client sends, so a hung tool fails the
+// test instead of stalling the run
+const CallTimeout = 30 * time.Second
+
+// Client is an initialized MCP client connected to an in-process server
+type Client struct {
+	t          testing.TB
+	client     *client.Client
+	initResult *mcp.InitializeResult
+}
+
+// Start serves s over a stdio pipe and returns a client that has completed
+// the initialize handshake. The server stops when the test ends.
+func Start(t testing.TB, s *server.MCPServer) *Client {
+	t.Helper()
+
+	serverReader, clientWriter := io.Pipe()
+	clientReader, serverWriter := io.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		stdio := server.NewStdioServer(s)
+		stdio.SetErrorLogger(log.New(io.Discard, "", 0))
+		stdio.Listen(ctx, serverReader, serverWriter)
+	}()
+
+	pipe := transport.NewIO(clientReader, clientWriter, io.NopCloser(strings.NewReader("")))
+	c := client.NewClient(pipe)
+	t.Cleanup(func() {
+		c.Close()
+		cancel()
+		serverReader.Close()
+		serverWriter.Close()
+		wg.Wait()
+	})
+	if err := c.Start(ctx); err != nil {
+		t.Fatalf("Failed to start the client: %v", err)
+	}
+
+	request := mcp.InitializeRequest{}
+	request.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	request.Params.ClientInfo = mcp.Implementation{Name: "mcptesting", Version: "1.0.0"}
+	callCtx, callCancel := context.WithTimeout(ctx, CallTimeout)
+	defer callCancel()
+	result, err := c.Initialize(callCtx, request)
+	if err != nil {
+		t.Fatalf("Initialize failed: %v", err)
+	}
+	return &Client{t: t, client: c, initResult: result}
+}
+
+// ServerInfo returns the name and version the server sent while initializing
+func (c *Client) ServerInfo() mcp.Implementation {
+	return c.initResult.ServerInfo
+}
+
+// ListTools returns the server's tools, failing the test on a protocol error
+func (c *Client) ListTools() []mcp.Tool {
+	c.t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), CallTimeout)
+	defer cancel()
+	result, err := c.client.ListTools(ctx, mcp.ListToolsRequest{})
+	if err != nil {
+		c.t.Fatalf("tools/list failed: %v", err)
+	}
+	return result.Tools
+}
+
+// ToolNames returns the names of the server's tools, for membership checks
+func (c *Client) ToolNames() map[string]bool {
+	c.t.Helper()
+	names := make(map[string]bool)
+	for _, tool := range c.ListTools() {
+		names[tool.Name] = true
+	}
+	return names
+}
+
+// CallTool calls the named tool and returns its result, which may be a tool
+// error. Protocol errors, such as an unknown tool, fail the test.
+func (c *Client) CallTool(name string, arguments map[string]any) *mcp.CallToolResult {
+	c.t.Helper()
+	result, err := c.TryCallTool(name, arguments)
+	if err != nil {
+		c.t.Fatalf("tools/call %s failed: %v", name, err)
+	}
+	return result
+}
+
+// TryCallTool calls the named tool, returning protocol errors to the caller
+func (c *Client) TryCallTool(name string, arguments map[string]any) (*mcp.CallToolResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), CallTimeout)
+	defer cancel()
+	request := mcp.CallToolRequest{}
+	request.Params.Name = name
+	request.Params.Arguments = arguments
+	return c.client.CallTool(ctx, request)
+}
+
+// CallText calls the named tool, fails the test if it returns an error
+// result, and returns the result's text
+func (c *Client) CallText(name string, arguments map[string]any) string {
+	c.t.Helper()
+	result := c.CallTool(name, arguments)
+	if result.IsError {
+		c.t.Fatalf("%s returned an error: %s", name, Text(result))
+	}
+	return Text(result)
+}
+
+// CallJSON calls the named tool and decodes its text result into v
+func (c *Client) CallJSON(name string, arguments map[string]any, v any) {
+	c.t.Helper()
+	text := c.CallText(name, arguments)
+	if err := json.Unmarshal([]byte(text), v); err != nil {
+		c.t.Fatalf("%s returned invalid JSON: %v\n%s", name, err, text)
+	}
+}
+
+// CallError calls the named tool, fails the test unless it returns an error
+// envelope with the given code, and returns the error
+func (c *Client) CallError(name string, arguments map[string]any, code string) *shared.ToolError {
+	c.t.Helper()
+	result := c.CallTool(name, arguments)
+	text := Text(result)
+	if !result.IsError {
+		c.t.Fatalf("%s: expected a %s error, got %s", name, code, text)
+	}
+	var envelope shared.ErrorEnvelope
+	if err := json.Unmarshal([]byte(text), &envelope); err != nil || envelope.Error == nil {
+		c.t.Fatalf("%s: error result is not an envelope: %s", name, text)
+	}
+	if envelope.Error.Code != code {
+		c.t.Fatalf("%s: code %s, want %s: %s", name, envelope.Error.Code, code, envelope.Error.Message)
+	}
+	return envelope.Error
+}
+
+// Text joins the text content of result
+func Text(result *mcp.CallToolResult) string {
+	var texts []string
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
@@ -0,0 +1,44 @@
+package mcptesting
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kevsmith/my-mcp/pkg/shared"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestHarness(t *testing.T) {
+	s := server.NewMCPServer("echo-mcp", "2.0.0", server.WithToolCapabilities(true))
+	s.AddTool(mcp.NewTool("echo", mcp.WithString("text", mcp.Required())),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			text, err := request.RequireString("text")
+			if err != nil {
+				return shared.ErrorResultf(shared.CodeInvalidArgument, "%v", err), nil
+			}
+			return mcp.NewToolResultText(`{"echo":"` + text + `"}`), nil
+		})
+
+	c := Start(t, s)
+	if info := c.ServerInfo(); info.Name != "echo-mcp" || info.Version != "2.0.0" {
+		t.Errorf("Unexpected server info: %+v", info)
+	}
+	if !c.ToolNames()["echo"] {
+		t.Error("Expected the echo tool to be listed")
+	}
+
+	if text := c.CallText("echo", map[string]any{"text": "hi"}); text != `{"echo":"hi"}` {
+		t.Errorf("echo returned %s", text)
+	}
+	var decoded struct{ Echo string }
+	c.CallJSON("echo", map[string]any{"text": "json"}, &decoded)
+	if decoded.Echo != "json" {
+		t.Errorf("Decoded %+v", decoded)
+	}
+	c.CallError("echo", map[string]any{}, shared.CodeInvalidArgument)
+
+	if _, err := c.TryCallTool("missing", nil); err == nil {
+		t.Error("Expected a protocol error for an unknown tool")
+	}
+}
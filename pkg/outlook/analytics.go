@@ -0,0 +1,201 @@
+package outlook
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Limits on get_inbox_analytics
+const (
+	defaultAnalyticsDays = 30
+	maxAnalyticsDays     = 366
+	defaultAnalyticsTop  = 10
+	maxAnalyticsTop      = 50
+	maxAnalyticsMessages = 10_000 // Messages a backend counts, newest first
+)
+
+// unreadAgeBuckets group unread messages by the number of days since the
+// local date they arrived; the last bucket has no upper bound
+var unreadAgeBuckets = []struct {
+	below int
+	label string
+}{
+	{1, "today"},
+	{7, "1-6 days"},
+	{30, "7-29 days"},
+	{0, "30+ days"},
+}
+
+// validateAnalytics checks an analytics date range and top count
+func validateAnalytics(request AnalyticsRequest) error {
+	if !request.End.After(request.Start) {
+		return fmt.Errorf("end must be after start")
+	}
+	if request.End.Sub(request.Start) > maxAnalyticsDays*24*time.Hour {
+		return fmt.Errorf("the range can cover at most %d days", maxAnalyticsDays)
+	}
+	if request.Top < 0 || request.Top > maxAnalyticsTop {
+		return fmt.Errorf("top must be between 1 and %d", maxAnalyticsTop)
+	}
+	return nil
+}
+
+// GetInboxAnalytics summarizes the mail a folder received over a date range:
+// volume by sender, domain and day, how long unread mail has been waiting and
+// the largest messages. The backend does the counting, so messages aren't
+// paged through one by one.
+func GetInboxAnalytics(manager Backend, request AnalyticsRequest, now time.Time) (*InboxAnalytics, error) {
+	if request.Top == 0 {
+		request.Top = defaultAnalyticsTop
+	}
+	if err := validateAnalytics(request); err != nil {
+		return nil, err
+	}
+
+	stats, err := manager.GetMailboxStats(request.Folder, request.Account, request.Start, request.End, request.Top)
+	if err != nil {
+		return nil, err
+	}
+	return summarizeMailboxStats(stats, request, now), nil
+}
+
+// summarizeMailboxStats ranks a backend's counts and fills in the days and
+// age buckets
+func summarizeMailboxStats(stats *MailboxStats, request AnalyticsRequest, now time.Time) *InboxAnalytics {
+	analytics := &InboxAnalytics{
+		Folder:       stats.Folder,
+		Start:        request.Start,
+		End:          request.End,
+		Messages:     stats.Messages,
+		Unread:       stats.Unread,
+		TotalSize:    stats.TotalSize,
+		Truncated:    stats.Truncated,
+		TopSenders:   topVolumes(stats.Senders, request.Top),
+		OldestUnread: stats.OldestUnread,
+		Largest:      stats.Largest[:min(len(stats.Largest), request.Top)],
+	}
+
+	domains := map[string]*SenderVolume{}
+	for _, sender := range stats.Senders {
+		domain := "(unknown)"
+		if at := strings.LastIndex(sender.Address, "@"); at >= 0 {
+			domain = strings.ToLower(sender.Address[at+1:])
+		}
+		if domains[domain] == nil {
+			domains[domain] = &SenderVolume{Address: domain}
+		}
+		domains[domain].Count += sender.Count
+		domains[domain].Unread += sender.Unread
+		domains[domain].Size += sender.Size
+	}
+	var domainList []SenderVolume
+	for _, domain := range domains {
+		domainList = append(domainList, *domain)
+	}
+	analytics.TopDomains = topVolumes(domainList, request.Top)
+
+	byDate := map[string]DayVolume{}
+	for _, day := range stats.Days {
+		byDate[day.Date] = day
+	}
+	location := request.Start.Location()
+	last := request.End.Add(-time.Nanosecond).In(location)
+	for day := request.Start.In(location); ; day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		volume, ok := byDate[date]
+		if !ok {
+			volume = DayVolume{Date: date}
+		}
+		analytics.ByDay = append(analytics.ByDay, volume)
+		if date == last.Format("2006-01-02") {
+			break
+		}
+	}
+
+	analytics.UnreadAging = make([]AgeBucket, len(unreadAgeBuckets))
+	for i, bucket := range unreadAgeBuckets {
+		analytics.UnreadAging[i].Label = bucket.label
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	for _, day := range stats.Days {
+		if day.Unread == 0 {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", day.Date)
+		if err != nil {
+			continue
+		}
+		age := int(today.Sub(date).Hours() / 24)
+		for i, bucket := range unreadAgeBuckets {
+			if age < bucket.below || bucket.below == 0 {
+				analytics.UnreadAging[i].Count += day.Unread
+				break
+			}
+		}
+	}
+
+	return analytics
+}
+
+// topVolumes returns the n largest counts, ties broken by address
+func topVolumes(volumes []SenderVolume, n int) []SenderVolume {
+	sorted := append([]SenderVolume{}, volumes...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Count != sorted[j].Count {
+			return sorted[i].Count > sorted[j].Count
+		}
+		return sorted[i].Address < sorted[j].Address
+	})
+	return sorted[:min(len(sorted), n)]
+}
+
+// collectMailboxStats counts messages the way the COM sidecar's /stats
+// endpoint does, for backends that can only list them
+func collectMailboxStats(folder string, messages []Message, truncated bool, largest int) *MailboxStats {
+	stats := &MailboxStats{Folder: folder, Truncated: truncated, Senders: []SenderVolume{}, Days: []DayVolume{}}
+	senders := map[string]int{}
+	days := map[string]int{}
+	for _, message := range messages {
+		stats.Messages++
+		stats.TotalSize += int64(message.Size)
+
+		key := strings.ToLower(message.SenderEmail)
+		if key == "" {
+			key = strings.ToLower(message.Sender)
+		}
+		i, ok := senders[key]
+		if !ok {
+			i = len(stats.Senders)
+			senders[key] = i
+			stats.Senders = append(stats.Senders, SenderVolume{Name: message.Sender, Address: key})
+		}
+		date := message.ReceivedTime.Local().Format("2006-01-02")
+		j, ok := days[date]
+		if !ok {
+			j = len(stats.Days)
+			days[date] = j
+			stats.Days = append(stats.Days, DayVolume{Date: date})
+		}
+
+		stats.Senders[i].Count++
+		stats.Senders[i].Size += int64(message.Size)
+		stats.Days[j].Count++
+		if message.Unread {
+			stats.Unread++
+			stats.Senders[i].Unread++
+			stats.Days[j].Unread++
+			if stats.OldestUnread == nil || message.ReceivedTime.Before(*stats.OldestUnread) {
+				received := message.ReceivedTime
+				stats.OldestUnread = &received
+			}
+		}
+	}
+	sort.Slice(stats.Days, func(i, j int) bool { return stats.Days[i].Date < stats.Days[j].Date })
+
+	stats.Largest = append([]Message{}, messages...)
+	sort.SliceStable(stats.Largest, func(i, j int) bool { return stats.Largest[i].Size > stats.Largest[j].Size })
+	stats.Largest = stats.Largest[:min(len(stats.Largest), largest)]
+	return stats
+}
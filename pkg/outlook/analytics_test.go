@@ -0,0 +1,112 @@
+package outlook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInboxAnalyticsGraph(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.Local)
+	received := func(daysAgo int) string {
+		return now.AddDate(0, 0, -daysAgo).UTC().Format(time.RFC3339)
+	}
+	var filter string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		filter = r.URL.Query().Get("$filter")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"value": [
+			{"id": "1", "subject": "Build failed", "from": {"emailAddress": {"name": "CI", "address": "ci@builds.example.com"}}, "receivedDateTime": "` + received(0) + `", "isRead": false,
+			 "singleValueExtendedProperties": [{"id": "Integer 0xe08", "value": "2000"}]},
+			{"id": "2", "subject": "Build passed", "from": {"emailAddress": {"name": "CI", "address": "CI@builds.example.com"}}, "receivedDateTime": "` + received(2) + `", "isRead": true,
+			 "singleValueExtendedProperties": [{"id": "Integer 0xe08", "value": "1000"}]},
+			{"id": "3", "subject": "Report", "from": {"emailAddress": {"name": "Ana", "address": "ana@example.org"}}, "receivedDateTime": "` + received(2) + `", "isRead": false,
+			 "singleValueExtendedProperties": [{"id": "Integer 0xe08", "value": "90000"}]},
+			{"id": "4", "subject": "Nightly", "from": {"emailAddress": {"name": "Ops", "address": "ops@builds.example.com"}}, "receivedDateTime": "` + received(12) + `", "isRead": false,
+			 "singleValueExtendedProperties": [{"id": "Integer 0xe08", "value": "500"}]}
+		]}`))
+	}))
+	defer server.Close()
+
+	start := time.Date(2026, 2, 25, 0, 0, 0, 0, time.Local)
+	analytics, err := GetInboxAnalytics(newTestGraphManager(server.URL), AnalyticsRequest{Start: start, End: now, Top: 2}, now)
+	if err != nil {
+		t.Fatalf("GetInboxAnalytics failed: %v", err)
+	}
+	if !strings.Contains(filter, "receivedDateTime ge "+start.UTC().Format(time.RFC3339)) {
+		t.Errorf("Unexpected filter %q", filter)
+	}
+	if analytics.Messages != 4 || analytics.Unread != 3 || analytics.TotalSize != 93500 || analytics.Folder != "Inbox" {
+		t.Errorf("Unexpected totals: %+v", analytics)
+	}
+	if len(analytics.TopSenders) != 2 || analytics.TopSenders[0].Address != "ci@builds.example.com" || analytics.TopSenders[0].Count != 2 || analytics.TopSenders[0].Unread != 1 {
+		t.Errorf("Unexpected top senders: %+v", analytics.TopSenders)
+	}
+	if len(analytics.TopDomains) != 2 || analytics.TopDomains[0].Address != "builds.example.com" || analytics.TopDomains[0].Count != 3 {
+		t.Errorf("Unexpected top domains: %+v", analytics.TopDomains)
+	}
+	if len(analytics.ByDay) != 14 || analytics.ByDay[0].Date != "2026-02-25" || analytics.ByDay[13].Count != 1 || analytics.ByDay[11].Count != 2 {
+		t.Errorf("Unexpected days: %+v", analytics.ByDay)
+	}
+	aging := map[string]int{}
+	for _, bucket := range analytics.UnreadAging {
+		aging[bucket.Label] = bucket.Count
+	}
+	if aging["today"] != 1 || aging["1-6 days"] != 1 || aging["7-29 days"] != 1 || aging["30+ days"] != 0 {
+		t.Errorf("Unexpected unread aging: %+v", analytics.UnreadAging)
+	}
+	if analytics.OldestUnread == nil || analytics.OldestUnread.Format(time.RFC3339) != received(12) {
+		t.Errorf("Unexpected oldest unread: %v", analytics.OldestUnread)
+	}
+	if len(analytics.Largest) != 2 || analytics.Largest[0].ID != "3" || analytics.Largest[1].ID != "1" {
+		t.Errorf("Unexpected largest messages: %+v", analytics.Largest)
+	}
+}
+
+func TestInboxAnalyticsCOM(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/stats" {
+			t.Errorf("Unexpected request %s", r.URL.Path)
+		}
+		query = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"folder": "Archive", "messages": 10000, "unread": 2, "totalSize": 500, "truncated": true,
+			"senders": [{"name": "Ana", "address": "ana@example.org", "count": 10000, "unread": 2, "size": 500}],
+			"days": [{"date": "2026-03-09", "count": 10000, "unread": 2}], "largest": []}`))
+	}))
+	defer server.Close()
+	manager := &Manager{baseURL: server.URL, client: &http.Client{Timeout: 5 * time.Second}}
+
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.Local)
+	start, end, err := parseAnalyticsRange("7d", "2026-03-09", now)
+	if err != nil {
+		t.Fatalf("parseAnalyticsRange failed: %v", err)
+	}
+	analytics, err := GetInboxAnalytics(manager, AnalyticsRequest{Folder: "archive", Start: start, End: end}, now)
+	if err != nil {
+		t.Fatalf("GetInboxAnalytics failed: %v", err)
+	}
+	if query.Get("folder") != "archive" || query.Get("largest") != "10" || query.Get("limit") != "10000" || query.Get("before") != "2026-03-10T00:00:00"+now.Format("Z07:00") {
+		t.Errorf("Unexpected query %v", query)
+	}
+	if !analytics.Truncated || len(analytics.ByDay) != 7 || analytics.UnreadAging[1].Count != 2 {
+		t.Errorf("Unexpected analytics: %+v", analytics)
+	}
+	if text := formatInboxAnalytics(analytics); !strings.Contains(text, "Only the newest 10000 messages were counted") || !strings.Contains(text, "1. Ana <ana@example.org>: 10000 messages (2 unread)") {
+		t.Errorf("Unexpected text:\n%s", text)
+	}
+
+	for _, bad := range []AnalyticsRequest{
+		{Start: end, End: start},
+		{Start: now.AddDate(-2, 0, 0), End: now},
+		{Start: start, End: end, Top: maxAnalyticsTop + 1},
+	} {
+		if _, err := GetInboxAnalytics(manager, bad, now); err == nil {
+			t.Errorf("Expected an error for %+v", bad)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package outlook
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Backend names accepted by OUTLOOK_BACKEND and outlook-mcp's --backend flag
+const (
+	BackendCOM   = "com"   // Desktop Outlook through the PowerShell COM sidecar (Windows only)
+	BackendGraph = "graph" // Microsoft Graph REST API (any platform, cloud mailboxes)
+)
+
+// Backend is the mailbox access layer behind the Outlook tools. Manager talks to
+// desktop Outlook through the PowerShell sidecar; GraphManager talks to
+// Microsoft Graph. Handlers only depend on this interface.
+type Backend interface {
+	ListFolders(account string) (*FolderListResponse, error)
+	ListMessages(page int, folder, account string, filters ListFilters) (*MessageListResponse, error)
+	ListMessagesSince(since time.Time, page int, folder, account string) (*MessageListResponse, error)
+	GetMessage(messageID string) (*Message, error)
+	GetMessageBody(messageID string) (*MessageBodyResponse, error)
+	GetMessageBodyRaw(messageID string) (*MessageBodyRawResponse, error)
+	GetConversation(messageID string) (*ConversationResponse, error)
+	SearchMessages(query, folder, account string, filters SearchFilters, page, pageSize int) (*SearchResponse, error)
+	GetMailboxStats(folder, account string, start, end time.Time, largest int) (*MailboxStats, error)
+	UpdateMessage(messageID string, request UpdateMessageRequest) (*UpdateMessageResponse, error)
+	MoveMessage(messageID, folder, account string) (*MoveMessageResponse, error)
+	DeleteMessage(messageID string, permanent bool) (*DeleteMessageResponse, error)
+	CreateDraft(request DraftRequest) (*DraftResponse, error)
+	ListAttachments(messageID string) (*AttachmentListResponse, error)
+	SaveAttachment(messageID string, index int, directory string) (*SaveAttachmentResponse, error)
+	GetAttachmentContent(messageID string, index int) (*AttachmentContentResponse, error)
+	ExportMessage(messageID, format, path string) (*ExportMessageResponse, error)
+	ListCalendarEvents(start, end time.Time, limit int) (*CalendarEventListResponse, error)
+	GetEvent(eventID string) (*CalendarEvent, error)
+	GetAutomaticReplies(account string) (*AutomaticReplyStatus, error)
+	GetProfile() (*UserProfile, error)
+	GetFreeBusy(attendees []string, start, end time.Time, interval int) (*FreeBusyResponse, error)
+	ListTasks(status string, limit int) (*TaskListResponse, error)
+	CreateTask(request CreateTaskRequest) (*Task, error)
+	CompleteTask(taskID string) (*Task, error)
+	ListAccounts() (*AccountListResponse, error)
+	ListRules() (*RuleListResponse, error)
+	ListCategories() (*CategoryListResponse, error)
+	SearchContacts(query string, limit int) (*ContactSearchResponse, error)
+	GetContact(contactID string) (*Contact, error)
+	ResolveRecipient(name string, expand bool) (*RecipientResolution, error)
+	SendMessage(request SendMessageRequest) (*SendResponse, error)
+	ReplyToMessage(messageID string, request ReplyRequest) (*SendResponse, error)
+	ForwardMessage(messageID string, request ForwardRequest) (*SendResponse, error)
+	HealthCheck() *HealthStatus
+	Reconnect() error
+	ServerLogs(limit int, stream, filter string) (*ServerLogsResponse, error)
+	SendEnabled() bool
+	PermanentDeleteEnabled() bool
+	Stop() error
+}
+
+var (
+	_ Backend = (*Manager)(nil)
+	_ Backend = (*GraphManager)(nil)
+)
+
+// GetSaveRoots returns the directories in OUTLOOK_SAVE_ROOTS, separated like
+// PATH. When set, save_attachment, export_message and export_messages only
+// write under them, and create_draft only attaches files from under them;
+// without them create_draft attaches nothing.
+func GetSaveRoots() []string {
+	return filepath.SplitList(os.Getenv("OUTLOOK_SAVE_ROOTS"))
+}
+
+// NewBackend creates the backend selected by OUTLOOK_BACKEND: the COM sidecar
+// by default, or Microsoft Graph
+func NewBackend() (Backend, error) {
+	switch name := os.Getenv("OUTLOOK_BACKEND"); name {
+	case "", BackendCOM:
+		manager, err := NewManager()
+		if err != nil {
+			return nil, err
+		}
+		return manager, nil
+	case BackendGraph:
+		manager, err := NewGraphManager()
+		if err != nil {
+			return nil, err
+		}
+		return manager, nil
+	default:
+		return nil, fmt.Errorf("unknown Outlook backend %q (expected %s or %s)", name, BackendCOM, BackendGraph)
+	}
+}
@@ -0,0 +1,172 @@
+package outlook
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kevsmith/my-mcp/pkg/shared"
+)
+
+// Limits on how many messages a search-based bulk update may select
+const (
+	defaultBulkLimit = 50
+	maxBulkLimit     = 500
+)
+
+// validateBulkUpdate checks that a bulk update names a usable action and exactly one way of selecting messages
+func validateBulkUpdate(request BulkUpdateRequest, permanentDeleteEnabled bool) error {
+	switch request.Action {
+	case BulkActionMarkRead, BulkActionMarkUnread:
+	case BulkActionMove:
+		if request.Destination == "" {
+			return fmt.Errorf("destination is required for move")
+		}
+	case BulkActionCategorize:
+		if len(request.Categories) == 0 {
+			return fmt.Errorf("categories are required for categorize")
+		}
+	case BulkActionDelete:
+		if request.Permanent && !permanentDeleteEnabled {
+			return shared.NewError(shared.CodeAccessDenied, "permanent deletion is disabled; start outlook-mcp with --allow-permanent-delete to enable it")
+		}
+		// Deleting what is already in Deleted Items removes it for good
+		if !request.Permanent && isDeletedItemsFolder(request.Folder) {
+			if !permanentDeleteEnabled {
+				return shared.NewError(shared.CodeAccessDenied, "the messages are already in Deleted Items and deleting them again would remove them permanently, which is disabled; start outlook-mcp with --allow-permanent-delete to enable it")
+			}
+			return fmt.Errorf("the messages are already in Deleted Items; set permanent to remove them for good")
+		}
+	default:
+		return fmt.Errorf("invalid action %q (expected %s, %s, %s, %s or %s)", request.Action,
+			BulkActionMarkRead, BulkActionMarkUnread, BulkActionMove, BulkActionCategorize, BulkActionDelete)
+	}
+	if request.Permanent && request.Action != BulkActionDelete {
+		return fmt.Errorf("permanent only applies to delete")
+	}
+
+	bySearch := request.Query != "" || !request.Filters.IsEmpty()
+	if len(request.MessageIDs) > 0 && bySearch {
+		return fmt.Errorf("give either message IDs or a search query and filters, not both")
+	}
+	if len(request.MessageIDs) == 0 && !bySearch {
+		return fmt.Errorf("message IDs or a search query or filter is required")
+	}
+	if len(request.MessageIDs) > maxBulkLimit {
+		return fmt.Errorf("at most %d message IDs can be updated at once", maxBulkLimit)
+	}
+	if request.Limit < 0 || request.Limit > maxBulkLimit {
+		return fmt.Errorf("limit must be between 1 and %d", maxBulkLimit)
+	}
+	return validateSearch(request.Filters, 0)
+}
+
+// BulkUpdateMessages applies one action to a set of messages. The whole set is
+// resolved before anything changes, so actions that alter search results (such
+// as marking unread matches read) do not shift later pages. Failures on
+// individual messages are collected rather than aborting the run.
+func BulkUpdateMessages(manager Backend, request BulkUpdateRequest) (*BulkUpdateResult, error) {
+	if err := validateBulkUpdate(request, manager.PermanentDeleteEnabled()); err != nil {
+		return nil, err
+	}
+
+	result := &BulkUpdateResult{Action: request.Action, DryRun: request.DryRun}
+	if len(request.MessageIDs) > 0 {
+		for _, id := range request.MessageIDs {
+			message, err := manager.GetMessage(id)
+			if err != nil {
+				result.Failures = append(result.Failures, BulkFailure{MessageID: id, Error: err.Error()})
+				continue
+			}
+			result.Messages = append(result.Messages, *message)
+		}
+	} else if err := collectBulkMatches(manager, request, result); err != nil {
+		return nil, err
+	}
+
+	if request.DryRun {
+		return result, nil
+	}
+
+	for _, message := range result.Messages {
+		if err := applyBulkAction(manager, request, message); err != nil {
+			result.Failures = append(result.Failures, BulkFailure{MessageID: message.ID, Error: err.Error()})
+			continue
+		}
+		result.Succeeded++
+	}
+	return result, nil
+}
+
+// collectBulkMatches pages through search results until the limit is reached
+func collectBulkMatches(manager Backend, request BulkUpdateRequest, result *BulkUpdateResult) error {
+	limit := request.Limit
+	if limit == 0 {
+		limit = defaultBulkLimit
+	}
+
+	for page := 1; ; page++ {
+		response, err := manager.SearchMessages(request.Query, request.Folder, request.Account, request.Filters, page, maxSearchPageSize)
+		if err != nil {
+			return fmt.Errorf("failed to search messages: %w", err)
+		}
+		for _, message := range response.Results {
+			if len(result.Messages) == limit {
+				result.Truncated = true
+				return nil
+			}
+			result.Messages = append(result.Messages, message)
+		}
+		if response.Truncated {
+			result.Truncated = true
+		}
+		if !response.Pagination.HasNext {
+			return nil
+		}
+		if len(result.Messages) == limit {
+			result.Truncated = true
+			return nil
+		}
+	}
+}
+
+// applyBulkAction changes a single message
+func applyBulkAction(manager Backend, request BulkUpdateRequest, message Message) error {
+	var err error
+	switch request.Action {
+	case BulkActionMarkRead, BulkActionMarkUnread:
+		unread := request.Action == BulkActionMarkUnread
+		_, err = manager.UpdateMessage(message.ID, UpdateMessageRequest{Unread: &unread})
+	case BulkActionMove:
+		_, err = manager.MoveMessage(message.ID, request.Destination, request.Account)
+	case BulkActionCategorize:
+		categories := mergeCategories(message.Categories, request.Categories)
+		_, err = manager.UpdateMessage(message.ID, UpdateMessageRequest{Categories: &categories})
+	case BulkActionDelete:
+		_, err = manager.DeleteMessage(message.ID, request.Permanent)
+	}
+	return err
+}
+
+// isDeletedItemsFolder reports whether a folder argument names Deleted Items
+func isDeletedItemsFolder(folder string) bool {
+	name := strings.ToLower(strings.TrimSpace(folder))
+	return name == "deleteditems" || graphWellKnownFolders[name] == "deleteditems"
+}
+
+// mergeCategories adds categories a message does not already have, ignoring case
+func mergeCategories(existing, added []string) []string {
+	merged := append([]string{}, existing...)
+	for _, category := range added {
+		found := false
+		for _, current := range merged {
+			if strings.EqualFold(current, category) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, category)
+		}
+	}
+	return merged
+}
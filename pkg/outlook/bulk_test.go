@@ -0,0 +1,150 @@
+package outlook
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newBulkTestServer serves three pages of unread search results with two messages each
+// and records every PATCH body by message ID
+func newBulkTestServer(t *testing.T, patches map[string]UpdateMessageRequest, mu *sync.Mutex) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/search":
+			page := r.URL.Query().Get("page")
+			w.Write([]byte(`{"query": "invoice", "results": [
+				{"id": "p` + page + `a", "subject": "Invoice", "unread": true, "categories": ["Finance"]},
+				{"id": "p` + page + `b", "subject": "Invoice", "unread": true}],
+				"count": 2, "pagination": {"page": 1, "pageSize": 100, "total": 6, "hasNext": ` + strconv.FormatBool(page != "3") + `}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/messages/missing":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": "Message not found", "code": "MESSAGE_NOT_FOUND"}`))
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/messages/"):
+			w.Write([]byte(`{"id": "` + strings.TrimPrefix(r.URL.Path, "/messages/") + `", "subject": "Hello"}`))
+		case r.Method == http.MethodPatch:
+			var request UpdateMessageRequest
+			if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+				t.Errorf("Invalid PATCH body: %v", err)
+			}
+			mu.Lock()
+			patches[strings.TrimPrefix(r.URL.Path, "/messages/")] = request
+			mu.Unlock()
+			w.Write([]byte(`{"id": "x"}`))
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestBulkUpdateMessagesBySearch(t *testing.T) {
+	var mu sync.Mutex
+	patches := map[string]UpdateMessageRequest{}
+	server := newBulkTestServer(t, patches, &mu)
+	defer server.Close()
+
+	manager := &Manager{baseURL: server.URL, client: &http.Client{Timeout: 5 * time.Second}}
+	unread := true
+
+	// A dry run resolves the set without changing anything
+	result, err := BulkUpdateMessages(manager, BulkUpdateRequest{
+		Action:     BulkActionCategorize,
+		Query:      "invoice",
+		Filters:    SearchFilters{Unread: &unread},
+		Categories: []string{"finance", "Q3"},
+		Limit:      5,
+		DryRun:     true,
+	})
+	if err != nil {
+		t.Fatalf("Dry run failed: %v", err)
+	}
+	if len(result.Messages) != 5 || !result.Truncated || len(patches) != 0 {
+		t.Errorf("Expected 5 of 6 messages and no changes, got %d (truncated %t) and %d patches", len(result.Messages), result.Truncated, len(patches))
+	}
+
+	result, err = BulkUpdateMessages(manager, BulkUpdateRequest{
+		Action:     BulkActionCategorize,
+		Query:      "invoice",
+		Categories: []string{"finance", "Q3"},
+	})
+	if err != nil {
+		t.Fatalf("Bulk update failed: %v", err)
+	}
+	if result.Succeeded != 6 || len(patches) != 6 {
+		t.Fatalf("Expected all 6 messages updated, got %d and %d patches", result.Succeeded, len(patches))
+	}
+	if categories := *patches["p1a"].Categories; strings.Join(categories, ",") != "Finance,Q3" {
+		t.Errorf("Expected new categories merged into existing ones, got %v", categories)
+	}
+}
+
+func TestBulkUpdateMessagesByID(t *testing.T) {
+	var mu sync.Mutex
+	patches := map[string]UpdateMessageRequest{}
+	server := newBulkTestServer(t, patches, &mu)
+	defer server.Close()
+
+	manager := &Manager{baseURL: server.URL, client: &http.Client{Timeout: 5 * time.Second}}
+	result, err := BulkUpdateMessages(manager, BulkUpdateRequest{
+		Action:     BulkActionMarkRead,
+		MessageIDs: []string{"a", "missing", "b"},
+	})
+	if err != nil {
+		t.Fatalf("Bulk update failed: %v", err)
+	}
+	if result.Succeeded != 2 || len(result.Failures) != 1 || result.Failures[0].MessageID != "missing" {
+		t.Errorf("Expected 2 updates and the missing message reported, got %+v", result)
+	}
+	if patch := patches["a"]; patch.Unread == nil || *patch.Unread {
+		t.Errorf("Expected a to be marked read, got %+v", patch)
+	}
+
+	formatted := formatBulkUpdateResult(result)
+	if !strings.Contains(formatted, "Applied mark_read to 2 of 2 messages") || !strings.Contains(formatted, "- missing:") {
+		t.Errorf("Unexpected formatted result:\n%s", formatted)
+	}
+}
+
+func TestValidateBulkUpdate(t *testing.T) {
+	tests := []struct {
+		name    string
+		request BulkUpdateRequest
+	}{
+		{"unknown action", BulkUpdateRequest{Action: "archive", MessageIDs: []string{"a"}}},
+		{"no selection", BulkUpdateRequest{Action: BulkActionMarkRead}},
+		{"ids and query", BulkUpdateRequest{Action: BulkActionMarkRead, MessageIDs: []string{"a"}, Query: "x"}},
+		{"move without destination", BulkUpdateRequest{Action: BulkActionMove, Query: "x"}},
+		{"categorize without categories", BulkUpdateRequest{Action: BulkActionCategorize, Query: "x"}},
+		{"permanent without delete", BulkUpdateRequest{Action: BulkActionMarkRead, Query: "x", Permanent: true}},
+		{"permanent delete disabled", BulkUpdateRequest{Action: BulkActionDelete, Query: "x", Permanent: true}},
+		{"delete from Deleted Items", BulkUpdateRequest{Action: BulkActionDelete, Query: "x", Folder: "Deleted Items"}},
+		{"delete from deleteditems", BulkUpdateRequest{Action: BulkActionDelete, Query: "x", Folder: "deleteditems"}},
+		{"limit too large", BulkUpdateRequest{Action: BulkActionDelete, Query: "x", Limit: maxBulkLimit + 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateBulkUpdate(tt.request, false); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+
+	if err := validateBulkUpdate(BulkUpdateRequest{Action: BulkActionDelete, Query: "x", Permanent: true}, true); err != nil {
+		t.Errorf("Expected permanent delete to be allowed, got %v", err)
+	}
+	// Even with purging allowed, a soft delete must not purge Deleted Items
+	if err := validateBulkUpdate(BulkUpdateRequest{Action: BulkActionDelete, Query: "x", Folder: "deleted"}, true); err == nil {
+		t.Error("Expected a soft delete from Deleted Items to be refused")
+	}
+	if err := validateBulkUpdate(BulkUpdateRequest{Action: BulkActionDelete, Query: "x", Folder: "deleted", Permanent: true}, true); err != nil {
+		t.Errorf("Expected a permanent delete from Deleted Items to be allowed, got %v", err)
+	}
+}
@@ -0,0 +1,183 @@
+package outlook
+
+import (
+	"container/list"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kevsmith/my-mcp/pkg/shared"
+)
+
+// CacheEntry represents a cached PowerShell server response with TTL
+type CacheEntry struct {
+	body     []byte
+	expireAt time.Time
+	listNode *list.Element
+}
+
+// ResponseCache is an LRU cache with TTL for read responses from the
+// PowerShell server, keyed by request endpoint including the query string
+type ResponseCache struct {
+	mutex      sync.Mutex
+	cache      map[string]*CacheEntry
+	lruList    *list.List
+	maxSize    int
+	defaultTTL time.Duration
+	hits       int64 // Guarded by mutex
+	misses     int64
+}
+
+// CacheConfig holds cache configuration parameters
+type CacheConfig struct {
+	MaxSize    int
+	DefaultTTL time.Duration // Zero disables caching
+}
+
+// GetCacheConfig returns cache configuration from environment variables or defaults
+func GetCacheConfig() CacheConfig {
+	config := CacheConfig{
+		MaxSize:    200,              // Default max 200 responses
+		DefaultTTL: 30 * time.Second, // Default 30 second TTL; mail changes underneath us
+	}
+
+	if maxSizeStr := os.Getenv("OUTLOOK_CACHE_MAX_SIZE"); maxSizeStr != "" {
+		if maxSize, err := strconv.Atoi(maxSizeStr); err == nil && maxSize > 0 {
+			config.MaxSize = maxSize
+		}
+	}
+
+	if ttlStr := os.Getenv("OUTLOOK_CACHE_TTL_SECONDS"); ttlStr != "" {
+		if ttlSeconds, err := strconv.Atoi(ttlStr); err == nil && ttlSeconds >= 0 {
+			config.DefaultTTL = time.Duration(ttlSeconds) * time.Second
+		}
+	}
+
+	return config
+}
+
+// NewResponseCache creates a new LRU cache with TTL for server responses, or
+// nil when the configured TTL disables caching
+func NewResponseCache(config CacheConfig) *ResponseCache {
+	if config.DefaultTTL <= 0 || config.MaxSize <= 0 {
+		return nil
+	}
+
+	return &ResponseCache{
+		cache:      make(map[string]*CacheEntry),
+		lruList:    list.New(),
+		maxSize:    config.MaxSize,
+		defaultTTL: config.DefaultTTL,
+	}
+}
+
+// cacheableEndpoint reports whether a GET endpoint's response may be served
+// from the cache. Health probes must reach the server, and polling for new mail
+// with a fixed since timestamp must not see a stale page.
+func cacheableEndpoint(endpoint string) bool {
+	switch {
+	case strings.HasPrefix(endpoint, "/health"):
+		return false
+	case strings.Contains(endpoint, "since="):
+		return false
+	case strings.HasPrefix(endpoint, "/messages"),
+		strings.HasPrefix(endpoint, "/search"),
+		strings.HasPrefix(endpoint, "/folders"):
+		return true
+	default:
+		return false
+	}
+}
+
+// Get retrieves a response from the cache if it exists and hasn't expired
+func (rc *ResponseCache) Get(endpoint string) ([]byte, bool) {
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+
+	entry, exists := rc.cache[endpoint]
+	if !exists {
+		rc.misses++
+		return nil, false
+	}
+
+	if time.Now().After(entry.expireAt) {
+		rc.removeEntry(endpoint, entry)
+		rc.misses++
+		return nil, false
+	}
+
+	rc.lruList.MoveToFront(entry.listNode)
+	rc.hits++
+	return entry.body, true
+}
+
+// Put stores a response in the cache
+func (rc *ResponseCache) Put(endpoint string, body []byte) {
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+
+	// If already exists, update it
+	if entry, exists := rc.cache[endpoint]; exists {
+		entry.body = body
+		entry.expireAt = time.Now().Add(rc.defaultTTL)
+		rc.lruList.MoveToFront(entry.listNode)
+		return
+	}
+
+	entry := &CacheEntry{
+		body:     body,
+		expireAt: time.Now().Add(rc.defaultTTL),
+	}
+	entry.listNode = rc.lruList.PushFront(endpoint)
+	rc.cache[endpoint] = entry
+
+	// Evict oldest entries if cache is full
+	for rc.lruList.Len() > rc.maxSize {
+		rc.evictOldest()
+	}
+}
+
+// Clear removes all entries from the cache; write operations call it because
+// a move, delete or flag change can affect any cached list or message
+func (rc *ResponseCache) Clear() {
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+
+	clear(rc.cache)
+	rc.lruList.Init()
+}
+
+// Size returns the current number of cached responses
+func (rc *ResponseCache) Size() int {
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+	return len(rc.cache)
+}
+
+// Stats returns the cache's hit and miss counts and current size
+func (rc *ResponseCache) Stats() shared.CacheStats {
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+	return shared.CacheStats{Hits: rc.hits, Misses: rc.misses, Entries: len(rc.cache)}
+}
+
+// removeEntry removes an entry from both cache map and LRU list
+func (rc *ResponseCache) removeEntry(endpoint string, entry *CacheEntry) {
+	delete(rc.cache, endpoint)
+	rc.lruList.Remove(entry.listNode)
+}
+
+// evictOldest removes the least recently used entry
+func (rc *ResponseCache) evictOldest() {
+	oldest := rc.lruList.Back()
+	if oldest == nil {
+		return
+	}
+
+	endpoint := oldest.Value.(string)
+	if entry := rc.cache[endpoint]; entry != nil {
+		rc.removeEntry(endpoint, entry)
+	}
+}
@@ -0,0 +1,130 @@
+package outlook
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGetCacheConfig(t *testing.T) {
+	t.Setenv("OUTLOOK_CACHE_MAX_SIZE", "")
+	t.Setenv("OUTLOOK_CACHE_TTL_SECONDS", "")
+
+	config := GetCacheConfig()
+	if config.MaxSize != 200 {
+		t.Errorf("Expected default MaxSize 200, got %d", config.MaxSize)
+	}
+	if config.DefaultTTL != 30*time.Second {
+		t.Errorf("Expected default TTL 30 seconds, got %v", config.DefaultTTL)
+	}
+
+	t.Setenv("OUTLOOK_CACHE_MAX_SIZE", "50")
+	t.Setenv("OUTLOOK_CACHE_TTL_SECONDS", "0")
+
+	config = GetCacheConfig()
+	if config.MaxSize != 50 {
+		t.Errorf("Expected MaxSize 50 from env var, got %d", config.MaxSize)
+	}
+	if config.DefaultTTL != 0 {
+		t.Errorf("Expected TTL 0 from env var, got %v", config.DefaultTTL)
+	}
+	if NewResponseCache(config) != nil {
+		t.Error("Expected a zero TTL to disable the cache")
+	}
+}
+
+func TestResponseCacheOperations(t *testing.T) {
+	cache := NewResponseCache(CacheConfig{MaxSize: 2, DefaultTTL: time.Hour})
+
+	cache.Put("/messages?page=1", []byte("one"))
+	cache.Put("/messages?page=2", []byte("two"))
+
+	if body, ok := cache.Get("/messages?page=1"); !ok || string(body) != "one" {
+		t.Errorf("Expected cached page 1, got %q, %t", body, ok)
+	}
+
+	// Page 2 is now least recently used and is evicted
+	cache.Put("/messages?page=3", []byte("three"))
+	if _, ok := cache.Get("/messages?page=2"); ok {
+		t.Error("Expected page 2 to be evicted")
+	}
+	if cache.Size() != 2 {
+		t.Errorf("Expected size 2, got %d", cache.Size())
+	}
+	if stats := cache.Stats(); stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("Expected 1 hit and 1 miss, got %+v", stats)
+	}
+
+	cache.Clear()
+	if cache.Size() != 0 {
+		t.Errorf("Expected empty cache after Clear, got %d", cache.Size())
+	}
+
+	expiring := NewResponseCache(CacheConfig{MaxSize: 2, DefaultTTL: time.Millisecond})
+	expiring.Put("/folders", []byte("folders"))
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := expiring.Get("/folders"); ok {
+		t.Error("Expected expired entry to be dropped")
+	}
+}
+
+func TestCacheableEndpoint(t *testing.T) {
+	tests := map[string]bool{
+		"/messages?page=1":                    true,
+		"/messages/abc/body":                  true,
+		"/search?q=report":                    true,
+		"/folders":                            true,
+		"/health":                             false,
+		"/messages?page=1&since=2026-01-01":   false,
+		"/calendar/events?start=2026-01-01":   false,
+		"/messages/abc/attachments/0/content": true,
+	}
+
+	for endpoint, expected := range tests {
+		if got := cacheableEndpoint(endpoint); got != expected {
+			t.Errorf("cacheableEndpoint(%q) = %t, expected %t", endpoint, got, expected)
+		}
+	}
+}
+
+func TestManagerCachesReadsAndInvalidatesOnWrite(t *testing.T) {
+	listCalls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			listCalls++
+			json.NewEncoder(w).Encode(MessageListResponse{})
+		case http.MethodPost:
+			json.NewEncoder(w).Encode(MoveMessageResponse{})
+		}
+	}))
+	defer server.Close()
+
+	manager := &Manager{
+		baseURL: server.URL,
+		client:  &http.Client{Timeout: 5 * time.Second},
+		cache:   NewResponseCache(CacheConfig{MaxSize: 10, DefaultTTL: time.Hour}),
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := manager.ListMessages(1, "", "", ListFilters{}); err != nil {
+			t.Fatalf("ListMessages failed: %v", err)
+		}
+	}
+	if listCalls != 1 {
+		t.Errorf("Expected second list to be served from cache, got %d server calls", listCalls)
+	}
+
+	if _, err := manager.MoveMessage("msg1", "Archive", ""); err != nil {
+		t.Fatalf("MoveMessage failed: %v", err)
+	}
+	if _, err := manager.ListMessages(1, "", "", ListFilters{}); err != nil {
+		t.Fatalf("ListMessages failed: %v", err)
+	}
+	if listCalls != 2 {
+		t.Errorf("Expected write to invalidate the cache, got %d server calls", listCalls)
+	}
+}
@@ -7,11 +7,30 @@ import (
 func GetToolDefinitions() []mcp.Tool {
 	return []mcp.Tool{
 		mcp.NewTool("list_messages",
-			mcp.WithDescription("List messages from Outlook inbox with pagination"),
+			mcp.WithDescription("List messages from an Outlook folder with pagination (defaults to the inbox), optionally only unread messages or those of an importance, category or attachment state"),
 			mcp.WithReadOnlyHintAnnotation(true),
 			mcp.WithNumber("page",
 				mcp.Description("Page number (default: 1)"),
 			),
+			mcp.WithString("folder",
+				mcp.Description("Folder to list: well-known name (inbox, sent, drafts, deleted, junk), folder path (e.g., 'Archive', 'Inbox/Projects') or folder ID from list_folders (default: inbox)"),
+			),
+			mcp.WithString("account",
+				mcp.Description("Account or mailbox: email address or mailbox name from list_accounts, or a shared mailbox address (default: the default account)"),
+			),
+			mcp.WithBoolean("unread_only",
+				mcp.Description("Only unread messages"),
+			),
+			mcp.WithString("importance",
+				mcp.Description("Only messages of this importance"),
+				mcp.Enum("low", "normal", "high"),
+			),
+			mcp.WithString("category",
+				mcp.Description("Only messages with this category"),
+			),
+			mcp.WithBoolean("has_attachments",
+				mcp.Description("Only messages with (true) or without (false) attachments"),
+			),
 		),
 		mcp.NewTool("get_message",
 			mcp.WithDescription("Get full details of a specific message by ID"),
@@ -22,28 +41,635 @@ func GetToolDefinitions() []mcp.Tool {
 			),
 		),
 		mcp.NewTool("get_message_body",
-			mcp.WithDescription("Get the readable text content of a message"),
+			mcp.WithDescription("Get the readable text content of a message, with its total length. Long bodies are returned in windows: pass the offset given at the end of one call to get the next window"),
 			mcp.WithReadOnlyHintAnnotation(true),
 			mcp.WithString("message_id",
 				mcp.Description("The message ID (EntryID from Outlook)"),
 				mcp.Required(),
 			),
+			mcp.WithNumber("offset",
+				mcp.Description("Character to start from (default: 0)"),
+			),
+			mcp.WithNumber("max_chars",
+				mcp.Description("Maximum number of characters to return (default: 20000, maximum: 100000)"),
+			),
 		),
 		mcp.NewTool("get_message_body_raw",
-			mcp.WithDescription("Get the raw body content (HTML and plain text) of a message"),
+			mcp.WithDescription("Get the raw body content (HTML and plain text) of a message, with their total lengths. Long bodies are returned in windows over both: pass the offset given at the end of one call to get the next window"),
 			mcp.WithReadOnlyHintAnnotation(true),
 			mcp.WithString("message_id",
 				mcp.Description("The message ID (EntryID from Outlook)"),
 				mcp.Required(),
 			),
+			mcp.WithNumber("offset",
+				mcp.Description("Character to start from in each body (default: 0)"),
+			),
+			mcp.WithNumber("max_chars",
+				mcp.Description("Maximum number of characters to return of each body (default: 20000, maximum: 100000)"),
+			),
 		),
 		mcp.NewTool("search_messages",
-			mcp.WithDescription("Search messages in Outlook inbox by subject, body, or sender"),
+			mcp.WithDescription("Search messages in an Outlook folder by subject, body, or sender (defaults to the inbox), optionally narrowed by sender, recipient, date range, attachments, read state and importance. Matches are paged newest first; each page is ordered by relevance (subject matches first, then sender, then body, newer first) and includes a snippet of the matching body text"),
 			mcp.WithReadOnlyHintAnnotation(true),
 			mcp.WithString("query",
-				mcp.Description("Search query to match against subject, body, or sender"),
+				mcp.Description("Search query to match against subject, body, or sender (optional when filters are given)"),
+			),
+			mcp.WithString("folder",
+				mcp.Description("Folder to search: well-known name, folder path or folder ID (default: inbox)"),
+			),
+			mcp.WithString("account",
+				mcp.Description("Account or mailbox: email address or mailbox name from list_accounts, or a shared mailbox address (default: the default account)"),
+			),
+			mcp.WithString("from",
+				mcp.Description("Only messages whose sender name or address contains this text"),
+			),
+			mcp.WithString("to",
+				mcp.Description("Only messages whose To or Cc recipients contain this name"),
+			),
+			mcp.WithString("after",
+				mcp.Description("Only messages received on or after this date (YYYY-MM-DD or RFC 3339)"),
+			),
+			mcp.WithString("before",
+				mcp.Description("Only messages received before this date; a YYYY-MM-DD date includes that whole day"),
+			),
+			mcp.WithBoolean("has_attachments",
+				mcp.Description("Only messages with (true) or without (false) attachments"),
+			),
+			mcp.WithBoolean("unread",
+				mcp.Description("Only unread (true) or read (false) messages"),
+			),
+			mcp.WithString("importance",
+				mcp.Description("Only messages of this importance"),
+				mcp.Enum("low", "normal", "high"),
+			),
+			mcp.WithNumber("page",
+				mcp.Description("Page number (default: 1)"),
+			),
+			mcp.WithNumber("page_size",
+				mcp.Description("Results per page (default: 25, maximum: 100)"),
+			),
+		),
+		mcp.NewTool("list_folders",
+			mcp.WithDescription("List the mail folders in the Outlook mailbox, including Sent Items, Archive and custom folders"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("account",
+				mcp.Description("Account or mailbox: email address or mailbox name from list_accounts, or a shared mailbox address (default: the default account)"),
+			),
+		),
+		mcp.NewTool("update_message",
+			mcp.WithDescription("Triage a message: mark it read or unread, flag it for follow-up or complete, and set its categories"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithIdempotentHintAnnotation(true),
+			mcp.WithString("message_id",
+				mcp.Description("The message ID (EntryID from Outlook)"),
+				mcp.Required(),
+			),
+			mcp.WithBoolean("unread",
+				mcp.Description("Set to true to mark unread, false to mark read"),
+			),
+			mcp.WithString("flag",
+				mcp.Description("Follow-up flag: 'flagged', 'complete' or 'none' to clear"),
+				mcp.Enum("flagged", "complete", "none"),
+			),
+			mcp.WithArray("categories",
+				mcp.Description("Categories to assign, replacing existing ones (empty list clears all)"),
+				mcp.WithStringItems(),
+			),
+		),
+		mcp.NewTool("move_message",
+			mcp.WithDescription("Move a message to another folder; returns the message's new ID"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithString("message_id",
+				mcp.Description("The message ID (EntryID from Outlook)"),
+				mcp.Required(),
+			),
+			mcp.WithString("folder",
+				mcp.Description("Destination folder: well-known name (inbox, sent, drafts, deleted, junk), folder path (e.g., 'Archive', 'Inbox/Projects') or folder ID from list_folders"),
+				mcp.Required(),
+			),
+			mcp.WithString("account",
+				mcp.Description("Account or mailbox containing the destination folder (default: the default account)"),
+			),
+		),
+		mcp.NewTool("delete_message",
+			mcp.WithDescription("Delete a message by moving it to Deleted Items. Permanent deletion requires the server to be started with --allow-permanent-delete"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(true),
+			mcp.WithString("message_id",
+				mcp.Description("The message ID (EntryID from Outlook)"),
+				mcp.Required(),
+			),
+			mcp.WithBoolean("permanent",
+				mcp.Description("Delete permanently instead of moving to Deleted Items (default: false)"),
+			),
+		),
+		mcp.NewTool("create_draft",
+			mcp.WithDescription("Compose a message and save it to the Drafts folder without sending it, so a person can review and send it from Outlook"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithArray("to",
+				mcp.Description("Recipient email addresses or names resolvable by Outlook"),
+				mcp.WithStringItems(),
+			),
+			mcp.WithArray("cc",
+				mcp.Description("CC recipients"),
+				mcp.WithStringItems(),
+			),
+			mcp.WithArray("bcc",
+				mcp.Description("BCC recipients"),
+				mcp.WithStringItems(),
+			),
+			mcp.WithString("subject",
+				mcp.Description("Message subject"),
+				mcp.Required(),
+			),
+			mcp.WithString("body",
+				mcp.Description("Message body"),
+			),
+			mcp.WithBoolean("html",
+				mcp.Description("Treat body as HTML instead of plain text (default: false)"),
+			),
+			mcp.WithArray("attachments",
+				mcp.Description("Paths of local files to attach; only files under the server's save roots can be attached, and none when it has no save roots"),
+				mcp.WithStringItems(),
+			),
+		),
+		mcp.NewTool("list_attachments",
+			mcp.WithDescription("List the attachments of a message with their index, file name and size, optionally with small thumbnails of image attachments so they can be seen without saving them"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("message_id",
+				mcp.Description("The message ID (EntryID from Outlook)"),
 				mcp.Required(),
 			),
+			mcp.WithBoolean("thumbnails",
+				mcp.Description("Also return a thumbnail of each image attachment (PNG, JPEG, GIF, BMP, WebP or TIFF, up to 10 images of 10 MB each)"),
+			),
+			mcp.WithNumber("thumbnail_size",
+				mcp.Description("Longest side of the thumbnails in pixels (default: 128, maximum: 512)"),
+			),
+		),
+		mcp.NewTool("save_attachment",
+			mcp.WithDescription("Save an attachment of a message to a local directory and return the saved file's path. Existing files are never overwritten"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithString("message_id",
+				mcp.Description("The message ID (EntryID from Outlook)"),
+				mcp.Required(),
+			),
+			mcp.WithNumber("index",
+				mcp.Description("Attachment index from list_attachments (default: 1)"),
+			),
+			mcp.WithString("directory",
+				mcp.Description("Directory to save into (default: the first save root when save roots are configured, otherwise an outlook-mcp-attachments folder in the temp directory)"),
+			),
+		),
+		mcp.NewTool("list_calendar_events",
+			mcp.WithDescription("List calendar events overlapping a date range from the default Outlook calendar, with recurring meetings expanded into individual occurrences"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("start",
+				mcp.Description("Start of the range as YYYY-MM-DD or an RFC 3339 timestamp (default: start of today)"),
+			),
+			mcp.WithString("end",
+				mcp.Description("End of the range as YYYY-MM-DD (inclusive) or an RFC 3339 timestamp (default: 7 days after start)"),
+			),
+			mcp.WithNumber("limit",
+				mcp.Description("Maximum number of events to return (default: 100)"),
+			),
+		),
+		mcp.NewTool("get_event",
+			mcp.WithDescription("Get full details of a calendar event: attendees, body, reminder and, for recurring events, the recurrence pattern of the series"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("event_id",
+				mcp.Description("The event ID from list_calendar_events (occurrences of a recurring series share the series ID)"),
+				mcp.Required(),
+			),
+		),
+		mcp.NewTool("search_contacts",
+			mcp.WithDescription("Search the Outlook Contacts folder by name, email address, company, department or job title, e.g. to resolve 'Bob from finance' to an email address"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("query",
+				mcp.Description("Text to match (case-insensitive substring); omit to list all contacts"),
+			),
+			mcp.WithNumber("limit",
+				mcp.Description("Maximum number of contacts to return (default: 25)"),
+			),
+		),
+		mcp.NewTool("get_contact",
+			mcp.WithDescription("Get full details of a contact, including all email addresses, phone numbers, addresses and notes"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("contact_id",
+				mcp.Description("The contact ID from search_contacts"),
+				mcp.Required(),
+			),
+		),
+		mcp.NewTool("resolve_recipient",
+			mcp.WithDescription("Resolve a name to an email address using the Outlook/Exchange address book, as Outlook's Check Names does, and list the members of a distribution list. Use before drafting mail to make sure it targets the right people"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("name",
+				mcp.Description("A display name, alias or email address"),
+				mcp.Required(),
+			),
+			mcp.WithBoolean("expand_lists",
+				mcp.Description("List the members of a distribution list, including nested lists (default: true)"),
+			),
+		),
+		mcp.NewTool("get_conversation",
+			mcp.WithDescription("Get every message in the email thread containing a message, across folders (including Sent Items), ordered oldest first with a preview of each body"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("message_id",
+				mcp.Description("The ID of any message in the thread"),
+				mcp.Required(),
+			),
+		),
+		mcp.NewTool("list_messages_since",
+			mcp.WithDescription("List only messages received after a point in time, newest first with pagination, so polling for new mail doesn't require paging through the whole folder"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("since",
+				mcp.Description("Relative duration back from now (e.g. '24h', '30m', '7d'), YYYY-MM-DD date or RFC 3339 timestamp"),
+				mcp.Required(),
+			),
+			mcp.WithNumber("page",
+				mcp.Description("Page number (default: 1)"),
+			),
+			mcp.WithString("folder",
+				mcp.Description("Folder to list: well-known name, folder path or folder ID (default: inbox)"),
+			),
+			mcp.WithString("account",
+				mcp.Description("Account or mailbox: email address or mailbox name from list_accounts, or a shared mailbox address (default: the default account)"),
+			),
+		),
+		mcp.NewTool("poll_new_messages",
+			mcp.WithDescription("Watch a folder for new messages matching a search. The first call (without cursor) starts watching from now or from since and returns a cursor; each later call with that cursor returns only messages that arrived after the previous call, oldest first, and a new cursor. With wait_seconds, the call keeps checking until a new message arrives, for 'tell me when the invoice arrives' workflows"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("cursor",
+				mcp.Description("Cursor returned by the previous poll_new_messages call; omit to start watching"),
+			),
+			mcp.WithString("since",
+				mcp.Description("Where a new watch starts, when there's no cursor: relative duration back from now (e.g. '24h', '30m'), YYYY-MM-DD date or RFC 3339 timestamp (default: now)"),
+			),
+			mcp.WithString("query",
+				mcp.Description("Only messages whose subject, body or sender matches this text"),
+			),
+			mcp.WithString("folder",
+				mcp.Description("Folder to watch: well-known name, folder path or folder ID (default: inbox)"),
+			),
+			mcp.WithString("account",
+				mcp.Description("Account or mailbox: email address or mailbox name from list_accounts, or a shared mailbox address (default: the default account)"),
+			),
+			mcp.WithString("from",
+				mcp.Description("Only messages whose sender name or address contains this text"),
+			),
+			mcp.WithString("to",
+				mcp.Description("Only messages whose To or Cc recipients contain this name"),
+			),
+			mcp.WithBoolean("has_attachments",
+				mcp.Description("Only messages with (true) or without (false) attachments"),
+			),
+			mcp.WithBoolean("unread",
+				mcp.Description("Only unread (true) or read (false) messages"),
+			),
+			mcp.WithString("importance",
+				mcp.Description("Only messages of this importance"),
+				mcp.Enum("low", "normal", "high"),
+			),
+			mcp.WithNumber("limit",
+				mcp.Description("Maximum messages returned; poll again with the new cursor for the rest (default: 25, maximum: 100)"),
+			),
+			mcp.WithNumber("wait_seconds",
+				mcp.Description("Keep checking every 15 seconds for up to this long while nothing new has arrived (default: 0, maximum: 300)"),
+			),
+		),
+		mcp.NewTool("get_inbox_analytics",
+			mcp.WithDescription("Summarize a folder's mail over a date range without paging through it: message volume by sender, sender domain and day, how long unread messages have been waiting, and the largest messages. Counting happens in the mail backend, up to the newest 10000 messages"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("start",
+				mcp.Description("Start of the range: relative duration back from now (e.g. '7d', '24h'), YYYY-MM-DD date or RFC 3339 timestamp (default: 30 days ago)"),
+			),
+			mcp.WithString("end",
+				mcp.Description("End of the range: YYYY-MM-DD date (inclusive) or RFC 3339 timestamp (default: now)"),
+			),
+			mcp.WithString("folder",
+				mcp.Description("Folder to analyze: well-known name, folder path or folder ID (default: inbox)"),
+			),
+			mcp.WithString("account",
+				mcp.Description("Account or mailbox: email address or mailbox name from list_accounts, or a shared mailbox address (default: the default account)"),
+			),
+			mcp.WithNumber("top",
+				mcp.Description("Senders, domains and largest messages to list (default: 10, maximum: 50)"),
+			),
+		),
+		mcp.NewTool("list_rules_and_categories",
+			mcp.WithDescription("List the configured Outlook mail rules (conditions and actions, in execution order) and the master category list, to see what inbox automation already exists before proposing more"),
+			mcp.WithReadOnlyHintAnnotation(true),
+		),
+		mcp.NewTool("list_accounts",
+			mcp.WithDescription("List the email accounts and mailboxes (including shared mailboxes and archives) open in the Outlook profile; use their names with the account parameter of the mail tools"),
+			mcp.WithReadOnlyHintAnnotation(true),
+		),
+		mcp.NewTool("health_check",
+			mcp.WithDescription("Report whether the PowerShell server is running, whether its Outlook COM connection responds, and the supervisor's restart and recent error history; use it to diagnose failing Outlook tools"),
+			mcp.WithReadOnlyHintAnnotation(true),
+		),
+		mcp.NewTool("export_message",
+			mcp.WithDescription("Export a message as an Outlook .msg file or a standard .eml (MIME) file, including attachments. Saves to a path when one is given, otherwise returns the file content base64-encoded. Existing files are never overwritten"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithString("message_id",
+				mcp.Description("The message ID (EntryID from Outlook)"),
+				mcp.Required(),
+			),
+			mcp.WithString("format",
+				mcp.Description("File format: msg (Outlook) or eml (MIME, readable by most mail tools) (default: msg)"),
+				mcp.Enum("msg", "eml"),
+			),
+			mcp.WithString("path",
+				mcp.Description("File path to write, or an existing directory to save into under the message subject. Omit to return the content base64-encoded (up to 10 MB)"),
+			),
+		),
+		mcp.NewTool("export_messages",
+			mcp.WithDescription("Export the metadata of every message matching a search to a CSV file, newest first: ID, received and sent times, sender, subject, size, read state, importance, attachments, flag and categories. For reports too large to return inline. Existing files are never overwritten"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithString("path",
+				mcp.Description("CSV file path to create, or an existing directory to save a timestamped file into"),
+				mcp.Required(),
+			),
+			mcp.WithString("query",
+				mcp.Description("Search query to match against subject, body, or sender (optional when filters are given)"),
+			),
+			mcp.WithString("folder",
+				mcp.Description("Folder to search: well-known name, folder path or folder ID (default: inbox)"),
+			),
+			mcp.WithString("account",
+				mcp.Description("Account or mailbox: email address or mailbox name from list_accounts, or a shared mailbox address (default: the default account)"),
+			),
+			mcp.WithString("from",
+				mcp.Description("Only messages whose sender name or address contains this text"),
+			),
+			mcp.WithString("to",
+				mcp.Description("Only messages whose To or Cc recipients contain this name"),
+			),
+			mcp.WithString("after",
+				mcp.Description("Only messages received on or after this date (YYYY-MM-DD or RFC 3339)"),
+			),
+			mcp.WithString("before",
+				mcp.Description("Only messages received before this date; a YYYY-MM-DD date includes that whole day"),
+			),
+			mcp.WithBoolean("has_attachments",
+				mcp.Description("Only messages with (true) or without (false) attachments"),
+			),
+			mcp.WithBoolean("unread",
+				mcp.Description("Only unread (true) or read (false) messages"),
+			),
+			mcp.WithString("importance",
+				mcp.Description("Only messages of this importance"),
+				mcp.Enum("low", "normal", "high"),
+			),
+			mcp.WithNumber("limit",
+				mcp.Description("Maximum number of messages to export (default: 1000, maximum: 10000)"),
+			),
+		),
+		mcp.NewTool("get_automatic_replies",
+			mcp.WithDescription("Report whether automatic replies (out of office) are turned on for the mailbox. The reply text and schedule are not exposed by Outlook's object model"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("account",
+				mcp.Description("Account or store name from list_accounts (default: the default mailbox)"),
+			),
+		),
+		mcp.NewTool("get_free_busy",
+			mcp.WithDescription("Look up free/busy for one or more attendees over a time range, listing each attendee's busy blocks and the windows in which everyone is free, e.g. to find a meeting time"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("attendees",
+				mcp.Description("Comma- or semicolon-separated email addresses or names to look up (at most 20)"),
+				mcp.Required(),
+			),
+			mcp.WithString("start",
+				mcp.Description("Start of the range as YYYY-MM-DD or an RFC 3339 timestamp (default: start of today)"),
+			),
+			mcp.WithString("end",
+				mcp.Description("End of the range as YYYY-MM-DD (inclusive) or an RFC 3339 timestamp (default: 7 days after start; at most 31 days)"),
+			),
+			mcp.WithNumber("interval",
+				mcp.Description("Slot length in minutes (default: 30)"),
+			),
+			mcp.WithNumber("min_duration",
+				mcp.Description("Shortest common free window to report, in minutes (default: 30)"),
+			),
+		),
+		mcp.NewTool("list_tasks",
+			mcp.WithDescription("List the Outlook To-Do List: tasks and messages flagged for follow-up, soonest due first"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithString("status",
+				mcp.Description("Which tasks to list (default: open)"),
+				mcp.Enum("open", "completed", "all"),
+			),
+			mcp.WithNumber("limit",
+				mcp.Description("Maximum number of tasks to return (default: 50)"),
+			),
+		),
+		mcp.NewTool("create_task",
+			mcp.WithDescription("Create an Outlook task, or turn a message into a task by flagging it for follow-up when message_id is given"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithString("subject",
+				mcp.Description("Task subject (required unless message_id is given; for a message it overrides the task title)"),
+			),
+			mcp.WithString("body",
+				mcp.Description("Task notes (new tasks only)"),
+			),
+			mcp.WithString("due_date",
+				mcp.Description("Due date as YYYY-MM-DD"),
+			),
+			mcp.WithString("start_date",
+				mcp.Description("Start date as YYYY-MM-DD"),
+			),
+			mcp.WithString("importance",
+				mcp.Description("Task importance (default: normal)"),
+				mcp.Enum("low", "normal", "high"),
+			),
+			mcp.WithArray("categories",
+				mcp.Description("Categories to assign"),
+				mcp.WithStringItems(),
+			),
+			mcp.WithString("message_id",
+				mcp.Description("Message ID (EntryID) to flag for follow-up instead of creating a new task"),
+			),
+		),
+		mcp.NewTool("complete_task",
+			mcp.WithDescription("Mark a task or flagged message from list_tasks as complete"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithIdempotentHintAnnotation(true),
+			mcp.WithString("task_id",
+				mcp.Description("The task ID from list_tasks or create_task"),
+				mcp.Required(),
+			),
+		),
+		mcp.NewTool("get_server_logs",
+			mcp.WithDescription("Show recent output of the PowerShell server (startup messages, request log, COM errors) and supervisor events such as crashes and restarts; use it with health_check to debug errors like 'Outlook is not available'"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithNumber("lines",
+				mcp.Description("Number of most recent matching lines to return (default: 100)"),
+			),
+			mcp.WithString("stream",
+				mcp.Description("Only show one stream: stdout (request log and status), stderr (errors) or supervisor (process exits and restarts) (default: all)"),
+				mcp.Enum("all", "stdout", "stderr", "supervisor"),
+			),
+			mcp.WithString("contains",
+				mcp.Description("Only show lines containing this text (case-insensitive)"),
+			),
+		),
+		mcp.NewTool("reconnect",
+			mcp.WithDescription("Re-bind the PowerShell server to Outlook without restarting it, e.g. after Outlook was closed and reopened and tools report 'Outlook is not available'. The server also retries on its own every few seconds. With the Graph backend, refreshes the access token"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithIdempotentHintAnnotation(true),
+		),
+		mcp.NewTool("bulk_update_messages",
+			mcp.WithDescription("Apply one action (mark read/unread, move, categorize or delete) to many messages at once, selected by message_ids or by a search query and filters as in search_messages. Use dry_run first to see which messages would be affected"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(true),
+			mcp.WithString("action",
+				mcp.Description("What to do with each message"),
+				mcp.Enum(BulkActionMarkRead, BulkActionMarkUnread, BulkActionMove, BulkActionCategorize, BulkActionDelete),
+				mcp.Required(),
+			),
+			mcp.WithArray("message_ids",
+				mcp.Description("Message IDs to update (instead of a search)"),
+				mcp.WithStringItems(),
+			),
+			mcp.WithString("query",
+				mcp.Description("Search text selecting the messages, as in search_messages"),
+			),
+			mcp.WithString("folder",
+				mcp.Description("Folder to search (default: Inbox)"),
+			),
+			mcp.WithString("account",
+				mcp.Description("Account to search and move within (default: the default account)"),
+			),
+			mcp.WithString("from",
+				mcp.Description("Only messages whose sender name or address contains this text"),
+			),
+			mcp.WithString("to",
+				mcp.Description("Only messages whose To or Cc recipients contain this text"),
+			),
+			mcp.WithString("after",
+				mcp.Description("Only messages received on or after this date (YYYY-MM-DD or RFC 3339)"),
+			),
+			mcp.WithString("before",
+				mcp.Description("Only messages received before this date (YYYY-MM-DD includes that day, or RFC 3339)"),
+			),
+			mcp.WithBoolean("has_attachments",
+				mcp.Description("Only messages with (true) or without (false) attachments"),
+			),
+			mcp.WithBoolean("unread",
+				mcp.Description("Only unread (true) or read (false) messages"),
+			),
+			mcp.WithString("importance",
+				mcp.Description("Only messages of this importance"),
+				mcp.Enum(ImportanceLow, ImportanceNormal, ImportanceHigh),
+			),
+			mcp.WithString("destination",
+				mcp.Description("Target folder for move"),
+			),
+			mcp.WithArray("categories",
+				mcp.Description("Categories to add to each message for categorize"),
+				mcp.WithStringItems(),
+			),
+			mcp.WithBoolean("permanent",
+				mcp.Description("Delete permanently instead of moving to Deleted Items; requires --allow-permanent-delete (default: false)"),
+			),
+			mcp.WithNumber("limit",
+				mcp.Description("Maximum number of messages a search may select (default: 50, max: 500)"),
+			),
+			mcp.WithBoolean("dry_run",
+				mcp.Description("List the messages that would be affected without changing them (default: false)"),
+			),
+		),
+		mcp.NewTool("get_profile",
+			mcp.WithDescription("Get the current user's display name, primary email address and saved Outlook signatures (marking the defaults for new messages and replies), so drafts and replies can be signed consistently. Signatures are only available with the COM backend"),
+			mcp.WithReadOnlyHintAnnotation(true),
+			mcp.WithBoolean("include_html",
+				mcp.Description("Also return the HTML version of each signature (default: false)"),
+			),
+		),
+	}
+}
+
+// GetSendToolDefinitions returns the tools that send mail on the user's behalf.
+// They are only registered when outlook-mcp is started with --allow-send.
+func GetSendToolDefinitions() []mcp.Tool {
+	return []mcp.Tool{
+		mcp.NewTool("send_message",
+			mcp.WithDescription("Compose and send a new email from the default Outlook account"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithOpenWorldHintAnnotation(true),
+			mcp.WithArray("to",
+				mcp.Description("Recipient email addresses or names resolvable by Outlook"),
+				mcp.WithStringItems(),
+				mcp.Required(),
+			),
+			mcp.WithArray("cc",
+				mcp.Description("CC recipients"),
+				mcp.WithStringItems(),
+			),
+			mcp.WithArray("bcc",
+				mcp.Description("BCC recipients"),
+				mcp.WithStringItems(),
+			),
+			mcp.WithString("subject",
+				mcp.Description("Message subject"),
+				mcp.Required(),
+			),
+			mcp.WithString("body",
+				mcp.Description("Message body"),
+			),
+			mcp.WithBoolean("html",
+				mcp.Description("Treat body as HTML instead of plain text (default: false)"),
+			),
+		),
+		mcp.NewTool("reply_to_message",
+			mcp.WithDescription("Reply to an existing message; the original is quoted below the reply text"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithOpenWorldHintAnnotation(true),
+			mcp.WithString("message_id",
+				mcp.Description("The message ID (EntryID from Outlook)"),
+				mcp.Required(),
+			),
+			mcp.WithString("body",
+				mcp.Description("Reply text"),
+				mcp.Required(),
+			),
+			mcp.WithBoolean("reply_all",
+				mcp.Description("Reply to all recipients instead of only the sender (default: false)"),
+			),
+		),
+		mcp.NewTool("forward_message",
+			mcp.WithDescription("Forward an existing message, including its attachments, to new recipients"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithOpenWorldHintAnnotation(true),
+			mcp.WithString("message_id",
+				mcp.Description("The message ID (EntryID from Outlook)"),
+				mcp.Required(),
+			),
+			mcp.WithArray("to",
+				mcp.Description("Recipient email addresses or names resolvable by Outlook"),
+				mcp.WithStringItems(),
+				mcp.Required(),
+			),
+			mcp.WithArray("cc",
+				mcp.Description("CC recipients"),
+				mcp.WithStringItems(),
+			),
+			mcp.WithArray("bcc",
+				mcp.Description("BCC recipients"),
+				mcp.WithStringItems(),
+			),
+			mcp.WithString("body",
+				mcp.Description("Text to add above the forwarded message"),
+			),
 		),
 	}
 }
@@ -0,0 +1,125 @@
+package outlook
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limits on export_messages
+const (
+	defaultExportLimit = 1000
+	maxExportMessages  = 10_000
+)
+
+// exportColumns is the header row of an exported CSV file
+var exportColumns = []string{
+	"id", "received", "sent", "sender_name", "sender_email", "subject", "size",
+	"unread", "importance", "has_attachments", "attachment_count", "flag_status", "categories",
+}
+
+// ExportMessages writes the metadata of the messages matching a search to a
+// CSV file, newest first. The file is created only once every page has been
+// fetched, so a failed search leaves nothing behind.
+func ExportMessages(manager Backend, request ExportRequest) (*ExportResult, error) {
+	if request.Path == "" {
+		return nil, fmt.Errorf("path is required")
+	}
+	if request.Limit < 0 || request.Limit > maxExportMessages {
+		return nil, fmt.Errorf("limit must be between 1 and %d", maxExportMessages)
+	}
+	if request.Limit == 0 {
+		request.Limit = defaultExportLimit
+	}
+	if err := validateSearch(request.Filters, 0); err != nil {
+		return nil, err
+	}
+
+	result := &ExportResult{}
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Write(exportColumns)
+	for page := 1; result.Messages < request.Limit; page++ {
+		response, err := manager.SearchMessages(request.Query, request.Folder, request.Account, request.Filters, page, maxSearchPageSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search messages: %w", err)
+		}
+		for _, message := range response.Results {
+			if result.Messages == request.Limit {
+				result.Truncated = true
+				break
+			}
+			writer.Write(exportRow(message))
+			result.Messages++
+		}
+		if response.Truncated {
+			result.Truncated = true
+		}
+		if !response.Pagination.HasNext {
+			break
+		}
+		if result.Messages == request.Limit {
+			result.Truncated = true
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("failed to write CSV: %w", err)
+	}
+
+	directory, file, err := resolveExportTarget(request.Path)
+	if err != nil {
+		return nil, err
+	}
+	if directory != "" {
+		name := "messages-" + time.Now().Format("20060102-150405") + ".csv"
+		file, err = writeUniqueFile(directory, name, "messages.csv", buf.Bytes())
+	} else {
+		err = writeNewFile(file, buf.Bytes())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result.Path = file
+	result.FileName = filepath.Base(file)
+	result.Size = int64(buf.Len())
+	return result, nil
+}
+
+// exportRow returns the CSV fields of a message, in exportColumns order
+func exportRow(message Message) []string {
+	sent := ""
+	if message.SentOn != nil {
+		sent = message.SentOn.Format(time.RFC3339)
+	}
+	return []string{
+		message.ID,
+		message.ReceivedTime.Format(time.RFC3339),
+		sent,
+		csvText(message.Sender),
+		csvText(message.SenderEmail),
+		csvText(message.Subject),
+		strconv.Itoa(message.Size),
+		strconv.FormatBool(message.Unread),
+		strings.ToLower(getImportanceString(message.Importance)),
+		strconv.FormatBool(message.HasAttachments),
+		strconv.Itoa(message.AttachmentCount),
+		message.FlagStatus,
+		csvText(strings.Join(message.Categories, "; ")),
+	}
+}
+
+// csvText guards text taken from a message against being run as a formula
+// when the file is opened in a spreadsheet, by prefixing a quote to values
+// that start with a formula character
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
@@ -0,0 +1,86 @@
+package outlook
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportMessages(t *testing.T) {
+	var pages []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/search" {
+			t.Errorf("Unexpected request %s", r.URL.Path)
+		}
+		page := r.URL.Query().Get("page")
+		pages = append(pages, page)
+		w.Header().Set("Content-Type", "application/json")
+		if page == "1" {
+			var results []string
+			for i := range 100 {
+				results = append(results, fmt.Sprintf(`{"id": "m%d", "subject": "Invoice %d", "sender": "Billing", "senderEmail": "billing@example.com", "receivedTime": "2026-03-10T09:00:00Z", "size": 100}`, i, i))
+			}
+			w.Write([]byte(`{"query": "invoice", "results": [` + strings.Join(results, ",") + `], "count": 100, "pagination": {"page": 1, "pageSize": 100, "hasNext": true}}`))
+			return
+		}
+		w.Write([]byte(`{"query": "invoice", "results": [
+			{"id": "last", "subject": "=HYPERLINK(\"http://x\")", "sender": "Ana, Silva", "senderEmail": "ana@example.org", "receivedTime": "2026-03-01T08:30:00Z",
+			 "sentOn": "2026-03-01T08:29:00Z", "size": 2048, "unread": true, "importance": 2, "hasAttachments": true, "attachmentCount": 2, "flagStatus": "flagged", "categories": ["Red", "Audit"]}
+		], "count": 1, "pagination": {"page": 2, "pageSize": 100, "hasNext": false}}`))
+	}))
+	defer server.Close()
+	manager := &Manager{baseURL: server.URL, client: &http.Client{Timeout: 5 * time.Second}}
+	dir := t.TempDir()
+
+	// Every page is exported to the named file
+	file := filepath.Join(dir, "reports", "invoices.csv")
+	result, err := ExportMessages(manager, ExportRequest{Query: "invoice", Path: file})
+	if err != nil {
+		t.Fatalf("ExportMessages failed: %v", err)
+	}
+	if result.Messages != 101 || result.Truncated || result.Path != file || strings.Join(pages, ",") != "1,2" {
+		t.Errorf("Unexpected result %+v after pages %v", result, pages)
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(f).ReadAll()
+	f.Close()
+	if err != nil {
+		t.Fatalf("Export is not valid CSV: %v", err)
+	}
+	if len(rows) != 102 || strings.Join(rows[0], ",") != strings.Join(exportColumns, ",") {
+		t.Fatalf("Unexpected rows: %d, header %v", len(rows), rows[0])
+	}
+	want := []string{"last", "2026-03-01T08:30:00Z", "2026-03-01T08:29:00Z", "Ana, Silva", "ana@example.org", `'=HYPERLINK("http://x")`,
+		"2048", "true", "high", "true", "2", "flagged", "Red; Audit"}
+	if strings.Join(rows[101], "|") != strings.Join(want, "|") {
+		t.Errorf("Unexpected row:\n%q\nwant:\n%q", rows[101], want)
+	}
+
+	// Existing files are never overwritten
+	if _, err := ExportMessages(manager, ExportRequest{Query: "invoice", Path: file}); err == nil {
+		t.Error("Expected an error for an existing file")
+	}
+
+	// A directory gets a timestamped file; the limit stops paging
+	pages = nil
+	result, err = ExportMessages(manager, ExportRequest{Query: "invoice", Path: dir, Limit: 100})
+	if err != nil {
+		t.Fatalf("ExportMessages failed: %v", err)
+	}
+	if result.Messages != 100 || !result.Truncated || filepath.Dir(result.Path) != dir || !strings.HasPrefix(result.FileName, "messages-") || len(pages) != 1 {
+		t.Errorf("Unexpected result %+v after pages %v", result, pages)
+	}
+
+	if _, err := ExportMessages(manager, ExportRequest{Query: "invoice", Path: file, Limit: maxExportMessages + 1}); err == nil {
+		t.Error("Expected an error for an oversized limit")
+	}
+}
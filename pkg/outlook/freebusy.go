@@ -0,0 +1,94 @@
+package outlook
+
+import "time"
+
+// freeBusyStatus maps a character of Recipient.FreeBusy's complete format to a BusyStatus name
+func freeBusyStatus(slot byte) string {
+	switch slot {
+	case '0':
+		return BusyStatusFree
+	case '1':
+		return BusyStatusTentative
+	case '2':
+		return BusyStatusBusy
+	case '3':
+		return BusyStatusOutOfOffice
+	case '4':
+		return BusyStatusWorkingElsewhere
+	default:
+		return "unknown"
+	}
+}
+
+// slotTime returns the start of slot index i, clipped to the end of the range
+func (r *FreeBusyResponse) slotTime(i int) time.Time {
+	t := r.Start.Add(time.Duration(i*r.Interval) * time.Minute)
+	if t.After(r.End) {
+		return r.End
+	}
+	return t
+}
+
+// Blocks merges an attendee's slots into runs of the same status
+func (r *FreeBusyResponse) Blocks(attendee *AttendeeFreeBusy) []FreeBusyBlock {
+	var blocks []FreeBusyBlock
+	for i := 0; i < len(attendee.Slots); {
+		j := i
+		for j < len(attendee.Slots) && attendee.Slots[j] == attendee.Slots[i] {
+			j++
+		}
+		blocks = append(blocks, FreeBusyBlock{
+			Start:  r.slotTime(i),
+			End:    r.slotTime(j),
+			Status: freeBusyStatus(attendee.Slots[i]),
+		})
+		i = j
+	}
+	return blocks
+}
+
+// CommonFreeWindows returns the windows of at least minDuration in which every
+// attendee with free/busy data is free. Attendees that could not be resolved or
+// looked up are ignored; with no usable attendee there are no windows.
+func (r *FreeBusyResponse) CommonFreeWindows(minDuration time.Duration) []FreeBusyBlock {
+	var usable []string
+	slotCount := 0
+	for _, attendee := range r.Attendees {
+		if !attendee.Resolved || attendee.Error != "" {
+			continue
+		}
+		usable = append(usable, attendee.Slots)
+		slotCount = max(slotCount, len(attendee.Slots))
+	}
+	if len(usable) == 0 {
+		return nil
+	}
+
+	free := func(i int) bool {
+		for _, slots := range usable {
+			// A slot missing from the data is unknown, not free
+			if i >= len(slots) || slots[i] != '0' {
+				return false
+			}
+		}
+		return true
+	}
+
+	var windows []FreeBusyBlock
+	for i := 0; i < slotCount; {
+		if !free(i) {
+			i++
+			continue
+		}
+		j := i
+		for j < slotCount && free(j) {
+			j++
+		}
+		window := FreeBusyBlock{Start: r.slotTime(i), End: r.slotTime(j), Status: BusyStatusFree}
+		if window.End.Sub(window.Start) >= minDuration {
+			windows = append(windows, window)
+		}
+		i = j
+	}
+	return windows
+}
@@ -0,0 +1,126 @@
+package outlook
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFreeBusyBlocks(t *testing.T) {
+	start := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	response := &FreeBusyResponse{Start: start, End: start.Add(150 * time.Minute), Interval: 30}
+	attendee := &AttendeeFreeBusy{Resolved: true, Slots: "00221"}
+
+	blocks := response.Blocks(attendee)
+	if len(blocks) != 3 {
+		t.Fatalf("Expected 3 blocks, got %d: %+v", len(blocks), blocks)
+	}
+	if blocks[1].Status != BusyStatusBusy || !blocks[1].Start.Equal(start.Add(time.Hour)) || !blocks[1].End.Equal(start.Add(2*time.Hour)) {
+		t.Errorf("Unexpected busy block: %+v", blocks[1])
+	}
+	if blocks[2].Status != BusyStatusTentative || !blocks[2].End.Equal(response.End) {
+		t.Errorf("Unexpected tentative block: %+v", blocks[2])
+	}
+}
+
+func TestCommonFreeWindows(t *testing.T) {
+	start := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	response := &FreeBusyResponse{
+		Start:    start,
+		End:      start.Add(4 * time.Hour),
+		Interval: 30,
+		Attendees: []AttendeeFreeBusy{
+			{Attendee: "a", Resolved: true, Slots: "00020000"},
+			{Attendee: "b", Resolved: true, Slots: "00000100"},
+			{Attendee: "c", Resolved: false, Error: "Could not resolve attendee"},
+			{Attendee: "d", Resolved: true, Error: "Free/busy information is not available"},
+		},
+	}
+
+	windows := response.CommonFreeWindows(30 * time.Minute)
+	if len(windows) != 3 {
+		t.Fatalf("Expected 3 windows, got %d: %+v", len(windows), windows)
+	}
+	if !windows[0].Start.Equal(start) || windows[0].End.Sub(windows[0].Start) != 90*time.Minute {
+		t.Errorf("Unexpected first window: %+v", windows[0])
+	}
+
+	// Longer minimum drops the 30-minute gap between the two busy slots
+	if windows := response.CommonFreeWindows(time.Hour); len(windows) != 2 {
+		t.Errorf("Expected 2 windows of at least an hour, got %+v", windows)
+	}
+
+	empty := &FreeBusyResponse{Start: start, End: start.Add(time.Hour), Interval: 30,
+		Attendees: []AttendeeFreeBusy{{Attendee: "c", Resolved: false}}}
+	if windows := empty.CommonFreeWindows(30 * time.Minute); windows != nil {
+		t.Errorf("Expected no windows without usable attendees, got %+v", windows)
+	}
+}
+
+func TestManagerFreeBusyAndAutomaticReplies(t *testing.T) {
+	var lastQuery map[string][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastQuery = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/oof":
+			json.NewEncoder(w).Encode(AutomaticReplyStatus{Store: "Mailbox", Enabled: true})
+		case "/freebusy":
+			json.NewEncoder(w).Encode(FreeBusyResponse{Interval: 15})
+		}
+	}))
+	defer server.Close()
+
+	manager := &Manager{baseURL: server.URL, client: &http.Client{Timeout: 5 * time.Second}}
+
+	status, err := manager.GetAutomaticReplies("Support")
+	if err != nil {
+		t.Fatalf("GetAutomaticReplies failed: %v", err)
+	}
+	if !status.Enabled || lastQuery["account"][0] != "Support" {
+		t.Errorf("Unexpected status %+v for query %v", status, lastQuery)
+	}
+
+	start := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	if _, err := manager.GetFreeBusy([]string{"a@example.com", "Bob"}, start, start.Add(8*time.Hour), 15); err != nil {
+		t.Fatalf("GetFreeBusy failed: %v", err)
+	}
+	if lastQuery["attendees"][0] != "a@example.com;Bob" || lastQuery["interval"][0] != "15" || lastQuery["start"][0] != "2026-10-15T09:00:00Z" {
+		t.Errorf("Unexpected free/busy query: %v", lastQuery)
+	}
+
+	if _, err := manager.GetFreeBusy(nil, start, start.Add(time.Hour), 0); err == nil {
+		t.Error("Expected error without attendees")
+	}
+	if _, err := manager.GetFreeBusy([]string{"a"}, start, start.AddDate(0, 0, 40), 0); err == nil {
+		t.Error("Expected error for a range over 31 days")
+	}
+}
+
+func TestFormatFreeBusy(t *testing.T) {
+	start := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	response := &FreeBusyResponse{
+		Start:    start,
+		End:      start.Add(2 * time.Hour),
+		Interval: 30,
+		Attendees: []AttendeeFreeBusy{
+			{Attendee: "alice@example.com", Resolved: true, Name: "Alice", Email: "alice@example.com", Slots: "0200"},
+			{Attendee: "nobody", Resolved: false, Error: "Could not resolve attendee"},
+		},
+	}
+
+	output := formatFreeBusy(response, 30*time.Minute)
+	for _, expected := range []string{
+		"Alice <alice@example.com>:",
+		"Thu 2026-10-15 09:30 - 10:00  busy",
+		"nobody: Could not resolve attendee",
+		"Thu 2026-10-15 10:00 - 11:00 (1h0m0s)",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, output)
+		}
+	}
+}
@@ -0,0 +1,2414 @@
+package outlook
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kevsmith/my-mcp/pkg/shared"
+)
+
+// graphBaseURL is the Microsoft Graph v1.0 endpoint
+const graphBaseURL = "https://graph.microsoft.com/v1.0"
+
+// graphAuthority is the Microsoft identity platform that issues Graph tokens
+const graphAuthority = "https://login.microsoftonline.com"
+
+// Graph backend limits. Search cannot be combined with $skip, so results are
+// collected up to graphSearchLimit and paged locally; simple file attachments
+// are limited by Graph to 3 MB, larger ones need an upload session.
+const (
+	graphMessagePageSize    = 10
+	graphSearchLimit        = 250
+	graphMaxAttachmentBytes = 3 * 1024 * 1024
+	graphMaxInlineExport    = 10 * 1024 * 1024
+	graphMaxAttachmentData  = 10 * 1024 * 1024 // Attachment content returned in memory
+	graphMaxThrottleRetries = 3
+	graphMaxRetryAfter      = 30 * time.Second
+)
+
+// graphMessageSizeProperty is the MAPI PR_MESSAGE_SIZE property, which Graph
+// only exposes as an extended property
+const graphMessageSizeProperty = "Integer 0x0E08"
+
+// graphMessageSelect lists the message fields converted into Message
+const graphMessageSelect = "id,subject,from,receivedDateTime,sentDateTime,isRead,importance,hasAttachments,bodyPreview,flag,categories,conversationId"
+
+// graphWellKnownFolders maps the folder names accepted by the COM backend to
+// Graph's well-known folder names
+var graphWellKnownFolders = map[string]string{
+	"inbox":         "inbox",
+	"sent":          "sentitems",
+	"sent items":    "sentitems",
+	"drafts":        "drafts",
+	"deleted":       "deleteditems",
+	"deleted items": "deleteditems",
+	"junk":          "junkemail",
+	"junk email":    "junkemail",
+	"archive":       "archive",
+	"outbox":        "outbox",
+}
+
+// graphCategoryColors are the category color names in Outlook's order; Graph
+// reports them as preset0 (red) to preset24
+var graphCategoryColors = []string{
+	"none", "red", "orange", "peach", "yellow", "green", "teal", "olive", "blue", "purple",
+	"maroon", "steel", "dark steel", "gray", "dark gray", "black", "dark red", "dark orange",
+	"dark peach", "dark yellow", "dark green", "dark teal", "dark olive", "dark blue",
+	"dark purple", "dark maroon",
+}
+
+// GraphManager implements Backend on top of the Microsoft Graph REST API, for
+// cloud mailboxes on any platform. It signs in as the user with the device
+// code flow. Message IDs of shared mailboxes carry the mailbox address as an
+// "address::id" prefix, since Graph IDs are only valid within their mailbox.
+type GraphManager struct {
+	auth       *graphAuth
+	baseURL    string
+	client     *http.Client
+	allowSend  bool
+	allowPurge bool
+	health     healthTracker
+}
+
+// NewGraphManager creates a Graph backend for the app registration in
+// OUTLOOK_GRAPH_CLIENT_ID and signs in, prompting on stderr when no cached
+// token can be used
+func NewGraphManager() (*GraphManager, error) {
+	clientID := os.Getenv("OUTLOOK_GRAPH_CLIENT_ID")
+	if clientID == "" {
+		return nil, fmt.Errorf("OUTLOOK_GRAPH_CLIENT_ID must be set to the application ID of an app registration with public client flows enabled")
+	}
+	tenant := os.Getenv("OUTLOOK_GRAPH_TENANT")
+	if tenant == "" {
+		tenant = "common"
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	g := &GraphManager{
+		auth: &graphAuth{
+			clientID:  clientID,
+			tenant:    tenant,
+			authority: graphAuthority,
+			cachePath: defaultGraphTokenCache(),
+			client:    client,
+			prompt:    os.Stderr,
+		},
+		baseURL:    graphBaseURL,
+		client:     client,
+		allowSend:  boolFromEnv("OUTLOOK_ALLOW_SEND"),
+		allowPurge: boolFromEnv("OUTLOOK_ALLOW_PERMANENT_DELETE"),
+	}
+	g.health.startedAt = time.Now()
+
+	// Sign in now so the device code prompt appears at startup rather than
+	// stalling the first tool call
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Minute)
+	defer cancel()
+	if _, err := g.auth.accessToken(ctx); err != nil {
+		return nil, fmt.Errorf("failed to sign in to Microsoft Graph: %w", err)
+	}
+
+	return g, nil
+}
+
+// Stop releases the backend; Graph holds no process or connection to close
+func (g *GraphManager) Stop() error {
+	return nil
+}
+
+// SendEnabled reports whether the backend was started with sending mail allowed
+func (g *GraphManager) SendEnabled() bool {
+	return g.allowSend
+}
+
+// PermanentDeleteEnabled reports whether the backend was started with permanent deletion allowed
+func (g *GraphManager) PermanentDeleteEnabled() bool {
+	return g.allowPurge
+}
+
+// graphError is the error body returned by Graph
+type graphError struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// do sends a request to Graph, encoding payload as JSON when it is non-nil.
+// Endpoints are relative to the API root, or absolute @odata.nextLink URLs.
+// Throttled requests are retried after the interval Graph asks for; Graph
+// rejects them before acting, so this is safe for every method.
+func (g *GraphManager) do(method, endpoint string, payload interface{}, prefer string) ([]byte, error) {
+	var data []byte
+	if payload != nil {
+		var err error
+		data, err = json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+
+	target := endpoint
+	if !strings.HasPrefix(endpoint, "https://") && !strings.HasPrefix(endpoint, "http://") {
+		target = g.baseURL + endpoint
+	}
+
+	for attempt := 0; ; attempt++ {
+		body, retryAfter, err := g.doOnce(method, target, data, prefer)
+		if retryAfter == 0 || attempt >= graphMaxThrottleRetries {
+			return body, err
+		}
+		time.Sleep(retryAfter)
+	}
+}
+
+// doOnce makes a single Graph request, returning how long to wait when throttled
+func (g *GraphManager) doOnce(method, target string, data []byte, prefer string) ([]byte, time.Duration, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	token, err := g.auth.accessToken(ctx)
+	if err != nil {
+		err = fmt.Errorf("failed to get Microsoft Graph token: %w", err)
+		g.health.recordRequestError(err)
+		return nil, 0, err
+	}
+
+	var reqBody io.Reader
+	if data != nil {
+		reqBody = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reqBody)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if data != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if prefer != "" {
+		req.Header.Set("Prefer", prefer)
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		err = fmt.Errorf("%w: %w", errTransport, err)
+		g.health.recordRequestError(err)
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return body, 0, nil
+	}
+
+	var graphErr graphError
+	if json.Unmarshal(body, &graphErr) == nil && graphErr.Error.Message != "" {
+		err = shared.NewError(shared.CodeForHTTPStatus(resp.StatusCode), "graph error (%d): %s", resp.StatusCode, graphErr.Error.Message)
+	} else {
+		err = shared.NewError(shared.CodeForHTTPStatus(resp.StatusCode), "graph error (%d): %s", resp.StatusCode, string(body))
+	}
+	if resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests {
+		g.health.recordRequestError(err)
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		retryAfter := 2 * time.Second
+		if seconds, convErr := strconv.Atoi(resp.Header.Get("Retry-After")); convErr == nil && seconds > 0 {
+			retryAfter = min(time.Duration(seconds)*time.Second, graphMaxRetryAfter)
+		}
+		return nil, retryAfter, err
+	}
+	return nil, 0, err
+}
+
+// getJSON makes a GET request and decodes the response into out
+func (g *GraphManager) getJSON(endpoint, prefer string, out interface{}) error {
+	body, err := g.do(http.MethodGet, endpoint, nil, prefer)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// sendJSON makes a request with a JSON payload and decodes the response into
+// out unless out is nil
+func (g *GraphManager) sendJSON(method, endpoint string, payload, out interface{}) error {
+	body, err := g.do(method, endpoint, payload, "")
+	if err != nil {
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// graphPage is one page of a Graph collection
+type graphPage[T any] struct {
+	Value    []T    `json:"value"`
+	Count    *int   `json:"@odata.count"`
+	NextLink string `json:"@odata.nextLink"`
+}
+
+// graphList collects a Graph collection by following @odata.nextLink, stopping
+// after limit items (0 for all). It reports whether items were left over.
+func graphList[T any](g *GraphManager, endpoint, prefer string, limit int) ([]T, bool, error) {
+	var items []T
+	for endpoint != "" {
+		var page graphPage[T]
+		if err := g.getJSON(endpoint, prefer, &page); err != nil {
+			return nil, false, err
+		}
+		items = append(items, page.Value...)
+		if limit > 0 && len(items) >= limit {
+			truncated := len(items) > limit || page.NextLink != ""
+			return items[:limit], truncated, nil
+		}
+		endpoint = page.NextLink
+	}
+	return items, false, nil
+}
+
+// mailboxPath returns the API path of the signed-in user's mailbox, or of
+// another mailbox the user has been granted access to
+func mailboxPath(account string) string {
+	if account == "" {
+		return "/me"
+	}
+	return "/users/" + url.PathEscape(account)
+}
+
+// splitMessageID separates the mailbox prefix from a message ID
+func splitMessageID(messageID string) (account, id string) {
+	if prefix, rest, found := strings.Cut(messageID, "::"); found && strings.Contains(prefix, "@") {
+		return prefix, rest
+	}
+	return "", messageID
+}
+
+// joinMessageID prefixes a shared mailbox's message ID with the mailbox address
+func joinMessageID(account, id string) string {
+	if account == "" {
+		return id
+	}
+	return account + "::" + id
+}
+
+// messagePath returns the API path of a message
+func messagePath(messageID string) string {
+	account, id := splitMessageID(messageID)
+	return mailboxPath(account) + "/messages/" + url.PathEscape(id)
+}
+
+// graphEmailAddress is a Graph recipient's address
+type graphEmailAddress struct {
+	Name    string `json:"name,omitempty"`
+	Address string `json:"address"`
+}
+
+// graphRecipient is a message sender or recipient
+type graphRecipient struct {
+	EmailAddress graphEmailAddress `json:"emailAddress"`
+}
+
+// graphDateTime is a Graph dateTimeTimeZone value
+type graphDateTime struct {
+	DateTime string `json:"dateTime"`
+	TimeZone string `json:"timeZone"`
+}
+
+// graphBody is a message, event or task body
+type graphBody struct {
+	ContentType string `json:"contentType"`
+	Content     string `json:"content"`
+}
+
+// graphFlag is a message's follow-up flag
+type graphFlag struct {
+	FlagStatus        string         `json:"flagStatus"`
+	StartDateTime     *graphDateTime `json:"startDateTime,omitempty"`
+	DueDateTime       *graphDateTime `json:"dueDateTime,omitempty"`
+	CompletedDateTime *graphDateTime `json:"completedDateTime,omitempty"`
+}
+
+// graphMessage is the subset of a Graph message the backend reads
+type graphMessage struct {
+	ID               string           `json:"id"`
+	Subject          string           `json:"subject"`
+	From             *graphRecipient  `json:"from"`
+	ToRecipients     []graphRecipient `json:"toRecipients"`
+	CcRecipients     []graphRecipient `json:"ccRecipients"`
+	ReceivedDateTime time.Time        `json:"receivedDateTime"`
+	SentDateTime     *time.Time       `json:"sentDateTime"`
+	IsRead           bool             `json:"isRead"`
+	Importance       string           `json:"importance"`
+	HasAttachments   bool             `json:"hasAttachments"`
+	BodyPreview      string           `json:"bodyPreview"`
+	Body             *graphBody       `json:"body"`
+	Flag             graphFlag        `json:"flag"`
+	Categories       []string         `json:"categories"`
+	ConversationID   string           `json:"conversationId"`
+	Attachments      []struct {
+		ID string `json:"id"`
+	} `json:"attachments"`
+	ExtendedProperties []struct {
+		ID    string `json:"id"`
+		Value string `json:"value"`
+	} `json:"singleValueExtendedProperties"`
+}
+
+// toMessage converts a Graph message into a Message of the given mailbox
+func (gm *graphMessage) toMessage(account string) Message {
+	msg := Message{
+		ID:              joinMessageID(account, gm.ID),
+		Subject:         gm.Subject,
+		ReceivedTime:    gm.ReceivedDateTime,
+		SentOn:          gm.SentDateTime,
+		Unread:          !gm.IsRead,
+		Importance:      graphImportanceLevel(gm.Importance),
+		HasAttachments:  gm.HasAttachments,
+		AttachmentCount: len(gm.Attachments),
+		FlagStatus:      graphFlagStatusName(gm.Flag.FlagStatus),
+		Categories:      gm.Categories,
+	}
+	if gm.From != nil {
+		msg.Sender = gm.From.EmailAddress.Name
+		msg.SenderEmail = gm.From.EmailAddress.Address
+	}
+	for _, prop := range gm.ExtendedProperties {
+		if sameGraphProperty(prop.ID, graphMessageSizeProperty) {
+			msg.Size, _ = strconv.Atoi(prop.Value)
+		}
+	}
+	return msg
+}
+
+// sameGraphProperty compares extended property IDs such as "Integer 0x0E08";
+// Graph echoes them back with the tag normalized, e.g. "Integer 0xe08"
+func sameGraphProperty(a, b string) bool {
+	typeA, tagA, okA := strings.Cut(a, " 0x")
+	typeB, tagB, okB := strings.Cut(b, " 0x")
+	if !okA || !okB || !strings.EqualFold(typeA, typeB) {
+		return false
+	}
+	valueA, errA := strconv.ParseUint(tagA, 16, 32)
+	valueB, errB := strconv.ParseUint(tagB, 16, 32)
+	return errA == nil && errB == nil && valueA == valueB
+}
+
+// graphImportanceLevel maps Graph importance to Outlook's olImportance values
+func graphImportanceLevel(importance string) int {
+	switch importance {
+	case ImportanceLow:
+		return 0
+	case ImportanceHigh:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// graphFlagStatusName maps a Graph flag status to the FlagStatus constants
+func graphFlagStatusName(status string) string {
+	switch status {
+	case "flagged":
+		return FlagStatusFlagged
+	case "complete":
+		return FlagStatusComplete
+	default:
+		return FlagStatusNone
+	}
+}
+
+// graphMessageQuery returns the $select and $expand parameters for messages
+func graphMessageQuery() url.Values {
+	params := url.Values{}
+	params.Set("$select", graphMessageSelect)
+	params.Set("$expand", fmt.Sprintf("singleValueExtendedProperties($filter=id eq '%s')", graphMessageSizeProperty))
+	return params
+}
+
+// graphRecipients converts addresses into Graph recipients
+func graphRecipients(addresses []string) []graphRecipient {
+	recipients := make([]graphRecipient, 0, len(addresses))
+	for _, address := range addresses {
+		recipients = append(recipients, graphRecipient{EmailAddress: graphEmailAddress{Address: address}})
+	}
+	return recipients
+}
+
+// recipientAddresses lists the addresses of Graph recipients
+func recipientAddresses(recipients []graphRecipient) []string {
+	addresses := make([]string, 0, len(recipients))
+	for _, recipient := range recipients {
+		addresses = append(addresses, recipient.EmailAddress.Address)
+	}
+	return addresses
+}
+
+// graphFolder is a Graph mail folder
+type graphFolder struct {
+	ID               string `json:"id"`
+	DisplayName      string `json:"displayName"`
+	TotalItemCount   int    `json:"totalItemCount"`
+	UnreadItemCount  int    `json:"unreadItemCount"`
+	ChildFolderCount int    `json:"childFolderCount"`
+}
+
+// ListFolders retrieves the mail folder hierarchy of the signed-in user's
+// mailbox, or of a shared mailbox when account is set. Paths are slash
+// separated display names, which the folder parameters accept.
+func (g *GraphManager) ListFolders(account string) (*FolderListResponse, error) {
+	folders := []Folder{}
+	if err := g.walkFolders(mailboxPath(account), "", "", 0, &folders); err != nil {
+		return nil, err
+	}
+	return &FolderListResponse{Account: account, Folders: folders, Count: len(folders)}, nil
+}
+
+// walkFolders appends the subfolders of parentID (the top level when empty)
+// depth first, so each folder is followed by its own subfolders
+func (g *GraphManager) walkFolders(mailbox, parentID, parentPath string, depth int, folders *[]Folder) error {
+	endpoint := mailbox + "/mailFolders"
+	if parentID != "" {
+		endpoint += "/" + url.PathEscape(parentID) + "/childFolders"
+	}
+	endpoint += "?$top=250&$select=id,displayName,totalItemCount,unreadItemCount,childFolderCount"
+	children, _, err := graphList[graphFolder](g, endpoint, "", 0)
+	if err != nil {
+		return err
+	}
+
+	for _, child := range children {
+		path := child.DisplayName
+		if parentPath != "" {
+			path = parentPath + "/" + child.DisplayName
+		}
+		*folders = append(*folders, Folder{
+			ID:          child.ID,
+			Name:        child.DisplayName,
+			Path:        path,
+			ItemCount:   child.TotalItemCount,
+			UnreadCount: child.UnreadItemCount,
+			Depth:       depth,
+		})
+		if child.ChildFolderCount > 0 {
+			if err := g.walkFolders(mailbox, child.ID, path, depth+1, folders); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// resolveFolder returns the API path segment of a folder given by well-known
+// name, slash separated path, display name or ID. An empty folder is the Inbox.
+func (g *GraphManager) resolveFolder(folder, account string) (string, error) {
+	if folder == "" {
+		return "inbox", nil
+	}
+	if wellKnown, ok := graphWellKnownFolders[strings.ToLower(folder)]; ok {
+		return wellKnown, nil
+	}
+
+	response, err := g.ListFolders(account)
+	if err != nil {
+		return "", err
+	}
+	trimmed := strings.Trim(folder, "/\\")
+	for _, f := range response.Folders {
+		if strings.EqualFold(f.Path, trimmed) {
+			return url.PathEscape(f.ID), nil
+		}
+	}
+	for _, f := range response.Folders {
+		if strings.EqualFold(f.Name, trimmed) {
+			return url.PathEscape(f.ID), nil
+		}
+	}
+	for _, f := range response.Folders {
+		if f.ID == folder {
+			return url.PathEscape(f.ID), nil
+		}
+	}
+
+	if account != "" {
+		return "", fmt.Errorf("folder %q not found in mailbox %s", folder, account)
+	}
+	return "", fmt.Errorf("folder %q not found", folder)
+}
+
+// ListMessages retrieves a page of messages from a folder (default: Inbox),
+// newest first
+func (g *GraphManager) ListMessages(page int, folder, account string, filters ListFilters) (*MessageListResponse, error) {
+	if err := validateImportance(filters.Importance); err != nil {
+		return nil, err
+	}
+	return g.listMessages(page, folder, account, time.Time{}, filters)
+}
+
+// ListMessagesSince retrieves a page of messages received after since
+func (g *GraphManager) ListMessagesSince(since time.Time, page int, folder, account string) (*MessageListResponse, error) {
+	return g.listMessages(page, folder, account, since, ListFilters{})
+}
+
+// listMessages retrieves a page of a folder, optionally only messages received
+// after since and matching filters
+func (g *GraphManager) listMessages(page int, folder, account string, since time.Time, filters ListFilters) (*MessageListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+
+	folderSegment, err := g.resolveFolder(folder, account)
+	if err != nil {
+		return nil, err
+	}
+
+	params := graphMessageQuery()
+	params.Set("$top", strconv.Itoa(graphMessagePageSize))
+	params.Set("$skip", strconv.Itoa((page-1)*graphMessagePageSize))
+	params.Set("$orderby", "receivedDateTime desc")
+	params.Set("$count", "true")
+	if filter := graphListFilter(since, filters); filter != "" {
+		params.Set("$filter", filter)
+	}
+
+	endpoint := mailboxPath(account) + "/mailFolders/" + folderSegment + "/messages?" + params.Encode()
+	var result graphPage[graphMessage]
+	if err := g.getJSON(endpoint, "", &result); err != nil {
+		return nil, err
+	}
+
+	response := &MessageListResponse{Folder: folder, Messages: make([]Message, 0, len(result.Value))}
+	if response.Folder == "" {
+		response.Folder = "Inbox"
+	}
+	if !since.IsZero() {
+		response.Since = &since
+	}
+	for i := range result.Value {
+		response.Messages = append(response.Messages, result.Value[i].toMessage(account))
+	}
+
+	total := len(response.Messages) + (page-1)*graphMessagePageSize
+	if result.Count != nil {
+		total = *result.Count
+	}
+	response.Pagination = Pagination{
+		Page:        page,
+		PageSize:    graphMessagePageSize,
+		Total:       total,
+		HasNext:     page*graphMessagePageSize < total,
+		HasPrevious: page > 1,
+	}
+
+	return response, nil
+}
+
+// GetMailboxStats counts the messages a folder (default: Inbox) received in
+// [start, end), newest first up to maxAnalyticsMessages. Graph can't
+// aggregate, so the messages' metadata is listed and counted here.
+func (g *GraphManager) GetMailboxStats(folder, account string, start, end time.Time, largest int) (*MailboxStats, error) {
+	folderSegment, err := g.resolveFolder(folder, account)
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("$select", "id,subject,from,receivedDateTime,isRead,importance,hasAttachments")
+	params.Set("$expand", fmt.Sprintf("singleValueExtendedProperties($filter=id eq '%s')", graphMessageSizeProperty))
+	params.Set("$top", "100")
+	params.Set("$orderby", "receivedDateTime desc")
+	params.Set("$filter", "receivedDateTime ge "+start.UTC().Format(time.RFC3339)+" and receivedDateTime lt "+end.UTC().Format(time.RFC3339))
+
+	endpoint := mailboxPath(account) + "/mailFolders/" + folderSegment + "/messages?" + params.Encode()
+	items, truncated, err := graphList[graphMessage](g, endpoint, "", maxAnalyticsMessages)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]Message, 0, len(items))
+	for i := range items {
+		messages = append(messages, items[i].toMessage(account))
+	}
+	if folder == "" {
+		folder = "Inbox"
+	}
+	return collectMailboxStats(folder, messages, truncated, largest), nil
+}
+
+// getMessage fetches a message with the given $select fields
+func (g *GraphManager) getMessage(messageID string, params url.Values, prefer string) (*graphMessage, error) {
+	var msg graphMessage
+	if err := g.getJSON(messagePath(messageID)+"?"+params.Encode(), prefer, &msg); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+// GetMessage retrieves a message's metadata with a short body preview
+func (g *GraphManager) GetMessage(messageID string) (*Message, error) {
+	params := graphMessageQuery()
+	params.Set("$expand", params.Get("$expand")+",attachments($select=id)")
+
+	gm, err := g.getMessage(messageID, params, "")
+	if err != nil {
+		return nil, err
+	}
+
+	account, _ := splitMessageID(messageID)
+	msg := gm.toMessage(account)
+	msg.BodyPreview = gm.BodyPreview
+	return &msg, nil
+}
+
+// GetMessageBody retrieves a message's body as plain text
+func (g *GraphManager) GetMessageBody(messageID string) (*MessageBodyResponse, error) {
+	gm, err := g.getMessage(messageID, url.Values{"$select": {"body"}}, `outlook.body-content-type="text"`)
+	if err != nil {
+		return nil, err
+	}
+
+	text := ""
+	if gm.Body != nil {
+		text = gm.Body.Content
+	}
+	return &MessageBodyResponse{
+		ID:        messageID,
+		BodyText:  text,
+		WordCount: len(strings.Fields(text)),
+		CharCount: utf8.RuneCountInString(text),
+	}, nil
+}
+
+// GetMessageBodyRaw retrieves a message's body in its stored format, with a
+// plain text rendering of HTML bodies
+func (g *GraphManager) GetMessageBodyRaw(messageID string) (*MessageBodyRawResponse, error) {
+	gm, err := g.getMessage(messageID, url.Values{"$select": {"body"}}, "")
+	if err != nil {
+		return nil, err
+	}
+
+	response := &MessageBodyRawResponse{ID: messageID, Format: "PlainText"}
+	if gm.Body == nil || !strings.EqualFold(gm.Body.ContentType, "html") {
+		if gm.Body != nil {
+			response.BodyText = gm.Body.Content
+		}
+		return response, nil
+	}
+
+	response.Format = "HTML"
+	response.BodyHTML = gm.Body.Content
+	text, err := g.GetMessageBody(messageID)
+	if err != nil {
+		return nil, err
+	}
+	response.BodyText = text.BodyText
+	return response, nil
+}
+
+// GetConversation retrieves every message of the conversation a message belongs to, oldest first
+func (g *GraphManager) GetConversation(messageID string) (*ConversationResponse, error) {
+	gm, err := g.getMessage(messageID, url.Values{"$select": {"conversationId,subject"}}, "")
+	if err != nil {
+		return nil, err
+	}
+
+	account, _ := splitMessageID(messageID)
+	params := graphMessageQuery()
+	params.Set("$filter", fmt.Sprintf("conversationId eq '%s'", strings.ReplaceAll(gm.ConversationID, "'", "''")))
+
+	// Graph rejects $orderby together with this filter, so sort locally
+	items, _, err := graphList[graphMessage](g, mailboxPath(account)+"/messages?"+params.Encode(), "", 0)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].ReceivedDateTime.Before(items[j].ReceivedDateTime) })
+
+	response := &ConversationResponse{
+		ConversationID: gm.ConversationID,
+		Topic:          gm.Subject,
+		Messages:       make([]Message, 0, len(items)),
+	}
+	for i := range items {
+		msg := items[i].toMessage(account)
+		msg.BodyPreview = items[i].BodyPreview
+		response.Messages = append(response.Messages, msg)
+	}
+	if len(items) > 0 {
+		response.Topic = items[0].Subject
+	}
+	response.Count = len(response.Messages)
+
+	return response, nil
+}
+
+// SearchMessages searches a folder (default: Inbox) by free text and/or
+// filters. Text, sender and recipients go to Graph's KQL search; the remaining
+// filters are applied to its results, which are capped at graphSearchLimit.
+// Without KQL every filter fits in $filter, so Graph pages and counts the
+// matches itself.
+func (g *GraphManager) SearchMessages(query, folder, account string, filters SearchFilters, page, pageSize int) (*SearchResponse, error) {
+	if err := validateSearch(filters, pageSize); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(query) == "" && filters.IsEmpty() {
+		return nil, fmt.Errorf("a query or at least one filter is required")
+	}
+	if page < 1 {
+		page = 1
+	}
+	if pageSize == 0 {
+		pageSize = 25
+	}
+
+	folderSegment, err := g.resolveFolder(folder, account)
+	if err != nil {
+		return nil, err
+	}
+	kql := graphSearchKQL(query, filters)
+	if kql == "" {
+		return g.filterMessages(folderSegment, folder, account, filters, page, pageSize)
+	}
+
+	params := graphMessageQuery()
+	params.Set("$top", "50")
+	params.Set("$search", `"`+kql+`"`)
+
+	endpoint := mailboxPath(account) + "/mailFolders/" + folderSegment + "/messages?" + params.Encode()
+	items, truncated, err := graphList[graphMessage](g, endpoint, "", graphSearchLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	needle := strings.ToLower(strings.TrimSpace(query))
+	matches := []Message{}
+	for i := range items {
+		if !graphMatchesFilters(&items[i], filters) {
+			continue
+		}
+		msg := items[i].toMessage(account)
+		if needle != "" && strings.Contains(strings.ToLower(items[i].BodyPreview), needle) {
+			msg.Snippet = items[i].BodyPreview
+		}
+		matches = append(matches, msg)
+	}
+	rankSearchResults(matches, query, time.Now())
+
+	skip := min((page-1)*pageSize, len(matches))
+	results := matches[skip:min(skip+pageSize, len(matches))]
+
+	return &SearchResponse{
+		Query:   query,
+		Folder:  folder,
+		Results: results,
+		Count:   len(results),
+		Pagination: Pagination{
+			Page:        page,
+			PageSize:    pageSize,
+			Total:       len(matches),
+			HasNext:     skip+pageSize < len(matches),
+			HasPrevious: page > 1,
+		},
+		Truncated: truncated,
+	}, nil
+}
+
+// filterMessages retrieves a page of the messages in a folder that match
+// filters, newest first
+func (g *GraphManager) filterMessages(folderSegment, folder, account string, filters SearchFilters, page, pageSize int) (*SearchResponse, error) {
+	params := graphMessageQuery()
+	params.Set("$top", strconv.Itoa(pageSize))
+	params.Set("$skip", strconv.Itoa((page-1)*pageSize))
+	params.Set("$orderby", "receivedDateTime desc")
+	params.Set("$count", "true")
+	params.Set("$filter", graphSearchFilter(filters))
+
+	endpoint := mailboxPath(account) + "/mailFolders/" + folderSegment + "/messages?" + params.Encode()
+	var result graphPage[graphMessage]
+	if err := g.getJSON(endpoint, "", &result); err != nil {
+		return nil, err
+	}
+
+	results := make([]Message, 0, len(result.Value))
+	for i := range result.Value {
+		results = append(results, result.Value[i].toMessage(account))
+	}
+	rankSearchResults(results, "", time.Now())
+
+	total := len(results) + (page-1)*pageSize
+	if result.Count != nil {
+		total = *result.Count
+	}
+	return &SearchResponse{
+		Folder:  folder,
+		Results: results,
+		Count:   len(results),
+		Pagination: Pagination{
+			Page:        page,
+			PageSize:    pageSize,
+			Total:       total,
+			HasNext:     page*pageSize < total,
+			HasPrevious: page > 1,
+		},
+	}, nil
+}
+
+// graphSearchKQL builds the KQL search for the text, sender and recipient
+// criteria, or returns "" when none are set
+func graphSearchKQL(query string, filters SearchFilters) string {
+	clean := func(s string) string {
+		return strings.TrimSpace(strings.NewReplacer(`"`, " ", `\`, " ").Replace(s))
+	}
+
+	var terms []string
+	if q := clean(query); q != "" {
+		terms = append(terms, q)
+	}
+	if from := clean(filters.From); from != "" {
+		terms = append(terms, "from:"+from)
+	}
+	if to := clean(filters.To); to != "" {
+		terms = append(terms, "participants:"+to)
+	}
+	return strings.Join(terms, " AND ")
+}
+
+// graphListFilter builds the $filter of a folder listing. Graph rejects
+// filters that don't start with the $orderby property, so a filter on other
+// properties is prefixed with a receivedDateTime condition every message meets.
+func graphListFilter(since time.Time, filters ListFilters) string {
+	var conditions []string
+	if !since.IsZero() {
+		conditions = append(conditions, "receivedDateTime gt "+since.UTC().Format(time.RFC3339))
+	} else if !filters.IsEmpty() {
+		conditions = append(conditions, "receivedDateTime ge 1900-01-01T00:00:00Z")
+	}
+	if filters.UnreadOnly {
+		conditions = append(conditions, "isRead eq false")
+	}
+	if filters.Importance != "" {
+		conditions = append(conditions, "importance eq '"+filters.Importance+"'")
+	}
+	if filters.Category != "" {
+		conditions = append(conditions, "categories/any(c:c eq '"+strings.ReplaceAll(filters.Category, "'", "''")+"')")
+	}
+	if filters.HasAttachments != nil {
+		conditions = append(conditions, "hasAttachments eq "+strconv.FormatBool(*filters.HasAttachments))
+	}
+	return strings.Join(conditions, " and ")
+}
+
+// graphSearchFilter builds the $filter of a search without KQL; like
+// graphListFilter, it starts with a receivedDateTime condition
+func graphSearchFilter(filters SearchFilters) string {
+	after := "1900-01-01T00:00:00Z"
+	if !filters.After.IsZero() {
+		after = filters.After.UTC().Format(time.RFC3339)
+	}
+	conditions := []string{"receivedDateTime ge " + after}
+	if !filters.Before.IsZero() {
+		conditions = append(conditions, "receivedDateTime lt "+filters.Before.UTC().Format(time.RFC3339))
+	}
+	if filters.Unread != nil {
+		conditions = append(conditions, "isRead eq "+strconv.FormatBool(!*filters.Unread))
+	}
+	if filters.Importance != "" {
+		conditions = append(conditions, "importance eq '"+filters.Importance+"'")
+	}
+	if filters.HasAttachments != nil {
+		conditions = append(conditions, "hasAttachments eq "+strconv.FormatBool(*filters.HasAttachments))
+	}
+	return strings.Join(conditions, " and ")
+}
+
+// graphMatchesFilters applies the filters KQL does not cover to a search result
+func graphMatchesFilters(gm *graphMessage, filters SearchFilters) bool {
+	if !filters.After.IsZero() && gm.ReceivedDateTime.Before(filters.After) {
+		return false
+	}
+	if !filters.Before.IsZero() && !gm.ReceivedDateTime.Before(filters.Before) {
+		return false
+	}
+	if filters.HasAttachments != nil && gm.HasAttachments != *filters.HasAttachments {
+		return false
+	}
+	if filters.Unread != nil && gm.IsRead == *filters.Unread {
+		return false
+	}
+	if filters.Importance != "" && !strings.EqualFold(gm.Importance, filters.Importance) {
+		return false
+	}
+	return true
+}
+
+// UpdateMessage changes the read state, follow-up flag and/or categories of a message
+func (g *GraphManager) UpdateMessage(messageID string, request UpdateMessageRequest) (*UpdateMessageResponse, error) {
+	if request.Unread == nil && request.Flag == "" && request.Categories == nil {
+		return nil, fmt.Errorf("no changes requested")
+	}
+
+	patch := map[string]interface{}{}
+	if request.Unread != nil {
+		patch["isRead"] = !*request.Unread
+	}
+	switch request.Flag {
+	case "":
+	case FlagStatusFlagged, FlagStatusComplete:
+		patch["flag"] = graphFlag{FlagStatus: request.Flag}
+	case FlagStatusNone:
+		patch["flag"] = graphFlag{FlagStatus: "notFlagged"}
+	default:
+		return nil, fmt.Errorf("invalid flag value %q (expected %s, %s or %s)", request.Flag, FlagStatusFlagged, FlagStatusComplete, FlagStatusNone)
+	}
+	if request.Categories != nil {
+		patch["categories"] = *request.Categories
+	}
+
+	var gm graphMessage
+	if err := g.sendJSON(http.MethodPatch, messagePath(messageID), patch, &gm); err != nil {
+		return nil, err
+	}
+
+	categories := gm.Categories
+	if categories == nil {
+		categories = []string{}
+	}
+	return &UpdateMessageResponse{
+		ID:         messageID,
+		Unread:     !gm.IsRead,
+		FlagStatus: graphFlagStatusName(gm.Flag.FlagStatus),
+		Categories: categories,
+	}, nil
+}
+
+// MoveMessage moves a message to another folder of its mailbox and returns its
+// new ID. Graph cannot move messages between mailboxes.
+func (g *GraphManager) MoveMessage(messageID, folder, account string) (*MoveMessageResponse, error) {
+	if folder == "" {
+		return nil, fmt.Errorf("destination folder is required")
+	}
+
+	source, _ := splitMessageID(messageID)
+	if account != "" && !strings.EqualFold(account, source) {
+		if source != "" || !g.isSignedInUser(account) {
+			return nil, fmt.Errorf("moving messages between mailboxes is not supported by the graph backend")
+		}
+	}
+
+	destination, err := g.resolveFolder(folder, source)
+	if err != nil {
+		return nil, err
+	}
+	destinationID, err := url.PathUnescape(destination)
+	if err != nil {
+		return nil, fmt.Errorf("invalid folder ID: %w", err)
+	}
+
+	var moved graphMessage
+	if err := g.sendJSON(http.MethodPost, messagePath(messageID)+"/move", map[string]string{"destinationId": destinationID}, &moved); err != nil {
+		return nil, err
+	}
+
+	return &MoveMessageResponse{
+		ID:         joinMessageID(source, moved.ID),
+		PreviousID: messageID,
+		Folder:     folder,
+	}, nil
+}
+
+// isSignedInUser reports whether an account address names the signed-in user's own mailbox
+func (g *GraphManager) isSignedInUser(account string) bool {
+	user, err := g.me()
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(account, user.Mail) || strings.EqualFold(account, user.UserPrincipalName)
+}
+
+// DeleteMessage moves a message to Deleted Items, or removes it for good when
+// permanent is set and the backend was started with permanent deletion allowed
+func (g *GraphManager) DeleteMessage(messageID string, permanent bool) (*DeleteMessageResponse, error) {
+	if permanent && !g.allowPurge {
+		return nil, shared.NewError(shared.CodeAccessDenied, "permanent deletion is disabled; start outlook-mcp with --allow-permanent-delete to enable it")
+	}
+
+	if permanent {
+		if _, err := g.do(http.MethodPost, messagePath(messageID)+"/permanentDelete", nil, ""); err != nil {
+			return nil, err
+		}
+		return &DeleteMessageResponse{ID: messageID, Permanent: true}, nil
+	}
+
+	// DELETE moves the message to Deleted Items rather than purging it
+	if _, err := g.do(http.MethodDelete, messagePath(messageID), nil, ""); err != nil {
+		return nil, err
+	}
+	return &DeleteMessageResponse{ID: messageID, Folder: "Deleted Items"}, nil
+}
+
+// graphOutgoingMessage is the message resource sent to the draft and send endpoints
+type graphOutgoingMessage struct {
+	Subject       string           `json:"subject"`
+	Body          graphBody        `json:"body"`
+	ToRecipients  []graphRecipient `json:"toRecipients"`
+	CcRecipients  []graphRecipient `json:"ccRecipients,omitempty"`
+	BccRecipients []graphRecipient `json:"bccRecipients,omitempty"`
+}
+
+// newOutgoingMessage builds a Graph message from send or draft fields
+func newOutgoingMessage(to, cc, bcc []string, subject, body string, html bool) graphOutgoingMessage {
+	contentType := "text"
+	if html {
+		contentType = "html"
+	}
+	return graphOutgoingMessage{
+		Subject:       subject,
+		Body:          graphBody{ContentType: contentType, Content: body},
+		ToRecipients:  graphRecipients(to),
+		CcRecipients:  graphRecipients(cc),
+		BccRecipients: graphRecipients(bcc),
+	}
+}
+
+// CreateDraft composes a message and saves it to the Drafts folder without sending it.
+// Attachment paths are resolved to absolute paths and must refer to existing files.
+func (g *GraphManager) CreateDraft(request DraftRequest) (*DraftResponse, error) {
+	attachments, err := resolveAttachmentPaths(request.Attachments)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range attachments {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("attachment not accessible: %w", err)
+		}
+		if info.Size() > graphMaxAttachmentBytes {
+			return nil, fmt.Errorf("attachment %s exceeds the %d MB the graph backend can attach", path, graphMaxAttachmentBytes/(1024*1024))
+		}
+	}
+
+	message := newOutgoingMessage(request.To, request.Cc, request.Bcc, request.Subject, request.Body, request.HTML)
+	var draft graphMessage
+	if err := g.sendJSON(http.MethodPost, "/me/messages", message, &draft); err != nil {
+		return nil, err
+	}
+
+	for _, path := range attachments {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read attachment: %w", err)
+		}
+		attachment := map[string]string{
+			"@odata.type":  "#microsoft.graph.fileAttachment",
+			"name":         filepath.Base(path),
+			"contentBytes": base64.StdEncoding.EncodeToString(data),
+		}
+		if err := g.sendJSON(http.MethodPost, messagePath(draft.ID)+"/attachments", attachment, nil); err != nil {
+			return nil, fmt.Errorf("draft %s created but attaching %s failed: %w", draft.ID, filepath.Base(path), err)
+		}
+	}
+
+	return &DraftResponse{
+		ID:              draft.ID,
+		Subject:         draft.Subject,
+		To:              strings.Join(request.To, "; "),
+		Folder:          "Drafts",
+		AttachmentCount: len(attachments),
+	}, nil
+}
+
+// graphAttachment is a Graph attachment's metadata
+type graphAttachment struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Size     int64  `json:"size"`
+	IsInline bool   `json:"isInline"`
+}
+
+// listGraphAttachments fetches a message's attachments in Graph's order, which
+// defines the 1-based indexes
+func (g *GraphManager) listGraphAttachments(messageID string) ([]graphAttachment, error) {
+	endpoint := messagePath(messageID) + "/attachments?$select=id,name,size,isInline"
+	attachments, _, err := graphList[graphAttachment](g, endpoint, "", 0)
+	return attachments, err
+}
+
+// ListAttachments retrieves the attachments of a message
+func (g *GraphManager) ListAttachments(messageID string) (*AttachmentListResponse, error) {
+	attachments, err := g.listGraphAttachments(messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &AttachmentListResponse{ID: messageID, Attachments: make([]Attachment, 0, len(attachments))}
+	for i, attachment := range attachments {
+		response.Attachments = append(response.Attachments, Attachment{
+			Index:    i + 1,
+			FileName: attachment.Name,
+			Size:     attachment.Size,
+			Inline:   attachment.IsInline,
+		})
+	}
+	response.Count = len(response.Attachments)
+
+	return response, nil
+}
+
+// SaveAttachment writes an attachment of a message to directory and returns the
+// saved file's path. An empty directory selects a per-user temp directory; an
+// existing file with the same name is never overwritten.
+func (g *GraphManager) SaveAttachment(messageID string, index int, directory string) (*SaveAttachmentResponse, error) {
+	if index < 1 {
+		return nil, fmt.Errorf("attachment index must be 1 or greater")
+	}
+
+	if directory == "" {
+		directory = filepath.Join(os.TempDir(), "outlook-mcp-attachments")
+	}
+	absDir, err := filepath.Abs(directory)
+	if err != nil {
+		return nil, fmt.Errorf("invalid directory %s: %w", directory, err)
+	}
+	if err := os.MkdirAll(absDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	attachments, err := g.listGraphAttachments(messageID)
+	if err != nil {
+		return nil, err
+	}
+	if index > len(attachments) {
+		return nil, fmt.Errorf("attachment index %d out of range (message has %d attachments)", index, len(attachments))
+	}
+	attachment := attachments[index-1]
+
+	data, err := g.do(http.MethodGet, messagePath(messageID)+"/attachments/"+url.PathEscape(attachment.ID)+"/$value", nil, "")
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := writeUniqueFile(absDir, attachment.Name, "attachment", data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SaveAttachmentResponse{
+		ID:       messageID,
+		Index:    index,
+		FileName: filepath.Base(path),
+		Path:     path,
+		Size:     int64(len(data)),
+	}, nil
+}
+
+// GetAttachmentContent returns the bytes of an attachment of a message, up
+// to the same 10 MB limit as the COM backend
+func (g *GraphManager) GetAttachmentContent(messageID string, index int) (*AttachmentContentResponse, error) {
+	if index < 1 {
+		return nil, fmt.Errorf("attachment index must be 1 or greater")
+	}
+
+	attachments, err := g.listGraphAttachments(messageID)
+	if err != nil {
+		return nil, err
+	}
+	if index > len(attachments) {
+		return nil, fmt.Errorf("attachment index %d out of range (message has %d attachments)", index, len(attachments))
+	}
+	attachment := attachments[index-1]
+	if attachment.Size > graphMaxAttachmentData {
+		return nil, shared.NewError(shared.CodeTooLarge, "attachment is %d bytes; content over %d bytes must be saved instead", attachment.Size, graphMaxAttachmentData)
+	}
+
+	data, err := g.do(http.MethodGet, messagePath(messageID)+"/attachments/"+url.PathEscape(attachment.ID)+"/$value", nil, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return &AttachmentContentResponse{
+		ID:       messageID,
+		Index:    index,
+		FileName: attachment.Name,
+		Size:     int64(len(data)),
+		Content:  data,
+	}, nil
+}
+
+// ExportMessage saves a message as an .eml file; Graph cannot produce .msg
+// files. Paths are interpreted as by the COM backend.
+func (g *GraphManager) ExportMessage(messageID, format, path string) (*ExportMessageResponse, error) {
+	if format == "" {
+		format = ExportFormatEml
+	}
+	if format == ExportFormatMsg {
+		return nil, fmt.Errorf("the graph backend can only export %q; .msg files need the com backend", ExportFormatEml)
+	}
+	if format != ExportFormatEml {
+		return nil, fmt.Errorf("format must be %q or %q", ExportFormatMsg, ExportFormatEml)
+	}
+
+	directory, file, err := resolveExportTarget(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := g.do(http.MethodGet, messagePath(messageID)+"/$value", nil, "")
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ExportMessageResponse{ID: messageID, Format: format, Size: int64(len(data))}
+	switch {
+	case directory != "":
+		gm, err := g.getMessage(messageID, url.Values{"$select": {"subject"}}, "")
+		if err != nil {
+			return nil, err
+		}
+		subject := gm.Subject
+		if subject == "" {
+			subject = "message"
+		}
+		if file, err = writeUniqueFile(directory, subject+"."+format, "message."+format, data); err != nil {
+			return nil, err
+		}
+	case file != "":
+		if err := writeNewFile(file, data); err != nil {
+			return nil, err
+		}
+	default:
+		if len(data) > graphMaxInlineExport {
+			return nil, fmt.Errorf("message is too large to return inline (%d bytes); pass a path to save it instead", len(data))
+		}
+		response.Content = base64.StdEncoding.EncodeToString(data)
+		return response, nil
+	}
+
+	response.Path = file
+	response.FileName = filepath.Base(file)
+	return response, nil
+}
+
+// writeNewFile writes data to a file that must not exist yet
+func writeNewFile(file string, data []byte) error {
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	_, writeErr := f.Write(data)
+	if err := errors.Join(writeErr, f.Close()); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
+// writeUniqueFile writes data to name in directory, replacing characters that
+// are invalid in file names and appending " (N)" rather than overwriting
+func writeUniqueFile(directory, name, fallback string, data []byte) (string, error) {
+	safeName := strings.TrimSpace(strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`\/:*?"<>|`, r) || r < 0x20 {
+			return '_'
+		}
+		return r
+	}, name))
+	if safeName == "" {
+		safeName = fallback
+	}
+
+	ext := filepath.Ext(safeName)
+	base := strings.TrimSuffix(safeName, ext)
+	candidate := filepath.Join(directory, safeName)
+	for counter := 1; ; counter++ {
+		f, err := os.OpenFile(candidate, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if errors.Is(err, os.ErrExist) {
+			candidate = filepath.Join(directory, fmt.Sprintf("%s (%d)%s", base, counter, ext))
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to create file: %w", err)
+		}
+		_, writeErr := f.Write(data)
+		if err := errors.Join(writeErr, f.Close()); err != nil {
+			return "", fmt.Errorf("failed to write file: %w", err)
+		}
+		return candidate, nil
+	}
+}
+
+// graphEvent is the subset of a Graph event the backend reads
+type graphEvent struct {
+	ID             string        `json:"id"`
+	SeriesMasterID string        `json:"seriesMasterId"`
+	Type           string        `json:"type"`
+	Subject        string        `json:"subject"`
+	Start          graphDateTime `json:"start"`
+	End            graphDateTime `json:"end"`
+	Location       struct {
+		DisplayName string `json:"displayName"`
+	} `json:"location"`
+	Organizer  *graphRecipient `json:"organizer"`
+	IsAllDay   bool            `json:"isAllDay"`
+	ShowAs     string          `json:"showAs"`
+	Categories []string        `json:"categories"`
+	Body       *graphBody      `json:"body"`
+	Attendees  []struct {
+		Type         string            `json:"type"`
+		EmailAddress graphEmailAddress `json:"emailAddress"`
+	} `json:"attendees"`
+	IsReminderOn               bool `json:"isReminderOn"`
+	ReminderMinutesBeforeStart int  `json:"reminderMinutesBeforeStart"`
+	Recurrence                 *struct {
+		Pattern struct {
+			Type     string `json:"type"`
+			Interval int    `json:"interval"`
+		} `json:"pattern"`
+		Range struct {
+			Type      string `json:"type"`
+			StartDate string `json:"startDate"`
+			EndDate   string `json:"endDate"`
+		} `json:"range"`
+	} `json:"recurrence"`
+}
+
+// graphEventSelect lists the event fields converted into CalendarEvent
+const graphEventSelect = "id,seriesMasterId,type,subject,start,end,location,organizer,isAllDay,showAs,categories"
+
+// parseGraphDateTime parses a dateTimeTimeZone value, which carries no offset
+// in its dateTime; requests ask Graph for UTC
+func parseGraphDateTime(value graphDateTime) time.Time {
+	loc := time.UTC
+	if value.TimeZone != "" && !strings.EqualFold(value.TimeZone, "UTC") {
+		if tz, err := time.LoadLocation(value.TimeZone); err == nil {
+			loc = tz
+		}
+	}
+	parsed, err := time.ParseInLocation("2006-01-02T15:04:05.9999999", value.DateTime, loc)
+	if err != nil {
+		return time.Time{}
+	}
+	return parsed
+}
+
+// graphBusyStatus maps a Graph showAs value to the BusyStatus constants
+func graphBusyStatus(showAs string) string {
+	switch showAs {
+	case "free":
+		return BusyStatusFree
+	case "tentative":
+		return BusyStatusTentative
+	case "oof":
+		return BusyStatusOutOfOffice
+	case "workingElsewhere":
+		return BusyStatusWorkingElsewhere
+	default:
+		return BusyStatusBusy
+	}
+}
+
+// toCalendarEvent converts a Graph event; occurrences report their series ID
+func (ge *graphEvent) toCalendarEvent() CalendarEvent {
+	event := CalendarEvent{
+		ID:          ge.ID,
+		Subject:     ge.Subject,
+		Start:       parseGraphDateTime(ge.Start),
+		End:         parseGraphDateTime(ge.End),
+		Location:    ge.Location.DisplayName,
+		AllDay:      ge.IsAllDay,
+		IsRecurring: ge.Type != "" && ge.Type != "singleInstance",
+		BusyStatus:  graphBusyStatus(ge.ShowAs),
+		Categories:  ge.Categories,
+	}
+	if ge.SeriesMasterID != "" {
+		event.ID = ge.SeriesMasterID
+	}
+	if ge.Organizer != nil {
+		event.Organizer = ge.Organizer.EmailAddress.Name
+	}
+	return event
+}
+
+// ListCalendarEvents retrieves calendar events overlapping [start, end), with
+// recurring series expanded into individual occurrences. At most limit events
+// are returned; a limit of 0 uses the default of 100.
+func (g *GraphManager) ListCalendarEvents(start, end time.Time, limit int) (*CalendarEventListResponse, error) {
+	if !end.After(start) {
+		return nil, fmt.Errorf("end must be after start")
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+
+	params := url.Values{}
+	params.Set("startDateTime", start.UTC().Format(time.RFC3339))
+	params.Set("endDateTime", end.UTC().Format(time.RFC3339))
+	params.Set("$select", graphEventSelect)
+	params.Set("$orderby", "start/dateTime")
+	params.Set("$top", strconv.Itoa(min(limit, 100)))
+
+	items, truncated, err := graphList[graphEvent](g, "/me/calendarView?"+params.Encode(), `outlook.timezone="UTC"`, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &CalendarEventListResponse{Start: start, End: end, Events: make([]CalendarEvent, 0, len(items)), Truncated: truncated}
+	for i := range items {
+		response.Events = append(response.Events, items[i].toCalendarEvent())
+	}
+	response.Count = len(response.Events)
+
+	return response, nil
+}
+
+// GetEvent retrieves full details of a calendar event, including attendees and
+// the recurrence pattern of recurring series
+func (g *GraphManager) GetEvent(eventID string) (*CalendarEvent, error) {
+	params := url.Values{}
+	params.Set("$select", graphEventSelect+",body,attendees,isReminderOn,reminderMinutesBeforeStart,recurrence")
+
+	var ge graphEvent
+	endpoint := "/me/events/" + url.PathEscape(eventID) + "?" + params.Encode()
+	if err := g.getJSON(endpoint, `outlook.timezone="UTC", outlook.body-content-type="text"`, &ge); err != nil {
+		return nil, err
+	}
+
+	event := ge.toCalendarEvent()
+	if ge.Body != nil {
+		event.Body = ge.Body.Content
+	}
+	for _, attendee := range ge.Attendees {
+		name := attendee.EmailAddress.Name
+		if name == "" {
+			name = attendee.EmailAddress.Address
+		}
+		if attendee.Type == "required" {
+			event.RequiredAttendees = append(event.RequiredAttendees, name)
+		} else {
+			event.OptionalAttendees = append(event.OptionalAttendees, name)
+		}
+	}
+	event.IsMeeting = len(ge.Attendees) > 0
+	if ge.IsReminderOn {
+		minutes := ge.ReminderMinutesBeforeStart
+		event.ReminderMinutes = &minutes
+	}
+	if ge.Recurrence != nil {
+		event.IsRecurring = true
+		recurrence := &Recurrence{
+			Type:     graphRecurrenceType(ge.Recurrence.Pattern.Type),
+			Interval: ge.Recurrence.Pattern.Interval,
+		}
+		recurrence.PatternStart, _ = time.Parse("2006-01-02", ge.Recurrence.Range.StartDate)
+		if ge.Recurrence.Range.Type == "endDate" {
+			if patternEnd, err := time.Parse("2006-01-02", ge.Recurrence.Range.EndDate); err == nil {
+				recurrence.PatternEnd = &patternEnd
+			}
+		}
+		event.Recurrence = recurrence
+	}
+
+	return &event, nil
+}
+
+// graphRecurrenceType maps a Graph recurrence pattern type to daily, weekly, monthly or yearly
+func graphRecurrenceType(patternType string) string {
+	switch patternType {
+	case "absoluteMonthly", "relativeMonthly":
+		return "monthly"
+	case "absoluteYearly", "relativeYearly":
+		return "yearly"
+	default:
+		return patternType
+	}
+}
+
+// GetAutomaticReplies reports whether automatic replies (out of office) are turned
+// on for the signed-in user, or another mailbox when account is set
+func (g *GraphManager) GetAutomaticReplies(account string) (*AutomaticReplyStatus, error) {
+	var setting struct {
+		Status                 string        `json:"status"` // disabled, alwaysEnabled or scheduled
+		ScheduledStartDateTime graphDateTime `json:"scheduledStartDateTime"`
+		ScheduledEndDateTime   graphDateTime `json:"scheduledEndDateTime"`
+	}
+	if err := g.getJSON(mailboxPath(account)+"/mailboxSettings/automaticRepliesSetting", "", &setting); err != nil {
+		return nil, err
+	}
+
+	store := account
+	if store == "" {
+		user, err := g.me()
+		if err != nil {
+			return nil, err
+		}
+		store = user.address()
+	}
+
+	enabled := setting.Status == "alwaysEnabled"
+	if setting.Status == "scheduled" {
+		now := time.Now()
+		enabled = !now.Before(parseGraphDateTime(setting.ScheduledStartDateTime)) &&
+			now.Before(parseGraphDateTime(setting.ScheduledEndDateTime))
+	}
+
+	return &AutomaticReplyStatus{Store: store, Enabled: enabled}, nil
+}
+
+// GetProfile reports the signed-in user's identity. Outlook signatures are
+// stored by the desktop and web clients and are not exposed by Graph, so none
+// are returned.
+func (g *GraphManager) GetProfile() (*UserProfile, error) {
+	user, err := g.me()
+	if err != nil {
+		return nil, err
+	}
+
+	return &UserProfile{
+		DisplayName: user.DisplayName,
+		SMTPAddress: user.address(),
+		Signatures:  []Signature{},
+	}, nil
+}
+
+// GetFreeBusy retrieves free/busy slots of interval minutes for each attendee
+// over [start, end). An interval of 0 uses the default of 30 minutes.
+func (g *GraphManager) GetFreeBusy(attendees []string, start, end time.Time, interval int) (*FreeBusyResponse, error) {
+	if err := validateFreeBusy(attendees, start, end); err != nil {
+		return nil, err
+	}
+	if interval <= 0 {
+		interval = 30
+	}
+
+	request := map[string]interface{}{
+		"schedules":                attendees,
+		"startTime":                graphDateTime{DateTime: start.UTC().Format("2006-01-02T15:04:05"), TimeZone: "UTC"},
+		"endTime":                  graphDateTime{DateTime: end.UTC().Format("2006-01-02T15:04:05"), TimeZone: "UTC"},
+		"availabilityViewInterval": interval,
+	}
+
+	var result struct {
+		Value []struct {
+			ScheduleID       string `json:"scheduleId"`
+			AvailabilityView string `json:"availabilityView"`
+			Error            *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		} `json:"value"`
+	}
+	body, err := g.do(http.MethodPost, "/me/calendar/getSchedule", request, `outlook.timezone="UTC"`)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	response := &FreeBusyResponse{Start: start.UTC(), End: end.UTC(), Interval: interval}
+	for _, schedule := range result.Value {
+		attendee := AttendeeFreeBusy{Attendee: schedule.ScheduleID, Email: schedule.ScheduleID}
+		if schedule.Error != nil {
+			attendee.Error = schedule.Error.Message
+		} else {
+			attendee.Resolved = true
+			attendee.Slots = schedule.AvailabilityView
+		}
+		response.Attendees = append(response.Attendees, attendee)
+	}
+
+	return response, nil
+}
+
+// graphTodoTask is the subset of a Microsoft To Do task the backend reads
+type graphTodoTask struct {
+	ID                string         `json:"id"`
+	Title             string         `json:"title"`
+	Status            string         `json:"status"`
+	Importance        string         `json:"importance"`
+	DueDateTime       *graphDateTime `json:"dueDateTime"`
+	StartDateTime     *graphDateTime `json:"startDateTime"`
+	CompletedDateTime *graphDateTime `json:"completedDateTime"`
+	Categories        []string       `json:"categories"`
+}
+
+// graphTaskStatusNames maps To Do task states to the Task.Status values
+var graphTaskStatusNames = map[string]string{
+	"notStarted":      "not_started",
+	"inProgress":      "in_progress",
+	"completed":       "completed",
+	"waitingOnOthers": "waiting",
+	"deferred":        "deferred",
+}
+
+// graphTaskDate returns the YYYY-MM-DD date of an optional dateTimeTimeZone value
+func graphTaskDate(value *graphDateTime) string {
+	if value == nil || len(value.DateTime) < 10 {
+		return ""
+	}
+	return value.DateTime[:10]
+}
+
+// graphTaskDateTime converts a YYYY-MM-DD date into a dateTimeTimeZone value
+func graphTaskDateTime(date string) *graphDateTime {
+	if date == "" {
+		return nil
+	}
+	return &graphDateTime{DateTime: date + "T00:00:00", TimeZone: "UTC"}
+}
+
+// toTask converts a To Do task; its ID carries the list ID as "listId::taskId"
+func (gt *graphTodoTask) toTask(listID string) Task {
+	categories := gt.Categories
+	if categories == nil {
+		categories = []string{}
+	}
+	return Task{
+		ID:            listID + "::" + gt.ID,
+		Subject:       gt.Title,
+		Kind:          TaskKindTask,
+		Status:        graphTaskStatusNames[gt.Status],
+		Complete:      gt.Status == "completed",
+		DueDate:       graphTaskDate(gt.DueDateTime),
+		StartDate:     graphTaskDate(gt.StartDateTime),
+		CompletedDate: graphTaskDate(gt.CompletedDateTime),
+		Importance:    gt.Importance,
+		Categories:    categories,
+	}
+}
+
+// messageToTask converts a flagged message into a Task
+func messageToTask(gm *graphMessage, account string) Task {
+	categories := gm.Categories
+	if categories == nil {
+		categories = []string{}
+	}
+	task := Task{
+		ID:            joinMessageID(account, gm.ID),
+		Subject:       gm.Subject,
+		Kind:          TaskKindMessage,
+		Status:        "not_started",
+		Complete:      gm.Flag.FlagStatus == "complete",
+		DueDate:       graphTaskDate(gm.Flag.DueDateTime),
+		StartDate:     graphTaskDate(gm.Flag.StartDateTime),
+		CompletedDate: graphTaskDate(gm.Flag.CompletedDateTime),
+		Importance:    gm.Importance,
+		Categories:    categories,
+	}
+	if task.Complete {
+		task.Status = "completed"
+	}
+	if gm.From != nil {
+		task.Sender = gm.From.EmailAddress.Name
+	}
+	return task
+}
+
+// defaultTaskList returns the ID of the user's default To Do list
+func (g *GraphManager) defaultTaskList() (string, error) {
+	lists, _, err := graphList[struct {
+		ID                string `json:"id"`
+		WellknownListName string `json:"wellknownListName"`
+	}](g, "/me/todo/lists", "", 0)
+	if err != nil {
+		return "", err
+	}
+	for _, list := range lists {
+		if list.WellknownListName == "defaultList" {
+			return list.ID, nil
+		}
+	}
+	return "", fmt.Errorf("default To Do list not found")
+}
+
+// ListTasks retrieves the tasks of the default To Do list and the messages
+// flagged for follow-up, filtered by status and soonest due first. An empty
+// status lists open tasks and a limit of 0 uses the default of 50.
+func (g *GraphManager) ListTasks(status string, limit int) (*TaskListResponse, error) {
+	if err := validateTaskStatus(status); err != nil {
+		return nil, err
+	}
+	if status == "" {
+		status = TaskStatusOpen
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+
+	listID, err := g.defaultTaskList()
+	if err != nil {
+		return nil, err
+	}
+
+	taskParams := url.Values{}
+	messageParams := url.Values{"$select": {"id,subject,from,importance,categories,flag"}}
+	switch status {
+	case TaskStatusOpen:
+		taskParams.Set("$filter", "status ne 'completed'")
+		messageParams.Set("$filter", "flag/flagStatus eq 'flagged'")
+	case TaskStatusCompleted:
+		taskParams.Set("$filter", "status eq 'completed'")
+		messageParams.Set("$filter", "flag/flagStatus eq 'complete'")
+	default:
+		messageParams.Set("$filter", "flag/flagStatus ne 'notFlagged'")
+	}
+
+	// Fetch one more than the limit of each kind so truncation is detected after merging
+	todoTasks, todoTruncated, err := graphList[graphTodoTask](g, "/me/todo/lists/"+url.PathEscape(listID)+"/tasks?"+taskParams.Encode(), "", limit+1)
+	if err != nil {
+		return nil, err
+	}
+	flagged, flaggedTruncated, err := graphList[graphMessage](g, "/me/messages?"+messageParams.Encode(), "", limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]Task, 0, len(todoTasks)+len(flagged))
+	for i := range todoTasks {
+		tasks = append(tasks, todoTasks[i].toTask(listID))
+	}
+	for i := range flagged {
+		tasks = append(tasks, messageToTask(&flagged[i], ""))
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if (tasks[i].DueDate == "") != (tasks[j].DueDate == "") {
+			return tasks[j].DueDate == ""
+		}
+		return tasks[i].DueDate < tasks[j].DueDate
+	})
+
+	truncated := todoTruncated || flaggedTruncated || len(tasks) > limit
+	if len(tasks) > limit {
+		tasks = tasks[:limit]
+	}
+
+	return &TaskListResponse{Status: status, Tasks: tasks, Count: len(tasks), Truncated: truncated}, nil
+}
+
+// CreateTask creates a task in the default To Do list, or flags
+// request.MessageID for follow-up. Graph has no separate task subject for
+// flagged messages, so Subject only applies to new tasks.
+func (g *GraphManager) CreateTask(request CreateTaskRequest) (*Task, error) {
+	if err := validateCreateTask(request); err != nil {
+		return nil, err
+	}
+
+	if request.MessageID != "" {
+		flag := graphFlag{FlagStatus: "flagged"}
+		if request.DueDate != "" || request.StartDate != "" {
+			// Graph requires a start date whenever a due date is set
+			startDate := request.StartDate
+			if startDate == "" {
+				startDate = request.DueDate
+			}
+			flag.StartDateTime = graphTaskDateTime(startDate)
+			flag.DueDateTime = graphTaskDateTime(request.DueDate)
+		}
+		patch := map[string]interface{}{"flag": flag}
+		if request.Importance != "" {
+			patch["importance"] = request.Importance
+		}
+		if request.Categories != nil {
+			patch["categories"] = request.Categories
+		}
+
+		var gm graphMessage
+		if err := g.sendJSON(http.MethodPatch, messagePath(request.MessageID), patch, &gm); err != nil {
+			return nil, err
+		}
+		account, _ := splitMessageID(request.MessageID)
+		task := messageToTask(&gm, account)
+		return &task, nil
+	}
+
+	listID, err := g.defaultTaskList()
+	if err != nil {
+		return nil, err
+	}
+
+	payload := map[string]interface{}{"title": request.Subject}
+	if request.Body != "" {
+		payload["body"] = graphBody{ContentType: "text", Content: request.Body}
+	}
+	if due := graphTaskDateTime(request.DueDate); due != nil {
+		payload["dueDateTime"] = due
+	}
+	if start := graphTaskDateTime(request.StartDate); start != nil {
+		payload["startDateTime"] = start
+	}
+	if request.Importance != "" {
+		payload["importance"] = request.Importance
+	}
+	if request.Categories != nil {
+		payload["categories"] = request.Categories
+	}
+
+	var created graphTodoTask
+	if err := g.sendJSON(http.MethodPost, "/me/todo/lists/"+url.PathEscape(listID)+"/tasks", payload, &created); err != nil {
+		return nil, err
+	}
+	task := created.toTask(listID)
+	return &task, nil
+}
+
+// CompleteTask marks a To Do task ("listId::taskId") or flagged message complete
+func (g *GraphManager) CompleteTask(taskID string) (*Task, error) {
+	listID, todoID, isTask := strings.Cut(taskID, "::")
+	if isTask && !strings.Contains(listID, "@") {
+		var updated graphTodoTask
+		endpoint := "/me/todo/lists/" + url.PathEscape(listID) + "/tasks/" + url.PathEscape(todoID)
+		if err := g.sendJSON(http.MethodPatch, endpoint, map[string]string{"status": "completed"}, &updated); err != nil {
+			return nil, err
+		}
+		task := updated.toTask(listID)
+		return &task, nil
+	}
+
+	var gm graphMessage
+	if err := g.sendJSON(http.MethodPatch, messagePath(taskID), map[string]interface{}{"flag": graphFlag{FlagStatus: "complete"}}, &gm); err != nil {
+		return nil, err
+	}
+	account, _ := splitMessageID(taskID)
+	task := messageToTask(&gm, account)
+	return &task, nil
+}
+
+// graphUser is the signed-in user's profile
+type graphUser struct {
+	DisplayName       string `json:"displayName"`
+	Mail              string `json:"mail"`
+	UserPrincipalName string `json:"userPrincipalName"`
+}
+
+// address returns the user's email address, falling back to the sign-in name
+func (u *graphUser) address() string {
+	if u.Mail != "" {
+		return u.Mail
+	}
+	return u.UserPrincipalName
+}
+
+// me fetches the signed-in user's profile
+func (g *GraphManager) me() (*graphUser, error) {
+	var user graphUser
+	if err := g.getJSON("/me?$select=displayName,mail,userPrincipalName", "", &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// ListAccounts reports the signed-in user's mailbox. Graph cannot enumerate
+// the shared mailboxes a user may open; they are selected by address.
+func (g *GraphManager) ListAccounts() (*AccountListResponse, error) {
+	user, err := g.me()
+	if err != nil {
+		return nil, err
+	}
+
+	return &AccountListResponse{
+		Accounts: []Account{{
+			Name:        user.DisplayName,
+			SMTPAddress: user.address(),
+			Type:        "exchange",
+			StoreName:   user.address(),
+			IsDefault:   true,
+		}},
+		Stores: []Store{{Name: user.address(), Type: "primary", IsDefault: true}},
+	}, nil
+}
+
+// ListRules retrieves the Inbox rules of the signed-in user in execution order
+func (g *GraphManager) ListRules() (*RuleListResponse, error) {
+	rules, _, err := graphList[struct {
+		DisplayName string                     `json:"displayName"`
+		Sequence    int                        `json:"sequence"`
+		IsEnabled   bool                       `json:"isEnabled"`
+		Conditions  map[string]json.RawMessage `json:"conditions"`
+		Exceptions  map[string]json.RawMessage `json:"exceptions"`
+		Actions     map[string]json.RawMessage `json:"actions"`
+	}](g, "/me/mailFolders/inbox/messageRules", "", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &RuleListResponse{Rules: make([]Rule, 0, len(rules))}
+	for _, rule := range rules {
+		conditions := describeGraphRuleClauses(rule.Conditions, "")
+		conditions = append(conditions, describeGraphRuleClauses(rule.Exceptions, "Except ")...)
+		response.Rules = append(response.Rules, Rule{
+			Name:           rule.DisplayName,
+			Enabled:        rule.IsEnabled,
+			ExecutionOrder: rule.Sequence,
+			Type:           "receive",
+			Conditions:     conditions,
+			Actions:        describeGraphRuleClauses(rule.Actions, ""),
+		})
+	}
+	sort.SliceStable(response.Rules, func(i, j int) bool {
+		return response.Rules[i].ExecutionOrder < response.Rules[j].ExecutionOrder
+	})
+	response.Count = len(response.Rules)
+
+	return response, nil
+}
+
+// describeGraphRuleClauses turns rule predicates or actions into readable
+// descriptions such as "Subject contains: invoice", in key order
+func describeGraphRuleClauses(clauses map[string]json.RawMessage, prefix string) []string {
+	keys := make([]string, 0, len(clauses))
+	for key := range clauses {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	descriptions := []string{}
+	for _, key := range keys {
+		var value interface{}
+		if json.Unmarshal(clauses[key], &value) != nil {
+			continue
+		}
+		label := humanizeGraphKey(key)
+		if prefix != "" {
+			label = prefix + strings.ToLower(label)
+		}
+		switch v := value.(type) {
+		case bool:
+			if v {
+				descriptions = append(descriptions, label)
+			}
+		case nil:
+		default:
+			if text := describeGraphValue(v); text != "" {
+				descriptions = append(descriptions, label+": "+text)
+			}
+		}
+	}
+	return descriptions
+}
+
+// describeGraphValue renders a rule clause value: strings and numbers as is,
+// recipients by address and lists comma separated
+func describeGraphValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []interface{}:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			if text := describeGraphValue(item); text != "" {
+				parts = append(parts, text)
+			}
+		}
+		return strings.Join(parts, ", ")
+	case map[string]interface{}:
+		if address, ok := v["emailAddress"].(map[string]interface{}); ok {
+			return describeGraphValue(address["address"])
+		}
+		if minimum, ok := v["minimumSize"]; ok {
+			return fmt.Sprintf("%s-%s KB", describeGraphValue(minimum), describeGraphValue(v["maximumSize"]))
+		}
+	}
+	return ""
+}
+
+// humanizeGraphKey turns a camelCase key such as subjectContains into "Subject contains"
+func humanizeGraphKey(key string) string {
+	var words strings.Builder
+	for i, r := range key {
+		switch {
+		case i == 0:
+			words.WriteString(strings.ToUpper(string(r)))
+		case r >= 'A' && r <= 'Z':
+			words.WriteByte(' ')
+			words.WriteString(strings.ToLower(string(r)))
+		default:
+			words.WriteRune(r)
+		}
+	}
+	return words.String()
+}
+
+// ListCategories retrieves the master category list
+func (g *GraphManager) ListCategories() (*CategoryListResponse, error) {
+	categories, _, err := graphList[struct {
+		DisplayName string `json:"displayName"`
+		Color       string `json:"color"`
+	}](g, "/me/outlook/masterCategories", "", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &CategoryListResponse{Categories: make([]Category, 0, len(categories))}
+	for _, category := range categories {
+		response.Categories = append(response.Categories, Category{
+			Name:  category.DisplayName,
+			Color: graphCategoryColor(category.Color),
+		})
+	}
+	response.Count = len(response.Categories)
+
+	return response, nil
+}
+
+// graphCategoryColor maps a Graph category color preset to Outlook's color name
+func graphCategoryColor(preset string) string {
+	if n, err := strconv.Atoi(strings.TrimPrefix(preset, "preset")); err == nil && n >= 0 && n+1 < len(graphCategoryColors) {
+		return graphCategoryColors[n+1]
+	}
+	return "none"
+}
+
+// graphContact is the subset of a Graph contact the backend reads
+type graphContact struct {
+	ID             string              `json:"id"`
+	DisplayName    string              `json:"displayName"`
+	EmailAddresses []graphEmailAddress `json:"emailAddresses"`
+	BusinessPhones []string            `json:"businessPhones"`
+	HomePhones     []string            `json:"homePhones"`
+	MobilePhone    string              `json:"mobilePhone"`
+	CompanyName    string              `json:"companyName"`
+	Department     string              `json:"department"`
+	JobTitle       string              `json:"jobTitle"`
+	BusinessAddr   *graphAddress       `json:"businessAddress"`
+	HomeAddr       *graphAddress       `json:"homeAddress"`
+	PersonalNotes  string              `json:"personalNotes"`
+	Categories     []string            `json:"categories"`
+}
+
+// graphAddress is a contact's physical address
+type graphAddress struct {
+	Street          string `json:"street"`
+	City            string `json:"city"`
+	State           string `json:"state"`
+	PostalCode      string `json:"postalCode"`
+	CountryOrRegion string `json:"countryOrRegion"`
+}
+
+// String formats the address on one line, skipping empty parts
+func (a *graphAddress) String() string {
+	if a == nil {
+		return ""
+	}
+	parts := []string{}
+	for _, part := range []string{a.Street, a.City, a.State, a.PostalCode, a.CountryOrRegion} {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// toContact converts a Graph contact's summary fields
+func (gc *graphContact) toContact() Contact {
+	contact := Contact{
+		ID:         gc.ID,
+		Name:       gc.DisplayName,
+		Company:    gc.CompanyName,
+		Department: gc.Department,
+		JobTitle:   gc.JobTitle,
+	}
+	for _, email := range gc.EmailAddresses {
+		contact.Emails = append(contact.Emails, email.Address)
+	}
+	phones := map[string]string{}
+	if len(gc.BusinessPhones) > 0 {
+		phones["business"] = gc.BusinessPhones[0]
+	}
+	if gc.MobilePhone != "" {
+		phones["mobile"] = gc.MobilePhone
+	}
+	if len(gc.HomePhones) > 0 {
+		phones["home"] = gc.HomePhones[0]
+	}
+	if len(phones) > 0 {
+		contact.Phones = phones
+	}
+	return contact
+}
+
+// SearchContacts finds contacts whose name, email, company, department or job
+// title contain query. Graph cannot search these fields together, so contacts
+// are matched locally. An empty query lists all contacts; a limit of 0 uses
+// the default of 25.
+func (g *GraphManager) SearchContacts(query string, limit int) (*ContactSearchResponse, error) {
+	if limit <= 0 {
+		limit = 25
+	}
+
+	endpoint := "/me/contacts?$top=250&$select=id,displayName,emailAddresses,businessPhones,homePhones,mobilePhone,companyName,department,jobTitle"
+	contacts, _, err := graphList[graphContact](g, endpoint, "", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	needle := strings.ToLower(strings.TrimSpace(query))
+	response := &ContactSearchResponse{Query: query, Contacts: []Contact{}}
+	for i := range contacts {
+		contact := contacts[i].toContact()
+		fields := append([]string{contact.Name, contact.Company, contact.Department, contact.JobTitle}, contact.Emails...)
+		if needle != "" && !strings.Contains(strings.ToLower(strings.Join(fields, "\n")), needle) {
+			continue
+		}
+		if len(response.Contacts) == limit {
+			response.Truncated = true
+			break
+		}
+		response.Contacts = append(response.Contacts, contact)
+	}
+	response.Count = len(response.Contacts)
+
+	return response, nil
+}
+
+// GetContact retrieves full details of a contact, including addresses and notes
+func (g *GraphManager) GetContact(contactID string) (*Contact, error) {
+	var gc graphContact
+	if err := g.getJSON("/me/contacts/"+url.PathEscape(contactID), "", &gc); err != nil {
+		return nil, err
+	}
+
+	contact := gc.toContact()
+	contact.BusinessAddress = gc.BusinessAddr.String()
+	contact.HomeAddress = gc.HomeAddr.String()
+	contact.Notes = gc.PersonalNotes
+	contact.Categories = gc.Categories
+	return &contact, nil
+}
+
+// graphPerson is a person or group from the People API
+type graphPerson struct {
+	DisplayName          string `json:"displayName"`
+	ScoredEmailAddresses []struct {
+		Address string `json:"address"`
+	} `json:"scoredEmailAddresses"`
+	PersonType struct {
+		Class    string `json:"class"`    // Person or Group
+		Subclass string `json:"subclass"` // e.g. OrganizationUser, PersonalContact, PublicDistributionList
+	} `json:"personType"`
+}
+
+// toRecipientEntry converts a person into a RecipientEntry
+func (p *graphPerson) toRecipientEntry() RecipientEntry {
+	entry := RecipientEntry{Name: p.DisplayName, Type: RecipientOther}
+	if len(p.ScoredEmailAddresses) > 0 {
+		entry.Address = p.ScoredEmailAddresses[0].Address
+	}
+	switch {
+	case p.PersonType.Class == "Group":
+		entry.Type = RecipientDistributionList
+	case p.PersonType.Subclass == "PersonalContact":
+		entry.Type = RecipientContact
+	case p.PersonType.Class == "Person":
+		entry.Type = RecipientUser
+	}
+	return entry
+}
+
+// ResolveRecipient resolves a name or address with the People API, which
+// searches the directory and the user's contacts. A name is resolved when
+// exactly one entry matches it, or one has it as its name or address;
+// otherwise the matches are returned as candidates. Listing a distribution
+// list's members needs directory permissions the backend doesn't request.
+func (g *GraphManager) ResolveRecipient(name string, expand bool) (*RecipientResolution, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+
+	params := url.Values{}
+	params.Set("$search", `"`+strings.ReplaceAll(name, `"`, "")+`"`)
+	params.Set("$top", "10")
+	params.Set("$select", "displayName,scoredEmailAddresses,personType")
+	var page graphPage[graphPerson]
+	if err := g.getJSON("/me/people?"+params.Encode(), "", &page); err != nil {
+		return nil, err
+	}
+
+	response := &RecipientResolution{Query: name}
+	var candidates []RecipientEntry
+	for i := range page.Value {
+		if entry := page.Value[i].toRecipientEntry(); entry.Address != "" {
+			candidates = append(candidates, entry)
+		}
+	}
+	var match *RecipientEntry
+	for i := range candidates {
+		if strings.EqualFold(candidates[i].Address, name) || strings.EqualFold(candidates[i].Name, name) {
+			if match != nil {
+				match = nil // Two exact matches are as ambiguous as none
+				break
+			}
+			match = &candidates[i]
+		}
+	}
+	if match == nil && len(candidates) == 1 {
+		match = &candidates[0]
+	}
+	if match == nil {
+		response.Candidates = candidates
+		return response, nil
+	}
+
+	response.Resolved = true
+	response.Name, response.Address, response.Type = match.Name, match.Address, match.Type
+	if expand && match.Type == RecipientDistributionList {
+		response.Note = "the graph backend can't list distribution list members; use the com backend"
+	}
+	return response, nil
+}
+
+// checkSendAllowed rejects sending unless the backend was started with sending allowed
+func (g *GraphManager) checkSendAllowed() error {
+	if !g.allowSend {
+		return shared.NewError(shared.CodeAccessDenied, "sending is disabled; start outlook-mcp with --allow-send to enable it")
+	}
+	return nil
+}
+
+// SendMessage composes and sends a new message, saving it to Sent Items
+func (g *GraphManager) SendMessage(request SendMessageRequest) (*SendResponse, error) {
+	if err := g.checkSendAllowed(); err != nil {
+		return nil, err
+	}
+	if len(request.To) == 0 {
+		return nil, fmt.Errorf("at least one recipient is required")
+	}
+
+	payload := map[string]interface{}{
+		"message":         newOutgoingMessage(request.To, request.Cc, request.Bcc, request.Subject, request.Body, request.HTML),
+		"saveToSentItems": true,
+	}
+	if _, err := g.do(http.MethodPost, "/me/sendMail", payload, ""); err != nil {
+		return nil, err
+	}
+
+	return &SendResponse{Status: "sent", Action: "send", Subject: request.Subject, To: strings.Join(request.To, "; ")}, nil
+}
+
+// ReplyToMessage replies to the sender (or all recipients) of an existing message
+func (g *GraphManager) ReplyToMessage(messageID string, request ReplyRequest) (*SendResponse, error) {
+	if err := g.checkSendAllowed(); err != nil {
+		return nil, err
+	}
+
+	original, err := g.getMessage(messageID, url.Values{"$select": {"subject,from,toRecipients,ccRecipients"}}, "")
+	if err != nil {
+		return nil, err
+	}
+
+	action, endpoint := "reply", "/reply"
+	var recipients []string
+	if original.From != nil {
+		recipients = append(recipients, original.From.EmailAddress.Address)
+	}
+	if request.ReplyAll {
+		action, endpoint = "reply_all", "/replyAll"
+		recipients = append(recipients, recipientAddresses(original.ToRecipients)...)
+		recipients = append(recipients, recipientAddresses(original.CcRecipients)...)
+	}
+
+	if _, err := g.do(http.MethodPost, messagePath(messageID)+endpoint, map[string]string{"comment": request.Body}, ""); err != nil {
+		return nil, err
+	}
+
+	return &SendResponse{Status: "sent", Action: action, ID: messageID, Subject: "RE: " + original.Subject, To: strings.Join(recipients, "; ")}, nil
+}
+
+// ForwardMessage forwards an existing message to new recipients
+func (g *GraphManager) ForwardMessage(messageID string, request ForwardRequest) (*SendResponse, error) {
+	if err := g.checkSendAllowed(); err != nil {
+		return nil, err
+	}
+	if len(request.To) == 0 {
+		return nil, fmt.Errorf("at least one recipient is required")
+	}
+
+	original, err := g.getMessage(messageID, url.Values{"$select": {"subject"}}, "")
+	if err != nil {
+		return nil, err
+	}
+
+	payload := map[string]interface{}{
+		"comment":      request.Body,
+		"toRecipients": graphRecipients(request.To),
+	}
+	if len(request.Cc) > 0 || len(request.Bcc) > 0 {
+		payload["message"] = map[string]interface{}{
+			"ccRecipients":  graphRecipients(request.Cc),
+			"bccRecipients": graphRecipients(request.Bcc),
+		}
+	}
+	if _, err := g.do(http.MethodPost, messagePath(messageID)+"/forward", payload, ""); err != nil {
+		return nil, err
+	}
+
+	return &SendResponse{Status: "sent", Action: "forward", ID: messageID, Subject: "FW: " + original.Subject, To: strings.Join(request.To, "; ")}, nil
+}
+
+// Reconnect discards the access token and fetches a fresh one, for when
+// permissions or the session changed; Graph has no connection to re-bind
+func (g *GraphManager) Reconnect() error {
+	g.auth.invalidate()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if _, err := g.auth.accessToken(ctx); err != nil {
+		return fmt.Errorf("failed to refresh Microsoft Graph token: %w", err)
+	}
+	return nil
+}
+
+// ServerLogs is not supported: the Graph backend runs no server process
+func (g *GraphManager) ServerLogs(limit int, stream, filter string) (*ServerLogsResponse, error) {
+	return nil, fmt.Errorf("server logs are only captured by the com backend; the graph backend has no PowerShell server")
+}
+
+// HealthCheck probes Graph as the signed-in user and reports recent request
+// errors. It never fails: an unreachable API is reported in the returned status.
+func (g *GraphManager) HealthCheck() *HealthStatus {
+	status := &HealthStatus{Backend: BackendGraph, Graph: &GraphHealth{}}
+
+	// Probe once without throttling retries so a problem is reported promptly
+	body, _, err := g.doOnce(http.MethodGet, g.baseURL+"/me?$select=displayName,mail,userPrincipalName", nil, "")
+	if err != nil {
+		status.Graph.Error = err.Error()
+	} else {
+		var user graphUser
+		if err := json.Unmarshal(body, &user); err != nil {
+			status.Graph.Error = fmt.Sprintf("failed to parse response: %v", err)
+		} else {
+			status.Graph.Reachable = true
+			status.Graph.User = user.DisplayName
+			status.Graph.Email = user.address()
+		}
+	}
+
+	g.health.snapshot(status, time.Now())
+
+	return status
+}
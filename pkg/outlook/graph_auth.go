@@ -0,0 +1,237 @@
+package outlook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// graphScopes are the delegated permissions the Graph backend requests. The
+// .Shared scopes cover shared mailboxes selected with the account parameter.
+const graphScopes = "offline_access User.Read Mail.ReadWrite Mail.ReadWrite.Shared Mail.Send Calendars.Read Calendars.Read.Shared Contacts.Read People.Read MailboxSettings.Read Tasks.ReadWrite"
+
+// graphToken is the cached OAuth token; the refresh token survives restarts so
+// the device code login is only needed once
+type graphToken struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// graphTokenResponse is the token endpoint's response, including OAuth errors
+type graphTokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	RefreshToken     string `json:"refresh_token"`
+	ExpiresIn        int    `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// graphDeviceCode is the device authorization endpoint's response
+type graphDeviceCode struct {
+	DeviceCode string `json:"device_code"`
+	ExpiresIn  int    `json:"expires_in"`
+	Interval   int    `json:"interval"`
+	Message    string `json:"message"` // Instructions to show the user, including the code and URL
+}
+
+// graphAuth obtains and refreshes Microsoft Graph access tokens with the OAuth
+// device code flow, caching the token on disk between runs
+type graphAuth struct {
+	clientID  string
+	tenant    string
+	authority string // Identity platform base URL, replaced in tests
+	cachePath string
+	client    *http.Client
+	prompt    io.Writer // Device code instructions; stdout carries MCP traffic, so this is stderr
+
+	mu    sync.Mutex
+	token graphToken
+}
+
+// accessToken returns a valid access token, refreshing it or running the
+// device code flow as needed
+func (a *graphAuth) accessToken(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.token.AccessToken == "" && a.token.RefreshToken == "" {
+		a.loadCache()
+	}
+
+	// Leave a minute of slack so a token does not expire mid-request
+	if a.token.AccessToken != "" && time.Now().Add(time.Minute).Before(a.token.ExpiresAt) {
+		return a.token.AccessToken, nil
+	}
+
+	if a.token.RefreshToken != "" {
+		err := a.redeem(ctx, url.Values{
+			"grant_type":    {"refresh_token"},
+			"refresh_token": {a.token.RefreshToken},
+		})
+		if err == nil {
+			return a.token.AccessToken, nil
+		}
+		fmt.Fprintf(a.prompt, "Microsoft Graph token refresh failed, signing in again: %v\n", err)
+	}
+
+	if err := a.deviceCodeLogin(ctx); err != nil {
+		return "", err
+	}
+	return a.token.AccessToken, nil
+}
+
+// invalidate discards the access token so the next call refreshes it
+func (a *graphAuth) invalidate() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.token.AccessToken = ""
+	a.token.ExpiresAt = time.Time{}
+}
+
+// deviceCodeLogin asks the user to sign in on another device and polls until
+// they do; the caller holds a.mu
+func (a *graphAuth) deviceCodeLogin(ctx context.Context) error {
+	body, err := a.post(ctx, "devicecode", url.Values{"scope": {graphScopes}})
+	if err != nil {
+		return fmt.Errorf("failed to start device code login: %w", err)
+	}
+
+	var code graphDeviceCode
+	if err := json.Unmarshal(body, &code); err != nil || code.DeviceCode == "" {
+		return fmt.Errorf("failed to start device code login: %s", string(body))
+	}
+	fmt.Fprintln(a.prompt, code.Message)
+
+	interval := time.Duration(max(code.Interval, 1)) * time.Second
+	deadline := time.Now().Add(time.Duration(code.ExpiresIn) * time.Second)
+	for time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+
+		err := a.redeem(ctx, url.Values{
+			"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+			"device_code": {code.DeviceCode},
+		})
+		switch {
+		case err == nil:
+			return nil
+		case strings.Contains(err.Error(), "authorization_pending"):
+			continue
+		case strings.Contains(err.Error(), "slow_down"):
+			interval += 5 * time.Second
+		default:
+			return fmt.Errorf("device code login failed: %w", err)
+		}
+	}
+
+	return fmt.Errorf("device code login timed out")
+}
+
+// redeem exchanges a grant for tokens and caches them; the caller holds a.mu
+func (a *graphAuth) redeem(ctx context.Context, form url.Values) error {
+	form.Set("scope", graphScopes)
+	body, err := a.post(ctx, "token", form)
+	if err != nil {
+		return err
+	}
+
+	var response graphTokenResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return fmt.Errorf("failed to parse token response: %w", err)
+	}
+	if response.Error != "" {
+		return fmt.Errorf("%s: %s", response.Error, response.ErrorDescription)
+	}
+
+	refreshToken := response.RefreshToken
+	if refreshToken == "" {
+		refreshToken = a.token.RefreshToken
+	}
+	a.token = graphToken{
+		AccessToken:  response.AccessToken,
+		RefreshToken: refreshToken,
+		ExpiresAt:    time.Now().Add(time.Duration(response.ExpiresIn) * time.Second),
+	}
+	a.saveCache()
+	return nil
+}
+
+// post sends a form to an OAuth 2.0 endpoint of the tenant. OAuth errors come
+// back with a 400 status and a JSON body, so the body is returned regardless.
+func (a *graphAuth) post(ctx context.Context, endpoint string, form url.Values) ([]byte, error) {
+	form.Set("client_id", a.clientID)
+	target := fmt.Sprintf("%s/%s/oauth2/v2.0/%s", a.authority, url.PathEscape(a.tenant), endpoint)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return body, nil
+}
+
+// loadCache reads a previously saved token; a missing or corrupt cache just means signing in again
+func (a *graphAuth) loadCache() {
+	if a.cachePath == "" {
+		return
+	}
+	data, err := os.ReadFile(a.cachePath)
+	if err != nil {
+		return
+	}
+	var token graphToken
+	if json.Unmarshal(data, &token) == nil {
+		a.token = token
+	}
+}
+
+// saveCache writes the token readable only by the current user
+func (a *graphAuth) saveCache() {
+	if a.cachePath == "" {
+		return
+	}
+	data, err := json.Marshal(a.token)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(a.cachePath), 0700); err != nil {
+		fmt.Fprintf(a.prompt, "Failed to cache Microsoft Graph token: %v\n", err)
+		return
+	}
+	if err := os.WriteFile(a.cachePath, data, 0600); err != nil {
+		fmt.Fprintf(a.prompt, "Failed to cache Microsoft Graph token: %v\n", err)
+	}
+}
+
+// defaultGraphTokenCache returns the token cache path in the user's config directory
+func defaultGraphTokenCache() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "my-mcp", "outlook-graph-token.json")
+}
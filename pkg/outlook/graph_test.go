@@ -0,0 +1,396 @@
+package outlook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestGraphManager creates a Graph backend for a mock server with a valid token
+func newTestGraphManager(serverURL string) *GraphManager {
+	client := &http.Client{Timeout: 5 * time.Second}
+	return &GraphManager{
+		auth: &graphAuth{
+			client: client,
+			prompt: io.Discard,
+			token:  graphToken{AccessToken: "test-token", ExpiresAt: time.Now().Add(time.Hour)},
+		},
+		baseURL: serverURL,
+		client:  client,
+	}
+}
+
+// TestGraphManagerListMessages tests paging, authorization and message conversion
+func TestGraphManagerListMessages(t *testing.T) {
+	var gotQuery url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			t.Errorf("Expected bearer token, got %q", r.Header.Get("Authorization"))
+		}
+		if r.URL.Path != "/me/mailFolders/inbox/messages" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		gotQuery = r.URL.Query()
+		w.Write([]byte(`{"@odata.count": 12, "value": [{
+			"id": "AAMk1", "subject": "Quarterly report",
+			"from": {"emailAddress": {"name": "Alice", "address": "alice@example.com"}},
+			"receivedDateTime": "2025-03-01T09:30:00Z", "isRead": false, "importance": "high",
+			"hasAttachments": true, "flag": {"flagStatus": "flagged"}, "categories": ["Finance"],
+			"singleValueExtendedProperties": [{"id": "Integer 0xe08", "value": "2048"}]
+		}]}`))
+	}))
+	defer server.Close()
+
+	response, err := newTestGraphManager(server.URL).ListMessages(2, "", "", ListFilters{})
+	if err != nil {
+		t.Fatalf("ListMessages failed: %v", err)
+	}
+
+	if gotQuery.Get("$top") != "10" || gotQuery.Get("$skip") != "10" {
+		t.Errorf("Expected $top=10 and $skip=10, got %v", gotQuery)
+	}
+	if !response.Pagination.HasPrevious || response.Pagination.HasNext || response.Pagination.Total != 12 {
+		t.Errorf("Unexpected pagination %+v", response.Pagination)
+	}
+
+	msg := response.Messages[0]
+	if msg.Sender != "Alice" || msg.SenderEmail != "alice@example.com" || !msg.Unread {
+		t.Errorf("Unexpected sender or read state: %+v", msg)
+	}
+	if msg.Importance != 2 || msg.FlagStatus != FlagStatusFlagged || msg.Size != 2048 {
+		t.Errorf("Expected importance 2, flagged and size 2048, got %d, %s, %d", msg.Importance, msg.FlagStatus, msg.Size)
+	}
+}
+
+// TestGraphManagerSharedMailbox tests that shared mailbox message IDs route back to their mailbox
+func TestGraphListFilter(t *testing.T) {
+	without := false
+	since := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		since   time.Time
+		filters ListFilters
+		want    string
+	}{
+		{time.Time{}, ListFilters{}, ""},
+		{since, ListFilters{}, "receivedDateTime gt 2026-03-01T09:00:00Z"},
+		{time.Time{}, ListFilters{UnreadOnly: true, Importance: "high"},
+			"receivedDateTime ge 1900-01-01T00:00:00Z and isRead eq false and importance eq 'high'"},
+		{since, ListFilters{Category: "Bob's", HasAttachments: &without},
+			"receivedDateTime gt 2026-03-01T09:00:00Z and categories/any(c:c eq 'Bob''s') and hasAttachments eq false"},
+	}
+	for _, tt := range tests {
+		if got := graphListFilter(tt.since, tt.filters); got != tt.want {
+			t.Errorf("graphListFilter(%v, %+v) = %q, want %q", tt.since, tt.filters, got, tt.want)
+		}
+	}
+}
+
+func TestGraphManagerSharedMailbox(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/mailFolders/inbox/messages"):
+			w.Write([]byte(`{"value": [{"id": "AAMk2", "subject": "Shared"}]}`))
+		default:
+			w.Write([]byte(`{"body": {"contentType": "text", "content": "hello shared world"}}`))
+		}
+	}))
+	defer server.Close()
+
+	manager := newTestGraphManager(server.URL)
+	list, err := manager.ListMessages(1, "", "team@example.com", ListFilters{})
+	if err != nil {
+		t.Fatalf("ListMessages failed: %v", err)
+	}
+	id := list.Messages[0].ID
+	if id != "team@example.com::AAMk2" {
+		t.Fatalf("Expected mailbox-prefixed ID, got %s", id)
+	}
+
+	body, err := manager.GetMessageBody(id)
+	if err != nil {
+		t.Fatalf("GetMessageBody failed: %v", err)
+	}
+	if body.WordCount != 3 {
+		t.Errorf("Expected 3 words, got %d", body.WordCount)
+	}
+	if paths[1] != "/users/team@example.com/messages/AAMk2" {
+		t.Errorf("Expected request to the shared mailbox, got %s", paths[1])
+	}
+}
+
+// TestGraphManagerSearchMessages tests KQL construction and local filtering
+func TestGraphManagerSearchMessages(t *testing.T) {
+	var search string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		search = r.URL.Query().Get("$search")
+		w.Write([]byte(`{"value": [
+			{"id": "1", "subject": "Invoice 42", "isRead": true, "receivedDateTime": "2025-03-02T00:00:00Z"},
+			{"id": "2", "subject": "Invoice 43", "isRead": false, "receivedDateTime": "2025-03-01T00:00:00Z"}
+		]}`))
+	}))
+	defer server.Close()
+
+	unread := true
+	response, err := newTestGraphManager(server.URL).SearchMessages("invoice", "", "", SearchFilters{From: "billing", Unread: &unread}, 1, 10)
+	if err != nil {
+		t.Fatalf("SearchMessages failed: %v", err)
+	}
+
+	if search != `"invoice AND from:billing"` {
+		t.Errorf("Unexpected $search %q", search)
+	}
+	if response.Count != 1 || response.Results[0].ID != "2" || response.Truncated {
+		t.Errorf("Expected only the unread message, got %+v", response)
+	}
+}
+
+// TestGraphManagerSearchMessagesFilterOnly tests that searches without text
+// are filtered and counted by Graph, and that capped KQL searches are flagged
+func TestGraphManagerSearchMessagesFilterOnly(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		if query.Get("$search") != "" || query.Get("page") != "" {
+			w.Write([]byte(`{"value": [{"id": "1", "subject": "Invoice", "receivedDateTime": "2025-03-02T00:00:00Z"}], "@odata.nextLink": "` + "http://" + r.Host + r.URL.Path + `?page=2"}`))
+			return
+		}
+		w.Write([]byte(`{"@odata.count": 612, "value": [
+			{"id": "51", "subject": "Status", "isRead": false, "importance": "high", "receivedDateTime": "2025-03-02T00:00:00Z"}
+		]}`))
+	}))
+	defer server.Close()
+	manager := newTestGraphManager(server.URL)
+
+	unread, attachments := true, false
+	before := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	response, err := manager.SearchMessages("", "", "", SearchFilters{Unread: &unread, Importance: "high", HasAttachments: &attachments, Before: before}, 3, 25)
+	if err != nil {
+		t.Fatalf("SearchMessages failed: %v", err)
+	}
+	wantFilter := "receivedDateTime ge 1900-01-01T00:00:00Z and receivedDateTime lt 2025-04-01T00:00:00Z and isRead eq false and importance eq 'high' and hasAttachments eq false"
+	if query.Get("$filter") != wantFilter || query.Get("$skip") != "50" || query.Get("$top") != "25" || query.Get("$count") != "true" {
+		t.Errorf("Unexpected query %v", query)
+	}
+	if response.Pagination.Total != 612 || !response.Pagination.HasNext || response.Truncated || response.Count != 1 {
+		t.Errorf("Unexpected response %+v", response)
+	}
+
+	// A KQL search stops at graphSearchLimit and says so
+	response, err = manager.SearchMessages("invoice", "", "", SearchFilters{}, 1, 25)
+	if err != nil {
+		t.Fatalf("SearchMessages failed: %v", err)
+	}
+	if !response.Truncated || response.Pagination.Total != graphSearchLimit {
+		t.Errorf("Expected a truncated search of %d messages, got total %d, truncated %v", graphSearchLimit, response.Pagination.Total, response.Truncated)
+	}
+}
+
+// TestGraphManagerDeleteMessage tests the permanent deletion opt-in and request routing
+func TestGraphManagerDeleteMessage(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	manager := newTestGraphManager(server.URL)
+	if _, err := manager.DeleteMessage("AAMk1", true); err == nil || !strings.Contains(err.Error(), "--allow-permanent-delete") {
+		t.Errorf("Expected permanent deletion to be refused, got %v", err)
+	}
+
+	response, err := manager.DeleteMessage("AAMk1", false)
+	if err != nil {
+		t.Fatalf("DeleteMessage failed: %v", err)
+	}
+	if response.Permanent || response.Folder != "Deleted Items" {
+		t.Errorf("Unexpected response %+v", response)
+	}
+
+	manager.allowPurge = true
+	if _, err := manager.DeleteMessage("AAMk1", true); err != nil {
+		t.Fatalf("Permanent DeleteMessage failed: %v", err)
+	}
+
+	expected := []string{"DELETE /me/messages/AAMk1", "POST /me/messages/AAMk1/permanentDelete"}
+	if strings.Join(requests, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected requests %v, got %v", expected, requests)
+	}
+}
+
+// TestGraphManagerGetFreeBusy tests the getSchedule request and availability mapping
+func TestGraphManagerGetFreeBusy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Schedules []string `json:"schedules"`
+			Interval  int      `json:"availabilityViewInterval"`
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		if r.URL.Path != "/me/calendar/getSchedule" || len(request.Schedules) != 2 || request.Interval != 30 {
+			t.Errorf("Unexpected request %s %+v", r.URL.Path, request)
+		}
+		w.Write([]byte(`{"value": [
+			{"scheduleId": "bob@example.com", "availabilityView": "0220"},
+			{"scheduleId": "nobody@example.com", "error": {"message": "not found"}}
+		]}`))
+	}))
+	defer server.Close()
+
+	start := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+	response, err := newTestGraphManager(server.URL).GetFreeBusy([]string{"bob@example.com", "nobody@example.com"}, start, start.Add(2*time.Hour), 0)
+	if err != nil {
+		t.Fatalf("GetFreeBusy failed: %v", err)
+	}
+
+	if !response.Attendees[0].Resolved || response.Attendees[0].Slots != "0220" {
+		t.Errorf("Unexpected first attendee %+v", response.Attendees[0])
+	}
+	if response.Attendees[1].Resolved || response.Attendees[1].Error != "not found" {
+		t.Errorf("Unexpected second attendee %+v", response.Attendees[1])
+	}
+	if windows := response.CommonFreeWindows(30 * time.Minute); len(windows) != 2 {
+		t.Errorf("Expected the two free slots of the resolved attendee, got %v", windows)
+	}
+}
+
+// TestGraphManagerTasks tests merging To Do tasks with flagged messages and completing both kinds
+func TestGraphManagerTasks(t *testing.T) {
+	var patched []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/me/todo/lists":
+			w.Write([]byte(`{"value": [{"id": "other"}, {"id": "L1", "wellknownListName": "defaultList"}]}`))
+		case r.URL.Path == "/me/todo/lists/L1/tasks" && r.Method == http.MethodGet:
+			w.Write([]byte(`{"value": [{"id": "T1", "title": "Renew passport", "status": "notStarted", "importance": "normal",
+				"dueDateTime": {"dateTime": "2025-04-01T00:00:00.0000000", "timeZone": "UTC"}}]}`))
+		case r.URL.Path == "/me/messages":
+			w.Write([]byte(`{"value": [{"id": "M1", "subject": "Review contract", "importance": "high",
+				"from": {"emailAddress": {"name": "Carol"}},
+				"flag": {"flagStatus": "flagged", "dueDateTime": {"dateTime": "2025-03-15T00:00:00.0000000", "timeZone": "UTC"}}}]}`))
+		case r.Method == http.MethodPatch:
+			patched = append(patched, r.URL.Path)
+			if strings.Contains(r.URL.Path, "/todo/") {
+				w.Write([]byte(`{"id": "T1", "title": "Renew passport", "status": "completed"}`))
+			} else {
+				w.Write([]byte(`{"id": "M1", "subject": "Review contract", "flag": {"flagStatus": "complete"}}`))
+			}
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	manager := newTestGraphManager(server.URL)
+	response, err := manager.ListTasks("", 0)
+	if err != nil {
+		t.Fatalf("ListTasks failed: %v", err)
+	}
+	if response.Count != 2 || response.Tasks[0].ID != "M1" || response.Tasks[1].ID != "L1::T1" {
+		t.Fatalf("Expected flagged message first by due date, got %+v", response.Tasks)
+	}
+	if response.Tasks[0].Kind != TaskKindMessage || response.Tasks[0].Sender != "Carol" {
+		t.Errorf("Unexpected flagged message task %+v", response.Tasks[0])
+	}
+
+	for _, id := range []string{"L1::T1", "M1"} {
+		task, err := manager.CompleteTask(id)
+		if err != nil {
+			t.Fatalf("CompleteTask(%s) failed: %v", id, err)
+		}
+		if !task.Complete {
+			t.Errorf("Expected %s to be complete", id)
+		}
+	}
+	expected := []string{"/me/todo/lists/L1/tasks/T1", "/me/messages/M1"}
+	if strings.Join(patched, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected PATCH requests %v, got %v", expected, patched)
+	}
+}
+
+// TestGraphManagerHealthCheck tests the Graph health report
+func TestGraphManagerHealthCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"displayName": "Dana", "userPrincipalName": "dana@example.com"}`))
+	}))
+	defer server.Close()
+
+	status := newTestGraphManager(server.URL).HealthCheck()
+	if status.Backend != BackendGraph || !status.Graph.Reachable || status.Graph.Email != "dana@example.com" {
+		t.Fatalf("Unexpected health status %+v", status.Graph)
+	}
+
+	report := formatHealthStatus(status, time.Now())
+	if !strings.Contains(report, "Outlook MCP Health: OK") || !strings.Contains(report, "Microsoft Graph: connected as Dana") {
+		t.Errorf("Unexpected health report:\n%s", report)
+	}
+}
+
+// TestGraphAuthDeviceCodeLogin tests falling back from a rejected refresh token
+// to the device code flow and caching the new token
+func TestGraphAuthDeviceCodeLogin(t *testing.T) {
+	var grants []string
+	authority := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("client_id") != "client-123" {
+			t.Errorf("Expected client_id, got %q", r.Form.Get("client_id"))
+		}
+		switch r.URL.Path {
+		case "/contoso/oauth2/v2.0/devicecode":
+			w.Write([]byte(`{"device_code": "dc", "expires_in": 60, "interval": 1, "message": "Enter ABC at https://example.com/devicelogin"}`))
+		case "/contoso/oauth2/v2.0/token":
+			grants = append(grants, r.Form.Get("grant_type"))
+			if r.Form.Get("grant_type") == "refresh_token" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error": "invalid_grant", "error_description": "expired"}`))
+				return
+			}
+			w.Write([]byte(`{"access_token": "new-access", "refresh_token": "new-refresh", "expires_in": 3600}`))
+		default:
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer authority.Close()
+
+	cachePath := filepath.Join(t.TempDir(), "token.json")
+	stale, _ := json.Marshal(graphToken{AccessToken: "old", RefreshToken: "old-refresh", ExpiresAt: time.Now().Add(-time.Hour)})
+	os.WriteFile(cachePath, stale, 0600)
+
+	var prompt strings.Builder
+	auth := &graphAuth{
+		clientID:  "client-123",
+		tenant:    "contoso",
+		authority: authority.URL,
+		cachePath: cachePath,
+		client:    &http.Client{Timeout: 5 * time.Second},
+		prompt:    &prompt,
+	}
+
+	token, err := auth.accessToken(t.Context())
+	if err != nil {
+		t.Fatalf("accessToken failed: %v", err)
+	}
+	if token != "new-access" {
+		t.Errorf("Expected new access token, got %q", token)
+	}
+	if !strings.Contains(prompt.String(), "Enter ABC") {
+		t.Errorf("Expected device code instructions, got %q", prompt.String())
+	}
+	if strings.Join(grants, ",") != "refresh_token,urn:ietf:params:oauth:grant-type:device_code" {
+		t.Errorf("Unexpected grant sequence %v", grants)
+	}
+
+	var cached graphToken
+	data, _ := os.ReadFile(cachePath)
+	if err := json.Unmarshal(data, &cached); err != nil || cached.RefreshToken != "new-refresh" {
+		t.Errorf("Expected refreshed token in cache, got %s", data)
+	}
+}
@@ -2,34 +2,325 @@ package outlook
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
+	"github.com/kevsmith/my-mcp/pkg/shared"
+	"github.com/kevsmith/my-mcp/pkg/shared/sandbox"
 	"github.com/mark3labs/mcp-go/mcp"
 )
 
 type ListMessagesArgs struct {
-	Page *int `json:"page,omitempty"`
+	Page           *int   `json:"page,omitempty"`
+	Folder         string `json:"folder,omitempty"`
+	Account        string `json:"account,omitempty"`
+	UnreadOnly     bool   `json:"unread_only,omitempty"`
+	Importance     string `json:"importance,omitempty"`
+	Category       string `json:"category,omitempty"`
+	HasAttachments *bool  `json:"has_attachments,omitempty"`
+}
+
+type ListMessagesSinceArgs struct {
+	Since   string `json:"since"`
+	Page    *int   `json:"page,omitempty"`
+	Folder  string `json:"folder,omitempty"`
+	Account string `json:"account,omitempty"`
+}
+
+type ListFoldersArgs struct {
+	Account string `json:"account,omitempty"`
 }
 
 type GetMessageArgs struct {
 	MessageID string `json:"message_id"`
 }
 
+type GetMessageBodyArgs struct {
+	MessageID string `json:"message_id"`
+	Offset    int    `json:"offset,omitempty"`
+	MaxChars  int    `json:"max_chars,omitempty"`
+}
+
 type SearchMessagesArgs struct {
-	Query string `json:"query"`
+	Query          string `json:"query,omitempty"`
+	Folder         string `json:"folder,omitempty"`
+	Account        string `json:"account,omitempty"`
+	From           string `json:"from,omitempty"`
+	To             string `json:"to,omitempty"`
+	After          string `json:"after,omitempty"`
+	Before         string `json:"before,omitempty"`
+	HasAttachments *bool  `json:"has_attachments,omitempty"`
+	Unread         *bool  `json:"unread,omitempty"`
+	Importance     string `json:"importance,omitempty"`
+	Page           *int   `json:"page,omitempty"`
+	PageSize       int    `json:"page_size,omitempty"`
+}
+
+type SendMessageArgs struct {
+	To      []string `json:"to"`
+	Cc      []string `json:"cc,omitempty"`
+	Bcc     []string `json:"bcc,omitempty"`
+	Subject string   `json:"subject"`
+	Body    string   `json:"body"`
+	HTML    bool     `json:"html,omitempty"`
+}
+
+type UpdateMessageArgs struct {
+	MessageID  string    `json:"message_id"`
+	Unread     *bool     `json:"unread,omitempty"`
+	Flag       string    `json:"flag,omitempty"`
+	Categories *[]string `json:"categories,omitempty"`
+}
+
+type MoveMessageArgs struct {
+	MessageID string `json:"message_id"`
+	Folder    string `json:"folder"`
+	Account   string `json:"account,omitempty"`
+}
+
+type DeleteMessageArgs struct {
+	MessageID string `json:"message_id"`
+	Permanent bool   `json:"permanent,omitempty"`
+}
+
+type BulkUpdateMessagesArgs struct {
+	Action         string   `json:"action"`
+	MessageIDs     []string `json:"message_ids,omitempty"`
+	Query          string   `json:"query,omitempty"`
+	Folder         string   `json:"folder,omitempty"`
+	Account        string   `json:"account,omitempty"`
+	From           string   `json:"from,omitempty"`
+	To             string   `json:"to,omitempty"`
+	After          string   `json:"after,omitempty"`
+	Before         string   `json:"before,omitempty"`
+	HasAttachments *bool    `json:"has_attachments,omitempty"`
+	Unread         *bool    `json:"unread,omitempty"`
+	Importance     string   `json:"importance,omitempty"`
+	Destination    string   `json:"destination,omitempty"`
+	Categories     []string `json:"categories,omitempty"`
+	Permanent      bool     `json:"permanent,omitempty"`
+	Limit          int      `json:"limit,omitempty"`
+	DryRun         bool     `json:"dry_run,omitempty"`
+}
+
+type PollNewMessagesArgs struct {
+	Cursor         string `json:"cursor,omitempty"`
+	Since          string `json:"since,omitempty"`
+	Query          string `json:"query,omitempty"`
+	Folder         string `json:"folder,omitempty"`
+	Account        string `json:"account,omitempty"`
+	From           string `json:"from,omitempty"`
+	To             string `json:"to,omitempty"`
+	HasAttachments *bool  `json:"has_attachments,omitempty"`
+	Unread         *bool  `json:"unread,omitempty"`
+	Importance     string `json:"importance,omitempty"`
+	Limit          int    `json:"limit,omitempty"`
+	WaitSeconds    int    `json:"wait_seconds,omitempty"`
+}
+
+type CreateDraftArgs struct {
+	To          []string `json:"to,omitempty"`
+	Cc          []string `json:"cc,omitempty"`
+	Bcc         []string `json:"bcc,omitempty"`
+	Subject     string   `json:"subject"`
+	Body        string   `json:"body"`
+	HTML        bool     `json:"html,omitempty"`
+	Attachments []string `json:"attachments,omitempty"`
+}
+
+type ListAttachmentsArgs struct {
+	MessageID     string `json:"message_id"`
+	Thumbnails    bool   `json:"thumbnails,omitempty"`
+	ThumbnailSize int    `json:"thumbnail_size,omitempty"`
+}
+
+type SaveAttachmentArgs struct {
+	MessageID string `json:"message_id"`
+	Index     int    `json:"index,omitempty"`
+	Directory string `json:"directory,omitempty"`
+}
+
+type ExportMessageArgs struct {
+	MessageID string `json:"message_id"`
+	Format    string `json:"format,omitempty"`
+	Path      string `json:"path,omitempty"`
+}
+
+type ExportMessagesArgs struct {
+	Path           string `json:"path"`
+	Query          string `json:"query,omitempty"`
+	Folder         string `json:"folder,omitempty"`
+	Account        string `json:"account,omitempty"`
+	From           string `json:"from,omitempty"`
+	To             string `json:"to,omitempty"`
+	After          string `json:"after,omitempty"`
+	Before         string `json:"before,omitempty"`
+	HasAttachments *bool  `json:"has_attachments,omitempty"`
+	Unread         *bool  `json:"unread,omitempty"`
+	Importance     string `json:"importance,omitempty"`
+	Limit          int    `json:"limit,omitempty"`
+}
+
+type ListCalendarEventsArgs struct {
+	Start string `json:"start,omitempty"`
+	End   string `json:"end,omitempty"`
+	Limit int    `json:"limit,omitempty"`
+}
+
+type GetEventArgs struct {
+	EventID string `json:"event_id"`
+}
+
+type ListTasksArgs struct {
+	Status string `json:"status,omitempty"`
+	Limit  int    `json:"limit,omitempty"`
+}
+
+type CreateTaskArgs struct {
+	Subject    string   `json:"subject,omitempty"`
+	Body       string   `json:"body,omitempty"`
+	DueDate    string   `json:"due_date,omitempty"`
+	StartDate  string   `json:"start_date,omitempty"`
+	Importance string   `json:"importance,omitempty"`
+	Categories []string `json:"categories,omitempty"`
+	MessageID  string   `json:"message_id,omitempty"`
+}
+
+type CompleteTaskArgs struct {
+	TaskID string `json:"task_id"`
+}
+
+type GetServerLogsArgs struct {
+	Lines    int    `json:"lines,omitempty"`
+	Stream   string `json:"stream,omitempty"`
+	Contains string `json:"contains,omitempty"`
+}
+
+type GetProfileArgs struct {
+	IncludeHTML bool `json:"include_html,omitempty"`
+}
+
+type GetAutomaticRepliesArgs struct {
+	Account string `json:"account,omitempty"`
+}
+
+type GetFreeBusyArgs struct {
+	Attendees   string `json:"attendees"`
+	Start       string `json:"start,omitempty"`
+	End         string `json:"end,omitempty"`
+	Interval    int    `json:"interval,omitempty"`
+	MinDuration int    `json:"min_duration,omitempty"`
+}
+
+type GetInboxAnalyticsArgs struct {
+	Start   string `json:"start,omitempty"`
+	End     string `json:"end,omitempty"`
+	Folder  string `json:"folder,omitempty"`
+	Account string `json:"account,omitempty"`
+	Top     int    `json:"top,omitempty"`
+}
+
+type SearchContactsArgs struct {
+	Query string `json:"query,omitempty"`
+	Limit int    `json:"limit,omitempty"`
+}
+
+type GetContactArgs struct {
+	ContactID string `json:"contact_id"`
+}
+
+type ResolveRecipientArgs struct {
+	Name        string `json:"name"`
+	ExpandLists *bool  `json:"expand_lists,omitempty"` // Defaults to true
+}
+
+type ReplyToMessageArgs struct {
+	MessageID string `json:"message_id"`
+	Body      string `json:"body"`
+	ReplyAll  bool   `json:"reply_all,omitempty"`
+}
+
+type ForwardMessageArgs struct {
+	MessageID string   `json:"message_id"`
+	To        []string `json:"to"`
+	Cc        []string `json:"cc,omitempty"`
+	Bcc       []string `json:"bcc,omitempty"`
+	Body      string   `json:"body,omitempty"`
+}
+
+// ListFoldersHandler handles the list_folders tool
+func ListFoldersHandler(manager Backend) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args ListFoldersArgs
+		argBytes, err := json.Marshal(request.Params.Arguments)
+		if err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Failed to marshal arguments"), nil
+		}
+		if err := json.Unmarshal(argBytes, &args); err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments"), nil
+		}
+
+		response, err := manager.ListFolders(args.Account)
+		if err != nil {
+			return shared.ErrorResultFromErr("Failed to list folders", err), nil
+		}
+
+		title := "Mail Folders"
+		if response.Account != "" {
+			title += " in " + response.Account
+		}
+
+		return mcp.NewToolResultText(fmt.Sprintf(`%s (%d):
+
+%s`, title, response.Count, formatFolderList(response.Folders))), nil
+	}
+}
+
+// HealthCheckHandler handles the health_check tool
+func HealthCheckHandler(manager Backend) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(formatHealthStatus(manager.HealthCheck(), time.Now())), nil
+	}
+}
+
+// ReconnectHandler handles the reconnect tool
+func ReconnectHandler(manager Backend) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if err := manager.Reconnect(); err != nil {
+			return shared.ErrorResultFromErr("Failed to reconnect", err), nil
+		}
+
+		return mcp.NewToolResultText("Reconnected.\n\n" + formatHealthStatus(manager.HealthCheck(), time.Now())), nil
+	}
+}
+
+// ListAccountsHandler handles the list_accounts tool
+func ListAccountsHandler(manager Backend) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		response, err := manager.ListAccounts()
+		if err != nil {
+			return shared.ErrorResultFromErr("Failed to list accounts", err), nil
+		}
+
+		return mcp.NewToolResultText(formatAccountList(response)), nil
+	}
 }
 
 // ListMessagesHandler handles the list_messages tool
-func ListMessagesHandler(manager *Manager) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func ListMessagesHandler(manager Backend) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		var args ListMessagesArgs
 		argBytes, err := json.Marshal(request.Params.Arguments)
 		if err != nil {
-			return mcp.NewToolResultError("Failed to marshal arguments"), nil
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Failed to marshal arguments"), nil
 		}
 		if err := json.Unmarshal(argBytes, &args); err != nil {
-			return mcp.NewToolResultError("Invalid arguments"), nil
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments"), nil
 		}
 
 		page := 1
@@ -37,17 +328,31 @@ func ListMessagesHandler(manager *Manager) func(ctx context.Context, request mcp
 			page = *args.Page
 		}
 
-		response, err := manager.ListMessages(page)
+		filters := ListFilters{
+			UnreadOnly:     args.UnreadOnly,
+			Importance:     strings.ToLower(args.Importance),
+			Category:       args.Category,
+			HasAttachments: args.HasAttachments,
+		}
+		response, err := manager.ListMessages(page, args.Folder, args.Account, filters)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to list messages: %v", err)), nil
+			return shared.ErrorResultFromErr("Failed to list messages", err), nil
+		}
+
+		folderName := response.Folder
+		if folderName == "" {
+			folderName = "Inbox"
+		}
+		if !filters.IsEmpty() {
+			folderName += " matching " + formatListFilters(filters)
 		}
 
-		return mcp.NewToolResultText(fmt.Sprintf(`Messages (Page %d of %d):
+		return mcp.NewToolResultText(fmt.Sprintf(`Messages in %s (Page %d of %d):
 
 Total Messages: %d
 Current Page: %d messages
 
-`, response.Pagination.Page,
+`, folderName, response.Pagination.Page,
 			(response.Pagination.Total+response.Pagination.PageSize-1)/response.Pagination.PageSize,
 			response.Pagination.Total,
 			len(response.Messages)) +
@@ -55,25 +360,102 @@ Current Page: %d messages
 	}
 }
 
+// ListMessagesSinceHandler handles the list_messages_since tool
+func ListMessagesSinceHandler(manager Backend) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args ListMessagesSinceArgs
+		argBytes, err := json.Marshal(request.Params.Arguments)
+		if err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Failed to marshal arguments"), nil
+		}
+		if err := json.Unmarshal(argBytes, &args); err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments"), nil
+		}
+
+		if args.Since == "" {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "since parameter is required"), nil
+		}
+		since, err := parseSince(args.Since, time.Now())
+		if err != nil {
+			return shared.ErrorResult(err), nil
+		}
+
+		page := 1
+		if args.Page != nil {
+			page = *args.Page
+		}
+
+		response, err := manager.ListMessagesSince(since, page, args.Folder, args.Account)
+		if err != nil {
+			return shared.ErrorResultFromErr("Failed to list messages", err), nil
+		}
+
+		folderName := response.Folder
+		if folderName == "" {
+			folderName = "Inbox"
+		}
+
+		totalPages := 1
+		if response.Pagination.PageSize > 0 && response.Pagination.Total > 0 {
+			totalPages = (response.Pagination.Total + response.Pagination.PageSize - 1) / response.Pagination.PageSize
+		}
+
+		return mcp.NewToolResultText(fmt.Sprintf(`Messages in %s received since %s (Page %d of %d):
+
+Total Messages: %d
+Current Page: %d messages
+
+`, folderName, since.Format(time.RFC3339), response.Pagination.Page, totalPages,
+			response.Pagination.Total,
+			len(response.Messages)) +
+			formatMessageList(response.Messages)), nil
+	}
+}
+
+// parseSince parses a list_messages_since point in time: a relative duration
+// back from now ("24h", "90m", "7d"), a YYYY-MM-DD date or an RFC 3339 timestamp
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n > 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if duration, err := time.ParseDuration(value); err == nil {
+		if duration <= 0 {
+			return time.Time{}, fmt.Errorf("invalid since: duration must be positive")
+		}
+		return now.Add(-duration), nil
+	}
+
+	since, _, err := parseCalendarDate(value, now.Location())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid since: %q is not a duration (e.g. 24h, 7d), YYYY-MM-DD date or RFC 3339 timestamp", value)
+	}
+	if since.After(now) {
+		return time.Time{}, fmt.Errorf("invalid since: %s is in the future", value)
+	}
+	return since, nil
+}
+
 // GetMessageHandler handles the get_message tool
-func GetMessageHandler(manager *Manager) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func GetMessageHandler(manager Backend) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		var args GetMessageArgs
 		argBytes, err := json.Marshal(request.Params.Arguments)
 		if err != nil {
-			return mcp.NewToolResultError("Failed to marshal arguments"), nil
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Failed to marshal arguments"), nil
 		}
 		if err := json.Unmarshal(argBytes, &args); err != nil {
-			return mcp.NewToolResultError("Invalid arguments"), nil
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments"), nil
 		}
 
 		if args.MessageID == "" {
-			return mcp.NewToolResultError("message_id parameter is required"), nil
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "message_id parameter is required"), nil
 		}
 
 		message, err := manager.GetMessage(args.MessageID)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to get message: %v", err)), nil
+			return shared.ErrorResultFromErr("Failed to get message", err), nil
 		}
 
 		result := fmt.Sprintf(`Message Details:
@@ -85,147 +467,2173 @@ Size: %d bytes
 Unread: %t
 Has Attachments: %t (%d attachments)
 Importance: %s
+Flag: %s
+Categories: %s
 
 Preview:
 %s`, message.Subject, message.Sender, message.SenderEmail,
 			message.ReceivedTime.Format("2006-01-02 15:04:05"),
 			message.Size, message.Unread, message.HasAttachments, message.AttachmentCount,
-			getImportanceString(message.Importance), message.BodyPreview)
+			getImportanceString(message.Importance), formatFlagStatus(message.FlagStatus),
+			formatCategories(message.Categories), message.BodyPreview)
 
 		return mcp.NewToolResultText(result), nil
 	}
 }
 
-// GetMessageBodyHandler handles the get_message_body tool
-func GetMessageBodyHandler(manager *Manager) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+// Limits on the text get_message_body returns in one call
+const (
+	defaultBodyChars = 20_000
+	maxBodyChars     = 100_000
+)
+
+// GetMessageBodyHandler handles the get_message_body tool. Long bodies are
+// returned in windows of max_chars characters starting at offset.
+func GetMessageBodyHandler(manager Backend) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		var args GetMessageArgs
+		var args GetMessageBodyArgs
 		argBytes, err := json.Marshal(request.Params.Arguments)
 		if err != nil {
-			return mcp.NewToolResultError("Failed to marshal arguments"), nil
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Failed to marshal arguments"), nil
 		}
 		if err := json.Unmarshal(argBytes, &args); err != nil {
-			return mcp.NewToolResultError("Invalid arguments"), nil
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments"), nil
 		}
 
 		if args.MessageID == "" {
-			return mcp.NewToolResultError("message_id parameter is required"), nil
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "message_id parameter is required"), nil
+		}
+
+		if err := args.checkWindow(); err != nil {
+			return shared.ErrorResult(err), nil
 		}
 
 		response, err := manager.GetMessageBody(args.MessageID)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to get message body: %v", err)), nil
+			return shared.ErrorResultFromErr("Failed to get message body", err), nil
 		}
 
+		text, total := bodyWindow(response.BodyText, args.Offset, args.MaxChars)
+		if args.Offset > 0 && args.Offset >= total {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "offset %d is past the end of the body (%d characters)", args.Offset, total), nil
+		}
+		end := args.Offset + utf8.RuneCountInString(text)
+
 		result := fmt.Sprintf(`Message Body (Readable Text):
 
 Word Count: %d
 Character Count: %d
-
-Content:
-%s`, response.WordCount, response.CharCount, response.BodyText)
+`, response.WordCount, total)
+		if args.Offset > 0 || end < total {
+			result += fmt.Sprintf("Showing: characters %d-%d of %d\n", args.Offset, end, total)
+		}
+		result += "\nContent:\n" + text
+		if end < total {
+			result += fmt.Sprintf("\n\n[%d more characters; call again with offset=%d to continue]", total-end, end)
+		}
 
 		return mcp.NewToolResultText(result), nil
 	}
 }
 
+// checkWindow validates the offset and applies the default max_chars
+func (args *GetMessageBodyArgs) checkWindow() error {
+	if args.Offset < 0 {
+		return shared.NewError(shared.CodeInvalidArgument, "offset must not be negative")
+	}
+	if args.MaxChars < 0 || args.MaxChars > maxBodyChars {
+		return shared.NewError(shared.CodeInvalidArgument, "max_chars must be between 1 and %d", maxBodyChars)
+	}
+	if args.MaxChars == 0 {
+		args.MaxChars = defaultBodyChars
+	}
+	return nil
+}
+
+// bodyWindow returns up to maxChars characters of text starting at the
+// offset'th character, and the length of text in characters. It slices the
+// string in place, so enormous bodies aren't copied.
+func bodyWindow(text string, offset, maxChars int) (string, int) {
+	start, end, count := len(text), len(text), 0
+	for i := range text {
+		if count == offset {
+			start = i
+		}
+		if count == offset+maxChars {
+			end = i
+			break
+		}
+		count++
+	}
+	if end < len(text) {
+		count += utf8.RuneCountInString(text[end:])
+	}
+	return text[start:end], count
+}
+
 // GetMessageBodyRawHandler handles the get_message_body_raw tool
-func GetMessageBodyRawHandler(manager *Manager) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func GetMessageBodyRawHandler(manager Backend) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		var args GetMessageArgs
+		var args GetMessageBodyArgs
 		argBytes, err := json.Marshal(request.Params.Arguments)
 		if err != nil {
-			return mcp.NewToolResultError("Failed to marshal arguments"), nil
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Failed to marshal arguments"), nil
 		}
 		if err := json.Unmarshal(argBytes, &args); err != nil {
-			return mcp.NewToolResultError("Invalid arguments"), nil
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments"), nil
 		}
 
 		if args.MessageID == "" {
-			return mcp.NewToolResultError("message_id parameter is required"), nil
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "message_id parameter is required"), nil
+		}
+
+		if err := args.checkWindow(); err != nil {
+			return shared.ErrorResult(err), nil
 		}
 
 		response, err := manager.GetMessageBodyRaw(args.MessageID)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to get raw message body: %v", err)), nil
+			return shared.ErrorResultFromErr("Failed to get raw message body", err), nil
+		}
+
+		// The same window applies to both bodies
+		text, textTotal := bodyWindow(response.BodyText, args.Offset, args.MaxChars)
+		html, htmlTotal := bodyWindow(response.BodyHTML, args.Offset, args.MaxChars)
+		total := max(textTotal, htmlTotal)
+		if args.Offset > 0 && args.Offset >= total {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "offset %d is past the end of the body (%d characters)", args.Offset, total), nil
+		}
+		heading := func(name string, window string, total int) string {
+			start := min(args.Offset, total)
+			end := start + utf8.RuneCountInString(window)
+			if start > 0 || end < total {
+				return fmt.Sprintf("%s (characters %d-%d of %d):", name, start, end, total)
+			}
+			return name + ":"
 		}
 
 		result := fmt.Sprintf(`Message Body (Raw):
 
 Format: %s
 
-Plain Text Body:
+%s
 %s
 
-HTML Body:
-%s`, response.Format, response.BodyText, response.BodyHTML)
+%s
+%s`, response.Format, heading("Plain Text Body", text, textTotal), text, heading("HTML Body", html, htmlTotal), html)
+		if end := args.Offset + args.MaxChars; end < total {
+			result += fmt.Sprintf("\n\n[%d more characters; call again with offset=%d to continue]", total-end, end)
+		}
 
 		return mcp.NewToolResultText(result), nil
 	}
 }
 
+// GetConversationHandler handles the get_conversation tool
+func GetConversationHandler(manager Backend) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args GetMessageArgs
+		argBytes, err := json.Marshal(request.Params.Arguments)
+		if err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Failed to marshal arguments"), nil
+		}
+		if err := json.Unmarshal(argBytes, &args); err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments"), nil
+		}
+
+		if args.MessageID == "" {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "message_id parameter is required"), nil
+		}
+
+		response, err := manager.GetConversation(args.MessageID)
+		if err != nil {
+			return shared.ErrorResultFromErr("Failed to get conversation", err), nil
+		}
+
+		return mcp.NewToolResultText(formatConversation(response)), nil
+	}
+}
+
 // SearchMessagesHandler handles the search_messages tool
-func SearchMessagesHandler(manager *Manager) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func SearchMessagesHandler(manager Backend) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		var args SearchMessagesArgs
 		argBytes, err := json.Marshal(request.Params.Arguments)
 		if err != nil {
-			return mcp.NewToolResultError("Failed to marshal arguments"), nil
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Failed to marshal arguments"), nil
 		}
 		if err := json.Unmarshal(argBytes, &args); err != nil {
-			return mcp.NewToolResultError("Invalid arguments"), nil
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments"), nil
+		}
+
+		filters, err := parseSearchFilters(args, time.Now())
+		if err != nil {
+			return shared.ErrorResult(err), nil
+		}
+		if args.Query == "" && filters.IsEmpty() {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "query parameter or at least one filter is required"), nil
 		}
 
-		if args.Query == "" {
-			return mcp.NewToolResultError("query parameter is required"), nil
+		page := 1
+		if args.Page != nil {
+			page = *args.Page
 		}
 
-		response, err := manager.SearchMessages(args.Query)
+		response, err := manager.SearchMessages(args.Query, args.Folder, args.Account, filters, page, args.PageSize)
 		if err != nil {
-			return mcp.NewToolResultError(fmt.Sprintf("Failed to search messages: %v", err)), nil
+			return shared.ErrorResultFromErr("Failed to search messages", err), nil
+		}
+
+		title := "Search Results"
+		if response.Query != "" {
+			title = fmt.Sprintf(`Search Results for "%s"`, response.Query)
+		}
+		if description := describeSearchFilters(filters); description != "" {
+			title += " [" + description + "]"
 		}
 
-		result := fmt.Sprintf(`Search Results for "%s":
+		pagination := response.Pagination
+		totalPages := 1
+		if pagination.PageSize > 0 && pagination.Total > 0 {
+			totalPages = (pagination.Total + pagination.PageSize - 1) / pagination.PageSize
+		}
+
+		result := fmt.Sprintf(`%s (Page %d of %d, most relevant first):
 
-Found %d messages:
+Total Matches: %d
+Current Page: %d messages
 
-%s`, response.Query, response.Count, formatMessageList(response.Results))
+%s`, title, pagination.Page, totalPages, pagination.Total, response.Count, formatMessageList(response.Results))
+		if pagination.HasNext {
+			result += fmt.Sprintf("\nMore results available: request page %d.", pagination.Page+1)
+		}
+		if response.Truncated {
+			result += "\nOnly the most relevant messages were searched, so more may match; narrow the search to see the rest."
+		}
 
 		return mcp.NewToolResultText(result), nil
 	}
 }
 
-// Helper function to format a list of messages
-func formatMessageList(messages []Message) string {
-	if len(messages) == 0 {
-		return "No messages found."
+// UpdateMessageHandler handles the update_message tool
+func UpdateMessageHandler(manager Backend) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args UpdateMessageArgs
+		argBytes, err := json.Marshal(request.Params.Arguments)
+		if err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Failed to marshal arguments"), nil
+		}
+		if err := json.Unmarshal(argBytes, &args); err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments"), nil
+		}
+
+		if args.MessageID == "" {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "message_id parameter is required"), nil
+		}
+		if args.Unread == nil && args.Flag == "" && args.Categories == nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "at least one of unread, flag or categories is required"), nil
+		}
+
+		response, err := manager.UpdateMessage(args.MessageID, UpdateMessageRequest{
+			Unread:     args.Unread,
+			Flag:       args.Flag,
+			Categories: args.Categories,
+		})
+		if err != nil {
+			return shared.ErrorResultFromErr("Failed to update message", err), nil
+		}
+
+		return mcp.NewToolResultText(fmt.Sprintf(`Message updated.
+
+ID: %s
+Unread: %t
+Flag: %s
+Categories: %s`, response.ID, response.Unread, response.FlagStatus, formatCategories(response.Categories))), nil
 	}
+}
 
-	result := ""
-	for i, msg := range messages {
-		unreadStatus := ""
-		if msg.Unread {
-			unreadStatus = " [UNREAD]"
+// MoveMessageHandler handles the move_message tool
+func MoveMessageHandler(manager Backend) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args MoveMessageArgs
+		argBytes, err := json.Marshal(request.Params.Arguments)
+		if err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Failed to marshal arguments"), nil
+		}
+		if err := json.Unmarshal(argBytes, &args); err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments"), nil
 		}
 
-		attachmentInfo := ""
-		if msg.HasAttachments {
-			attachmentInfo = fmt.Sprintf(" 📎(%d)", msg.AttachmentCount)
+		if args.MessageID == "" {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "message_id parameter is required"), nil
+		}
+		if args.Folder == "" {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "folder parameter is required"), nil
 		}
 
-		result += fmt.Sprintf(`%d. %s%s%s
-   From: %s <%s>
-   Received: %s
-   Size: %d bytes
-   ID: %s
+		response, err := manager.MoveMessage(args.MessageID, args.Folder, args.Account)
+		if err != nil {
+			return shared.ErrorResultFromErr("Failed to move message", err), nil
+		}
 
-`, i+1, msg.Subject, unreadStatus, attachmentInfo,
-			msg.Sender, msg.SenderEmail,
-			msg.ReceivedTime.Format("2006-01-02 15:04:05"),
-			msg.Size, msg.ID)
+		return mcp.NewToolResultText(fmt.Sprintf(`Message moved to %s.
+
+New ID: %s
+(The previous ID %s is no longer valid.)`, response.Folder, response.ID, response.PreviousID)), nil
 	}
+}
 
-	return result
+// DeleteMessageHandler handles the delete_message tool
+func DeleteMessageHandler(manager Backend) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args DeleteMessageArgs
+		argBytes, err := json.Marshal(request.Params.Arguments)
+		if err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Failed to marshal arguments"), nil
+		}
+		if err := json.Unmarshal(argBytes, &args); err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments"), nil
+		}
+
+		if args.MessageID == "" {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "message_id parameter is required"), nil
+		}
+
+		response, err := manager.DeleteMessage(args.MessageID, args.Permanent)
+		if err != nil {
+			return shared.ErrorResultFromErr("Failed to delete message", err), nil
+		}
+
+		if response.Permanent {
+			return mcp.NewToolResultText(fmt.Sprintf("Message %s permanently deleted.", response.ID)), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Message %s moved to %s.", response.ID, response.Folder)), nil
+	}
+}
+
+// BulkUpdateMessagesHandler handles the bulk_update_messages tool
+func BulkUpdateMessagesHandler(manager Backend) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args BulkUpdateMessagesArgs
+		argBytes, err := json.Marshal(request.Params.Arguments)
+		if err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Failed to marshal arguments"), nil
+		}
+		if err := json.Unmarshal(argBytes, &args); err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments"), nil
+		}
+
+		filters, err := parseSearchFilters(SearchMessagesArgs{
+			From:           args.From,
+			To:             args.To,
+			After:          args.After,
+			Before:         args.Before,
+			HasAttachments: args.HasAttachments,
+			Unread:         args.Unread,
+			Importance:     args.Importance,
+		}, time.Now())
+		if err != nil {
+			return shared.ErrorResult(err), nil
+		}
+
+		result, err := BulkUpdateMessages(manager, BulkUpdateRequest{
+			Action:      args.Action,
+			MessageIDs:  args.MessageIDs,
+			Query:       args.Query,
+			Folder:      args.Folder,
+			Account:     args.Account,
+			Filters:     filters,
+			Destination: args.Destination,
+			Categories:  args.Categories,
+			Permanent:   args.Permanent,
+			Limit:       args.Limit,
+			DryRun:      args.DryRun,
+		})
+		if err != nil {
+			return shared.ErrorResultFromErr("Failed to update messages", err), nil
+		}
+
+		return mcp.NewToolResultText(formatBulkUpdateResult(result)), nil
+	}
+}
+
+// PollNewMessagesHandler handles the poll_new_messages tool
+func PollNewMessagesHandler(manager Backend) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args PollNewMessagesArgs
+		argBytes, err := json.Marshal(request.Params.Arguments)
+		if err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Failed to marshal arguments"), nil
+		}
+		if err := json.Unmarshal(argBytes, &args); err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments"), nil
+		}
+
+		now := time.Now()
+		filters, err := parseSearchFilters(SearchMessagesArgs{
+			From:           args.From,
+			To:             args.To,
+			HasAttachments: args.HasAttachments,
+			Unread:         args.Unread,
+			Importance:     args.Importance,
+		}, now)
+		if err != nil {
+			return shared.ErrorResult(err), nil
+		}
+		var since time.Time
+		if args.Since != "" {
+			if args.Cursor != "" {
+				return shared.ErrorResultf(shared.CodeInvalidArgument, "give either cursor or since, not both"), nil
+			}
+			if since, err = parseSince(args.Since, now); err != nil {
+				return shared.ErrorResult(err), nil
+			}
+		}
+
+		result, err := PollNewMessages(ctx, manager, PollRequest{
+			Cursor:  args.Cursor,
+			Since:   since,
+			Query:   args.Query,
+			Folder:  args.Folder,
+			Account: args.Account,
+			Filters: filters,
+			Limit:   args.Limit,
+			Wait:    time.Duration(args.WaitSeconds) * time.Second,
+		})
+		if err != nil {
+			return shared.ErrorResultFromErr("Failed to poll for new messages", err), nil
+		}
+
+		return mcp.NewToolResultText(formatPollResult(result, args.Folder)), nil
+	}
+}
+
+// CreateDraftHandler handles the create_draft tool
+func CreateDraftHandler(manager Backend, paths *sandbox.Sandbox) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args CreateDraftArgs
+		argBytes, err := json.Marshal(request.Params.Arguments)
+		if err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Failed to marshal arguments"), nil
+		}
+		if err := json.Unmarshal(argBytes, &args); err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments"), nil
+		}
+
+		if args.Subject == "" {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "subject parameter is required"), nil
+		}
+		// Attaching uploads the file to the mailbox, so only files under the
+		// save roots may be attached, and none without them
+		if len(args.Attachments) > 0 && paths == nil {
+			return shared.ErrorResultf(shared.CodeAccessDenied, "Attachments are disabled; start the server with --save-roots to attach files from those directories"), nil
+		}
+		for _, path := range args.Attachments {
+			if err := paths.Check(path); err != nil {
+				return shared.ErrorResult(err), nil
+			}
+		}
+
+		response, err := manager.CreateDraft(DraftRequest{
+			To:          args.To,
+			Cc:          args.Cc,
+			Bcc:         args.Bcc,
+			Subject:     args.Subject,
+			Body:        args.Body,
+			HTML:        args.HTML,
+			Attachments: args.Attachments,
+		})
+		if err != nil {
+			return shared.ErrorResultFromErr("Failed to create draft", err), nil
+		}
+
+		return mcp.NewToolResultText(fmt.Sprintf(`Draft saved to %s (not sent).
+
+ID: %s
+Subject: %s
+To: %s
+Attachments: %d`, response.Folder, response.ID, response.Subject, response.To, response.AttachmentCount)), nil
+	}
+}
+
+// ListAttachmentsHandler handles the list_attachments tool
+func ListAttachmentsHandler(manager Backend) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args ListAttachmentsArgs
+		argBytes, err := json.Marshal(request.Params.Arguments)
+		if err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Failed to marshal arguments"), nil
+		}
+		if err := json.Unmarshal(argBytes, &args); err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments"), nil
+		}
+
+		if args.MessageID == "" {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "message_id parameter is required"), nil
+		}
+		size := args.ThumbnailSize
+		if size == 0 {
+			size = defaultAttachmentThumbnailSize
+		}
+		if size < 16 || size > maxAttachmentThumbnailSize {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "thumbnail_size must be between 16 and %d", maxAttachmentThumbnailSize), nil
+		}
+
+		response, err := manager.ListAttachments(args.MessageID)
+		if err != nil {
+			return shared.ErrorResultFromErr("Failed to list attachments", err), nil
+		}
+
+		text := formatAttachmentList(response.Attachments)
+		if !args.Thumbnails {
+			return mcp.NewToolResultText(text), nil
+		}
+		return attachmentPreviewResult(manager, args.MessageID, response.Attachments, size, text), nil
+	}
+}
+
+// attachmentPreviewResult follows an attachment list with a thumbnail of each
+// image attachment, up to maxAttachmentPreviews. Attachments that can't be
+// previewed are noted in the text rather than failing the call.
+func attachmentPreviewResult(manager Backend, messageID string, attachments []Attachment, size int, text string) *mcp.CallToolResult {
+	var previews []mcp.Content
+	var notes []string
+	shown := 0
+	for _, attachment := range attachments {
+		if !isPreviewableAttachment(attachment) {
+			continue
+		}
+		if shown == maxAttachmentPreviews {
+			notes = append(notes, fmt.Sprintf("Only the first %d images are previewed.", maxAttachmentPreviews))
+			break
+		}
+		content, err := manager.GetAttachmentContent(messageID, attachment.Index)
+		if err != nil {
+			notes = append(notes, fmt.Sprintf("%d. %s: no preview (%v)", attachment.Index, attachment.FileName, err))
+			continue
+		}
+		thumbnail, mimeType, err := attachmentThumbnail(content.Content, size)
+		if err != nil {
+			notes = append(notes, fmt.Sprintf("%d. %s: no preview (%v)", attachment.Index, attachment.FileName, err))
+			continue
+		}
+		previews = append(previews,
+			mcp.NewTextContent(fmt.Sprintf("Preview of %d. %s:", attachment.Index, attachment.FileName)),
+			mcp.NewImageContent(base64.StdEncoding.EncodeToString(thumbnail), mimeType))
+		shown++
+	}
+
+	if shown == 0 && len(notes) == 0 {
+		notes = append(notes, "No image attachments to preview.")
+	}
+	if len(notes) > 0 {
+		text += "\n" + strings.Join(notes, "\n") + "\n"
+	}
+	return &mcp.CallToolResult{Content: append([]mcp.Content{mcp.NewTextContent(text)}, previews...)}
+}
+
+// SaveAttachmentHandler handles the save_attachment tool; a non-nil paths
+// restricts the directories attachments are saved to, and replaces the temp
+// directory default with the first allowed root
+func SaveAttachmentHandler(manager Backend, paths *sandbox.Sandbox) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args SaveAttachmentArgs
+		argBytes, err := json.Marshal(request.Params.Arguments)
+		if err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Failed to marshal arguments"), nil
+		}
+		if err := json.Unmarshal(argBytes, &args); err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments"), nil
+		}
+
+		if args.MessageID == "" {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "message_id parameter is required"), nil
+		}
+		if args.Index == 0 {
+			args.Index = 1
+		}
+		if args.Directory == "" && paths != nil {
+			args.Directory = paths.Roots()[0]
+		}
+		if args.Directory != "" {
+			if err := paths.Check(args.Directory); err != nil {
+				return shared.ErrorResult(err), nil
+			}
+		}
+
+		response, err := manager.SaveAttachment(args.MessageID, args.Index, args.Directory)
+		if err != nil {
+			return shared.ErrorResultFromErr("Failed to save attachment", err), nil
+		}
+
+		return mcp.NewToolResultText(fmt.Sprintf(`Attachment saved.
+
+File: %s
+Path: %s
+Size: %d bytes`, response.FileName, response.Path, response.Size)), nil
+	}
+}
+
+// ExportMessageHandler handles the export_message tool; a non-nil paths
+// restricts where messages are saved
+func ExportMessageHandler(manager Backend, paths *sandbox.Sandbox) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args ExportMessageArgs
+		argBytes, err := json.Marshal(request.Params.Arguments)
+		if err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Failed to marshal arguments"), nil
+		}
+		if err := json.Unmarshal(argBytes, &args); err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments"), nil
+		}
+
+		if args.MessageID == "" {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "message_id parameter is required"), nil
+		}
+
+		if args.Path != "" {
+			if err := paths.Check(args.Path); err != nil {
+				return shared.ErrorResult(err), nil
+			}
+		}
+
+		response, err := manager.ExportMessage(args.MessageID, args.Format, args.Path)
+		if err != nil {
+			return shared.ErrorResultFromErr("Failed to export message", err), nil
+		}
+
+		if response.Path == "" {
+			return mcp.NewToolResultText(fmt.Sprintf("Message exported as .%s (%d bytes, base64-encoded):\n\n%s",
+				response.Format, response.Size, response.Content)), nil
+		}
+
+		return mcp.NewToolResultText(fmt.Sprintf(`Message exported.
+
+Format: %s
+File: %s
+Path: %s
+Size: %d bytes`, response.Format, response.FileName, response.Path, response.Size)), nil
+	}
+}
+
+// ExportMessagesHandler handles the export_messages tool; a non-nil paths
+// restricts where CSV files are saved
+func ExportMessagesHandler(manager Backend, paths *sandbox.Sandbox) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args ExportMessagesArgs
+		argBytes, err := json.Marshal(request.Params.Arguments)
+		if err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Failed to marshal arguments"), nil
+		}
+		if err := json.Unmarshal(argBytes, &args); err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments"), nil
+		}
+
+		if args.Path == "" {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "path parameter is required"), nil
+		}
+		if err := paths.Check(args.Path); err != nil {
+			return shared.ErrorResult(err), nil
+		}
+		filters, err := parseSearchFilters(SearchMessagesArgs{
+			From:           args.From,
+			To:             args.To,
+			After:          args.After,
+			Before:         args.Before,
+			HasAttachments: args.HasAttachments,
+			Unread:         args.Unread,
+			Importance:     args.Importance,
+		}, time.Now())
+		if err != nil {
+			return shared.ErrorResult(err), nil
+		}
+		if args.Query == "" && filters.IsEmpty() {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "query parameter or at least one filter is required"), nil
+		}
+
+		result, err := ExportMessages(manager, ExportRequest{
+			Query:   args.Query,
+			Folder:  args.Folder,
+			Account: args.Account,
+			Filters: filters,
+			Path:    args.Path,
+			Limit:   args.Limit,
+		})
+		if err != nil {
+			return shared.ErrorResultFromErr("Failed to export messages", err), nil
+		}
+
+		text := fmt.Sprintf(`Messages exported.
+
+Messages: %d
+File: %s
+Path: %s
+Size: %d bytes`, result.Messages, result.FileName, result.Path, result.Size)
+		if result.Truncated {
+			text += fmt.Sprintf("\n\nMore messages matched; only the newest %d were exported. Raise limit or narrow the search to export the rest.", result.Messages)
+		}
+		return mcp.NewToolResultText(text), nil
+	}
+}
+
+// defaultCalendarRange is how far list_calendar_events looks ahead when no end is given
+const defaultCalendarRange = 7 * 24 * time.Hour
+
+// ListCalendarEventsHandler handles the list_calendar_events tool
+func ListCalendarEventsHandler(manager Backend) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args ListCalendarEventsArgs
+		argBytes, err := json.Marshal(request.Params.Arguments)
+		if err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Failed to marshal arguments"), nil
+		}
+		if err := json.Unmarshal(argBytes, &args); err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments"), nil
+		}
+
+		start, end, err := parseCalendarRange(args.Start, args.End, time.Now())
+		if err != nil {
+			return shared.ErrorResult(err), nil
+		}
+
+		response, err := manager.ListCalendarEvents(start, end, args.Limit)
+		if err != nil {
+			return shared.ErrorResultFromErr("Failed to list calendar events", err), nil
+		}
+
+		result := fmt.Sprintf("Calendar events from %s to %s:\n\n%s",
+			start.Format("2006-01-02 15:04"), end.Format("2006-01-02 15:04"), formatEventList(response.Events))
+		if response.Truncated {
+			result += fmt.Sprintf("\n(Showing the first %d events; narrow the date range or raise the limit to see more.)", response.Count)
+		}
+
+		return mcp.NewToolResultText(result), nil
+	}
+}
+
+// GetEventHandler handles the get_event tool
+func GetEventHandler(manager Backend) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args GetEventArgs
+		argBytes, err := json.Marshal(request.Params.Arguments)
+		if err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Failed to marshal arguments"), nil
+		}
+		if err := json.Unmarshal(argBytes, &args); err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments"), nil
+		}
+
+		if args.EventID == "" {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "event_id parameter is required"), nil
+		}
+
+		event, err := manager.GetEvent(args.EventID)
+		if err != nil {
+			return shared.ErrorResultFromErr("Failed to get event", err), nil
+		}
+
+		return mcp.NewToolResultText(formatEventDetails(event)), nil
+	}
+}
+
+// ListTasksHandler handles the list_tasks tool
+func ListTasksHandler(manager Backend) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args ListTasksArgs
+		argBytes, err := json.Marshal(request.Params.Arguments)
+		if err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Failed to marshal arguments"), nil
+		}
+		if err := json.Unmarshal(argBytes, &args); err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments"), nil
+		}
+
+		response, err := manager.ListTasks(args.Status, args.Limit)
+		if err != nil {
+			return shared.ErrorResultFromErr("Failed to list tasks", err), nil
+		}
+
+		result := formatTaskList(response.Tasks)
+		if response.Truncated {
+			result += fmt.Sprintf("\n(Showing the first %d tasks; raise the limit to see more.)", response.Count)
+		}
+
+		return mcp.NewToolResultText(result), nil
+	}
+}
+
+// CreateTaskHandler handles the create_task tool
+func CreateTaskHandler(manager Backend) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args CreateTaskArgs
+		argBytes, err := json.Marshal(request.Params.Arguments)
+		if err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Failed to marshal arguments"), nil
+		}
+		if err := json.Unmarshal(argBytes, &args); err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments"), nil
+		}
+
+		if args.Subject == "" && args.MessageID == "" {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "subject or message_id parameter is required"), nil
+		}
+
+		task, err := manager.CreateTask(CreateTaskRequest{
+			Subject:    args.Subject,
+			Body:       args.Body,
+			DueDate:    args.DueDate,
+			StartDate:  args.StartDate,
+			Importance: args.Importance,
+			Categories: args.Categories,
+			MessageID:  args.MessageID,
+		})
+		if err != nil {
+			return shared.ErrorResultFromErr("Failed to create task", err), nil
+		}
+
+		action := "Task created."
+		if task.Kind == TaskKindMessage {
+			action = "Message flagged for follow-up."
+		}
+		return mcp.NewToolResultText(action + "\n\n" + formatTask(task)), nil
+	}
+}
+
+// CompleteTaskHandler handles the complete_task tool
+func CompleteTaskHandler(manager Backend) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args CompleteTaskArgs
+		argBytes, err := json.Marshal(request.Params.Arguments)
+		if err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Failed to marshal arguments"), nil
+		}
+		if err := json.Unmarshal(argBytes, &args); err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments"), nil
+		}
+
+		if args.TaskID == "" {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "task_id parameter is required"), nil
+		}
+
+		task, err := manager.CompleteTask(args.TaskID)
+		if err != nil {
+			return shared.ErrorResultFromErr("Failed to complete task", err), nil
+		}
+
+		return mcp.NewToolResultText("Task completed.\n\n" + formatTask(task)), nil
+	}
+}
+
+// GetServerLogsHandler handles the get_server_logs tool
+func GetServerLogsHandler(manager Backend) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args GetServerLogsArgs
+		argBytes, err := json.Marshal(request.Params.Arguments)
+		if err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Failed to marshal arguments"), nil
+		}
+		if err := json.Unmarshal(argBytes, &args); err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments"), nil
+		}
+
+		if args.Lines < 0 {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "lines must not be negative"), nil
+		}
+		if args.Lines == 0 {
+			args.Lines = 100
+		}
+		if args.Stream == "all" {
+			args.Stream = ""
+		}
+
+		response, err := manager.ServerLogs(args.Lines, args.Stream, args.Contains)
+		if err != nil {
+			return shared.ErrorResultFromErr("Failed to get server logs", err), nil
+		}
+
+		return mcp.NewToolResultText(formatServerLogs(response)), nil
+	}
+}
+
+// GetAutomaticRepliesHandler handles the get_automatic_replies tool
+func GetAutomaticRepliesHandler(manager Backend) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args GetAutomaticRepliesArgs
+		argBytes, err := json.Marshal(request.Params.Arguments)
+		if err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Failed to marshal arguments"), nil
+		}
+		if err := json.Unmarshal(argBytes, &args); err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments"), nil
+		}
+
+		response, err := manager.GetAutomaticReplies(args.Account)
+		if err != nil {
+			return shared.ErrorResultFromErr("Failed to get automatic replies", err), nil
+		}
+
+		state := "OFF"
+		if response.Enabled {
+			state = "ON"
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Automatic replies for %s: %s", response.Store, state)), nil
+	}
+}
+
+// GetProfileHandler handles the get_profile tool
+func GetProfileHandler(manager Backend) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args GetProfileArgs
+		argBytes, err := json.Marshal(request.Params.Arguments)
+		if err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Failed to marshal arguments"), nil
+		}
+		if err := json.Unmarshal(argBytes, &args); err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments"), nil
+		}
+
+		profile, err := manager.GetProfile()
+		if err != nil {
+			return shared.ErrorResultFromErr("Failed to get profile", err), nil
+		}
+
+		return mcp.NewToolResultText(formatUserProfile(profile, args.IncludeHTML)), nil
+	}
+}
+
+// defaultMeetingDuration is the shortest common free window get_free_busy reports by default
+const defaultMeetingDuration = 30 * time.Minute
+
+// GetFreeBusyHandler handles the get_free_busy tool
+func GetFreeBusyHandler(manager Backend) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args GetFreeBusyArgs
+		argBytes, err := json.Marshal(request.Params.Arguments)
+		if err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Failed to marshal arguments"), nil
+		}
+		if err := json.Unmarshal(argBytes, &args); err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments"), nil
+		}
+
+		attendees := splitAttendees(args.Attendees)
+		if len(attendees) == 0 {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "attendees parameter is required"), nil
+		}
+
+		start, end, err := parseCalendarRange(args.Start, args.End, time.Now())
+		if err != nil {
+			return shared.ErrorResult(err), nil
+		}
+
+		minDuration := defaultMeetingDuration
+		if args.MinDuration > 0 {
+			minDuration = time.Duration(args.MinDuration) * time.Minute
+		}
+
+		response, err := manager.GetFreeBusy(attendees, start, end, args.Interval)
+		if err != nil {
+			return shared.ErrorResultFromErr("Failed to get free/busy", err), nil
+		}
+
+		return mcp.NewToolResultText(formatFreeBusy(response, minDuration)), nil
+	}
+}
+
+// GetInboxAnalyticsHandler handles the get_inbox_analytics tool
+func GetInboxAnalyticsHandler(manager Backend) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args GetInboxAnalyticsArgs
+		argBytes, err := json.Marshal(request.Params.Arguments)
+		if err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Failed to marshal arguments"), nil
+		}
+		if err := json.Unmarshal(argBytes, &args); err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments"), nil
+		}
+
+		now := time.Now()
+		start, end, err := parseAnalyticsRange(args.Start, args.End, now)
+		if err != nil {
+			return shared.ErrorResult(err), nil
+		}
+
+		analytics, err := GetInboxAnalytics(manager, AnalyticsRequest{
+			Folder:  args.Folder,
+			Account: args.Account,
+			Start:   start,
+			End:     end,
+			Top:     args.Top,
+		}, now)
+		if err != nil {
+			return shared.ErrorResultFromErr("Failed to get inbox analytics", err), nil
+		}
+
+		return mcp.NewToolResultText(formatInboxAnalytics(analytics)), nil
+	}
+}
+
+// parseAnalyticsRange turns the optional start and end arguments of
+// get_inbox_analytics into a time range. Start may be a relative duration as
+// for list_messages_since and defaults to the beginning of the day 30 days
+// ago; end defaults to now, and a date-only end includes that whole day.
+func parseAnalyticsRange(startArg, endArg string, now time.Time) (time.Time, time.Time, error) {
+	start := time.Date(now.Year(), now.Month(), now.Day()-defaultAnalyticsDays, 0, 0, 0, 0, now.Location())
+	if startArg != "" {
+		parsed, err := parseSince(startArg, now)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid start: %v", strings.TrimPrefix(err.Error(), "invalid since: "))
+		}
+		start = parsed
+	}
+
+	end := now
+	if endArg != "" {
+		parsed, dateOnly, err := parseCalendarDate(endArg, now.Location())
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid end: %v", err)
+		}
+		if dateOnly {
+			parsed = parsed.AddDate(0, 0, 1)
+		}
+		end = parsed
+	}
+
+	if !end.After(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("end must be after start")
+	}
+	return start, end, nil
+}
+
+// splitAttendees splits a comma- or semicolon-separated attendee list
+func splitAttendees(value string) []string {
+	var attendees []string
+	for _, part := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ';' }) {
+		if attendee := strings.TrimSpace(part); attendee != "" {
+			attendees = append(attendees, attendee)
+		}
+	}
+	return attendees
+}
+
+// ListRulesAndCategoriesHandler handles the list_rules_and_categories tool. Rules
+// can be unavailable (e.g. for some IMAP accounts) while categories still list,
+// so a rules failure is reported inline rather than failing the tool.
+func ListRulesAndCategoriesHandler(manager Backend) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		categories, err := manager.ListCategories()
+		if err != nil {
+			return shared.ErrorResultFromErr("Failed to list categories", err), nil
+		}
+
+		var result strings.Builder
+		rules, err := manager.ListRules()
+		if err != nil {
+			result.WriteString(fmt.Sprintf("Rules: unavailable (%v)\n\n", err))
+		} else {
+			result.WriteString(formatRuleList(rules.Rules))
+		}
+		result.WriteString(formatCategoryList(categories.Categories))
+
+		return mcp.NewToolResultText(result.String()), nil
+	}
+}
+
+// SearchContactsHandler handles the search_contacts tool
+func SearchContactsHandler(manager Backend) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args SearchContactsArgs
+		argBytes, err := json.Marshal(request.Params.Arguments)
+		if err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Failed to marshal arguments"), nil
+		}
+		if err := json.Unmarshal(argBytes, &args); err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments"), nil
+		}
+
+		response, err := manager.SearchContacts(args.Query, args.Limit)
+		if err != nil {
+			return shared.ErrorResultFromErr("Failed to search contacts", err), nil
+		}
+
+		result := formatContactList(response.Contacts)
+		if response.Truncated {
+			result += fmt.Sprintf("\n(Showing the first %d contacts; refine the query or raise the limit to see more.)", response.Count)
+		}
+
+		return mcp.NewToolResultText(result), nil
+	}
+}
+
+// GetContactHandler handles the get_contact tool
+func GetContactHandler(manager Backend) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args GetContactArgs
+		argBytes, err := json.Marshal(request.Params.Arguments)
+		if err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Failed to marshal arguments"), nil
+		}
+		if err := json.Unmarshal(argBytes, &args); err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments"), nil
+		}
+
+		if args.ContactID == "" {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "contact_id parameter is required"), nil
+		}
+
+		contact, err := manager.GetContact(args.ContactID)
+		if err != nil {
+			return shared.ErrorResultFromErr("Failed to get contact", err), nil
+		}
+
+		return mcp.NewToolResultText(formatContactDetails(contact)), nil
+	}
+}
+
+// ResolveRecipientHandler handles the resolve_recipient tool
+func ResolveRecipientHandler(manager Backend) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args ResolveRecipientArgs
+		argBytes, err := json.Marshal(request.Params.Arguments)
+		if err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Failed to marshal arguments"), nil
+		}
+		if err := json.Unmarshal(argBytes, &args); err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments"), nil
+		}
+
+		if strings.TrimSpace(args.Name) == "" {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "name parameter is required"), nil
+		}
+		expand := args.ExpandLists == nil || *args.ExpandLists
+
+		resolution, err := manager.ResolveRecipient(args.Name, expand)
+		if err != nil {
+			return shared.ErrorResultFromErr("Failed to resolve recipient", err), nil
+		}
+
+		return mcp.NewToolResultText(formatRecipientResolution(resolution)), nil
+	}
+}
+
+// parseSearchFilters converts search_messages arguments into SearchFilters. A
+// date-only before includes that whole day.
+func parseSearchFilters(args SearchMessagesArgs, now time.Time) (SearchFilters, error) {
+	filters := SearchFilters{
+		From:           args.From,
+		To:             args.To,
+		HasAttachments: args.HasAttachments,
+		Unread:         args.Unread,
+		Importance:     strings.ToLower(args.Importance),
+	}
+
+	if args.After != "" {
+		after, _, err := parseCalendarDate(args.After, now.Location())
+		if err != nil {
+			return SearchFilters{}, fmt.Errorf("invalid after: %v", err)
+		}
+		filters.After = after
+	}
+	if args.Before != "" {
+		before, dateOnly, err := parseCalendarDate(args.Before, now.Location())
+		if err != nil {
+			return SearchFilters{}, fmt.Errorf("invalid before: %v", err)
+		}
+		if dateOnly {
+			before = before.AddDate(0, 0, 1)
+		}
+		filters.Before = before
+	}
+
+	return filters, nil
+}
+
+// parseCalendarRange turns the optional start and end arguments into a time range.
+// Dates may be given as YYYY-MM-DD (local time, with a date-only end being inclusive)
+// or RFC 3339 timestamps. Start defaults to the beginning of today and end to a week later.
+func parseCalendarRange(startArg, endArg string, now time.Time) (time.Time, time.Time, error) {
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if startArg != "" {
+		parsed, _, err := parseCalendarDate(startArg, now.Location())
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid start: %v", err)
+		}
+		start = parsed
+	}
+
+	end := start.Add(defaultCalendarRange)
+	if endArg != "" {
+		parsed, dateOnly, err := parseCalendarDate(endArg, now.Location())
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid end: %v", err)
+		}
+		if dateOnly {
+			parsed = parsed.AddDate(0, 0, 1)
+		}
+		end = parsed
+	}
+
+	if !end.After(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("end must be after start")
+	}
+
+	return start, end, nil
+}
+
+// parseCalendarDate parses a YYYY-MM-DD date or RFC 3339 timestamp, reporting whether it was date-only
+func parseCalendarDate(value string, loc *time.Location) (time.Time, bool, error) {
+	if date, err := time.ParseInLocation("2006-01-02", value, loc); err == nil {
+		return date, true, nil
+	}
+	timestamp, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("%q is not a YYYY-MM-DD date or RFC 3339 timestamp", value)
+	}
+	return timestamp, false, nil
+}
+
+// SendMessageHandler handles the send_message tool
+func SendMessageHandler(manager Backend) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args SendMessageArgs
+		argBytes, err := json.Marshal(request.Params.Arguments)
+		if err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Failed to marshal arguments"), nil
+		}
+		if err := json.Unmarshal(argBytes, &args); err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments"), nil
+		}
+
+		if len(args.To) == 0 {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "to parameter must contain at least one recipient"), nil
+		}
+		if args.Subject == "" {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "subject parameter is required"), nil
+		}
+
+		response, err := manager.SendMessage(SendMessageRequest{
+			To:      args.To,
+			Cc:      args.Cc,
+			Bcc:     args.Bcc,
+			Subject: args.Subject,
+			Body:    args.Body,
+			HTML:    args.HTML,
+		})
+		if err != nil {
+			return shared.ErrorResultFromErr("Failed to send message", err), nil
+		}
+
+		return mcp.NewToolResultText(formatSendResult(response)), nil
+	}
+}
+
+// ReplyToMessageHandler handles the reply_to_message tool
+func ReplyToMessageHandler(manager Backend) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args ReplyToMessageArgs
+		argBytes, err := json.Marshal(request.Params.Arguments)
+		if err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Failed to marshal arguments"), nil
+		}
+		if err := json.Unmarshal(argBytes, &args); err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments"), nil
+		}
+
+		if args.MessageID == "" {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "message_id parameter is required"), nil
+		}
+		if args.Body == "" {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "body parameter is required"), nil
+		}
+
+		response, err := manager.ReplyToMessage(args.MessageID, ReplyRequest{
+			Body:     args.Body,
+			ReplyAll: args.ReplyAll,
+		})
+		if err != nil {
+			return shared.ErrorResultFromErr("Failed to reply to message", err), nil
+		}
+
+		return mcp.NewToolResultText(formatSendResult(response)), nil
+	}
+}
+
+// ForwardMessageHandler handles the forward_message tool
+func ForwardMessageHandler(manager Backend) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args ForwardMessageArgs
+		argBytes, err := json.Marshal(request.Params.Arguments)
+		if err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Failed to marshal arguments"), nil
+		}
+		if err := json.Unmarshal(argBytes, &args); err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments"), nil
+		}
+
+		if args.MessageID == "" {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "message_id parameter is required"), nil
+		}
+		if len(args.To) == 0 {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "to parameter must contain at least one recipient"), nil
+		}
+
+		response, err := manager.ForwardMessage(args.MessageID, ForwardRequest{
+			To:   args.To,
+			Cc:   args.Cc,
+			Bcc:  args.Bcc,
+			Body: args.Body,
+		})
+		if err != nil {
+			return shared.ErrorResultFromErr("Failed to forward message", err), nil
+		}
+
+		return mcp.NewToolResultText(formatSendResult(response)), nil
+	}
+}
+
+// Helper function to format the confirmation for a sent, replied or forwarded message
+func formatSendResult(response *SendResponse) string {
+	var action string
+	switch response.Action {
+	case "reply":
+		action = "Reply sent"
+	case "reply_all":
+		action = "Reply to all sent"
+	case "forward":
+		action = "Message forwarded"
+	default:
+		action = "Message sent"
+	}
+
+	return fmt.Sprintf(`%s successfully.
+
+Subject: %s
+To: %s`, action, response.Subject, response.To)
+}
+
+// Helper function to format a flag status, treating a missing status as none
+func formatFlagStatus(flagStatus string) string {
+	if flagStatus == "" {
+		return FlagStatusNone
+	}
+	return flagStatus
+}
+
+// Helper function to format message categories
+func formatCategories(categories []string) string {
+	if len(categories) == 0 {
+		return "(none)"
+	}
+	return strings.Join(categories, ", ")
+}
+
+// Helper function to format the user's identity and signatures
+func formatUserProfile(profile *UserProfile, includeHTML bool) string {
+	var builder strings.Builder
+
+	builder.WriteString(fmt.Sprintf("Name: %s\nEmail: %s\n", profile.DisplayName, profile.SMTPAddress))
+	if profile.Profile != "" {
+		builder.WriteString(fmt.Sprintf("Outlook profile: %s\n", profile.Profile))
+	}
+
+	if len(profile.Signatures) == 0 {
+		builder.WriteString("\nNo signatures found.\n")
+		return builder.String()
+	}
+
+	builder.WriteString(fmt.Sprintf("\nSignatures (%d):\n", len(profile.Signatures)))
+	for _, signature := range profile.Signatures {
+		var defaults []string
+		if signature.DefaultNew {
+			defaults = append(defaults, "new messages")
+		}
+		if signature.DefaultReply {
+			defaults = append(defaults, "replies and forwards")
+		}
+
+		builder.WriteString(fmt.Sprintf("\n--- %s", signature.Name))
+		if len(defaults) > 0 {
+			builder.WriteString(fmt.Sprintf(" (default for %s)", strings.Join(defaults, " and ")))
+		}
+		builder.WriteString(" ---\n")
+
+		switch {
+		case signature.Text != "":
+			builder.WriteString(signature.Text + "\n")
+		case !includeHTML:
+			builder.WriteString("(HTML only; request include_html to see it)\n")
+		}
+		if includeHTML && signature.HTML != "" {
+			builder.WriteString("HTML:\n" + signature.HTML + "\n")
+		}
+	}
+
+	return builder.String()
+}
+
+// Helper function to format the outcome of a bulk update
+func formatBulkUpdateResult(result *BulkUpdateResult) string {
+	var builder strings.Builder
+
+	if result.DryRun {
+		builder.WriteString(fmt.Sprintf("Dry run: %s would affect %d messages. Nothing was changed.\n", result.Action, len(result.Messages)))
+	} else {
+		builder.WriteString(fmt.Sprintf("Applied %s to %d of %d messages.\n", result.Action, result.Succeeded, len(result.Messages)))
+	}
+	if result.Truncated {
+		builder.WriteString("More messages matched than the limit; run again or raise limit for the rest.\n")
+	}
+
+	if len(result.Failures) > 0 {
+		builder.WriteString(fmt.Sprintf("\nFailed (%d):\n", len(result.Failures)))
+		for _, failure := range result.Failures {
+			builder.WriteString(fmt.Sprintf("- %s: %s\n", failure.MessageID, failure.Error))
+		}
+	}
+
+	if result.DryRun {
+		builder.WriteString("\n")
+		builder.WriteString(formatMessageList(result.Messages))
+	}
+
+	return builder.String()
+}
+
+// formatInboxAnalytics formats mail volume, unread aging and the largest messages
+func formatInboxAnalytics(analytics *InboxAnalytics) string {
+	var result strings.Builder
+
+	result.WriteString(fmt.Sprintf("Inbox analytics for %s, %s to %s:\n\n", analytics.Folder,
+		analytics.Start.Format("2006-01-02 15:04"), analytics.End.Format("2006-01-02 15:04")))
+	result.WriteString(fmt.Sprintf("Messages: %d (%d unread), %d bytes\n", analytics.Messages, analytics.Unread, analytics.TotalSize))
+	if analytics.Truncated {
+		result.WriteString(fmt.Sprintf("Only the newest %d messages were counted; narrow the range for exact figures.\n", analytics.Messages))
+	}
+
+	writeVolumes := func(title string, volumes []SenderVolume) {
+		result.WriteString(fmt.Sprintf("\n%s:\n", title))
+		if len(volumes) == 0 {
+			result.WriteString("   None\n")
+		}
+		for i, volume := range volumes {
+			name := volume.Address
+			if volume.Name != "" && !strings.EqualFold(volume.Name, volume.Address) {
+				name = fmt.Sprintf("%s <%s>", volume.Name, volume.Address)
+			}
+			result.WriteString(fmt.Sprintf("%d. %s: %d messages (%d unread), %d bytes\n", i+1, name, volume.Count, volume.Unread, volume.Size))
+		}
+	}
+	writeVolumes("Top senders", analytics.TopSenders)
+	writeVolumes("Top domains", analytics.TopDomains)
+
+	result.WriteString("\nMessages per day:\n")
+	for _, day := range analytics.ByDay {
+		result.WriteString(fmt.Sprintf("   %s  %d", day.Date, day.Count))
+		if day.Unread > 0 {
+			result.WriteString(fmt.Sprintf(" (%d unread)", day.Unread))
+		}
+		result.WriteString("\n")
+	}
+
+	result.WriteString("\nUnread by age:\n")
+	for _, bucket := range analytics.UnreadAging {
+		result.WriteString(fmt.Sprintf("   %s: %d\n", bucket.Label, bucket.Count))
+	}
+	if analytics.OldestUnread != nil {
+		result.WriteString(fmt.Sprintf("   Oldest unread: %s\n", analytics.OldestUnread.Format("2006-01-02 15:04")))
+	}
+
+	result.WriteString("\nLargest messages:\n")
+	result.WriteString(formatMessageList(analytics.Largest))
+
+	return result.String()
+}
+
+// formatPollResult formats new messages with the cursor for the next poll
+func formatPollResult(result *PollResult, folder string) string {
+	if folder == "" {
+		folder = "Inbox"
+	}
+
+	var builder strings.Builder
+	if len(result.Messages) == 0 {
+		builder.WriteString(fmt.Sprintf("No new messages in %s.\n", folder))
+	} else {
+		builder.WriteString(fmt.Sprintf("%d new messages in %s, oldest first:\n\n", len(result.Messages), folder))
+		builder.WriteString(formatMessageList(result.Messages))
+	}
+	if result.HasMore {
+		builder.WriteString("\nMore new messages are waiting; poll again with this cursor.\n")
+	}
+	if result.Truncated {
+		builder.WriteString(fmt.Sprintf("\nMore than %d messages arrived, or more than the search could scan; older ones were skipped.\n", maxPollScan))
+	}
+	builder.WriteString(fmt.Sprintf("\nCursor: %s\n(pass it as cursor to the next poll_new_messages call)", result.Cursor))
+
+	return builder.String()
+}
+
+// Helper function to format a list of attachments
+func formatAttachmentList(attachments []Attachment) string {
+	if len(attachments) == 0 {
+		return "Message has no attachments."
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Found %d attachments:\n\n", len(attachments)))
+	for _, attachment := range attachments {
+		inline := ""
+		if attachment.Inline {
+			inline = " [inline]"
+		}
+		result.WriteString(fmt.Sprintf("%d. %s (%d bytes)%s\n", attachment.Index, attachment.FileName, attachment.Size, inline))
+	}
+
+	return result.String()
+}
+
+// Helper function to format an event's time span
+func formatEventTime(event *CalendarEvent) string {
+	if event.AllDay {
+		lastDay := event.End.AddDate(0, 0, -1)
+		if !lastDay.After(event.Start) {
+			return event.Start.Format("Mon 2006-01-02") + " (all day)"
+		}
+		return fmt.Sprintf("%s - %s (all day)", event.Start.Format("Mon 2006-01-02"), lastDay.Format("Mon 2006-01-02"))
+	}
+	if event.End.Format("2006-01-02") == event.Start.Format("2006-01-02") {
+		return fmt.Sprintf("%s - %s", event.Start.Format("Mon 2006-01-02 15:04"), event.End.Format("15:04"))
+	}
+	return fmt.Sprintf("%s - %s", event.Start.Format("Mon 2006-01-02 15:04"), event.End.Format("Mon 2006-01-02 15:04"))
+}
+
+// Helper function to format a free/busy block as a time range
+func formatFreeBusyRange(block FreeBusyBlock) string {
+	if block.End.Format("2006-01-02") == block.Start.Format("2006-01-02") {
+		return fmt.Sprintf("%s - %s", block.Start.Format("Mon 2006-01-02 15:04"), block.End.Format("15:04"))
+	}
+	return fmt.Sprintf("%s - %s", block.Start.Format("Mon 2006-01-02 15:04"), block.End.Format("Mon 2006-01-02 15:04"))
+}
+
+// Helper function to format free/busy for each attendee and the windows when all are free
+func formatFreeBusy(response *FreeBusyResponse, minDuration time.Duration) string {
+	var result strings.Builder
+
+	result.WriteString(fmt.Sprintf("Free/busy %s (%d-minute slots)\n\n",
+		formatFreeBusyRange(FreeBusyBlock{Start: response.Start, End: response.End}), response.Interval))
+
+	for i := range response.Attendees {
+		attendee := &response.Attendees[i]
+		if !attendee.Resolved {
+			result.WriteString(fmt.Sprintf("%s: %s\n\n", attendee.Attendee, attendee.Error))
+			continue
+		}
+
+		result.WriteString(fmt.Sprintf("%s <%s>:\n", attendee.Name, attendee.Email))
+		if attendee.Error != "" {
+			result.WriteString(fmt.Sprintf("   %s\n\n", attendee.Error))
+			continue
+		}
+
+		busy := 0
+		for _, block := range response.Blocks(attendee) {
+			if block.Status == BusyStatusFree {
+				continue
+			}
+			result.WriteString(fmt.Sprintf("   %s  %s\n", formatFreeBusyRange(block), block.Status))
+			busy++
+		}
+		if busy == 0 {
+			result.WriteString("   Free for the whole range\n")
+		}
+		result.WriteString("\n")
+	}
+
+	windows := response.CommonFreeWindows(minDuration)
+	result.WriteString(fmt.Sprintf("Common free windows (at least %s):\n", minDuration))
+	if len(windows) == 0 {
+		result.WriteString("   None\n")
+	}
+	for _, window := range windows {
+		result.WriteString(fmt.Sprintf("   %s (%s)\n", formatFreeBusyRange(window), window.End.Sub(window.Start)))
+	}
+
+	return result.String()
+}
+
+// Helper function to format a list of calendar events
+func formatEventList(events []CalendarEvent) string {
+	if len(events) == 0 {
+		return "No events found."
+	}
+
+	var result strings.Builder
+	for i := range events {
+		event := &events[i]
+		recurring := ""
+		if event.IsRecurring {
+			recurring = " [RECURRING]"
+		}
+		result.WriteString(fmt.Sprintf("%d. %s%s\n   When: %s\n", i+1, event.Subject, recurring, formatEventTime(event)))
+		if event.Location != "" {
+			result.WriteString(fmt.Sprintf("   Where: %s\n", event.Location))
+		}
+		if event.Organizer != "" {
+			result.WriteString(fmt.Sprintf("   Organizer: %s\n", event.Organizer))
+		}
+		result.WriteString(fmt.Sprintf("   Status: %s\n   ID: %s\n\n", event.BusyStatus, event.ID))
+	}
+
+	return result.String()
+}
+
+// Helper function to format full details of a calendar event
+func formatEventDetails(event *CalendarEvent) string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Subject: %s\nWhen: %s\n", event.Subject, formatEventTime(event)))
+	if event.Location != "" {
+		result.WriteString(fmt.Sprintf("Where: %s\n", event.Location))
+	}
+	if event.Organizer != "" {
+		result.WriteString(fmt.Sprintf("Organizer: %s\n", event.Organizer))
+	}
+	result.WriteString(fmt.Sprintf("Status: %s\n", event.BusyStatus))
+	if event.IsMeeting {
+		result.WriteString(fmt.Sprintf("Required: %s\n", formatAttendees(event.RequiredAttendees)))
+		if len(event.OptionalAttendees) > 0 {
+			result.WriteString(fmt.Sprintf("Optional: %s\n", formatAttendees(event.OptionalAttendees)))
+		}
+	}
+	if event.Recurrence != nil {
+		every := event.Recurrence.Type
+		if event.Recurrence.Interval > 1 {
+			every = fmt.Sprintf("every %d (%s)", event.Recurrence.Interval, event.Recurrence.Type)
+		}
+		until := "no end date"
+		if event.Recurrence.PatternEnd != nil {
+			until = "until " + event.Recurrence.PatternEnd.Format("2006-01-02")
+		}
+		result.WriteString(fmt.Sprintf("Recurs: %s, from %s, %s\n", every, event.Recurrence.PatternStart.Format("2006-01-02"), until))
+	}
+	if event.ReminderMinutes != nil {
+		result.WriteString(fmt.Sprintf("Reminder: %d minutes before\n", *event.ReminderMinutes))
+	}
+	if len(event.Categories) > 0 {
+		result.WriteString(fmt.Sprintf("Categories: %s\n", formatCategories(event.Categories)))
+	}
+	result.WriteString(fmt.Sprintf("ID: %s\n", event.ID))
+	if event.Body != "" {
+		result.WriteString("\n")
+		result.WriteString(event.Body)
+	}
+
+	return result.String()
+}
+
+// Helper function to format an attendee list
+func formatAttendees(attendees []string) string {
+	if len(attendees) == 0 {
+		return "None"
+	}
+	return strings.Join(attendees, "; ")
+}
+
+// Helper function to format a health report
+func formatHealthStatus(status *HealthStatus, now time.Time) string {
+	if status.Backend == BackendGraph {
+		return formatGraphHealthStatus(status)
+	}
+
+	var result strings.Builder
+
+	overall := "OK"
+	switch {
+	case !status.SidecarReachable:
+		overall = "DOWN"
+	case !status.Sidecar.OutlookConnected:
+		overall = "DEGRADED"
+	}
+	result.WriteString(fmt.Sprintf("Outlook MCP Health: %s\n\n", overall))
+
+	if status.SidecarReachable {
+		sidecar := status.Sidecar
+		result.WriteString(fmt.Sprintf("PowerShell server: running (PID %d, PowerShell %s, up %s)\n",
+			sidecar.PID, sidecar.PowerShellVersion, now.Sub(sidecar.StartedAt).Round(time.Second)))
+		if sidecar.OutlookConnected {
+			result.WriteString(fmt.Sprintf("Outlook: connected (version %s, profile %s, %s)\n", sidecar.OutlookVersion, sidecar.Profile, sidecar.ConnectionMode))
+		} else {
+			result.WriteString(fmt.Sprintf("Outlook: not connected (%s)\n", sidecar.OutlookError))
+		}
+		if sidecar.Reconnects > 0 {
+			result.WriteString(fmt.Sprintf("Outlook reconnects: %d", sidecar.Reconnects))
+			if sidecar.LastReconnectAt != nil {
+				result.WriteString(fmt.Sprintf(" (last %s)", sidecar.LastReconnectAt.Format("2006-01-02 15:04:05")))
+			}
+			result.WriteString("\n")
+		}
+	} else {
+		result.WriteString(fmt.Sprintf("PowerShell server: unreachable (%s)\n", status.SidecarError))
+	}
+
+	result.WriteString("\nSupervisor:\n")
+	if status.Restarting {
+		result.WriteString("   Restart in progress\n")
+	}
+	if !status.ManagerStartedAt.IsZero() {
+		result.WriteString(fmt.Sprintf("   Started: %s\n", status.ManagerStartedAt.Format("2006-01-02 15:04:05")))
+	}
+	result.WriteString(fmt.Sprintf("   Process exits: %d\n", status.ProcessExits))
+	result.WriteString(fmt.Sprintf("   Restarts: %d (%d failed)\n", status.Restarts, status.FailedRestarts))
+	if status.LastRestart != nil {
+		result.WriteString(fmt.Sprintf("   Last restart: %s\n", status.LastRestart.Format("2006-01-02 15:04:05")))
+	}
+	result.WriteString(fmt.Sprintf("   Request errors (last 15 minutes): %d\n", status.RecentRequestErrors))
+	if status.LastError != "" {
+		result.WriteString(fmt.Sprintf("   Last error: %s (%s)\n", status.LastError, status.LastErrorAt.Format("2006-01-02 15:04:05")))
+	}
+
+	return result.String()
+}
+
+// Helper function to format a health report of the Microsoft Graph backend
+func formatGraphHealthStatus(status *HealthStatus) string {
+	var result strings.Builder
+
+	overall := "OK"
+	if status.Graph == nil || !status.Graph.Reachable {
+		overall = "DOWN"
+	}
+	result.WriteString(fmt.Sprintf("Outlook MCP Health: %s\n\n", overall))
+
+	if overall == "OK" {
+		result.WriteString(fmt.Sprintf("Microsoft Graph: connected as %s <%s>\n", status.Graph.User, status.Graph.Email))
+	} else if status.Graph != nil {
+		result.WriteString(fmt.Sprintf("Microsoft Graph: unreachable (%s)\n", status.Graph.Error))
+	}
+
+	result.WriteString("\nBackend:\n")
+	if !status.ManagerStartedAt.IsZero() {
+		result.WriteString(fmt.Sprintf("   Started: %s\n", status.ManagerStartedAt.Format("2006-01-02 15:04:05")))
+	}
+	result.WriteString(fmt.Sprintf("   Request errors (last 15 minutes): %d\n", status.RecentRequestErrors))
+	if status.LastError != "" {
+		result.WriteString(fmt.Sprintf("   Last error: %s (%s)\n", status.LastError, status.LastErrorAt.Format("2006-01-02 15:04:05")))
+	}
+
+	return result.String()
+}
+
+// Helper function to format the accounts and stores of the profile
+func formatAccountList(response *AccountListResponse) string {
+	var result strings.Builder
+
+	result.WriteString(fmt.Sprintf("Accounts (%d):\n\n", len(response.Accounts)))
+	for _, account := range response.Accounts {
+		defaultMarker := ""
+		if account.IsDefault {
+			defaultMarker = " [DEFAULT]"
+		}
+		result.WriteString(fmt.Sprintf("- %s <%s> (%s)%s\n", account.Name, account.SMTPAddress, account.Type, defaultMarker))
+	}
+
+	result.WriteString(fmt.Sprintf("\nMailboxes and data files (%d):\n\n", len(response.Stores)))
+	for _, store := range response.Stores {
+		defaultMarker := ""
+		if store.IsDefault {
+			defaultMarker = " [DEFAULT]"
+		}
+		result.WriteString(fmt.Sprintf("- %s (%s)%s\n", store.Name, store.Type, defaultMarker))
+	}
+
+	result.WriteString("\nPass an account email address or mailbox name as the account parameter of the mail tools. Shared mailboxes not listed here can be opened by their email address (well-known folders only).\n")
+
+	return result.String()
+}
+
+// Helper function to format a list of rules
+func formatRuleList(rules []Rule) string {
+	if len(rules) == 0 {
+		return "Rules: none configured\n\n"
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Rules (%d, in execution order):\n\n", len(rules)))
+	for i, rule := range rules {
+		status := ""
+		if !rule.Enabled {
+			status = " [DISABLED]"
+		}
+		if rule.IsLocal {
+			status += " [CLIENT-ONLY]"
+		}
+		result.WriteString(fmt.Sprintf("%d. %s (%s)%s\n", i+1, rule.Name, rule.Type, status))
+		if len(rule.Conditions) > 0 {
+			result.WriteString(fmt.Sprintf("   When: %s\n", strings.Join(rule.Conditions, "; ")))
+		}
+		if len(rule.Actions) > 0 {
+			result.WriteString(fmt.Sprintf("   Then: %s\n", strings.Join(rule.Actions, "; ")))
+		}
+		result.WriteString("\n")
+	}
+
+	return result.String()
+}
+
+// Helper function to format the master category list
+func formatCategoryList(categories []Category) string {
+	if len(categories) == 0 {
+		return "Categories: none defined\n"
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Categories (%d):\n\n", len(categories)))
+	for _, category := range categories {
+		result.WriteString(fmt.Sprintf("- %s (%s)", category.Name, category.Color))
+		if category.ShortcutKey != "" {
+			result.WriteString(fmt.Sprintf(" [%s]", category.ShortcutKey))
+		}
+		result.WriteString("\n")
+	}
+
+	return result.String()
+}
+
+// Helper function to describe the active search filters, e.g. "from: bob, unread"
+func describeSearchFilters(filters SearchFilters) string {
+	var parts []string
+	if filters.From != "" {
+		parts = append(parts, "from: "+filters.From)
+	}
+	if filters.To != "" {
+		parts = append(parts, "to: "+filters.To)
+	}
+	if !filters.After.IsZero() {
+		parts = append(parts, "after: "+filters.After.Format("2006-01-02 15:04"))
+	}
+	if !filters.Before.IsZero() {
+		parts = append(parts, "before: "+filters.Before.Format("2006-01-02 15:04"))
+	}
+	if filters.HasAttachments != nil {
+		if *filters.HasAttachments {
+			parts = append(parts, "with attachments")
+		} else {
+			parts = append(parts, "without attachments")
+		}
+	}
+	if filters.Unread != nil {
+		if *filters.Unread {
+			parts = append(parts, "unread")
+		} else {
+			parts = append(parts, "read")
+		}
+	}
+	if filters.Importance != "" {
+		parts = append(parts, filters.Importance+" importance")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Helper function to describe where a contact works, e.g. "Analyst, Finance, Contoso"
+func formatContactOrganization(contact *Contact) string {
+	parts := make([]string, 0, 3)
+	for _, part := range []string{contact.JobTitle, contact.Department, contact.Company} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Helper function to format a resolved recipient and any list members
+func formatRecipientResolution(resolution *RecipientResolution) string {
+	var result strings.Builder
+
+	if !resolution.Resolved {
+		if len(resolution.Candidates) == 0 {
+			result.WriteString(fmt.Sprintf("Could not resolve %q to a single recipient. It may be unknown or ambiguous; try a fuller name or an email address, or use search_contacts.", resolution.Query))
+			return result.String()
+		}
+		result.WriteString(fmt.Sprintf("%q is ambiguous; %d possible recipients:\n\n", resolution.Query, len(resolution.Candidates)))
+		for i, candidate := range resolution.Candidates {
+			result.WriteString(fmt.Sprintf("%d. %s\n", i+1, formatRecipientEntry(candidate)))
+		}
+		return result.String()
+	}
+
+	result.WriteString(fmt.Sprintf("Resolved %q to %s\n", resolution.Query, formatRecipientEntry(RecipientEntry{Name: resolution.Name, Address: resolution.Address, Type: resolution.Type})))
+
+	if len(resolution.Members) > 0 {
+		result.WriteString(fmt.Sprintf("\nMembers (%d):\n", len(resolution.Members)))
+		for i, member := range resolution.Members {
+			result.WriteString(fmt.Sprintf("%d. %s\n", i+1, formatRecipientEntry(member)))
+		}
+	} else if resolution.Type == RecipientDistributionList && resolution.Note == "" {
+		result.WriteString("\nThe list has no members.\n")
+	}
+	if len(resolution.NestedLists) > 0 {
+		result.WriteString("\nIncludes the nested lists:\n")
+		for _, list := range resolution.NestedLists {
+			result.WriteString(fmt.Sprintf("- %s\n", formatRecipientEntry(list)))
+		}
+	}
+	if resolution.MembersTruncated {
+		result.WriteString(fmt.Sprintf("\nOnly the first %d members are listed.\n", len(resolution.Members)))
+	}
+	if resolution.Note != "" {
+		result.WriteString(fmt.Sprintf("\nNote: %s\n", resolution.Note))
+	}
+
+	return result.String()
+}
+
+// Helper function to format a recipient as "Name <address> (type)"
+func formatRecipientEntry(entry RecipientEntry) string {
+	text := entry.Name
+	if entry.Address != "" && !strings.EqualFold(entry.Address, entry.Name) {
+		if text == "" {
+			text = entry.Address
+		} else {
+			text += " <" + entry.Address + ">"
+		}
+	}
+	if entry.Type != "" {
+		text += " (" + strings.ReplaceAll(entry.Type, "_", " ") + ")"
+	}
+	return text
+}
+
+// Helper function to format phone numbers in a stable order
+func formatPhones(phones map[string]string) string {
+	parts := make([]string, 0, len(phones))
+	for _, kind := range []string{"business", "mobile", "home"} {
+		if number, ok := phones[kind]; ok {
+			parts = append(parts, fmt.Sprintf("%s (%s)", number, kind))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Helper function to format a task or flagged item
+func formatTask(task *Task) string {
+	var result strings.Builder
+
+	kind := ""
+	if task.Kind == TaskKindMessage {
+		kind = " [FLAGGED MESSAGE]"
+	}
+	result.WriteString(fmt.Sprintf("%s%s\n", task.Subject, kind))
+	result.WriteString(fmt.Sprintf("   Status: %s\n", task.Status))
+	if task.DueDate != "" {
+		result.WriteString(fmt.Sprintf("   Due: %s\n", task.DueDate))
+	}
+	if task.StartDate != "" {
+		result.WriteString(fmt.Sprintf("   Start: %s\n", task.StartDate))
+	}
+	if task.CompletedDate != "" {
+		result.WriteString(fmt.Sprintf("   Completed: %s\n", task.CompletedDate))
+	}
+	if task.Importance != "" && task.Importance != ImportanceNormal {
+		result.WriteString(fmt.Sprintf("   Importance: %s\n", task.Importance))
+	}
+	if task.Sender != "" {
+		result.WriteString(fmt.Sprintf("   From: %s\n", task.Sender))
+	}
+	if len(task.Categories) > 0 {
+		result.WriteString(fmt.Sprintf("   Categories: %s\n", formatCategories(task.Categories)))
+	}
+	result.WriteString(fmt.Sprintf("   ID: %s\n", task.ID))
+
+	return result.String()
+}
+
+// Helper function to format captured server log lines
+func formatServerLogs(response *ServerLogsResponse) string {
+	if len(response.Lines) == 0 {
+		return "No matching log lines captured."
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Showing %d of %d matching log lines (buffer holds %d", len(response.Lines), response.Matched, response.Capacity))
+	if response.Dropped > 0 {
+		result.WriteString(fmt.Sprintf("; %d older lines discarded", response.Dropped))
+	}
+	result.WriteString("):\n\n")
+	for _, line := range response.Lines {
+		result.WriteString(fmt.Sprintf("%s [%s] %s\n", line.Time.Format("2006-01-02 15:04:05"), line.Stream, line.Text))
+	}
+
+	return result.String()
+}
+
+// Helper function to format the To-Do List
+func formatTaskList(tasks []Task) string {
+	if len(tasks) == 0 {
+		return "No tasks found."
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Found %d tasks:\n\n", len(tasks)))
+	for i := range tasks {
+		result.WriteString(fmt.Sprintf("%d. %s\n", i+1, formatTask(&tasks[i])))
+	}
+
+	return result.String()
+}
+
+// Helper function to format a list of contacts
+func formatContactList(contacts []Contact) string {
+	if len(contacts) == 0 {
+		return "No contacts found."
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Found %d contacts:\n\n", len(contacts)))
+	for i := range contacts {
+		contact := &contacts[i]
+		result.WriteString(fmt.Sprintf("%d. %s\n", i+1, contact.Name))
+		if len(contact.Emails) > 0 {
+			result.WriteString(fmt.Sprintf("   Email: %s\n", strings.Join(contact.Emails, ", ")))
+		}
+		if organization := formatContactOrganization(contact); organization != "" {
+			result.WriteString(fmt.Sprintf("   Organization: %s\n", organization))
+		}
+		if phones := formatPhones(contact.Phones); phones != "" {
+			result.WriteString(fmt.Sprintf("   Phone: %s\n", phones))
+		}
+		result.WriteString(fmt.Sprintf("   ID: %s\n\n", contact.ID))
+	}
+
+	return result.String()
+}
+
+// Helper function to format full details of a contact
+func formatContactDetails(contact *Contact) string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Name: %s\n", contact.Name))
+	if len(contact.Emails) > 0 {
+		result.WriteString(fmt.Sprintf("Email: %s\n", strings.Join(contact.Emails, ", ")))
+	}
+	if organization := formatContactOrganization(contact); organization != "" {
+		result.WriteString(fmt.Sprintf("Organization: %s\n", organization))
+	}
+	if phones := formatPhones(contact.Phones); phones != "" {
+		result.WriteString(fmt.Sprintf("Phone: %s\n", phones))
+	}
+	if contact.BusinessAddress != "" {
+		result.WriteString(fmt.Sprintf("Business Address: %s\n", strings.ReplaceAll(contact.BusinessAddress, "\r\n", ", ")))
+	}
+	if contact.HomeAddress != "" {
+		result.WriteString(fmt.Sprintf("Home Address: %s\n", strings.ReplaceAll(contact.HomeAddress, "\r\n", ", ")))
+	}
+	if len(contact.Categories) > 0 {
+		result.WriteString(fmt.Sprintf("Categories: %s\n", formatCategories(contact.Categories)))
+	}
+	result.WriteString(fmt.Sprintf("ID: %s\n", contact.ID))
+	if contact.Notes != "" {
+		result.WriteString("\nNotes:\n")
+		result.WriteString(contact.Notes)
+	}
+
+	return result.String()
+}
+
+// Helper function to format a conversation as a chronological transcript
+func formatConversation(conversation *ConversationResponse) string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Conversation: %s (%d messages, oldest first)\n\n", conversation.Topic, len(conversation.Messages)))
+
+	for i, msg := range conversation.Messages {
+		result.WriteString(fmt.Sprintf("%d. %s - %s <%s>\n", i+1, msg.ReceivedTime.Format("2006-01-02 15:04"), msg.Sender, msg.SenderEmail))
+		result.WriteString(fmt.Sprintf("   Subject: %s\n", msg.Subject))
+		if msg.HasAttachments {
+			result.WriteString(fmt.Sprintf("   Attachments: %d\n", msg.AttachmentCount))
+		}
+		if msg.BodyPreview != "" {
+			result.WriteString(fmt.Sprintf("   Preview: %s\n", msg.BodyPreview))
+		}
+		result.WriteString(fmt.Sprintf("   ID: %s\n\n", msg.ID))
+	}
+
+	return result.String()
+}
+
+// Helper function to format a list of messages
+func formatMessageList(messages []Message) string {
+	if len(messages) == 0 {
+		return "No messages found."
+	}
+
+	result := ""
+	for i, msg := range messages {
+		unreadStatus := ""
+		if msg.Unread {
+			unreadStatus = " [UNREAD]"
+		}
+		if msg.FlagStatus == FlagStatusFlagged {
+			unreadStatus += " [FLAGGED]"
+		}
+
+		attachmentInfo := ""
+		if msg.HasAttachments {
+			attachmentInfo = fmt.Sprintf(" 📎(%d)", msg.AttachmentCount)
+		}
+
+		snippet := ""
+		if msg.Snippet != "" {
+			snippet = fmt.Sprintf("   Match: %s\n", msg.Snippet)
+		}
+
+		result += fmt.Sprintf(`%d. %s%s%s
+   From: %s <%s>
+   Received: %s
+   Size: %d bytes
+%s   ID: %s
+
+`, i+1, msg.Subject, unreadStatus, attachmentInfo,
+			msg.Sender, msg.SenderEmail,
+			msg.ReceivedTime.Format("2006-01-02 15:04:05"),
+			msg.Size, snippet, msg.ID)
+	}
+
+	return result
+}
+
+// Helper function to format the folder hierarchy as an indented list
+func formatFolderList(folders []Folder) string {
+	if len(folders) == 0 {
+		return "No folders found."
+	}
+
+	result := ""
+	for _, folder := range folders {
+		indent := strings.Repeat("  ", folder.Depth)
+		result += fmt.Sprintf("%s- %s (%d items, %d unread)\n%s  Path: %s\n", indent, folder.Name,
+			folder.ItemCount, folder.UnreadCount, indent, folder.Path)
+	}
+
+	return result
+}
+
+// Helper function to describe list_messages filters, e.g. "unread, high importance"
+func formatListFilters(filters ListFilters) string {
+	var parts []string
+	if filters.UnreadOnly {
+		parts = append(parts, "unread")
+	}
+	if filters.Importance != "" {
+		parts = append(parts, filters.Importance+" importance")
+	}
+	if filters.Category != "" {
+		parts = append(parts, fmt.Sprintf("category %q", filters.Category))
+	}
+	if filters.HasAttachments != nil {
+		if *filters.HasAttachments {
+			parts = append(parts, "with attachments")
+		} else {
+			parts = append(parts, "without attachments")
+		}
+	}
+	return strings.Join(parts, ", ")
 }
 
 // Helper function to convert importance number to string
@@ -2,6 +2,7 @@ package outlook
 
 import (
 	"testing"
+	"time"
 )
 
 func TestFormatMessageListSimple(t *testing.T) {
@@ -13,6 +14,97 @@ func TestFormatMessageListSimple(t *testing.T) {
 	}
 }
 
+func TestFormatMessageListSnippet(t *testing.T) {
+	result := formatMessageList([]Message{
+		{ID: "msg1", Subject: "Budget", Snippet: "...the budget review..."},
+		{ID: "msg2", Subject: "Other"},
+	})
+	if !containsSubstring(result, "   Match: ...the budget review...\n   ID: msg1") {
+		t.Errorf("Expected snippet line before ID, got '%s'", result)
+	}
+	if containsSubstring(result, "Match: \n") {
+		t.Errorf("Messages without a snippet should have no match line, got '%s'", result)
+	}
+}
+
+func TestFormatFolderListSimple(t *testing.T) {
+	if result := formatFolderList(nil); result != "No folders found." {
+		t.Errorf("Expected 'No folders found.', got '%s'", result)
+	}
+
+	result := formatFolderList([]Folder{
+		{Name: "Inbox", Path: "\\\\Mailbox\\Inbox", ItemCount: 5, UnreadCount: 1, Depth: 0},
+		{Name: "Projects", Path: "\\\\Mailbox\\Inbox\\Projects", ItemCount: 2, Depth: 1},
+	})
+	if !containsSubstring(result, "- Inbox (5 items, 1 unread)") {
+		t.Errorf("Expected top-level folder line, got '%s'", result)
+	}
+	if !containsSubstring(result, "  - Projects (2 items, 0 unread)") {
+		t.Errorf("Expected indented subfolder line, got '%s'", result)
+	}
+}
+
+func TestFormatSendResultSimple(t *testing.T) {
+	tests := []struct {
+		action   string
+		expected string
+	}{
+		{"send", "Message sent successfully."},
+		{"reply", "Reply sent successfully."},
+		{"reply_all", "Reply to all sent successfully."},
+		{"forward", "Message forwarded successfully."},
+	}
+
+	for _, tt := range tests {
+		result := formatSendResult(&SendResponse{Action: tt.action, Subject: "Status", To: "alice@example.com"})
+		if !containsSubstring(result, tt.expected) {
+			t.Errorf("formatSendResult(%s) = %s, expected to contain %s", tt.action, result, tt.expected)
+		}
+		if !containsSubstring(result, "To: alice@example.com") {
+			t.Errorf("formatSendResult(%s) should include recipients, got %s", tt.action, result)
+		}
+	}
+}
+
+func TestSendToolDefinitionsAreSeparate(t *testing.T) {
+	sendTools := map[string]bool{
+		"send_message":     true,
+		"reply_to_message": true,
+		"forward_message":  true,
+	}
+
+	for _, tool := range GetToolDefinitions() {
+		if sendTools[tool.Name] {
+			t.Errorf("Send tool %s must not be part of the default tool set", tool.Name)
+		}
+	}
+
+	definitions := GetSendToolDefinitions()
+	if len(definitions) != len(sendTools) {
+		t.Fatalf("Expected %d send tools, got %d", len(sendTools), len(definitions))
+	}
+	for _, tool := range definitions {
+		if !sendTools[tool.Name] {
+			t.Errorf("Unexpected send tool: %s", tool.Name)
+		}
+		if tool.Annotations.ReadOnlyHint == nil || *tool.Annotations.ReadOnlyHint {
+			t.Errorf("Send tool %s must not be marked read-only", tool.Name)
+		}
+	}
+}
+
+func TestFormatCategoriesSimple(t *testing.T) {
+	if result := formatCategories(nil); result != "(none)" {
+		t.Errorf("Expected '(none)', got '%s'", result)
+	}
+	if result := formatCategories([]string{"Red", "Project X"}); result != "Red, Project X" {
+		t.Errorf("Expected 'Red, Project X', got '%s'", result)
+	}
+	if result := formatFlagStatus(""); result != FlagStatusNone {
+		t.Errorf("Expected '%s', got '%s'", FlagStatusNone, result)
+	}
+}
+
 func TestGetImportanceStringSimple(t *testing.T) {
 	tests := []struct {
 		importance int
@@ -32,6 +124,183 @@ func TestGetImportanceStringSimple(t *testing.T) {
 	}
 }
 
+func TestFormatAttachmentListSimple(t *testing.T) {
+	if result := formatAttachmentList(nil); result != "Message has no attachments." {
+		t.Errorf("Expected 'Message has no attachments.', got '%s'", result)
+	}
+
+	result := formatAttachmentList([]Attachment{
+		{Index: 1, FileName: "budget.xlsx", Size: 2048},
+		{Index: 2, FileName: "logo.png", Size: 512, Inline: true},
+	})
+	if !containsSubstring(result, "1. budget.xlsx (2048 bytes)\n") {
+		t.Errorf("Expected attachment line, got '%s'", result)
+	}
+	if !containsSubstring(result, "2. logo.png (512 bytes) [inline]") {
+		t.Errorf("Expected inline marker, got '%s'", result)
+	}
+}
+
+func TestParseCalendarRange(t *testing.T) {
+	now := time.Date(2024, 6, 5, 15, 30, 0, 0, time.UTC)
+
+	start, end, err := parseCalendarRange("", "", now)
+	if err != nil {
+		t.Fatalf("Default range failed: %v", err)
+	}
+	if !start.Equal(time.Date(2024, 6, 5, 0, 0, 0, 0, time.UTC)) || !end.Equal(start.AddDate(0, 0, 7)) {
+		t.Errorf("Unexpected default range: %v - %v", start, end)
+	}
+
+	// A date-only end includes the whole day
+	start, end, err = parseCalendarRange("2024-06-10", "2024-06-10", now)
+	if err != nil {
+		t.Fatalf("Single day range failed: %v", err)
+	}
+	if end.Sub(start) != 24*time.Hour {
+		t.Errorf("Expected a one-day range, got %v - %v", start, end)
+	}
+
+	start, end, err = parseCalendarRange("2024-06-10T09:00:00Z", "2024-06-10T12:00:00Z", now)
+	if err != nil || end.Sub(start) != 3*time.Hour {
+		t.Errorf("Unexpected timestamp range: %v - %v (%v)", start, end, err)
+	}
+
+	if _, _, err := parseCalendarRange("next tuesday", "", now); err == nil {
+		t.Error("Expected error for unparseable start")
+	}
+	if _, _, err := parseCalendarRange("2024-06-10T12:00:00Z", "2024-06-10T09:00:00Z", now); err == nil {
+		t.Error("Expected error when end is before start")
+	}
+}
+
+func TestFormatEventListSimple(t *testing.T) {
+	if result := formatEventList(nil); result != "No events found." {
+		t.Errorf("Expected 'No events found.', got '%s'", result)
+	}
+
+	result := formatEventList([]CalendarEvent{
+		{ID: "ev1", Subject: "Standup", Start: time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC), End: time.Date(2024, 6, 3, 9, 15, 0, 0, time.UTC), IsRecurring: true, BusyStatus: BusyStatusBusy, Location: "Room 1"},
+		{ID: "ev2", Subject: "Offsite", Start: time.Date(2024, 6, 6, 0, 0, 0, 0, time.UTC), End: time.Date(2024, 6, 8, 0, 0, 0, 0, time.UTC), AllDay: true, BusyStatus: BusyStatusOutOfOffice},
+	})
+	if !containsSubstring(result, "1. Standup [RECURRING]") || !containsSubstring(result, "When: Mon 2024-06-03 09:00 - 09:15") {
+		t.Errorf("Expected recurring timed event, got '%s'", result)
+	}
+	if !containsSubstring(result, "Where: Room 1") {
+		t.Errorf("Expected location line, got '%s'", result)
+	}
+	if !containsSubstring(result, "When: Thu 2024-06-06 - Fri 2024-06-07 (all day)") {
+		t.Errorf("Expected multi-day all-day event, got '%s'", result)
+	}
+}
+
+func TestParseSearchFilters(t *testing.T) {
+	now := time.Date(2024, 6, 5, 10, 0, 0, 0, time.UTC)
+	unread := true
+
+	filters, err := parseSearchFilters(SearchMessagesArgs{From: "bob", After: "2024-06-01", Before: "2024-06-03", Unread: &unread, Importance: "High"}, now)
+	if err != nil {
+		t.Fatalf("parseSearchFilters failed: %v", err)
+	}
+	if !filters.After.Equal(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected after: %v", filters.After)
+	}
+	// A date-only before includes the whole day
+	if !filters.Before.Equal(time.Date(2024, 6, 4, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected before: %v", filters.Before)
+	}
+	if filters.Importance != ImportanceHigh {
+		t.Errorf("Expected importance to be lower-cased, got %q", filters.Importance)
+	}
+	if filters.IsEmpty() {
+		t.Error("Expected filters to be set")
+	}
+
+	description := describeSearchFilters(filters)
+	if description != "from: bob, after: 2024-06-01 00:00, before: 2024-06-04 00:00, unread, high importance" {
+		t.Errorf("Unexpected description: %q", description)
+	}
+
+	if _, err := parseSearchFilters(SearchMessagesArgs{After: "last week"}, now); err == nil {
+		t.Error("Expected error for invalid after date")
+	}
+	if empty, _ := parseSearchFilters(SearchMessagesArgs{Query: "report"}, now); !empty.IsEmpty() {
+		t.Errorf("Expected no filters, got %+v", empty)
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2024, 6, 5, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value    string
+		expected time.Time
+	}{
+		{"24h", now.Add(-24 * time.Hour)},
+		{"90m", now.Add(-90 * time.Minute)},
+		{"7d", now.AddDate(0, 0, -7)},
+		{"2024-06-01", time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{"2024-06-04T08:15:00Z", time.Date(2024, 6, 4, 8, 15, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		since, err := parseSince(tt.value, now)
+		if err != nil {
+			t.Errorf("parseSince(%q) failed: %v", tt.value, err)
+			continue
+		}
+		if !since.Equal(tt.expected) {
+			t.Errorf("parseSince(%q) = %v, expected %v", tt.value, since, tt.expected)
+		}
+	}
+
+	for _, value := range []string{"yesterday", "-1h", "0d", "2024-07-01"} {
+		if _, err := parseSince(value, now); err == nil {
+			t.Errorf("Expected error for %q", value)
+		}
+	}
+}
+
+func TestBodyWindow(t *testing.T) {
+	text := "héllo wörld"
+	tests := []struct {
+		offset, maxChars int
+		want             string
+	}{
+		{0, 100, "héllo wörld"},
+		{0, 5, "héllo"},
+		{6, 3, "wör"},
+		{9, 5, "ld"},
+		{11, 5, ""},
+		{20, 5, ""},
+	}
+	for _, tt := range tests {
+		got, total := bodyWindow(text, tt.offset, tt.maxChars)
+		if got != tt.want || total != 11 {
+			t.Errorf("bodyWindow(%d, %d) = %q, %d; want %q, 11", tt.offset, tt.maxChars, got, total, tt.want)
+		}
+	}
+}
+
+func TestFormatContactListSimple(t *testing.T) {
+	if result := formatContactList(nil); result != "No contacts found." {
+		t.Errorf("Expected 'No contacts found.', got '%s'", result)
+	}
+
+	result := formatContactList([]Contact{
+		{ID: "c1", Name: "Bob Smith", Emails: []string{"bob@example.com"}, Company: "Contoso", Department: "Finance", JobTitle: "Analyst",
+			Phones: map[string]string{"mobile": "555-0101", "business": "555-0100"}},
+	})
+	if !containsSubstring(result, "1. Bob Smith") || !containsSubstring(result, "Email: bob@example.com") {
+		t.Errorf("Expected contact name and email, got '%s'", result)
+	}
+	if !containsSubstring(result, "Organization: Analyst, Finance, Contoso") {
+		t.Errorf("Expected organization line, got '%s'", result)
+	}
+	if !containsSubstring(result, "Phone: 555-0100 (business), 555-0101 (mobile)") {
+		t.Errorf("Expected phones in stable order, got '%s'", result)
+	}
+}
+
 func TestOutlookServerScriptEmbedded(t *testing.T) {
 	if outlookServerScript == "" {
 		t.Error("Embedded PowerShell script should not be empty")
@@ -43,6 +312,24 @@ func TestOutlookServerScriptEmbedded(t *testing.T) {
 		"$listener = New-Object System.Net.HttpListener",
 		"/messages",
 		"/search",
+		"/folders",
+		"/send",
+		"/drafts",
+		"/attachments",
+		"/export",
+		"/calendar/events",
+		"/freebusy",
+		"/oof",
+		"/profile",
+		"/tasks",
+		"/contacts",
+		"/conversation",
+		"/rules",
+		"/accounts",
+		"/health", "/reconnect", "/version",
+		"/categories",
+		"OUTLOOK_ALLOW_SEND",
+		"OUTLOOK_SERVER_TOKEN",
 	}
 
 	for _, content := range expectedContent {
@@ -0,0 +1,141 @@
+package outlook
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// healthErrorWindow is how far back request errors count as recent
+const healthErrorWindow = 15 * time.Minute
+
+// maxTrackedErrors bounds the request error timestamps kept in memory
+const maxTrackedErrors = 1000
+
+// healthTracker records supervisor and request events for the health_check tool.
+// The zero value is ready to use.
+type healthTracker struct {
+	mu             sync.Mutex
+	startedAt      time.Time
+	lastRestart    time.Time
+	restarts       int
+	failedRestarts int
+	processExits   int
+	requestErrors  []time.Time
+	lastError      string
+	lastErrorAt    time.Time
+}
+
+// setLastError notes the most recent error of any kind; the caller holds h.mu
+func (h *healthTracker) setLastError(err error, at time.Time) {
+	h.lastError = err.Error()
+	h.lastErrorAt = at
+}
+
+// recordProcessExit notes that the PowerShell process exited unexpectedly
+func (h *healthTracker) recordProcessExit(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.processExits++
+	if err == nil {
+		err = fmt.Errorf("PowerShell process exited")
+	}
+	h.setLastError(fmt.Errorf("PowerShell process exited: %w", err), time.Now())
+}
+
+// recordRestart notes a successful restart of the PowerShell process
+func (h *healthTracker) recordRestart() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.restarts++
+	h.lastRestart = time.Now()
+}
+
+// recordFailedRestart notes a restart attempt that did not bring the server back
+func (h *healthTracker) recordFailedRestart(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.failedRestarts++
+	h.setLastError(err, time.Now())
+}
+
+// recordRequestError notes a failed request to the PowerShell server
+func (h *healthTracker) recordRequestError(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	h.requestErrors = append(h.requestErrors, now)
+	if len(h.requestErrors) > maxTrackedErrors {
+		h.requestErrors = h.requestErrors[len(h.requestErrors)-maxTrackedErrors:]
+	}
+	h.setLastError(err, now)
+}
+
+// snapshot copies the tracked state into status, counting request errors within
+// healthErrorWindow of now
+func (h *healthTracker) snapshot(status *HealthStatus, now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	cutoff := now.Add(-healthErrorWindow)
+	recent := h.requestErrors[:0]
+	for _, at := range h.requestErrors {
+		if at.After(cutoff) {
+			recent = append(recent, at)
+		}
+	}
+	h.requestErrors = recent
+
+	status.ManagerStartedAt = h.startedAt
+	status.Restarts = h.restarts
+	status.FailedRestarts = h.failedRestarts
+	status.ProcessExits = h.processExits
+	status.RecentRequestErrors = len(recent)
+	status.LastError = h.lastError
+	if !h.lastRestart.IsZero() {
+		lastRestart := h.lastRestart
+		status.LastRestart = &lastRestart
+	}
+	if !h.lastErrorAt.IsZero() {
+		lastErrorAt := h.lastErrorAt
+		status.LastErrorAt = &lastErrorAt
+	}
+}
+
+// HealthCheck probes the PowerShell server and reports its state together with
+// the supervisor's restart and error history. It never fails: an unreachable
+// server is reported in the returned status.
+func (m *Manager) HealthCheck() *HealthStatus {
+	status := &HealthStatus{Backend: BackendCOM, Restarting: m.restarting.Load()}
+
+	// Probe once without retries so a down server is reported promptly
+	body, err := m.doRequest(http.MethodGet, "/health", nil)
+	if err != nil {
+		status.SidecarError = err.Error()
+	} else {
+		var sidecar SidecarHealth
+		if err := json.Unmarshal(body, &sidecar); err != nil {
+			status.SidecarError = fmt.Sprintf("failed to parse response: %v", err)
+		} else {
+			status.SidecarReachable = true
+			status.Sidecar = &sidecar
+		}
+	}
+
+	m.health.snapshot(status, time.Now())
+
+	return status
+}
+
+// Reconnect makes the PowerShell server drop its Outlook COM handle and bind
+// again, for when Outlook was closed and reopened. The listener keeps running.
+func (m *Manager) Reconnect() error {
+	_, err := m.makeRequestWithBody(http.MethodPost, "/reconnect", nil)
+	return err
+}
@@ -0,0 +1,124 @@
+package outlook
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHealthTracker(t *testing.T) {
+	var tracker healthTracker
+	tracker.startedAt = time.Now().Add(-time.Hour)
+
+	tracker.recordProcessExit(fmt.Errorf("exit status 1"))
+	tracker.recordFailedRestart(fmt.Errorf("server did not start within timeout period"))
+	tracker.recordRestart()
+	tracker.recordRequestError(fmt.Errorf("request failed: connection refused"))
+	tracker.recordRequestError(fmt.Errorf("server error (503): Outlook is not available"))
+
+	// An error outside the window is no longer recent
+	tracker.requestErrors[0] = time.Now().Add(-2 * healthErrorWindow)
+
+	var status HealthStatus
+	tracker.snapshot(&status, time.Now())
+
+	if status.ProcessExits != 1 || status.FailedRestarts != 1 || status.Restarts != 1 {
+		t.Errorf("Unexpected counters: %+v", status)
+	}
+	if status.RecentRequestErrors != 1 {
+		t.Errorf("Expected 1 recent request error, got %d", status.RecentRequestErrors)
+	}
+	if status.LastRestart == nil || status.LastErrorAt == nil {
+		t.Errorf("Expected last restart and last error times, got %+v", status)
+	}
+	if !strings.Contains(status.LastError, "Outlook is not available") {
+		t.Errorf("Expected most recent error, got %q", status.LastError)
+	}
+}
+
+func TestManagerHealthCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/health":
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"pid": 4242, "startedAt": "2024-06-03T09:00:00Z", "powershellVersion": "5.1.19041.4291",
+				"outlookConnected": true, "outlookVersion": "16.0.0.17328", "profile": "Outlook", "connectionMode": "cached"}`))
+		case "/messages/missing":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": "Message not found", "code": "MESSAGE_NOT_FOUND"}`))
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"error": "Outlook is not available", "code": "OUTLOOK_UNAVAILABLE"}`))
+		}
+	}))
+	defer server.Close()
+
+	manager := &Manager{
+		baseURL: server.URL,
+		client:  &http.Client{Timeout: 5 * time.Second},
+	}
+
+	// Only server-side failures count against health
+	manager.GetMessage("missing")
+	manager.ListFolders("")
+
+	status := manager.HealthCheck()
+	if !status.SidecarReachable || status.Sidecar.PID != 4242 || !status.Sidecar.OutlookConnected {
+		t.Errorf("Unexpected sidecar health: %+v", status)
+	}
+	if status.RecentRequestErrors != 1 {
+		t.Errorf("Expected 1 recent request error, got %d", status.RecentRequestErrors)
+	}
+
+	report := formatHealthStatus(status, time.Date(2024, 6, 3, 10, 30, 0, 0, time.UTC))
+	if !strings.Contains(report, "Outlook MCP Health: OK") || !strings.Contains(report, "up 1h30m0s") {
+		t.Errorf("Unexpected report: %s", report)
+	}
+	if !strings.Contains(report, "Outlook: connected (version 16.0.0.17328, profile Outlook, cached)") {
+		t.Errorf("Expected Outlook connection details, got: %s", report)
+	}
+
+	server.Close()
+	status = manager.HealthCheck()
+	if status.SidecarReachable || status.SidecarError == "" {
+		t.Errorf("Expected unreachable sidecar, got %+v", status)
+	}
+	if report := formatHealthStatus(status, time.Now()); !strings.Contains(report, "Outlook MCP Health: DOWN") {
+		t.Errorf("Expected DOWN report, got: %s", report)
+	}
+}
+
+func TestManagerReconnect(t *testing.T) {
+	available := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/reconnect" || r.Method != http.MethodPost {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if !available {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"error": "Outlook is not available", "code": "OUTLOOK_UNAVAILABLE"}`))
+			return
+		}
+		w.Write([]byte(`{"pid": 4242, "outlookConnected": true, "reconnects": 1}`))
+	}))
+	defer server.Close()
+
+	manager := &Manager{
+		baseURL: server.URL,
+		client:  &http.Client{Timeout: 5 * time.Second},
+	}
+
+	if err := manager.Reconnect(); err == nil {
+		t.Error("Expected an error while Outlook is unavailable")
+	}
+
+	available = true
+	if err := manager.Reconnect(); err != nil {
+		t.Errorf("Reconnect failed: %v", err)
+	}
+}
@@ -0,0 +1,268 @@
+package outlook
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// A persistent instance is a PowerShell server that keeps running after
+// outlook-mcp exits, so the next outlook-mcp attaches to it instead of
+// waiting for Outlook's COM warmup again. OUTLOOK_INSTANCE names it; its
+// port, token and settings are recorded in a state file readable only by the
+// current user, and its output goes to a log file beside it.
+
+// instanceHealthInterval is how often an attached instance is checked, and
+// instanceHealthFailures how many failed checks in a row count as a crash
+const (
+	instanceHealthInterval = 5 * time.Second
+	instanceHealthFailures = 3
+)
+
+// validInstanceName keeps instance names usable in file names
+var validInstanceName = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// instanceState records a running persistent instance
+type instanceState struct {
+	PID                  int       `json:"pid"`
+	Port                 int       `json:"port"`
+	Token                string    `json:"token"`
+	PowerShell           string    `json:"powershell"`
+	ScriptSHA256         string    `json:"script_sha256"` // Replaced when outlook-mcp runs a different script
+	AllowSend            bool      `json:"allow_send"`
+	AllowPermanentDelete bool      `json:"allow_permanent_delete"`
+	StartedAt            time.Time `json:"started_at"`
+}
+
+// GetInstanceName returns the persistent instance named by OUTLOOK_INSTANCE,
+// or an empty string when the PowerShell server should exit with outlook-mcp
+func GetInstanceName() (string, error) {
+	name := os.Getenv("OUTLOOK_INSTANCE")
+	if name != "" && !validInstanceName.MatchString(name) {
+		return "", fmt.Errorf("invalid OUTLOOK_INSTANCE %q: use up to 64 letters, digits, '-' and '_'", name)
+	}
+	return name, nil
+}
+
+// defaultInstanceStatePath returns the state file of the named instance in
+// the user's cache directory
+func defaultInstanceStatePath(name string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find a directory for instance state: %w", err)
+	}
+	return filepath.Join(dir, "my-mcp", "outlook-instance-"+name+".json"), nil
+}
+
+// instanceLogPath returns the log file beside an instance's state file
+func instanceLogPath(statePath string) string {
+	return strings.TrimSuffix(statePath, ".json") + ".log"
+}
+
+// scriptHash identifies a PowerShell server script
+func scriptHash(script string) string {
+	sum := sha256.Sum256([]byte(script))
+	return hex.EncodeToString(sum[:])
+}
+
+// readInstanceState reads a state file; a missing file returns nil
+func readInstanceState(path string) (*instanceState, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var state instanceState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("invalid instance state %s: %w", path, err)
+	}
+	return &state, nil
+}
+
+// writeInstanceState saves a state file readable only by the current user,
+// since it holds the server's bearer token
+func writeInstanceState(path string, state instanceState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create instance state directory: %w", err)
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// matches reports whether a running instance can serve this manager: it
+// must run the same script in the same PowerShell with the same
+// permissions, and the configured port if one is set
+func (s *instanceState) matches(m *Manager) bool {
+	if s.ScriptSHA256 != m.scriptSHA256 || s.PowerShell != m.powershell.Executable {
+		return false
+	}
+	if s.AllowSend != m.allowSend || s.AllowPermanentDelete != m.allowPurge {
+		return false
+	}
+	return os.Getenv("OUTLOOK_SERVER_PORT") == "" || s.Port == m.port
+}
+
+// probeInstance reports whether the server in state answers /health with its token
+func (m *Manager) probeInstance(state *instanceState) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("http://127.0.0.1:%d/health", state.Port), nil)
+	if err != nil {
+		return false
+	}
+	req.Header.Set("Authorization", "Bearer "+state.Token)
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode == http.StatusOK
+}
+
+// attachInstance connects to the recorded persistent instance when it is
+// running and matches this manager's settings, and reports whether it did.
+// A running instance that doesn't match is stopped so a new one can take
+// its place.
+func (m *Manager) attachInstance() bool {
+	state, err := readInstanceState(m.instanceState)
+	if err != nil {
+		m.logSupervisor("Ignoring persistent instance state: %v", err)
+		return false
+	}
+	if state == nil || !m.probeInstance(state) {
+		return false
+	}
+	if !state.matches(m) {
+		m.logSupervisor("Replacing persistent instance %q (pid %d): it runs a different script or settings", m.instance, state.PID)
+		killInstance(state.PID)
+		return false
+	}
+	// The state file may outlive the build that wrote it; ask the server itself
+	if err := m.checkServerVersion(fmt.Sprintf("http://127.0.0.1:%d", state.Port), state.Token); err != nil {
+		m.logSupervisor("Replacing persistent instance %q (pid %d): %v", m.instance, state.PID, err)
+		killInstance(state.PID)
+		return false
+	}
+
+	m.procMu.Lock()
+	m.port, m.token = state.Port, state.Token
+	m.baseURL = fmt.Sprintf("http://127.0.0.1:%d", state.Port)
+	m.instancePID = state.PID
+	m.generation++
+	generation := m.generation
+	m.procMu.Unlock()
+
+	m.logSupervisor("Attached to persistent instance %q (pid %d, port %d); its output goes to %s", m.instance, state.PID, state.Port, instanceLogPath(m.instanceState))
+	go m.monitorInstance(generation)
+	return true
+}
+
+// recordInstance saves the state of a newly started persistent instance
+func (m *Manager) recordInstance(pid int) error {
+	return writeInstanceState(m.instanceState, instanceState{
+		PID:                  pid,
+		Port:                 m.port,
+		Token:                m.token,
+		PowerShell:           m.powershell.Executable,
+		ScriptSHA256:         m.scriptSHA256,
+		AllowSend:            m.allowSend,
+		AllowPermanentDelete: m.allowPurge,
+		StartedAt:            time.Now(),
+	})
+}
+
+// monitorInstance checks an attached instance, which is not this process's
+// child, and signals a restart like monitorProcess when it stops answering
+func (m *Manager) monitorInstance(generation uint64) {
+	ticker := time.NewTicker(instanceHealthInterval)
+	defer ticker.Stop()
+
+	failures := 0
+	for {
+		select {
+		case <-m.supervisorCtx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		m.procMu.Lock()
+		current := m.generation == generation
+		state := &instanceState{Port: m.port, Token: m.token}
+		m.procMu.Unlock()
+		if !current || m.isShutdown.Load() {
+			return
+		}
+
+		if m.probeInstance(state) {
+			failures = 0
+			continue
+		}
+		if failures++; failures < instanceHealthFailures {
+			continue
+		}
+
+		err := fmt.Errorf("persistent instance stopped answering health checks")
+		m.logSupervisor("PowerShell process exited with error: %v", err)
+		m.health.recordProcessExit(err)
+		m.restarting.Store(true)
+		select {
+		case m.restartChan <- true:
+		default:
+		}
+		return
+	}
+}
+
+// killInstance ends a persistent instance's process, if it is still running
+func killInstance(pid int) {
+	if pid <= 0 {
+		return
+	}
+	if process, err := os.FindProcess(pid); err == nil {
+		process.Kill()
+	}
+}
+
+// StopInstance stops the persistent instance named by OUTLOOK_INSTANCE and
+// removes its state. It only signals a process that answers with the
+// recorded token, so a reused PID is never killed.
+func StopInstance() error {
+	name, err := GetInstanceName()
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		return fmt.Errorf("no persistent instance named: set --instance or OUTLOOK_INSTANCE")
+	}
+	path, err := defaultInstanceStatePath(name)
+	if err != nil {
+		return err
+	}
+	state, err := readInstanceState(path)
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		return fmt.Errorf("persistent instance %q is not running", name)
+	}
+
+	m := &Manager{client: &http.Client{Timeout: 5 * time.Second}}
+	if m.probeInstance(state) {
+		killInstance(state.PID)
+	}
+	return os.Remove(path)
+}
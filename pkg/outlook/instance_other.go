@@ -0,0 +1,14 @@
+//go:build !windows
+
+package outlook
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detachProcess starts a persistent instance in its own session, so it
+// outlives outlook-mcp and ignores signals sent to its process group
+func detachProcess(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
@@ -0,0 +1,139 @@
+package outlook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetInstanceName(t *testing.T) {
+	for name, valid := range map[string]bool{
+		"":                      true,
+		"work":                  true,
+		"Work_2-main":           true,
+		"../escape":             false,
+		"has space":             false,
+		"a/b":                   false,
+		strings.Repeat("a", 65): false,
+	} {
+		t.Setenv("OUTLOOK_INSTANCE", name)
+		got, err := GetInstanceName()
+		if (err == nil) != valid {
+			t.Errorf("GetInstanceName(%q) error = %v, want valid %v", name, err, valid)
+		}
+		if valid && got != name {
+			t.Errorf("GetInstanceName(%q) = %q", name, got)
+		}
+	}
+}
+
+func TestInstanceStateRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "outlook-instance-work.json")
+	if state, err := readInstanceState(path); err != nil || state != nil {
+		t.Fatalf("readInstanceState of a missing file = %v, %v; want nil", state, err)
+	}
+
+	want := instanceState{PID: 42, Port: 8123, Token: "secret", ScriptSHA256: scriptHash(outlookServerScript), AllowSend: true}
+	if err := writeInstanceState(path, want); err != nil {
+		t.Fatalf("writeInstanceState failed: %v", err)
+	}
+	if runtime.GOOS != "windows" {
+		if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+			t.Errorf("State file mode = %v, %v; want 0600 since it holds the token", info.Mode().Perm(), err)
+		}
+	}
+
+	got, err := readInstanceState(path)
+	if err != nil || got == nil {
+		t.Fatalf("readInstanceState = %v, %v", got, err)
+	}
+	if got.PID != want.PID || got.Port != want.Port || got.Token != want.Token || !got.AllowSend {
+		t.Errorf("readInstanceState = %+v, want %+v", got, want)
+	}
+	if instanceLogPath(path) != filepath.Join(filepath.Dir(path), "outlook-instance-work.log") {
+		t.Errorf("instanceLogPath = %q", instanceLogPath(path))
+	}
+}
+
+func TestAttachInstance(t *testing.T) {
+	t.Setenv("OUTLOOK_SERVER_PORT", "")
+	runningScript := scriptHash(outlookServerScript) // What the server reports from /version; empty for an older build
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.URL.Path == "/health":
+			w.WriteHeader(http.StatusOK)
+		case r.URL.Path == "/version" && runningScript != "":
+			json.NewEncoder(w).Encode(SidecarVersion{ScriptSHA256: runningScript, PID: 42})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	serverURL, _ := url.Parse(server.URL)
+	port, _ := strconv.Atoi(serverURL.Port())
+
+	newManager := func(t *testing.T, state instanceState) *Manager {
+		path := filepath.Join(t.TempDir(), "outlook-instance-work.json")
+		if err := writeInstanceState(path, state); err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		return &Manager{
+			client:        &http.Client{Timeout: 5 * time.Second},
+			supervisorCtx: ctx,
+			restartChan:   make(chan bool, 1),
+			scriptSHA256:  scriptHash(outlookServerScript),
+			instance:      "work",
+			instanceState: path,
+		}
+	}
+	// PID 0 keeps a mismatched instance from signalling any real process
+	running := instanceState{Port: port, Token: "secret", ScriptSHA256: scriptHash(outlookServerScript)}
+
+	m := newManager(t, running)
+	if !m.attachInstance() {
+		t.Fatal("Expected to attach to a matching instance")
+	}
+	if m.port != port || m.token != "secret" || m.baseURL != server.URL {
+		t.Errorf("Attached to port %d with token %q at %s, want the recorded instance", m.port, m.token, m.baseURL)
+	}
+
+	mismatched := map[string]instanceState{
+		"wrong token":     {Port: port, Token: "stale", ScriptSHA256: scriptHash(outlookServerScript)},
+		"other script":    {Port: port, Token: "secret", ScriptSHA256: "0000"},
+		"other send mode": {Port: port, Token: "secret", ScriptSHA256: scriptHash(outlookServerScript), AllowSend: true},
+	}
+	for name, state := range mismatched {
+		if newManager(t, state).attachInstance() {
+			t.Errorf("%s: attached to an instance that doesn't match", name)
+		}
+	}
+
+	missing := newManager(t, running)
+	os.Remove(missing.instanceState)
+	if missing.attachInstance() {
+		t.Error("Attached without a state file")
+	}
+
+	// The state file matches, but the server itself was started by another build
+	for name, script := range map[string]string{"older build without /version": "", "other build": "0000"} {
+		runningScript = script
+		if newManager(t, running).attachInstance() {
+			t.Errorf("%s: attached to a stale server", name)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+//go:build windows
+
+package outlook
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detachedProcess is DETACHED_PROCESS: the child gets no console of ours
+const detachedProcess = 0x00000008
+
+// detachProcess starts a persistent instance outside outlook-mcp's console
+// and process group, so it outlives outlook-mcp and ignores its Ctrl+C
+func detachProcess(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP | detachedProcess,
+		HideWindow:    true,
+	}
+}
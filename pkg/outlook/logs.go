@@ -0,0 +1,141 @@
+package outlook
+
+import (
+	"bytes"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Streams recorded in the server log buffer
+const (
+	LogStreamStdout     = "stdout"     // PowerShell server output, e.g. request log and startup messages
+	LogStreamStderr     = "stderr"     // PowerShell errors, e.g. COM exceptions
+	LogStreamSupervisor = "supervisor" // Process exits and restarts observed by the manager
+)
+
+// defaultLogLines is how many log lines are kept unless OUTLOOK_LOG_LINES says otherwise
+const defaultLogLines = 1000
+
+// maxLogLineLength truncates pathological lines such as dumped objects
+const maxLogLineLength = 2000
+
+// logBuffer is a ring buffer of the most recent server log lines. The zero
+// value is not usable; create one with newLogBuffer.
+type logBuffer struct {
+	mu      sync.Mutex
+	lines   []ServerLogLine
+	next    int // Index the next line is written to once the buffer is full
+	full    bool
+	dropped int // Lines overwritten since the manager started
+}
+
+// newLogBuffer creates a buffer holding up to capacity lines
+func newLogBuffer(capacity int) *logBuffer {
+	return &logBuffer{lines: make([]ServerLogLine, 0, capacity)}
+}
+
+// logCapacity returns the buffer size from OUTLOOK_LOG_LINES or the default
+func logCapacity() int {
+	if value := os.Getenv("OUTLOOK_LOG_LINES"); value != "" {
+		if lines, err := strconv.Atoi(value); err == nil && lines > 0 {
+			return lines
+		}
+	}
+	return defaultLogLines
+}
+
+// add records a line, overwriting the oldest one when the buffer is full
+func (b *logBuffer) add(stream, text string) {
+	if len(text) > maxLogLineLength {
+		text = text[:maxLogLineLength] + "..."
+	}
+	line := ServerLogLine{Time: time.Now(), Stream: stream, Text: text}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.full {
+		b.lines = append(b.lines, line)
+		b.full = len(b.lines) == cap(b.lines)
+		return
+	}
+	b.lines[b.next] = line
+	b.next = (b.next + 1) % len(b.lines)
+	b.dropped++
+}
+
+// snapshot returns the newest limit lines (all when limit is 0) of the given
+// stream ("" for all) that contain filter, oldest first
+func (b *logBuffer) snapshot(limit int, stream, filter string) *ServerLogsResponse {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ordered := append(append([]ServerLogLine{}, b.lines[b.next:]...), b.lines[:b.next]...)
+
+	needle := strings.ToLower(filter)
+	matches := []ServerLogLine{}
+	for _, line := range ordered {
+		if stream != "" && line.Stream != stream {
+			continue
+		}
+		if needle != "" && !strings.Contains(strings.ToLower(line.Text), needle) {
+			continue
+		}
+		matches = append(matches, line)
+	}
+
+	response := &ServerLogsResponse{Capacity: cap(b.lines), Dropped: b.dropped, Matched: len(matches)}
+	if limit > 0 && len(matches) > limit {
+		matches = matches[len(matches)-limit:]
+	}
+	response.Lines = matches
+	return response
+}
+
+// writer returns an io.Writer that records each complete line written to it
+// under stream; each process gets its own writers so partial lines never mix
+func (b *logBuffer) writer(stream string) *logWriter {
+	return &logWriter{buffer: b, stream: stream}
+}
+
+// logWriter splits process output into lines for a logBuffer
+type logWriter struct {
+	mu      sync.Mutex
+	buffer  *logBuffer
+	stream  string
+	partial []byte
+}
+
+// Write records every complete line in p and keeps any trailing partial line
+func (w *logWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+		w.record(w.partial[:i])
+		w.partial = w.partial[i+1:]
+	}
+	// A runaway line without newlines is flushed rather than buffered forever
+	if len(w.partial) > maxLogLineLength {
+		w.record(w.partial)
+		w.partial = nil
+	}
+	return len(p), nil
+}
+
+// record adds a line, dropping Windows line endings and blank lines
+func (w *logWriter) record(line []byte) {
+	text := strings.TrimRight(string(line), "\r")
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	w.buffer.add(w.stream, text)
+}
@@ -0,0 +1,82 @@
+package outlook
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLogBufferWrapsAround(t *testing.T) {
+	buffer := newLogBuffer(3)
+	for _, text := range []string{"one", "two", "three", "four", "five"} {
+		buffer.add(LogStreamStdout, text)
+	}
+
+	response := buffer.snapshot(0, "", "")
+	var texts []string
+	for _, line := range response.Lines {
+		texts = append(texts, line.Text)
+	}
+	if strings.Join(texts, ",") != "three,four,five" {
+		t.Errorf("Expected the three newest lines oldest first, got %v", texts)
+	}
+	if response.Dropped != 2 || response.Capacity != 3 {
+		t.Errorf("Expected 2 dropped lines and capacity 3, got %d and %d", response.Dropped, response.Capacity)
+	}
+
+	if limited := buffer.snapshot(2, "", ""); len(limited.Lines) != 2 || limited.Lines[0].Text != "four" || limited.Matched != 3 {
+		t.Errorf("Expected the two newest of 3 matches, got %+v", limited)
+	}
+}
+
+func TestLogBufferFilters(t *testing.T) {
+	buffer := newLogBuffer(10)
+	buffer.add(LogStreamStdout, "GET /messages")
+	buffer.add(LogStreamStderr, "Error processing request: Outlook is not available")
+	buffer.add(LogStreamSupervisor, "PowerShell process exited with error: exit status 1")
+
+	if response := buffer.snapshot(0, LogStreamStderr, ""); len(response.Lines) != 1 || response.Lines[0].Stream != LogStreamStderr {
+		t.Errorf("Expected only the stderr line, got %+v", response.Lines)
+	}
+	if response := buffer.snapshot(0, "", "OUTLOOK IS NOT"); len(response.Lines) != 1 {
+		t.Errorf("Expected a case-insensitive text match, got %+v", response.Lines)
+	}
+}
+
+func TestLogWriterSplitsLines(t *testing.T) {
+	buffer := newLogBuffer(10)
+	writer := buffer.writer(LogStreamStdout)
+
+	writer.Write([]byte("Starting Outlook REST API server\r\nSucc"))
+	writer.Write([]byte("essfully connected to Outlook\n\n"))
+	writer.Write([]byte("partial"))
+
+	response := buffer.snapshot(0, "", "")
+	if len(response.Lines) != 2 {
+		t.Fatalf("Expected 2 complete lines, got %+v", response.Lines)
+	}
+	if response.Lines[0].Text != "Starting Outlook REST API server" || response.Lines[1].Text != "Successfully connected to Outlook" {
+		t.Errorf("Unexpected lines %+v", response.Lines)
+	}
+}
+
+func TestManagerServerLogs(t *testing.T) {
+	manager := &Manager{logs: newLogBuffer(10)}
+	manager.logSupervisor("PowerShell server restarted successfully")
+
+	response, err := manager.ServerLogs(10, LogStreamSupervisor, "")
+	if err != nil {
+		t.Fatalf("ServerLogs failed: %v", err)
+	}
+	if len(response.Lines) != 1 {
+		t.Errorf("Expected the supervisor event, got %+v", response.Lines)
+	}
+
+	if _, err := manager.ServerLogs(10, "stdin", ""); err == nil {
+		t.Error("Expected an invalid stream to be rejected")
+	}
+
+	formatted := formatServerLogs(response)
+	if !strings.Contains(formatted, "[supervisor] PowerShell server restarted successfully") {
+		t.Errorf("Unexpected formatted logs:\n%s", formatted)
+	}
+}
@@ -1,41 +1,123 @@
 package outlook
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	_ "embed"
+
+	"github.com/kevsmith/my-mcp/pkg/shared"
 )
 
 //go:embed scripts/outlook-server.ps1
 var outlookServerScript string
 
-// Manager handles the PowerShell server process and REST API communication
+// ErrServerRestarting is wrapped into request errors that occur while the
+// supervisor is restarting the PowerShell server
+var ErrServerRestarting = shared.NewError(shared.CodeUnavailable, "outlook server is restarting, try again in a few seconds")
+
+// errTransport marks requests that got no HTTP response at all
+var errTransport = shared.NewError(shared.CodeUnavailable, "request failed")
+
+// Request retry defaults; the backoff doubles from defaultRetryDelay up to
+// maxRetryDelay, which covers the supervisor's restart delay plus startup
+const (
+	defaultRequestRetries = 4
+	defaultRetryDelay     = 1 * time.Second
+	maxRetryDelay         = 8 * time.Second
+)
+
+// Free/busy lookups are limited to what Outlook typically publishes and to a
+// small meeting's worth of attendees, since each one is a separate COM lookup
+const (
+	maxFreeBusyRange     = 31 * 24 * time.Hour
+	maxFreeBusyAttendees = 20
+)
+
+// maxSearchPageSize caps the page size of SearchMessages so a single response stays small
+const maxSearchPageSize = 100
+
+// Manager handles the PowerShell server process and REST API communication.
+//
+// The process fields are guarded by procMu. Each started process gets a new
+// generation; a monitor only requests a restart when the process it watched is
+// still the current generation, so deliberately replaced processes are not
+// restarted twice.
 type Manager struct {
 	port          int
-	cmd           *exec.Cmd
+	token         string // Bearer token the PowerShell server requires on every request
 	baseURL       string
 	client        *http.Client
 	supervisorCtx context.Context
 	cancelFunc    context.CancelFunc
 	restartChan   chan bool
-	isShutdown    bool
+	isShutdown    atomic.Bool
+	restarting    atomic.Bool // Set while the supervisor is replacing a crashed process
+	maxRetries    int         // Retries after transport errors; zero disables retrying
+	retryDelay    time.Duration
+	allowSend     bool
+	allowPurge    bool
+	health        healthTracker
+	cache         *ResponseCache // Nil when caching is disabled
+	logs          *logBuffer     // Captured server output; nil disables capture
+	powershell    PowerShellConfig
+	scriptSHA256  string // Hash of the server script, identifying persistent instances that run it
+	instance      string // Persistent instance name; empty when the server exits with the manager
+	instanceState string // State file of the persistent instance
+
+	procMu      sync.Mutex
+	cmd         *exec.Cmd
+	procDone    chan struct{} // Closed when cmd has exited
+	instancePID int           // Attached persistent instance, which is not our child
+	generation  uint64
 }
 
 // NewManager creates a new Outlook manager and starts the PowerShell server
 func NewManager() (*Manager, error) {
-	port := 8080
-	if portEnv := os.Getenv("OUTLOOK_SERVER_PORT"); portEnv != "" {
-		if p, err := strconv.Atoi(portEnv); err == nil {
-			port = p
+	port, err := serverPort()
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := newServerToken()
+	if err != nil {
+		return nil, err
+	}
+
+	powershell := GetPowerShellConfig()
+	if _, err := powershell.lookPath(); err != nil {
+		return nil, err
+	}
+	script, err := powershell.script()
+	if err != nil {
+		return nil, err
+	}
+
+	instance, err := GetInstanceName()
+	if err != nil {
+		return nil, err
+	}
+	var statePath string
+	if instance != "" {
+		if statePath, err = defaultInstanceStatePath(instance); err != nil {
+			return nil, err
 		}
 	}
 
@@ -43,12 +125,30 @@ func NewManager() (*Manager, error) {
 
 	m := &Manager{
 		port:          port,
-		baseURL:       fmt.Sprintf("http://localhost:%d", port),
+		token:         token,
+		baseURL:       fmt.Sprintf("http://127.0.0.1:%d", port),
 		client:        &http.Client{Timeout: 30 * time.Second},
 		supervisorCtx: ctx,
 		cancelFunc:    cancel,
 		restartChan:   make(chan bool, 1),
-		isShutdown:    false,
+		maxRetries:    defaultRequestRetries,
+		retryDelay:    defaultRetryDelay,
+		allowSend:     boolFromEnv("OUTLOOK_ALLOW_SEND"),
+		allowPurge:    boolFromEnv("OUTLOOK_ALLOW_PERMANENT_DELETE"),
+		cache:         NewResponseCache(GetCacheConfig()),
+		logs:          newLogBuffer(logCapacity()),
+		powershell:    powershell,
+		scriptSHA256:  scriptHash(script),
+		instance:      instance,
+		instanceState: statePath,
+	}
+
+	m.health.startedAt = time.Now()
+
+	// A persistent instance left by an earlier run is already warmed up
+	if m.instance != "" && m.attachInstance() {
+		go m.supervisorLoop()
+		return m, nil
 	}
 
 	if err := m.startPowerShellServer(); err != nil {
@@ -68,60 +168,145 @@ func NewManager() (*Manager, error) {
 	return m, nil
 }
 
-// startPowerShellServer starts the PowerShell server process
-func (m *Manager) startPowerShellServer() error {
-	// Create temp file for the script
-	tmpFile, err := os.CreateTemp("", "outlook-server-*.ps1")
+// serverPort returns the port from OUTLOOK_SERVER_PORT, or a free loopback port
+// chosen by the OS when it is unset
+func serverPort() (int, error) {
+	if portEnv := os.Getenv("OUTLOOK_SERVER_PORT"); portEnv != "" {
+		port, err := strconv.Atoi(portEnv)
+		if err != nil || port < 1 || port > 65535 {
+			return 0, fmt.Errorf("invalid OUTLOOK_SERVER_PORT %q", portEnv)
+		}
+		return port, nil
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
 	if err != nil {
-		return fmt.Errorf("failed to create temp file: %w", err)
+		return 0, fmt.Errorf("failed to find a free port: %w", err)
+	}
+	defer listener.Close()
+
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
+
+// newServerToken generates the random bearer token shared with the PowerShell server
+func newServerToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate server token: %w", err)
 	}
+	return hex.EncodeToString(buf), nil
+}
 
-	// Write the embedded script to the temp file
-	if _, err := tmpFile.WriteString(outlookServerScript); err != nil {
-		tmpFile.Close()
-		os.Remove(tmpFile.Name())
-		return fmt.Errorf("failed to write script: %w", err)
+// startPowerShellServer starts the PowerShell server process
+func (m *Manager) startPowerShellServer() error {
+	scriptPath, cleanup, err := m.powershell.scriptFile()
+	if err != nil {
+		return err
 	}
-	tmpFile.Close()
 
-	// Set environment variable for port
-	env := append(os.Environ(), fmt.Sprintf("OUTLOOK_SERVER_PORT=%d", m.port))
+	// Set environment variables for port and send permission
+	env := append(os.Environ(),
+		fmt.Sprintf("OUTLOOK_SERVER_PORT=%d", m.port),
+		fmt.Sprintf("OUTLOOK_SERVER_TOKEN=%s", m.token),
+		fmt.Sprintf("OUTLOOK_ALLOW_SEND=%t", m.allowSend),
+		fmt.Sprintf("OUTLOOK_ALLOW_PERMANENT_DELETE=%t", m.allowPurge),
+	)
 
 	// Start PowerShell process
-	m.cmd = exec.Command("powershell.exe", "-ExecutionPolicy", "Bypass", "-File", tmpFile.Name())
-	m.cmd.Env = env
+	cmd := exec.Command(m.powershell.Executable, m.powershell.commandArgs(scriptPath)...)
+	cmd.Env = env
 	// Note: SysProcAttr configuration is Windows-specific and would be set at runtime
 
+	// Stdout carries the MCP protocol, so the server's output is captured for
+	// get_server_logs instead of being inherited. A persistent instance
+	// outlives this process and writes to its log file instead.
+	if m.instance != "" {
+		logFile, err := os.OpenFile(instanceLogPath(m.instanceState), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+		if err != nil {
+			cleanup()
+			return fmt.Errorf("failed to open instance log: %w", err)
+		}
+		defer logFile.Close() // The child keeps its own handle
+		cmd.Stdout, cmd.Stderr = logFile, logFile
+		detachProcess(cmd)
+	} else if m.logs != nil {
+		cmd.Stdout = m.logs.writer(LogStreamStdout)
+		cmd.Stderr = m.logs.writer(LogStreamStderr)
+	}
+
 	// Start the process
-	if err := m.cmd.Start(); err != nil {
-		os.Remove(tmpFile.Name())
-		return fmt.Errorf("failed to start PowerShell: %w", err)
+	if err := cmd.Start(); err != nil {
+		cleanup()
+		return fmt.Errorf("failed to start %s: %w", m.powershell.Executable, err)
+	}
+
+	if err := m.adoptProcess(cmd); err != nil {
+		cleanup()
+		return err
+	}
+	if m.instance != "" {
+		if err := m.recordInstance(cmd.Process.Pid); err != nil {
+			m.logSupervisor("Failed to record persistent instance %q; the next run will start another: %v", m.instance, err)
+		}
 	}
 
-	// Clean up temp file in a goroutine after a delay
+	// Clean up the temp file once PowerShell has read it
 	go func() {
 		time.Sleep(5 * time.Second)
-		os.Remove(tmpFile.Name())
+		cleanup()
 	}()
 
-	// Start process monitor goroutine
-	go m.monitorProcess()
+	return nil
+}
+
+// adoptProcess makes a started process the current generation and starts its
+// monitor. A process started while Stop was running is killed instead.
+func (m *Manager) adoptProcess(cmd *exec.Cmd) error {
+	m.procMu.Lock()
+	defer m.procMu.Unlock()
+
+	if m.isShutdown.Load() {
+		cmd.Process.Kill()
+		go cmd.Wait()
+		return fmt.Errorf("manager is shut down")
+	}
+
+	m.generation++
+	m.cmd = cmd
+	m.procDone = make(chan struct{})
+
+	go m.monitorProcess(cmd, m.generation, m.procDone)
 
 	return nil
 }
 
+// retireProcess detaches the current process so its exit is not treated as a
+// crash, and returns it together with its exit channel
+func (m *Manager) retireProcess() (*exec.Cmd, chan struct{}) {
+	m.procMu.Lock()
+	defer m.procMu.Unlock()
+
+	cmd, done := m.cmd, m.procDone
+	m.generation++
+	m.cmd = nil
+	m.procDone = nil
+	m.instancePID = 0
+
+	return cmd, done
+}
+
 // waitForServer waits for the PowerShell server to be ready
 func (m *Manager) waitForServer() error {
 	maxRetries := 30
 	for i := 0; i < maxRetries; i++ {
 		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-		req, _ := http.NewRequestWithContext(ctx, "GET", m.baseURL+"/messages?page=1", nil)
+		req, _ := m.newRequest(ctx, http.MethodGet, "/health", nil)
 		resp, err := m.client.Do(req)
 		cancel()
 
 		if err == nil {
 			resp.Body.Close()
-			return nil
+			return m.checkServerVersion(m.baseURL, m.token)
 		}
 
 		time.Sleep(500 * time.Millisecond)
@@ -130,51 +315,246 @@ func (m *Manager) waitForServer() error {
 	return fmt.Errorf("server did not start within timeout period")
 }
 
-// Stop gracefully stops the PowerShell server and supervisor
+// checkServerVersion asks the server at baseURL which script it runs and
+// returns an error unless it is the one this manager starts. A server left
+// running by an older build, which has no /version endpoint, fails the check
+// instead of failing later on responses whose shape has changed.
+func (m *Manager) checkServerVersion(baseURL, token string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/version", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("version check failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized:
+		return fmt.Errorf("the server rejected the token; another process may be listening on its port")
+	default:
+		// Older scripts answer 404, or 503 while Outlook is unavailable
+		return fmt.Errorf("the server has no version endpoint (status %d); it was started by an older outlook-mcp", resp.StatusCode)
+	}
+
+	var version SidecarVersion
+	if err := json.NewDecoder(resp.Body).Decode(&version); err != nil {
+		return fmt.Errorf("failed to parse version: %w", err)
+	}
+	if version.ScriptSHA256 != m.scriptSHA256 {
+		return fmt.Errorf("the server runs script %.12s, not %.12s; it was started by a different outlook-mcp build", version.ScriptSHA256, m.scriptSHA256)
+	}
+	return nil
+}
+
+// CacheStats returns the response cache's hit and miss counts and size;
+// they stay zero when caching is disabled
+func (m *Manager) CacheStats() shared.CacheStats {
+	if m.cache == nil {
+		return shared.CacheStats{}
+	}
+	return m.cache.Stats()
+}
+
+// Stop gracefully stops the PowerShell server and supervisor. It is safe to
+// call more than once and concurrently with a restart.
 func (m *Manager) Stop() error {
-	m.isShutdown = true
+	if !m.isShutdown.CompareAndSwap(false, true) {
+		return nil
+	}
 
 	// Cancel supervisor context to stop all monitoring goroutines
 	if m.cancelFunc != nil {
 		m.cancelFunc()
 	}
 
-	if m.cmd != nil && m.cmd.Process != nil {
-		// Send interrupt signal
-		if err := m.cmd.Process.Signal(os.Interrupt); err != nil {
-			// Force kill if interrupt fails
-			return m.cmd.Process.Kill()
-		}
+	cmd, done := m.retireProcess()
 
-		// Wait for process to exit with timeout
-		done := make(chan error, 1)
-		go func() {
-			done <- m.cmd.Wait()
-		}()
+	// A persistent instance keeps running for the next outlook-mcp
+	if m.instance != "" {
+		m.logSupervisor("Leaving persistent instance %q running", m.instance)
+		return nil
+	}
+	if cmd == nil || cmd.Process == nil {
+		return nil
+	}
 
-		select {
-		case <-done:
-			return nil
-		case <-time.After(5 * time.Second):
-			return m.cmd.Process.Kill()
-		}
+	// Send interrupt signal
+	if err := cmd.Process.Signal(os.Interrupt); err != nil {
+		// Force kill if interrupt fails
+		return cmd.Process.Kill()
 	}
-	return nil
+
+	// The process monitor is the only caller of Wait; it closes done on exit
+	select {
+	case <-done:
+		return nil
+	case <-time.After(5 * time.Second):
+		return cmd.Process.Kill()
+	}
+}
+
+// boolFromEnv reports whether an opt-in environment variable such as OUTLOOK_ALLOW_SEND is set to true
+func boolFromEnv(name string) bool {
+	enabled, err := strconv.ParseBool(os.Getenv(name))
+	return err == nil && enabled
+}
+
+// SendEnabled reports whether the manager was started with sending mail allowed
+func (m *Manager) SendEnabled() bool {
+	return m.allowSend
+}
+
+// PermanentDeleteEnabled reports whether the manager was started with permanent deletion allowed
+func (m *Manager) PermanentDeleteEnabled() bool {
+	return m.allowPurge
+}
+
+// newRequest creates a request to the PowerShell server carrying the bearer token.
+// The server's HttpListener is registered for the localhost host name, so the
+// Host header names it even though the connection goes to 127.0.0.1.
+func (m *Manager) newRequest(ctx context.Context, method, endpoint string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, m.baseURL+endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	if m.token != "" {
+		req.Header.Set("Authorization", "Bearer "+m.token)
+		req.Host = fmt.Sprintf("localhost:%d", m.port)
+	}
+	return req, nil
 }
 
-// makeRequest makes an HTTP request to the PowerShell server
+// makeRequest makes a GET request to the PowerShell server
 func (m *Manager) makeRequest(endpoint string) ([]byte, error) {
+	return m.makeRequestWithBody(http.MethodGet, endpoint, nil)
+}
+
+// makeRequestWithBody makes an HTTP request to the PowerShell server, sending
+// payload as a JSON body when it is non-nil. Cacheable reads are served from
+// the response cache, and any other method clears it.
+func (m *Manager) makeRequestWithBody(method, endpoint string, payload interface{}) ([]byte, error) {
+	if m.cache == nil {
+		return m.makeRequestWithRetry(method, endpoint, payload)
+	}
+
+	if method != http.MethodGet {
+		// Clear even when the request fails; the server may have applied part of it
+		defer m.cache.Clear()
+		return m.makeRequestWithRetry(method, endpoint, payload)
+	}
+
+	if !cacheableEndpoint(endpoint) {
+		return m.makeRequestWithRetry(method, endpoint, payload)
+	}
+
+	if body, ok := m.cache.Get(endpoint); ok {
+		return body, nil
+	}
+
+	body, err := m.makeRequestWithRetry(method, endpoint, payload)
+	if err != nil {
+		return nil, err
+	}
+	m.cache.Put(endpoint, body)
+	return body, nil
+}
+
+// makeRequestWithRetry makes an HTTP request to the PowerShell server. Transport
+// errors are retried with exponential backoff so calls ride out a supervisor
+// restart; requests that change state are only retried when they never reached
+// the server.
+func (m *Manager) makeRequestWithRetry(method, endpoint string, payload interface{}) ([]byte, error) {
+	var data []byte
+	if payload != nil {
+		var err error
+		data, err = json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+
+	delay := m.retryDelay
+	for attempt := 0; ; attempt++ {
+		body, err := m.doRequest(method, endpoint, data)
+		if err == nil {
+			return body, nil
+		}
+		if attempt >= m.maxRetries || !shouldRetry(method, err) || !m.waitForRetry(delay) {
+			if errors.Is(err, errTransport) && m.restarting.Load() {
+				err = fmt.Errorf("%w: %w", ErrServerRestarting, err)
+			}
+			return nil, err
+		}
+		delay = min(delay*2, maxRetryDelay)
+	}
+}
+
+// shouldRetry reports whether a failed request is safe to send again. Reads
+// are retried after any transport error; other methods only when the
+// connection was never established, so a send is never delivered twice.
+func shouldRetry(method string, err error) bool {
+	if !errors.Is(err, errTransport) {
+		return false
+	}
+	if method == http.MethodGet {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// waitForRetry sleeps for d before the next attempt, returning false if the
+// manager is stopped in the meantime
+func (m *Manager) waitForRetry(d time.Duration) bool {
+	if m.isShutdown.Load() {
+		return false
+	}
+
+	var stopped <-chan struct{}
+	if m.supervisorCtx != nil {
+		stopped = m.supervisorCtx.Done()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-stopped:
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// doRequest makes a single HTTP request to the PowerShell server
+func (m *Manager) doRequest(method, endpoint string, data []byte) ([]byte, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	req, err := http.NewRequestWithContext(ctx, "GET", m.baseURL+endpoint, nil)
+	var reqBody io.Reader
+	if data != nil {
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := m.newRequest(ctx, method, endpoint, reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
+	if data != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
 
 	resp, err := m.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		err = fmt.Errorf("%w: %w", errTransport, err)
+		m.health.recordRequestError(err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
@@ -186,21 +566,148 @@ func (m *Manager) makeRequest(endpoint string) ([]byte, error) {
 	if resp.StatusCode != http.StatusOK {
 		var errorResp ErrorResponse
 		if json.Unmarshal(body, &errorResp) == nil {
-			return nil, fmt.Errorf("server error (%d): %s", resp.StatusCode, errorResp.Error)
+			err = shared.NewError(shared.CodeForHTTPStatus(resp.StatusCode), "server error (%d): %s", resp.StatusCode, errorResp.Error)
+		} else {
+			err = shared.NewError(shared.CodeForHTTPStatus(resp.StatusCode), "server error (%d): %s", resp.StatusCode, string(body))
 		}
-		return nil, fmt.Errorf("server error (%d): %s", resp.StatusCode, string(body))
+		// Client errors such as unknown IDs are not a sign of an unhealthy server
+		if resp.StatusCode >= http.StatusInternalServerError {
+			m.health.recordRequestError(err)
+		}
+		return nil, err
 	}
 
 	return body, nil
 }
 
-// ListMessages retrieves messages from the inbox with pagination
-func (m *Manager) ListMessages(page int) (*MessageListResponse, error) {
+// ListFolders retrieves the mail folder hierarchy of the default store, or of
+// another account or shared mailbox when account is set
+func (m *Manager) ListFolders(account string) (*FolderListResponse, error) {
+	endpoint := "/folders"
+	if account != "" {
+		endpoint += "?" + url.Values{"account": {account}}.Encode()
+	}
+	body, err := m.makeRequest(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	var response FolderListResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// ListMessages retrieves messages from a folder with pagination.
+// An empty folder selects the Inbox; an empty account selects the default account.
+func (m *Manager) ListMessages(page int, folder, account string, filters ListFilters) (*MessageListResponse, error) {
+	if err := validateImportance(filters.Importance); err != nil {
+		return nil, err
+	}
+	if page < 1 {
+		page = 1
+	}
+
+	params := url.Values{}
+	params.Set("page", strconv.Itoa(page))
+	setFolderParams(params, folder, account)
+	if filters.UnreadOnly {
+		params.Set("unread", "true")
+	}
+	if filters.Importance != "" {
+		params.Set("importance", filters.Importance)
+	}
+	if filters.Category != "" {
+		params.Set("category", filters.Category)
+	}
+	if filters.HasAttachments != nil {
+		params.Set("has_attachments", strconv.FormatBool(*filters.HasAttachments))
+	}
+
+	return m.fetchMessageList(params)
+}
+
+// ListMessagesSince lists messages received strictly after since, newest first,
+// so polling clients only see new mail. An empty folder selects the Inbox.
+func (m *Manager) ListMessagesSince(since time.Time, page int, folder, account string) (*MessageListResponse, error) {
+	if since.IsZero() {
+		return nil, fmt.Errorf("since is required")
+	}
 	if page < 1 {
 		page = 1
 	}
 
-	endpoint := fmt.Sprintf("/messages?page=%d", page)
+	params := url.Values{}
+	params.Set("since", since.Format(time.RFC3339Nano))
+	params.Set("page", strconv.Itoa(page))
+	setFolderParams(params, folder, account)
+
+	return m.fetchMessageList(params)
+}
+
+// GetMailboxStats counts the messages a folder (default: Inbox) received in
+// [start, end), newest first up to maxAnalyticsMessages, with the largest
+// messages. The sidecar does the counting.
+func (m *Manager) GetMailboxStats(folder, account string, start, end time.Time, largest int) (*MailboxStats, error) {
+	params := url.Values{}
+	setFolderParams(params, folder, account)
+	params.Set("after", start.Format(time.RFC3339))
+	params.Set("before", end.Format(time.RFC3339))
+	params.Set("largest", strconv.Itoa(largest))
+	params.Set("limit", strconv.Itoa(maxAnalyticsMessages))
+
+	body, err := m.makeRequest("/stats?" + params.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	var response MailboxStats
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// validateSearch checks the search arguments shared by every backend
+func validateSearch(filters SearchFilters, pageSize int) error {
+	if pageSize < 0 || pageSize > maxSearchPageSize {
+		return fmt.Errorf("page size must be between 1 and %d", maxSearchPageSize)
+	}
+	if err := validateImportance(filters.Importance); err != nil {
+		return err
+	}
+	if !filters.After.IsZero() && !filters.Before.IsZero() && !filters.Before.After(filters.After) {
+		return fmt.Errorf("before must be later than after")
+	}
+	return nil
+}
+
+// validateImportance checks an importance filter; empty leaves it unset
+func validateImportance(importance string) error {
+	switch importance {
+	case "", ImportanceLow, ImportanceNormal, ImportanceHigh:
+		return nil
+	default:
+		return fmt.Errorf("invalid importance %q (expected %s, %s or %s)", importance, ImportanceLow, ImportanceNormal, ImportanceHigh)
+	}
+}
+
+// setFolderParams adds the optional folder and account query parameters
+func setFolderParams(params url.Values, folder, account string) {
+	if folder != "" {
+		params.Set("folder", folder)
+	}
+	if account != "" {
+		params.Set("account", account)
+	}
+}
+
+// fetchMessageList queries the /messages endpoint
+func (m *Manager) fetchMessageList(params url.Values) (*MessageListResponse, error) {
+	endpoint := "/messages?" + params.Encode()
 	body, err := m.makeRequest(endpoint)
 	if err != nil {
 		return nil, err
@@ -262,15 +769,16 @@ func (m *Manager) GetMessageBodyRaw(messageID string) (*MessageBodyRawResponse,
 	return &response, nil
 }
 
-// SearchMessages searches for messages matching the query
-func (m *Manager) SearchMessages(query string) (*SearchResponse, error) {
-	endpoint := fmt.Sprintf("/search?q=%s", url.QueryEscape(query))
+// GetConversation retrieves every message in the thread containing a message,
+// across folders and ordered chronologically
+func (m *Manager) GetConversation(messageID string) (*ConversationResponse, error) {
+	endpoint := fmt.Sprintf("/messages/%s/conversation", url.PathEscape(messageID))
 	body, err := m.makeRequest(endpoint)
 	if err != nil {
 		return nil, err
 	}
 
-	var response SearchResponse
+	var response ConversationResponse
 	if err := json.Unmarshal(body, &response); err != nil {
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
@@ -278,55 +786,826 @@ func (m *Manager) SearchMessages(query string) (*SearchResponse, error) {
 	return &response, nil
 }
 
-// supervisorLoop monitors the PowerShell process and restarts it if needed
-func (m *Manager) supervisorLoop() {
-	for {
-		select {
-		case <-m.supervisorCtx.Done():
-			// Supervisor context cancelled, exit
-			return
-		case <-m.restartChan:
-			if m.isShutdown {
-				return
-			}
+// SearchMessages searches for messages matching the query and filters within a
+// folder. An empty folder selects the Inbox and an empty account the default
+// account; the query may be empty when filters are set. Matches are paged newest
+// first and each page is ordered by relevance; a pageSize of 0 uses the server default.
+func (m *Manager) SearchMessages(query, folder, account string, filters SearchFilters, page, pageSize int) (*SearchResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if err := validateSearch(filters, pageSize); err != nil {
+		return nil, err
+	}
 
-			fmt.Fprintf(os.Stderr, "PowerShell server crashed, attempting restart...\n")
+	params := url.Values{}
+	if query != "" {
+		params.Set("q", query)
+	}
+	setFolderParams(params, folder, account)
+	if filters.From != "" {
+		params.Set("from", filters.From)
+	}
+	if filters.To != "" {
+		params.Set("to", filters.To)
+	}
+	if !filters.After.IsZero() {
+		params.Set("after", filters.After.Format(time.RFC3339))
+	}
+	if !filters.Before.IsZero() {
+		params.Set("before", filters.Before.Format(time.RFC3339))
+	}
+	if filters.HasAttachments != nil {
+		params.Set("has_attachments", strconv.FormatBool(*filters.HasAttachments))
+	}
+	if filters.Unread != nil {
+		params.Set("unread", strconv.FormatBool(*filters.Unread))
+	}
+	if filters.Importance != "" {
+		params.Set("importance", filters.Importance)
+	}
+	params.Set("page", strconv.Itoa(page))
+	if pageSize > 0 {
+		params.Set("page_size", strconv.Itoa(pageSize))
+	}
 
-			// Wait a moment before restarting to avoid rapid restart loops
-			time.Sleep(2 * time.Second)
+	endpoint := "/search?" + params.Encode()
+	body, err := m.makeRequest(endpoint)
+	if err != nil {
+		return nil, err
+	}
 
-			// Attempt to restart the server
-			if err := m.restartPowerShellServer(); err != nil {
-				fmt.Fprintf(os.Stderr, "Failed to restart PowerShell server: %v\n", err)
-				// Wait longer before next attempt
-				time.Sleep(10 * time.Second)
-				// Trigger another restart attempt
-				select {
-				case m.restartChan <- true:
-				default:
-				}
-			} else {
-				fmt.Fprintf(os.Stderr, "PowerShell server restarted successfully\n")
-			}
-		}
+	var response SearchResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
+
+	rankSearchResults(response.Results, query, time.Now())
+
+	return &response, nil
 }
 
-// monitorProcess watches the PowerShell process and signals restart if it dies
-func (m *Manager) monitorProcess() {
-	if m.cmd == nil {
-		return
+// UpdateMessage changes the read state, follow-up flag and/or categories of a message
+func (m *Manager) UpdateMessage(messageID string, request UpdateMessageRequest) (*UpdateMessageResponse, error) {
+	if request.Unread == nil && request.Flag == "" && request.Categories == nil {
+		return nil, fmt.Errorf("no changes requested")
 	}
 
-	// Wait for the process to exit
-	err := m.cmd.Wait()
+	switch request.Flag {
+	case "", FlagStatusFlagged, FlagStatusComplete, FlagStatusNone:
+	default:
+		return nil, fmt.Errorf("invalid flag value %q (expected %s, %s or %s)", request.Flag, FlagStatusFlagged, FlagStatusComplete, FlagStatusNone)
+	}
 
-	// If we're shutting down, don't attempt restart
-	if m.isShutdown {
+	endpoint := fmt.Sprintf("/messages/%s", url.PathEscape(messageID))
+	body, err := m.makeRequestWithBody(http.MethodPatch, endpoint, request)
+	if err != nil {
+		return nil, err
+	}
+
+	var response UpdateMessageResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// MoveMessage moves a message to another folder, optionally in another account,
+// and returns its new ID
+func (m *Manager) MoveMessage(messageID, folder, account string) (*MoveMessageResponse, error) {
+	if folder == "" {
+		return nil, fmt.Errorf("destination folder is required")
+	}
+
+	payload := map[string]string{"folder": folder}
+	if account != "" {
+		payload["account"] = account
+	}
+
+	endpoint := fmt.Sprintf("/messages/%s/move", url.PathEscape(messageID))
+	body, err := m.makeRequestWithBody(http.MethodPost, endpoint, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var response MoveMessageResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// DeleteMessage moves a message to Deleted Items, or removes it for good when
+// permanent is set and the manager was started with permanent deletion allowed
+func (m *Manager) DeleteMessage(messageID string, permanent bool) (*DeleteMessageResponse, error) {
+	if permanent && !m.allowPurge {
+		return nil, shared.NewError(shared.CodeAccessDenied, "permanent deletion is disabled; start outlook-mcp with --allow-permanent-delete to enable it")
+	}
+
+	endpoint := fmt.Sprintf("/messages/%s", url.PathEscape(messageID))
+	if permanent {
+		endpoint += "?permanent=true"
+	}
+
+	body, err := m.makeRequestWithBody(http.MethodDelete, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response DeleteMessageResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// CreateDraft composes a message and saves it to the Drafts folder without sending it.
+// Attachment paths are resolved to absolute paths and must refer to existing files.
+func (m *Manager) CreateDraft(request DraftRequest) (*DraftResponse, error) {
+	attachments, err := resolveAttachmentPaths(request.Attachments)
+	if err != nil {
+		return nil, err
+	}
+	request.Attachments = attachments
+
+	body, err := m.makeRequestWithBody(http.MethodPost, "/drafts", request)
+	if err != nil {
+		return nil, err
+	}
+
+	var response DraftResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// resolveAttachmentPaths makes draft attachment paths absolute and checks that
+// each refers to an existing file
+func resolveAttachmentPaths(paths []string) ([]string, error) {
+	attachments := make([]string, 0, len(paths))
+	for _, path := range paths {
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid attachment path %s: %w", path, err)
+		}
+		info, err := os.Stat(absPath)
+		if err != nil {
+			return nil, fmt.Errorf("attachment not accessible: %w", err)
+		}
+		if info.IsDir() {
+			return nil, fmt.Errorf("attachment is a directory: %s", absPath)
+		}
+		attachments = append(attachments, absPath)
+	}
+	return attachments, nil
+}
+
+// ListAttachments retrieves the attachments of a message
+func (m *Manager) ListAttachments(messageID string) (*AttachmentListResponse, error) {
+	endpoint := fmt.Sprintf("/messages/%s/attachments", url.PathEscape(messageID))
+	body, err := m.makeRequest(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	var response AttachmentListResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// SaveAttachment writes an attachment of a message to directory and returns the
+// saved file's path. An empty directory selects a per-user temp directory; an
+// existing file with the same name is never overwritten.
+func (m *Manager) SaveAttachment(messageID string, index int, directory string) (*SaveAttachmentResponse, error) {
+	if index < 1 {
+		return nil, fmt.Errorf("attachment index must be 1 or greater")
+	}
+
+	if directory == "" {
+		directory = filepath.Join(os.TempDir(), "outlook-mcp-attachments")
+	}
+	absDir, err := filepath.Abs(directory)
+	if err != nil {
+		return nil, fmt.Errorf("invalid directory %s: %w", directory, err)
+	}
+	if err := os.MkdirAll(absDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("/messages/%s/attachments/%d/save", url.PathEscape(messageID), index)
+	body, err := m.makeRequestWithBody(http.MethodPost, endpoint, map[string]string{"directory": absDir})
+	if err != nil {
+		return nil, err
+	}
+
+	var response SaveAttachmentResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// GetAttachmentContent returns the bytes of an attachment of a message, up
+// to the sidecar's 10 MB limit
+func (m *Manager) GetAttachmentContent(messageID string, index int) (*AttachmentContentResponse, error) {
+	if index < 1 {
+		return nil, fmt.Errorf("attachment index must be 1 or greater")
+	}
+
+	endpoint := fmt.Sprintf("/messages/%s/attachments/%d/content", url.PathEscape(messageID), index)
+	body, err := m.makeRequest(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	var response AttachmentContentResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// ExportMessage saves a message as a .msg or .eml file. A path naming an
+// existing directory (or ending in a separator) saves into it under the
+// message's subject; any other path is the target file, which must not exist.
+// An empty path returns the file content base64-encoded instead.
+func (m *Manager) ExportMessage(messageID, format, path string) (*ExportMessageResponse, error) {
+	if format == "" {
+		format = ExportFormatMsg
+	}
+	if format != ExportFormatMsg && format != ExportFormatEml {
+		return nil, fmt.Errorf("format must be %q or %q", ExportFormatMsg, ExportFormatEml)
+	}
+
+	payload := map[string]string{"format": format}
+	directory, file, err := resolveExportTarget(path)
+	if err != nil {
+		return nil, err
+	}
+	if directory != "" {
+		payload["directory"] = directory
+	}
+	if file != "" {
+		payload["path"] = file
+	}
+
+	endpoint := fmt.Sprintf("/messages/%s/export", url.PathEscape(messageID))
+	body, err := m.makeRequestWithBody(http.MethodPost, endpoint, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var response ExportMessageResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// resolveExportTarget turns an export path into either a directory to save
+// into or a file to create, creating missing directories. Both are empty when
+// path is empty and the export is returned inline.
+func resolveExportTarget(path string) (directory, file string, err error) {
+	if path == "" {
+		return "", "", nil
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid path %s: %w", path, err)
+	}
+
+	info, statErr := os.Stat(absPath)
+	isDir := statErr == nil && info.IsDir() ||
+		strings.HasSuffix(path, string(filepath.Separator)) || strings.HasSuffix(path, "/")
+
+	switch {
+	case isDir:
+		if err := os.MkdirAll(absPath, 0755); err != nil {
+			return "", "", fmt.Errorf("failed to create directory: %w", err)
+		}
+		return absPath, "", nil
+	case statErr == nil:
+		return "", "", fmt.Errorf("file already exists: %s", absPath)
+	default:
+		if err := os.MkdirAll(filepath.Dir(absPath), 0755); err != nil {
+			return "", "", fmt.Errorf("failed to create directory: %w", err)
+		}
+		return "", absPath, nil
+	}
+}
+
+// ListCalendarEvents retrieves calendar events overlapping [start, end), with
+// recurring series expanded into individual occurrences. At most limit events
+// are returned; a limit of 0 uses the server default.
+func (m *Manager) ListCalendarEvents(start, end time.Time, limit int) (*CalendarEventListResponse, error) {
+	if !end.After(start) {
+		return nil, fmt.Errorf("end must be after start")
+	}
+
+	params := url.Values{}
+	params.Set("start", start.Format(time.RFC3339))
+	params.Set("end", end.Format(time.RFC3339))
+	if limit > 0 {
+		params.Set("limit", strconv.Itoa(limit))
+	}
+
+	body, err := m.makeRequest("/calendar/events?" + params.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	var response CalendarEventListResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// ListTasks retrieves the To-Do List (tasks and flagged items) filtered by
+// status, soonest due first. An empty status lists open tasks and a limit of 0
+// uses the server default.
+func (m *Manager) ListTasks(status string, limit int) (*TaskListResponse, error) {
+	if err := validateTaskStatus(status); err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	if status != "" {
+		params.Set("status", status)
+	}
+	if limit > 0 {
+		params.Set("limit", strconv.Itoa(limit))
+	}
+
+	endpoint := "/tasks"
+	if len(params) > 0 {
+		endpoint += "?" + params.Encode()
+	}
+
+	body, err := m.makeRequest(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	var response TaskListResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// CreateTask creates a task item, or flags request.MessageID for follow-up
+func (m *Manager) CreateTask(request CreateTaskRequest) (*Task, error) {
+	if err := validateCreateTask(request); err != nil {
+		return nil, err
+	}
+
+	body, err := m.makeRequestWithBody(http.MethodPost, "/tasks", request)
+	if err != nil {
+		return nil, err
+	}
+
+	var response Task
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// validateTaskStatus checks a ListTasks status filter
+func validateTaskStatus(status string) error {
+	switch status {
+	case "", TaskStatusOpen, TaskStatusCompleted, TaskStatusAll:
+		return nil
+	default:
+		return fmt.Errorf("invalid status %q (expected %s, %s or %s)", status, TaskStatusOpen, TaskStatusCompleted, TaskStatusAll)
+	}
+}
+
+// validateCreateTask checks the fields of a CreateTask request
+func validateCreateTask(request CreateTaskRequest) error {
+	if request.Subject == "" && request.MessageID == "" {
+		return fmt.Errorf("a subject or message ID is required")
+	}
+	for _, date := range []string{request.DueDate, request.StartDate} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return fmt.Errorf("invalid date %q (expected YYYY-MM-DD)", date)
+		}
+	}
+	switch request.Importance {
+	case "", ImportanceLow, ImportanceNormal, ImportanceHigh:
+		return nil
+	default:
+		return fmt.Errorf("invalid importance %q (expected %s, %s or %s)", request.Importance, ImportanceLow, ImportanceNormal, ImportanceHigh)
+	}
+}
+
+// CompleteTask marks a task or flagged item complete
+func (m *Manager) CompleteTask(taskID string) (*Task, error) {
+	endpoint := fmt.Sprintf("/tasks/%s/complete", url.PathEscape(taskID))
+	body, err := m.makeRequestWithBody(http.MethodPost, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var response Task
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// GetAutomaticReplies reports whether automatic replies (out of office) are turned
+// on for the default store, or another account's store when account is set
+func (m *Manager) GetAutomaticReplies(account string) (*AutomaticReplyStatus, error) {
+	endpoint := "/oof"
+	if account != "" {
+		endpoint += "?" + url.Values{"account": {account}}.Encode()
+	}
+
+	body, err := m.makeRequest(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	var response AutomaticReplyStatus
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// GetProfile retrieves the current user's identity and saved signatures
+func (m *Manager) GetProfile() (*UserProfile, error) {
+	body, err := m.makeRequest("/profile")
+	if err != nil {
+		return nil, err
+	}
+
+	var response UserProfile
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// GetFreeBusy retrieves free/busy slots of interval minutes for each attendee
+// over [start, end). An interval of 0 uses the server default of 30 minutes.
+func (m *Manager) GetFreeBusy(attendees []string, start, end time.Time, interval int) (*FreeBusyResponse, error) {
+	if err := validateFreeBusy(attendees, start, end); err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("attendees", strings.Join(attendees, ";"))
+	params.Set("start", start.Format(time.RFC3339))
+	params.Set("end", end.Format(time.RFC3339))
+	if interval > 0 {
+		params.Set("interval", strconv.Itoa(interval))
+	}
+
+	body, err := m.makeRequest("/freebusy?" + params.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	var response FreeBusyResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// validateFreeBusy checks the attendee count and range of a free/busy lookup
+func validateFreeBusy(attendees []string, start, end time.Time) error {
+	if len(attendees) == 0 {
+		return fmt.Errorf("at least one attendee is required")
+	}
+	if len(attendees) > maxFreeBusyAttendees {
+		return fmt.Errorf("at most %d attendees can be looked up at once", maxFreeBusyAttendees)
+	}
+	if !end.After(start) {
+		return fmt.Errorf("end must be after start")
+	}
+	if end.Sub(start) > maxFreeBusyRange {
+		return fmt.Errorf("range must not exceed %d days", int(maxFreeBusyRange.Hours()/24))
+	}
+	return nil
+}
+
+// GetEvent retrieves full details of a calendar event, including attendees and
+// the recurrence pattern of recurring series
+func (m *Manager) GetEvent(eventID string) (*CalendarEvent, error) {
+	endpoint := fmt.Sprintf("/calendar/events/%s", url.PathEscape(eventID))
+	body, err := m.makeRequest(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	var event CalendarEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &event, nil
+}
+
+// ListAccounts retrieves the accounts and stores (including shared mailboxes and
+// archives) open in the Outlook profile
+func (m *Manager) ListAccounts() (*AccountListResponse, error) {
+	body, err := m.makeRequest("/accounts")
+	if err != nil {
+		return nil, err
+	}
+
+	var response AccountListResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// ListRules retrieves the mail rules of the default store in execution order
+func (m *Manager) ListRules() (*RuleListResponse, error) {
+	body, err := m.makeRequest("/rules")
+	if err != nil {
+		return nil, err
+	}
+
+	var response RuleListResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// ListCategories retrieves the master category list
+func (m *Manager) ListCategories() (*CategoryListResponse, error) {
+	body, err := m.makeRequest("/categories")
+	if err != nil {
+		return nil, err
+	}
+
+	var response CategoryListResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// SearchContacts finds contacts whose name, email, company, department or job
+// title contain query. An empty query lists all contacts; a limit of 0 uses the
+// server default.
+func (m *Manager) SearchContacts(query string, limit int) (*ContactSearchResponse, error) {
+	params := url.Values{}
+	if query != "" {
+		params.Set("q", query)
+	}
+	if limit > 0 {
+		params.Set("limit", strconv.Itoa(limit))
+	}
+
+	endpoint := "/contacts"
+	if encoded := params.Encode(); encoded != "" {
+		endpoint += "?" + encoded
+	}
+	body, err := m.makeRequest(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	var response ContactSearchResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// GetContact retrieves full details of a contact, including addresses and notes
+func (m *Manager) GetContact(contactID string) (*Contact, error) {
+	endpoint := fmt.Sprintf("/contacts/%s", url.PathEscape(contactID))
+	body, err := m.makeRequest(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	var contact Contact
+	if err := json.Unmarshal(body, &contact); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &contact, nil
+}
+
+// maxListMembers caps the people listed when a distribution list is expanded
+const maxListMembers = 500
+
+// ResolveRecipient resolves a name or address against the Outlook address
+// books, as Outlook's Check Names does. With expand, a distribution list's
+// members are listed too, nested lists expanded.
+func (m *Manager) ResolveRecipient(name string, expand bool) (*RecipientResolution, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+
+	params := url.Values{}
+	params.Set("name", name)
+	params.Set("expand", strconv.FormatBool(expand))
+	params.Set("limit", strconv.Itoa(maxListMembers))
+
+	body, err := m.makeRequest("/recipients/resolve?" + params.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	var response RecipientResolution
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// SendMessage composes and sends a new message
+func (m *Manager) SendMessage(request SendMessageRequest) (*SendResponse, error) {
+	if len(request.To) == 0 {
+		return nil, fmt.Errorf("at least one recipient is required")
+	}
+
+	body, err := m.makeRequestWithBody(http.MethodPost, "/send", request)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseSendResponse(body)
+}
+
+// ReplyToMessage replies to the sender (or all recipients) of an existing message
+func (m *Manager) ReplyToMessage(messageID string, request ReplyRequest) (*SendResponse, error) {
+	endpoint := fmt.Sprintf("/messages/%s/reply", url.PathEscape(messageID))
+	body, err := m.makeRequestWithBody(http.MethodPost, endpoint, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseSendResponse(body)
+}
+
+// ForwardMessage forwards an existing message to new recipients
+func (m *Manager) ForwardMessage(messageID string, request ForwardRequest) (*SendResponse, error) {
+	if len(request.To) == 0 {
+		return nil, fmt.Errorf("at least one recipient is required")
+	}
+
+	endpoint := fmt.Sprintf("/messages/%s/forward", url.PathEscape(messageID))
+	body, err := m.makeRequestWithBody(http.MethodPost, endpoint, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseSendResponse(body)
+}
+
+// parseSendResponse decodes the response shared by the send endpoints
+func parseSendResponse(body []byte) (*SendResponse, error) {
+	var response SendResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// supervisorLoop monitors the PowerShell process and restarts it if needed
+func (m *Manager) supervisorLoop() {
+	for {
+		select {
+		case <-m.supervisorCtx.Done():
+			// Supervisor context cancelled, exit
+			return
+		case <-m.restartChan:
+			if m.isShutdown.Load() {
+				return
+			}
+
+			m.logSupervisor("PowerShell server crashed, attempting restart...")
+
+			// Wait a moment before restarting to avoid rapid restart loops
+			if !m.sleepUnlessStopped(2 * time.Second) {
+				return
+			}
+
+			// Attempt to restart the server
+			if err := m.restartPowerShellServer(); err != nil {
+				m.logSupervisor("Failed to restart PowerShell server: %v", err)
+				m.health.recordFailedRestart(err)
+				// Wait longer before next attempt
+				if !m.sleepUnlessStopped(10 * time.Second) {
+					return
+				}
+				// Trigger another restart attempt
+				select {
+				case m.restartChan <- true:
+				default:
+				}
+			} else {
+				m.logSupervisor("PowerShell server restarted successfully")
+				m.health.recordRestart()
+				m.restarting.Store(false)
+				// Outlook may have changed while the server was down
+				if m.cache != nil {
+					m.cache.Clear()
+				}
+			}
+		}
+	}
+}
+
+// logSupervisor reports a supervisor event on stderr and in the server log buffer
+func (m *Manager) logSupervisor(format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	fmt.Fprintln(os.Stderr, message)
+	if m.logs != nil {
+		m.logs.add(LogStreamSupervisor, message)
+	}
+}
+
+// ServerLogs returns the newest limit captured log lines of stream (all
+// streams when empty) containing filter; a limit of 0 returns every match
+func (m *Manager) ServerLogs(limit int, stream, filter string) (*ServerLogsResponse, error) {
+	switch stream {
+	case "", LogStreamStdout, LogStreamStderr, LogStreamSupervisor:
+	default:
+		return nil, shared.NewError(shared.CodeInvalidArgument, "invalid stream %q (expected %s, %s or %s)", stream, LogStreamStdout, LogStreamStderr, LogStreamSupervisor)
+	}
+	if m.logs == nil {
+		return nil, shared.NewError(shared.CodeUnavailable, "server log capture is disabled")
+	}
+	return m.logs.snapshot(limit, stream, filter), nil
+}
+
+// sleepUnlessStopped waits for d, returning false early if the supervisor is cancelled
+func (m *Manager) sleepUnlessStopped(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-m.supervisorCtx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// monitorProcess waits for a PowerShell process to exit, closes done, and
+// signals a restart if the process was still the current generation
+func (m *Manager) monitorProcess(cmd *exec.Cmd, generation uint64, done chan struct{}) {
+	// Wait for the process to exit
+	err := cmd.Wait()
+	close(done)
+
+	// If we're shutting down, don't attempt restart
+	if m.isShutdown.Load() {
+		return
+	}
+
+	// A retired or replaced process exited on purpose
+	m.procMu.Lock()
+	current := m.generation == generation
+	m.procMu.Unlock()
+	if !current {
 		return
 	}
 
-	fmt.Fprintf(os.Stderr, "PowerShell process exited with error: %v\n", err)
+	m.logSupervisor("PowerShell process exited with error: %v", err)
+	m.health.recordProcessExit(err)
+	m.restarting.Store(true)
 
 	// Signal supervisor to restart the process
 	select {
@@ -338,10 +1617,16 @@ func (m *Manager) monitorProcess() {
 
 // restartPowerShellServer restarts the PowerShell server process
 func (m *Manager) restartPowerShellServer() error {
-	// Clean up the old process
-	if m.cmd != nil && m.cmd.Process != nil {
-		m.cmd.Process.Kill()
+	// Clean up the old process; retiring it first keeps its monitor from
+	// signalling another restart when it exits. An attached instance that
+	// stopped answering is killed by its recorded PID.
+	m.procMu.Lock()
+	attachedPID := m.instancePID
+	m.procMu.Unlock()
+	if cmd, _ := m.retireProcess(); cmd != nil && cmd.Process != nil {
+		cmd.Process.Kill()
 	}
+	killInstance(attachedPID)
 
 	// Start a new PowerShell server
 	if err := m.startPowerShellServer(); err != nil {
@@ -1,12 +1,24 @@
 package outlook
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
+	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/kevsmith/my-mcp/pkg/shared"
+	"github.com/kevsmith/my-mcp/pkg/shared/sandbox"
+	"github.com/mark3labs/mcp-go/mcp"
 )
 
 // TestManagerRequiresWindows tests that the manager properly validates Windows OS
@@ -88,7 +100,7 @@ func TestManagerErrorHandling(t *testing.T) {
 	}
 
 	// Test error handling for unavailable service
-	_, err := manager.ListMessages(1)
+	_, err := manager.ListMessages(1, "", "", ListFilters{})
 	if err == nil {
 		t.Error("Expected error for unavailable service")
 	}
@@ -97,7 +109,7 @@ func TestManagerErrorHandling(t *testing.T) {
 	}
 
 	// Test error handling for bad request
-	_, err = manager.SearchMessages("")
+	_, err = manager.SearchMessages("", "", "", SearchFilters{}, 1, 0)
 	if err == nil {
 		t.Error("Expected error for empty query")
 	}
@@ -160,7 +172,7 @@ func TestManagerSuccessfulResponses(t *testing.T) {
 	}
 
 	// Test successful message listing
-	response, err := manager.ListMessages(1)
+	response, err := manager.ListMessages(1, "", "", ListFilters{})
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
 	}
@@ -175,7 +187,7 @@ func TestManagerSuccessfulResponses(t *testing.T) {
 	}
 
 	// Test successful search
-	searchResp, err := manager.SearchMessages("test query")
+	searchResp, err := manager.SearchMessages("test query", "", "", SearchFilters{}, 1, 0)
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
 	}
@@ -196,7 +208,7 @@ func TestEnvironmentVariableHandling(t *testing.T) {
 		}
 	}()
 
-	// Test default port (8080) when no environment variable
+	// Test dynamic port selection when no environment variable
 	os.Unsetenv("OUTLOOK_SERVER_PORT")
 	// We can't actually create a manager without Windows/PowerShell,
 	// but we can verify the embedded script is available
@@ -220,3 +232,1329 @@ func containsString(haystack, needle string) bool {
 			haystack[len(haystack)-len(needle):] == needle ||
 			containsSubstring(haystack, needle))
 }
+
+// TestManagerFolderSupport tests folder listing and folder-scoped requests
+func TestManagerFolderSupport(t *testing.T) {
+	var lastFolder string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		lastFolder = r.URL.Query().Get("folder")
+
+		switch r.URL.Path {
+		case "/folders":
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{
+				"folders": [
+					{"id": "f1", "name": "Inbox", "path": "\\\\Mailbox\\Inbox", "itemCount": 42, "unreadCount": 3, "depth": 0},
+					{"id": "f2", "name": "Projects", "path": "\\\\Mailbox\\Inbox\\Projects", "itemCount": 7, "unreadCount": 0, "depth": 1}
+				],
+				"count": 2
+			}`))
+		case "/messages":
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"folder": "Sent Items", "messages": [], "pagination": {"page": 1, "pageSize": 10, "total": 0}}`))
+		case "/search":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"Folder not found: Nowhere","code":"FOLDER_NOT_FOUND"}`))
+		}
+	}))
+	defer server.Close()
+
+	manager := &Manager{
+		baseURL: server.URL,
+		client:  &http.Client{Timeout: 5 * time.Second},
+	}
+
+	folders, err := manager.ListFolders("")
+	if err != nil {
+		t.Fatalf("ListFolders failed: %v", err)
+	}
+	if folders.Count != 2 || len(folders.Folders) != 2 {
+		t.Fatalf("Expected 2 folders, got %d", len(folders.Folders))
+	}
+	if folders.Folders[1].Name != "Projects" || folders.Folders[1].Depth != 1 {
+		t.Errorf("Unexpected folder: %+v", folders.Folders[1])
+	}
+
+	response, err := manager.ListMessages(1, "sent", "", ListFilters{})
+	if err != nil {
+		t.Fatalf("ListMessages failed: %v", err)
+	}
+	if lastFolder != "sent" {
+		t.Errorf("Expected folder parameter 'sent', got %q", lastFolder)
+	}
+	if response.Folder != "Sent Items" {
+		t.Errorf("Expected folder 'Sent Items', got %q", response.Folder)
+	}
+
+	_, err = manager.SearchMessages("report", "Nowhere", "", SearchFilters{}, 1, 0)
+	if err == nil || !containsString(err.Error(), "Folder not found") {
+		t.Errorf("Expected folder not found error, got: %v", err)
+	}
+	if lastFolder != "Nowhere" {
+		t.Errorf("Expected folder parameter 'Nowhere', got %q", lastFolder)
+	}
+}
+
+func TestManagerSendOperations(t *testing.T) {
+	var lastMethod, lastPath, lastContentType string
+	var lastPayload map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		lastMethod = r.Method
+		lastPath = r.URL.Path
+		lastContentType = r.Header.Get("Content-Type")
+		lastPayload = nil
+		json.NewDecoder(r.Body).Decode(&lastPayload)
+
+		switch r.URL.Path {
+		case "/send":
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"status": "sent", "action": "send", "subject": "Status", "to": "alice@example.com"}`))
+		case "/messages/msg1/reply":
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"status": "sent", "action": "reply_all", "id": "msg1", "subject": "RE: Status", "to": "bob@example.com"}`))
+		case "/messages/missing/forward":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": "Message not found", "code": "MESSAGE_NOT_FOUND"}`))
+		default:
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"error": "Sending mail is disabled", "code": "SEND_DISABLED"}`))
+		}
+	}))
+	defer server.Close()
+
+	manager := &Manager{
+		baseURL: server.URL,
+		client:  &http.Client{Timeout: 5 * time.Second},
+	}
+
+	sent, err := manager.SendMessage(SendMessageRequest{
+		To:      []string{"alice@example.com"},
+		Subject: "Status",
+		Body:    "All good",
+	})
+	if err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	if lastMethod != http.MethodPost || lastPath != "/send" {
+		t.Errorf("Expected POST /send, got %s %s", lastMethod, lastPath)
+	}
+	if lastContentType != "application/json" {
+		t.Errorf("Expected JSON content type, got %q", lastContentType)
+	}
+	if lastPayload["subject"] != "Status" || lastPayload["body"] != "All good" {
+		t.Errorf("Unexpected send payload: %v", lastPayload)
+	}
+	if sent.Status != "sent" || sent.To != "alice@example.com" {
+		t.Errorf("Unexpected send response: %+v", sent)
+	}
+
+	reply, err := manager.ReplyToMessage("msg1", ReplyRequest{Body: "Thanks", ReplyAll: true})
+	if err != nil {
+		t.Fatalf("ReplyToMessage failed: %v", err)
+	}
+	if lastPayload["replyAll"] != true {
+		t.Errorf("Expected replyAll in payload, got %v", lastPayload)
+	}
+	if reply.Action != "reply_all" || reply.ID != "msg1" {
+		t.Errorf("Unexpected reply response: %+v", reply)
+	}
+
+	_, err = manager.ForwardMessage("missing", ForwardRequest{To: []string{"carol@example.com"}})
+	if err == nil || !containsString(err.Error(), "Message not found") {
+		t.Errorf("Expected message not found error, got: %v", err)
+	}
+
+	_, err = manager.ForwardMessage("msg1", ForwardRequest{})
+	if err == nil || !containsString(err.Error(), "recipient") {
+		t.Errorf("Expected recipient validation error, got: %v", err)
+	}
+
+	_, err = manager.ReplyToMessage("blocked", ReplyRequest{Body: "Hi"})
+	if err == nil || !containsString(err.Error(), "403") {
+		t.Errorf("Expected disabled send error, got: %v", err)
+	}
+}
+
+func TestBoolFromEnv(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected bool
+	}{
+		{"", false},
+		{"false", false},
+		{"nonsense", false},
+		{"true", true},
+		{"1", true},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("OUTLOOK_ALLOW_SEND", tt.value)
+		if got := boolFromEnv("OUTLOOK_ALLOW_SEND"); got != tt.expected {
+			t.Errorf("boolFromEnv() with %q = %t, expected %t", tt.value, got, tt.expected)
+		}
+	}
+}
+
+func TestManagerCreateDraft(t *testing.T) {
+	var lastMethod string
+	var lastPayload DraftRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		lastMethod = r.Method
+		json.NewDecoder(r.Body).Decode(&lastPayload)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"id": "draft1", "subject": "Plan", "to": "alice@example.com", "folder": "Drafts", "attachmentCount": 1}`))
+	}))
+	defer server.Close()
+
+	manager := &Manager{
+		baseURL: server.URL,
+		client:  &http.Client{Timeout: 5 * time.Second},
+	}
+
+	attachment := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(attachment, []byte("notes"), 0644); err != nil {
+		t.Fatalf("Failed to write attachment: %v", err)
+	}
+
+	draft, err := manager.CreateDraft(DraftRequest{
+		To:          []string{"alice@example.com"},
+		Subject:     "Plan",
+		Attachments: []string{attachment},
+	})
+	if err != nil {
+		t.Fatalf("CreateDraft failed: %v", err)
+	}
+	if lastMethod != http.MethodPost {
+		t.Errorf("Expected POST, got %s", lastMethod)
+	}
+	if len(lastPayload.Attachments) != 1 || !filepath.IsAbs(lastPayload.Attachments[0]) {
+		t.Errorf("Expected absolute attachment path, got %v", lastPayload.Attachments)
+	}
+	if draft.ID != "draft1" || draft.Folder != "Drafts" || draft.AttachmentCount != 1 {
+		t.Errorf("Unexpected draft response: %+v", draft)
+	}
+
+	_, err = manager.CreateDraft(DraftRequest{
+		Subject:     "Plan",
+		Attachments: []string{filepath.Join(t.TempDir(), "missing.txt")},
+	})
+	if err == nil || !containsString(err.Error(), "attachment not accessible") {
+		t.Errorf("Expected missing attachment error, got: %v", err)
+	}
+
+	_, err = manager.CreateDraft(DraftRequest{Subject: "Plan", Attachments: []string{t.TempDir()}})
+	if err == nil || !containsString(err.Error(), "directory") {
+		t.Errorf("Expected directory attachment error, got: %v", err)
+	}
+}
+
+// TestCreateDraftSaveRoots tests that create_draft only attaches files from under the save roots
+func TestCreateDraftSaveRoots(t *testing.T) {
+	drafts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		drafts++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": "draft1", "subject": "Plan", "folder": "Drafts", "attachmentCount": 1}`))
+	}))
+	defer server.Close()
+	manager := &Manager{baseURL: server.URL, client: &http.Client{Timeout: 5 * time.Second}}
+
+	root := t.TempDir()
+	inside := filepath.Join(root, "notes.txt")
+	outside := filepath.Join(t.TempDir(), "id_rsa")
+	for _, path := range []string{inside, outside} {
+		if err := os.WriteFile(path, []byte("notes"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	paths, err := sandbox.New([]string{root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	draft := func(paths *sandbox.Sandbox, attachments ...any) *mcp.CallToolResult {
+		t.Helper()
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]any{"subject": "Plan", "attachments": attachments}
+		result, err := CreateDraftHandler(manager, paths)(context.Background(), request)
+		if err != nil {
+			t.Fatalf("create_draft failed: %v", err)
+		}
+		return result
+	}
+
+	if result := draft(paths, inside); result.IsError || drafts != 1 {
+		t.Fatalf("Expected an attachment under the root to succeed: %+v", result)
+	}
+	for _, result := range []*mcp.CallToolResult{draft(paths, outside), draft(nil, inside)} {
+		if !result.IsError || !strings.Contains(result.Content[0].(mcp.TextContent).Text, shared.CodeAccessDenied) {
+			t.Errorf("Expected ACCESS_DENIED, got %+v", result)
+		}
+	}
+	if drafts != 1 {
+		t.Errorf("Refused drafts reached the backend: %d requests", drafts)
+	}
+
+	// Drafts without attachments need no save roots
+	if result := draft(nil); result.IsError || drafts != 2 {
+		t.Errorf("Expected a draft without attachments to succeed: %+v", result)
+	}
+}
+
+func TestManagerUpdateMessage(t *testing.T) {
+	var lastMethod, lastPath string
+	var lastPayload map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		lastMethod = r.Method
+		lastPath = r.URL.Path
+		lastPayload = nil
+		json.NewDecoder(r.Body).Decode(&lastPayload)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"id": "msg1", "unread": false, "flagStatus": "flagged", "categories": []}`))
+	}))
+	defer server.Close()
+
+	manager := &Manager{
+		baseURL: server.URL,
+		client:  &http.Client{Timeout: 5 * time.Second},
+	}
+
+	unread := false
+	noCategories := []string{}
+	response, err := manager.UpdateMessage("msg1", UpdateMessageRequest{
+		Unread:     &unread,
+		Flag:       FlagStatusFlagged,
+		Categories: &noCategories,
+	})
+	if err != nil {
+		t.Fatalf("UpdateMessage failed: %v", err)
+	}
+	if lastMethod != http.MethodPatch || lastPath != "/messages/msg1" {
+		t.Errorf("Expected PATCH /messages/msg1, got %s %s", lastMethod, lastPath)
+	}
+	if lastPayload["unread"] != false || lastPayload["flag"] != "flagged" {
+		t.Errorf("Unexpected payload: %v", lastPayload)
+	}
+	if categories, ok := lastPayload["categories"].([]interface{}); !ok || len(categories) != 0 {
+		t.Errorf("Expected empty categories list to be sent to clear categories, got %v", lastPayload["categories"])
+	}
+	if response.FlagStatus != FlagStatusFlagged || response.Unread {
+		t.Errorf("Unexpected response: %+v", response)
+	}
+
+	// Fields left nil are not sent, so they stay unchanged
+	if _, err := manager.UpdateMessage("msg1", UpdateMessageRequest{Flag: FlagStatusComplete}); err != nil {
+		t.Fatalf("UpdateMessage failed: %v", err)
+	}
+	if _, ok := lastPayload["unread"]; ok {
+		t.Error("unread should be omitted when not set")
+	}
+	if _, ok := lastPayload["categories"]; ok {
+		t.Error("categories should be omitted when not set")
+	}
+
+	if _, err := manager.UpdateMessage("msg1", UpdateMessageRequest{}); err == nil {
+		t.Error("Expected error for empty update")
+	}
+	if _, err := manager.UpdateMessage("msg1", UpdateMessageRequest{Flag: "urgent"}); err == nil {
+		t.Error("Expected error for invalid flag")
+	}
+}
+
+func TestManagerMoveAndDelete(t *testing.T) {
+	var lastMethod, lastURI string
+	var lastPayload map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		lastMethod = r.Method
+		lastURI = r.URL.RequestURI()
+		lastPayload = nil
+		json.NewDecoder(r.Body).Decode(&lastPayload)
+
+		switch {
+		case r.URL.Path == "/messages/msg1/move":
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"id": "msg1-moved", "previousId": "msg1", "folder": "Archive"}`))
+		case r.URL.Path == "/messages/trashed" && r.Method == http.MethodDelete:
+			// The sidecar refuses to soft-delete what is already in Deleted Items, since that would purge it
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"error": "The message is already in Deleted Items; deleting it again would remove it permanently, which is disabled.", "code": "PERMANENT_DELETE_DISABLED"}`))
+		case r.Method == http.MethodDelete:
+			permanent := r.URL.Query().Get("permanent") == "true"
+			w.WriteHeader(http.StatusOK)
+			if permanent {
+				w.Write([]byte(`{"id": "msg1", "permanent": true}`))
+			} else {
+				w.Write([]byte(`{"id": "msg1", "permanent": false, "folder": "Deleted Items"}`))
+			}
+		}
+	}))
+	defer server.Close()
+
+	manager := &Manager{
+		baseURL: server.URL,
+		client:  &http.Client{Timeout: 5 * time.Second},
+	}
+
+	moved, err := manager.MoveMessage("msg1", "Archive", "")
+	if err != nil {
+		t.Fatalf("MoveMessage failed: %v", err)
+	}
+	if lastMethod != http.MethodPost || lastPayload["folder"] != "Archive" {
+		t.Errorf("Unexpected move request: %s %v", lastMethod, lastPayload)
+	}
+	if moved.ID != "msg1-moved" || moved.Folder != "Archive" {
+		t.Errorf("Unexpected move response: %+v", moved)
+	}
+
+	if _, err := manager.MoveMessage("msg1", "", ""); err == nil {
+		t.Error("Expected error for missing destination folder")
+	}
+
+	deleted, err := manager.DeleteMessage("msg1", false)
+	if err != nil {
+		t.Fatalf("DeleteMessage failed: %v", err)
+	}
+	if lastMethod != http.MethodDelete || lastURI != "/messages/msg1" {
+		t.Errorf("Expected DELETE /messages/msg1, got %s %s", lastMethod, lastURI)
+	}
+	if deleted.Permanent || deleted.Folder != "Deleted Items" {
+		t.Errorf("Unexpected delete response: %+v", deleted)
+	}
+
+	// A message already in Deleted Items is not purged by a soft delete
+	_, err = manager.DeleteMessage("trashed", false)
+	var toolErr *shared.ToolError
+	if !errors.As(err, &toolErr) || toolErr.Code != shared.CodeAccessDenied || !containsString(err.Error(), "already in Deleted Items") {
+		t.Errorf("Expected ACCESS_DENIED for a message already in Deleted Items, got: %v", err)
+	}
+
+	// Permanent deletion is refused unless enabled
+	lastURI = ""
+	if _, err := manager.DeleteMessage("msg1", true); err == nil || !containsString(err.Error(), "--allow-permanent-delete") {
+		t.Errorf("Expected permanent delete to be refused, got: %v", err)
+	}
+	if lastURI != "" {
+		t.Error("Refused permanent delete should not reach the server")
+	}
+
+	manager.allowPurge = true
+	deleted, err = manager.DeleteMessage("msg1", true)
+	if err != nil {
+		t.Fatalf("Permanent DeleteMessage failed: %v", err)
+	}
+	if lastURI != "/messages/msg1?permanent=true" || !deleted.Permanent {
+		t.Errorf("Unexpected permanent delete: %s %+v", lastURI, deleted)
+	}
+}
+
+func TestManagerAttachments(t *testing.T) {
+	var lastMethod string
+	var lastPayload map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		lastMethod = r.Method
+		lastPayload = nil
+		json.NewDecoder(r.Body).Decode(&lastPayload)
+
+		switch r.URL.Path {
+		case "/messages/msg1/attachments":
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"id": "msg1", "count": 2, "attachments": [
+				{"index": 1, "fileName": "budget.xlsx", "size": 2048, "inline": false},
+				{"index": 2, "fileName": "logo.png", "size": 512, "inline": true}
+			]}`))
+		case "/messages/msg1/attachments/1/save":
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"id": "msg1", "index": 1, "fileName": "budget.xlsx", "path": "C:\\tmp\\budget.xlsx", "size": 2048}`))
+		}
+	}))
+	defer server.Close()
+
+	manager := &Manager{
+		baseURL: server.URL,
+		client:  &http.Client{Timeout: 5 * time.Second},
+	}
+
+	list, err := manager.ListAttachments("msg1")
+	if err != nil {
+		t.Fatalf("ListAttachments failed: %v", err)
+	}
+	if list.Count != 2 || list.Attachments[0].FileName != "budget.xlsx" || !list.Attachments[1].Inline {
+		t.Errorf("Unexpected attachment list: %+v", list)
+	}
+
+	dir := t.TempDir()
+	saved, err := manager.SaveAttachment("msg1", 1, dir)
+	if err != nil {
+		t.Fatalf("SaveAttachment failed: %v", err)
+	}
+	if lastMethod != http.MethodPost || lastPayload["directory"] != dir {
+		t.Errorf("Unexpected save request: %s %v", lastMethod, lastPayload)
+	}
+	if saved.FileName != "budget.xlsx" || saved.Size != 2048 {
+		t.Errorf("Unexpected save response: %+v", saved)
+	}
+
+	if _, err := manager.SaveAttachment("msg1", 0, dir); err == nil {
+		t.Error("Expected error for attachment index 0")
+	}
+}
+
+// TestSaveAttachmentSaveRoots tests that save_attachment only writes under the save roots
+func TestSaveAttachmentSaveRoots(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/$value") {
+			w.Write([]byte("quarterly numbers"))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"value": []map[string]any{{"id": "att1", "name": "report.csv", "size": 17}}})
+	}))
+	defer server.Close()
+
+	root := t.TempDir()
+	paths, err := sandbox.New([]string{root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := SaveAttachmentHandler(newTestGraphManager(server.URL), paths)
+	save := func(directory string) *mcp.CallToolResult {
+		t.Helper()
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]any{"message_id": "AAMk1", "directory": directory}
+		result, err := handler(context.Background(), request)
+		if err != nil {
+			t.Fatalf("save_attachment failed: %v", err)
+		}
+		return result
+	}
+
+	if result := save(filepath.Join(root, "mail")); result.IsError {
+		t.Fatalf("Expected a save under the root to succeed: %+v", result)
+	}
+	if data, err := os.ReadFile(filepath.Join(root, "mail", "report.csv")); err != nil || string(data) != "quarterly numbers" {
+		t.Errorf("Saved attachment = %q, %v", data, err)
+	}
+
+	result := save(t.TempDir())
+	if !result.IsError || !strings.Contains(result.Content[0].(mcp.TextContent).Text, shared.CodeAccessDenied) {
+		t.Errorf("Expected ACCESS_DENIED outside the root, got %+v", result)
+	}
+
+	// Without a directory the attachment goes to the first root, not the temp directory
+	if result := save(""); result.IsError {
+		t.Fatalf("Expected a save without a directory to succeed: %+v", result)
+	}
+	if data, err := os.ReadFile(filepath.Join(root, "report.csv")); err != nil || string(data) != "quarterly numbers" {
+		t.Errorf("Attachment saved without a directory = %q, %v; want it in the save root", data, err)
+	}
+}
+
+func TestManagerCalendar(t *testing.T) {
+	var lastQuery url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		lastQuery = r.URL.Query()
+
+		switch r.URL.Path {
+		case "/calendar/events":
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"start": "2024-06-03T00:00:00-07:00", "end": "2024-06-10T00:00:00-07:00", "count": 2, "truncated": false, "events": [
+				{"id": "ev1", "subject": "Standup", "start": "2024-06-03T09:00:00-07:00", "end": "2024-06-03T09:15:00-07:00", "isRecurring": true, "busyStatus": "busy"},
+				{"id": "ev1", "subject": "Standup", "start": "2024-06-04T09:00:00-07:00", "end": "2024-06-04T09:15:00-07:00", "isRecurring": true, "busyStatus": "busy"}
+			]}`))
+		case "/calendar/events/ev1":
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"id": "ev1", "subject": "Standup", "start": "2024-06-03T09:00:00-07:00", "end": "2024-06-03T09:15:00-07:00",
+				"isRecurring": true, "isMeeting": true, "busyStatus": "busy", "requiredAttendees": ["Alice", "Bob"], "reminderMinutes": 5,
+				"recurrence": {"type": "daily", "interval": 1, "patternStart": "2024-01-01T00:00:00-07:00"}}`))
+		}
+	}))
+	defer server.Close()
+
+	manager := &Manager{
+		baseURL: server.URL,
+		client:  &http.Client{Timeout: 5 * time.Second},
+	}
+
+	start := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 7)
+
+	list, err := manager.ListCalendarEvents(start, end, 50)
+	if err != nil {
+		t.Fatalf("ListCalendarEvents failed: %v", err)
+	}
+	if lastQuery.Get("start") != "2024-06-03T00:00:00Z" || lastQuery.Get("end") != "2024-06-10T00:00:00Z" || lastQuery.Get("limit") != "50" {
+		t.Errorf("Unexpected query: %v", lastQuery)
+	}
+	if list.Count != 2 || list.Events[1].Start.Day() != 4 || !list.Events[0].IsRecurring {
+		t.Errorf("Unexpected event list: %+v", list)
+	}
+
+	if _, err := manager.ListCalendarEvents(end, start, 0); err == nil {
+		t.Error("Expected error when end is before start")
+	}
+
+	event, err := manager.GetEvent("ev1")
+	if err != nil {
+		t.Fatalf("GetEvent failed: %v", err)
+	}
+	if len(event.RequiredAttendees) != 2 || event.Recurrence == nil || event.Recurrence.Type != "daily" || event.Recurrence.PatternEnd != nil {
+		t.Errorf("Unexpected event details: %+v", event)
+	}
+	if event.ReminderMinutes == nil || *event.ReminderMinutes != 5 {
+		t.Errorf("Expected 5 minute reminder, got %v", event.ReminderMinutes)
+	}
+}
+
+func TestManagerContacts(t *testing.T) {
+	var lastQuery url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		lastQuery = r.URL.Query()
+
+		switch r.URL.Path {
+		case "/contacts":
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"query": "finance", "count": 1, "truncated": false, "contacts": [
+				{"id": "c1", "name": "Bob Smith", "emails": ["bob@example.com"], "department": "Finance", "phones": {"business": "555-0100"}}
+			]}`))
+		case "/contacts/c1":
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"id": "c1", "name": "Bob Smith", "emails": ["bob@example.com", "bob.smith@home.example"],
+				"businessAddress": "1 Main St", "notes": "Prefers email", "categories": ["VIP"]}`))
+		case "/contacts/missing":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": "Contact not found", "code": "CONTACT_NOT_FOUND"}`))
+		}
+	}))
+	defer server.Close()
+
+	manager := &Manager{
+		baseURL: server.URL,
+		client:  &http.Client{Timeout: 5 * time.Second},
+	}
+
+	list, err := manager.SearchContacts("finance", 10)
+	if err != nil {
+		t.Fatalf("SearchContacts failed: %v", err)
+	}
+	if lastQuery.Get("q") != "finance" || lastQuery.Get("limit") != "10" {
+		t.Errorf("Unexpected query: %v", lastQuery)
+	}
+	if list.Count != 1 || list.Contacts[0].Emails[0] != "bob@example.com" || list.Contacts[0].Phones["business"] != "555-0100" {
+		t.Errorf("Unexpected contact list: %+v", list)
+	}
+
+	if _, err := manager.SearchContacts("", 0); err != nil {
+		t.Fatalf("SearchContacts without query failed: %v", err)
+	}
+	if len(lastQuery) != 0 {
+		t.Errorf("Expected no query parameters, got %v", lastQuery)
+	}
+
+	contact, err := manager.GetContact("c1")
+	if err != nil {
+		t.Fatalf("GetContact failed: %v", err)
+	}
+	if len(contact.Emails) != 2 || contact.BusinessAddress != "1 Main St" || contact.Notes != "Prefers email" {
+		t.Errorf("Unexpected contact details: %+v", contact)
+	}
+
+	if _, err := manager.GetContact("missing"); err == nil {
+		t.Error("Expected error for missing contact")
+	}
+}
+
+func TestManagerSearchFilters(t *testing.T) {
+	var lastQuery url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		lastQuery = r.URL.Query()
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"folder": "Inbox", "count": 1, "results": [{"id": "m1", "subject": "Invoice", "unread": true, "hasAttachments": true}]}`))
+	}))
+	defer server.Close()
+
+	manager := &Manager{
+		baseURL: server.URL,
+		client:  &http.Client{Timeout: 5 * time.Second},
+	}
+
+	hasAttachments := true
+	unread := false
+	filters := SearchFilters{
+		From:           "bob",
+		To:             "finance",
+		After:          time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+		Before:         time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC),
+		HasAttachments: &hasAttachments,
+		Unread:         &unread,
+		Importance:     ImportanceHigh,
+	}
+
+	response, err := manager.SearchMessages("", "", "", filters, 1, 0)
+	if err != nil {
+		t.Fatalf("SearchMessages failed: %v", err)
+	}
+	if response.Count != 1 {
+		t.Errorf("Expected 1 result, got %d", response.Count)
+	}
+
+	expected := map[string]string{
+		"from":            "bob",
+		"to":              "finance",
+		"after":           "2024-06-01T00:00:00Z",
+		"before":          "2024-07-01T00:00:00Z",
+		"has_attachments": "true",
+		"unread":          "false",
+		"importance":      "high",
+	}
+	for key, value := range expected {
+		if lastQuery.Get(key) != value {
+			t.Errorf("Expected %s=%s, got %q", key, value, lastQuery.Get(key))
+		}
+	}
+	if lastQuery.Has("q") {
+		t.Errorf("Expected no q parameter for a filter-only search, got %q", lastQuery.Get("q"))
+	}
+
+	if _, err := manager.SearchMessages("x", "", "", SearchFilters{Importance: "urgent"}, 1, 0); err == nil {
+		t.Error("Expected error for invalid importance")
+	}
+	if _, err := manager.SearchMessages("x", "", "", SearchFilters{After: filters.Before, Before: filters.After}, 1, 0); err == nil {
+		t.Error("Expected error when before is not later than after")
+	}
+}
+
+func TestManagerSearchPagination(t *testing.T) {
+	var lastQuery url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		lastQuery = r.URL.Query()
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"query": "report", "folder": "Inbox", "count": 1, "results": [{"id": "m3", "subject": "Report"}],
+			"pagination": {"page": 2, "pageSize": 2, "total": 5, "hasNext": true, "hasPrevious": true}}`))
+	}))
+	defer server.Close()
+
+	manager := &Manager{
+		baseURL: server.URL,
+		client:  &http.Client{Timeout: 5 * time.Second},
+	}
+
+	response, err := manager.SearchMessages("report", "", "", SearchFilters{}, 2, 2)
+	if err != nil {
+		t.Fatalf("SearchMessages failed: %v", err)
+	}
+	if lastQuery.Get("page") != "2" || lastQuery.Get("page_size") != "2" {
+		t.Errorf("Unexpected query: %v", lastQuery)
+	}
+	if response.Pagination.Total != 5 || !response.Pagination.HasNext || !response.Pagination.HasPrevious {
+		t.Errorf("Unexpected pagination: %+v", response.Pagination)
+	}
+
+	if _, err := manager.SearchMessages("report", "", "", SearchFilters{}, 0, 0); err != nil {
+		t.Fatalf("SearchMessages failed: %v", err)
+	}
+	if lastQuery.Get("page") != "1" || lastQuery.Has("page_size") {
+		t.Errorf("Expected page 1 with the server default page size, got %v", lastQuery)
+	}
+
+	if _, err := manager.SearchMessages("report", "", "", SearchFilters{}, 1, maxSearchPageSize+1); err == nil {
+		t.Error("Expected error for oversized page")
+	}
+}
+
+func TestManagerGetConversation(t *testing.T) {
+	var lastPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		lastPath = r.URL.Path
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"conversationId": "conv1", "topic": "Budget", "count": 2, "messages": [
+			{"id": "m1", "subject": "Budget", "sender": "Alice", "receivedTime": "2024-06-03T09:00:00Z", "bodyPreview": "Draft attached"},
+			{"id": "m2", "subject": "RE: Budget", "sender": "Bob", "receivedTime": "2024-06-03T11:30:00Z", "bodyPreview": "Looks good"}
+		]}`))
+	}))
+	defer server.Close()
+
+	manager := &Manager{
+		baseURL: server.URL,
+		client:  &http.Client{Timeout: 5 * time.Second},
+	}
+
+	conversation, err := manager.GetConversation("m2")
+	if err != nil {
+		t.Fatalf("GetConversation failed: %v", err)
+	}
+	if lastPath != "/messages/m2/conversation" {
+		t.Errorf("Unexpected path: %s", lastPath)
+	}
+	if conversation.Topic != "Budget" || len(conversation.Messages) != 2 || conversation.Messages[1].BodyPreview != "Looks good" {
+		t.Errorf("Unexpected conversation: %+v", conversation)
+	}
+
+	result := formatConversation(conversation)
+	if !containsString(result, "Conversation: Budget (2 messages, oldest first)") || !containsString(result, "2. 2024-06-03 11:30 - Bob") {
+		t.Errorf("Unexpected transcript: %s", result)
+	}
+}
+
+func TestManagerListMessagesSince(t *testing.T) {
+	var lastQuery url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		lastQuery = r.URL.Query()
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"folder": "Inbox", "since": "2024-06-03T09:30:15Z", "messages": [{"id": "m1", "subject": "New"}],
+			"pagination": {"page": 1, "pageSize": 10, "total": 1, "hasNext": false, "hasPrevious": false}}`))
+	}))
+	defer server.Close()
+
+	manager := &Manager{
+		baseURL: server.URL,
+		client:  &http.Client{Timeout: 5 * time.Second},
+	}
+
+	since := time.Date(2024, 6, 3, 9, 30, 15, 0, time.UTC)
+	response, err := manager.ListMessagesSince(since, 0, "inbox", "")
+	if err != nil {
+		t.Fatalf("ListMessagesSince failed: %v", err)
+	}
+	if lastQuery.Get("since") != "2024-06-03T09:30:15Z" || lastQuery.Get("page") != "1" || lastQuery.Get("folder") != "inbox" {
+		t.Errorf("Unexpected query: %v", lastQuery)
+	}
+	if response.Since == nil || !response.Since.Equal(since) || len(response.Messages) != 1 {
+		t.Errorf("Unexpected response: %+v", response)
+	}
+
+	if _, err := manager.ListMessagesSince(time.Time{}, 1, "", ""); err == nil {
+		t.Error("Expected error for zero since")
+	}
+}
+
+func TestManagerRulesAndCategories(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/rules":
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"count": 1, "rules": [{"name": "Invoices", "enabled": true, "executionOrder": 1, "type": "receive",
+				"conditions": ["Subject contains: invoice"], "actions": ["MoveToFolder: \\\\Mailbox\\Inbox\\Invoices", "Stop"]}]}`))
+		case "/categories":
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"count": 2, "categories": [{"name": "Finance", "color": "green", "shortcutKey": "Ctrl+F2"}, {"name": "Follow up", "color": "red"}]}`))
+		}
+	}))
+	defer server.Close()
+
+	manager := &Manager{
+		baseURL: server.URL,
+		client:  &http.Client{Timeout: 5 * time.Second},
+	}
+
+	rules, err := manager.ListRules()
+	if err != nil {
+		t.Fatalf("ListRules failed: %v", err)
+	}
+	if rules.Count != 1 || rules.Rules[0].Name != "Invoices" || len(rules.Rules[0].Actions) != 2 {
+		t.Errorf("Unexpected rules: %+v", rules)
+	}
+
+	categories, err := manager.ListCategories()
+	if err != nil {
+		t.Fatalf("ListCategories failed: %v", err)
+	}
+	if categories.Count != 2 || categories.Categories[0].ShortcutKey != "Ctrl+F2" {
+		t.Errorf("Unexpected categories: %+v", categories)
+	}
+
+	result := formatRuleList(rules.Rules) + formatCategoryList(categories.Categories)
+	if !containsString(result, "1. Invoices (receive)") || !containsString(result, "When: Subject contains: invoice") {
+		t.Errorf("Unexpected rule listing: %s", result)
+	}
+	if !containsString(result, "- Finance (green) [Ctrl+F2]") || !containsString(result, "- Follow up (red)") {
+		t.Errorf("Unexpected category listing: %s", result)
+	}
+}
+
+func TestManagerAccounts(t *testing.T) {
+	var lastQuery url.Values
+	var lastBody map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		lastQuery = r.URL.Query()
+
+		switch r.URL.Path {
+		case "/accounts":
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"accounts": [{"name": "Work", "smtpAddress": "me@example.com", "type": "exchange", "storeName": "me@example.com", "isDefault": true}],
+				"stores": [{"name": "me@example.com", "type": "primary", "isDefault": true}, {"name": "Support", "type": "delegate", "isDefault": false}]}`))
+		case "/folders":
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"account": "Support", "count": 1, "folders": [{"id": "f1", "name": "Inbox", "path": "\\\\Support\\Inbox"}]}`))
+		case "/messages":
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"folder": "Inbox", "messages": [], "pagination": {"page": 1, "pageSize": 10}}`))
+		case "/search":
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"query": "x", "results": [], "count": 0}`))
+		case "/messages/msg1/move":
+			json.NewDecoder(r.Body).Decode(&lastBody)
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"id": "msg2", "previousId": "msg1", "folder": "Inbox"}`))
+		}
+	}))
+	defer server.Close()
+
+	manager := &Manager{
+		baseURL: server.URL,
+		client:  &http.Client{Timeout: 5 * time.Second},
+	}
+
+	accounts, err := manager.ListAccounts()
+	if err != nil {
+		t.Fatalf("ListAccounts failed: %v", err)
+	}
+	if len(accounts.Accounts) != 1 || accounts.Accounts[0].SMTPAddress != "me@example.com" || len(accounts.Stores) != 2 {
+		t.Errorf("Unexpected accounts: %+v", accounts)
+	}
+	if result := formatAccountList(accounts); !containsString(result, "- Work <me@example.com> (exchange) [DEFAULT]") || !containsString(result, "- Support (delegate)") {
+		t.Errorf("Unexpected account listing: %s", result)
+	}
+
+	folders, err := manager.ListFolders("Support")
+	if err != nil {
+		t.Fatalf("ListFolders failed: %v", err)
+	}
+	if lastQuery.Get("account") != "Support" || folders.Account != "Support" {
+		t.Errorf("Expected account to be passed through, got query %v and response %+v", lastQuery, folders)
+	}
+
+	if _, err := manager.ListMessages(1, "inbox", "shared@example.com", ListFilters{}); err != nil {
+		t.Fatalf("ListMessages failed: %v", err)
+	}
+	if lastQuery.Get("account") != "shared@example.com" || lastQuery.Get("folder") != "inbox" || lastQuery.Has("unread") {
+		t.Errorf("Unexpected list query: %v", lastQuery)
+	}
+
+	withAttachments := true
+	if _, err := manager.ListMessages(1, "", "", ListFilters{UnreadOnly: true, Importance: "high", Category: "Red", HasAttachments: &withAttachments}); err != nil {
+		t.Fatalf("ListMessages failed: %v", err)
+	}
+	if lastQuery.Get("unread") != "true" || lastQuery.Get("importance") != "high" || lastQuery.Get("category") != "Red" || lastQuery.Get("has_attachments") != "true" {
+		t.Errorf("Unexpected filtered list query: %v", lastQuery)
+	}
+	if _, err := manager.ListMessages(1, "", "", ListFilters{Importance: "urgent"}); err == nil {
+		t.Error("Expected an error for an invalid importance")
+	}
+
+	if _, err := manager.SearchMessages("x", "", "Support", SearchFilters{}, 1, 0); err != nil {
+		t.Fatalf("SearchMessages failed: %v", err)
+	}
+	if lastQuery.Get("account") != "Support" || lastQuery.Has("folder") {
+		t.Errorf("Unexpected search query: %v", lastQuery)
+	}
+
+	if _, err := manager.MoveMessage("msg1", "Inbox", "Support"); err != nil {
+		t.Fatalf("MoveMessage failed: %v", err)
+	}
+	if lastBody["folder"] != "Inbox" || lastBody["account"] != "Support" {
+		t.Errorf("Unexpected move payload: %v", lastBody)
+	}
+}
+
+// TestManagerSendsBearerToken tests that every request carries the server token
+func TestManagerSendsBearerToken(t *testing.T) {
+	var gotAuth, gotHost string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotHost = r.Host
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(MessageListResponse{})
+	}))
+	defer server.Close()
+
+	manager := &Manager{
+		port:    server.Listener.Addr().(*net.TCPAddr).Port,
+		token:   "secret-token",
+		baseURL: server.URL,
+		client:  &http.Client{Timeout: 5 * time.Second},
+	}
+
+	if _, err := manager.ListMessages(1, "", "", ListFilters{}); err != nil {
+		t.Fatalf("ListMessages failed: %v", err)
+	}
+	if gotAuth != "Bearer secret-token" {
+		t.Errorf("Expected bearer token header, got %q", gotAuth)
+	}
+	if expected := fmt.Sprintf("localhost:%d", manager.port); gotHost != expected {
+		t.Errorf("Expected Host %q, got %q", expected, gotHost)
+	}
+}
+
+// TestServerPort tests port selection from the environment and the free-port fallback
+func TestServerPort(t *testing.T) {
+	t.Setenv("OUTLOOK_SERVER_PORT", "9090")
+	if port, err := serverPort(); err != nil || port != 9090 {
+		t.Errorf("Expected port 9090, got %d (%v)", port, err)
+	}
+
+	t.Setenv("OUTLOOK_SERVER_PORT", "not-a-port")
+	if _, err := serverPort(); err == nil {
+		t.Error("Expected error for invalid OUTLOOK_SERVER_PORT")
+	}
+
+	t.Setenv("OUTLOOK_SERVER_PORT", "")
+	port, err := serverPort()
+	if err != nil {
+		t.Fatalf("serverPort failed: %v", err)
+	}
+	if port <= 0 || port > 65535 {
+		t.Errorf("Expected a valid free port, got %d", port)
+	}
+
+	token, err := newServerToken()
+	if err != nil {
+		t.Fatalf("newServerToken failed: %v", err)
+	}
+	if len(token) != 64 {
+		t.Errorf("Expected 64 hex characters, got %d", len(token))
+	}
+}
+
+// TestManagerRetriesUntilServerReturns tests that reads ride out a server that is briefly down
+func TestManagerRetriesUntilServerReturns(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to reserve port: %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	// Bring the server up only after the first attempt has been refused
+	serverUp := make(chan *httptest.Server, 1)
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		l, err := net.Listen("tcp", addr)
+		if err != nil {
+			serverUp <- nil
+			return
+		}
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(FolderListResponse{})
+		}))
+		server.Listener = l
+		server.Start()
+		serverUp <- server
+	}()
+
+	manager := &Manager{
+		baseURL:    "http://" + addr,
+		client:     &http.Client{Timeout: 5 * time.Second},
+		maxRetries: 5,
+		retryDelay: 50 * time.Millisecond,
+	}
+
+	_, err = manager.ListFolders("")
+	server := <-serverUp
+	if server == nil {
+		t.Skip("Could not rebind the reserved port")
+	}
+	defer server.Close()
+	if err != nil {
+		t.Fatalf("Expected request to succeed after retries, got %v", err)
+	}
+}
+
+// TestManagerReportsRestarting tests that transport errors during a restart say so
+func TestManagerReportsRestarting(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	baseURL := server.URL
+	server.Close()
+
+	manager := &Manager{baseURL: baseURL, client: &http.Client{Timeout: 5 * time.Second}}
+
+	_, err := manager.ListFolders("")
+	if err == nil || errors.Is(err, ErrServerRestarting) {
+		t.Fatalf("Expected a plain transport error, got %v", err)
+	}
+
+	manager.restarting.Store(true)
+	_, err = manager.ListFolders("")
+	if !errors.Is(err, ErrServerRestarting) {
+		t.Errorf("Expected ErrServerRestarting, got %v", err)
+	}
+}
+
+// TestManagerDoesNotRetryServerErrors tests that HTTP error responses are returned immediately
+func TestManagerDoesNotRetryServerErrors(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "boom"})
+	}))
+	defer server.Close()
+
+	manager := &Manager{
+		baseURL:    server.URL,
+		client:     &http.Client{Timeout: 5 * time.Second},
+		maxRetries: 3,
+		retryDelay: time.Millisecond,
+	}
+
+	if _, err := manager.ListFolders(""); err == nil {
+		t.Fatal("Expected server error")
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 call, got %d", calls)
+	}
+}
+
+// TestShouldRetry tests which failures are safe to retry
+func TestShouldRetry(t *testing.T) {
+	dialErr := fmt.Errorf("%w: %w", errTransport, &net.OpError{Op: "dial", Err: errors.New("connection refused")})
+	readErr := fmt.Errorf("%w: %w", errTransport, &net.OpError{Op: "read", Err: errors.New("connection reset")})
+	serverErr := errors.New("server error (500): boom")
+
+	tests := []struct {
+		method   string
+		err      error
+		expected bool
+	}{
+		{http.MethodGet, dialErr, true},
+		{http.MethodGet, readErr, true},
+		{http.MethodPost, dialErr, true},
+		{http.MethodPost, readErr, false},
+		{http.MethodGet, serverErr, false},
+	}
+
+	for _, tt := range tests {
+		if got := shouldRetry(tt.method, tt.err); got != tt.expected {
+			t.Errorf("shouldRetry(%s, %v) = %t, expected %t", tt.method, tt.err, got, tt.expected)
+		}
+	}
+}
+
+// TestManagerExportMessage tests how export targets are passed to the server
+func TestManagerExportMessage(t *testing.T) {
+	var lastPath string
+	var lastBody map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastPath = r.URL.Path
+		lastBody = nil
+		json.NewDecoder(r.Body).Decode(&lastBody)
+		response := ExportMessageResponse{ID: "msg1", Format: lastBody["format"], Size: 3}
+		if lastBody["path"] == "" && lastBody["directory"] == "" {
+			response.Content = "YWJj"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	manager := &Manager{baseURL: server.URL, client: &http.Client{Timeout: 5 * time.Second}}
+	dir := t.TempDir()
+
+	response, err := manager.ExportMessage("msg1", "", "")
+	if err != nil {
+		t.Fatalf("ExportMessage failed: %v", err)
+	}
+	if lastPath != "/messages/msg1/export" || lastBody["format"] != ExportFormatMsg || response.Content != "YWJj" {
+		t.Errorf("Unexpected inline export: path %s, body %v, response %+v", lastPath, lastBody, response)
+	}
+
+	if _, err := manager.ExportMessage("msg1", ExportFormatEml, dir); err != nil {
+		t.Fatalf("ExportMessage failed: %v", err)
+	}
+	if lastBody["directory"] != dir || lastBody["path"] != "" {
+		t.Errorf("Expected directory export, got %v", lastBody)
+	}
+
+	target := filepath.Join(dir, "nested", "thread.eml")
+	if _, err := manager.ExportMessage("msg1", ExportFormatEml, target); err != nil {
+		t.Fatalf("ExportMessage failed: %v", err)
+	}
+	if lastBody["path"] != target {
+		t.Errorf("Expected file export to %s, got %v", target, lastBody)
+	}
+	if info, err := os.Stat(filepath.Dir(target)); err != nil || !info.IsDir() {
+		t.Errorf("Expected parent directory to be created: %v", err)
+	}
+
+	existing := filepath.Join(dir, "existing.msg")
+	os.WriteFile(existing, []byte("x"), 0644)
+	if _, err := manager.ExportMessage("msg1", ExportFormatMsg, existing); err == nil {
+		t.Error("Expected error for existing file")
+	}
+
+	if _, err := manager.ExportMessage("msg1", "pdf", ""); err == nil {
+		t.Error("Expected error for unsupported format")
+	}
+}
+
+// TestManagerTasks tests listing, creating and completing tasks
+func TestManagerTasks(t *testing.T) {
+	var lastRequest *http.Request
+	var lastBody CreateTaskRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastRequest = r
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/tasks":
+			json.NewEncoder(w).Encode(TaskListResponse{
+				Status: r.URL.Query().Get("status"),
+				Tasks:  []Task{{ID: "t1", Subject: "Reply to Bob", Kind: TaskKindMessage, Status: "not_started"}},
+				Count:  1,
+			})
+		case r.Method == http.MethodPost && r.URL.Path == "/tasks":
+			json.NewDecoder(r.Body).Decode(&lastBody)
+			kind := TaskKindTask
+			if lastBody.MessageID != "" {
+				kind = TaskKindMessage
+			}
+			json.NewEncoder(w).Encode(Task{ID: "t2", Subject: lastBody.Subject, Kind: kind, DueDate: lastBody.DueDate})
+		case r.Method == http.MethodPost && r.URL.Path == "/tasks/t2/complete":
+			json.NewEncoder(w).Encode(Task{ID: "t2", Status: "completed", Complete: true})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	manager := &Manager{baseURL: server.URL, client: &http.Client{Timeout: 5 * time.Second}}
+
+	list, err := manager.ListTasks(TaskStatusAll, 10)
+	if err != nil {
+		t.Fatalf("ListTasks failed: %v", err)
+	}
+	if list.Status != TaskStatusAll || lastRequest.URL.Query().Get("limit") != "10" || len(list.Tasks) != 1 {
+		t.Errorf("Unexpected list response %+v for %s", list, lastRequest.URL)
+	}
+	if _, err := manager.ListTasks("overdue", 0); err == nil {
+		t.Error("Expected error for invalid status")
+	}
+
+	task, err := manager.CreateTask(CreateTaskRequest{Subject: "Prepare slides", DueDate: "2026-10-20", Importance: ImportanceHigh})
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+	if task.Kind != TaskKindTask || lastBody.DueDate != "2026-10-20" || lastBody.Importance != ImportanceHigh {
+		t.Errorf("Unexpected task %+v for body %+v", task, lastBody)
+	}
+
+	flagged, err := manager.CreateTask(CreateTaskRequest{MessageID: "msg1"})
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+	if flagged.Kind != TaskKindMessage || lastBody.MessageID != "msg1" {
+		t.Errorf("Expected message to be flagged, got %+v", flagged)
+	}
+
+	for _, invalid := range []CreateTaskRequest{
+		{},
+		{Subject: "x", DueDate: "next friday"},
+		{Subject: "x", Importance: "urgent"},
+	} {
+		if _, err := manager.CreateTask(invalid); err == nil {
+			t.Errorf("Expected error for %+v", invalid)
+		}
+	}
+
+	completed, err := manager.CompleteTask("t2")
+	if err != nil {
+		t.Fatalf("CompleteTask failed: %v", err)
+	}
+	if !completed.Complete {
+		t.Errorf("Expected completed task, got %+v", completed)
+	}
+}
+
+func TestManagerGetProfile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/profile" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"displayName": "Kim Lee", "smtpAddress": "kim@example.com", "profile": "Outlook",
+			"signatures": [
+				{"name": "Work", "text": "Kim Lee\r\nExample Corp", "html": "<p>Kim Lee</p>", "defaultNew": true, "defaultReply": false},
+				{"name": "Short", "html": "<p>Kim</p>", "defaultNew": false, "defaultReply": true}]}`))
+	}))
+	defer server.Close()
+
+	manager := &Manager{baseURL: server.URL, client: &http.Client{Timeout: 5 * time.Second}}
+	profile, err := manager.GetProfile()
+	if err != nil {
+		t.Fatalf("GetProfile failed: %v", err)
+	}
+	if profile.SMTPAddress != "kim@example.com" || len(profile.Signatures) != 2 || !profile.Signatures[1].DefaultReply {
+		t.Errorf("Unexpected profile %+v", profile)
+	}
+
+	formatted := formatUserProfile(profile, false)
+	for _, expected := range []string{"Email: kim@example.com", "--- Work (default for new messages) ---", "(HTML only; request include_html to see it)"} {
+		if !strings.Contains(formatted, expected) {
+			t.Errorf("Expected %q in:\n%s", expected, formatted)
+		}
+	}
+	if strings.Contains(formatted, "<p>") {
+		t.Errorf("Expected no HTML without include_html:\n%s", formatted)
+	}
+}
+
+func TestGetMessageBodyRawWindow(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(MessageBodyRawResponse{
+			ID:       "msg1",
+			BodyText: "short text",
+			BodyHTML: "<p>" + strings.Repeat("x", 30) + "</p>",
+			Format:   "html",
+		})
+	}))
+	defer server.Close()
+	handler := GetMessageBodyRawHandler(&Manager{baseURL: server.URL, client: &http.Client{Timeout: 5 * time.Second}})
+	call := func(arguments map[string]any) string {
+		t.Helper()
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = arguments
+		result, err := handler(context.Background(), request)
+		if err != nil {
+			t.Fatalf("get_message_body_raw failed: %v", err)
+		}
+		text := result.Content[0].(mcp.TextContent).Text
+		if result.IsError {
+			return "error: " + text
+		}
+		return text
+	}
+
+	text := call(map[string]any{"message_id": "msg1", "max_chars": 20})
+	for _, want := range []string{
+		"Plain Text Body:\nshort text\n",
+		"HTML Body (characters 0-20 of 37):\n<p>" + strings.Repeat("x", 17) + "\n",
+		"[17 more characters; call again with offset=20 to continue]",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Missing %q in:\n%s", want, text)
+		}
+	}
+
+	text = call(map[string]any{"message_id": "msg1", "offset": 20, "max_chars": 20})
+	if !strings.Contains(text, "Plain Text Body (characters 10-10 of 10):") || !strings.Contains(text, "HTML Body (characters 20-37 of 37):") || strings.Contains(text, "more characters") {
+		t.Errorf("Unexpected second window:\n%s", text)
+	}
+
+	if text := call(map[string]any{"message_id": "msg1", "offset": 37}); !strings.Contains(text, "past the end") {
+		t.Errorf("Expected an offset error, got %s", text)
+	}
+	if text := call(map[string]any{"message_id": "msg1", "max_chars": maxBodyChars + 1}); !strings.Contains(text, shared.CodeInvalidArgument) {
+		t.Errorf("Expected an invalid max_chars error, got %s", text)
+	}
+}
@@ -0,0 +1,160 @@
+package outlook
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/kevsmith/my-mcp/pkg/shared"
+)
+
+// Limits on poll_new_messages
+const (
+	defaultPollLimit = 25
+	maxPollScan      = 500 // Matches examined per poll; older ones beyond it are skipped
+	maxPollWait      = 5 * time.Minute
+)
+
+// pollInterval is how often PollNewMessages searches again while waiting
+var pollInterval = 15 * time.Second
+
+// pollCursor is the decoded form of a poll cursor: the receive time of the
+// newest message returned so far and the IDs of the messages received at
+// exactly that time, which a search from that time finds again
+type pollCursor struct {
+	After time.Time `json:"after"`
+	Seen  []string  `json:"seen,omitempty"`
+}
+
+// encodePollCursor returns the opaque string form of a cursor
+func encodePollCursor(cursor pollCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodePollCursor parses a cursor from encodePollCursor
+func decodePollCursor(value string) (pollCursor, error) {
+	var cursor pollCursor
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err == nil {
+		err = json.Unmarshal(data, &cursor)
+	}
+	if err != nil || cursor.After.IsZero() {
+		return pollCursor{}, shared.NewError(shared.CodeInvalidArgument, "invalid cursor: pass the cursor returned by the previous poll_new_messages call")
+	}
+	return cursor, nil
+}
+
+// isNew reports whether a message arrived after the cursor
+func (c pollCursor) isNew(message Message) bool {
+	if message.ReceivedTime.Equal(c.After) {
+		return !slices.Contains(c.Seen, message.ID)
+	}
+	return message.ReceivedTime.After(c.After)
+}
+
+// PollNewMessages returns the messages matching a search that arrived after
+// the request's cursor, oldest first, with a cursor for the next call. A
+// request without a cursor starts watching from Since, or from now. With a
+// Wait, the search is repeated every pollInterval until something new
+// arrives, the wait is over or ctx is cancelled; the result then has no
+// messages and the cursor is unchanged.
+func PollNewMessages(ctx context.Context, manager Backend, request PollRequest) (*PollResult, error) {
+	if request.Limit < 0 || request.Limit > maxSearchPageSize {
+		return nil, fmt.Errorf("limit must be between 1 and %d", maxSearchPageSize)
+	}
+	if request.Limit == 0 {
+		request.Limit = defaultPollLimit
+	}
+	if request.Wait < 0 || request.Wait > maxPollWait {
+		return nil, fmt.Errorf("wait must be between 0 and %s", maxPollWait)
+	}
+	if err := validateSearch(request.Filters, 0); err != nil {
+		return nil, err
+	}
+
+	var cursor pollCursor
+	if request.Cursor != "" {
+		var err error
+		if cursor, err = decodePollCursor(request.Cursor); err != nil {
+			return nil, err
+		}
+	} else if !request.Since.IsZero() {
+		cursor.After = request.Since
+	} else {
+		cursor.After = time.Now()
+	}
+
+	deadline := time.Now().Add(request.Wait)
+	for {
+		result, err := pollOnce(manager, request, cursor)
+		if err != nil || len(result.Messages) > 0 || !time.Now().Before(deadline) {
+			return result, err
+		}
+		select {
+		case <-ctx.Done():
+			return result, nil
+		case <-time.After(min(pollInterval, time.Until(deadline))):
+		}
+	}
+}
+
+// pollOnce searches for messages received since the cursor and returns the
+// oldest of them up to the request's limit
+func pollOnce(manager Backend, request PollRequest, cursor pollCursor) (*PollResult, error) {
+	filters := request.Filters
+	if filters.After.Before(cursor.After) {
+		filters.After = cursor.After
+	}
+
+	result := &PollResult{Messages: []Message{}}
+	var found []Message
+	for page := 1; ; page++ {
+		response, err := manager.SearchMessages(request.Query, request.Folder, request.Account, filters, page, maxSearchPageSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search messages: %w", err)
+		}
+		for _, message := range response.Results {
+			if cursor.isNew(message) {
+				found = append(found, message)
+			}
+		}
+		if response.Truncated {
+			result.Truncated = true
+		}
+		if !response.Pagination.HasNext {
+			break
+		}
+		if len(found) >= maxPollScan {
+			result.Truncated = true
+			break
+		}
+	}
+
+	sort.SliceStable(found, func(i, j int) bool { return found[i].ReceivedTime.Before(found[j].ReceivedTime) })
+	if len(found) > request.Limit {
+		found = found[:request.Limit]
+		result.HasMore = true
+	}
+	result.Messages = append(result.Messages, found...)
+
+	if len(found) > 0 {
+		newest := found[len(found)-1].ReceivedTime
+		next := pollCursor{After: newest}
+		if newest.Equal(cursor.After) {
+			next.Seen = slices.Clone(cursor.Seen)
+		}
+		for _, message := range found {
+			if message.ReceivedTime.Equal(newest) {
+				next.Seen = append(next.Seen, message.ID)
+			}
+		}
+		cursor = next
+	}
+	result.Cursor = encodePollCursor(cursor)
+	return result, nil
+}
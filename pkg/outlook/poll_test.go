@@ -0,0 +1,104 @@
+package outlook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPollNewMessages(t *testing.T) {
+	base := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	var mu sync.Mutex
+	var inbox []Message
+	var afters []string
+	deliver := func(id string, minutes int) {
+		mu.Lock()
+		defer mu.Unlock()
+		inbox = append([]Message{{ID: id, Subject: "Invoice " + id, ReceivedTime: base.Add(time.Duration(minutes) * time.Minute)}}, inbox...)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/search" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		afters = append(afters, r.URL.Query().Get("after"))
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(SearchResponse{Query: "invoice", Results: inbox, Count: len(inbox), Pagination: Pagination{Page: 1, PageSize: 100, Total: len(inbox)}})
+	}))
+	defer server.Close()
+	manager := &Manager{baseURL: server.URL, client: &http.Client{Timeout: 5 * time.Second}}
+	ctx := context.Background()
+
+	// A new watch only reports messages from its starting point on
+	deliver("old", -10)
+	deliver("a", 1)
+	deliver("b", 1)
+	result, err := PollNewMessages(ctx, manager, PollRequest{Query: "invoice", Since: base})
+	if err != nil {
+		t.Fatalf("PollNewMessages failed: %v", err)
+	}
+	if len(result.Messages) != 2 || result.Messages[0].ID != "b" || result.HasMore {
+		t.Fatalf("Unexpected first poll: %+v", result)
+	}
+	if afters[0] != base.Format(time.RFC3339) {
+		t.Errorf("Search after = %q, want the watch start", afters[0])
+	}
+
+	// The cursor skips what was returned, even at the same receive time
+	deliver("c", 1)
+	deliver("d", 2)
+	deliver("e", 3)
+	result, err = PollNewMessages(ctx, manager, PollRequest{Cursor: result.Cursor, Query: "invoice", Limit: 2})
+	if err != nil {
+		t.Fatalf("Second poll failed: %v", err)
+	}
+	if len(result.Messages) != 2 || result.Messages[0].ID != "c" || result.Messages[1].ID != "d" || !result.HasMore {
+		t.Fatalf("Unexpected second poll: %+v", result)
+	}
+	result, err = PollNewMessages(ctx, manager, PollRequest{Cursor: result.Cursor, Query: "invoice"})
+	if err != nil {
+		t.Fatalf("Third poll failed: %v", err)
+	}
+	if len(result.Messages) != 1 || result.Messages[0].ID != "e" {
+		t.Fatalf("Unexpected third poll: %+v", result)
+	}
+
+	// With nothing new, the cursor stays put; waiting picks up later arrivals
+	cursor := result.Cursor
+	result, err = PollNewMessages(ctx, manager, PollRequest{Cursor: cursor, Query: "invoice"})
+	if err != nil || len(result.Messages) != 0 || result.Cursor != cursor {
+		t.Fatalf("Unexpected empty poll: %+v, %v", result, err)
+	}
+	defer func(interval time.Duration) { pollInterval = interval }(pollInterval)
+	pollInterval = 10 * time.Millisecond
+	time.AfterFunc(30*time.Millisecond, func() { deliver("f", 4) })
+	result, err = PollNewMessages(ctx, manager, PollRequest{Cursor: cursor, Query: "invoice", Wait: 5 * time.Second})
+	if err != nil || len(result.Messages) != 1 || result.Messages[0].ID != "f" {
+		t.Fatalf("Unexpected waiting poll: %+v, %v", result, err)
+	}
+
+	// Cancelling a wait returns what there is
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if result, err = PollNewMessages(cancelled, manager, PollRequest{Cursor: result.Cursor, Wait: time.Minute}); err != nil || len(result.Messages) != 0 {
+		t.Errorf("Unexpected cancelled poll: %+v, %v", result, err)
+	}
+
+	for _, bad := range []PollRequest{
+		{Cursor: "not a cursor"},
+		{Limit: maxSearchPageSize + 1},
+		{Wait: maxPollWait + time.Second},
+		{Filters: SearchFilters{Importance: "urgent"}},
+	} {
+		if _, err := PollNewMessages(ctx, manager, bad); err == nil {
+			t.Errorf("Expected an error for %+v", bad)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package outlook
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// defaultPowerShell is Windows PowerShell 5.1, present on every Windows
+// install. Environments that block it usually allow PowerShell 7 (pwsh.exe).
+const defaultPowerShell = "powershell.exe"
+
+// PowerShellConfig selects how the PowerShell server is launched
+type PowerShellConfig struct {
+	Executable string   // powershell.exe, pwsh.exe or a full path
+	Args       []string // Extra startup arguments, placed before -File
+	ScriptPath string   // Script run in place of the embedded one; empty runs the embedded script
+}
+
+// GetPowerShellConfig returns the launch configuration from OUTLOOK_POWERSHELL,
+// OUTLOOK_POWERSHELL_ARGS (separated by spaces) and OUTLOOK_SERVER_SCRIPT
+func GetPowerShellConfig() PowerShellConfig {
+	config := PowerShellConfig{
+		Executable: defaultPowerShell,
+		Args:       strings.Fields(os.Getenv("OUTLOOK_POWERSHELL_ARGS")),
+		ScriptPath: os.Getenv("OUTLOOK_SERVER_SCRIPT"),
+	}
+	if executable := os.Getenv("OUTLOOK_POWERSHELL"); executable != "" {
+		config.Executable = executable
+	}
+	return config
+}
+
+// commandArgs returns the arguments that run script
+func (c PowerShellConfig) commandArgs(script string) []string {
+	args := []string{"-ExecutionPolicy", "Bypass"}
+	args = append(args, c.Args...)
+	return append(args, "-File", script)
+}
+
+// script returns the server script the configuration runs: the embedded one,
+// or the contents of ScriptPath
+func (c PowerShellConfig) script() (string, error) {
+	if c.ScriptPath == "" {
+		return outlookServerScript, nil
+	}
+	data, err := os.ReadFile(c.ScriptPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read OUTLOOK_SERVER_SCRIPT: %w", err)
+	}
+	return string(data), nil
+}
+
+// lookPath finds the executable, suggesting pwsh.exe when Windows PowerShell
+// is missing
+func (c PowerShellConfig) lookPath() (string, error) {
+	path, err := exec.LookPath(c.Executable)
+	if err != nil {
+		if c.Executable == defaultPowerShell {
+			return "", fmt.Errorf("%s not found; set OUTLOOK_POWERSHELL=pwsh.exe to use PowerShell 7: %w", c.Executable, err)
+		}
+		return "", fmt.Errorf("PowerShell executable %s not found: %w", c.Executable, err)
+	}
+	return path, nil
+}
+
+// scriptFile returns a path PowerShell can run the server script from, and a
+// function that removes it once the server has read it. The embedded script
+// is written to a temporary file; an override runs in place, so signed
+// scripts keep their signature.
+func (c PowerShellConfig) scriptFile() (string, func(), error) {
+	if c.ScriptPath != "" {
+		return c.ScriptPath, func() {}, nil
+	}
+
+	tmpFile, err := os.CreateTemp("", "outlook-server-*.ps1")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	if _, err := tmpFile.WriteString(outlookServerScript); err != nil {
+		tmpFile.Close()
+		os.Remove(tmpFile.Name())
+		return "", nil, fmt.Errorf("failed to write script: %w", err)
+	}
+	tmpFile.Close()
+
+	return tmpFile.Name(), func() { os.Remove(tmpFile.Name()) }, nil
+}
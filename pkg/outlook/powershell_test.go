@@ -0,0 +1,70 @@
+package outlook
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGetPowerShellConfig(t *testing.T) {
+	t.Setenv("OUTLOOK_POWERSHELL", "")
+	t.Setenv("OUTLOOK_POWERSHELL_ARGS", "")
+	t.Setenv("OUTLOOK_SERVER_SCRIPT", "")
+	config := GetPowerShellConfig()
+	if config.Executable != "powershell.exe" || len(config.Args) != 0 || config.ScriptPath != "" {
+		t.Errorf("Default config = %+v, want powershell.exe and the embedded script", config)
+	}
+	if got, want := config.commandArgs("server.ps1"), []string{"-ExecutionPolicy", "Bypass", "-File", "server.ps1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("commandArgs = %v, want %v", got, want)
+	}
+
+	t.Setenv("OUTLOOK_POWERSHELL", "pwsh.exe")
+	t.Setenv("OUTLOOK_POWERSHELL_ARGS", " -NoProfile  -NonInteractive ")
+	config = GetPowerShellConfig()
+	if config.Executable != "pwsh.exe" {
+		t.Errorf("Executable = %q, want pwsh.exe", config.Executable)
+	}
+	want := []string{"-ExecutionPolicy", "Bypass", "-NoProfile", "-NonInteractive", "-File", "server.ps1"}
+	if got := config.commandArgs("server.ps1"); !reflect.DeepEqual(got, want) {
+		t.Errorf("commandArgs = %v, want extra arguments before -File: %v", got, want)
+	}
+}
+
+func TestPowerShellScriptOverride(t *testing.T) {
+	embedded := PowerShellConfig{}
+	path, cleanup, err := embedded.scriptFile()
+	if err != nil {
+		t.Fatalf("scriptFile failed: %v", err)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != outlookServerScript {
+		t.Errorf("Temp script doesn't hold the embedded script: %v", err)
+	}
+	cleanup()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("cleanup left the temp script behind: %v", err)
+	}
+
+	override := filepath.Join(t.TempDir(), "signed-server.ps1")
+	if err := os.WriteFile(override, []byte("# custom server"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config := PowerShellConfig{ScriptPath: override}
+	path, cleanup, err = config.scriptFile()
+	if err != nil || path != override {
+		t.Fatalf("scriptFile = %q, %v; want the override run in place", path, err)
+	}
+	cleanup()
+	if _, err := os.Stat(override); err != nil {
+		t.Errorf("cleanup removed the override script: %v", err)
+	}
+	if script, err := config.script(); err != nil || script != "# custom server" {
+		t.Errorf("script = %q, %v", script, err)
+	}
+
+	config.ScriptPath = filepath.Join(t.TempDir(), "missing.ps1")
+	if _, err := config.script(); err == nil || !strings.Contains(err.Error(), "OUTLOOK_SERVER_SCRIPT") {
+		t.Errorf("Expected an error naming OUTLOOK_SERVER_SCRIPT, got %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package outlook
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif" // Registers the GIF decoder
+	"image/jpeg"
+	"image/png"
+	"math"
+	"path/filepath"
+	"strings"
+
+	_ "golang.org/x/image/bmp" // Registers the BMP decoder
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/tiff" // Registers the TIFF decoder
+	_ "golang.org/x/image/webp" // Registers the WebP decoder
+)
+
+// Limits on attachment thumbnails
+const (
+	defaultAttachmentThumbnailSize = 128
+	maxAttachmentThumbnailSize     = 512
+	maxAttachmentPreviews          = 10         // Thumbnails returned by one list_attachments call
+	maxPreviewPixels               = 50_000_000 // Larger images aren't decoded
+)
+
+// previewExtensions are the attachment file types thumbnails are made for
+var previewExtensions = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true,
+	".bmp": true, ".webp": true, ".tif": true, ".tiff": true,
+}
+
+// isPreviewableAttachment reports whether an attachment looks like an image
+// a thumbnail can be made of
+func isPreviewableAttachment(attachment Attachment) bool {
+	return previewExtensions[strings.ToLower(filepath.Ext(attachment.FileName))]
+}
+
+// attachmentThumbnail decodes an image and returns a copy scaled down to fit
+// in a size by size square, as JPEG, or as PNG when it has transparency
+func attachmentThumbnail(data []byte, size int) ([]byte, string, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("not a supported image: %w", err)
+	}
+	if config.Width*config.Height > maxPreviewPixels {
+		return nil, "", fmt.Errorf("image is %dx%d; thumbnails are made for images up to %d pixels", config.Width, config.Height, maxPreviewPixels)
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if scale := float64(size) / float64(max(width, height)); scale < 1 {
+		width = max(1, int(math.Round(float64(width)*scale)))
+		height = max(1, int(math.Round(float64(height)*scale)))
+	}
+	thumb := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(thumb, thumb.Bounds(), src, bounds, draw.Src, nil)
+
+	var encoded bytes.Buffer
+	if thumb.Opaque() {
+		err = jpeg.Encode(&encoded, thumb, &jpeg.Options{Quality: 80})
+		return encoded.Bytes(), "image/jpeg", err
+	}
+	err = png.Encode(&encoded, thumb)
+	return encoded.Bytes(), "image/png", err
+}
@@ -0,0 +1,95 @@
+package outlook
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestListAttachmentsThumbnails(t *testing.T) {
+	photo := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	for x := range 400 {
+		for y := range 200 {
+			photo.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 90, A: 255})
+		}
+	}
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, photo); err != nil {
+		t.Fatal(err)
+	}
+
+	var fetched []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/messages/msg1/attachments":
+			w.Write([]byte(`{"id": "msg1", "count": 3, "attachments": [
+				{"index": 1, "fileName": "photo.PNG", "size": 4096},
+				{"index": 2, "fileName": "notes.txt", "size": 10},
+				{"index": 3, "fileName": "broken.jpg", "size": 5}
+			]}`))
+		case "/messages/msg1/attachments/1/content", "/messages/msg1/attachments/3/content":
+			fetched = append(fetched, r.URL.Path)
+			content := []byte("nope!")
+			if strings.Contains(r.URL.Path, "/1/") {
+				content = encoded.Bytes()
+			}
+			json.NewEncoder(w).Encode(AttachmentContentResponse{ID: "msg1", FileName: "x", Size: int64(len(content)), Content: content})
+		default:
+			t.Errorf("Unexpected request %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	manager := &Manager{baseURL: server.URL, client: &http.Client{Timeout: 5 * time.Second}}
+	handler := ListAttachmentsHandler(manager)
+	call := func(args map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = args
+		result, err := handler(context.Background(), request)
+		if err != nil {
+			t.Fatalf("Handler failed: %v", err)
+		}
+		return result
+	}
+
+	// Without thumbnails nothing is downloaded
+	if result := call(map[string]any{"message_id": "msg1"}); len(result.Content) != 1 || len(fetched) != 0 {
+		t.Fatalf("Unexpected plain result: %+v, fetched %v", result.Content, fetched)
+	}
+
+	// Image attachments get a thumbnail; ones that don't decode are noted
+	result := call(map[string]any{"message_id": "msg1", "thumbnails": true, "thumbnail_size": 100})
+	if result.IsError || len(result.Content) != 3 || len(fetched) != 2 {
+		t.Fatalf("Unexpected preview result: %+v, fetched %v", result.Content, fetched)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "3. broken.jpg: no preview") {
+		t.Errorf("Broken image not noted: %q", text)
+	}
+	preview, ok := result.Content[2].(mcp.ImageContent)
+	if !ok || preview.MIMEType != "image/jpeg" {
+		t.Fatalf("Expected a JPEG thumbnail, got %+v", result.Content[2])
+	}
+	data, _ := base64.StdEncoding.DecodeString(preview.Data)
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || config.Width != 100 || config.Height != 50 {
+		t.Errorf("Thumbnail is %dx%d (%v), want 100x50", config.Width, config.Height, err)
+	}
+
+	if result := call(map[string]any{"message_id": "msg1", "thumbnails": true, "thumbnail_size": 4096}); !result.IsError {
+		t.Error("Expected an error for an oversized thumbnail_size")
+	}
+}
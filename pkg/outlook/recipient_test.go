@@ -0,0 +1,112 @@
+package outlook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResolveRecipientCOM(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/recipients/resolve" {
+			t.Errorf("Unexpected request %s", r.URL.Path)
+		}
+		query = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		if query.Get("name") == "Chris" {
+			w.Write([]byte(`{"query": "Chris", "resolved": false}`))
+			return
+		}
+		w.Write([]byte(`{"query": "eng-leads", "resolved": true, "name": "Engineering Leads", "address": "eng-leads@example.com", "type": "distribution_list",
+			"members": [
+				{"name": "Ana Silva", "address": "ana@example.com", "type": "user"},
+				{"name": "Bo Chen", "address": "bo@example.com", "type": "user"}
+			],
+			"nestedLists": [{"name": "Platform Leads", "address": "platform-leads@example.com", "type": "distribution_list"}],
+			"membersTruncated": false}`))
+	}))
+	defer server.Close()
+	manager := &Manager{baseURL: server.URL, client: &http.Client{Timeout: 5 * time.Second}}
+
+	resolution, err := manager.ResolveRecipient("eng-leads", true)
+	if err != nil {
+		t.Fatalf("ResolveRecipient failed: %v", err)
+	}
+	if query.Get("expand") != "true" || query.Get("limit") != "500" {
+		t.Errorf("Unexpected query %v", query)
+	}
+	if !resolution.Resolved || resolution.Type != RecipientDistributionList || len(resolution.Members) != 2 || len(resolution.NestedLists) != 1 {
+		t.Errorf("Unexpected resolution: %+v", resolution)
+	}
+	text := formatRecipientResolution(resolution)
+	for _, want := range []string{
+		`Resolved "eng-leads" to Engineering Leads <eng-leads@example.com> (distribution list)`,
+		"2. Bo Chen <bo@example.com> (user)",
+		"- Platform Leads <platform-leads@example.com> (distribution list)",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Missing %q in:\n%s", want, text)
+		}
+	}
+
+	resolution, err = manager.ResolveRecipient("Chris", false)
+	if err != nil {
+		t.Fatalf("ResolveRecipient failed: %v", err)
+	}
+	if query.Get("expand") != "false" || resolution.Resolved {
+		t.Errorf("Unexpected resolution %+v for query %v", resolution, query)
+	}
+	if text := formatRecipientResolution(resolution); !strings.Contains(text, "try a fuller name or an email address") {
+		t.Errorf("Unexpected text: %s", text)
+	}
+
+	if _, err := manager.ResolveRecipient("  ", true); err == nil {
+		t.Error("Expected an error for an empty name")
+	}
+}
+
+func TestResolveRecipientGraph(t *testing.T) {
+	var search string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/me/people") {
+			t.Errorf("Unexpected request %s", r.URL.Path)
+		}
+		search = r.URL.Query().Get("$search")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"value": [
+			{"displayName": "Ana Silva", "scoredEmailAddresses": [{"address": "ana@example.com"}], "personType": {"class": "Person", "subclass": "OrganizationUser"}},
+			{"displayName": "Ana Lists", "scoredEmailAddresses": [{"address": "ana-lists@example.com"}], "personType": {"class": "Group", "subclass": "PublicDistributionList"}},
+			{"displayName": "Ana (no address)", "scoredEmailAddresses": [], "personType": {"class": "Person", "subclass": "PersonalContact"}}
+		]}`))
+	}))
+	defer server.Close()
+	graph := newTestGraphManager(server.URL)
+
+	// Several matches and none exact: candidates are returned
+	resolution, err := graph.ResolveRecipient("Ana", true)
+	if err != nil {
+		t.Fatalf("ResolveRecipient failed: %v", err)
+	}
+	if search != `"Ana"` {
+		t.Errorf("Unexpected search %q", search)
+	}
+	if resolution.Resolved || len(resolution.Candidates) != 2 || resolution.Candidates[1].Type != RecipientDistributionList {
+		t.Errorf("Unexpected resolution: %+v", resolution)
+	}
+	if text := formatRecipientResolution(resolution); !strings.Contains(text, `"Ana" is ambiguous; 2 possible recipients`) {
+		t.Errorf("Unexpected text: %s", text)
+	}
+
+	// An exact address match resolves; lists can't be expanded
+	resolution, err = graph.ResolveRecipient("ANA-LISTS@example.com", true)
+	if err != nil {
+		t.Fatalf("ResolveRecipient failed: %v", err)
+	}
+	if !resolution.Resolved || resolution.Address != "ana-lists@example.com" || resolution.Note == "" {
+		t.Errorf("Unexpected resolution: %+v", resolution)
+	}
+}
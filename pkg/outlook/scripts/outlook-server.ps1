@@ -7,24 +7,118 @@ if ($env:OUTLOOK_SERVER_PORT) {
     $Port = [int]$env:OUTLOOK_SERVER_PORT
 }
 
+# Every request must carry this bearer token; the Go manager generates a random one at startup
+$authToken = $env:OUTLOOK_SERVER_TOKEN
+if (-not $authToken) {
+    Write-Error "OUTLOOK_SERVER_TOKEN must be set; the server refuses to run unauthenticated"
+    exit 1
+}
+
+# Sending mail is opt-in; the Go manager sets this when started with --allow-send
+$allowSend = $env:OUTLOOK_ALLOW_SEND -eq "true"
+
+# Permanent deletion is opt-in; the Go manager sets this when started with --allow-permanent-delete
+$allowPermanentDelete = $env:OUTLOOK_ALLOW_PERMANENT_DELETE -eq "true"
+
+# Exports without a target path are returned inline as base64; larger ones must be written to disk
+$maxInlineExportBytes = 10MB
+
+# Attachment content returned inline as base64 (for previews) is capped the same way
+$maxInlineAttachmentBytes = 10MB
+
+# Nested distribution lists are expanded this many levels deep
+$maxListDepth = 5
+
 Write-Host "Starting Outlook REST API server on localhost:$Port"
 
-# Initialize Outlook COM object with error handling
+$serverStartedAt = Get-Date
+
+# GET /version reports this script's hash so the Go manager can tell a server started by another build;
+# it is taken now because the manager deletes its temporary copy of the script once the server is up
+$scriptSha256 = (Get-FileHash -Algorithm SHA256 -LiteralPath $PSCommandPath).Hash.ToLowerInvariant()
+
+# Outlook COM state; Connect-Outlook (re)binds it, e.g. after Outlook was closed and reopened
 $outlook = $null
+$namespace = $null
+$inbox = $null
 $outlookAvailable = $false
+$outlookError = $null
+$reconnectCount = 0
+$lastReconnectAt = $null
+$lastConnectAttemptAt = [DateTime]::MinValue
 
-try {
-    $outlook = New-Object -ComObject Outlook.Application
-    $namespace = $outlook.GetNamespace("MAPI")
-    $inbox = $namespace.GetDefaultFolder(6) # olFolderInbox = 6
-    $outlookAvailable = $true
+# Automatic reconnection is attempted at most this often while Outlook stays unreachable
+$reconnectInterval = [TimeSpan]::FromSeconds(5)
+
+# Helper function to bind (or re-bind) the Outlook COM objects; returns whether Outlook is reachable
+function Connect-Outlook {
+    $script:lastConnectAttemptAt = Get-Date
+    
+    # Release the stale handle so the new one does not attach to a dead Outlook process
+    if ($script:outlook) {
+        try { [void][System.Runtime.InteropServices.Marshal]::ReleaseComObject($script:outlook) } catch { }
+    }
+    $script:outlook = $null
+    $script:namespace = $null
+    $script:inbox = $null
+    $script:outlookAvailable = $false
+    
+    try {
+        $script:outlook = New-Object -ComObject Outlook.Application
+        $script:namespace = $script:outlook.GetNamespace("MAPI")
+        $script:inbox = $script:namespace.GetDefaultFolder(6) # olFolderInbox = 6
+        $script:outlookAvailable = $true
+        $script:outlookError = $null
+        return $true
+    } catch {
+        $script:outlookError = $_.Exception.Message
+        return $false
+    }
+}
+
+# Helper function to check that the COM handle still talks to a running Outlook
+function Test-OutlookConnection {
+    if (-not $outlookAvailable) {
+        return $false
+    }
+    try {
+        $null = $namespace.CurrentProfileName
+        return $true
+    } catch {
+        return $false
+    }
+}
+
+# Helper function to re-bind Outlook when the handle went stale; throttled so a closed
+# Outlook is not relaunched on every request
+function Restore-OutlookConnection {
+    if (Test-OutlookConnection) {
+        return $true
+    }
+    if (((Get-Date) - $lastConnectAttemptAt) -lt $reconnectInterval) {
+        return $false
+    }
+    
+    Write-Warning "Outlook connection lost, reconnecting..."
+    if (Connect-Outlook) {
+        $script:reconnectCount++
+        $script:lastReconnectAt = Get-Date
+        Write-Host "Reconnected to Outlook"
+        return $true
+    }
+    Write-Warning "Could not reconnect to Outlook: $outlookError"
+    return $false
+}
+
+if (Connect-Outlook) {
     Write-Host "Successfully connected to Outlook"
-} catch {
-    Write-Warning "Could not connect to Outlook: $($_.Exception.Message)"
-    Write-Host "Server will start but return errors for all requests"
+} else {
+    Write-Warning "Could not connect to Outlook: $outlookError"
+    Write-Host "Server will start but return errors until Outlook is reachable"
 }
 
-# HTTP Listener setup
+# HTTP Listener setup. HttpListener cannot register a 127.0.0.1 prefix without a URL ACL,
+# so it uses the localhost prefix and the request loop rejects anything not from loopback.
 $listener = New-Object System.Net.HttpListener
 $listener.Prefixes.Add("http://localhost:$Port/")
 $listener.Start()
@@ -48,11 +142,335 @@ function Convert-OutlookItemToObject {
         importance = $item.Importance
         hasAttachments = $item.Attachments.Count -gt 0
         attachmentCount = $item.Attachments.Count
+        flagStatus = Get-FlagStatusName $item.FlagStatus
+        categories = @(Split-Categories $item.Categories)
     }
     
     return $obj
 }
 
+# Helper function to map OlFlagStatus to the names used by the API
+function Get-FlagStatusName {
+    param([int]$flagStatus)
+    
+    switch ($flagStatus) {
+        1 { return "complete" } # olFlagComplete = 1
+        2 { return "flagged" }  # olFlagMarked = 2
+        default { return "none" } # olNoFlag = 0
+    }
+}
+
+# Helper function to split Outlook's delimited category string into a list
+function Split-Categories {
+    param([string]$categories)
+    
+    if (-not $categories) {
+        return @()
+    }
+    return @($categories -split '[,;]' | ForEach-Object { $_.Trim() } | Where-Object { $_ })
+}
+
+# Helper function to convert an Outlook attachment to a JSON-compatible object
+function Convert-AttachmentToObject {
+    param($attachment, [int]$index)
+    
+    # Inline attachments (e.g., signature images) carry a content ID
+    $inline = $false
+    try {
+        $contentId = $attachment.PropertyAccessor.GetProperty("http://schemas.microsoft.com/mapi/proptag/0x3712001F")
+        $inline = [bool]$contentId
+    } catch {
+        $inline = $false
+    }
+    
+    return @{
+        index = $index
+        fileName = $attachment.FileName
+        size = $attachment.Size
+        inline = $inline
+    }
+}
+
+# Helper function to pick a path in directory for fileName that doesn't overwrite an existing file
+function Get-UniqueAttachmentPath {
+    param([string]$directory, [string]$fileName)
+    
+    $safeName = ($fileName -replace '[\\/:*?"<>|]', '_').Trim()
+    if (-not $safeName) {
+        $safeName = "attachment"
+    }
+    
+    $baseName = [System.IO.Path]::GetFileNameWithoutExtension($safeName)
+    $extension = [System.IO.Path]::GetExtension($safeName)
+    $candidate = Join-Path $directory $safeName
+    $counter = 1
+    while (Test-Path -LiteralPath $candidate) {
+        $candidate = Join-Path $directory "$baseName ($counter)$extension"
+        $counter++
+    }
+    return $candidate
+}
+
+# Helper function to resolve an Outlook address entry to an SMTP address; Exchange
+# users are stored with an X.500 address that other mail tools cannot use
+function Get-SmtpAddress {
+    param($addressEntry, [string]$fallback)
+    
+    try {
+        if ($addressEntry -and $addressEntry.Type -eq "EX") {
+            if ($addressEntry.AddressEntryUserType -eq 1) { # olExchangeDistributionListAddressEntry
+                $list = $addressEntry.GetExchangeDistributionList()
+                if ($list -and $list.PrimarySmtpAddress) {
+                    return $list.PrimarySmtpAddress
+                }
+            }
+            $exchangeUser = $addressEntry.GetExchangeUser()
+            if ($exchangeUser -and $exchangeUser.PrimarySmtpAddress) {
+                return $exchangeUser.PrimarySmtpAddress
+            }
+        }
+        if ($addressEntry -and $addressEntry.Address) {
+            return $addressEntry.Address
+        }
+    } catch {
+        # Fall back to whatever address Outlook reported
+    }
+    return $fallback
+}
+
+# Helper function to name an AddressEntryUserType the way the API reports recipient types
+function Get-AddressEntryTypeName {
+    param([int]$userType)
+    
+    switch ($userType) {
+        0 { return "user" }               # olExchangeUserAddressEntry
+        5 { return "user" }               # olExchangeRemoteUserAddressEntry
+        1 { return "distribution_list" }  # olExchangeDistributionListAddressEntry
+        11 { return "distribution_list" } # olOutlookDistributionListAddressEntry
+        10 { return "contact" }           # olOutlookContactAddressEntry
+        30 { return "user" }              # olSmtpAddressEntry
+        default { return "other" }
+    }
+}
+
+# Helper function to collect the members of a distribution list into $state, expanding
+# nested lists up to $maxListDepth levels. Each person and list is listed once; the
+# walk stops at $state.limit members.
+function Expand-DistributionList {
+    param($addressEntry, $state, [int]$depth)
+    
+    try {
+        $members = if ($addressEntry.AddressEntryUserType -eq 1) {
+            $addressEntry.GetExchangeDistributionList().GetExchangeDistributionListMembers()
+        } else {
+            $addressEntry.Members
+        }
+    } catch {
+        [void]$state.errors.Add("Could not read the members of $($addressEntry.Name): $($_.Exception.Message)")
+        return
+    }
+    if (-not $members) {
+        return
+    }
+    
+    for ($i = 1; $i -le $members.Count; $i++) {
+        if ($state.members.Count -ge $state.limit) {
+            $state.truncated = $true
+            return
+        }
+        $member = $members.Item($i)
+        $entry = @{
+            name = $member.Name
+            address = Get-SmtpAddress $member $member.Address
+            type = Get-AddressEntryTypeName $member.AddressEntryUserType
+        }
+        $key = ([string]$entry.address).ToLower()
+        if (-not $state.seen.Add($key)) {
+            continue
+        }
+        if ($entry.type -ne "distribution_list") {
+            [void]$state.members.Add($entry)
+        } elseif ($depth -lt $maxListDepth) {
+            [void]$state.nestedLists.Add($entry)
+            Expand-DistributionList $member $state ($depth + 1)
+        } else {
+            [void]$state.nestedLists.Add($entry)
+            $state.truncated = $true
+        }
+    }
+}
+
+# Helper function to read a signature name from Outlook's MailSettings registry value,
+# which older versions store as a null-terminated UTF-16 byte array
+function Get-SignatureSetting {
+    param($settings, [string]$name)
+    
+    if (-not $settings) { return "" }
+    $value = $settings.$name
+    if ($value -is [byte[]]) {
+        $value = [System.Text.Encoding]::Unicode.GetString($value)
+    }
+    if ($value) { return ([string]$value).TrimEnd([char]0) }
+    return ""
+}
+
+# Helper function to list the signatures Outlook keeps in %APPDATA%\Microsoft\Signatures,
+# marking the defaults chosen for new messages and for replies
+function Get-Signatures {
+    $directory = Join-Path $env:APPDATA "Microsoft\Signatures"
+    if (-not (Test-Path $directory)) {
+        return @()
+    }
+    
+    # Each installed Office version has its own MailSettings key; the newest one wins
+    $settings = Get-ItemProperty -Path "HKCU:\Software\Microsoft\Office\*\Common\MailSettings" -ErrorAction SilentlyContinue |
+        Sort-Object { [double]($_.PSPath -replace '^.*\\Office\\([0-9.]+)\\.*$', '$1') } -Descending |
+        Select-Object -First 1
+    $defaultNew = Get-SignatureSetting $settings "NewSignature"
+    $defaultReply = Get-SignatureSetting $settings "ReplySignature"
+    
+    $signatures = @()
+    $names = Get-ChildItem -Path $directory -File -ErrorAction SilentlyContinue |
+        Where-Object { $_.Extension -in ".htm", ".txt" } |
+        ForEach-Object { $_.BaseName } | Sort-Object -Unique
+    foreach ($name in $names) {
+        $textPath = Join-Path $directory "$name.txt"
+        $htmlPath = Join-Path $directory "$name.htm"
+        $signatures += @{
+            name = $name
+            text = if (Test-Path $textPath) { [System.IO.File]::ReadAllText($textPath).Trim() } else { "" }
+            html = if (Test-Path $htmlPath) { [System.IO.File]::ReadAllText($htmlPath) } else { "" }
+            defaultNew = $name -eq $defaultNew
+            defaultReply = $name -eq $defaultReply
+        }
+    }
+    return $signatures
+}
+
+# Helper function to encode a header value as an RFC 2047 encoded-word unless it is plain ASCII
+function ConvertTo-MimeHeaderValue {
+    param([string]$value)
+    
+    if ($value -match '^[\x20-\x7E]*$') {
+        return $value
+    }
+    return "=?utf-8?B?" + [Convert]::ToBase64String([System.Text.Encoding]::UTF8.GetBytes($value)) + "?="
+}
+
+# Helper function to format a display name and address as an RFC 5322 mailbox
+function Format-MimeAddress {
+    param([string]$name, [string]$address)
+    
+    if (-not $address) {
+        return ConvertTo-MimeHeaderValue $name
+    }
+    if (-not $name -or $name -eq $address) {
+        return "<$address>"
+    }
+    
+    $encodedName = ConvertTo-MimeHeaderValue $name
+    if ($encodedName -eq $name) {
+        $encodedName = '"' + ($name -replace '(["\\])', '\$1') + '"'
+    }
+    return "$encodedName <$address>"
+}
+
+# Helper function to build an RFC 5322 (.eml) message from a mail item. Outlook has
+# no EML save format, so headers, bodies and attachments are assembled here.
+function New-EmlContent {
+    param($item)
+    
+    $crlf = "`r`n"
+    $builder = New-Object System.Text.StringBuilder
+    
+    $to = @()
+    $cc = @()
+    foreach ($recipient in $item.Recipients) {
+        $formatted = Format-MimeAddress $recipient.Name (Get-SmtpAddress $recipient.AddressEntry $recipient.Address)
+        switch ($recipient.Type) {
+            1 { $to += $formatted } # olTo = 1
+            2 { $cc += $formatted } # olCC = 2; Bcc recipients are not part of the message headers
+        }
+    }
+    
+    $sender = Format-MimeAddress $item.SenderName (Get-SmtpAddress $item.Sender $item.SenderEmailAddress)
+    [void]$builder.Append("From: $sender$crlf")
+    if ($to.Count -gt 0) {
+        [void]$builder.Append("To: " + ($to -join ",$crlf ") + $crlf)
+    }
+    if ($cc.Count -gt 0) {
+        [void]$builder.Append("Cc: " + ($cc -join ",$crlf ") + $crlf)
+    }
+    [void]$builder.Append("Subject: " + (ConvertTo-MimeHeaderValue $item.Subject) + $crlf)
+    
+    $date = if ($item.SentOn -and $item.SentOn.Year -lt 4000) { $item.SentOn } else { $item.ReceivedTime }
+    [void]$builder.Append("Date: " + $date.ToUniversalTime().ToString("ddd, dd MMM yyyy HH:mm:ss +0000", [System.Globalization.CultureInfo]::InvariantCulture) + $crlf)
+    try {
+        $messageIdHeader = $item.PropertyAccessor.GetProperty("http://schemas.microsoft.com/mapi/proptag/0x1035001F") # PR_INTERNET_MESSAGE_ID
+        if ($messageIdHeader) {
+            [void]$builder.Append("Message-ID: $messageIdHeader$crlf")
+        }
+    } catch {
+        # Drafts and some items have no Internet message ID
+    }
+    [void]$builder.Append("MIME-Version: 1.0$crlf")
+    
+    $mixedBoundary = "----=_outlook_mcp_mixed_" + [guid]::NewGuid().ToString("N")
+    $alternativeBoundary = "----=_outlook_mcp_alt_" + [guid]::NewGuid().ToString("N")
+    [void]$builder.Append("Content-Type: multipart/mixed; boundary=`"$mixedBoundary`"$crlf$crlf")
+    
+    [void]$builder.Append("--$mixedBoundary$crlf")
+    [void]$builder.Append("Content-Type: multipart/alternative; boundary=`"$alternativeBoundary`"$crlf$crlf")
+    $bodies = @(@{ type = "text/plain"; content = [string]$item.Body })
+    if ($item.BodyFormat -eq 2) { # olFormatHTML = 2
+        $bodies += @{ type = "text/html"; content = [string]$item.HTMLBody }
+    }
+    foreach ($body in $bodies) {
+        [void]$builder.Append("--$alternativeBoundary$crlf")
+        [void]$builder.Append("Content-Type: $($body.type); charset=utf-8$crlf")
+        [void]$builder.Append("Content-Transfer-Encoding: base64$crlf$crlf")
+        [void]$builder.Append([Convert]::ToBase64String([System.Text.Encoding]::UTF8.GetBytes($body.content), [Base64FormattingOptions]::InsertLineBreaks) + $crlf)
+    }
+    [void]$builder.Append("--$alternativeBoundary--$crlf")
+    
+    $tempDir = Join-Path ([System.IO.Path]::GetTempPath()) ("outlook-mcp-export-" + [guid]::NewGuid().ToString("N"))
+    New-Item -ItemType Directory -Path $tempDir | Out-Null
+    try {
+        for ($i = 1; $i -le $item.Attachments.Count; $i++) {
+            $attachment = $item.Attachments.Item($i)
+            $tempPath = Get-UniqueAttachmentPath $tempDir $attachment.FileName
+            try {
+                $attachment.SaveAsFile($tempPath)
+                $bytes = [System.IO.File]::ReadAllBytes($tempPath)
+            } catch {
+                # OLE objects and links cannot be saved as files; leave them out
+                continue
+            }
+            
+            $fileName = ConvertTo-MimeHeaderValue $attachment.FileName
+            # Attachments are sent as opaque bytes; mail clients infer the type from the file name
+            [void]$builder.Append("--$mixedBoundary$crlf")
+            [void]$builder.Append("Content-Type: application/octet-stream; name=`"$fileName`"$crlf")
+            [void]$builder.Append("Content-Disposition: attachment; filename=`"$fileName`"$crlf")
+            try {
+                $contentId = $attachment.PropertyAccessor.GetProperty("http://schemas.microsoft.com/mapi/proptag/0x3712001F")
+                if ($contentId) {
+                    [void]$builder.Append("Content-ID: <$contentId>$crlf")
+                }
+            } catch {
+                # Not an inline attachment
+            }
+            [void]$builder.Append("Content-Transfer-Encoding: base64$crlf$crlf")
+            [void]$builder.Append([Convert]::ToBase64String($bytes, [Base64FormattingOptions]::InsertLineBreaks) + $crlf)
+        }
+    } finally {
+        Remove-Item -LiteralPath $tempDir -Recurse -Force -ErrorAction SilentlyContinue
+    }
+    [void]$builder.Append("--$mixedBoundary--$crlf")
+    
+    return [System.Text.Encoding]::ASCII.GetBytes($builder.ToString())
+}
+
 # Helper function to get message body text (cooked)
 function Get-MessageBodyText {
     param($item)
@@ -77,158 +495,2130 @@ function Get-MessageBodyText {
     return ""
 }
 
-# Main request processing loop
-try {
-    while ($listener.IsListening) {
-        $context = $listener.GetContext()
-        $request = $context.Request
-        $response = $context.Response
-        
-        Write-Host "$(Get-Date -Format 'yyyy-MM-dd HH:mm:ss') - $($request.HttpMethod) $($request.Url.PathAndQuery)"
-        
-        # Set CORS headers for localhost
-        $response.Headers.Add("Access-Control-Allow-Origin", "http://localhost:*")
-        $response.Headers.Add("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-        $response.Headers.Add("Access-Control-Allow-Headers", "Content-Type")
-        $response.ContentType = "application/json"
-        
-        $responseObj = $null
-        $statusCode = 200
-        
-        try {
-            if (-not $outlookAvailable) {
-                $responseObj = @{
-                    error = "Outlook is not available. Please ensure Outlook is installed and running."
-                    code = "OUTLOOK_UNAVAILABLE"
+# Helper function to build a short single-line preview of a message body
+function Get-BodyPreview {
+    param($item, [int]$maxChars = 300)
+    
+    $flat = ((Get-MessageBodyText $item) -replace '\s+', ' ').Trim()
+    if ($flat.Length -le $maxChars) {
+        return $flat
+    }
+    return $flat.Substring(0, $maxChars).TrimEnd() + "..."
+}
+
+# Helper function to collect every item of a conversation, walking the tree from its roots
+function Get-ConversationItems {
+    param($conversation)
+    
+    $pending = New-Object System.Collections.Queue
+    foreach ($root in $conversation.GetRootItems()) {
+        $pending.Enqueue($root)
+    }
+    
+    $items = @()
+    while ($pending.Count -gt 0) {
+        $current = $pending.Dequeue()
+        $items += $current
+        foreach ($child in $conversation.GetChildren($current)) {
+            $pending.Enqueue($child)
+        }
+    }
+    return $items
+}
+
+# Well-known folder names mapped to OlDefaultFolders constants
+$defaultFolderIds = @{
+    "inbox" = 6
+    "sent" = 5
+    "sent items" = 5
+    "drafts" = 16
+    "deleted" = 3
+    "deleted items" = 3
+    "outbox" = 4
+    "junk" = 23
+    "junk email" = 23
+}
+
+# Helper function to extract the first match of a query from text with surrounding words
+function Get-MatchSnippet {
+    param([string]$text, [string]$searchQuery, [int]$contextChars = 60)
+    
+    if (-not $text -or -not $searchQuery) {
+        return $null
+    }
+    
+    $flat = ($text -replace '\s+', ' ').Trim()
+    $index = $flat.IndexOf($searchQuery, [System.StringComparison]::OrdinalIgnoreCase)
+    if ($index -lt 0) {
+        return $null
+    }
+    
+    $start = [Math]::Max(0, $index - $contextChars)
+    $end = [Math]::Min($flat.Length, $index + $searchQuery.Length + $contextChars)
+    
+    # Widen to whole words so the snippet does not start or end mid-word
+    while ($start -gt 0 -and $flat[$start - 1] -ne ' ') { $start-- }
+    while ($end -lt $flat.Length -and $flat[$end] -ne ' ') { $end++ }
+    
+    $snippet = $flat.Substring($start, $end - $start).Trim()
+    if ($start -gt 0) { $snippet = "..." + $snippet }
+    if ($end -lt $flat.Length) { $snippet = $snippet + "..." }
+    return $snippet
+}
+
+# Helper function to quote a value for a DASL LIKE '%value%' condition
+function Get-DaslLikeValue {
+    param([string]$value)
+    
+    return "'%" + $value.Replace("'", "''") + "%'"
+}
+
+# Helper function to translate search parameters into a DASL filter for Items.Restrict.
+# Returns $null when a parameter is invalid; conditions are combined with AND.
+function Build-SearchFilter {
+    param($params)
+    
+    $conditions = @()
+    
+    if ($params["q"]) {
+        $like = Get-DaslLikeValue $params["q"]
+        $conditions += "(""urn:schemas:httpmail:subject"" LIKE $like OR ""urn:schemas:httpmail:textdescription"" LIKE $like OR ""urn:schemas:httpmail:fromname"" LIKE $like)"
+    }
+    if ($params["from"]) {
+        $like = Get-DaslLikeValue $params["from"]
+        $conditions += "(""urn:schemas:httpmail:fromname"" LIKE $like OR ""urn:schemas:httpmail:fromemail"" LIKE $like)"
+    }
+    if ($params["to"]) {
+        $like = Get-DaslLikeValue $params["to"]
+        $conditions += "(""urn:schemas:httpmail:displayto"" LIKE $like OR ""urn:schemas:httpmail:displaycc"" LIKE $like)"
+    }
+    
+    # DASL date comparisons are made in UTC
+    foreach ($bound in @(@{ name = "after"; op = ">=" }, @{ name = "before"; op = "<" })) {
+        if ($params[$bound.name]) {
+            try {
+                $date = ([DateTimeOffset]::Parse($params[$bound.name])).UtcDateTime
+            } catch {
+                return $null
+            }
+            $conditions += """urn:schemas:httpmail:datereceived"" $($bound.op) '" + $date.ToString("yyyy-MM-dd HH:mm") + "'"
+        }
+    }
+    
+    if ($params["has_attachments"]) {
+        $value = if ($params["has_attachments"] -eq "true") { 1 } else { 0 }
+        $conditions += """urn:schemas:httpmail:hasattachment"" = $value"
+    }
+    if ($params["unread"]) {
+        $value = if ($params["unread"] -eq "true") { 0 } else { 1 }
+        $conditions += """urn:schemas:httpmail:read"" = $value"
+    }
+    if ($params["importance"]) {
+        $levels = @{ "low" = 0; "normal" = 1; "high" = 2 }
+        if (-not $levels.ContainsKey($params["importance"])) {
+            return $null
+        }
+        $conditions += """urn:schemas:httpmail:importance"" = $($levels[$params["importance"]])"
+    }
+    if ($params["category"]) {
+        # Equality matches a message with the category among others
+        $conditions += """urn:schemas-microsoft-com:office:office#Keywords"" = '" + $params["category"].Replace("'", "''") + "'"
+    }
+    
+    return "@SQL=" + ($conditions -join " AND ")
+}
+
+# Helper function to resolve an account parameter (store display name, account name or
+# SMTP address) to a store in the profile; returns $null when it is not in the profile
+function Resolve-OutlookStore {
+    param([string]$accountParam)
+    
+    if (-not $accountParam) {
+        return $namespace.DefaultStore
+    }
+    
+    foreach ($account in $namespace.Accounts) {
+        if ($account.SmtpAddress -ieq $accountParam -or $account.DisplayName -ieq $accountParam) {
+            return $account.DeliveryStore
+        }
+    }
+    foreach ($store in $namespace.Stores) {
+        if ($store.DisplayName -ieq $accountParam) {
+            return $store
+        }
+    }
+    
+    return $null
+}
+
+# Helper function to walk a folder path such as "Archive" or "Inbox/Projects" below a root folder
+function Find-FolderByPath {
+    param($root, [string]$folderPath)
+    
+    $current = $root
+    foreach ($part in ($folderPath -split '[/\\]' | Where-Object { $_ })) {
+        $next = $null
+        foreach ($child in $current.Folders) {
+            if ($child.Name -ieq $part) {
+                $next = $child
+                break
+            }
+        }
+        if (-not $next) {
+            return $null
+        }
+        $current = $next
+    }
+    
+    return $current
+}
+
+# Helper function to resolve a folder parameter (well-known name, EntryID or path) to a folder,
+# optionally within another account's store or a shared mailbox that is not in the profile
+function Resolve-OutlookFolder {
+    param([string]$folderParam, [string]$accountParam)
+
+    $key = if ($folderParam) { $folderParam.ToLower() } else { "inbox" }
+
+    if (-not $accountParam) {
+        if (-not $folderParam) {
+            return $inbox
+        }
+        if ($defaultFolderIds.ContainsKey($key)) {
+            return $namespace.GetDefaultFolder($defaultFolderIds[$key])
+        }
+    } else {
+        $store = Resolve-OutlookStore $accountParam
+        if (-not $store) {
+            # Shared mailboxes outside the profile only expose their well-known folders
+            if (-not $defaultFolderIds.ContainsKey($key)) {
+                return $null
+            }
+            try {
+                $recipient = $namespace.CreateRecipient($accountParam)
+                if (-not $recipient.Resolve()) {
+                    return $null
                 }
-                $statusCode = 503
-            } else {
-                $path = $request.Url.AbsolutePath
-                $query = $request.Url.Query
-                
-                switch -Regex ($path) {
-                    "^/messages$" {
-                        # GET /messages - list inbox messages with pagination
-                        $pageParam = [System.Web.HttpUtility]::ParseQueryString($query)["page"]
-                        $page = if ($pageParam) { [int]$pageParam } else { 1 }
-                        $pageSize = 10
-                        $skip = ($page - 1) * $pageSize
-                        
-                        $totalCount = $inbox.Items.Count
-                        $items = $inbox.Items | Sort-Object ReceivedTime -Descending | Select-Object -Skip $skip -First $pageSize
-                        
-                        $messages = @()
-                        foreach ($item in $items) {
-                            if ($item.Class -eq 43) { # olMail = 43
-                                $messages += Convert-OutlookItemToObject $item
-                            }
-                        }
-                        
-                        $responseObj = @{
-                            messages = $messages
-                            pagination = @{
-                                page = $page
-                                pageSize = $pageSize
-                                total = $totalCount
-                                hasNext = ($skip + $pageSize) -lt $totalCount
-                                hasPrevious = $page -gt 1
-                            }
-                        }
-                    }
-                    
-                    "^/messages/([^/]+)$" {
-                        # GET /messages/{id} - full message details
-                        $messageId = $matches[1]
-                        
-                        try {
-                            $item = $namespace.GetItemFromID($messageId)
-                            if ($item.Class -eq 43) { # olMail = 43
-                                $messageObj = Convert-OutlookItemToObject $item
-                                $messageObj.bodyPreview = (Get-MessageBodyText $item).Substring(0, [Math]::Min(200, (Get-MessageBodyText $item).Length))
-                                $responseObj = $messageObj
-                            } else {
-                                $responseObj = @{ error = "Item is not a mail message"; code = "NOT_MAIL_ITEM" }
-                                $statusCode = 400
-                            }
-                        } catch {
-                            $responseObj = @{ error = "Message not found"; code = "MESSAGE_NOT_FOUND" }
-                            $statusCode = 404
-                        }
-                    }
-                    
-                    "^/messages/([^/]+)/body/raw$" {
+                return $namespace.GetSharedDefaultFolder($recipient, $defaultFolderIds[$key])
+            } catch {
+                return $null
+            }
+        }
+        if ($defaultFolderIds.ContainsKey($key)) {
+            try {
+                return $store.GetDefaultFolder($defaultFolderIds[$key])
+            } catch {
+                # Stores such as PST archives have no default folders; fall through to path resolution
+            }
+        }
+    }
+
+    # Try the parameter as a folder EntryID
+    try {
+        $folder = if ($accountParam) { $namespace.GetFolderFromID($folderParam, $store.StoreID) } else { $namespace.GetFolderFromID($folderParam) }
+        if ($folder) {
+            return $folder
+        }
+    } catch {
+        # Not an EntryID, fall through to path resolution
+    }
+
+    # Resolve as a path relative to the store root
+    $root = if ($accountParam) { $store.GetRootFolder() } else { $inbox.Parent }
+    return Find-FolderByPath $root $folderParam
+}
+
+# Helper function to build the error returned when a folder cannot be resolved
+function Get-FolderNotFoundError {
+    param([string]$folderParam, [string]$accountParam)
+    
+    $name = if ($folderParam) { $folderParam } else { "inbox" }
+    if ($accountParam) {
+        return @{ error = "Folder not found: $name in account $accountParam"; code = "FOLDER_NOT_FOUND" }
+    }
+    return @{ error = "Folder not found: $name"; code = "FOLDER_NOT_FOUND" }
+}
+
+# OlExchangeStoreType constants mapped to the names used by the API
+function Get-StoreTypeName {
+    param([int]$storeType)
+    
+    switch ($storeType) {
+        0 { return "primary" }          # olPrimaryExchangeMailbox = 0
+        1 { return "delegate" }         # olExchangeMailbox = 1
+        2 { return "public_folders" }   # olExchangePublicFolder = 2
+        4 { return "additional" }       # olAdditionalExchangeMailbox = 4
+        default { return "local" }      # olNotExchange = 3 (PST, IMAP, POP3)
+    }
+}
+
+# OlAccountType constants mapped to the names used by the API
+function Get-AccountTypeName {
+    param([int]$accountType)
+    
+    switch ($accountType) {
+        0 { return "exchange" }  # olExchange = 0
+        1 { return "imap" }      # olImap = 1
+        2 { return "pop3" }      # olPop3 = 2
+        3 { return "http" }      # olHttp = 3
+        4 { return "eas" }       # olEas = 4
+        default { return "other" }
+    }
+}
+
+# Helper function to convert a folder to a JSON-compatible object
+function Convert-FolderToObject {
+    param($folder, [int]$depth)
+    
+    return @{
+        id = $folder.EntryID
+        name = $folder.Name
+        path = $folder.FolderPath
+        itemCount = $folder.Items.Count
+        unreadCount = $folder.UnReadItemCount
+        depth = $depth
+    }
+}
+
+# Helper function to flatten the mail folder hierarchy below a folder
+function Get-FolderTree {
+    param($folder, [int]$depth)
+
+    $result = @()
+    foreach ($child in $folder.Folders) {
+        if ($child.DefaultItemType -eq 0) { # olMailItem = 0
+            $result += Convert-FolderToObject $child $depth
+        }
+        $result += Get-FolderTree $child ($depth + 1)
+    }
+
+    return $result
+}
+
+# Helper function to format an Outlook date as ISO 8601 with the local UTC offset
+function Format-OutlookDate {
+    param([datetime]$date)
+    
+    return ([DateTimeOffset]$date).ToString("yyyy-MM-ddTHH:mm:sszzz")
+}
+
+# Helper function to map OlBusyStatus to the names used by the API
+function Get-BusyStatusName {
+    param([int]$busyStatus)
+    
+    switch ($busyStatus) {
+        0 { return "free" }             # olFree = 0
+        1 { return "tentative" }        # olTentative = 1
+        3 { return "out_of_office" }    # olOutOfOffice = 3
+        4 { return "working_elsewhere" } # olWorkingElsewhere = 4
+        default { return "busy" }       # olBusy = 2
+    }
+}
+
+# Helper function to split Outlook's semicolon-separated attendee string into a list
+function Split-Attendees {
+    param([string]$attendees)
+    
+    if (-not $attendees) {
+        return @()
+    }
+    return @($attendees -split ';' | ForEach-Object { $_.Trim() } | Where-Object { $_ })
+}
+
+# Helper function to convert an Outlook appointment to a JSON-compatible summary object
+function Convert-AppointmentToObject {
+    param($item)
+    
+    return @{
+        id = $item.EntryID
+        subject = $item.Subject
+        start = Format-OutlookDate $item.Start
+        end = Format-OutlookDate $item.End
+        location = $item.Location
+        organizer = $item.Organizer
+        allDay = $item.AllDayEvent
+        isRecurring = $item.IsRecurring
+        busyStatus = Get-BusyStatusName $item.BusyStatus
+        categories = @(Split-Categories $item.Categories)
+    }
+}
+
+# Helper function to format a task date as YYYY-MM-DD; Outlook uses 4501-01-01 for "none"
+function Format-TaskDate {
+    param($date)
+    
+    if (-not $date -or $date.Year -ge 4000) {
+        return $null
+    }
+    return $date.ToString("yyyy-MM-dd")
+}
+
+# Helper function to map OlImportance to the names used by the API
+function Get-ImportanceName {
+    param([int]$importance)
+    
+    switch ($importance) {
+        0 { return "low" }  # olImportanceLow = 0
+        2 { return "high" } # olImportanceHigh = 2
+        default { return "normal" } # olImportanceNormal = 1
+    }
+}
+
+# Helper function to map OlTaskStatus to the names used by the API
+function Get-TaskStatusName {
+    param([int]$status)
+    
+    switch ($status) {
+        1 { return "in_progress" } # olTaskInProgress = 1
+        2 { return "completed" }   # olTaskComplete = 2
+        3 { return "waiting" }     # olTaskWaiting = 3
+        4 { return "deferred" }    # olTaskDeferred = 4
+        default { return "not_started" } # olTaskNotStarted = 0
+    }
+}
+
+# Helper function to convert a task or an item flagged for follow-up to a JSON-compatible object
+function Convert-TaskToObject {
+    param($item)
+    
+    if ($item.Class -eq 48) { # olTask = 48
+        return @{
+            id = $item.EntryID
+            subject = $item.Subject
+            kind = "task"
+            status = Get-TaskStatusName $item.Status
+            complete = $item.Complete
+            dueDate = Format-TaskDate $item.DueDate
+            startDate = Format-TaskDate $item.StartDate
+            completedDate = Format-TaskDate $item.DateCompleted
+            importance = Get-ImportanceName $item.Importance
+            categories = @(Split-Categories $item.Categories)
+        }
+    }
+    
+    # Flagged messages carry their task state in the Task* properties
+    $complete = $item.FlagStatus -eq 1 # olFlagComplete = 1
+    return @{
+        id = $item.EntryID
+        subject = if ($item.TaskSubject) { $item.TaskSubject } else { $item.Subject }
+        kind = "message"
+        status = if ($complete) { "completed" } else { "not_started" }
+        complete = $complete
+        dueDate = Format-TaskDate $item.TaskDueDate
+        startDate = Format-TaskDate $item.TaskStartDate
+        completedDate = Format-TaskDate $item.TaskCompletedDate
+        importance = Get-ImportanceName $item.Importance
+        categories = @(Split-Categories $item.Categories)
+        sender = $item.SenderName
+    }
+}
+
+# Helper function to parse an optional YYYY-MM-DD date from a request body
+function ConvertFrom-TaskDate {
+    param([string]$value)
+    
+    if (-not $value) {
+        return $null
+    }
+    return [DateTime]::ParseExact($value, "yyyy-MM-dd", [System.Globalization.CultureInfo]::InvariantCulture)
+}
+
+# Helper function to convert an Outlook contact to a JSON-compatible summary object
+function Convert-ContactToObject {
+    param($item)
+    
+    $phones = @{}
+    if ($item.BusinessTelephoneNumber) { $phones.business = $item.BusinessTelephoneNumber }
+    if ($item.MobileTelephoneNumber) { $phones.mobile = $item.MobileTelephoneNumber }
+    if ($item.HomeTelephoneNumber) { $phones.home = $item.HomeTelephoneNumber }
+    
+    return @{
+        id = $item.EntryID
+        name = $item.FullName
+        emails = @(@($item.Email1Address, $item.Email2Address, $item.Email3Address) | Where-Object { $_ })
+        phones = $phones
+        company = $item.CompanyName
+        department = $item.Department
+        jobTitle = $item.JobTitle
+    }
+}
+
+# Helper function to test whether a contact matches a free-text query
+function Test-ContactMatch {
+    param($item, [string]$searchQuery)
+    
+    if (-not $searchQuery) {
+        return $true
+    }
+    
+    $pattern = "*$([System.Management.Automation.WildcardPattern]::Escape($searchQuery))*"
+    foreach ($value in @($item.FullName, $item.Email1Address, $item.Email2Address, $item.Email3Address, $item.CompanyName, $item.Department, $item.JobTitle)) {
+        if ($value -and $value -like $pattern) {
+            return $true
+        }
+    }
+    return $false
+}
+
+# OlCategoryColor constants mapped to the names used by the API
+$categoryColorNames = @(
+    "none", "red", "orange", "peach", "yellow", "green", "teal", "olive", "blue", "purple", "maroon",
+    "steel", "dark steel", "gray", "dark gray", "black", "dark red", "dark orange", "dark peach",
+    "dark yellow", "dark green", "dark teal", "dark olive", "dark blue", "dark purple", "dark maroon"
+)
+
+# Rule condition and action properties described by Convert-RuleToObject
+$ruleConditionNames = @("From", "SenderAddress", "SentTo", "Subject", "Body", "BodyOrSubject", "MessageHeader",
+    "Category", "HasAttachment", "Importance", "ToMe", "ToOrCc", "OnlyToMe", "CcMe", "NotTo", "MeetingInviteOrUpdate")
+$ruleActionNames = @("MoveToFolder", "CopyToFolder", "AssignToCategory", "ClearCategories", "Delete", "DeletePermanently",
+    "MarkAsTask", "Forward", "ForwardAsAttachment", "Redirect", "PlaySound", "DesktopAlert", "NotifyDelivery", "NotifyRead", "Stop")
+
+# Helper function to describe an enabled rule condition or action, including its operand where it has one
+function Get-RuleClauseDescription {
+    param([string]$name, $clause)
+    
+    if ($clause.PSObject.Properties["Text"] -and $clause.Text) {
+        return "$name contains: " + (@($clause.Text) -join ", ")
+    }
+    if ($clause.PSObject.Properties["Address"] -and $clause.Address) {
+        return "$name contains: " + (@($clause.Address) -join ", ")
+    }
+    if ($clause.PSObject.Properties["Categories"] -and $clause.Categories) {
+        return "${name}: " + (@($clause.Categories) -join ", ")
+    }
+    if ($clause.PSObject.Properties["Folder"] -and $clause.Folder) {
+        return "${name}: " + $clause.Folder.FolderPath
+    }
+    if ($clause.PSObject.Properties["Recipients"] -and $clause.Recipients.Count -gt 0) {
+        $names = @()
+        foreach ($recipient in $clause.Recipients) { $names += $recipient.Name }
+        return "${name}: " + ($names -join ", ")
+    }
+    return $name
+}
+
+# Helper function to convert an Outlook rule to a JSON-compatible object
+function Convert-RuleToObject {
+    param($rule)
+    
+    $conditions = @()
+    foreach ($name in $ruleConditionNames) {
+        $condition = $rule.Conditions.$name
+        if ($condition -and $condition.Enabled) {
+            $conditions += Get-RuleClauseDescription $name $condition
+        }
+    }
+    
+    $actions = @()
+    foreach ($name in $ruleActionNames) {
+        $action = $rule.Actions.$name
+        if ($action -and $action.Enabled) {
+            $actions += Get-RuleClauseDescription $name $action
+        }
+    }
+    
+    return @{
+        name = $rule.Name
+        enabled = $rule.Enabled
+        executionOrder = $rule.ExecutionOrder
+        type = if ($rule.RuleType -eq 1) { "send" } else { "receive" } # olRuleSend = 1
+        isLocal = $rule.IsLocalRule
+        conditions = $conditions
+        actions = $actions
+    }
+}
+
+# OlExchangeConnectionMode constants mapped to the names used by the API
+function Get-ConnectionModeName {
+    param([int]$mode)
+    
+    switch ($mode) {
+        0 { return "none" }                             # olNoExchange = 0
+        { $_ -in 100, 200 } { return "offline" }        # olOffline, olCachedOffline
+        { $_ -in 300, 400 } { return "disconnected" }   # olDisconnected, olCachedDisconnected
+        { $_ -in 500, 600, 700 } { return "cached" }    # olCachedConnectedHeaders/Drizzle/Full
+        800 { return "online" }                         # olOnline = 800
+        default { return "unknown" }
+    }
+}
+
+# Helper function to report the server's own health, including whether the Outlook COM connection still responds
+function Get-ServerHealth {
+    $health = @{
+        pid = $PID
+        startedAt = Format-OutlookDate $serverStartedAt
+        powershellVersion = $PSVersionTable.PSVersion.ToString()
+        outlookConnected = $false
+        reconnects = $reconnectCount
+    }
+    if ($lastReconnectAt) {
+        $health.lastReconnectAt = Format-OutlookDate $lastReconnectAt
+    }
+    
+    if (-not $outlookAvailable) {
+        $health.outlookError = if ($outlookError) { "Could not connect to Outlook: $outlookError" } else { "Could not connect to Outlook" }
+        return $health
+    }
+    
+    try {
+        $health.outlookVersion = $outlook.Version
+        $health.profile = $namespace.CurrentProfileName
+        $health.connectionMode = Get-ConnectionModeName $namespace.ExchangeConnectionMode
+        $health.outlookConnected = $true
+    } catch {
+        $health.outlookError = $_.Exception.Message
+    }
+    
+    return $health
+}
+
+# Helper function to read a JSON request body
+function Read-RequestJson {
+    param($request)
+    
+    $reader = New-Object System.IO.StreamReader($request.InputStream, $request.ContentEncoding)
+    try {
+        $raw = $reader.ReadToEnd()
+    } finally {
+        $reader.Close()
+    }
+    
+    if (-not $raw) {
+        return $null
+    }
+    return $raw | ConvertFrom-Json
+}
+
+# Helper function to join a recipient list into Outlook's semicolon-separated form
+function Join-Recipients {
+    param($recipients)
+    
+    if (-not $recipients) {
+        return ""
+    }
+    return (@($recipients) | Where-Object { $_ }) -join "; "
+}
+
+# Helper function to put user-supplied text above the quoted content of a reply or forward
+function Add-BodyPrefix {
+    param($mailItem, [string]$text)
+    
+    if (-not $text) {
+        return
+    }
+    
+    if ($mailItem.BodyFormat -eq 2) { # olFormatHTML = 2
+        $encoded = [System.Web.HttpUtility]::HtmlEncode($text) -replace "`r?`n", "<br>"
+        $mailItem.HTMLBody = "<div>$encoded</div><br>" + $mailItem.HTMLBody
+    } else {
+        $mailItem.Body = $text + "`r`n`r`n" + $mailItem.Body
+    }
+}
+
+# Helper function to reject write requests that are not allowed; returns an error object or $null
+function Test-SendRequest {
+    param($request)
+    
+    if ($request.HttpMethod -ne "POST") {
+        return @{ status = 405; body = @{ error = "Method not allowed"; code = "METHOD_NOT_ALLOWED" } }
+    }
+    if (-not $allowSend) {
+        return @{ status = 403; body = @{ error = "Sending mail is disabled. Start outlook-mcp with --allow-send to enable it."; code = "SEND_DISABLED" } }
+    }
+    return $null
+}
+
+# Helper function to compare the request's bearer token in constant time
+function Test-RequestAuthorized {
+    param($request)
+    
+    $expected = "Bearer $authToken"
+    $actual = $request.Headers["Authorization"]
+    if (-not $actual -or $actual.Length -ne $expected.Length) {
+        return $false
+    }
+    
+    $diff = 0
+    for ($i = 0; $i -lt $expected.Length; $i++) {
+        $diff = $diff -bor ([int]$expected[$i] -bxor [int]$actual[$i])
+    }
+    return $diff -eq 0
+}
+
+# Main request processing loop
+try {
+    while ($listener.IsListening) {
+        $context = $listener.GetContext()
+        $request = $context.Request
+        $response = $context.Response
+        
+        Write-Host "$(Get-Date -Format 'yyyy-MM-dd HH:mm:ss') - $($request.HttpMethod) $($request.Url.PathAndQuery)"
+        
+        # No CORS headers: only the Go manager talks to this server, never a browser
+        $response.ContentType = "application/json"
+        
+        $responseObj = $null
+        $statusCode = 200
+        
+        try {
+            if (-not [System.Net.IPAddress]::IsLoopback($request.RemoteEndPoint.Address)) {
+                $responseObj = @{ error = "Only loopback connections are accepted"; code = "FORBIDDEN" }
+                $statusCode = 403
+            } elseif (-not (Test-RequestAuthorized $request)) {
+                $responseObj = @{ error = "Missing or invalid bearer token"; code = "UNAUTHORIZED" }
+                $statusCode = 401
+            } elseif ($request.Url.AbsolutePath -eq "/health") {
+                # GET /health - server and Outlook connection status; answered even when Outlook is unavailable
+                $responseObj = Get-ServerHealth
+            } elseif ($request.Url.AbsolutePath -eq "/version") {
+                # GET /version - the SHA-256 of the running script; answered even when Outlook is unavailable
+                $responseObj = @{ scriptSha256 = $scriptSha256; pid = $PID; startedAt = Format-OutlookDate $serverStartedAt }
+            } elseif ($request.Url.AbsolutePath -eq "/reconnect") {
+                # POST /reconnect - drop the COM handle and bind to Outlook again without restarting the listener
+                if ($request.HttpMethod -ne "POST") {
+                    $responseObj = @{ error = "Method not allowed"; code = "METHOD_NOT_ALLOWED" }
+                    $statusCode = 405
+                } elseif (Connect-Outlook) {
+                    $reconnectCount++
+                    $lastReconnectAt = Get-Date
+                    Write-Host "Reconnected to Outlook on request"
+                    $responseObj = Get-ServerHealth
+                } else {
+                    $responseObj = @{ error = "Could not connect to Outlook: $outlookError"; code = "OUTLOOK_UNAVAILABLE" }
+                    $statusCode = 503
+                }
+            } elseif (-not (Restore-OutlookConnection)) {
+                $responseObj = @{
+                    error = "Outlook is not available. Please ensure Outlook is installed and running."
+                    code = "OUTLOOK_UNAVAILABLE"
+                }
+                $statusCode = 503
+            } else {
+                $path = $request.Url.AbsolutePath
+                $query = $request.Url.Query
+                
+                switch -Regex ($path) {
+                    "^/accounts$" {
+                        # GET /accounts - accounts configured in the profile and the stores (mailboxes, archives) they expose
+                        $defaultStoreId = $namespace.DefaultStore.StoreID
+                        
+                        $accounts = @()
+                        foreach ($account in $namespace.Accounts) {
+                            $accounts += @{
+                                name = $account.DisplayName
+                                smtpAddress = $account.SmtpAddress
+                                type = Get-AccountTypeName $account.AccountType
+                                storeName = if ($account.DeliveryStore) { $account.DeliveryStore.DisplayName } else { "" }
+                                isDefault = $account.DeliveryStore -and $account.DeliveryStore.StoreID -eq $defaultStoreId
+                            }
+                        }
+                        
+                        $stores = @()
+                        foreach ($store in $namespace.Stores) {
+                            $stores += @{
+                                name = $store.DisplayName
+                                type = Get-StoreTypeName $store.ExchangeStoreType
+                                filePath = $store.FilePath
+                                isDefault = $store.StoreID -eq $defaultStoreId
+                            }
+                        }
+                        
+                        $responseObj = @{
+                            accounts = $accounts
+                            stores = $stores
+                        }
+                    }
+                    
+                    "^/folders$" {
+                        # GET /folders?account=X - list mail folders in the default store or another account's store
+                        $params = [System.Web.HttpUtility]::ParseQueryString($query)
+                        $accountParam = $params["account"]
+                        
+                        if (-not $accountParam) {
+                            $folders = @(Get-FolderTree $inbox.Parent 0)
+                        } else {
+                            $store = Resolve-OutlookStore $accountParam
+                            if ($store) {
+                                $folders = @(Get-FolderTree $store.GetRootFolder() 0)
+                            } else {
+                                # A shared mailbox outside the profile: only its Inbox subtree is reachable
+                                $sharedInbox = Resolve-OutlookFolder "" $accountParam
+                                if (-not $sharedInbox) {
+                                    $responseObj = @{ error = "Account not found: $accountParam"; code = "ACCOUNT_NOT_FOUND" }
+                                    $statusCode = 404
+                                    break
+                                }
+                                $folders = @(Convert-FolderToObject $sharedInbox 0) + @(Get-FolderTree $sharedInbox 1)
+                            }
+                        }
+                        
+                        $responseObj = @{
+                            account = $accountParam
+                            folders = $folders
+                            count = $folders.Count
+                        }
+                    }
+                    
+                    "^/messages$" {
+                        # GET /messages?page=N&folder=X&account=X&since=X&unread=B&importance=X&category=X&has_attachments=B -
+                        # list folder messages with pagination (default: Inbox), optionally only those received after an
+                        # ISO 8601 timestamp and matching the filters
+                        $params = [System.Web.HttpUtility]::ParseQueryString($query)
+                        $pageParam = $params["page"]
+                        $page = if ($pageParam) { [int]$pageParam } else { 1 }
+                        $pageSize = 10
+                        $skip = ($page - 1) * $pageSize
+                        
+                        $folder = Resolve-OutlookFolder $params["folder"] $params["account"]
+                        if (-not $folder) {
+                            $responseObj = Get-FolderNotFoundError $params["folder"] $params["account"]
+                            $statusCode = 404
+                            break
+                        }
+                        
+                        $since = $null
+                        if ($params["since"]) {
+                            try {
+                                $since = ([DateTimeOffset]::Parse($params["since"])).LocalDateTime
+                            } catch {
+                                $responseObj = @{ error = "Query parameter 'since' must be an ISO 8601 date"; code = "INVALID_DATE" }
+                                $statusCode = 400
+                                break
+                            }
+                        }
+                        
+                        # Only the filter conditions apply; Build-SearchFilter ignores the other parameters
+                        $filter = Build-SearchFilter @{
+                            unread = $params["unread"]
+                            importance = $params["importance"]
+                            category = $params["category"]
+                            has_attachments = $params["has_attachments"]
+                        }
+                        if (-not $filter) {
+                            $responseObj = @{ error = "Invalid filter: importance must be one of low, normal, high"; code = "INVALID_FILTER" }
+                            $statusCode = 400
+                            break
+                        }
+                        
+                        $sourceItems = $folder.Items
+                        if ($filter -ne "@SQL=") {
+                            $sourceItems = $folder.Items.Restrict($filter)
+                        }
+                        if ($since) {
+                            # Restrict dates have minute precision, so narrow with it and compare exactly afterwards
+                            $filter = "[ReceivedTime] >= '" + $since.ToString("g") + "'"
+                            $sourceItems = @($sourceItems.Restrict($filter) | Where-Object { $_.ReceivedTime -gt $since })
+                        }
+                        
+                        $totalCount = $sourceItems.Count
+                        $items = $sourceItems | Sort-Object ReceivedTime -Descending | Select-Object -Skip $skip -First $pageSize
+                        
+                        $messages = @()
+                        foreach ($item in $items) {
+                            if ($item.Class -eq 43) { # olMail = 43
+                                $messages += Convert-OutlookItemToObject $item
+                            }
+                        }
+                        
+                        $responseObj = @{
+                            folder = $folder.Name
+                            since = if ($since) { Format-OutlookDate $since } else { $null }
+                            messages = $messages
+                            pagination = @{
+                                page = $page
+                                pageSize = $pageSize
+                                total = $totalCount
+                                hasNext = ($skip + $pageSize) -lt $totalCount
+                                hasPrevious = $page -gt 1
+                            }
+                        }
+                    }
+                    
+                    "^/messages/([^/]+)$" {
+                        # GET /messages/{id} - full message details
+                        # PATCH /messages/{id} - update read state, flag and categories
+                        # DELETE /messages/{id}?permanent=true - move to Deleted Items, or delete permanently
+                        $messageId = $matches[1]
+                        
+                        if ($request.HttpMethod -eq "DELETE") {
+                            $params = [System.Web.HttpUtility]::ParseQueryString($query)
+                            $permanent = $params["permanent"] -eq "true"
+                            
+                            if ($permanent -and -not $allowPermanentDelete) {
+                                $responseObj = @{ error = "Permanent deletion is disabled. Start outlook-mcp with --allow-permanent-delete to enable it."; code = "PERMANENT_DELETE_DISABLED" }
+                                $statusCode = 403
+                                break
+                            }
+                            
+                            try {
+                                $item = $namespace.GetItemFromID($messageId)
+                            } catch {
+                                $responseObj = @{ error = "Message not found"; code = "MESSAGE_NOT_FOUND" }
+                                $statusCode = 404
+                                break
+                            }
+                            
+                            # The item's own store, so messages in shared or secondary mailboxes stay in them
+                            $deletedItems = $item.Parent.Store.GetDefaultFolder(3) # olFolderDeletedItems = 3
+                            $inDeletedItems = $item.Parent.EntryID -eq $deletedItems.EntryID
+                            if ($permanent) {
+                                # Deleting from Deleted Items removes the item for good
+                                if (-not $inDeletedItems) {
+                                    $item = $item.Move($deletedItems)
+                                }
+                                $item.Delete()
+                                $folderName = $null
+                            } elseif ($inDeletedItems) {
+                                # Item.Delete() would purge it, so a soft delete has nothing left to do
+                                if (-not $allowPermanentDelete) {
+                                    $responseObj = @{ error = "The message is already in Deleted Items; deleting it again would remove it permanently, which is disabled. Start outlook-mcp with --allow-permanent-delete to enable it."; code = "PERMANENT_DELETE_DISABLED" }
+                                    $statusCode = 403
+                                } else {
+                                    $responseObj = @{ error = "The message is already in Deleted Items; pass permanent=true to remove it for good"; code = "ALREADY_DELETED" }
+                                    $statusCode = 409
+                                }
+                                break
+                            } else {
+                                [void]$item.Move($deletedItems)
+                                $folderName = $deletedItems.Name
+                            }
+                            
+                            $responseObj = @{
+                                id = $messageId
+                                permanent = $permanent
+                                folder = $folderName
+                            }
+                            break
+                        }
+                        
+                        if ($request.HttpMethod -eq "PATCH") {
+                            $payload = Read-RequestJson $request
+                            if (-not $payload) {
+                                $responseObj = @{ error = "Request body is required"; code = "MISSING_BODY" }
+                                $statusCode = 400
+                                break
+                            }
+                            
+                            try {
+                                $item = $namespace.GetItemFromID($messageId)
+                            } catch {
+                                $responseObj = @{ error = "Message not found"; code = "MESSAGE_NOT_FOUND" }
+                                $statusCode = 404
+                                break
+                            }
+                            
+                            if ($item.Class -ne 43) { # olMail = 43
+                                $responseObj = @{ error = "Item is not a mail message"; code = "NOT_MAIL_ITEM" }
+                                $statusCode = 400
+                                break
+                            }
+                            
+                            if ($null -ne $payload.unread) {
+                                $item.UnRead = [bool]$payload.unread
+                            }
+                            
+                            if ($payload.flag) {
+                                switch ($payload.flag) {
+                                    "flagged" { $item.MarkAsTask(4) } # olMarkNoDate = 4
+                                    "complete" { $item.FlagStatus = 1 } # olFlagComplete = 1
+                                    "none" { $item.ClearTaskFlag() }
+                                    default {
+                                        $responseObj = @{ error = "Invalid flag value: $($payload.flag)"; code = "INVALID_FLAG" }
+                                        $statusCode = 400
+                                    }
+                                }
+                                if ($statusCode -ne 200) {
+                                    break
+                                }
+                            }
+                            
+                            if ($null -ne $payload.categories) {
+                                $item.Categories = (@($payload.categories) | Where-Object { $_ }) -join ", "
+                            }
+                            
+                            $item.Save()
+                            
+                            $responseObj = @{
+                                id = $messageId
+                                unread = $item.UnRead
+                                flagStatus = Get-FlagStatusName $item.FlagStatus
+                                categories = @(Split-Categories $item.Categories)
+                            }
+                            break
+                        }
+                        
+                        try {
+                            $item = $namespace.GetItemFromID($messageId)
+                            if ($item.Class -eq 43) { # olMail = 43
+                                $messageObj = Convert-OutlookItemToObject $item
+                                $messageObj.bodyPreview = (Get-MessageBodyText $item).Substring(0, [Math]::Min(200, (Get-MessageBodyText $item).Length))
+                                $responseObj = $messageObj
+                            } else {
+                                $responseObj = @{ error = "Item is not a mail message"; code = "NOT_MAIL_ITEM" }
+                                $statusCode = 400
+                            }
+                        } catch {
+                            $responseObj = @{ error = "Message not found"; code = "MESSAGE_NOT_FOUND" }
+                            $statusCode = 404
+                        }
+                    }
+                    
+                    "^/messages/([^/]+)/body/raw$" {
                         # GET /messages/{id}/body/raw - raw message body
                         $messageId = $matches[1]
                         
                         try {
-                            $item = $namespace.GetItemFromID($messageId)
-                            if ($item.Class -eq 43) { # olMail = 43
-                                $responseObj = @{
-                                    id = $messageId
-                                    bodyText = $item.Body
-                                    bodyHtml = $item.HTMLBody
-                                    format = if ($item.BodyFormat -eq 2) { "HTML" } elseif ($item.BodyFormat -eq 3) { "RichText" } else { "PlainText" }
+                            $item = $namespace.GetItemFromID($messageId)
+                            if ($item.Class -eq 43) { # olMail = 43
+                                $responseObj = @{
+                                    id = $messageId
+                                    bodyText = $item.Body
+                                    bodyHtml = $item.HTMLBody
+                                    format = if ($item.BodyFormat -eq 2) { "HTML" } elseif ($item.BodyFormat -eq 3) { "RichText" } else { "PlainText" }
+                                }
+                            } else {
+                                $responseObj = @{ error = "Item is not a mail message"; code = "NOT_MAIL_ITEM" }
+                                $statusCode = 400
+                            }
+                        } catch {
+                            $responseObj = @{ error = "Message not found"; code = "MESSAGE_NOT_FOUND" }
+                            $statusCode = 404
+                        }
+                    }
+                    
+                    "^/messages/([^/]+)/body$" {
+                        # GET /messages/{id}/body - cooked message body (readable text)
+                        $messageId = $matches[1]
+                        
+                        try {
+                            $item = $namespace.GetItemFromID($messageId)
+                            if ($item.Class -eq 43) { # olMail = 43
+                                $bodyText = Get-MessageBodyText $item
+                                $responseObj = @{
+                                    id = $messageId
+                                    bodyText = $bodyText
+                                    wordCount = ($bodyText -split '\s+').Count
+                                    charCount = $bodyText.Length
+                                }
+                            } else {
+                                $responseObj = @{ error = "Item is not a mail message"; code = "NOT_MAIL_ITEM" }
+                                $statusCode = 400
+                            }
+                        } catch {
+                            $responseObj = @{ error = "Message not found"; code = "MESSAGE_NOT_FOUND" }
+                            $statusCode = 404
+                        }
+                    }
+                    
+                    "^/search$" {
+                        # GET /search?q={query}&folder=X&account=X&from=X&to=X&after=X&before=X&has_attachments=B&unread=B&importance=X&page=N&page_size=N
+                        # - search within a folder (default: Inbox) by free text and/or structured filters, newest first, with pagination
+                        $params = [System.Web.HttpUtility]::ParseQueryString($query)
+                        $searchQuery = $params["q"]
+                        $pageParam = $params["page"]
+                        $page = if ($pageParam) { [int]$pageParam } else { 1 }
+                        $pageSizeParam = $params["page_size"]
+                        $pageSize = if ($pageSizeParam) { [Math]::Min([int]$pageSizeParam, 100) } else { 25 }
+                        $skip = ($page - 1) * $pageSize
+                        $folder = Resolve-OutlookFolder $params["folder"] $params["account"]
+                        $filterNames = @("q", "from", "to", "after", "before", "has_attachments", "unread", "importance")
+                        
+                        if (-not ($filterNames | Where-Object { $params[$_] })) {
+                            $responseObj = @{ error = "Query parameter 'q' or at least one filter is required"; code = "MISSING_QUERY" }
+                            $statusCode = 400
+                            break
+                        }
+                        if (-not $folder) {
+                            $responseObj = Get-FolderNotFoundError $params["folder"] $params["account"]
+                            $statusCode = 404
+                            break
+                        }
+                        
+                        $filter = Build-SearchFilter $params
+                        if (-not $filter) {
+                            $responseObj = @{ error = "Invalid search filter: dates must be ISO 8601 and importance one of low, normal, high"; code = "INVALID_FILTER" }
+                            $statusCode = 400
+                            break
+                        }
+                        
+                        $searchResults = $folder.Items.Restrict($filter)
+                        $searchResults.Sort("[ReceivedTime]", $true)
+                        $totalCount = $searchResults.Count
+                        
+                        # Only the requested page is converted; reading bodies for snippets is the expensive part
+                        $messages = @()
+                        $lastIndex = [Math]::Min($skip + $pageSize, $totalCount)
+                        for ($i = $skip + 1; $i -le $lastIndex; $i++) {
+                            $item = $searchResults.Item($i)
+                            if ($item.Class -eq 43) { # olMail = 43
+                                $messageObj = Convert-OutlookItemToObject $item
+                                $snippet = Get-MatchSnippet (Get-MessageBodyText $item) $searchQuery
+                                if ($snippet) {
+                                    $messageObj.snippet = $snippet
+                                }
+                                $messages += $messageObj
+                            }
+                        }
+                        
+                        # Pages are in received time order; the Go client applies relevance ordering within a page
+                        $responseObj = @{
+                            query = $searchQuery
+                            folder = $folder.Name
+                            results = $messages
+                            count = $messages.Count
+                            pagination = @{
+                                page = $page
+                                pageSize = $pageSize
+                                total = $totalCount
+                                hasNext = ($skip + $pageSize) -lt $totalCount
+                                hasPrevious = $page -gt 1
+                            }
+                        }
+                    }
+                    
+                    "^/stats$" {
+                        # GET /stats?folder=X&account=X&after=X&before=Y&largest=N&limit=N - counts over the messages a folder
+                        # received in [after, before), newest first up to limit: per sender, per local day, unread and the largest
+                        $params = [System.Web.HttpUtility]::ParseQueryString($query)
+                        $folder = Resolve-OutlookFolder $params["folder"] $params["account"]
+                        
+                        if (-not $params["after"] -or -not $params["before"]) {
+                            $responseObj = @{ error = "Query parameters 'after' and 'before' are required"; code = "INVALID_DATE_RANGE" }
+                            $statusCode = 400
+                            break
+                        }
+                        if (-not $folder) {
+                            $responseObj = Get-FolderNotFoundError $params["folder"] $params["account"]
+                            $statusCode = 404
+                            break
+                        }
+                        
+                        # Only the date conditions apply; Build-SearchFilter ignores the other parameters
+                        $filter = Build-SearchFilter @{ after = $params["after"]; before = $params["before"] }
+                        if (-not $filter) {
+                            $responseObj = @{ error = "Query parameters 'after' and 'before' must be ISO 8601 dates"; code = "INVALID_DATE_RANGE" }
+                            $statusCode = 400
+                            break
+                        }
+                        $largestCount = if ($params["largest"]) { [int]$params["largest"] } else { 10 }
+                        $limit = if ($params["limit"]) { [int]$params["limit"] } else { 10000 }
+                        
+                        $items = $folder.Items.Restrict($filter)
+                        $items.Sort("[ReceivedTime]", $true)
+                        $itemCount = $items.Count
+                        
+                        $senders = @{}
+                        $days = @{}
+                        $sizes = New-Object System.Collections.ArrayList
+                        $exchangeAddresses = @{}
+                        $counted = 0
+                        $unread = 0
+                        $totalSize = [long]0
+                        $oldestUnread = $null
+                        for ($i = 1; $i -le $itemCount -and $counted -lt $limit; $i++) {
+                            $item = $items.Item($i)
+                            if ($item.Class -ne 43) { # olMail = 43
+                                continue
+                            }
+                            $counted++
+                            
+                            # Exchange senders have X.500 addresses; resolving them is slow, so each is resolved once
+                            $address = [string]$item.SenderEmailAddress
+                            if ($item.SenderEmailType -eq "EX") {
+                                if (-not $exchangeAddresses.ContainsKey($address)) {
+                                    $exchangeAddresses[$address] = Get-SmtpAddress $item.Sender $address
+                                }
+                                $address = $exchangeAddresses[$address]
+                            }
+                            $key = if ($address) { $address.ToLower() } else { ([string]$item.SenderName).ToLower() }
+                            if (-not $senders.ContainsKey($key)) {
+                                $senders[$key] = @{ name = $item.SenderName; address = $key; count = 0; unread = 0; size = [long]0 }
+                            }
+                            $day = $item.ReceivedTime.ToString("yyyy-MM-dd")
+                            if (-not $days.ContainsKey($day)) {
+                                $days[$day] = @{ date = $day; count = 0; unread = 0 }
+                            }
+                            
+                            # Indexers, since a "count" key would be hidden by the hashtable's Count property
+                            $senders[$key]["count"] += 1
+                            $senders[$key]["size"] += $item.Size
+                            $days[$day]["count"] += 1
+                            $totalSize += $item.Size
+                            if ($item.UnRead) {
+                                $unread++
+                                $senders[$key]["unread"] += 1
+                                $days[$day]["unread"] += 1
+                                if (-not $oldestUnread -or $item.ReceivedTime -lt $oldestUnread) {
+                                    $oldestUnread = $item.ReceivedTime
+                                }
+                            }
+                            [void]$sizes.Add([PSCustomObject]@{ size = $item.Size; id = $item.EntryID })
+                        }
+                        
+                        $largest = @()
+                        foreach ($entry in ($sizes | Sort-Object -Property size -Descending | Select-Object -First $largestCount)) {
+                            $largest += Convert-OutlookItemToObject $namespace.GetItemFromID($entry.id)
+                        }
+                        
+                        $responseObj = @{
+                            folder = $folder.Name
+                            messages = $counted
+                            unread = $unread
+                            totalSize = $totalSize
+                            truncated = $counted -lt $itemCount -and $counted -ge $limit
+                            senders = @($senders.Values)
+                            days = @($days.Values | Sort-Object { $_["date"] })
+                            oldestUnread = if ($oldestUnread) { Format-OutlookDate $oldestUnread } else { $null }
+                            largest = $largest
+                        }
+                    }
+                    
+                    "^/oof$" {
+                        # GET /oof?account=X - automatic reply (out-of-office) state of the default or another store
+                        $params = [System.Web.HttpUtility]::ParseQueryString($query)
+                        $accountParam = $params["account"]
+                        
+                        $store = Resolve-OutlookStore $accountParam
+                        if (-not $store) {
+                            $responseObj = @{ error = "Account not found: $accountParam"; code = "ACCOUNT_NOT_FOUND" }
+                            $statusCode = 404
+                            break
+                        }
+                        
+                        # The object model only exposes the on/off state; reply text and schedule live in
+                        # Exchange mailbox settings that are reachable through EWS or Microsoft Graph only
+                        try {
+                            $enabled = [bool]$store.PropertyAccessor.GetProperty("http://schemas.microsoft.com/mapi/proptag/0x661D000B") # PR_OOF_STATE
+                        } catch {
+                            $responseObj = @{ error = "Automatic replies are not available for store '$($store.DisplayName)'; they require an Exchange mailbox"; code = "OOF_UNAVAILABLE" }
+                            $statusCode = 501
+                            break
+                        }
+                        
+                        $responseObj = @{
+                            store = $store.DisplayName
+                            enabled = $enabled
+                        }
+                    }
+                    
+                    "^/profile$" {
+                        # GET /profile - the current user's identity and the signatures saved for this Windows user
+                        $currentUser = $namespace.CurrentUser
+                        $fallbackAddress = ""
+                        foreach ($account in $namespace.Accounts) {
+                            if ($account.DeliveryStore -and $account.DeliveryStore.StoreID -eq $namespace.DefaultStore.StoreID) {
+                                $fallbackAddress = $account.SmtpAddress
+                                break
+                            }
+                        }
+                        
+                        $responseObj = @{
+                            displayName = $currentUser.Name
+                            smtpAddress = Get-SmtpAddress $currentUser.AddressEntry $fallbackAddress
+                            profile = $namespace.CurrentProfileName
+                            signatures = @(Get-Signatures)
+                        }
+                    }
+                    
+                    "^/freebusy$" {
+                        # GET /freebusy?attendees=a;b&start=X&end=Y&interval=N - free/busy slots per attendee.
+                        # Each character of an attendee's slots covers interval minutes from the returned start:
+                        # 0 free, 1 tentative, 2 busy, 3 out of office, 4 working elsewhere
+                        $params = [System.Web.HttpUtility]::ParseQueryString($query)
+                        
+                        $attendees = @(([string]$params["attendees"]) -split '[;,]' | ForEach-Object { $_.Trim() } | Where-Object { $_ })
+                        if ($attendees.Count -eq 0) {
+                            $responseObj = @{ error = "Query parameter 'attendees' is required"; code = "MISSING_ATTENDEES" }
+                            $statusCode = 400
+                            break
+                        }
+                        
+                        try {
+                            $rangeStart = ([DateTimeOffset]::Parse($params["start"])).LocalDateTime
+                            $rangeEnd = ([DateTimeOffset]::Parse($params["end"])).LocalDateTime
+                        } catch {
+                            $responseObj = @{ error = "Query parameters 'start' and 'end' must be ISO 8601 dates"; code = "INVALID_DATE_RANGE" }
+                            $statusCode = 400
+                            break
+                        }
+                        if ($rangeEnd -le $rangeStart) {
+                            $responseObj = @{ error = "'end' must be after 'start'"; code = "INVALID_DATE_RANGE" }
+                            $statusCode = 400
+                            break
+                        }
+                        
+                        $interval = if ($params["interval"]) { [int]$params["interval"] } else { 30 }
+                        if ($interval -lt 5 -or $interval -gt 1440) {
+                            $responseObj = @{ error = "'interval' must be between 5 and 1440 minutes"; code = "INVALID_INTERVAL" }
+                            $statusCode = 400
+                            break
+                        }
+                        
+                        # Recipient.FreeBusy always starts at midnight of the given day, so skip the
+                        # slots before the requested start and cut off those after the end
+                        $dayStart = $rangeStart.Date
+                        $skip = [int][Math]::Floor(($rangeStart - $dayStart).TotalMinutes / $interval)
+                        $slotCount = [int][Math]::Ceiling(($rangeEnd - $dayStart).TotalMinutes / $interval) - $skip
+                        $slotStart = $dayStart.AddMinutes($skip * $interval)
+                        
+                        $results = @()
+                        foreach ($attendee in $attendees) {
+                            $recipient = $namespace.CreateRecipient($attendee)
+                            [void]$recipient.Resolve()
+                            if (-not $recipient.Resolved) {
+                                $results += @{ attendee = $attendee; resolved = $false; error = "Could not resolve attendee" }
+                                continue
+                            }
+                            
+                            $result = @{
+                                attendee = $attendee
+                                resolved = $true
+                                name = $recipient.Name
+                                email = Get-SmtpAddress $recipient.AddressEntry $recipient.Address
+                            }
+                            try {
+                                $freeBusy = $recipient.FreeBusy($dayStart, $interval, $true)
+                                if ($freeBusy.Length -gt $skip) {
+                                    $result.slots = $freeBusy.Substring($skip, [Math]::Min($slotCount, $freeBusy.Length - $skip))
+                                } else {
+                                    $result.slots = ""
+                                }
+                            } catch {
+                                $result.error = "Free/busy information is not available: $($_.Exception.Message)"
+                            }
+                            $results += $result
+                        }
+                        
+                        $responseObj = @{
+                            start = Format-OutlookDate $slotStart
+                            end = Format-OutlookDate $rangeEnd
+                            interval = $interval
+                            attendees = $results
+                        }
+                    }
+                    
+                    "^/calendar/events$" {
+                        # GET /calendar/events?start=X&end=Y&limit=N - events overlapping a date range, with recurring series expanded
+                        $params = [System.Web.HttpUtility]::ParseQueryString($query)
+                        
+                        try {
+                            $rangeStart = ([DateTimeOffset]::Parse($params["start"])).LocalDateTime
+                            $rangeEnd = ([DateTimeOffset]::Parse($params["end"])).LocalDateTime
+                        } catch {
+                            $responseObj = @{ error = "Query parameters 'start' and 'end' must be ISO 8601 dates"; code = "INVALID_DATE_RANGE" }
+                            $statusCode = 400
+                            break
+                        }
+                        
+                        if ($rangeEnd -le $rangeStart) {
+                            $responseObj = @{ error = "'end' must be after 'start'"; code = "INVALID_DATE_RANGE" }
+                            $statusCode = 400
+                            break
+                        }
+                        
+                        $limitParam = $params["limit"]
+                        $limit = if ($limitParam) { [int]$limitParam } else { 100 }
+                        
+                        $calendar = $namespace.GetDefaultFolder(9) # olFolderCalendar = 9
+                        $items = $calendar.Items
+                        # Recurrence expansion requires sorting by start before restricting,
+                        # and the expanded collection must be enumerated rather than counted
+                        $items.Sort("[Start]")
+                        $items.IncludeRecurrences = $true
+                        $filter = "[Start] < '" + $rangeEnd.ToString("g") + "' AND [End] > '" + $rangeStart.ToString("g") + "'"
+                        $matching = $items.Restrict($filter)
+                        
+                        $events = @()
+                        $truncated = $false
+                        foreach ($item in $matching) {
+                            if ($item.Class -ne 26) { # olAppointment = 26
+                                continue
+                            }
+                            if ($events.Count -ge $limit) {
+                                $truncated = $true
+                                break
+                            }
+                            $events += Convert-AppointmentToObject $item
+                        }
+                        
+                        $responseObj = @{
+                            start = Format-OutlookDate $rangeStart
+                            end = Format-OutlookDate $rangeEnd
+                            events = $events
+                            count = $events.Count
+                            truncated = $truncated
+                        }
+                    }
+                    
+                    "^/calendar/events/([^/]+)$" {
+                        # GET /calendar/events/{id} - full details of an event (series details for recurring events)
+                        $eventId = $matches[1]
+                        
+                        try {
+                            $item = $namespace.GetItemFromID($eventId)
+                        } catch {
+                            $responseObj = @{ error = "Event not found"; code = "EVENT_NOT_FOUND" }
+                            $statusCode = 404
+                            break
+                        }
+                        
+                        if ($item.Class -ne 26) { # olAppointment = 26
+                            $responseObj = @{ error = "Item is not a calendar event"; code = "NOT_CALENDAR_ITEM" }
+                            $statusCode = 400
+                            break
+                        }
+                        
+                        $eventObj = Convert-AppointmentToObject $item
+                        $eventObj.body = if ($item.Body) { $item.Body.Trim() } else { "" }
+                        $eventObj.requiredAttendees = @(Split-Attendees $item.RequiredAttendees)
+                        $eventObj.optionalAttendees = @(Split-Attendees $item.OptionalAttendees)
+                        $eventObj.isMeeting = $item.MeetingStatus -ne 0 # olNonMeeting = 0
+                        $eventObj.reminderMinutes = if ($item.ReminderSet) { $item.ReminderMinutesBeforeStart } else { $null }
+                        
+                        if ($item.IsRecurring) {
+                            $pattern = $item.GetRecurrencePattern()
+                            $eventObj.recurrence = @{
+                                type = switch ($pattern.RecurrenceType) {
+                                    0 { "daily" }    # olRecursDaily = 0
+                                    1 { "weekly" }   # olRecursWeekly = 1
+                                    2 { "monthly" }  # olRecursMonthly = 2
+                                    3 { "monthly" }  # olRecursMonthNth = 3
+                                    default { "yearly" } # olRecursYearly = 5, olRecursYearNth = 6
+                                }
+                                interval = $pattern.Interval
+                                patternStart = Format-OutlookDate $pattern.PatternStartDate
+                                patternEnd = if ($pattern.NoEndDate) { $null } else { Format-OutlookDate $pattern.PatternEndDate }
+                            }
+                        }
+                        
+                        $responseObj = $eventObj
+                    }
+                    
+                    "^/rules$" {
+                        # GET /rules - rules configured for the default store, in execution order
+                        try {
+                            $rules = $namespace.DefaultStore.GetRules()
+                        } catch {
+                            $responseObj = @{ error = "Rules are not available for this mailbox: $($_.Exception.Message)"; code = "RULES_UNAVAILABLE" }
+                            $statusCode = 503
+                            break
+                        }
+                        
+                        $ruleObjects = @()
+                        foreach ($rule in $rules) {
+                            $ruleObjects += Convert-RuleToObject $rule
+                        }
+                        $ruleObjects = @($ruleObjects | Sort-Object { $_.executionOrder })
+                        
+                        $responseObj = @{
+                            rules = $ruleObjects
+                            count = $ruleObjects.Count
+                        }
+                    }
+                    
+                    "^/categories$" {
+                        # GET /categories - the master category list
+                        $categories = @()
+                        foreach ($category in $namespace.Categories) {
+                            $color = [int]$category.Color
+                            $categories += @{
+                                name = $category.Name
+                                color = if ($color -ge 0 -and $color -lt $categoryColorNames.Count) { $categoryColorNames[$color] } else { "none" }
+                                shortcutKey = if ($category.ShortcutKey -gt 0) { "Ctrl+F$($category.ShortcutKey)" } else { "" } # olCategoryShortcutKeyCtrlF2 = 2
+                            }
+                        }
+                        
+                        $responseObj = @{
+                            categories = $categories
+                            count = $categories.Count
+                        }
+                    }
+                    
+                    "^/recipients/resolve$" {
+                        # GET /recipients/resolve?name=X&expand=B&limit=N - resolve a name or address against the
+                        # address books; with expand, also list a distribution list's members, nested lists expanded
+                        $params = [System.Web.HttpUtility]::ParseQueryString($query)
+                        $name = $params["name"]
+                        
+                        if (-not $name) {
+                            $responseObj = @{ error = "Query parameter 'name' is required"; code = "MISSING_NAME" }
+                            $statusCode = 400
+                            break
+                        }
+                        
+                        # Resolve fails both for unknown and for ambiguous names
+                        $recipient = $namespace.CreateRecipient($name)
+                        [void]$recipient.Resolve()
+                        if (-not $recipient.Resolved) {
+                            $responseObj = @{ query = $name; resolved = $false }
+                            break
+                        }
+                        
+                        $entry = $recipient.AddressEntry
+                        $responseObj = @{
+                            query = $name
+                            resolved = $true
+                            name = $entry.Name
+                            address = Get-SmtpAddress $entry $recipient.Address
+                            type = Get-AddressEntryTypeName $entry.AddressEntryUserType
+                        }
+                        
+                        if ($responseObj.type -eq "distribution_list" -and $params["expand"] -eq "true") {
+                            $state = @{
+                                members = New-Object System.Collections.ArrayList
+                                nestedLists = New-Object System.Collections.ArrayList
+                                errors = New-Object System.Collections.ArrayList
+                                seen = New-Object 'System.Collections.Generic.HashSet[string]'
+                                limit = if ($params["limit"]) { [int]$params["limit"] } else { 500 }
+                                truncated = $false
+                            }
+                            [void]$state.seen.Add(([string]$responseObj.address).ToLower())
+                            Expand-DistributionList $entry $state 1
+                            
+                            $responseObj.members = @($state.members)
+                            $responseObj.nestedLists = @($state.nestedLists)
+                            $responseObj.membersTruncated = $state.truncated
+                            if ($state.errors.Count -gt 0) {
+                                $responseObj.note = $state.errors -join "; "
+                            }
+                        }
+                    }
+                    
+                    "^/contacts$" {
+                        # GET /contacts?q=X&limit=N - contacts whose name, email, company, department or job title match
+                        $params = [System.Web.HttpUtility]::ParseQueryString($query)
+                        $searchQuery = $params["q"]
+                        $limitParam = $params["limit"]
+                        $limit = if ($limitParam) { [int]$limitParam } else { 25 }
+                        
+                        $contactsFolder = $namespace.GetDefaultFolder(10) # olFolderContacts = 10
+                        $items = $contactsFolder.Items
+                        $items.Sort("[FullName]")
+                        
+                        $contacts = @()
+                        $truncated = $false
+                        foreach ($item in $items) {
+                            if ($item.Class -ne 40) { # olContact = 40 (skips distribution lists)
+                                continue
+                            }
+                            if (-not (Test-ContactMatch $item $searchQuery)) {
+                                continue
+                            }
+                            if ($contacts.Count -ge $limit) {
+                                $truncated = $true
+                                break
+                            }
+                            $contacts += Convert-ContactToObject $item
+                        }
+                        
+                        $responseObj = @{
+                            query = $searchQuery
+                            contacts = $contacts
+                            count = $contacts.Count
+                            truncated = $truncated
+                        }
+                    }
+                    
+                    "^/contacts/([^/]+)$" {
+                        # GET /contacts/{id} - full details of a contact
+                        $contactId = $matches[1]
+                        
+                        try {
+                            $item = $namespace.GetItemFromID($contactId)
+                        } catch {
+                            $responseObj = @{ error = "Contact not found"; code = "CONTACT_NOT_FOUND" }
+                            $statusCode = 404
+                            break
+                        }
+                        
+                        if ($item.Class -ne 40) { # olContact = 40
+                            $responseObj = @{ error = "Item is not a contact"; code = "NOT_CONTACT_ITEM" }
+                            $statusCode = 400
+                            break
+                        }
+                        
+                        $contactObj = Convert-ContactToObject $item
+                        $contactObj.businessAddress = $item.BusinessAddress
+                        $contactObj.homeAddress = $item.HomeAddress
+                        $contactObj.notes = if ($item.Body) { $item.Body.Trim() } else { "" }
+                        $contactObj.categories = @(Split-Categories $item.Categories)
+                        
+                        $responseObj = $contactObj
+                    }
+                    
+                    "^/tasks$" {
+                        # GET /tasks?status=open|completed|all&limit=N - tasks and flagged items from the To-Do List
+                        # POST /tasks - create a task, or flag a message for follow-up when messageId is set
+                        # (body: {subject, body?, dueDate?, startDate?, importance?, categories?, messageId?})
+                        if ($request.HttpMethod -eq "POST") {
+                            $payload = Read-RequestJson $request
+                            if (-not $payload -or (-not $payload.subject -and -not $payload.messageId)) {
+                                $responseObj = @{ error = "A 'subject' or 'messageId' is required"; code = "MISSING_SUBJECT" }
+                                $statusCode = 400
+                                break
+                            }
+                            
+                            try {
+                                $dueDate = ConvertFrom-TaskDate $payload.dueDate
+                                $startDate = ConvertFrom-TaskDate $payload.startDate
+                            } catch {
+                                $responseObj = @{ error = "Dates must be YYYY-MM-DD"; code = "INVALID_DATE" }
+                                $statusCode = 400
+                                break
+                            }
+                            
+                            $levels = @{ "low" = 0; "normal" = 1; "high" = 2 }
+                            if ($payload.importance -and -not $levels.ContainsKey([string]$payload.importance)) {
+                                $responseObj = @{ error = "Importance must be one of low, normal, high"; code = "INVALID_IMPORTANCE" }
+                                $statusCode = 400
+                                break
+                            }
+                            
+                            if ($payload.messageId) {
+                                try {
+                                    $item = $namespace.GetItemFromID($payload.messageId)
+                                } catch {
+                                    $responseObj = @{ error = "Message not found"; code = "MESSAGE_NOT_FOUND" }
+                                    $statusCode = 404
+                                    break
+                                }
+                                
+                                $item.MarkAsTask(4) # olMarkNoDate = 4
+                                if ($payload.subject) {
+                                    $item.TaskSubject = [string]$payload.subject
+                                }
+                                if ($startDate) {
+                                    $item.TaskStartDate = $startDate
+                                }
+                                if ($dueDate) {
+                                    $item.TaskDueDate = $dueDate
                                 }
                             } else {
-                                $responseObj = @{ error = "Item is not a mail message"; code = "NOT_MAIL_ITEM" }
-                                $statusCode = 400
+                                $item = $outlook.CreateItem(3) # olTaskItem = 3
+                                $item.Subject = [string]$payload.subject
+                                $item.Body = [string]$payload.body
+                                if ($startDate) {
+                                    $item.StartDate = $startDate
+                                }
+                                if ($dueDate) {
+                                    $item.DueDate = $dueDate
+                                }
+                            }
+                            if ($payload.importance) {
+                                $item.Importance = $levels[[string]$payload.importance]
+                            }
+                            if ($payload.categories) {
+                                $item.Categories = (@($payload.categories) | Where-Object { $_ }) -join ", "
+                            }
+                            
+                            # Save() places a new task item in the default Tasks folder
+                            $item.Save()
+                            $responseObj = Convert-TaskToObject $item
+                            break
+                        }
+                        
+                        $params = [System.Web.HttpUtility]::ParseQueryString($query)
+                        $status = if ($params["status"]) { $params["status"] } else { "open" }
+                        if ($status -notin @("open", "completed", "all")) {
+                            $responseObj = @{ error = "Status must be one of open, completed, all"; code = "INVALID_STATUS" }
+                            $statusCode = 400
+                            break
+                        }
+                        $limit = if ($params["limit"]) { [int]$params["limit"] } else { 50 }
+                        
+                        $todo = $namespace.GetDefaultFolder(28) # olFolderToDo = 28
+                        $tasks = @()
+                        foreach ($item in $todo.Items) {
+                            $task = Convert-TaskToObject $item
+                            if (($status -eq "open" -and $task.complete) -or ($status -eq "completed" -and -not $task.complete)) {
+                                continue
                             }
+                            $tasks += $task
+                        }
+                        
+                        # Soonest due first; tasks without a due date last
+                        $tasks = @($tasks | Sort-Object @{ Expression = { if ($_.dueDate) { $_.dueDate } else { "9999-12-31" } } }, @{ Expression = { $_.subject } })
+                        $truncated = $tasks.Count -gt $limit
+                        if ($truncated) {
+                            $tasks = @($tasks[0..($limit - 1)])
+                        }
+                        
+                        $responseObj = @{
+                            status = $status
+                            tasks = $tasks
+                            count = $tasks.Count
+                            truncated = $truncated
+                        }
+                    }
+                    
+                    "^/tasks/([^/]+)/complete$" {
+                        # POST /tasks/{id}/complete - mark a task or flagged item complete
+                        $taskId = $matches[1]
+                        
+                        if ($request.HttpMethod -ne "POST") {
+                            $responseObj = @{ error = "Method not allowed"; code = "METHOD_NOT_ALLOWED" }
+                            $statusCode = 405
+                            break
+                        }
+                        
+                        try {
+                            $item = $namespace.GetItemFromID($taskId)
                         } catch {
-                            $responseObj = @{ error = "Message not found"; code = "MESSAGE_NOT_FOUND" }
+                            $responseObj = @{ error = "Task not found"; code = "TASK_NOT_FOUND" }
                             $statusCode = 404
+                            break
+                        }
+                        
+                        if ($item.Class -eq 48) { # olTask = 48
+                            $item.MarkComplete()
+                        } elseif ($item.IsMarkedAsTask) {
+                            $item.MarkAsTask(5) # olMarkComplete = 5
+                        } else {
+                            $responseObj = @{ error = "Item is neither a task nor flagged for follow-up"; code = "NOT_A_TASK" }
+                            $statusCode = 400
+                            break
                         }
+                        $item.Save()
+                        
+                        $responseObj = Convert-TaskToObject $item
                     }
                     
-                    "^/messages/([^/]+)/body$" {
-                        # GET /messages/{id}/body - cooked message body (readable text)
+                    "^/drafts$" {
+                        # POST /drafts - compose a message and save it to Drafts without sending
+                        if ($request.HttpMethod -ne "POST") {
+                            $responseObj = @{ error = "Method not allowed"; code = "METHOD_NOT_ALLOWED" }
+                            $statusCode = 405
+                            break
+                        }
+                        
+                        $payload = Read-RequestJson $request
+                        if (-not $payload) {
+                            $responseObj = @{ error = "Request body is required"; code = "MISSING_BODY" }
+                            $statusCode = 400
+                            break
+                        }
+                        
+                        $missing = @($payload.attachments | Where-Object { $_ -and -not (Test-Path -LiteralPath $_ -PathType Leaf) })
+                        if ($missing.Count -gt 0) {
+                            $responseObj = @{ error = "Attachment not found: $($missing[0])"; code = "ATTACHMENT_NOT_FOUND" }
+                            $statusCode = 400
+                            break
+                        }
+                        
+                        $mail = $outlook.CreateItem(0) # olMailItem = 0
+                        $mail.To = Join-Recipients $payload.to
+                        $mail.CC = Join-Recipients $payload.cc
+                        $mail.BCC = Join-Recipients $payload.bcc
+                        $mail.Subject = [string]$payload.subject
+                        if ($payload.html) {
+                            $mail.HTMLBody = [string]$payload.body
+                        } else {
+                            $mail.Body = [string]$payload.body
+                        }
+                        foreach ($attachmentPath in @($payload.attachments | Where-Object { $_ })) {
+                            $mail.Attachments.Add($attachmentPath) | Out-Null
+                        }
+                        
+                        # Save() places a new mail item in the default Drafts folder
+                        $mail.Save()
+                        
+                        $responseObj = @{
+                            id = $mail.EntryID
+                            subject = $mail.Subject
+                            to = $mail.To
+                            folder = $mail.Parent.Name
+                            attachmentCount = $mail.Attachments.Count
+                        }
+                    }
+                    
+                    "^/send$" {
+                        # POST /send - compose and send a new message
+                        $rejection = Test-SendRequest $request
+                        if ($rejection) {
+                            $responseObj = $rejection.body
+                            $statusCode = $rejection.status
+                            break
+                        }
+                        
+                        $payload = Read-RequestJson $request
+                        if (-not $payload -or -not $payload.to) {
+                            $responseObj = @{ error = "At least one 'to' recipient is required"; code = "MISSING_RECIPIENTS" }
+                            $statusCode = 400
+                            break
+                        }
+                        
+                        $mail = $outlook.CreateItem(0) # olMailItem = 0
+                        $mail.To = Join-Recipients $payload.to
+                        $mail.CC = Join-Recipients $payload.cc
+                        $mail.BCC = Join-Recipients $payload.bcc
+                        $mail.Subject = [string]$payload.subject
+                        if ($payload.html) {
+                            $mail.HTMLBody = [string]$payload.body
+                        } else {
+                            $mail.Body = [string]$payload.body
+                        }
+                        
+                        if (-not $mail.Recipients.ResolveAll()) {
+                            $responseObj = @{ error = "One or more recipients could not be resolved"; code = "UNRESOLVED_RECIPIENTS" }
+                            $statusCode = 400
+                            break
+                        }
+                        
+                        $subject = $mail.Subject
+                        $to = $mail.To
+                        $mail.Send()
+                        
+                        $responseObj = @{
+                            status = "sent"
+                            action = "send"
+                            subject = $subject
+                            to = $to
+                        }
+                    }
+                    
+                    "^/messages/([^/]+)/conversation$" {
+                        # GET /messages/{id}/conversation - all messages in the thread of a message, oldest first
                         $messageId = $matches[1]
                         
                         try {
                             $item = $namespace.GetItemFromID($messageId)
-                            if ($item.Class -eq 43) { # olMail = 43
-                                $bodyText = Get-MessageBodyText $item
-                                $responseObj = @{
-                                    id = $messageId
-                                    bodyText = $bodyText
-                                    wordCount = ($bodyText -split '\s+').Count
-                                    charCount = $bodyText.Length
-                                }
-                            } else {
-                                $responseObj = @{ error = "Item is not a mail message"; code = "NOT_MAIL_ITEM" }
-                                $statusCode = 400
+                        } catch {
+                            $responseObj = @{ error = "Message not found"; code = "MESSAGE_NOT_FOUND" }
+                            $statusCode = 404
+                            break
+                        }
+                        
+                        if ($item.Class -ne 43) { # olMail = 43
+                            $responseObj = @{ error = "Item is not a mail message"; code = "NOT_MAIL_ITEM" }
+                            $statusCode = 400
+                            break
+                        }
+                        
+                        # GetConversation returns null when the store has conversations disabled
+                        $conversation = $item.GetConversation()
+                        $threadItems = if ($conversation) { @(Get-ConversationItems $conversation) } else { @($item) }
+                        
+                        $messages = @()
+                        foreach ($threadItem in ($threadItems | Sort-Object ReceivedTime)) {
+                            if ($threadItem.Class -eq 43) { # olMail = 43
+                                $messageObj = Convert-OutlookItemToObject $threadItem
+                                $messageObj.bodyPreview = Get-BodyPreview $threadItem
+                                $messages += $messageObj
                             }
+                        }
+                        
+                        $responseObj = @{
+                            conversationId = $item.ConversationID
+                            topic = $item.ConversationTopic
+                            messages = $messages
+                            count = $messages.Count
+                        }
+                    }
+                    
+                    "^/messages/([^/]+)/attachments$" {
+                        # GET /messages/{id}/attachments - list attachments of a message
+                        $messageId = $matches[1]
+                        
+                        try {
+                            $item = $namespace.GetItemFromID($messageId)
+                        } catch {
+                            $responseObj = @{ error = "Message not found"; code = "MESSAGE_NOT_FOUND" }
+                            $statusCode = 404
+                            break
+                        }
+                        
+                        $attachments = @()
+                        for ($i = 1; $i -le $item.Attachments.Count; $i++) {
+                            $attachments += Convert-AttachmentToObject $item.Attachments.Item($i) $i
+                        }
+                        
+                        $responseObj = @{
+                            id = $messageId
+                            attachments = $attachments
+                            count = $attachments.Count
+                        }
+                    }
+                    
+                    "^/messages/([^/]+)/attachments/(\d+)/save$" {
+                        # POST /messages/{id}/attachments/{index}/save - save an attachment to a directory
+                        $messageId = $matches[1]
+                        $index = [int]$matches[2]
+                        
+                        if ($request.HttpMethod -ne "POST") {
+                            $responseObj = @{ error = "Method not allowed"; code = "METHOD_NOT_ALLOWED" }
+                            $statusCode = 405
+                            break
+                        }
+                        
+                        $payload = Read-RequestJson $request
+                        if (-not $payload -or -not $payload.directory -or -not (Test-Path -LiteralPath $payload.directory -PathType Container)) {
+                            $responseObj = @{ error = "An existing 'directory' is required"; code = "INVALID_DIRECTORY" }
+                            $statusCode = 400
+                            break
+                        }
+                        
+                        try {
+                            $item = $namespace.GetItemFromID($messageId)
                         } catch {
                             $responseObj = @{ error = "Message not found"; code = "MESSAGE_NOT_FOUND" }
                             $statusCode = 404
+                            break
+                        }
+                        
+                        if ($index -lt 1 -or $index -gt $item.Attachments.Count) {
+                            $responseObj = @{ error = "Attachment $index not found"; code = "ATTACHMENT_NOT_FOUND" }
+                            $statusCode = 404
+                            break
+                        }
+                        
+                        $attachment = $item.Attachments.Item($index)
+                        $targetPath = Get-UniqueAttachmentPath $payload.directory $attachment.FileName
+                        $attachment.SaveAsFile($targetPath)
+                        
+                        $responseObj = @{
+                            id = $messageId
+                            index = $index
+                            fileName = $attachment.FileName
+                            path = $targetPath
+                            size = (Get-Item -LiteralPath $targetPath).Length
                         }
                     }
                     
-                    "^/search$" {
-                        # GET /search?q={query} - search within inbox
-                        $searchQuery = [System.Web.HttpUtility]::ParseQueryString($query)["q"]
+                    "^/messages/([^/]+)/attachments/(\d+)/content$" {
+                        # GET /messages/{id}/attachments/{index}/content - return an attachment's bytes base64-encoded
+                        $messageId = $matches[1]
+                        $index = [int]$matches[2]
+                        
+                        try {
+                            $item = $namespace.GetItemFromID($messageId)
+                        } catch {
+                            $responseObj = @{ error = "Message not found"; code = "MESSAGE_NOT_FOUND" }
+                            $statusCode = 404
+                            break
+                        }
+                        
+                        if ($index -lt 1 -or $index -gt $item.Attachments.Count) {
+                            $responseObj = @{ error = "Attachment $index not found"; code = "ATTACHMENT_NOT_FOUND" }
+                            $statusCode = 404
+                            break
+                        }
+                        
+                        $attachment = $item.Attachments.Item($index)
+                        if ($attachment.Size -gt $maxInlineAttachmentBytes) {
+                            $responseObj = @{ error = "Attachment is $($attachment.Size) bytes; content over $maxInlineAttachmentBytes bytes must be saved instead"; code = "ATTACHMENT_TOO_LARGE" }
+                            $statusCode = 413
+                            break
+                        }
+                        
+                        $tempDir = Join-Path ([System.IO.Path]::GetTempPath()) ("outlook-mcp-" + [guid]::NewGuid().ToString("N"))
+                        New-Item -ItemType Directory -Path $tempDir | Out-Null
+                        try {
+                            $tempPath = Get-UniqueAttachmentPath $tempDir $attachment.FileName
+                            $attachment.SaveAsFile($tempPath)
+                            $bytes = [System.IO.File]::ReadAllBytes($tempPath)
+                        } finally {
+                            Remove-Item -LiteralPath $tempDir -Recurse -Force -ErrorAction SilentlyContinue
+                        }
+                        
+                        $responseObj = @{
+                            id = $messageId
+                            index = $index
+                            fileName = $attachment.FileName
+                            size = $bytes.Length
+                            content = [Convert]::ToBase64String($bytes)
+                        }
+                    }
+                    
+                    "^/messages/([^/]+)/export$" {
+                        # POST /messages/{id}/export - save a message as .msg or .eml (body: {format, path?, directory?});
+                        # without a path or directory the file content is returned base64-encoded
+                        $messageId = $matches[1]
+                        
+                        if ($request.HttpMethod -ne "POST") {
+                            $responseObj = @{ error = "Method not allowed"; code = "METHOD_NOT_ALLOWED" }
+                            $statusCode = 405
+                            break
+                        }
                         
-                        if (-not $searchQuery) {
-                            $responseObj = @{ error = "Query parameter 'q' is required"; code = "MISSING_QUERY" }
+                        $payload = Read-RequestJson $request
+                        $format = if ($payload -and $payload.format) { ([string]$payload.format).ToLower() } else { "msg" }
+                        if ($format -notin @("msg", "eml")) {
+                            $responseObj = @{ error = "Format must be 'msg' or 'eml'"; code = "INVALID_FORMAT" }
                             $statusCode = 400
+                            break
+                        }
+                        
+                        try {
+                            $item = $namespace.GetItemFromID($messageId)
+                        } catch {
+                            $responseObj = @{ error = "Message not found"; code = "MESSAGE_NOT_FOUND" }
+                            $statusCode = 404
+                            break
+                        }
+                        
+                        $inline = $false
+                        if ($payload -and $payload.path) {
+                            $parent = Split-Path -Parent $payload.path
+                            if (-not $parent -or -not (Test-Path -LiteralPath $parent -PathType Container)) {
+                                $responseObj = @{ error = "The directory of 'path' must exist"; code = "INVALID_PATH" }
+                                $statusCode = 400
+                                break
+                            }
+                            if (Test-Path -LiteralPath $payload.path) {
+                                $responseObj = @{ error = "File already exists: $($payload.path)"; code = "FILE_EXISTS" }
+                                $statusCode = 409
+                                break
+                            }
+                            $targetPath = $payload.path
+                        } elseif ($payload -and $payload.directory) {
+                            if (-not (Test-Path -LiteralPath $payload.directory -PathType Container)) {
+                                $responseObj = @{ error = "An existing 'directory' is required"; code = "INVALID_DIRECTORY" }
+                                $statusCode = 400
+                                break
+                            }
+                            $subjectName = if ($item.Subject) { $item.Subject } else { "message" }
+                            $targetPath = Get-UniqueAttachmentPath $payload.directory "$subjectName.$format"
                         } else {
-                            # Use Outlook's search functionality
-                            $searchResults = $inbox.Items.Restrict("[Subject] LIKE '%$searchQuery%' OR [Body] LIKE '%$searchQuery%' OR [SenderName] LIKE '%$searchQuery%'")
-                            
-                            $messages = @()
-                            foreach ($item in $searchResults) {
-                                if ($item.Class -eq 43) { # olMail = 43
-                                    $messages += Convert-OutlookItemToObject $item
+                            $inline = $true
+                            $targetPath = Join-Path ([System.IO.Path]::GetTempPath()) ("outlook-mcp-" + [guid]::NewGuid().ToString("N") + ".$format")
+                        }
+                        
+                        if ($format -eq "msg") {
+                            $item.SaveAs($targetPath, 9) # olMSGUnicode = 9
+                        } else {
+                            [System.IO.File]::WriteAllBytes($targetPath, (New-EmlContent $item))
+                        }
+                        $size = (Get-Item -LiteralPath $targetPath).Length
+                        
+                        $responseObj = @{
+                            id = $messageId
+                            format = $format
+                            size = $size
+                        }
+                        if ($inline) {
+                            try {
+                                if ($size -gt $maxInlineExportBytes) {
+                                    $responseObj = @{ error = "Message is $size bytes; exports over $maxInlineExportBytes bytes need a path"; code = "EXPORT_TOO_LARGE" }
+                                    $statusCode = 413
+                                    break
                                 }
+                                $responseObj.content = [Convert]::ToBase64String([System.IO.File]::ReadAllBytes($targetPath))
+                            } finally {
+                                Remove-Item -LiteralPath $targetPath -Force -ErrorAction SilentlyContinue
                             }
-                            
-                            # Sort by received time descending
-                            $messages = $messages | Sort-Object receivedTime -Descending
-                            
-                            $responseObj = @{
-                                query = $searchQuery
-                                results = $messages
-                                count = $messages.Count
+                        } else {
+                            $responseObj.fileName = Split-Path -Leaf $targetPath
+                            $responseObj.path = $targetPath
+                        }
+                    }
+                    
+                    "^/messages/([^/]+)/move$" {
+                        # POST /messages/{id}/move - move a message to another folder (body: {folder, account?})
+                        $messageId = $matches[1]
+                        
+                        if ($request.HttpMethod -ne "POST") {
+                            $responseObj = @{ error = "Method not allowed"; code = "METHOD_NOT_ALLOWED" }
+                            $statusCode = 405
+                            break
+                        }
+                        
+                        $payload = Read-RequestJson $request
+                        if (-not $payload -or -not $payload.folder) {
+                            $responseObj = @{ error = "Destination 'folder' is required"; code = "MISSING_FOLDER" }
+                            $statusCode = 400
+                            break
+                        }
+                        
+                        $destination = Resolve-OutlookFolder $payload.folder $payload.account
+                        if (-not $destination) {
+                            $responseObj = Get-FolderNotFoundError $payload.folder $payload.account
+                            $statusCode = 404
+                            break
+                        }
+                        
+                        try {
+                            $item = $namespace.GetItemFromID($messageId)
+                        } catch {
+                            $responseObj = @{ error = "Message not found"; code = "MESSAGE_NOT_FOUND" }
+                            $statusCode = 404
+                            break
+                        }
+                        
+                        # Moving can change the EntryID, so report the new one
+                        $moved = $item.Move($destination)
+                        
+                        $responseObj = @{
+                            id = $moved.EntryID
+                            previousId = $messageId
+                            folder = $destination.Name
+                        }
+                    }
+                    
+                    "^/messages/([^/]+)/(reply|forward)$" {
+                        # POST /messages/{id}/reply and /messages/{id}/forward - respond to an existing message
+                        $messageId = $matches[1]
+                        $action = $matches[2]
+                        
+                        $rejection = Test-SendRequest $request
+                        if ($rejection) {
+                            $responseObj = $rejection.body
+                            $statusCode = $rejection.status
+                            break
+                        }
+                        
+                        $payload = Read-RequestJson $request
+                        
+                        try {
+                            $item = $namespace.GetItemFromID($messageId)
+                        } catch {
+                            $responseObj = @{ error = "Message not found"; code = "MESSAGE_NOT_FOUND" }
+                            $statusCode = 404
+                            break
+                        }
+                        
+                        if ($item.Class -ne 43) { # olMail = 43
+                            $responseObj = @{ error = "Item is not a mail message"; code = "NOT_MAIL_ITEM" }
+                            $statusCode = 400
+                            break
+                        }
+                        
+                        if ($action -eq "forward") {
+                            if (-not $payload -or -not $payload.to) {
+                                $responseObj = @{ error = "At least one 'to' recipient is required"; code = "MISSING_RECIPIENTS" }
+                                $statusCode = 400
+                                break
                             }
+                            $outgoing = $item.Forward()
+                            $outgoing.To = Join-Recipients $payload.to
+                            $outgoing.CC = Join-Recipients $payload.cc
+                            $outgoing.BCC = Join-Recipients $payload.bcc
+                        } elseif ($payload -and $payload.replyAll) {
+                            $outgoing = $item.ReplyAll()
+                            $action = "reply_all"
+                        } else {
+                            $outgoing = $item.Reply()
+                        }
+                        
+                        if ($payload) {
+                            Add-BodyPrefix $outgoing ([string]$payload.body)
+                        }
+                        
+                        if (-not $outgoing.Recipients.ResolveAll()) {
+                            $responseObj = @{ error = "One or more recipients could not be resolved"; code = "UNRESOLVED_RECIPIENTS" }
+                            $statusCode = 400
+                            break
+                        }
+                        
+                        $subject = $outgoing.Subject
+                        $to = $outgoing.To
+                        $outgoing.Send()
+                        
+                        $responseObj = @{
+                            status = "sent"
+                            action = $action
+                            id = $messageId
+                            subject = $subject
+                            to = $to
                         }
                     }
                     
@@ -0,0 +1,66 @@
+package outlook
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Relevance weights for search results. A subject match outranks a body match,
+// and recency breaks ties between messages that match in the same places.
+const (
+	subjectMatchWeight = 4.0
+	senderMatchWeight  = 2.0
+	bodyMatchWeight    = 1.0
+	maxRecencyBoost    = 1.0
+	recencyHalfLife    = 7 * 24 * time.Hour
+)
+
+// rankSearchResults scores messages against the query and orders them by
+// relevance, most relevant first. Body matches are detected from the snippet
+// the PowerShell server attaches when the query occurs in the message body.
+func rankSearchResults(messages []Message, query string, now time.Time) {
+	needle := strings.ToLower(strings.TrimSpace(query))
+
+	for i := range messages {
+		messages[i].Relevance = scoreMessage(&messages[i], needle, now)
+	}
+
+	sort.SliceStable(messages, func(i, j int) bool {
+		if messages[i].Relevance != messages[j].Relevance {
+			return messages[i].Relevance > messages[j].Relevance
+		}
+		return messages[i].ReceivedTime.After(messages[j].ReceivedTime)
+	})
+}
+
+// scoreMessage computes the relevance of a single message for a lower-cased query
+func scoreMessage(msg *Message, needle string, now time.Time) float64 {
+	var score float64
+
+	if needle != "" {
+		if strings.Contains(strings.ToLower(msg.Subject), needle) {
+			score += subjectMatchWeight
+		}
+		if strings.Contains(strings.ToLower(msg.Sender), needle) ||
+			strings.Contains(strings.ToLower(msg.SenderEmail), needle) {
+			score += senderMatchWeight
+		}
+	}
+	if msg.Snippet != "" {
+		score += bodyMatchWeight
+	}
+
+	// Recency boost halves every recencyHalfLife, so it only reorders messages
+	// whose match scores are equal
+	if !msg.ReceivedTime.IsZero() {
+		age := now.Sub(msg.ReceivedTime)
+		if age < 0 {
+			age = 0
+		}
+		score += maxRecencyBoost * math.Pow(0.5, float64(age)/float64(recencyHalfLife))
+	}
+
+	return math.Round(score*1000) / 1000
+}
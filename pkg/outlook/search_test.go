@@ -0,0 +1,56 @@
+package outlook
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRankSearchResults(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	messages := []Message{
+		{ID: "body-new", Subject: "Weekly update", Snippet: "...the budget review is...", ReceivedTime: now.Add(-1 * time.Hour)},
+		{ID: "subject-old", Subject: "Budget review", ReceivedTime: now.Add(-90 * 24 * time.Hour)},
+		{ID: "body-old", Subject: "Notes", Snippet: "...budget...", ReceivedTime: now.Add(-30 * 24 * time.Hour)},
+		{ID: "subject-new", Subject: "RE: budget review", Snippet: "budget review attached", ReceivedTime: now.Add(-2 * time.Hour)},
+		{ID: "sender", Subject: "Hello", Sender: "Budget Office", ReceivedTime: now.Add(-24 * time.Hour)},
+	}
+
+	rankSearchResults(messages, "Budget", now)
+
+	expected := []string{"subject-new", "subject-old", "sender", "body-new", "body-old"}
+	for i, id := range expected {
+		if messages[i].ID != id {
+			t.Errorf("Position %d: expected %s, got %s (relevance %.3f)", i, id, messages[i].ID, messages[i].Relevance)
+		}
+	}
+
+	for i := 1; i < len(messages); i++ {
+		if messages[i].Relevance > messages[i-1].Relevance {
+			t.Errorf("Results not sorted by relevance at position %d", i)
+		}
+	}
+}
+
+func TestScoreMessageRecencyBoost(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	fresh := Message{Subject: "report", ReceivedTime: now}
+	weekOld := Message{Subject: "report", ReceivedTime: now.Add(-recencyHalfLife)}
+
+	freshScore := scoreMessage(&fresh, "report", now)
+	weekOldScore := scoreMessage(&weekOld, "report", now)
+
+	if freshScore != subjectMatchWeight+maxRecencyBoost {
+		t.Errorf("Expected fresh score %.1f, got %.3f", subjectMatchWeight+maxRecencyBoost, freshScore)
+	}
+	if weekOldScore != subjectMatchWeight+maxRecencyBoost/2 {
+		t.Errorf("Expected half recency boost after one half-life, got %.3f", weekOldScore)
+	}
+
+	// Messages without a received time get no boost rather than a huge one
+	undated := Message{Subject: "report"}
+	if score := scoreMessage(&undated, "report", now); score != subjectMatchWeight {
+		t.Errorf("Expected undated score %.1f, got %.3f", subjectMatchWeight, score)
+	}
+}
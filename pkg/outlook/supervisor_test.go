@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os/exec"
 	"runtime"
 	"sync"
 	"testing"
@@ -24,7 +25,6 @@ func TestProcessSupervision(t *testing.T) {
 		client:        &http.Client{Timeout: 5 * time.Second},
 		supervisorCtx: context.Background(),
 		restartChan:   make(chan bool, 1),
-		isShutdown:    false,
 	}
 
 	// Verify the required fields exist
@@ -49,7 +49,6 @@ func TestSupervisorLoop(t *testing.T) {
 		supervisorCtx: ctx,
 		cancelFunc:    cancel,
 		restartChan:   make(chan bool, 1),
-		isShutdown:    false,
 	}
 
 	// Test that supervisor exits when context is cancelled
@@ -96,7 +95,6 @@ func TestRestartSignaling(t *testing.T) {
 		supervisorCtx: ctx,
 		cancelFunc:    cancel,
 		restartChan:   restartChan,
-		isShutdown:    false,
 	}
 
 	// Test that restart signal can be sent
@@ -131,8 +129,8 @@ func TestShutdownPreventsRestart(t *testing.T) {
 		supervisorCtx: ctx,
 		cancelFunc:    cancel,
 		restartChan:   make(chan bool, 1),
-		isShutdown:    true, // Set shutdown flag
 	}
+	manager.isShutdown.Store(true) // Set shutdown flag
 
 	// Mock a restart attempt - should return early due to shutdown flag
 	var wg sync.WaitGroup
@@ -150,7 +148,7 @@ func TestShutdownPreventsRestart(t *testing.T) {
 		case <-manager.supervisorCtx.Done():
 			// Supervisor context cancelled
 		case <-manager.restartChan:
-			if manager.isShutdown {
+			if manager.isShutdown.Load() {
 				// Expected: should return early
 				return
 			}
@@ -181,7 +179,6 @@ func TestManagerStopCancelsSupervision(t *testing.T) {
 		supervisorCtx: ctx,
 		cancelFunc:    cancel,
 		restartChan:   make(chan bool, 1),
-		isShutdown:    false,
 		cmd:           nil, // No actual process
 	}
 
@@ -192,7 +189,7 @@ func TestManagerStopCancelsSupervision(t *testing.T) {
 	}
 
 	// Verify shutdown flag is set
-	if !manager.isShutdown {
+	if !manager.isShutdown.Load() {
 		t.Error("isShutdown flag should be set after Stop()")
 	}
 
@@ -224,7 +221,6 @@ func TestMakeRequestWithSupervision(t *testing.T) {
 		supervisorCtx: ctx,
 		cancelFunc:    cancel,
 		restartChan:   make(chan bool, 1),
-		isShutdown:    false,
 	}
 
 	// Test that makeRequest still works with supervision fields
@@ -238,3 +234,123 @@ func TestMakeRequestWithSupervision(t *testing.T) {
 		t.Errorf("Expected response %s, got %s", expected, string(body))
 	}
 }
+
+// startSleepProcess starts a long-running child process for lifecycle tests
+func startSleepProcess(t *testing.T) *exec.Cmd {
+	t.Helper()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping process lifecycle test on Windows")
+	}
+	sleepPath, err := exec.LookPath("sleep")
+	if err != nil {
+		t.Skip("sleep binary not available")
+	}
+
+	cmd := exec.Command(sleepPath, "30")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Failed to start process: %v", err)
+	}
+	return cmd
+}
+
+// TestRetiredProcessDoesNotRestart tests that a replaced process exiting does not trigger a restart
+func TestRetiredProcessDoesNotRestart(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	manager := &Manager{
+		supervisorCtx: ctx,
+		cancelFunc:    cancel,
+		restartChan:   make(chan bool, 1),
+	}
+
+	if err := manager.adoptProcess(startSleepProcess(t)); err != nil {
+		t.Fatalf("adoptProcess failed: %v", err)
+	}
+
+	cmd, done := manager.retireProcess()
+	cmd.Process.Kill()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Process monitor did not observe exit")
+	}
+
+	select {
+	case <-manager.restartChan:
+		t.Error("Retired process exit should not signal a restart")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// TestCurrentProcessExitSignalsRestart tests that a crash of the current process signals a restart
+func TestCurrentProcessExitSignalsRestart(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	manager := &Manager{
+		supervisorCtx: ctx,
+		cancelFunc:    cancel,
+		restartChan:   make(chan bool, 1),
+	}
+
+	cmd := startSleepProcess(t)
+	if err := manager.adoptProcess(cmd); err != nil {
+		t.Fatalf("adoptProcess failed: %v", err)
+	}
+	cmd.Process.Kill()
+
+	select {
+	case <-manager.restartChan:
+	case <-time.After(5 * time.Second):
+		t.Error("Expected crash of the current process to signal a restart")
+	}
+	if manager.health.processExits != 1 {
+		t.Errorf("Expected process exit to be recorded, got %d", manager.health.processExits)
+	}
+}
+
+// TestStopIsIdempotentAndConcurrent tests that concurrent Stop calls stop the process once
+func TestStopIsIdempotentAndConcurrent(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	manager := &Manager{
+		supervisorCtx: ctx,
+		cancelFunc:    cancel,
+		restartChan:   make(chan bool, 1),
+	}
+
+	cmd := startSleepProcess(t)
+	if err := manager.adoptProcess(cmd); err != nil {
+		t.Fatalf("adoptProcess failed: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := manager.Stop(); err != nil {
+				t.Errorf("Stop() returned error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if cmd.ProcessState == nil {
+		t.Error("Process should have exited after Stop()")
+	}
+	select {
+	case <-manager.restartChan:
+		t.Error("Stop() should not signal a restart")
+	default:
+	}
+
+	// Processes started after shutdown are rejected
+	late := startSleepProcess(t)
+	if err := manager.adoptProcess(late); err == nil {
+		t.Error("Expected adoptProcess to fail after Stop()")
+	}
+}
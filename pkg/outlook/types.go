@@ -16,10 +16,16 @@ type Message struct {
 	HasAttachments  bool       `json:"hasAttachments"`
 	AttachmentCount int        `json:"attachmentCount"`
 	BodyPreview     string     `json:"bodyPreview,omitempty"`
+	FlagStatus      string     `json:"flagStatus,omitempty"`
+	Categories      []string   `json:"categories,omitempty"`
+	Snippet         string     `json:"snippet,omitempty"`
+	Relevance       float64    `json:"relevance,omitempty"`
 }
 
 // MessageListResponse represents the response from the /messages endpoint
 type MessageListResponse struct {
+	Folder     string     `json:"folder,omitempty"`
+	Since      *time.Time `json:"since,omitempty"` // Set when only messages received after it were listed
 	Messages   []Message  `json:"messages"`
 	Pagination Pagination `json:"pagination"`
 }
@@ -51,9 +57,682 @@ type MessageBodyRawResponse struct {
 
 // SearchResponse represents the response from the /search endpoint
 type SearchResponse struct {
-	Query   string    `json:"query"`
-	Results []Message `json:"results"`
-	Count   int       `json:"count"`
+	Query      string     `json:"query"`
+	Folder     string     `json:"folder,omitempty"`
+	Results    []Message  `json:"results"`
+	Count      int        `json:"count"` // Number of results on this page
+	Pagination Pagination `json:"pagination"`
+	Truncated  bool       `json:"truncated,omitempty"` // Only the first matches were searched, so Total is a lower bound
+}
+
+// Importance levels accepted by SearchFilters.Importance
+const (
+	ImportanceLow    = "low"
+	ImportanceNormal = "normal"
+	ImportanceHigh   = "high"
+)
+
+// SearchFilters narrows a message search. Zero values leave a criterion unset;
+// all set criteria must match.
+type SearchFilters struct {
+	From           string    // Substring of the sender name or address
+	To             string    // Substring of the To or Cc display names
+	After          time.Time // Received at or after
+	Before         time.Time // Received before
+	HasAttachments *bool
+	Unread         *bool
+	Importance     string // low, normal or high
+}
+
+// IsEmpty reports whether no filter criteria are set
+func (f SearchFilters) IsEmpty() bool {
+	return f.From == "" && f.To == "" && f.After.IsZero() && f.Before.IsZero() &&
+		f.HasAttachments == nil && f.Unread == nil && f.Importance == ""
+}
+
+// ListFilters narrows a folder listing. Zero values leave a criterion unset;
+// all set criteria must match.
+type ListFilters struct {
+	UnreadOnly     bool
+	Importance     string // low, normal or high
+	Category       string // Only messages with this category
+	HasAttachments *bool
+}
+
+// IsEmpty reports whether no filter criteria are set
+func (f ListFilters) IsEmpty() bool {
+	return !f.UnreadOnly && f.Importance == "" && f.Category == "" && f.HasAttachments == nil
+}
+
+// ConversationResponse represents the response from the GET /messages/{id}/conversation endpoint
+type ConversationResponse struct {
+	ConversationID string    `json:"conversationId"`
+	Topic          string    `json:"topic"`
+	Messages       []Message `json:"messages"` // Oldest first, with BodyPreview populated
+	Count          int       `json:"count"`
+}
+
+// Folder represents an Outlook mail folder
+type Folder struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Path        string `json:"path"`
+	ItemCount   int    `json:"itemCount"`
+	UnreadCount int    `json:"unreadCount"`
+	Depth       int    `json:"depth"`
+}
+
+// FolderListResponse represents the response from the /folders endpoint
+type FolderListResponse struct {
+	Account string   `json:"account,omitempty"`
+	Folders []Folder `json:"folders"`
+	Count   int      `json:"count"`
+}
+
+// Account represents an email account configured in the Outlook profile
+type Account struct {
+	Name        string `json:"name"`
+	SMTPAddress string `json:"smtpAddress"`
+	Type        string `json:"type"` // exchange, imap, pop3, http, eas or other
+	StoreName   string `json:"storeName"`
+	IsDefault   bool   `json:"isDefault"`
+}
+
+// Store represents a mailbox or data file open in the Outlook profile, including
+// shared mailboxes and archives that have no account of their own
+type Store struct {
+	Name      string `json:"name"`
+	Type      string `json:"type"` // primary, delegate, additional, public_folders or local
+	FilePath  string `json:"filePath,omitempty"`
+	IsDefault bool   `json:"isDefault"`
+}
+
+// AccountListResponse represents the response from the GET /accounts endpoint
+type AccountListResponse struct {
+	Accounts []Account `json:"accounts"`
+	Stores   []Store   `json:"stores"`
+}
+
+// SendMessageRequest is the payload for the POST /send endpoint
+type SendMessageRequest struct {
+	To      []string `json:"to"`
+	Cc      []string `json:"cc,omitempty"`
+	Bcc     []string `json:"bcc,omitempty"`
+	Subject string   `json:"subject"`
+	Body    string   `json:"body"`
+	HTML    bool     `json:"html,omitempty"`
+}
+
+// DraftRequest is the payload for the POST /drafts endpoint
+type DraftRequest struct {
+	To          []string `json:"to,omitempty"`
+	Cc          []string `json:"cc,omitempty"`
+	Bcc         []string `json:"bcc,omitempty"`
+	Subject     string   `json:"subject"`
+	Body        string   `json:"body"`
+	HTML        bool     `json:"html,omitempty"`
+	Attachments []string `json:"attachments,omitempty"`
+}
+
+// DraftResponse represents the response from the POST /drafts endpoint
+type DraftResponse struct {
+	ID              string `json:"id"`
+	Subject         string `json:"subject"`
+	To              string `json:"to"`
+	Folder          string `json:"folder"`
+	AttachmentCount int    `json:"attachmentCount"`
+}
+
+// ReplyRequest is the payload for the POST /messages/{id}/reply endpoint
+type ReplyRequest struct {
+	Body     string `json:"body"`
+	ReplyAll bool   `json:"replyAll,omitempty"`
+}
+
+// ForwardRequest is the payload for the POST /messages/{id}/forward endpoint
+type ForwardRequest struct {
+	To   []string `json:"to"`
+	Cc   []string `json:"cc,omitempty"`
+	Bcc  []string `json:"bcc,omitempty"`
+	Body string   `json:"body,omitempty"`
+}
+
+// SendResponse represents the response from the send, reply and forward endpoints
+type SendResponse struct {
+	Status  string `json:"status"`
+	Action  string `json:"action"`
+	ID      string `json:"id,omitempty"`
+	Subject string `json:"subject"`
+	To      string `json:"to"`
+}
+
+// Flag states accepted by UpdateMessageRequest.Flag and reported in FlagStatus
+const (
+	FlagStatusFlagged  = "flagged"
+	FlagStatusComplete = "complete"
+	FlagStatusNone     = "none"
+)
+
+// UpdateMessageRequest is the payload for the PATCH /messages/{id} endpoint.
+// Nil or empty fields are left unchanged; a non-nil empty Categories clears all categories.
+type UpdateMessageRequest struct {
+	Unread     *bool     `json:"unread,omitempty"`
+	Flag       string    `json:"flag,omitempty"`
+	Categories *[]string `json:"categories,omitempty"`
+}
+
+// UpdateMessageResponse represents the response from the PATCH /messages/{id} endpoint
+type UpdateMessageResponse struct {
+	ID         string   `json:"id"`
+	Unread     bool     `json:"unread"`
+	FlagStatus string   `json:"flagStatus"`
+	Categories []string `json:"categories"`
+}
+
+// MoveMessageResponse represents the response from the POST /messages/{id}/move endpoint
+type MoveMessageResponse struct {
+	ID         string `json:"id"`
+	PreviousID string `json:"previousId"`
+	Folder     string `json:"folder"`
+}
+
+// DeleteMessageResponse represents the response from the DELETE /messages/{id} endpoint
+type DeleteMessageResponse struct {
+	ID        string `json:"id"`
+	Permanent bool   `json:"permanent"`
+	Folder    string `json:"folder,omitempty"`
+}
+
+// Actions accepted by BulkUpdateRequest.Action
+const (
+	BulkActionMarkRead   = "mark_read"
+	BulkActionMarkUnread = "mark_unread"
+	BulkActionMove       = "move"
+	BulkActionCategorize = "categorize"
+	BulkActionDelete     = "delete"
+)
+
+// BulkUpdateRequest selects messages either by ID or by search and applies one action to all of them
+type BulkUpdateRequest struct {
+	Action      string
+	MessageIDs  []string      // Explicit messages; mutually exclusive with Query and Filters
+	Query       string        // Search text, as for SearchMessages
+	Folder      string        // Folder to search (default: Inbox)
+	Account     string        // Account to search (default: the default store)
+	Filters     SearchFilters // Search filters, as for SearchMessages
+	Destination string        // Target folder for move
+	Categories  []string      // Categories added to each message for categorize
+	Permanent   bool          // Delete permanently instead of moving to Deleted Items
+	Limit       int           // Maximum number of messages a search may select
+	DryRun      bool          // Resolve the affected messages without changing them
+}
+
+// BulkUpdateResult reports the messages a bulk update selected and what happened to them
+type BulkUpdateResult struct {
+	Action    string
+	DryRun    bool
+	Messages  []Message // Selected messages, in search or argument order
+	Truncated bool      // The search matched more messages than the limit, or than the backend could search
+	Succeeded int
+	Failures  []BulkFailure
+}
+
+// BulkFailure records a message a bulk update could not look up or change
+type BulkFailure struct {
+	MessageID string
+	Error     string
+}
+
+// PollRequest asks for the messages that arrived after a cursor and match a search
+type PollRequest struct {
+	Cursor  string        // From a previous PollResult; empty starts a new watch
+	Since   time.Time     // Where a new watch starts (default: now); ignored with a cursor
+	Query   string        // Search text, as for SearchMessages
+	Folder  string        // Folder to watch (default: Inbox)
+	Account string        // Account to watch (default: the default store)
+	Filters SearchFilters // Search filters, as for SearchMessages; After is set from the cursor
+	Limit   int           // Maximum number of messages returned
+	Wait    time.Duration // How long to keep polling while nothing new has arrived
+}
+
+// PollResult reports new messages and the cursor to pass to the next poll
+type PollResult struct {
+	Messages  []Message // Oldest first
+	Cursor    string
+	HasMore   bool // More new messages are waiting; poll again with Cursor
+	Truncated bool // Too many messages arrived to scan; the oldest of them were skipped
+}
+
+// ExportRequest asks for the messages matching a search to be written to a CSV file
+type ExportRequest struct {
+	Query   string        // Search text, as for SearchMessages
+	Folder  string        // Folder to search (default: Inbox)
+	Account string        // Account to search (default: the default store)
+	Filters SearchFilters // Search filters, as for SearchMessages
+	Path    string        // File to create, or a directory to save into
+	Limit   int           // Maximum number of messages exported
+}
+
+// ExportResult reports where a CSV export was written
+type ExportResult struct {
+	Path      string
+	FileName  string
+	Size      int64
+	Messages  int
+	Truncated bool // More messages matched than the limit allowed, or than the backend could search
+}
+
+// MailboxStats represents the response from the GET /stats endpoint: counts
+// over the messages a folder received in a date range, newest first up to a
+// limit
+type MailboxStats struct {
+	Folder       string         `json:"folder"`
+	Messages     int            `json:"messages"`
+	Unread       int            `json:"unread"`
+	TotalSize    int64          `json:"totalSize"`
+	Truncated    bool           `json:"truncated"` // More messages were received than were counted
+	Senders      []SenderVolume `json:"senders"`   // Every sender, by address
+	Days         []DayVolume    `json:"days"`      // Local dates with mail
+	OldestUnread *time.Time     `json:"oldestUnread,omitempty"`
+	Largest      []Message      `json:"largest"` // Largest first
+}
+
+// SenderVolume counts the messages from one sender or domain
+type SenderVolume struct {
+	Name    string `json:"name,omitempty"`
+	Address string `json:"address"` // Lower case; the domain for domain counts
+	Count   int    `json:"count"`
+	Unread  int    `json:"unread"`
+	Size    int64  `json:"size"`
+}
+
+// DayVolume counts the messages received on a local date
+type DayVolume struct {
+	Date   string `json:"date"` // YYYY-MM-DD
+	Count  int    `json:"count"`
+	Unread int    `json:"unread"`
+}
+
+// AnalyticsRequest selects the messages get_inbox_analytics summarizes
+type AnalyticsRequest struct {
+	Folder  string
+	Account string
+	Start   time.Time
+	End     time.Time
+	Top     int // Senders, domains and largest messages listed
+}
+
+// InboxAnalytics summarizes a folder's mail over a date range
+type InboxAnalytics struct {
+	Folder       string
+	Start        time.Time
+	End          time.Time
+	Messages     int
+	Unread       int
+	TotalSize    int64
+	Truncated    bool
+	TopSenders   []SenderVolume
+	TopDomains   []SenderVolume
+	ByDay        []DayVolume // Every day of the range, oldest first
+	UnreadAging  []AgeBucket
+	OldestUnread *time.Time
+	Largest      []Message
+}
+
+// AgeBucket counts unread messages by how many days ago they arrived
+type AgeBucket struct {
+	Label string
+	Count int
+}
+
+// Attachment describes a file attached to a message
+type Attachment struct {
+	Index    int    `json:"index"` // 1-based position, as used by Outlook's Attachments collection
+	FileName string `json:"fileName"`
+	Size     int64  `json:"size"`
+	Inline   bool   `json:"inline"` // Embedded in the body (e.g., signature images)
+}
+
+// AttachmentListResponse represents the response from the GET /messages/{id}/attachments endpoint
+type AttachmentListResponse struct {
+	ID          string       `json:"id"`
+	Attachments []Attachment `json:"attachments"`
+	Count       int          `json:"count"`
+}
+
+// SaveAttachmentResponse represents the response from the POST /messages/{id}/attachments/{index}/save endpoint
+type SaveAttachmentResponse struct {
+	ID       string `json:"id"`
+	Index    int    `json:"index"`
+	FileName string `json:"fileName"`
+	Path     string `json:"path"`
+	Size     int64  `json:"size"`
+}
+
+// AttachmentContentResponse represents the response from the GET /messages/{id}/attachments/{index}/content endpoint
+type AttachmentContentResponse struct {
+	ID       string `json:"id"`
+	Index    int    `json:"index"`
+	FileName string `json:"fileName"`
+	Size     int64  `json:"size"`
+	Content  []byte `json:"content"` // Base64 in JSON
+}
+
+// Formats accepted by ExportMessage
+const (
+	ExportFormatMsg = "msg" // Outlook item file (Unicode MSG)
+	ExportFormatEml = "eml" // RFC 5322 MIME message
+)
+
+// ExportMessageResponse represents the response from the POST /messages/{id}/export endpoint.
+// Path and FileName are set when the message was written to disk, Content (base64) otherwise.
+type ExportMessageResponse struct {
+	ID       string `json:"id"`
+	Format   string `json:"format"`
+	FileName string `json:"fileName,omitempty"`
+	Path     string `json:"path,omitempty"`
+	Size     int64  `json:"size"`
+	Content  string `json:"content,omitempty"`
+}
+
+// Busy states reported in CalendarEvent.BusyStatus
+const (
+	BusyStatusFree             = "free"
+	BusyStatusTentative        = "tentative"
+	BusyStatusBusy             = "busy"
+	BusyStatusOutOfOffice      = "out_of_office"
+	BusyStatusWorkingElsewhere = "working_elsewhere"
+)
+
+// Task kinds reported in Task.Kind
+const (
+	TaskKindTask    = "task"    // Outlook task item
+	TaskKindMessage = "message" // Message flagged for follow-up
+)
+
+// Task status filters accepted by ListTasks
+const (
+	TaskStatusOpen      = "open"
+	TaskStatusCompleted = "completed"
+	TaskStatusAll       = "all"
+)
+
+// Task represents an entry of the Outlook To-Do List: a task item or a message
+// flagged for follow-up. Dates are YYYY-MM-DD and empty when unset.
+type Task struct {
+	ID            string   `json:"id"`
+	Subject       string   `json:"subject"`
+	Kind          string   `json:"kind"`
+	Status        string   `json:"status"` // not_started, in_progress, completed, waiting or deferred
+	Complete      bool     `json:"complete"`
+	DueDate       string   `json:"dueDate,omitempty"`
+	StartDate     string   `json:"startDate,omitempty"`
+	CompletedDate string   `json:"completedDate,omitempty"`
+	Importance    string   `json:"importance"`
+	Categories    []string `json:"categories"`
+	Sender        string   `json:"sender,omitempty"` // Flagged messages only
+}
+
+// TaskListResponse represents the response from the GET /tasks endpoint
+type TaskListResponse struct {
+	Status    string `json:"status"`
+	Tasks     []Task `json:"tasks"`
+	Count     int    `json:"count"`
+	Truncated bool   `json:"truncated"`
+}
+
+// CreateTaskRequest is the body of the POST /tasks endpoint. Setting MessageID
+// flags that message for follow-up instead of creating a task item; Subject
+// then overrides the task subject shown in the To-Do List.
+type CreateTaskRequest struct {
+	Subject    string   `json:"subject,omitempty"`
+	Body       string   `json:"body,omitempty"`
+	DueDate    string   `json:"dueDate,omitempty"`
+	StartDate  string   `json:"startDate,omitempty"`
+	Importance string   `json:"importance,omitempty"`
+	Categories []string `json:"categories,omitempty"`
+	MessageID  string   `json:"messageId,omitempty"`
+}
+
+// AutomaticReplyStatus represents the response from the /oof endpoint
+type AutomaticReplyStatus struct {
+	Store   string `json:"store"`
+	Enabled bool   `json:"enabled"`
+}
+
+// Signature is an email signature saved in Outlook
+type Signature struct {
+	Name         string `json:"name"`
+	Text         string `json:"text,omitempty"` // Plain text version, if Outlook saved one
+	HTML         string `json:"html,omitempty"`
+	DefaultNew   bool   `json:"defaultNew"`   // Inserted into new messages
+	DefaultReply bool   `json:"defaultReply"` // Inserted into replies and forwards
+}
+
+// UserProfile represents the response from the /profile endpoint: who the
+// mailbox owner is and the signatures available for composing messages
+type UserProfile struct {
+	DisplayName string      `json:"displayName"`
+	SMTPAddress string      `json:"smtpAddress"`
+	Profile     string      `json:"profile,omitempty"` // Outlook profile name (COM backend)
+	Signatures  []Signature `json:"signatures"`
+}
+
+// AttendeeFreeBusy holds one attendee's free/busy slots. Each character of Slots
+// covers FreeBusyResponse.Interval minutes: 0 free, 1 tentative, 2 busy,
+// 3 out of office, 4 working elsewhere.
+type AttendeeFreeBusy struct {
+	Attendee string `json:"attendee"`
+	Resolved bool   `json:"resolved"`
+	Name     string `json:"name,omitempty"`
+	Email    string `json:"email,omitempty"`
+	Slots    string `json:"slots,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// FreeBusyResponse represents the response from the /freebusy endpoint. Start is
+// the beginning of the first slot, at or up to one interval before the requested start.
+type FreeBusyResponse struct {
+	Start     time.Time          `json:"start"`
+	End       time.Time          `json:"end"`
+	Interval  int                `json:"interval"` // Minutes per slot
+	Attendees []AttendeeFreeBusy `json:"attendees"`
+}
+
+// FreeBusyBlock is a run of consecutive slots sharing a busy status
+type FreeBusyBlock struct {
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	Status string    `json:"status"` // One of the BusyStatus constants, or "unknown"
+}
+
+// CalendarEvent represents an Outlook calendar appointment or meeting. Occurrences
+// of a recurring series share the series ID and differ by Start.
+type CalendarEvent struct {
+	ID          string    `json:"id"`
+	Subject     string    `json:"subject"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	Location    string    `json:"location,omitempty"`
+	Organizer   string    `json:"organizer,omitempty"`
+	AllDay      bool      `json:"allDay"`
+	IsRecurring bool      `json:"isRecurring"`
+	BusyStatus  string    `json:"busyStatus"`
+	Categories  []string  `json:"categories,omitempty"`
+
+	// Only populated by the GET /calendar/events/{id} endpoint
+	Body              string      `json:"body,omitempty"`
+	RequiredAttendees []string    `json:"requiredAttendees,omitempty"`
+	OptionalAttendees []string    `json:"optionalAttendees,omitempty"`
+	IsMeeting         bool        `json:"isMeeting,omitempty"`
+	ReminderMinutes   *int        `json:"reminderMinutes,omitempty"`
+	Recurrence        *Recurrence `json:"recurrence,omitempty"`
+}
+
+// Recurrence describes the pattern of a recurring series
+type Recurrence struct {
+	Type         string     `json:"type"` // daily, weekly, monthly or yearly
+	Interval     int        `json:"interval"`
+	PatternStart time.Time  `json:"patternStart"`
+	PatternEnd   *time.Time `json:"patternEnd,omitempty"` // Nil when the series has no end date
+}
+
+// CalendarEventListResponse represents the response from the GET /calendar/events endpoint
+type CalendarEventListResponse struct {
+	Start     time.Time       `json:"start"`
+	End       time.Time       `json:"end"`
+	Events    []CalendarEvent `json:"events"`
+	Count     int             `json:"count"`
+	Truncated bool            `json:"truncated"`
+}
+
+// Rule represents an Outlook mail rule. Conditions and actions are human-readable
+// descriptions of the enabled clauses, e.g. "Subject contains: invoice".
+type Rule struct {
+	Name           string   `json:"name"`
+	Enabled        bool     `json:"enabled"`
+	ExecutionOrder int      `json:"executionOrder"`
+	Type           string   `json:"type"`    // receive or send
+	IsLocal        bool     `json:"isLocal"` // Runs only in this Outlook client rather than on the server
+	Conditions     []string `json:"conditions"`
+	Actions        []string `json:"actions"`
+}
+
+// RuleListResponse represents the response from the GET /rules endpoint
+type RuleListResponse struct {
+	Rules []Rule `json:"rules"`
+	Count int    `json:"count"`
+}
+
+// Category represents an entry in the Outlook master category list
+type Category struct {
+	Name        string `json:"name"`
+	Color       string `json:"color"`
+	ShortcutKey string `json:"shortcutKey,omitempty"`
+}
+
+// CategoryListResponse represents the response from the GET /categories endpoint
+type CategoryListResponse struct {
+	Categories []Category `json:"categories"`
+	Count      int        `json:"count"`
+}
+
+// Contact represents an entry in the Outlook Contacts folder
+type Contact struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	Emails     []string          `json:"emails,omitempty"`
+	Phones     map[string]string `json:"phones,omitempty"` // Keyed by business, mobile and home
+	Company    string            `json:"company,omitempty"`
+	Department string            `json:"department,omitempty"`
+	JobTitle   string            `json:"jobTitle,omitempty"`
+
+	// Only populated by the GET /contacts/{id} endpoint
+	BusinessAddress string   `json:"businessAddress,omitempty"`
+	HomeAddress     string   `json:"homeAddress,omitempty"`
+	Notes           string   `json:"notes,omitempty"`
+	Categories      []string `json:"categories,omitempty"`
+}
+
+// ContactSearchResponse represents the response from the GET /contacts endpoint
+type ContactSearchResponse struct {
+	Query     string    `json:"query,omitempty"`
+	Contacts  []Contact `json:"contacts"`
+	Count     int       `json:"count"`
+	Truncated bool      `json:"truncated"`
+}
+
+// Recipient types reported by ResolveRecipient
+const (
+	RecipientUser             = "user"
+	RecipientDistributionList = "distribution_list"
+	RecipientContact          = "contact" // An entry in the user's own contacts
+	RecipientOther            = "other"
+)
+
+// RecipientEntry is a person or list found in an address book
+type RecipientEntry struct {
+	Name    string `json:"name"`
+	Address string `json:"address"` // SMTP address
+	Type    string `json:"type"`
+}
+
+// RecipientResolution represents the response from the GET /recipients/resolve endpoint
+type RecipientResolution struct {
+	Query            string           `json:"query"`
+	Resolved         bool             `json:"resolved"`
+	Name             string           `json:"name,omitempty"`
+	Address          string           `json:"address,omitempty"`
+	Type             string           `json:"type,omitempty"`
+	Candidates       []RecipientEntry `json:"candidates,omitempty"`  // Possible matches when the name is ambiguous
+	Members          []RecipientEntry `json:"members,omitempty"`     // People in an expanded list, nested lists included
+	NestedLists      []RecipientEntry `json:"nestedLists,omitempty"` // Lists expanded within the list
+	MembersTruncated bool             `json:"membersTruncated"`
+	Note             string           `json:"note,omitempty"`
+}
+
+// SidecarHealth represents the response from the GET /health endpoint
+type SidecarHealth struct {
+	PID               int        `json:"pid"`
+	StartedAt         time.Time  `json:"startedAt"`
+	PowerShellVersion string     `json:"powershellVersion"`
+	OutlookConnected  bool       `json:"outlookConnected"`
+	OutlookVersion    string     `json:"outlookVersion,omitempty"`
+	Profile           string     `json:"profile,omitempty"`
+	ConnectionMode    string     `json:"connectionMode,omitempty"` // online, cached, offline, disconnected or none
+	OutlookError      string     `json:"outlookError,omitempty"`
+	Reconnects        int        `json:"reconnects"` // COM re-binds after Outlook was restarted, automatic or requested
+	LastReconnectAt   *time.Time `json:"lastReconnectAt,omitempty"`
+}
+
+// SidecarVersion represents the response from the GET /version endpoint
+type SidecarVersion struct {
+	ScriptSHA256 string    `json:"scriptSha256"` // Hash of the running script, as scriptHash computes it
+	PID          int       `json:"pid"`
+	StartedAt    time.Time `json:"startedAt"`
+}
+
+// GraphHealth represents the result of probing Microsoft Graph as the signed-in user
+type GraphHealth struct {
+	Reachable bool   `json:"reachable"`
+	User      string `json:"user,omitempty"`
+	Email     string `json:"email,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// HealthStatus combines the PowerShell server's own report with the
+// supervisor's restart and error history. With the Graph backend only Graph
+// and the request error fields are set.
+type HealthStatus struct {
+	Backend             string         `json:"backend"` // com or graph
+	Graph               *GraphHealth   `json:"graph,omitempty"`
+	SidecarReachable    bool           `json:"sidecarReachable"`
+	SidecarError        string         `json:"sidecarError,omitempty"`
+	Sidecar             *SidecarHealth `json:"sidecar,omitempty"`
+	Restarting          bool           `json:"restarting"` // The supervisor is replacing a crashed process
+	ManagerStartedAt    time.Time      `json:"managerStartedAt"`
+	LastRestart         *time.Time     `json:"lastRestart,omitempty"`
+	Restarts            int            `json:"restarts"`
+	FailedRestarts      int            `json:"failedRestarts"`
+	ProcessExits        int            `json:"processExits"`
+	RecentRequestErrors int            `json:"recentRequestErrors"` // Within the last 15 minutes
+	LastError           string         `json:"lastError,omitempty"`
+	LastErrorAt         *time.Time     `json:"lastErrorAt,omitempty"`
+}
+
+// ServerLogLine is one line of PowerShell server output or a supervisor event
+type ServerLogLine struct {
+	Time   time.Time `json:"time"`
+	Stream string    `json:"stream"` // stdout, stderr or supervisor
+	Text   string    `json:"text"`
+}
+
+// ServerLogsResponse holds the most recent captured server log lines, oldest first
+type ServerLogsResponse struct {
+	Lines    []ServerLogLine `json:"lines"`
+	Matched  int             `json:"matched"`  // Lines matching the filters before the limit was applied
+	Capacity int             `json:"capacity"` // Lines the buffer holds
+	Dropped  int             `json:"dropped"`  // Older lines discarded since the manager started
 }
 
 // ErrorResponse represents an error response from the PowerShell server
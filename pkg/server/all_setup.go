@@ -0,0 +1,125 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/kevsmith/my-mcp/pkg/document"
+	"github.com/kevsmith/my-mcp/pkg/excel"
+	"github.com/kevsmith/my-mcp/pkg/filesystem"
+	"github.com/kevsmith/my-mcp/pkg/outlook"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// toolRegistrar receives a toolset's tools: a server of its own, or a
+// prefixing view of the combined server
+type toolRegistrar interface {
+	AddTool(tool mcp.Tool, handler server.ToolHandlerFunc)
+}
+
+// prefixedTools adds tools to a server under prefixed names, so toolsets
+// sharing a server can't collide
+type prefixedTools struct {
+	server *server.MCPServer
+	prefix string
+}
+
+func (p prefixedTools) AddTool(tool mcp.Tool, handler server.ToolHandlerFunc) {
+	tool.Name = p.prefix + tool.Name
+	p.server.AddTool(tool, handler)
+}
+
+// Global manager references for cleanup
+var (
+	allExcelManager      *excel.Manager
+	allDocumentManager   *document.Manager
+	allFilesystemHandler *filesystem.Handler
+	allOutlookManager    outlook.Backend
+)
+
+// OutlookAvailable reports why the Outlook toolset can't run on this
+// platform, or nil when it can: the COM backend drives desktop Outlook,
+// which only runs on Windows
+func OutlookAvailable() error {
+	backend := os.Getenv("OUTLOOK_BACKEND")
+	if (backend == "" || backend == outlook.BackendCOM) && runtime.GOOS != "windows" {
+		return fmt.Errorf("outlook-mcp with the com backend is only supported on Windows; use --backend=graph elsewhere")
+	}
+	return nil
+}
+
+// AllSetup creates one server hosting every toolset, with tool names
+// prefixed by their toolset: excel_, document_, fs_ and outlook_. The
+// filesystem tools need allowed roots and are left out without them; the
+// Excel and document tools are restricted to the same roots. The Outlook tools are
+// added when includeOutlook is set. server_info, describing the whole server,
+// is the one tool without a prefix.
+func AllSetup(allowedRoots []string, includeOutlook bool) (*server.MCPServer, error) {
+	info := newServerInfo("my-mcp", "1.0.0")
+	hooks := &server.Hooks{}
+	mcpServer := server.NewMCPServer(info.name, info.version,
+		server.WithToolCapabilities(true),
+		server.WithResourceCapabilities(false, true),
+		server.WithLogging(),
+		withMetrics(),
+		withLimits(),
+		withResponseLimit(),
+		withRecovery(),
+		server.WithHooks(hooks),
+	)
+
+	excelManager, err := addExcelTools(mcpServer, prefixedTools{mcpServer, "excel_"}, allowedRoots)
+	if err != nil {
+		return nil, err
+	}
+	allExcelManager = excelManager
+	info.toolsets["excel"] = excelSettings(allowedRoots)
+
+	documentManager, err := addDocumentTools(prefixedTools{mcpServer, "document_"}, allowedRoots)
+	if err != nil {
+		return nil, err
+	}
+	allDocumentManager = documentManager
+	info.toolsets["document"] = documentSettings(allowedRoots)
+
+	if len(allowedRoots) > 0 {
+		fsHandler, err := addFilesystemTools(prefixedTools{mcpServer, "fs_"}, hooks, allowedRoots)
+		if err != nil {
+			return nil, err
+		}
+		allFilesystemHandler = fsHandler
+		info.toolsets["filesystem"] = filesystemSettings(allowedRoots)
+	}
+
+	if includeOutlook {
+		manager, err := addOutlookTools(prefixedTools{mcpServer, "outlook_"})
+		if err != nil {
+			return nil, fmt.Errorf("failed to start Outlook toolset: %w", err)
+		}
+		allOutlookManager = manager
+		info.toolsets["outlook"] = outlookSettings(manager)
+	}
+
+	addServerInfoTool(mcpServer, info)
+	return mcpServer, nil
+}
+
+// ShutdownAll gracefully shuts down the managers used by the combined server
+// and removes its filesystem workspaces
+func ShutdownAll() error {
+	if allExcelManager != nil {
+		allExcelManager.Close()
+	}
+	if allDocumentManager != nil {
+		allDocumentManager.Close()
+	}
+	if allFilesystemHandler != nil {
+		allFilesystemHandler.Close()
+	}
+	if allOutlookManager != nil {
+		return allOutlookManager.Stop()
+	}
+	return nil
+}
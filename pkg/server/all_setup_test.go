@@ -0,0 +1,63 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestAllSetupPrefixesTools(t *testing.T) {
+	root := t.TempDir()
+	s, err := AllSetup([]string{root}, false)
+	if err != nil {
+		t.Fatalf("AllSetup failed: %v", err)
+	}
+	defer ShutdownAll()
+
+	response := s.HandleMessage(context.Background(), json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+	result, ok := response.(mcp.JSONRPCResponse).Result.(mcp.ListToolsResult)
+	if !ok {
+		t.Fatalf("Unexpected tools/list response: %#v", response)
+	}
+	names := make(map[string]bool)
+	for _, tool := range result.Tools {
+		names[tool.Name] = true
+		if tool.Name == "server_info" {
+			continue // Describes the whole server
+		}
+		if !strings.HasPrefix(tool.Name, "excel_") && !strings.HasPrefix(tool.Name, "document_") && !strings.HasPrefix(tool.Name, "fs_") {
+			t.Errorf("Tool %s has no toolset prefix", tool.Name)
+		}
+	}
+	for _, name := range []string{"excel_get_cell_value", "document_extract_text", "fs_read_file"} {
+		if !names[name] {
+			t.Errorf("Expected tool %s", name)
+		}
+	}
+
+	// Prefixed tools run the toolset's handler
+	call := `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"fs_get_current_directory","arguments":{}}}`
+	response = s.HandleMessage(context.Background(), json.RawMessage(call))
+	callResult, ok := response.(mcp.JSONRPCResponse).Result.(mcp.CallToolResult)
+	if !ok || callResult.IsError || len(callResult.Content) == 0 || !strings.Contains(callResult.Content[0].(mcp.TextContent).Text, root) {
+		t.Fatalf("Unexpected tools/call response: %#v", response)
+	}
+}
+
+func TestAllSetupWithoutRoots(t *testing.T) {
+	s, err := AllSetup(nil, false)
+	if err != nil {
+		t.Fatalf("AllSetup failed: %v", err)
+	}
+	defer ShutdownAll()
+
+	response := s.HandleMessage(context.Background(), json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+	for _, tool := range response.(mcp.JSONRPCResponse).Result.(mcp.ListToolsResult).Tools {
+		if strings.HasPrefix(tool.Name, "fs_") {
+			t.Errorf("Filesystem tool %s registered without roots", tool.Name)
+		}
+	}
+}
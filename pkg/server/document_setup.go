@@ -1,21 +1,91 @@
 package server
 
 import (
+	"fmt"
+
 	"github.com/kevsmith/my-mcp/pkg/document"
 	"github.com/mark3labs/mcp-go/server"
 )
 
-func DocumentSetup() *server.MCPServer {
-	documentManager := document.NewManager()
+// Global document manager reference for cleanup
+var documentServerManager *document.Manager
+
+// DocumentSetup creates the document server; with allowed roots, tools only
+// read and write paths under them. External converters are registered from
+// the file named by DOCUMENT_CONVERTERS.
+func DocumentSetup(allowedRoots []string) (*server.MCPServer, error) {
+	info := newServerInfo("document-mcp", "1.0.0")
+	mcpServer := server.NewMCPServer(info.name, info.version,
+		server.WithToolCapabilities(true),
+		withMetrics(),
+		withLimits(),
+		withResponseLimit(),
+		withRecovery(),
+	)
+	manager, err := addDocumentTools(mcpServer, allowedRoots)
+	if err != nil {
+		return nil, err
+	}
+	documentServerManager = manager
+	info.toolsets["document"] = documentSettings(allowedRoots)
+	addServerInfoTool(mcpServer, info)
+	return mcpServer, nil
+}
 
-	handlers := document.NewHandlers(documentManager)
+// ShutdownDocument stops the document manager's cache cleanup and cancels
+// background extractions
+func ShutdownDocument() error {
+	if documentServerManager != nil {
+		documentServerManager.Close()
+	}
+	return nil
+}
+
+// addDocumentTools registers the document tools with tools
+func addDocumentTools(tools toolRegistrar, allowedRoots []string) (*document.Manager, error) {
+	documentManager := document.NewManager()
+	serverMetrics.registerCache("document", documentManager.CacheStats)
 
-	mcpServer := server.NewMCPServer("document-mcp", "1.0.0", server.WithToolCapabilities(true))
+	converters, err := document.GetConverters()
+	if err != nil {
+		return nil, err
+	}
+	for _, converter := range converters {
+		if err := documentManager.RegisterConverter(converter); err != nil {
+			return nil, err
+		}
+	}
 
-	toolDefs := document.GetToolDefinitions()
+	handlers, err := document.NewHandlersWithRoots(documentManager, allowedRoots)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create document handlers: %w", err)
+	}
 
-	mcpServer.AddTool(toolDefs[0], handlers.ExtractText)
-	mcpServer.AddTool(toolDefs[1], handlers.GetDocumentInfo)
+	err = registerTools(tools, document.GetToolDefinitions(), toolHandlers{
+		"extract_text":           handlers.ExtractText,
+		"get_document_info":      handlers.GetDocumentInfo,
+		"convert_corpus":         handlers.ConvertCorpus,
+		"extract_pages":          handlers.ExtractPages,
+		"extract_tables":         handlers.ExtractTables,
+		"get_outline":            handlers.GetOutline,
+		"search_document":        handlers.SearchDocument,
+		"list_images":            handlers.ListImages,
+		"extract_image":          handlers.ExtractImage,
+		"extract_slides":         handlers.ExtractSlides,
+		"extract_review":         handlers.ExtractReview,
+		"convert_to_markdown":    handlers.ConvertToMarkdown,
+		"analyze_document":       handlers.AnalyzeDocument,
+		"extract_from_url":       handlers.ExtractFromURL,
+		"get_form_fields":        handlers.GetFormFields,
+		"start_extraction":       handlers.StartExtraction,
+		"get_extraction_status":  handlers.GetExtractionStatus,
+		"get_extraction_chunk":   handlers.GetExtractionChunk,
+		"get_embedded_workbooks": handlers.GetEmbeddedWorkbooks,
+	})
+	if err != nil {
+		documentManager.Close()
+		return nil, fmt.Errorf("failed to register document tools: %w", err)
+	}
 
-	return mcpServer
+	return documentManager, nil
 }
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kevsmith/my-mcp/pkg/shared"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestErrorEnvelopeAcrossToolsets(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "notes.xyz"), []byte{0, 1, 2, 3}, 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := AllSetup([]string{root}, false)
+	if err != nil {
+		t.Fatalf("AllSetup failed: %v", err)
+	}
+	defer ShutdownAll()
+
+	tests := []struct {
+		tool string
+		args map[string]any
+		code string
+	}{
+		{"fs_read_file", map[string]any{"path": filepath.Join(filepath.Dir(root), "outside.txt")}, shared.CodeAccessDenied},
+		{"document_extract_text", map[string]any{}, shared.CodeInvalidArgument},
+		{"document_extract_text", map[string]any{"file_path": filepath.Join(root, "notes.xyz")}, shared.CodeUnsupportedFormat},
+		{"excel_list_sheets", map[string]any{"file_path": filepath.Join(root, "missing.xlsx")}, shared.CodeNotFound},
+	}
+	for i, test := range tests {
+		params, _ := json.Marshal(map[string]any{"name": test.tool, "arguments": test.args})
+		call := fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"tools/call","params":%s}`, i+1, params)
+		response := s.HandleMessage(context.Background(), json.RawMessage(call))
+		result, ok := response.(mcp.JSONRPCResponse).Result.(mcp.CallToolResult)
+		if !ok || !result.IsError {
+			t.Errorf("%s: expected an error result, got %#v", test.tool, response)
+			continue
+		}
+
+		var envelope shared.ErrorEnvelope
+		text := result.Content[0].(mcp.TextContent).Text
+		if err := json.Unmarshal([]byte(text), &envelope); err != nil || envelope.Error == nil {
+			t.Errorf("%s: error result is not an envelope: %s", test.tool, text)
+			continue
+		}
+		if envelope.Error.Code != test.code {
+			t.Errorf("%s: code %s, want %s: %s", test.tool, envelope.Error.Code, test.code, envelope.Error.Message)
+		}
+	}
+}
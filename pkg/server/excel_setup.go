@@ -1,36 +1,89 @@
 package server
 
 import (
+	"fmt"
+
 	"github.com/kevsmith/my-mcp/pkg/excel"
 	"github.com/mark3labs/mcp-go/server"
 )
 
-// ExcelSetup creates and configures the MCP server with all excel tools
-func ExcelSetup() *server.MCPServer {
+// Global Excel manager reference for cleanup
+var excelServerManager *excel.Manager
+
+// ExcelSetup creates and configures the MCP server with all excel tools; with
+// allowed roots, tools only open workbooks under them
+func ExcelSetup(allowedRoots []string) (*server.MCPServer, error) {
+	// Create MCP server
+	info := newServerInfo("excel-mcp", "1.0.0")
+	mcpServer := server.NewMCPServer(info.name, info.version,
+		server.WithToolCapabilities(true),
+		server.WithResourceCapabilities(false, true),
+		withMetrics(),
+		withLimits(),
+		withResponseLimit(),
+		withRecovery(),
+	)
+	manager, err := addExcelTools(mcpServer, mcpServer, allowedRoots)
+	if err != nil {
+		return nil, err
+	}
+	excelServerManager = manager
+	info.toolsets["excel"] = excelSettings(allowedRoots)
+	addServerInfoTool(mcpServer, info)
+
+	return mcpServer, nil
+}
+
+// ShutdownExcel stops the Excel manager's cache cleanup and closes the
+// workbooks it has open
+func ShutdownExcel() error {
+	if excelServerManager != nil {
+		excelServerManager.Close()
+	}
+	return nil
+}
+
+// addExcelTools registers the Excel tools with tools, restricted to
+// allowedRoots when there are any, and publishes exported ranges as
+// resources of mcpServer
+func addExcelTools(mcpServer *server.MCPServer, tools toolRegistrar, allowedRoots []string) (*excel.Manager, error) {
 	// Create Excel manager
 	excelManager := excel.NewManager()
+	serverMetrics.registerCache("excel", excelManager.CacheStats)
 
 	// Create tool handlers
-	handlers := excel.NewHandlers(excelManager)
+	handlers, err := excel.NewHandlersWithRoots(excelManager, allowedRoots)
+	if err != nil {
+		excelManager.Close()
+		return nil, err
+	}
 
-	// Create MCP server
-	mcpServer := server.NewMCPServer("excel-mcp", "1.0.0", server.WithToolCapabilities(true))
-
-	// Get tool definitions
-	toolDefs := excel.GetToolDefinitions()
+	// Exported ranges are published as resources on this server
+	handlers.EnableResourceExports(mcpServer)
 
 	// Register all tools with their handlers
-	mcpServer.AddTool(toolDefs[0], handlers.EnumerateColumns)
-	mcpServer.AddTool(toolDefs[1], handlers.EnumerateRows)
-	mcpServer.AddTool(toolDefs[2], handlers.GetCellValue)
-	mcpServer.AddTool(toolDefs[3], handlers.GetRangeValues)
-	mcpServer.AddTool(toolDefs[4], handlers.ListSheets)
-	mcpServer.AddTool(toolDefs[5], handlers.SetCurrentSheet)
-	mcpServer.AddTool(toolDefs[6], handlers.GetColumn)
-	mcpServer.AddTool(toolDefs[7], handlers.GetRow)
-	mcpServer.AddTool(toolDefs[8], handlers.GetSheetStats)
-	mcpServer.AddTool(toolDefs[9], handlers.FlushCache)
-	mcpServer.AddTool(toolDefs[10], handlers.ExplainFormula)
-
-	return mcpServer
+	err = registerTools(tools, excel.GetToolDefinitions(), toolHandlers{
+		"enumerate_columns":        handlers.EnumerateColumns,
+		"enumerate_rows":           handlers.EnumerateRows,
+		"get_cell_value":           handlers.GetCellValue,
+		"get_range_values":         handlers.GetRangeValues,
+		"list_sheets":              handlers.ListSheets,
+		"set_current_sheet":        handlers.SetCurrentSheet,
+		"get_column":               handlers.GetColumn,
+		"get_row":                  handlers.GetRow,
+		"get_sheet_stats":          handlers.GetSheetStats,
+		"flush_cache":              handlers.FlushCache,
+		"explain_formula":          handlers.ExplainFormula,
+		"explain_formulas_sheet":   handlers.ExplainFormulasSheet,
+		"audit_sheet":              handlers.AuditSheet,
+		"get_sheet_layout":         handlers.GetSheetLayout,
+		"get_protection":           handlers.GetProtection,
+		"export_range_as_resource": handlers.ExportRangeAsResource,
+	})
+	if err != nil {
+		excelManager.Close()
+		return nil, fmt.Errorf("failed to register Excel tools: %w", err)
+	}
+
+	return excelManager, nil
 }
@@ -5,8 +5,10 @@ import (
 )
 
 func TestSetup(t *testing.T) {
-	server := ExcelSetup()
-
+	server, err := ExcelSetup(nil)
+	if err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
 	if server == nil {
 		t.Fatal("Setup returned nil server")
 	}
@@ -16,18 +18,34 @@ func TestSetup(t *testing.T) {
 }
 
 func TestSetupCreatesServer(t *testing.T) {
-	server := ExcelSetup()
-
+	server, err := ExcelSetup(nil)
+	if err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
 	if server == nil {
 		t.Fatal("Setup returned nil server")
 	}
 
 	// Test that ExcelSetup() consistently returns a server instance
-	server2 := ExcelSetup()
-	if server2 == nil {
+	server2, err := ExcelSetup(nil)
+	if err != nil || server2 == nil {
 		t.Fatal("Second call to Setup returned nil server")
 	}
 
 	// Both calls should return valid server instances
 	// (they will be different instances, which is expected)
 }
+
+func TestShutdownExcel(t *testing.T) {
+	if _, err := ExcelSetup(nil); err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+	if err := ShutdownExcel(); err != nil {
+		t.Errorf("ShutdownExcel failed: %v", err)
+	}
+
+	// A second shutdown, or one after the manager is closed, does nothing
+	if err := ShutdownExcel(); err != nil {
+		t.Errorf("Second ShutdownExcel failed: %v", err)
+	}
+}
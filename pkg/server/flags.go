@@ -0,0 +1,160 @@
+package server
+
+import (
+	"flag"
+	"os"
+	"strconv"
+
+	"github.com/kevsmith/my-mcp/pkg/shared"
+)
+
+// Each server's settings are read from environment variables by its
+// package. The flag functions register a server's command line flags and
+// return a function, to call after parsing, that exports the flags given to
+// those variables.
+
+// DocumentFlags registers the document server's flags
+func DocumentFlags(flags *flag.FlagSet) func() {
+	var cacheSize, cacheTTLMinutes, maxFileSizeMB, timeoutSeconds int
+	var convertersPath string
+	var allowInternalURLs bool
+	flags.IntVar(&cacheSize, "cache-size", 0, "Maximum number of documents to keep extraction results for (default: 20, env: DOCUMENT_CACHE_MAX_SIZE)")
+	flags.IntVar(&cacheTTLMinutes, "cache-ttl", 0, "Cache TTL in minutes (default: 10, env: DOCUMENT_CACHE_TTL_MINUTES)")
+	flags.IntVar(&maxFileSizeMB, "max-file-size", 0, "Largest document in MB accepted for extraction (default: 100, env: DOCUMENT_MAX_FILE_SIZE_MB)")
+	flags.IntVar(&timeoutSeconds, "timeout", 0, "Extraction timeout in seconds (default: 60, env: DOCUMENT_EXTRACT_TIMEOUT_SECONDS)")
+	flags.StringVar(&convertersPath, "converters", "", "JSON file of external converter commands for formats without a built-in extractor (env: DOCUMENT_CONVERTERS)")
+	flags.BoolVar(&allowInternalURLs, "allow-internal-urls", false, "Let extract_from_url fetch loopback, private and link-local addresses (env: DOCUMENT_ALLOW_INTERNAL_URLS)")
+
+	return func() {
+		setPositiveEnv("DOCUMENT_CACHE_MAX_SIZE", cacheSize)
+		setPositiveEnv("DOCUMENT_CACHE_TTL_MINUTES", cacheTTLMinutes)
+		setPositiveEnv("DOCUMENT_MAX_FILE_SIZE_MB", maxFileSizeMB)
+		setPositiveEnv("DOCUMENT_EXTRACT_TIMEOUT_SECONDS", timeoutSeconds)
+		if convertersPath != "" {
+			os.Setenv("DOCUMENT_CONVERTERS", convertersPath)
+		}
+		if allowInternalURLs {
+			os.Setenv("DOCUMENT_ALLOW_INTERNAL_URLS", "true")
+		}
+	}
+}
+
+// ExcelFlags registers the Excel server's flags
+func ExcelFlags(flags *flag.FlagSet) func() {
+	var cacheSize, cacheTTLMinutes int
+	flags.IntVar(&cacheSize, "cache-size", 0, "Maximum number of Excel files to cache (default: 10, env: EXCEL_CACHE_MAX_SIZE)")
+	flags.IntVar(&cacheTTLMinutes, "cache-ttl", 0, "Cache TTL in minutes (default: 5, env: EXCEL_CACHE_TTL_MINUTES)")
+
+	return func() {
+		setPositiveEnv("EXCEL_CACHE_MAX_SIZE", cacheSize)
+		setPositiveEnv("EXCEL_CACHE_TTL_MINUTES", cacheTTLMinutes)
+	}
+}
+
+// FilesystemFlags registers the filesystem server's flags
+func FilesystemFlags(flags *flag.FlagSet) func() {
+	var maxBytes int64
+	var maxFiles int
+	var allowWrite bool
+	flags.Int64Var(&maxBytes, "max-bytes", 0, "Maximum total bytes of file content returned per session (default: unlimited, env: FS_QUOTA_MAX_BYTES)")
+	flags.IntVar(&maxFiles, "max-files", 0, "Maximum number of distinct files read per session (default: unlimited, env: FS_QUOTA_MAX_FILES)")
+	flags.BoolVar(&allowWrite, "allow-write", false, "Enable tools that write under the allowed roots, such as create_workspace, replace_in_file, apply_patch and sync_paths (env: FS_ALLOW_WRITE)")
+
+	return func() {
+		if maxBytes > 0 {
+			os.Setenv("FS_QUOTA_MAX_BYTES", strconv.FormatInt(maxBytes, 10))
+		}
+		setPositiveEnv("FS_QUOTA_MAX_FILES", maxFiles)
+		if allowWrite {
+			os.Setenv("FS_ALLOW_WRITE", "true")
+		}
+	}
+}
+
+// OutlookFlags registers the Outlook server's flags
+func OutlookFlags(flags *flag.FlagSet) func() {
+	var allowSend, allowPermanentDelete bool
+	var backend, saveRoots, instance, powershell, powershellArgs, serverScript string
+	flags.BoolVar(&allowSend, "allow-send", false, "Enable tools that send, reply to and forward email (env: OUTLOOK_ALLOW_SEND)")
+	flags.BoolVar(&allowPermanentDelete, "allow-permanent-delete", false, "Allow delete_message to permanently delete instead of moving to Deleted Items (env: OUTLOOK_ALLOW_PERMANENT_DELETE)")
+	flags.StringVar(&backend, "backend", "", "Mailbox backend: com (desktop Outlook, Windows only) or graph (Microsoft Graph) (env: OUTLOOK_BACKEND, default: com)")
+	flags.StringVar(&saveRoots, "save-roots", "", "Directories save_attachment, export_message and export_messages may write under and create_draft may attach from, separated like PATH (env: OUTLOOK_SAVE_ROOTS, default: write anywhere, attach nothing)")
+	flags.StringVar(&instance, "instance", "", "Name of a persistent PowerShell server that keeps running between outlook-mcp runs (env: OUTLOOK_INSTANCE, default: none)")
+	flags.StringVar(&powershell, "powershell", "", "PowerShell executable running the server, e.g. pwsh.exe for PowerShell 7 (env: OUTLOOK_POWERSHELL, default: powershell.exe)")
+	flags.StringVar(&powershellArgs, "powershell-args", "", "Extra PowerShell startup arguments, separated by spaces (env: OUTLOOK_POWERSHELL_ARGS)")
+	flags.StringVar(&serverScript, "server-script", "", "PowerShell server script to run instead of the embedded one (env: OUTLOOK_SERVER_SCRIPT)")
+
+	return func() {
+		if allowSend {
+			os.Setenv("OUTLOOK_ALLOW_SEND", "true")
+		}
+		if allowPermanentDelete {
+			os.Setenv("OUTLOOK_ALLOW_PERMANENT_DELETE", "true")
+		}
+		if backend != "" {
+			os.Setenv("OUTLOOK_BACKEND", backend)
+		}
+		if saveRoots != "" {
+			os.Setenv("OUTLOOK_SAVE_ROOTS", saveRoots)
+		}
+		if instance != "" {
+			os.Setenv("OUTLOOK_INSTANCE", instance)
+		}
+		if powershell != "" {
+			os.Setenv("OUTLOOK_POWERSHELL", powershell)
+		}
+		if powershellArgs != "" {
+			os.Setenv("OUTLOOK_POWERSHELL_ARGS", powershellArgs)
+		}
+		if serverScript != "" {
+			os.Setenv("OUTLOOK_SERVER_SCRIPT", serverScript)
+		}
+	}
+}
+
+// LimitFlags registers the tool call and response size limit flags shared
+// by all servers. Zero turns a limit off, so unlike other flags -1 marks one
+// as not given.
+func LimitFlags(flags *flag.FlagSet) func() {
+	maxConcurrent := flags.Int("max-concurrent", -1, "Maximum tool calls running at once, 0 for unlimited (default: 16, env: MCP_MAX_CONCURRENT)")
+	sessionMaxConcurrent := flags.Int("session-max-concurrent", -1, "Maximum tool calls running at once per client session, 0 for unlimited (default: 8, env: MCP_SESSION_MAX_CONCURRENT)")
+	sessionRate := flags.Int("session-rate", -1, "Maximum tool calls per minute per client session, 0 for unlimited (default: unlimited, env: MCP_SESSION_RATE)")
+	maxResponseBytes := flags.Int("max-response-bytes", -1, "Size in bytes tool results are truncated to, 0 for unlimited (default: 1048576, env: MCP_MAX_RESPONSE_BYTES)")
+
+	return func() {
+		for name, value := range map[string]int{
+			"MCP_MAX_CONCURRENT":         *maxConcurrent,
+			"MCP_SESSION_MAX_CONCURRENT": *sessionMaxConcurrent,
+			"MCP_SESSION_RATE":           *sessionRate,
+			"MCP_MAX_RESPONSE_BYTES":     *maxResponseBytes,
+		} {
+			if value >= 0 {
+				os.Setenv(name, strconv.Itoa(value))
+			}
+		}
+	}
+}
+
+// ConfigFlag registers the --config flag shared by all servers. The returned
+// function, called after the server's flags are applied, loads the
+// configuration file so that flags and environment variables take
+// precedence over it.
+func ConfigFlag(flags *flag.FlagSet) func() (*shared.Config, error) {
+	var path string
+	flags.StringVar(&path, "config", "", "YAML or TOML configuration file (default: my-mcp/config.yaml or config.toml in the user config directory, env: MY_MCP_CONFIG)")
+
+	return func() (*shared.Config, error) {
+		config, err := shared.LoadConfigFile(path)
+		if err == nil {
+			loadedConfigPath = config.Path
+		}
+		return config, err
+	}
+}
+
+// setPositiveEnv sets an environment variable when a flag was given a value
+func setPositiveEnv(name string, value int) {
+	if value > 0 {
+		os.Setenv(name, strconv.Itoa(value))
+	}
+}
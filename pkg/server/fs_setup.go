@@ -1,13 +1,51 @@
 package server
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/kevsmith/my-mcp/pkg/filesystem"
 	"github.com/mark3labs/mcp-go/server"
 )
 
+// Global handler reference for cleanup
+var fsHandler *filesystem.Handler
+
 func NewMCPServer(allowedRoots []string) (*server.MCPServer, error) {
+	info := newServerInfo("fs-mcp", "2.0.0") // Version bump for new interface
+	hooks := &server.Hooks{}
+	s := server.NewMCPServer(
+		info.name,
+		info.version,
+		server.WithLogging(),
+		withMetrics(),
+		withLimits(),
+		withResponseLimit(),
+		withRecovery(),
+		server.WithHooks(hooks),
+	)
+	handler, err := addFilesystemTools(s, hooks, allowedRoots)
+	if err != nil {
+		return nil, err
+	}
+	fsHandler = handler
+	info.toolsets["filesystem"] = filesystemSettings(allowedRoots)
+	addServerInfoTool(s, info)
+	return s, nil
+}
+
+// ShutdownFilesystem removes the workspaces made by the filesystem server
+func ShutdownFilesystem() error {
+	if fsHandler != nil {
+		fsHandler.Close()
+	}
+	return nil
+}
+
+// addFilesystemTools registers the filesystem tools with tools, restricted to
+// allowedRoots. The handler's per-session state is dropped through hooks when
+// a client session ends.
+func addFilesystemTools(s toolRegistrar, hooks *server.Hooks, allowedRoots []string) (*filesystem.Handler, error) {
 	if len(allowedRoots) == 0 {
 		return nil, fmt.Errorf("at least one allowed root directory is required")
 	}
@@ -16,25 +54,50 @@ func NewMCPServer(allowedRoots []string) (*server.MCPServer, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create filesystem handler: %w", err)
 	}
+	hooks.AddOnUnregisterSession(func(ctx context.Context, session server.ClientSession) {
+		handler.EndSession(session.SessionID())
+	})
 
-	s := server.NewMCPServer(
-		"fs-mcp",
-		"2.0.0", // Version bump for new interface
-		server.WithLogging(),
-	)
+	err = registerTools(s, filesystem.GetToolDefinitions(), toolHandlers{
+		// Navigation tools
+		"change_directory":      filesystem.ChangeDirectoryHandler(handler),
+		"get_current_directory": filesystem.GetCurrentDirectoryHandler(handler),
+		"get_directory_info":    filesystem.GetDirectoryInfoHandler(handler),
 
-	toolDefinitions := filesystem.GetToolDefinitions()
+		// File operation tools
+		"list_directory":  filesystem.ListDirectoryHandler(handler),
+		"read_file":       filesystem.ReadFileHandler(handler),
+		"get_file_info":   filesystem.GetFileInfoHandler(handler),
+		"glob":            filesystem.GlobHandler(handler),
+		"get_image_info":  filesystem.GetImageInfoHandler(handler),
+		"read_structured": filesystem.ReadStructuredHandler(handler),
+		"parse_log":       filesystem.ParseLogHandler(handler),
 
-	// Navigation tools
-	s.AddTool(toolDefinitions[0], filesystem.ChangeDirectoryHandler(handler))     // change_directory
-	s.AddTool(toolDefinitions[1], filesystem.GetCurrentDirectoryHandler(handler)) // get_current_directory
-	s.AddTool(toolDefinitions[2], filesystem.GetDirectoryInfoHandler(handler))    // get_directory_info
+		// Change tracking tools
+		"snapshot_directory": filesystem.SnapshotDirectoryHandler(handler),
+		"diff_snapshot":      filesystem.DiffSnapshotHandler(handler),
 
-	// File operation tools
-	s.AddTool(toolDefinitions[3], filesystem.ListDirectoryHandler(handler)) // list_directory
-	s.AddTool(toolDefinitions[4], filesystem.ReadFileHandler(handler))      // read_file
-	s.AddTool(toolDefinitions[5], filesystem.GetFileInfoHandler(handler))   // get_file_info
-	s.AddTool(toolDefinitions[6], filesystem.GlobHandler(handler))          // glob
+		// Session limit tools
+		"quota_status": filesystem.QuotaStatusHandler(handler),
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	return s, nil
+	// Tools that write are opt-in via --allow-write
+	if handler.WriteEnabled() {
+		err = registerTools(s, filesystem.GetWriteToolDefinitions(), toolHandlers{
+			// Workspace tools
+			"create_workspace": filesystem.CreateWorkspaceHandler(handler),
+
+			// Edit tools
+			"replace_in_file": filesystem.ReplaceInFileHandler(handler),
+			"apply_patch":     filesystem.ApplyPatchHandler(handler),
+			"sync_paths":      filesystem.SyncPathsHandler(handler),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return handler, nil
 }
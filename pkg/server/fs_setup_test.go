@@ -0,0 +1,147 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestFilesystemNavigationTools(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "reports"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewMCPServer([]string{root})
+	if err != nil {
+		t.Fatalf("NewMCPServer failed: %v", err)
+	}
+
+	response := s.HandleMessage(context.Background(), json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+	names := make(map[string]bool)
+	for _, tool := range response.(mcp.JSONRPCResponse).Result.(mcp.ListToolsResult).Tools {
+		names[tool.Name] = true
+	}
+	for _, name := range []string{"change_directory", "get_current_directory", "get_directory_info"} {
+		if !names[name] {
+			t.Errorf("Expected navigation tool %s to be listed", name)
+		}
+	}
+
+	call := func(id int, tool string, arguments map[string]any) string {
+		t.Helper()
+		params, _ := json.Marshal(map[string]any{"name": tool, "arguments": arguments})
+		message := fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"tools/call","params":%s}`, id, params)
+		result, ok := s.HandleMessage(context.Background(), json.RawMessage(message)).(mcp.JSONRPCResponse).Result.(mcp.CallToolResult)
+		if !ok || result.IsError {
+			t.Fatalf("%s failed: %+v", tool, result)
+		}
+		return result.Content[0].(mcp.TextContent).Text
+	}
+
+	call(2, "change_directory", map[string]any{"path": "reports"})
+	if dir := call(3, "get_current_directory", map[string]any{}); dir != filepath.Join(root, "reports") {
+		t.Errorf("get_current_directory = %s, want the reports directory", dir)
+	}
+	if info := call(4, "get_directory_info", map[string]any{}); !strings.Contains(info, "reports") {
+		t.Errorf("get_directory_info doesn't show the new directory: %s", info)
+	}
+}
+
+func TestFilesystemWriteToolsNeedAllowWrite(t *testing.T) {
+	listed := func() map[string]bool {
+		t.Helper()
+		s, err := NewMCPServer([]string{t.TempDir()})
+		if err != nil {
+			t.Fatalf("NewMCPServer failed: %v", err)
+		}
+		response := s.HandleMessage(context.Background(), json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+		names := make(map[string]bool)
+		for _, tool := range response.(mcp.JSONRPCResponse).Result.(mcp.ListToolsResult).Tools {
+			names[tool.Name] = true
+		}
+		return names
+	}
+	writeTools := []string{"create_workspace", "replace_in_file", "apply_patch", "sync_paths"}
+
+	t.Setenv("FS_ALLOW_WRITE", "")
+	names := listed()
+	for _, name := range writeTools {
+		if names[name] {
+			t.Errorf("Write tool %s listed without --allow-write", name)
+		}
+	}
+	if !names["read_file"] {
+		t.Error("Expected read_file to be listed")
+	}
+
+	t.Setenv("FS_ALLOW_WRITE", "true")
+	names = listed()
+	for _, name := range writeTools {
+		if !names[name] {
+			t.Errorf("Write tool %s not listed with --allow-write", name)
+		}
+	}
+}
+
+// testSession is a client session of the transport-independent tests
+type testSession struct {
+	id            string
+	notifications chan mcp.JSONRPCNotification
+}
+
+func (s testSession) Initialize()                                         {}
+func (s testSession) Initialized() bool                                   { return true }
+func (s testSession) NotificationChannel() chan<- mcp.JSONRPCNotification { return s.notifications }
+func (s testSession) SessionID() string                                   { return s.id }
+
+func TestFilesystemQuotaPerSession(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "notes.txt"), []byte("0123456789"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("FS_QUOTA_MAX_BYTES", "15")
+	s, err := NewMCPServer([]string{root})
+	if err != nil {
+		t.Fatalf("NewMCPServer failed: %v", err)
+	}
+
+	sessions := map[string]testSession{}
+	for _, id := range []string{"alice", "bob"} {
+		sessions[id] = testSession{id: id, notifications: make(chan mcp.JSONRPCNotification, 10)}
+		if err := s.RegisterSession(context.Background(), sessions[id]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	read := func(id string) mcp.CallToolResult {
+		t.Helper()
+		ctx := s.WithContext(context.Background(), sessions[id])
+		message := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"read_file","arguments":{"path":"notes.txt"}}}`
+		return s.HandleMessage(ctx, json.RawMessage(message)).(mcp.JSONRPCResponse).Result.(mcp.CallToolResult)
+	}
+
+	if result := read("alice"); result.IsError {
+		t.Fatalf("First read failed: %+v", result)
+	}
+	if result := read("alice"); !result.IsError {
+		t.Error("Expected alice's second read to exceed her quota")
+	}
+	// Another client has its own quota
+	if result := read("bob"); result.IsError {
+		t.Errorf("Expected bob's read to fit in his quota: %+v", result)
+	}
+
+	// A session's usage is dropped when it ends
+	s.UnregisterSession(context.Background(), "alice")
+	if err := s.RegisterSession(context.Background(), sessions["alice"]); err != nil {
+		t.Fatal(err)
+	}
+	if result := read("alice"); result.IsError {
+		t.Errorf("Expected a new session to start with a fresh quota: %+v", result)
+	}
+}
@@ -0,0 +1,161 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+
+	"github.com/kevsmith/my-mcp/pkg/document"
+	"github.com/kevsmith/my-mcp/pkg/excel"
+	"github.com/kevsmith/my-mcp/pkg/filesystem"
+	"github.com/kevsmith/my-mcp/pkg/outlook"
+	"github.com/kevsmith/my-mcp/pkg/shared"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// What the command set up at startup, reported by server_info
+var (
+	servedTransport  atomic.Pointer[TransportConfig] // Transport Serve is running
+	loadedConfigPath string                          // Configuration file loaded by ConfigFlag, if any
+)
+
+// serverInfo describes a server for its server_info tool. Setups record each
+// toolset they add with the settings it runs with.
+type serverInfo struct {
+	name     string
+	version  string
+	toolsets map[string]any
+}
+
+func newServerInfo(name, version string) *serverInfo {
+	return &serverInfo{name: name, version: version, toolsets: make(map[string]any)}
+}
+
+// serverInfoResponse is the JSON document returned by server_info
+type serverInfoResponse struct {
+	Name          string           `json:"name"`
+	Version       string           `json:"version"`
+	Build         buildInfo        `json:"build"`
+	Platform      string           `json:"platform"`
+	UptimeSeconds float64          `json:"uptime_seconds"`
+	Transport     *TransportConfig `json:"transport,omitempty"` // Absent until Serve runs
+	ConfigFile    string           `json:"config_file,omitempty"`
+	Toolsets      map[string]any   `json:"toolsets"`
+	Limits        LimitConfig      `json:"limits"`
+}
+
+// buildInfo identifies the binary from the Go build information
+type buildInfo struct {
+	GoVersion    string `json:"go_version"`
+	Module       string `json:"module,omitempty"`
+	Version      string `json:"version,omitempty"`
+	Revision     string `json:"revision,omitempty"`
+	RevisionTime string `json:"revision_time,omitempty"`
+	Modified     bool   `json:"modified,omitempty"` // Built from a tree with uncommitted changes
+}
+
+// readBuildInfo returns the build information embedded in the binary
+func readBuildInfo() buildInfo {
+	info := buildInfo{GoVersion: runtime.Version()}
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	info.Module, info.Version = build.Main.Path, build.Main.Version
+	for _, setting := range build.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.Revision = setting.Value
+		case "vcs.time":
+			info.RevisionTime = setting.Value
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+	return info
+}
+
+// addServerInfoTool registers the server_info tool describing info. It is
+// never prefixed, since it describes the whole server.
+func addServerInfoTool(s *server.MCPServer, info *serverInfo) {
+	tool := mcp.NewTool("server_info",
+		mcp.WithDescription("Describe this server as JSON: name, version, build (Go version, module version, VCS revision), platform, uptime, transport, config file, the toolsets it hosts with their settings (allowed roots, caches, size limits, Outlook backend and whether sending and permanent deletion are enabled), and tool call limits - use this when debugging or to check what the server allows"),
+		mcp.WithReadOnlyHintAnnotation(true),
+	)
+	s.AddTool(tool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		response := serverInfoResponse{
+			Name:          info.name,
+			Version:       info.version,
+			Build:         readBuildInfo(),
+			Platform:      runtime.GOOS + "/" + runtime.GOARCH,
+			UptimeSeconds: time.Since(serverMetrics.started).Seconds(),
+			Transport:     servedTransport.Load(),
+			ConfigFile:    loadedConfigPath,
+			Toolsets:      info.toolsets,
+			Limits:        GetLimitConfig(),
+		}
+
+		data, err := json.MarshalIndent(response, "", "  ")
+		if err != nil {
+			return shared.ErrorResultFromErr("Failed to encode server info", err), nil
+		}
+		return mcp.NewToolResultText(string(data)), nil
+	})
+}
+
+// excelSettings describes the Excel toolset's configuration
+func excelSettings(allowedRoots []string) map[string]any {
+	cache := excel.GetCacheConfig()
+	return map[string]any{
+		"allowed_roots":     allowedRoots, // Unrestricted when empty
+		"cache_size":        cache.MaxSize,
+		"cache_ttl_minutes": cache.DefaultTTL.Minutes(),
+	}
+}
+
+// documentSettings describes the document toolset's configuration
+func documentSettings(allowedRoots []string) map[string]any {
+	cache := document.GetCacheConfig()
+	limits := document.GetLimits()
+	return map[string]any{
+		"allowed_roots":       allowedRoots, // Unrestricted when empty
+		"cache_size":          cache.MaxSize,
+		"cache_ttl_minutes":   cache.DefaultTTL.Minutes(),
+		"max_file_size":       limits.MaxFileSize,
+		"timeout_seconds":     limits.Timeout.Seconds(),
+		"converters":          os.Getenv("DOCUMENT_CONVERTERS"),
+		"allow_internal_urls": limits.AllowInternalURLs,
+	}
+}
+
+// filesystemSettings describes the filesystem toolset's configuration
+func filesystemSettings(allowedRoots []string) map[string]any {
+	quota := filesystem.GetQuotaConfig()
+	return map[string]any{
+		"allowed_roots": allowedRoots,
+		"max_bytes":     quota.MaxBytes, // 0 is unlimited
+		"max_files":     quota.MaxFiles,
+		"allow_write":   filesystem.GetWriteEnabled(),
+	}
+}
+
+// outlookSettings describes the Outlook toolset's configuration
+func outlookSettings(manager outlook.Backend) map[string]any {
+	backend := os.Getenv("OUTLOOK_BACKEND")
+	if backend == "" {
+		backend = outlook.BackendCOM
+	}
+	return map[string]any{
+		"backend":                backend,
+		"allow_send":             manager.SendEnabled(),
+		"allow_permanent_delete": manager.PermanentDeleteEnabled(),
+		"save_roots":             outlook.GetSaveRoots(), // Unrestricted when empty
+		"instance":               os.Getenv("OUTLOOK_INSTANCE"),
+		"powershell":             outlook.GetPowerShellConfig().Executable,
+	}
+}
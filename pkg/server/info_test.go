@@ -0,0 +1,55 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestServerInfoTool(t *testing.T) {
+	t.Setenv("MCP_SESSION_RATE", "30")
+	root := t.TempDir()
+	s, err := AllSetup([]string{root}, false)
+	if err != nil {
+		t.Fatalf("AllSetup failed: %v", err)
+	}
+	defer ShutdownAll()
+
+	call := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"server_info","arguments":{}}}`
+	response := s.HandleMessage(context.Background(), json.RawMessage(call))
+	result, ok := response.(mcp.JSONRPCResponse).Result.(mcp.CallToolResult)
+	if !ok || result.IsError {
+		t.Fatalf("Unexpected server_info response: %#v", response)
+	}
+
+	var info struct {
+		Name  string `json:"name"`
+		Build struct {
+			GoVersion string `json:"go_version"`
+		} `json:"build"`
+		Toolsets map[string]map[string]any `json:"toolsets"`
+		Limits   LimitConfig               `json:"limits"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &info); err != nil {
+		t.Fatalf("server_info did not return JSON: %v", err)
+	}
+	if info.Name != "my-mcp" || info.Build.GoVersion == "" {
+		t.Errorf("Unexpected name or build: %+v", info)
+	}
+	for _, toolset := range []string{"excel", "document", "filesystem"} {
+		if _, ok := info.Toolsets[toolset]; !ok {
+			t.Errorf("Expected the %s toolset in %v", toolset, info.Toolsets)
+		}
+	}
+	if _, ok := info.Toolsets["outlook"]; ok {
+		t.Error("Outlook was left out but is reported")
+	}
+	if roots, _ := info.Toolsets["filesystem"]["allowed_roots"].([]any); len(roots) != 1 || roots[0] != root {
+		t.Errorf("Filesystem roots = %v, want [%s]", info.Toolsets["filesystem"]["allowed_roots"], root)
+	}
+	if info.Limits.SessionRate != 30 {
+		t.Errorf("Limits = %+v, want the configured session rate", info.Limits)
+	}
+}
@@ -0,0 +1,122 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kevsmith/my-mcp/pkg/mcptesting"
+	"github.com/kevsmith/my-mcp/pkg/shared"
+	"github.com/xuri/excelize/v2"
+)
+
+// writeWorkbook saves a small workbook with a value and a formula
+func writeWorkbook(t *testing.T, path string) {
+	t.Helper()
+	f := excelize.NewFile()
+	defer f.Close()
+	f.SetCellValue("Sheet1", "A1", "Revenue")
+	f.SetCellValue("Sheet1", "B1", 1200)
+	f.SetCellFormula("Sheet1", "B2", "B1*2")
+	if err := f.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExcelServerEndToEnd(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "budget.xlsx")
+	writeWorkbook(t, path)
+	s, err := ExcelSetup(nil)
+	if err != nil {
+		t.Fatalf("ExcelSetup failed: %v", err)
+	}
+
+	c := mcptesting.Start(t, s)
+	if info := c.ServerInfo(); info.Name != "excel-mcp" {
+		t.Errorf("Unexpected server info: %+v", info)
+	}
+	names := c.ToolNames()
+	for _, name := range []string{"list_sheets", "get_cell_value", "explain_formulas_sheet", "server_info"} {
+		if !names[name] {
+			t.Errorf("Expected tool %s", name)
+		}
+	}
+
+	if text := c.CallText("list_sheets", map[string]any{"file_path": path}); !strings.Contains(text, "Sheet1") {
+		t.Errorf("list_sheets: %s", text)
+	}
+	if text := c.CallText("get_cell_value", map[string]any{"file_path": path, "cell": "B1"}); !strings.Contains(text, "1200") {
+		t.Errorf("get_cell_value: %s", text)
+	}
+	c.CallError("get_cell_value", map[string]any{"file_path": filepath.Join(filepath.Dir(path), "missing.xlsx"), "cell": "A1"}, shared.CodeNotFound)
+}
+
+func TestDocumentServerEndToEnd(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "notes.txt")
+	if err := os.WriteFile(path, []byte("Quarterly planning notes"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := DocumentSetup([]string{root})
+	if err != nil {
+		t.Fatalf("DocumentSetup failed: %v", err)
+	}
+
+	c := mcptesting.Start(t, s)
+	if text := c.CallText("extract_text", map[string]any{"file_path": path}); !strings.Contains(text, "Quarterly planning") {
+		t.Errorf("extract_text: %s", text)
+	}
+	c.CallError("extract_text", map[string]any{"file_path": filepath.Join(filepath.Dir(root), "outside.txt")}, shared.CodeAccessDenied)
+}
+
+func TestFilesystemServerEndToEnd(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "readme.txt"), []byte("hello from the harness"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewMCPServer([]string{root})
+	if err != nil {
+		t.Fatalf("NewMCPServer failed: %v", err)
+	}
+
+	c := mcptesting.Start(t, s)
+	if text := c.CallText("read_file", map[string]any{"path": "readme.txt"}); !strings.Contains(text, "hello from the harness") {
+		t.Errorf("read_file: %s", text)
+	}
+	if text := c.CallText("list_directory", map[string]any{}); !strings.Contains(text, "readme.txt") {
+		t.Errorf("list_directory: %s", text)
+	}
+	c.CallError("read_file", map[string]any{"path": filepath.Join(filepath.Dir(root), "outside.txt")}, shared.CodeAccessDenied)
+}
+
+func TestAllServerEndToEnd(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "budget.xlsx")
+	writeWorkbook(t, path)
+	s, err := AllSetup([]string{root}, false)
+	if err != nil {
+		t.Fatalf("AllSetup failed: %v", err)
+	}
+	defer ShutdownAll()
+
+	c := mcptesting.Start(t, s)
+	if text := c.CallText("excel_list_sheets", map[string]any{"file_path": path}); !strings.Contains(text, "Sheet1") {
+		t.Errorf("excel_list_sheets: %s", text)
+	}
+	c.CallError("excel_list_sheets", map[string]any{"file_path": filepath.Join(filepath.Dir(root), "outside.xlsx")}, shared.CodeAccessDenied)
+	if text := c.CallText("fs_get_file_info", map[string]any{"path": "budget.xlsx"}); !strings.Contains(text, "budget.xlsx") {
+		t.Errorf("fs_get_file_info: %s", text)
+	}
+
+	var info struct {
+		Name     string         `json:"name"`
+		Toolsets map[string]any `json:"toolsets"`
+	}
+	c.CallJSON("server_info", map[string]any{}, &info)
+	for _, toolset := range []string{"excel", "document", "filesystem"} {
+		if info.Toolsets[toolset] == nil {
+			t.Errorf("server_info doesn't describe the %s toolset: %+v", toolset, info)
+		}
+	}
+}
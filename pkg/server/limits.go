@@ -0,0 +1,216 @@
+package server
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kevsmith/my-mcp/pkg/shared"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const (
+	limitQueueTimeout = 30 * time.Second // How long a call waits for a free slot before it is rejected
+	limitSessionIdle  = 10 * time.Minute // Idle sessions' limiter state is dropped after this long
+)
+
+// LimitConfig caps the tool calls a server runs at once, how fast each
+// client session may issue them and how large their results may be. Zero
+// values mean unlimited.
+type LimitConfig struct {
+	MaxConcurrent        int `json:"max_concurrent"`         // Calls running at once across all sessions
+	SessionMaxConcurrent int `json:"session_max_concurrent"` // Calls running at once per session
+	SessionRate          int `json:"session_rate"`           // Calls per minute per session
+	MaxResponseBytes     int `json:"max_response_bytes"`     // Serialized size results are truncated to
+}
+
+// GetLimitConfig returns limit configuration from environment variables or defaults
+func GetLimitConfig() LimitConfig {
+	config := LimitConfig{
+		MaxConcurrent:        16, // Default 16 calls at once; extraction and directory walks are memory hungry
+		SessionMaxConcurrent: 8,  // Default 8 calls at once per session
+		MaxResponseBytes:     1024 * 1024,
+	}
+
+	if value := os.Getenv("MCP_MAX_CONCURRENT"); value != "" {
+		if maxConcurrent, err := strconv.Atoi(value); err == nil && maxConcurrent >= 0 {
+			config.MaxConcurrent = maxConcurrent
+		}
+	}
+
+	if value := os.Getenv("MCP_SESSION_MAX_CONCURRENT"); value != "" {
+		if maxConcurrent, err := strconv.Atoi(value); err == nil && maxConcurrent >= 0 {
+			config.SessionMaxConcurrent = maxConcurrent
+		}
+	}
+
+	if value := os.Getenv("MCP_SESSION_RATE"); value != "" {
+		if rate, err := strconv.Atoi(value); err == nil && rate >= 0 {
+			config.SessionRate = rate
+		}
+	}
+
+	if value := os.Getenv("MCP_MAX_RESPONSE_BYTES"); value != "" {
+		if maxBytes, err := strconv.Atoi(value); err == nil && maxBytes >= 0 {
+			config.MaxResponseBytes = maxBytes
+		}
+	}
+
+	return config
+}
+
+// limiter enforces a LimitConfig on a server's tool calls. Calls over a
+// concurrency cap wait for a slot, up to limitQueueTimeout; calls over the
+// session rate are rejected at once. Both get a retryable RATE_LIMITED error.
+type limiter struct {
+	config   LimitConfig
+	global   chan struct{} // Semaphore; nil when unlimited
+	mutex    sync.Mutex
+	sessions map[string]*sessionLimit
+	pruned   time.Time
+}
+
+// sessionLimit is one session's concurrency slots and token bucket
+type sessionLimit struct {
+	slots    chan struct{} // Semaphore; nil when unlimited
+	tokens   float64
+	refilled time.Time
+	lastUsed time.Time
+}
+
+func newLimiter(config LimitConfig) *limiter {
+	l := &limiter{config: config, sessions: make(map[string]*sessionLimit), pruned: time.Now()}
+	if config.MaxConcurrent > 0 {
+		l.global = make(chan struct{}, config.MaxConcurrent)
+	}
+	return l
+}
+
+// withLimits is the server option that applies the configured limits to a
+// server's tool calls
+func withLimits() server.ServerOption {
+	return server.WithToolHandlerMiddleware(newLimiter(GetLimitConfig()).middleware)
+}
+
+// middleware admits each tool call under the limits or returns an error
+// result without running it
+func (l *limiter) middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		release, err := l.acquire(ctx, sessionID(ctx))
+		if err != nil {
+			return shared.ErrorResult(err), nil
+		}
+		defer release()
+		return next(ctx, request)
+	}
+}
+
+// acquire takes a rate token and concurrency slots for a call from session,
+// returning the function that gives the slots back
+func (l *limiter) acquire(ctx context.Context, session string) (func(), error) {
+	state, err := l.admit(session)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, limitQueueTimeout)
+	defer cancel()
+
+	if state.slots != nil {
+		select {
+		case state.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, l.waitError(ctx, "session")
+		}
+	}
+	if l.global != nil {
+		select {
+		case l.global <- struct{}{}:
+		case <-ctx.Done():
+			if state.slots != nil {
+				<-state.slots
+			}
+			return nil, l.waitError(ctx, "server")
+		}
+	}
+
+	return func() {
+		if l.global != nil {
+			<-l.global
+		}
+		if state.slots != nil {
+			<-state.slots
+		}
+	}, nil
+}
+
+// admit looks up the session's state and takes a token from its bucket
+func (l *limiter) admit(session string) (*sessionLimit, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.pruned) > time.Minute {
+		l.prune(now)
+	}
+
+	state, exists := l.sessions[session]
+	if !exists {
+		state = &sessionLimit{tokens: float64(l.config.SessionRate), refilled: now}
+		if l.config.SessionMaxConcurrent > 0 {
+			state.slots = make(chan struct{}, l.config.SessionMaxConcurrent)
+		}
+		l.sessions[session] = state
+	}
+	state.lastUsed = now
+
+	if l.config.SessionRate > 0 {
+		// The bucket holds a minute's worth of calls and refills continuously
+		capacity := float64(l.config.SessionRate)
+		state.tokens = min(capacity, state.tokens+now.Sub(state.refilled).Minutes()*capacity)
+		state.refilled = now
+		if state.tokens < 1 {
+			retryAfter := time.Duration((1 - state.tokens) / capacity * float64(time.Minute))
+			return nil, shared.NewError(shared.CodeRateLimited, "rate limit exceeded: at most %d tool calls per minute per session", l.config.SessionRate).
+				WithDetail("retry_after_ms", retryAfter.Milliseconds())
+		}
+		state.tokens--
+	}
+	return state, nil
+}
+
+// prune drops the state of sessions idle for limitSessionIdle with no calls
+// running
+func (l *limiter) prune(now time.Time) {
+	for session, state := range l.sessions {
+		if now.Sub(state.lastUsed) > limitSessionIdle && (state.slots == nil || len(state.slots) == 0) {
+			delete(l.sessions, session)
+		}
+	}
+	l.pruned = now
+}
+
+// waitError explains why a call gave up waiting for a concurrency slot
+func (l *limiter) waitError(ctx context.Context, scope string) error {
+	if ctx.Err() == context.Canceled {
+		return shared.NewError(shared.CodeCancelled, "tool call cancelled while waiting for a free slot")
+	}
+	limit := l.config.MaxConcurrent
+	if scope == "session" {
+		limit = l.config.SessionMaxConcurrent
+	}
+	return shared.NewError(shared.CodeRateLimited, "too many concurrent tool calls: the %s runs at most %d at once", scope, limit).
+		WithDetail("retry_after_ms", limitQueueTimeout.Milliseconds())
+}
+
+// sessionID identifies the client session of a call; calls without one
+// share a single limit
+func sessionID(ctx context.Context) string {
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		return session.SessionID()
+	}
+	return ""
+}
@@ -0,0 +1,147 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kevsmith/my-mcp/pkg/shared"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func limitCode(t *testing.T, err error) string {
+	t.Helper()
+	var toolErr *shared.ToolError
+	if !errors.As(err, &toolErr) {
+		t.Fatalf("Expected a ToolError, got %v", err)
+	}
+	return toolErr.Code
+}
+
+func TestLimiterConcurrency(t *testing.T) {
+	l := newLimiter(LimitConfig{MaxConcurrent: 2, SessionMaxConcurrent: 1})
+	waitBriefly := func() context.Context {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		t.Cleanup(cancel)
+		return ctx
+	}
+
+	releaseA, err := l.acquire(context.Background(), "a")
+	if err != nil {
+		t.Fatalf("First call rejected: %v", err)
+	}
+
+	// Session a is at its cap while session b still gets a slot
+	if _, err := l.acquire(waitBriefly(), "a"); err == nil || limitCode(t, err) != shared.CodeRateLimited {
+		t.Errorf("Expected RATE_LIMITED for session a over its cap, got %v", err)
+	}
+	releaseB, err := l.acquire(context.Background(), "b")
+	if err != nil {
+		t.Fatalf("Session b rejected: %v", err)
+	}
+
+	// Both server slots are taken, so session c waits and gives up
+	if _, err := l.acquire(waitBriefly(), "c"); err == nil || !strings.Contains(err.Error(), "the server runs at most 2") {
+		t.Errorf("Expected the server cap to reject session c, got %v", err)
+	}
+
+	releaseA()
+	releaseB()
+	release, err := l.acquire(context.Background(), "a")
+	if err != nil {
+		t.Fatalf("Call rejected after slots were released: %v", err)
+	}
+	release()
+}
+
+func TestLimiterWaitsForSlot(t *testing.T) {
+	l := newLimiter(LimitConfig{MaxConcurrent: 1})
+	release, err := l.acquire(context.Background(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.AfterFunc(20*time.Millisecond, release)
+
+	start := time.Now()
+	second, err := l.acquire(context.Background(), "")
+	if err != nil {
+		t.Fatalf("Queued call rejected: %v", err)
+	}
+	second()
+	if time.Since(start) < 10*time.Millisecond {
+		t.Error("Expected the queued call to wait for the first to finish")
+	}
+
+	// A cancelled caller stops waiting
+	release, _ = l.acquire(context.Background(), "")
+	defer release()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := l.acquire(ctx, ""); err == nil || limitCode(t, err) != shared.CodeCancelled {
+		t.Errorf("Expected CANCELLED, got %v", err)
+	}
+}
+
+func TestLimiterSessionRate(t *testing.T) {
+	l := newLimiter(LimitConfig{SessionRate: 2})
+	for i := 0; i < 2; i++ {
+		release, err := l.acquire(context.Background(), "a")
+		if err != nil {
+			t.Fatalf("Call %d rejected: %v", i+1, err)
+		}
+		release()
+	}
+
+	_, err := l.acquire(context.Background(), "a")
+	if err == nil {
+		t.Fatal("Expected the third call in a minute to be rejected")
+	}
+	var toolErr *shared.ToolError
+	if !errors.As(err, &toolErr) || toolErr.Code != shared.CodeRateLimited || !toolErr.Retryable {
+		t.Fatalf("Expected a retryable RATE_LIMITED error, got %v", err)
+	}
+	if retryAfter, _ := toolErr.Details["retry_after_ms"].(int64); retryAfter <= 0 || retryAfter > 30000 {
+		t.Errorf("retry_after_ms = %v, want up to half a minute", toolErr.Details["retry_after_ms"])
+	}
+
+	// Each session has its own bucket
+	if release, err := l.acquire(context.Background(), "b"); err != nil {
+		t.Errorf("Session b rejected: %v", err)
+	} else {
+		release()
+	}
+}
+
+func TestLimiterMiddlewareReturnsErrorResult(t *testing.T) {
+	l := newLimiter(LimitConfig{SessionRate: 1})
+	calls := 0
+	handler := l.middleware(func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		calls++
+		return mcp.NewToolResultText("ok"), nil
+	})
+
+	handler(context.Background(), mcp.CallToolRequest{})
+	result, err := handler(context.Background(), mcp.CallToolRequest{})
+	if err != nil || !result.IsError {
+		t.Fatalf("Expected an error result, got %v, %v", result, err)
+	}
+	if text := result.Content[0].(mcp.TextContent).Text; !strings.Contains(text, `"code": "RATE_LIMITED"`) {
+		t.Errorf("Expected the RATE_LIMITED envelope, got %s", text)
+	}
+	if calls != 1 {
+		t.Errorf("Handler ran %d times, want 1", calls)
+	}
+}
+
+func TestGetLimitConfig(t *testing.T) {
+	t.Setenv("MCP_MAX_CONCURRENT", "0")
+	t.Setenv("MCP_SESSION_MAX_CONCURRENT", "bogus")
+	t.Setenv("MCP_SESSION_RATE", "60")
+
+	config := GetLimitConfig()
+	if config.MaxConcurrent != 0 || config.SessionMaxConcurrent != 8 || config.SessionRate != 60 || config.MaxResponseBytes != 1024*1024 {
+		t.Errorf("GetLimitConfig() = %+v, want unlimited server calls, the default session cap and response size, and 60 per minute", config)
+	}
+}
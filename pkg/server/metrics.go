@@ -0,0 +1,192 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kevsmith/my-mcp/pkg/shared"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// latencyBuckets are the upper bounds in seconds of the tool call latency
+// histogram; extraction and Outlook calls can take tens of seconds
+var latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// metrics collects tool call and cache statistics for the servers in this
+// process. Calls are always counted; the HTTP transports publish them on
+// /metrics in the Prometheus text format when enabled with --metrics.
+type metrics struct {
+	started time.Time
+	mutex   sync.Mutex
+	tools   map[string]*toolStats
+	caches  map[string]func() shared.CacheStats
+}
+
+// toolStats accumulates the calls of one tool
+type toolStats struct {
+	calls   int64
+	errors  int64 // Calls that failed or returned an error result
+	seconds float64
+	buckets []int64 // Calls per latency bucket, not cumulative
+}
+
+// serverMetrics is shared by every server setup, so the combined server and
+// the standalone servers report the same way
+var serverMetrics = newMetrics()
+
+func newMetrics() *metrics {
+	return &metrics{
+		started: time.Now(),
+		tools:   make(map[string]*toolStats),
+		caches:  make(map[string]func() shared.CacheStats),
+	}
+}
+
+// withMetrics is the server option that counts a server's tool calls
+func withMetrics() server.ServerOption {
+	return server.WithToolHandlerMiddleware(serverMetrics.middleware)
+}
+
+// middleware times each tool call and records whether it failed
+func (m *metrics) middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		result, err := next(ctx, request)
+		m.record(request.Params.Name, time.Since(start), err != nil || (result != nil && result.IsError))
+		return result, err
+	}
+}
+
+// record adds one call of a tool
+func (m *metrics) record(tool string, elapsed time.Duration, failed bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	stats, exists := m.tools[tool]
+	if !exists {
+		stats = &toolStats{buckets: make([]int64, len(latencyBuckets))}
+		m.tools[tool] = stats
+	}
+	stats.calls++
+	if failed {
+		stats.errors++
+	}
+	seconds := elapsed.Seconds()
+	stats.seconds += seconds
+	if i, _ := slices.BinarySearch(latencyBuckets, seconds); i < len(latencyBuckets) {
+		stats.buckets[i]++
+	}
+}
+
+// registerCache reports a cache's statistics under name
+func (m *metrics) registerCache(name string, stats func() shared.CacheStats) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.caches[name] = stats
+}
+
+// registerBackendCache reports the cache of an Outlook backend that keeps one
+func (m *metrics) registerBackendCache(name string, backend any) {
+	if cached, ok := backend.(interface{ CacheStats() shared.CacheStats }); ok {
+		m.registerCache(name, cached.CacheStats)
+	}
+}
+
+// writePrometheus writes the metrics in the Prometheus text exposition format
+func (m *metrics) writePrometheus(w io.Writer) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	tools := slices.Sorted(maps.Keys(m.tools))
+	caches := slices.Sorted(maps.Keys(m.caches))
+
+	writeHeader(w, "mcp_uptime_seconds", "gauge", "Seconds since the server started.")
+	fmt.Fprintf(w, "mcp_uptime_seconds %g\n", time.Since(m.started).Seconds())
+
+	writeHeader(w, "mcp_tool_calls_total", "counter", "Tool calls handled.")
+	for _, tool := range tools {
+		fmt.Fprintf(w, "mcp_tool_calls_total{tool=%s} %d\n", labelValue(tool), m.tools[tool].calls)
+	}
+
+	writeHeader(w, "mcp_tool_errors_total", "counter", "Tool calls that failed or returned an error result.")
+	for _, tool := range tools {
+		fmt.Fprintf(w, "mcp_tool_errors_total{tool=%s} %d\n", labelValue(tool), m.tools[tool].errors)
+	}
+
+	writeHeader(w, "mcp_tool_duration_seconds", "histogram", "Tool call latency.")
+	for _, tool := range tools {
+		stats, label := m.tools[tool], labelValue(tool)
+		var cumulative int64
+		for i, bound := range latencyBuckets {
+			cumulative += stats.buckets[i]
+			fmt.Fprintf(w, "mcp_tool_duration_seconds_bucket{tool=%s,le=\"%g\"} %d\n", label, bound, cumulative)
+		}
+		fmt.Fprintf(w, "mcp_tool_duration_seconds_bucket{tool=%s,le=\"+Inf\"} %d\n", label, stats.calls)
+		fmt.Fprintf(w, "mcp_tool_duration_seconds_sum{tool=%s} %g\n", label, stats.seconds)
+		fmt.Fprintf(w, "mcp_tool_duration_seconds_count{tool=%s} %d\n", label, stats.calls)
+	}
+
+	if len(caches) == 0 {
+		return
+	}
+	stats := make(map[string]shared.CacheStats, len(caches))
+	for _, name := range caches {
+		stats[name] = m.caches[name]()
+	}
+
+	writeHeader(w, "mcp_cache_hits_total", "counter", "Cache lookups served from the cache.")
+	for _, name := range caches {
+		fmt.Fprintf(w, "mcp_cache_hits_total{cache=%s} %d\n", labelValue(name), stats[name].Hits)
+	}
+	writeHeader(w, "mcp_cache_misses_total", "counter", "Cache lookups that missed or found an expired entry.")
+	for _, name := range caches {
+		fmt.Fprintf(w, "mcp_cache_misses_total{cache=%s} %d\n", labelValue(name), stats[name].Misses)
+	}
+	writeHeader(w, "mcp_cache_hit_ratio", "gauge", "Fraction of cache lookups that hit, 0 before any lookup.")
+	for _, name := range caches {
+		var ratio float64
+		if lookups := stats[name].Hits + stats[name].Misses; lookups > 0 {
+			ratio = float64(stats[name].Hits) / float64(lookups)
+		}
+		fmt.Fprintf(w, "mcp_cache_hit_ratio{cache=%s} %g\n", labelValue(name), ratio)
+	}
+	writeHeader(w, "mcp_cache_entries", "gauge", "Entries currently cached.")
+	for _, name := range caches {
+		fmt.Fprintf(w, "mcp_cache_entries{cache=%s} %d\n", labelValue(name), stats[name].Entries)
+	}
+}
+
+// serveMetrics is the /metrics endpoint
+func (m *metrics) serveMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.writePrometheus(w)
+}
+
+// serveHealth is the /healthz endpoint: it answers while the server runs
+func (m *metrics) serveHealth(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"status":         "ok",
+		"uptime_seconds": int64(time.Since(m.started).Seconds()),
+	})
+}
+
+func writeHeader(w io.Writer, name, kind, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// labelEscaper escapes label values as the exposition format requires
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func labelValue(value string) string {
+	return `"` + labelEscaper.Replace(value) + `"`
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kevsmith/my-mcp/pkg/shared"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	m := newMetrics()
+	call := func(name string, handler func() (*mcp.CallToolResult, error)) {
+		request := mcp.CallToolRequest{}
+		request.Params.Name = name
+		m.middleware(func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return handler()
+		})(context.Background(), request)
+	}
+
+	call("read_file", func() (*mcp.CallToolResult, error) { return mcp.NewToolResultText("ok"), nil })
+	call("read_file", func() (*mcp.CallToolResult, error) { return mcp.NewToolResultError("no such file"), nil })
+	call("list_sheets", func() (*mcp.CallToolResult, error) { return nil, errors.New("broken") })
+	m.record("extract_text", 3*time.Second, false)
+	m.registerCache("excel", func() shared.CacheStats { return shared.CacheStats{Hits: 3, Misses: 1, Entries: 2} })
+
+	output := new(strings.Builder)
+	m.writePrometheus(output)
+	for _, want := range []string{
+		`mcp_tool_calls_total{tool="read_file"} 2`,
+		`mcp_tool_errors_total{tool="read_file"} 1`,
+		`mcp_tool_errors_total{tool="list_sheets"} 1`,
+		`mcp_tool_duration_seconds_bucket{tool="extract_text",le="2.5"} 0`,
+		`mcp_tool_duration_seconds_bucket{tool="extract_text",le="5"} 1`,
+		`mcp_tool_duration_seconds_bucket{tool="extract_text",le="+Inf"} 1`,
+		`mcp_tool_duration_seconds_sum{tool="extract_text"} 3`,
+		`mcp_cache_hits_total{cache="excel"} 3`,
+		`mcp_cache_hit_ratio{cache="excel"} 0.75`,
+		`mcp_cache_entries{cache="excel"} 2`,
+		"# TYPE mcp_tool_duration_seconds histogram",
+	} {
+		if !strings.Contains(output.String(), want) {
+			t.Errorf("Metrics missing %q:\n%s", want, output)
+		}
+	}
+}
+
+func TestHTTPHealthAndMetricsEndpoints(t *testing.T) {
+	get := func(url string) (int, string) {
+		t.Helper()
+		response, err := http.Get(url)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", url, err)
+		}
+		defer response.Body.Close()
+		body, _ := io.ReadAll(response.Body)
+		return response.StatusCode, string(body)
+	}
+
+	for _, transport := range []string{TransportSSE, TransportStreamableHTTP} {
+		addr, stop := startHTTPTransport(t, TransportConfig{Transport: transport, Metrics: true})
+		if status, body := get(addr + "/healthz"); status != http.StatusOK || !strings.Contains(body, `"status":"ok"`) {
+			t.Errorf("%s: unexpected /healthz response %d: %s", transport, status, body)
+		}
+		if status, body := get(addr + "/metrics"); status != http.StatusOK || !strings.Contains(body, "mcp_uptime_seconds") {
+			t.Errorf("%s: unexpected /metrics response %d: %s", transport, status, body)
+		}
+		if err := stop(); err != nil {
+			t.Errorf("%s: shutdown failed: %v", transport, err)
+		}
+	}
+
+	// Metrics are only published when enabled
+	addr, stop := startHTTPTransport(t, TransportConfig{Transport: TransportStreamableHTTP})
+	defer stop()
+	if status, _ := get(addr + "/metrics"); status != http.StatusNotFound {
+		t.Errorf("Expected /metrics to be absent without --metrics, got %d", status)
+	}
+}
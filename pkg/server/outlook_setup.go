@@ -1,40 +1,41 @@
 package server
 
 import (
+	"fmt"
+
 	"github.com/kevsmith/my-mcp/pkg/outlook"
+	"github.com/kevsmith/my-mcp/pkg/shared/sandbox"
 	"github.com/mark3labs/mcp-go/server"
 )
 
 // Global manager reference for cleanup
-var outlookManager *outlook.Manager
+var outlookManager outlook.Backend
 
 // OutlookMCPServer extends MCPServer with Outlook-specific functionality
 type OutlookMCPServer struct {
 	*server.MCPServer
-	manager *outlook.Manager
+	manager outlook.Backend
 }
 
 // NewOutlookMCPServer creates a new Outlook MCP server
 func NewOutlookMCPServer() (*server.MCPServer, error) {
-	manager, err := outlook.NewManager()
-	if err != nil {
-		return nil, err
-	}
-
+	info := newServerInfo("outlook-mcp", "1.0.0")
 	s := server.NewMCPServer(
-		"outlook-mcp",
-		"1.0.0",
+		info.name,
+		info.version,
 		server.WithLogging(),
+		withMetrics(),
+		withLimits(),
+		withResponseLimit(),
+		withRecovery(),
 	)
 
-	toolDefinitions := outlook.GetToolDefinitions()
-
-	// Add all Outlook tools
-	s.AddTool(toolDefinitions[0], outlook.ListMessagesHandler(manager))      // list_messages
-	s.AddTool(toolDefinitions[1], outlook.GetMessageHandler(manager))        // get_message
-	s.AddTool(toolDefinitions[2], outlook.GetMessageBodyHandler(manager))    // get_message_body
-	s.AddTool(toolDefinitions[3], outlook.GetMessageBodyRawHandler(manager)) // get_message_body_raw
-	s.AddTool(toolDefinitions[4], outlook.SearchMessagesHandler(manager))    // search_messages
+	manager, err := addOutlookTools(s)
+	if err != nil {
+		return nil, err
+	}
+	info.toolsets["outlook"] = outlookSettings(manager)
+	addServerInfoTool(s, info)
 
 	// Store manager reference for cleanup (using a global or context as needed)
 	outlookManager = manager
@@ -42,6 +43,87 @@ func NewOutlookMCPServer() (*server.MCPServer, error) {
 	return s, nil
 }
 
+// addOutlookTools connects to the mailbox backend and registers the Outlook
+// tools with s
+func addOutlookTools(s toolRegistrar) (outlook.Backend, error) {
+	paths, err := sandbox.Optional(outlook.GetSaveRoots())
+	if err != nil {
+		return nil, fmt.Errorf("invalid Outlook save roots: %w", err)
+	}
+	manager, err := outlook.NewBackend()
+	if err != nil {
+		return nil, err
+	}
+	serverMetrics.registerBackendCache("outlook", manager)
+
+	if err := registerTools(s, outlook.GetToolDefinitions(), outlookToolHandlers(manager, paths)); err != nil {
+		manager.Stop()
+		return nil, fmt.Errorf("failed to register Outlook tools: %w", err)
+	}
+
+	// Tools that send mail are opt-in via --allow-send
+	if manager.SendEnabled() {
+		if err := registerTools(s, outlook.GetSendToolDefinitions(), outlookSendToolHandlers(manager)); err != nil {
+			manager.Stop()
+			return nil, fmt.Errorf("failed to register Outlook send tools: %w", err)
+		}
+	}
+
+	return manager, nil
+}
+
+// outlookToolHandlers returns the handlers of the Outlook tools, by name;
+// paths restricts where attachments and exported messages are saved, and
+// which files drafts may attach
+func outlookToolHandlers(manager outlook.Backend, paths *sandbox.Sandbox) toolHandlers {
+	return toolHandlers{
+		"list_messages":             outlook.ListMessagesHandler(manager),
+		"get_message":               outlook.GetMessageHandler(manager),
+		"get_message_body":          outlook.GetMessageBodyHandler(manager),
+		"get_message_body_raw":      outlook.GetMessageBodyRawHandler(manager),
+		"search_messages":           outlook.SearchMessagesHandler(manager),
+		"list_folders":              outlook.ListFoldersHandler(manager),
+		"update_message":            outlook.UpdateMessageHandler(manager),
+		"move_message":              outlook.MoveMessageHandler(manager),
+		"delete_message":            outlook.DeleteMessageHandler(manager),
+		"create_draft":              outlook.CreateDraftHandler(manager, paths),
+		"list_attachments":          outlook.ListAttachmentsHandler(manager),
+		"save_attachment":           outlook.SaveAttachmentHandler(manager, paths),
+		"list_calendar_events":      outlook.ListCalendarEventsHandler(manager),
+		"get_event":                 outlook.GetEventHandler(manager),
+		"search_contacts":           outlook.SearchContactsHandler(manager),
+		"get_contact":               outlook.GetContactHandler(manager),
+		"resolve_recipient":         outlook.ResolveRecipientHandler(manager),
+		"get_conversation":          outlook.GetConversationHandler(manager),
+		"list_messages_since":       outlook.ListMessagesSinceHandler(manager),
+		"poll_new_messages":         outlook.PollNewMessagesHandler(manager),
+		"get_inbox_analytics":       outlook.GetInboxAnalyticsHandler(manager),
+		"list_rules_and_categories": outlook.ListRulesAndCategoriesHandler(manager),
+		"list_accounts":             outlook.ListAccountsHandler(manager),
+		"health_check":              outlook.HealthCheckHandler(manager),
+		"export_message":            outlook.ExportMessageHandler(manager, paths),
+		"export_messages":           outlook.ExportMessagesHandler(manager, paths),
+		"get_automatic_replies":     outlook.GetAutomaticRepliesHandler(manager),
+		"get_free_busy":             outlook.GetFreeBusyHandler(manager),
+		"list_tasks":                outlook.ListTasksHandler(manager),
+		"create_task":               outlook.CreateTaskHandler(manager),
+		"complete_task":             outlook.CompleteTaskHandler(manager),
+		"get_server_logs":           outlook.GetServerLogsHandler(manager),
+		"reconnect":                 outlook.ReconnectHandler(manager),
+		"bulk_update_messages":      outlook.BulkUpdateMessagesHandler(manager),
+		"get_profile":               outlook.GetProfileHandler(manager),
+	}
+}
+
+// outlookSendToolHandlers returns the handlers of the tools that send mail
+func outlookSendToolHandlers(manager outlook.Backend) toolHandlers {
+	return toolHandlers{
+		"send_message":     outlook.SendMessageHandler(manager),
+		"reply_to_message": outlook.ReplyToMessageHandler(manager),
+		"forward_message":  outlook.ForwardMessageHandler(manager),
+	}
+}
+
 // ShutdownOutlookManager gracefully shuts down the global Outlook manager
 func ShutdownOutlookManager() error {
 	if outlookManager != nil {
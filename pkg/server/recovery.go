@@ -0,0 +1,35 @@
+package server
+
+import (
+	"context"
+	"log"
+	"runtime/debug"
+
+	"github.com/kevsmith/my-mcp/pkg/shared"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// withRecovery is the server option that turns a panicking tool handler into
+// an INTERNAL error result, so a bug in a parser or excelize fails one call
+// instead of killing the server and the client's session. The stack trace
+// goes to the standard logger. Panics in goroutines a handler starts can't
+// be recovered here.
+func withRecovery() server.ServerOption {
+	return server.WithToolHandlerMiddleware(recoverPanics)
+}
+
+// recoverPanics runs next, converting a panic into an error result
+func recoverPanics(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (result *mcp.CallToolResult, err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				log.Printf("panic in tool %s: %v\n%s", request.Params.Name, recovered, debug.Stack())
+				result = shared.ErrorResult(shared.NewError(shared.CodeInternal, "tool %s failed unexpectedly: %v", request.Params.Name, recovered).
+					WithDetail("panic", true))
+				err = nil
+			}
+		}()
+		return next(ctx, request)
+	}
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/kevsmith/my-mcp/pkg/shared"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestPanicRecovery(t *testing.T) {
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	s := server.NewMCPServer("test", "1.0.0", server.WithToolCapabilities(true), withRecovery())
+	s.AddTool(mcp.NewTool("explode"), func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var sheet map[string][]string
+		sheet["A1"] = nil // Panics on the nil map, as a buggy parser might
+		return mcp.NewToolResultText("unreachable"), nil
+	})
+	s.AddTool(mcp.NewTool("ping"), func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("pong"), nil
+	})
+
+	call := func(tool string) mcp.CallToolResult {
+		t.Helper()
+		response := s.HandleMessage(context.Background(), json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"`+tool+`"}}`))
+		result, ok := response.(mcp.JSONRPCResponse).Result.(mcp.CallToolResult)
+		if !ok {
+			t.Fatalf("%s: expected a tool result, got %#v", tool, response)
+		}
+		return result
+	}
+
+	result := call("explode")
+	var envelope shared.ErrorEnvelope
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &envelope); err != nil || !result.IsError {
+		t.Fatalf("Expected an error envelope, got %+v", result)
+	}
+	if envelope.Error.Code != shared.CodeInternal || !strings.Contains(envelope.Error.Message, "tool explode failed unexpectedly") {
+		t.Errorf("Unexpected error: %+v", envelope.Error)
+	}
+	if !strings.Contains(logs.String(), "panic in tool explode") || !strings.Contains(logs.String(), "recovery_test.go") {
+		t.Errorf("Expected the panic and its stack trace to be logged, got:\n%s", logs.String())
+	}
+
+	// The server keeps serving after the panic
+	if result := call("ping"); result.IsError || result.Content[0].(mcp.TextContent).Text != "pong" {
+		t.Errorf("Expected ping to succeed after the panic, got %+v", result)
+	}
+}
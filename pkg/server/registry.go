@@ -0,0 +1,80 @@
+package server
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// toolHandlers maps tool names to their handlers
+type toolHandlers map[string]server.ToolHandlerFunc
+
+// registerTools adds each definition to tools with the handler of the same
+// name. Every definition must have a handler and every handler a
+// definition, so a tool added or renamed on one side only fails at startup
+// instead of going missing; nothing is registered then.
+func registerTools(tools toolRegistrar, definitions []mcp.Tool, handlers toolHandlers) error {
+	var unhandled, duplicated []string
+	defined := make(map[string]bool, len(definitions))
+	for _, definition := range definitions {
+		if defined[definition.Name] {
+			duplicated = append(duplicated, definition.Name)
+		}
+		defined[definition.Name] = true
+		if handlers[definition.Name] == nil {
+			unhandled = append(unhandled, definition.Name)
+		}
+	}
+	var undefined []string
+	for _, name := range slices.Sorted(maps.Keys(handlers)) {
+		if !defined[name] {
+			undefined = append(undefined, name)
+		}
+	}
+
+	var problems []string
+	if len(unhandled) > 0 {
+		problems = append(problems, "no handler for "+strings.Join(unhandled, ", "))
+	}
+	if len(undefined) > 0 {
+		problems = append(problems, "no definition for "+strings.Join(undefined, ", "))
+	}
+	if len(duplicated) > 0 {
+		problems = append(problems, "defined more than once: "+strings.Join(duplicated, ", "))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("tool registration mismatch: %s", strings.Join(problems, "; "))
+	}
+
+	for _, definition := range definitions {
+		tools.AddTool(definition, handlers[definition.Name])
+	}
+	return nil
+}
+
+// only returns the handlers with the given names
+func (h toolHandlers) only(names ...string) toolHandlers {
+	selected := make(toolHandlers, len(names))
+	for _, name := range names {
+		if handler, ok := h[name]; ok {
+			selected[name] = handler
+		}
+	}
+	return selected
+}
+
+// selectTools returns the definitions with the given names, for servers
+// that host part of a toolset
+func selectTools(definitions []mcp.Tool, names ...string) []mcp.Tool {
+	var selected []mcp.Tool
+	for _, definition := range definitions {
+		if slices.Contains(names, definition.Name) {
+			selected = append(selected, definition)
+		}
+	}
+	return selected
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/kevsmith/my-mcp/pkg/outlook"
+	"github.com/kevsmith/my-mcp/pkg/workspace"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// recordedTools is a toolRegistrar that records the names it is given
+type recordedTools []string
+
+func (r *recordedTools) AddTool(tool mcp.Tool, handler server.ToolHandlerFunc) {
+	*r = append(*r, tool.Name)
+}
+
+func noopHandler(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return mcp.NewToolResultText("ok"), nil
+}
+
+func TestRegisterTools(t *testing.T) {
+	definitions := []mcp.Tool{mcp.NewTool("read"), mcp.NewTool("write")}
+
+	var tools recordedTools
+	if err := registerTools(&tools, definitions, toolHandlers{"write": noopHandler, "read": noopHandler}); err != nil {
+		t.Fatalf("registerTools failed: %v", err)
+	}
+	if strings.Join(tools, ",") != "read,write" {
+		t.Errorf("Registered %v, want the definitions in order", tools)
+	}
+
+	tests := []struct {
+		definitions []mcp.Tool
+		handlers    toolHandlers
+		want        string
+	}{
+		{definitions, toolHandlers{"read": noopHandler}, "no handler for write"},
+		{definitions, toolHandlers{"read": noopHandler, "write": noopHandler, "delete": noopHandler}, "no definition for delete"},
+		{append(definitions, mcp.NewTool("read")), toolHandlers{"read": noopHandler, "write": noopHandler}, "defined more than once: read"},
+	}
+	for _, test := range tests {
+		var tools recordedTools
+		err := registerTools(&tools, test.definitions, test.handlers)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("Expected an error containing %q, got %v", test.want, err)
+		}
+		if len(tools) != 0 {
+			t.Errorf("Registered %v despite the mismatch", tools)
+		}
+	}
+}
+
+// The Outlook tools need a backend to start, so their name mapping is checked
+// here with handlers that are never called
+func TestOutlookToolHandlersMatchDefinitions(t *testing.T) {
+	var tools recordedTools
+	if err := registerTools(&tools, outlook.GetToolDefinitions(), outlookToolHandlers(nil, nil)); err != nil {
+		t.Error(err)
+	}
+	if err := registerTools(&tools, outlook.GetSendToolDefinitions(), outlookSendToolHandlers(nil)); err != nil {
+		t.Error(err)
+	}
+
+	outlookTools := []string{"list_messages", "search_messages", "list_attachments"}
+	if err := registerTools(&tools, selectTools(outlook.GetToolDefinitions(), outlookTools...), outlookToolHandlers(nil, nil).only(outlookTools...)); err != nil {
+		t.Errorf("Workspace Outlook tools: %v", err)
+	}
+	if err := registerTools(&tools, workspace.GetToolDefinitions(), toolHandlers{"analyze_email_attachment": noopHandler}); err != nil {
+		t.Errorf("Workspace tools: %v", err)
+	}
+}
@@ -0,0 +1,216 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Transports an MCP server can be exposed over
+const (
+	TransportStdio          = "stdio"
+	TransportSSE            = "sse"
+	TransportStreamableHTTP = "streamable-http"
+)
+
+const (
+	defaultListenAddr = "localhost:8080"
+	shutdownTimeout   = 10 * time.Second // How long HTTP shutdown waits for requests in flight
+)
+
+// TransportConfig selects how a server is exposed to clients. Empty fields
+// fall back to MCP_TRANSPORT, MCP_LISTEN, MCP_METRICS, MCP_AUTH_TOKEN and
+// MCP_ALLOWED_HOSTS, then to stdio, localhost:8080, no metrics, no token and
+// no extra hosts.
+type TransportConfig struct {
+	Transport    string `json:"transport"`     // TransportStdio, TransportSSE or TransportStreamableHTTP
+	Listen       string `json:"listen"`        // Address the HTTP transports listen on
+	Metrics      bool   `json:"metrics"`       // Serve Prometheus metrics on /metrics of the HTTP transports
+	AuthToken    string `json:"-"`             // Bearer token the HTTP transports require; never reported
+	AllowedHosts string `json:"allowed_hosts"` // Comma-separated host names accepted in Host and Origin besides loopback; * accepts any
+}
+
+// RegisterTransportFlags adds the --transport and --listen flags shared by
+// all servers to flags
+func RegisterTransportFlags(flags *flag.FlagSet) *TransportConfig {
+	config := &TransportConfig{}
+	flags.StringVar(&config.Transport, "transport", "", "MCP transport: stdio, sse or streamable-http (default: stdio, env: MCP_TRANSPORT)")
+	flags.StringVar(&config.Listen, "listen", "", "Address the sse and streamable-http transports listen on (default: localhost:8080, env: MCP_LISTEN)")
+	flags.BoolVar(&config.Metrics, "metrics", false, "Serve Prometheus metrics on /metrics of the sse and streamable-http transports (env: MCP_METRICS)")
+	flags.StringVar(&config.AuthToken, "auth-token", "", "Bearer token the sse and streamable-http transports require from clients (env: MCP_AUTH_TOKEN, which keeps it out of the process list)")
+	flags.StringVar(&config.AllowedHosts, "allowed-hosts", "", "Comma-separated host names the sse and streamable-http transports accept in Host and Origin headers besides loopback and the --listen host, e.g. the name clients or a reverse proxy use; * accepts any (env: MCP_ALLOWED_HOSTS)")
+	return config
+}
+
+// resolved fills in the fields left empty from the environment and defaults
+func (c TransportConfig) resolved() TransportConfig {
+	if c.Transport == "" {
+		c.Transport = os.Getenv("MCP_TRANSPORT")
+	}
+	if c.Transport == "" {
+		c.Transport = TransportStdio
+	}
+	if c.Listen == "" {
+		c.Listen = os.Getenv("MCP_LISTEN")
+	}
+	if c.Listen == "" {
+		c.Listen = defaultListenAddr
+	}
+	if !c.Metrics {
+		c.Metrics, _ = strconv.ParseBool(os.Getenv("MCP_METRICS"))
+	}
+	if c.AuthToken == "" {
+		c.AuthToken = os.Getenv("MCP_AUTH_TOKEN")
+	}
+	if c.AllowedHosts == "" {
+		c.AllowedHosts = os.Getenv("MCP_ALLOWED_HOSTS")
+	}
+	return c
+}
+
+// Serve runs an MCP server over the configured transport until the client
+// closes stdin or the process receives SIGINT or SIGTERM. HTTP transports
+// stop accepting connections and wait for requests in flight before
+// returning; a clean shutdown returns nil. HTTP transports also answer
+// /healthz, and /metrics when metrics are enabled.
+func Serve(s *server.MCPServer, config TransportConfig) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	config = config.resolved()
+	servedTransport.Store(&config)
+	switch config.Transport {
+	case TransportStdio:
+		err := server.NewStdioServer(s).Listen(ctx, os.Stdin, os.Stdout)
+		if errors.Is(err, context.Canceled) {
+			return nil
+		}
+		return err
+	case TransportSSE, TransportStreamableHTTP:
+		listener, err := net.Listen("tcp", config.Listen)
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", config.Listen, err)
+		}
+		return serveHTTP(ctx, s, config, listener)
+	}
+	return fmt.Errorf("unknown transport %q: use %s, %s or %s", config.Transport, TransportStdio, TransportSSE, TransportStreamableHTTP)
+}
+
+// serveHTTP serves an HTTP transport on listener until ctx is done, then
+// shuts it down gracefully
+func serveHTTP(ctx context.Context, s *server.MCPServer, config TransportConfig, listener net.Listener) error {
+	transport := config.Transport
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", serverMetrics.serveHealth)
+	if config.Metrics {
+		mux.HandleFunc("/metrics", serverMetrics.serveMetrics)
+	}
+	httpServer := &http.Server{Handler: guardHTTP(mux, config), ReadHeaderTimeout: 10 * time.Second}
+
+	// Both transports take over the HTTP server so that their Shutdown also
+	// ends open sessions, which would otherwise hold the server open
+	var shutdown func(context.Context) error
+	var endpoint string
+	switch transport {
+	case TransportSSE:
+		sseServer := server.NewSSEServer(s, server.WithHTTPServer(httpServer))
+		mux.Handle("/", sseServer)
+		shutdown, endpoint = sseServer.Shutdown, sseServer.CompleteSsePath()
+	default:
+		endpoint = "/mcp"
+		httpTransport := server.NewStreamableHTTPServer(s, server.WithStreamableHTTPServer(httpServer), server.WithEndpointPath(endpoint))
+		mux.Handle(endpoint, httpTransport)
+		shutdown = httpTransport.Shutdown
+	}
+
+	fmt.Fprintf(os.Stderr, "Serving MCP over %s at http://%s%s\n", transport, listener.Addr(), endpoint)
+	served := make(chan error, 1)
+	go func() {
+		served <- httpServer.Serve(listener)
+	}()
+
+	select {
+	case err := <-served:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down %s transport: %w", transport, err)
+	}
+	return nil
+}
+
+// guardHTTP rejects requests that a web page could make through the user's
+// browser, as the MCP specification requires of HTTP transports: a Host that
+// isn't loopback, the listen address or one of the allowed hosts (DNS
+// rebinding), an Origin from any other site, and, when a token is configured,
+// a missing or wrong bearer token. /healthz stays open to token-less probes.
+func guardHTTP(next http.Handler, config TransportConfig) http.Handler {
+	var allowedHosts []string
+	for _, host := range strings.Split(config.AllowedHosts, ",") {
+		if host = strings.Trim(strings.TrimSpace(host), "[]"); host != "" {
+			allowedHosts = append(allowedHosts, host)
+		}
+	}
+
+	allowed := func(host string) bool {
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		host = strings.Trim(host, "[]")
+		if strings.EqualFold(host, "localhost") {
+			return true
+		}
+		if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+			return true
+		}
+		// Wildcard listeners and reverse proxies are reached under names
+		// only the operator knows
+		for _, allowedHost := range allowedHosts {
+			if allowedHost == "*" || strings.EqualFold(host, allowedHost) {
+				return true
+			}
+		}
+		// A server deliberately listening on a named address answers to that name
+		listenHost, _, err := net.SplitHostPort(config.Listen)
+		return err == nil && listenHost != "" && strings.EqualFold(host, strings.Trim(listenHost, "[]"))
+	}
+	expected := []byte("Bearer " + config.AuthToken)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !allowed(r.Host) {
+			http.Error(w, "Host not allowed", http.StatusForbidden)
+			return
+		}
+		if origin := r.Header.Get("Origin"); origin != "" {
+			parsed, err := url.Parse(origin)
+			if err != nil || parsed.Host == "" || !allowed(parsed.Host) {
+				http.Error(w, "Origin not allowed", http.StatusForbidden)
+				return
+			}
+		}
+		if config.AuthToken != "" && r.URL.Path != "/healthz" &&
+			subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "Missing or invalid bearer token", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
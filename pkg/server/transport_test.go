@@ -0,0 +1,194 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// startHTTPTransport serves a test server over an HTTP transport on a free
+// port and returns its address and a function that stops it
+func startHTTPTransport(t *testing.T, config TransportConfig) (string, func() error) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- serveHTTP(ctx, server.NewMCPServer("test-mcp", "1.0.0"), config, listener)
+	}()
+	stop := func() error {
+		cancel()
+		select {
+		case err := <-done:
+			return err
+		case <-time.After(5 * time.Second):
+			t.Fatal("Transport did not shut down")
+			return nil
+		}
+	}
+	return "http://" + listener.Addr().String(), stop
+}
+
+func TestStreamableHTTPTransport(t *testing.T) {
+	addr, stop := startHTTPTransport(t, TransportConfig{Transport: TransportStreamableHTTP})
+
+	request := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`
+	response, err := http.Post(addr+"/mcp", "application/json", strings.NewReader(request))
+	if err != nil {
+		t.Fatalf("POST failed: %v", err)
+	}
+	defer response.Body.Close()
+	body := new(strings.Builder)
+	if _, err := bufio.NewReader(response.Body).WriteTo(body); err != nil {
+		t.Fatal(err)
+	}
+	if response.StatusCode != http.StatusOK || !strings.Contains(body.String(), `"name":"test-mcp"`) {
+		t.Errorf("Unexpected initialize response %d: %s", response.StatusCode, body)
+	}
+
+	if err := stop(); err != nil {
+		t.Errorf("Shutdown failed: %v", err)
+	}
+}
+
+func TestHTTPTransportGuard(t *testing.T) {
+	addr, stop := startHTTPTransport(t, TransportConfig{Transport: TransportStreamableHTTP, AuthToken: "s3cret"})
+	defer stop()
+
+	initialize := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`
+	send := func(path string, header http.Header, host string) int {
+		t.Helper()
+		request, err := http.NewRequest(http.MethodPost, addr+path, strings.NewReader(initialize))
+		if err != nil {
+			t.Fatal(err)
+		}
+		request.Header = header
+		request.Header.Set("Content-Type", "application/json")
+		if host != "" {
+			request.Host = host
+		}
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatalf("POST failed: %v", err)
+		}
+		response.Body.Close()
+		return response.StatusCode
+	}
+	authorized := func(extra ...string) http.Header {
+		header := http.Header{"Authorization": {"Bearer s3cret"}}
+		for i := 0; i+1 < len(extra); i += 2 {
+			header.Set(extra[i], extra[i+1])
+		}
+		return header
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		header http.Header
+		host   string
+		want   int
+	}{
+		{"authorized", "/mcp", authorized(), "", http.StatusOK},
+		{"localhost origin", "/mcp", authorized("Origin", "http://localhost:3000"), "", http.StatusOK},
+		{"missing token", "/mcp", http.Header{}, "", http.StatusUnauthorized},
+		{"wrong token", "/mcp", http.Header{"Authorization": {"Bearer guess"}}, "", http.StatusUnauthorized},
+		{"foreign origin", "/mcp", authorized("Origin", "https://evil.example.com"), "", http.StatusForbidden},
+		{"null origin", "/mcp", authorized("Origin", "null"), "", http.StatusForbidden},
+		{"rebound host", "/mcp", authorized(), "evil.example.com:8080", http.StatusForbidden},
+		{"health without token", "/healthz", http.Header{}, "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		if got := send(tt.path, tt.header, tt.host); got != tt.want {
+			t.Errorf("%s: status %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHTTPGuardWildcardListener(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	status := func(config TransportConfig, host, origin string) int {
+		request := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+		request.Host = host
+		if origin != "" {
+			request.Header.Set("Origin", origin)
+		}
+		recorder := httptest.NewRecorder()
+		guardHTTP(ok, config).ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	tests := []struct {
+		name   string
+		config TransportConfig
+		host   string
+		origin string
+		want   int
+	}{
+		{"wildcard listener, remote host", TransportConfig{Listen: ":8080"}, "mcp.example.com:8080", "", http.StatusForbidden},
+		{"unspecified listener, remote host", TransportConfig{Listen: "0.0.0.0:8080"}, "mcp.example.com:8080", "", http.StatusForbidden},
+		{"wildcard listener, loopback", TransportConfig{Listen: ":8080"}, "127.0.0.1:8080", "", http.StatusOK},
+		{"allowed host", TransportConfig{Listen: ":8080", AllowedHosts: "mcp.example.com, proxy.internal"}, "mcp.example.com:8080", "", http.StatusOK},
+		{"allowed host without port", TransportConfig{Listen: "0.0.0.0:8080", AllowedHosts: "mcp.example.com,proxy.internal"}, "PROXY.internal", "", http.StatusOK},
+		{"allowed host, allowed origin", TransportConfig{Listen: ":8080", AllowedHosts: "mcp.example.com"}, "mcp.example.com", "https://mcp.example.com", http.StatusOK},
+		{"allowed host, foreign origin", TransportConfig{Listen: ":8080", AllowedHosts: "mcp.example.com"}, "mcp.example.com", "https://evil.example.com", http.StatusForbidden},
+		{"other host", TransportConfig{Listen: ":8080", AllowedHosts: "mcp.example.com"}, "evil.example.com", "", http.StatusForbidden},
+		{"any host", TransportConfig{Listen: ":8080", AllowedHosts: "*", AuthToken: "s3cret"}, "10.1.2.3:8080", "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		if got := status(tt.config, tt.host, tt.origin); got != tt.want {
+			t.Errorf("%s: status %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSSETransportShutdownClosesSessions(t *testing.T) {
+	addr, stop := startHTTPTransport(t, TransportConfig{Transport: TransportSSE})
+
+	response, err := http.Get(addr + "/sse")
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	defer response.Body.Close()
+	line, err := bufio.NewReader(response.Body).ReadString('\n')
+	if err != nil || line != "event: endpoint\n" {
+		t.Fatalf("Unexpected first event line %q: %v", line, err)
+	}
+
+	// The open event stream must not hold the server open
+	if err := stop(); err != nil {
+		t.Errorf("Shutdown failed: %v", err)
+	}
+}
+
+func TestTransportConfig(t *testing.T) {
+	t.Setenv("MCP_TRANSPORT", "")
+	t.Setenv("MCP_LISTEN", "")
+	t.Setenv("MCP_AUTH_TOKEN", "")
+	t.Setenv("MCP_ALLOWED_HOSTS", "")
+	if config := (TransportConfig{}).resolved(); config.Transport != TransportStdio || config.Listen != defaultListenAddr || config.AuthToken != "" || config.AllowedHosts != "" {
+		t.Errorf("Unexpected defaults: %+v", config)
+	}
+
+	t.Setenv("MCP_TRANSPORT", TransportSSE)
+	t.Setenv("MCP_LISTEN", ":9000")
+	t.Setenv("MCP_AUTH_TOKEN", "from-env")
+	t.Setenv("MCP_ALLOWED_HOSTS", "mcp.example.com")
+	if config := (TransportConfig{Listen: ":9100"}).resolved(); config.Transport != TransportSSE || config.Listen != ":9100" || config.AuthToken != "from-env" || config.AllowedHosts != "mcp.example.com" {
+		t.Errorf("Unexpected config: %+v", config)
+	}
+
+	if err := Serve(server.NewMCPServer("test-mcp", "1.0.0"), TransportConfig{Transport: "websocket"}); err == nil || !strings.Contains(err.Error(), `unknown transport "websocket"`) {
+		t.Errorf("Expected an unknown transport error, got %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// truncationReserve is left out of the text budget for the truncation notice
+// and the JSON around the kept content
+const truncationReserve = 512
+
+// withResponseLimit is the server option that truncates tool results larger
+// than the configured size
+func withResponseLimit() server.ServerOption {
+	maxBytes := GetLimitConfig().MaxResponseBytes
+	return server.WithToolHandlerMiddleware(func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			result, err := next(ctx, request)
+			if err != nil || result == nil {
+				return result, err
+			}
+			return truncateResult(result, maxBytes), nil
+		}
+	})
+}
+
+// truncateResult returns result unchanged when it serializes to at most
+// maxBytes, otherwise a copy keeping the content that fits, cutting the text
+// item that crosses the limit, and ending with a notice pointing the client
+// at pagination. Error results and a maxBytes of 0 are left alone.
+func truncateResult(result *mcp.CallToolResult, maxBytes int) *mcp.CallToolResult {
+	if maxBytes <= 0 || result.IsError {
+		return result
+	}
+	data, err := json.Marshal(result)
+	if err != nil || len(data) <= maxBytes {
+		return result
+	}
+
+	budget := max(maxBytes-truncationReserve, 0)
+	kept := make([]mcp.Content, 0, len(result.Content)+1)
+	used := 0
+	for _, content := range result.Content {
+		item, err := json.Marshal(content)
+		if err != nil {
+			continue
+		}
+		if used+len(item) <= budget {
+			kept = append(kept, content)
+			used += len(item)
+			continue
+		}
+		// Keep the start of the text that crosses the limit; other content
+		// can't be cut, so it's dropped with everything after it
+		if text, ok := content.(mcp.TextContent); ok {
+			if text = fitText(text, budget-used); text.Text != "" {
+				kept = append(kept, text)
+			}
+		}
+		break
+	}
+
+	truncated := *result
+	truncated.Content = append(kept, mcp.NewTextContent(fmt.Sprintf(
+		"[Response truncated: the full result is %d bytes and responses are limited to %d. Use the tool's pagination parameters (such as offset and limit, a smaller range or fewer items) to fetch the rest.]",
+		len(data), maxBytes)))
+	return &truncated
+}
+
+// fitText cuts text's content to a prefix that serializes to at most budget
+// bytes. Escaping makes the serialized size of a prefix unknown until it is
+// marshaled, so the prefix shrinks by the overshoot until it fits.
+func fitText(text mcp.TextContent, budget int) mcp.TextContent {
+	full := text.Text
+	n := budget
+	for n > 0 {
+		text.Text = utf8Prefix(full, n)
+		data, err := json.Marshal(text)
+		if err != nil {
+			break
+		}
+		if len(data) <= budget {
+			return text
+		}
+		n -= len(data) - budget
+	}
+	text.Text = ""
+	return text
+}
+
+// utf8Prefix returns at most n bytes of text without splitting a character
+func utf8Prefix(text string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	if n >= len(text) {
+		return text
+	}
+	for n > 0 && !utf8.RuneStart(text[n]) {
+		n--
+	}
+	return text[:n]
+}
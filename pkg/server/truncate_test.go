@@ -0,0 +1,69 @@
+package server
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func resultSize(t *testing.T, result *mcp.CallToolResult) int {
+	t.Helper()
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(data)
+}
+
+func TestTruncateResult(t *testing.T) {
+	small := mcp.NewToolResultText("short")
+	if got := truncateResult(small, 4096); got != small {
+		t.Error("Expected a result under the limit to be returned unchanged")
+	}
+
+	// Quotes double in size when serialized, and multi-byte characters
+	// must not be split
+	text := strings.Repeat(`"é",`, 5000)
+	large := mcp.NewToolResultText(text)
+	truncated := truncateResult(large, 4096)
+	if size := resultSize(t, truncated); size > 4096 {
+		t.Errorf("Truncated result is %d bytes, want at most 4096", size)
+	}
+	if len(truncated.Content) != 2 {
+		t.Fatalf("Expected the cut text and a notice, got %d items", len(truncated.Content))
+	}
+	kept := truncated.Content[0].(mcp.TextContent).Text
+	if !strings.HasPrefix(text, kept) || len(kept) < 2048 || !utf8.ValidString(kept) {
+		t.Errorf("Expected a valid prefix of most of the budget, got %d bytes", len(kept))
+	}
+	notice := truncated.Content[1].(mcp.TextContent).Text
+	if !strings.Contains(notice, "Response truncated") || !strings.Contains(notice, "pagination") {
+		t.Errorf("Unexpected notice: %s", notice)
+	}
+	if large.Content[0].(mcp.TextContent).Text != text {
+		t.Error("Truncation changed the handler's result")
+	}
+
+	// Content after the limit is dropped
+	multi := &mcp.CallToolResult{Content: []mcp.Content{
+		mcp.NewTextContent("summary"),
+		mcp.NewImageContent(strings.Repeat("A", 8000), "image/png"),
+		mcp.NewTextContent("more"),
+	}}
+	truncated = truncateResult(multi, 4096)
+	if len(truncated.Content) != 2 || truncated.Content[0].(mcp.TextContent).Text != "summary" {
+		t.Errorf("Expected the summary and a notice, got %+v", truncated.Content)
+	}
+
+	// Error results and a zero limit are left alone
+	failed := mcp.NewToolResultError(text)
+	if truncateResult(failed, 4096) != failed {
+		t.Error("Expected error results to be left alone")
+	}
+	if truncateResult(large, 0) != large {
+		t.Error("Expected a zero limit to turn truncation off")
+	}
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/kevsmith/my-mcp/pkg/document"
+	"github.com/kevsmith/my-mcp/pkg/excel"
+	"github.com/kevsmith/my-mcp/pkg/outlook"
+	"github.com/kevsmith/my-mcp/pkg/shared/sandbox"
+	"github.com/kevsmith/my-mcp/pkg/workspace"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Global manager references for cleanup
+var (
+	workspaceOutlookManager  outlook.Backend
+	workspaceExcelManager    *excel.Manager
+	workspaceDocumentManager *document.Manager
+)
+
+// NewWorkspaceMCPServer creates the combined workspace server, which hosts tools
+// that span the Outlook, Excel and document toolsets
+func NewWorkspaceMCPServer() (*server.MCPServer, error) {
+	paths, err := sandbox.Optional(outlook.GetSaveRoots())
+	if err != nil {
+		return nil, fmt.Errorf("invalid Outlook save roots: %w", err)
+	}
+	outlookManager, err := outlook.NewBackend()
+	if err != nil {
+		return nil, err
+	}
+	excelManager := excel.NewManager()
+	documentManager := document.NewManager()
+	serverMetrics.registerBackendCache("outlook", outlookManager)
+	serverMetrics.registerCache("excel", excelManager.CacheStats)
+	serverMetrics.registerCache("document", documentManager.CacheStats)
+
+	analyzer := workspace.NewAnalyzer(outlookManager, excelManager, documentManager)
+
+	info := newServerInfo("workspace-mcp", "1.0.0")
+	s := server.NewMCPServer(
+		info.name,
+		info.version,
+		server.WithLogging(),
+		withMetrics(),
+		withLimits(),
+		withResponseLimit(),
+		withRecovery(),
+	)
+
+	// Outlook tools needed to find messages and attachments to analyze
+	outlookTools := []string{"list_messages", "search_messages", "list_attachments"}
+	err = registerTools(s, selectTools(outlook.GetToolDefinitions(), outlookTools...), outlookToolHandlers(outlookManager, paths).only(outlookTools...))
+	if err == nil {
+		err = registerTools(s, workspace.GetToolDefinitions(), toolHandlers{
+			"analyze_email_attachment": workspace.AnalyzeEmailAttachmentHandler(analyzer, paths),
+		})
+	}
+	if err != nil {
+		excelManager.Close()
+		documentManager.Close()
+		outlookManager.Stop()
+		return nil, fmt.Errorf("failed to register workspace tools: %w", err)
+	}
+
+	// The workspace tools use the Excel and document managers directly
+	info.toolsets["outlook"] = outlookSettings(outlookManager)
+	info.toolsets["excel"] = excelSettings(nil)
+	info.toolsets["document"] = documentSettings(nil)
+	addServerInfoTool(s, info)
+
+	workspaceOutlookManager = outlookManager
+	workspaceExcelManager = excelManager
+	workspaceDocumentManager = documentManager
+
+	return s, nil
+}
+
+// ShutdownWorkspace gracefully shuts down the managers used by the workspace server
+func ShutdownWorkspace() error {
+	if workspaceExcelManager != nil {
+		workspaceExcelManager.Close()
+	}
+	if workspaceDocumentManager != nil {
+		workspaceDocumentManager.Close()
+	}
+	if workspaceOutlookManager != nil {
+		return workspaceOutlookManager.Stop()
+	}
+	return nil
+}
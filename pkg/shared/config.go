@@ -0,0 +1,280 @@
+package shared
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the configuration file shared by all servers, in YAML or TOML.
+// Servers read their settings from environment variables; Apply exports each
+// setting to its variable unless the variable is already set, so environment
+// variables and then command line flags take precedence over the file.
+type Config struct {
+	Transport    string           `yaml:"transport" toml:"transport"`         // MCP_TRANSPORT
+	Listen       string           `yaml:"listen" toml:"listen"`               // MCP_LISTEN
+	Metrics      bool             `yaml:"metrics" toml:"metrics"`             // MCP_METRICS
+	AllowedHosts []string         `yaml:"allowed_hosts" toml:"allowed_hosts"` // MCP_ALLOWED_HOSTS
+	LogFile      string           `yaml:"log_file" toml:"log_file"`           // Where server logs go instead of stderr
+	Limits       LimitsConfig     `yaml:"limits" toml:"limits"`
+	Document     DocumentConfig   `yaml:"document" toml:"document"`
+	Excel        ExcelConfig      `yaml:"excel" toml:"excel"`
+	Filesystem   FilesystemConfig `yaml:"filesystem" toml:"filesystem"`
+	Outlook      OutlookConfig    `yaml:"outlook" toml:"outlook"`
+	Path         string           `yaml:"-" toml:"-"` // File the configuration was loaded from
+}
+
+// LimitsConfig holds the tool call and response size limits shared by all
+// servers. They are pointers because zero turns a limit off rather than
+// leaving it unset.
+type LimitsConfig struct {
+	MaxConcurrent        *int `yaml:"max_concurrent" toml:"max_concurrent"`                 // MCP_MAX_CONCURRENT
+	SessionMaxConcurrent *int `yaml:"session_max_concurrent" toml:"session_max_concurrent"` // MCP_SESSION_MAX_CONCURRENT
+	SessionRate          *int `yaml:"session_rate" toml:"session_rate"`                     // MCP_SESSION_RATE, calls per minute
+	MaxResponseBytes     *int `yaml:"max_response_bytes" toml:"max_response_bytes"`         // MCP_MAX_RESPONSE_BYTES
+}
+
+// DocumentConfig holds the document server's settings
+type DocumentConfig struct {
+	AllowedRoots    []string `yaml:"allowed_roots" toml:"allowed_roots"` // Used when no roots are given as arguments
+	CacheSize       int      `yaml:"cache_size" toml:"cache_size"`
+	CacheTTLMinutes int      `yaml:"cache_ttl_minutes" toml:"cache_ttl_minutes"`
+	MaxFileSizeMB   int      `yaml:"max_file_size_mb" toml:"max_file_size_mb"`
+	TimeoutSeconds  int      `yaml:"timeout_seconds" toml:"timeout_seconds"`
+	Converters      string   `yaml:"converters" toml:"converters"`
+
+	AllowInternalURLs bool `yaml:"allow_internal_urls" toml:"allow_internal_urls"`
+}
+
+// ExcelConfig holds the Excel server's settings
+type ExcelConfig struct {
+	AllowedRoots    []string `yaml:"allowed_roots" toml:"allowed_roots"` // Used when no roots are given as arguments
+	CacheSize       int      `yaml:"cache_size" toml:"cache_size"`
+	CacheTTLMinutes int      `yaml:"cache_ttl_minutes" toml:"cache_ttl_minutes"`
+}
+
+// FilesystemConfig holds the filesystem server's settings
+type FilesystemConfig struct {
+	AllowedRoots []string `yaml:"allowed_roots" toml:"allowed_roots"` // Used when no roots are given as arguments
+	MaxBytes     int64    `yaml:"max_bytes" toml:"max_bytes"`
+	MaxFiles     int      `yaml:"max_files" toml:"max_files"`
+	AllowWrite   bool     `yaml:"allow_write" toml:"allow_write"`
+}
+
+// OutlookConfig holds the Outlook server's settings
+type OutlookConfig struct {
+	Backend              string   `yaml:"backend" toml:"backend"`
+	AllowSend            bool     `yaml:"allow_send" toml:"allow_send"`
+	AllowPermanentDelete bool     `yaml:"allow_permanent_delete" toml:"allow_permanent_delete"`
+	SaveRoots            []string `yaml:"save_roots" toml:"save_roots"` // OUTLOOK_SAVE_ROOTS
+	ServerPort           int      `yaml:"server_port" toml:"server_port"`
+	Instance             string   `yaml:"instance" toml:"instance"` // OUTLOOK_INSTANCE
+	PowerShell           string   `yaml:"powershell" toml:"powershell"`
+	PowerShellArgs       []string `yaml:"powershell_args" toml:"powershell_args"`
+	ServerScript         string   `yaml:"server_script" toml:"server_script"`
+	CacheSize            int      `yaml:"cache_size" toml:"cache_size"`
+	CacheTTLSeconds      int      `yaml:"cache_ttl_seconds" toml:"cache_ttl_seconds"`
+	LogLines             int      `yaml:"log_lines" toml:"log_lines"`
+	GraphClientID        string   `yaml:"graph_client_id" toml:"graph_client_id"`
+	GraphTenant          string   `yaml:"graph_tenant" toml:"graph_tenant"`
+}
+
+// configFileNames are looked for, in order, in the user's config directory
+var configFileNames = []string{"config.yaml", "config.yml", "config.toml"}
+
+// FindConfig returns the configuration file to load: path when given, else
+// MY_MCP_CONFIG, else the first of config.yaml, config.yml and config.toml in
+// the my-mcp directory of the user's config directory (~/.config/my-mcp on
+// Linux). It returns "" when there is none.
+func FindConfig(path string) string {
+	if path != "" {
+		return path
+	}
+	if path := os.Getenv("MY_MCP_CONFIG"); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	for _, name := range configFileNames {
+		candidate := filepath.Join(dir, "my-mcp", name)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// LoadConfig reads a YAML (.yaml, .yml) or TOML (.toml) configuration file.
+// Unknown settings are rejected, and relative paths are resolved against
+// the file's directory.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	config := &Config{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+		}
+	case ".toml":
+		metadata, err := toml.Decode(string(data), config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+		}
+		if undecoded := metadata.Undecoded(); len(undecoded) > 0 {
+			return nil, fmt.Errorf("failed to parse config %s: unknown setting %s", path, undecoded[0])
+		}
+	default:
+		return nil, fmt.Errorf("unsupported config format %q: use .yaml, .yml or .toml", filepath.Ext(path))
+	}
+
+	dir := filepath.Dir(path)
+	resolve := func(p string) string {
+		if p == "" || filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(dir, p)
+	}
+	for i, root := range config.Document.AllowedRoots {
+		config.Document.AllowedRoots[i] = resolve(root)
+	}
+	for i, root := range config.Excel.AllowedRoots {
+		config.Excel.AllowedRoots[i] = resolve(root)
+	}
+	for i, root := range config.Filesystem.AllowedRoots {
+		config.Filesystem.AllowedRoots[i] = resolve(root)
+	}
+	for i, root := range config.Outlook.SaveRoots {
+		config.Outlook.SaveRoots[i] = resolve(root)
+	}
+	config.Document.Converters = resolve(config.Document.Converters)
+	config.Outlook.ServerScript = resolve(config.Outlook.ServerScript)
+	config.LogFile = resolve(config.LogFile)
+	config.Path = path
+
+	return config, nil
+}
+
+// Environment returns the environment variables for the settings the file
+// sets; settings left out keep their servers' defaults
+func (c *Config) Environment() map[string]string {
+	env := make(map[string]string)
+	setString := func(name, value string) {
+		if value != "" {
+			env[name] = value
+		}
+	}
+	setInt := func(name string, value int64) {
+		if value > 0 {
+			env[name] = strconv.FormatInt(value, 10)
+		}
+	}
+	setBool := func(name string, value bool) {
+		if value {
+			env[name] = "true"
+		}
+	}
+	setLimit := func(name string, value *int) {
+		if value != nil && *value >= 0 {
+			env[name] = strconv.Itoa(*value)
+		}
+	}
+
+	setString("MCP_TRANSPORT", c.Transport)
+	setString("MCP_LISTEN", c.Listen)
+	setBool("MCP_METRICS", c.Metrics)
+	setString("MCP_ALLOWED_HOSTS", strings.Join(c.AllowedHosts, ","))
+
+	setLimit("MCP_MAX_CONCURRENT", c.Limits.MaxConcurrent)
+	setLimit("MCP_SESSION_MAX_CONCURRENT", c.Limits.SessionMaxConcurrent)
+	setLimit("MCP_SESSION_RATE", c.Limits.SessionRate)
+	setLimit("MCP_MAX_RESPONSE_BYTES", c.Limits.MaxResponseBytes)
+
+	setInt("DOCUMENT_CACHE_MAX_SIZE", int64(c.Document.CacheSize))
+	setInt("DOCUMENT_CACHE_TTL_MINUTES", int64(c.Document.CacheTTLMinutes))
+	setInt("DOCUMENT_MAX_FILE_SIZE_MB", int64(c.Document.MaxFileSizeMB))
+	setInt("DOCUMENT_EXTRACT_TIMEOUT_SECONDS", int64(c.Document.TimeoutSeconds))
+	setString("DOCUMENT_CONVERTERS", c.Document.Converters)
+	setBool("DOCUMENT_ALLOW_INTERNAL_URLS", c.Document.AllowInternalURLs)
+
+	setInt("EXCEL_CACHE_MAX_SIZE", int64(c.Excel.CacheSize))
+	setInt("EXCEL_CACHE_TTL_MINUTES", int64(c.Excel.CacheTTLMinutes))
+
+	setInt("FS_QUOTA_MAX_BYTES", c.Filesystem.MaxBytes)
+	setInt("FS_QUOTA_MAX_FILES", int64(c.Filesystem.MaxFiles))
+	setBool("FS_ALLOW_WRITE", c.Filesystem.AllowWrite)
+
+	setString("OUTLOOK_BACKEND", c.Outlook.Backend)
+	setBool("OUTLOOK_ALLOW_SEND", c.Outlook.AllowSend)
+	setBool("OUTLOOK_ALLOW_PERMANENT_DELETE", c.Outlook.AllowPermanentDelete)
+	setString("OUTLOOK_SAVE_ROOTS", strings.Join(c.Outlook.SaveRoots, string(os.PathListSeparator)))
+	setInt("OUTLOOK_SERVER_PORT", int64(c.Outlook.ServerPort))
+	setString("OUTLOOK_INSTANCE", c.Outlook.Instance)
+	setString("OUTLOOK_POWERSHELL", c.Outlook.PowerShell)
+	setString("OUTLOOK_POWERSHELL_ARGS", strings.Join(c.Outlook.PowerShellArgs, " "))
+	setString("OUTLOOK_SERVER_SCRIPT", c.Outlook.ServerScript)
+	setInt("OUTLOOK_CACHE_MAX_SIZE", int64(c.Outlook.CacheSize))
+	setInt("OUTLOOK_CACHE_TTL_SECONDS", int64(c.Outlook.CacheTTLSeconds))
+	setInt("OUTLOOK_LOG_LINES", int64(c.Outlook.LogLines))
+	setString("OUTLOOK_GRAPH_CLIENT_ID", c.Outlook.GraphClientID)
+	setString("OUTLOOK_GRAPH_TENANT", c.Outlook.GraphTenant)
+
+	return env
+}
+
+// Apply exports the file's settings to environment variables that aren't
+// already set and sends the standard logger to the configured log file
+func (c *Config) Apply() error {
+	for name, value := range c.Environment() {
+		if _, set := os.LookupEnv(name); !set {
+			os.Setenv(name, value)
+		}
+	}
+
+	if c.LogFile != "" {
+		file, err := os.OpenFile(c.LogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+		log.SetOutput(file)
+	}
+	return nil
+}
+
+// LoadConfigFile finds, loads and applies the configuration file (see
+// FindConfig). Without a file it returns an empty configuration; a file
+// named explicitly or by MY_MCP_CONFIG must exist.
+func LoadConfigFile(path string) (*Config, error) {
+	path = FindConfig(path)
+	if path == "" {
+		return &Config{}, nil
+	}
+	config, err := LoadConfig(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("config file not found: %s", path)
+		}
+		return nil, err
+	}
+	if err := config.Apply(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
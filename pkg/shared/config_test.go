@@ -0,0 +1,155 @@
+package shared
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfigFormats(t *testing.T) {
+	yamlPath := writeConfig(t, "config.yaml", `
+transport: sse
+limits:
+  session_max_concurrent: 0
+  session_rate: 120
+document:
+  allowed_roots: [docs, /srv/shared]
+  cache_size: 50
+  converters: converters.json
+excel:
+  allowed_roots: [workbooks]
+filesystem:
+  max_bytes: 1048576
+  allow_write: true
+outlook:
+  backend: graph
+  allow_send: true
+  save_roots: [attachments, /srv/mail]
+  instance: work
+  powershell: pwsh.exe
+  powershell_args: [-NoProfile, -NonInteractive]
+  server_script: scripts/outlook-server.ps1
+`)
+	tomlPath := writeConfig(t, "config.toml", `
+transport = "sse"
+
+[limits]
+session_max_concurrent = 0
+session_rate = 120
+
+[document]
+allowed_roots = ["docs", "/srv/shared"]
+cache_size = 50
+converters = "converters.json"
+
+[excel]
+allowed_roots = ["workbooks"]
+
+[filesystem]
+max_bytes = 1048576
+allow_write = true
+
+[outlook]
+backend = "graph"
+allow_send = true
+save_roots = ["attachments", "/srv/mail"]
+instance = "work"
+powershell = "pwsh.exe"
+powershell_args = ["-NoProfile", "-NonInteractive"]
+server_script = "scripts/outlook-server.ps1"
+`)
+
+	for _, path := range []string{yamlPath, tomlPath} {
+		config, err := LoadConfig(path)
+		if err != nil {
+			t.Fatalf("LoadConfig(%s) failed: %v", filepath.Base(path), err)
+		}
+		dir := filepath.Dir(path)
+		if got := config.Document.AllowedRoots; len(got) != 2 || got[0] != filepath.Join(dir, "docs") || got[1] != "/srv/shared" {
+			t.Errorf("%s: allowed roots = %v, want docs resolved against the config directory", filepath.Base(path), got)
+		}
+		if got := config.Excel.AllowedRoots; len(got) != 1 || got[0] != filepath.Join(dir, "workbooks") {
+			t.Errorf("%s: Excel allowed roots = %v, want workbooks resolved against the config directory", filepath.Base(path), got)
+		}
+
+		env := config.Environment()
+		want := map[string]string{
+			"MCP_TRANSPORT":              "sse",
+			"MCP_SESSION_MAX_CONCURRENT": "0",
+			"MCP_SESSION_RATE":           "120",
+			"DOCUMENT_CACHE_MAX_SIZE":    "50",
+			"DOCUMENT_CONVERTERS":        filepath.Join(dir, "converters.json"),
+			"FS_QUOTA_MAX_BYTES":         "1048576",
+			"FS_ALLOW_WRITE":             "true",
+			"OUTLOOK_BACKEND":            "graph",
+			"OUTLOOK_ALLOW_SEND":         "true",
+			"OUTLOOK_SAVE_ROOTS":         filepath.Join(dir, "attachments") + string(os.PathListSeparator) + "/srv/mail",
+			"OUTLOOK_INSTANCE":           "work",
+			"OUTLOOK_POWERSHELL":         "pwsh.exe",
+			"OUTLOOK_POWERSHELL_ARGS":    "-NoProfile -NonInteractive",
+			"OUTLOOK_SERVER_SCRIPT":      filepath.Join(dir, "scripts", "outlook-server.ps1"),
+		}
+		for name, value := range want {
+			if env[name] != value {
+				t.Errorf("%s: %s = %q, want %q", filepath.Base(path), name, env[name], value)
+			}
+		}
+		if len(env) != len(want) {
+			t.Errorf("%s: environment = %v, want only the settings given", filepath.Base(path), env)
+		}
+	}
+}
+
+func TestLoadConfigRejectsUnknownSettings(t *testing.T) {
+	for name, content := range map[string]string{
+		"config.yaml": "excel:\n  cache_sise: 5\n",
+		"config.toml": "[excel]\ncache_sise = 5\n",
+		"config.json": "{}",
+	} {
+		if _, err := LoadConfig(writeConfig(t, name, content)); err == nil {
+			t.Errorf("LoadConfig(%s) succeeded, want an error", name)
+		}
+	}
+}
+
+func TestConfigApplyKeepsEnvironment(t *testing.T) {
+	t.Setenv("EXCEL_CACHE_MAX_SIZE", "3")
+	t.Setenv("EXCEL_CACHE_TTL_MINUTES", "")
+	os.Unsetenv("EXCEL_CACHE_TTL_MINUTES")
+
+	config := &Config{Excel: ExcelConfig{CacheSize: 20, CacheTTLMinutes: 15}}
+	if err := config.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if got := os.Getenv("EXCEL_CACHE_MAX_SIZE"); got != "3" {
+		t.Errorf("EXCEL_CACHE_MAX_SIZE = %q, want the environment's 3", got)
+	}
+	if got := os.Getenv("EXCEL_CACHE_TTL_MINUTES"); got != "15" {
+		t.Errorf("EXCEL_CACHE_TTL_MINUTES = %q, want the file's 15", got)
+	}
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	t.Setenv("MY_MCP_CONFIG", filepath.Join(t.TempDir(), "missing.yaml"))
+	if _, err := LoadConfigFile(""); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("LoadConfigFile with a missing MY_MCP_CONFIG: error = %v, want not found", err)
+	}
+
+	t.Setenv("MY_MCP_CONFIG", "")
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	config, err := LoadConfigFile("")
+	if err != nil || config == nil {
+		t.Errorf("LoadConfigFile without a file = %v, %v, want an empty configuration", config, err)
+	}
+}
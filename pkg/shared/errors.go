@@ -0,0 +1,153 @@
+package shared
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// Error codes of the error envelope every server returns in error results,
+// so clients can branch on the kind of failure instead of its wording
+const (
+	CodeInvalidArgument   = "INVALID_ARGUMENT"   // Missing or malformed parameters
+	CodeNotFound          = "NOT_FOUND"          // File, sheet, message or other item doesn't exist
+	CodeAccessDenied      = "ACCESS_DENIED"      // Outside the allowed roots, disabled by the server, or a missing password
+	CodeUnsupportedFormat = "UNSUPPORTED_FORMAT" // File type or encoding the server can't read
+	CodeTooLarge          = "TOO_LARGE"          // Input exceeds a configured size limit
+	CodeQuotaExceeded     = "QUOTA_EXCEEDED"     // Session quota used up
+	CodeTimeout           = "TIMEOUT"            // Operation ran out of time
+	CodeRateLimited       = "RATE_LIMITED"       // Call limits reached, or an upstream service asked to slow down
+	CodeUnavailable       = "UNAVAILABLE"        // Backend unreachable or failing
+	CodeCancelled         = "CANCELLED"          // Request was cancelled
+	CodeInternal          = "INTERNAL"           // Anything else
+)
+
+// retryableCodes are failures that may succeed when retried unchanged
+var retryableCodes = map[string]bool{
+	CodeTimeout:     true,
+	CodeRateLimited: true,
+	CodeUnavailable: true,
+}
+
+// ToolError is an error carrying an envelope code. Packages return it where
+// the code can't be told from the cause, e.g. a path outside the allowed
+// roots; fmt.Errorf wrapping keeps the code.
+type ToolError struct {
+	Code      string         `json:"code"`
+	Message   string         `json:"message"`
+	Details   map[string]any `json:"details,omitempty"`
+	Retryable bool           `json:"retryable"`
+	err       error          // Wrapped cause, if any
+}
+
+func (e *ToolError) Error() string { return e.Message }
+
+func (e *ToolError) Unwrap() error { return e.err }
+
+// WithDetail adds a detail for clients, such as the offending parameter
+func (e *ToolError) WithDetail(key string, value any) *ToolError {
+	if e.Details == nil {
+		e.Details = make(map[string]any)
+	}
+	e.Details[key] = value
+	return e
+}
+
+// NewError formats an error with an envelope code; %w wraps causes as with
+// fmt.Errorf
+func NewError(code, format string, args ...any) *ToolError {
+	err := fmt.Errorf(format, args...)
+	return &ToolError{Code: code, Message: err.Error(), Retryable: retryableCodes[code], err: err}
+}
+
+// CodeForHTTPStatus maps a backend's HTTP error status to an envelope code
+func CodeForHTTPStatus(status int) string {
+	switch {
+	case status == http.StatusBadRequest, status == http.StatusUnprocessableEntity:
+		return CodeInvalidArgument
+	case status == http.StatusUnauthorized, status == http.StatusForbidden:
+		return CodeAccessDenied
+	case status == http.StatusNotFound, status == http.StatusGone:
+		return CodeNotFound
+	case status == http.StatusRequestTimeout, status == http.StatusGatewayTimeout:
+		return CodeTimeout
+	case status == http.StatusRequestEntityTooLarge:
+		return CodeTooLarge
+	case status == http.StatusUnsupportedMediaType:
+		return CodeUnsupportedFormat
+	case status == http.StatusTooManyRequests:
+		return CodeRateLimited
+	case status >= http.StatusInternalServerError:
+		return CodeUnavailable
+	}
+	return CodeInternal
+}
+
+// AsToolError classifies err for the envelope. A ToolError in its chain
+// keeps its code and details with the full message of err, and errors with
+// an ErrorCode() string method report their own code; well-known causes such
+// as missing files and deadlines get their codes; anything else is INTERNAL.
+func AsToolError(err error) *ToolError {
+	var toolErr *ToolError
+	if errors.As(err, &toolErr) {
+		classified := *toolErr
+		classified.Message = err.Error()
+		classified.err = err
+		return &classified
+	}
+
+	code := CodeInternal
+	var coded interface{ ErrorCode() string }
+	var netErr net.Error
+	switch {
+	case errors.As(err, &coded):
+		code = coded.ErrorCode()
+	case errors.Is(err, fs.ErrNotExist):
+		code = CodeNotFound
+	case errors.Is(err, fs.ErrPermission):
+		code = CodeAccessDenied
+	case errors.Is(err, context.DeadlineExceeded):
+		code = CodeTimeout
+	case errors.Is(err, context.Canceled):
+		code = CodeCancelled
+	case errors.As(err, &netErr):
+		code = CodeUnavailable
+	}
+	return &ToolError{Code: code, Message: err.Error(), Retryable: retryableCodes[code], err: err}
+}
+
+// ErrorEnvelope is the JSON body of every error tool result:
+// {"error": {"code", "message", "details", "retryable"}}
+type ErrorEnvelope struct {
+	Error *ToolError `json:"error"`
+}
+
+// ErrorResult returns err as an error tool result carrying the envelope
+func ErrorResult(err error) *mcp.CallToolResult {
+	envelope := ErrorEnvelope{Error: AsToolError(err)}
+	data, marshalErr := json.MarshalIndent(envelope, "", "  ")
+	if marshalErr != nil {
+		// Details that don't marshal are dropped rather than losing the error
+		envelope.Error.Details = nil
+		data, _ = json.MarshalIndent(envelope, "", "  ")
+	}
+	return mcp.NewToolResultError(string(data))
+}
+
+// ErrorResultf returns an error tool result with a code and a formatted
+// message, for failures handlers detect themselves such as missing parameters
+func ErrorResultf(code, format string, args ...any) *mcp.CallToolResult {
+	return ErrorResult(NewError(code, format, args...))
+}
+
+// ErrorResultFromErr returns err as an error tool result whose message is
+// prefixed with text, keeping err's code
+func ErrorResultFromErr(text string, err error) *mcp.CallToolResult {
+	return ErrorResult(fmt.Errorf("%s: %w", text, err))
+}
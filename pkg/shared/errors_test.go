@@ -0,0 +1,62 @@
+package shared
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type codedError struct{}
+
+func (codedError) Error() string     { return "password required" }
+func (codedError) ErrorCode() string { return CodeAccessDenied }
+
+func TestAsToolError(t *testing.T) {
+	_, notExist := os.Stat("/nonexistent/report.pdf")
+	tests := []struct {
+		err       error
+		code      string
+		retryable bool
+	}{
+		{NewError(CodeUnsupportedFormat, "unsupported file format: .xyz"), CodeUnsupportedFormat, false},
+		{fmt.Errorf("failed to open: %w", NewError(CodeAccessDenied, "access denied")), CodeAccessDenied, false},
+		{fmt.Errorf("failed to open file: %w", notExist), CodeNotFound, false},
+		{fmt.Errorf("extraction timed out: %w", context.DeadlineExceeded), CodeTimeout, true},
+		{NewError(CodeForHTTPStatus(503), "server error (503)"), CodeUnavailable, true},
+		{fmt.Errorf("failed to decrypt: %w", codedError{}), CodeAccessDenied, false},
+		{fmt.Errorf("something broke"), CodeInternal, false},
+	}
+	for _, test := range tests {
+		toolErr := AsToolError(test.err)
+		if toolErr.Code != test.code || toolErr.Retryable != test.retryable || toolErr.Message != test.err.Error() {
+			t.Errorf("AsToolError(%q) = %+v, want code %s retryable %v", test.err, toolErr, test.code, test.retryable)
+		}
+	}
+}
+
+func TestErrorResultEnvelope(t *testing.T) {
+	result := ErrorResultFromErr("Failed to list messages", NewError(CodeNotFound, "folder %q not found", "Archive").WithDetail("folder", "Archive"))
+	if !result.IsError {
+		t.Fatal("Expected an error result")
+	}
+
+	var envelope struct {
+		Error struct {
+			Code      string         `json:"code"`
+			Message   string         `json:"message"`
+			Details   map[string]any `json:"details"`
+			Retryable bool           `json:"retryable"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].(mcp.TextContent).Text), &envelope); err != nil {
+		t.Fatalf("Error result is not an envelope: %v", err)
+	}
+	if envelope.Error.Code != CodeNotFound || envelope.Error.Message != `Failed to list messages: folder "Archive" not found` ||
+		envelope.Error.Details["folder"] != "Archive" || envelope.Error.Retryable {
+		t.Errorf("Unexpected envelope %+v", envelope.Error)
+	}
+}
@@ -112,7 +112,7 @@ func (je *JSONEncoder) UnmarshalRequest(request mcp.CallToolRequest, args interf
 func (je *JSONEncoder) NewToolResultJSON(data interface{}) (*mcp.CallToolResult, error) {
 	jsonBytes, err := je.Marshal(data)
 	if err != nil {
-		return mcp.NewToolResultError("Failed to marshal response: " + err.Error()), nil
+		return ErrorResultFromErr("Failed to marshal response", err), nil
 	}
 	return mcp.NewToolResultText(string(jsonBytes)), nil
 }
@@ -121,7 +121,7 @@ func (je *JSONEncoder) NewToolResultJSON(data interface{}) (*mcp.CallToolResult,
 func (je *JSONEncoder) NewToolResultJSONIndent(data interface{}, prefix, indent string) (*mcp.CallToolResult, error) {
 	jsonBytes, err := je.MarshalIndent(data, prefix, indent)
 	if err != nil {
-		return mcp.NewToolResultError("Failed to marshal response: " + err.Error()), nil
+		return ErrorResultFromErr("Failed to marshal response", err), nil
 	}
 	return mcp.NewToolResultText(string(jsonBytes)), nil
 }
@@ -0,0 +1,148 @@
+// Package sandbox restricts file access to a set of allowed root
+// directories. Every server that takes paths from tool arguments enforces
+// the same policy through it: paths are cleaned and made absolute, must be a
+// root or lie under one, and may not escape a root through a symlink.
+package sandbox
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kevsmith/my-mcp/pkg/shared"
+)
+
+// Sandbox restricts file access to a set of allowed root directories
+type Sandbox struct {
+	roots            []string // Cleaned absolute roots without trailing separators
+	prefixes         []string // Roots with trailing separators for prefix matching
+	resolved         []string // Roots with symlinks resolved, for checking symlink targets
+	resolvedPrefixes []string
+}
+
+// New cleans the allowed roots and checks that each is an existing directory
+func New(allowedRoots []string) (*Sandbox, error) {
+	if len(allowedRoots) == 0 {
+		return nil, fmt.Errorf("at least one allowed root directory is required")
+	}
+
+	s := &Sandbox{}
+	for _, root := range allowedRoots {
+		absRoot, err := filepath.Abs(filepath.Clean(root))
+		if err != nil {
+			return nil, fmt.Errorf("invalid root path %s: %w", root, err)
+		}
+
+		info, err := os.Stat(absRoot)
+		if err != nil {
+			return nil, fmt.Errorf("root path %s does not exist: %w", absRoot, err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("root path %s is not a directory", absRoot)
+		}
+
+		resolvedRoot, err := filepath.EvalSymlinks(absRoot)
+		if err != nil {
+			return nil, fmt.Errorf("invalid root path %s: %w", absRoot, err)
+		}
+
+		s.roots = append(s.roots, absRoot)
+		s.prefixes = append(s.prefixes, withSeparator(absRoot))
+		s.resolved = append(s.resolved, resolvedRoot)
+		s.resolvedPrefixes = append(s.resolvedPrefixes, withSeparator(resolvedRoot))
+	}
+	return s, nil
+}
+
+// Optional returns a sandbox for the allowed roots, or nil when there are
+// none, for servers where roots are an opt-in restriction
+func Optional(allowedRoots []string) (*Sandbox, error) {
+	if len(allowedRoots) == 0 {
+		return nil, nil
+	}
+	return New(allowedRoots)
+}
+
+// Roots returns the cleaned absolute allowed roots
+func (s *Sandbox) Roots() []string {
+	return s.roots
+}
+
+// Allowed reports whether an absolute path is an allowed root or lies under
+// one. The check is lexical; Validate also follows symlinks.
+func (s *Sandbox) Allowed(path string) bool {
+	return underRoot(filepath.Clean(path), s.roots, s.prefixes)
+}
+
+// Check validates path like Validate, except that a nil sandbox accepts any
+// path. Servers whose roots are optional keep a nil sandbox without them.
+func (s *Sandbox) Check(path string) error {
+	if s == nil {
+		return nil
+	}
+	_, err := s.Validate(path)
+	return err
+}
+
+// Resolve interprets path relative to base when it isn't absolute, then
+// validates the result
+func (s *Sandbox) Resolve(base, path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(base, path)
+	}
+	return s.Validate(path)
+}
+
+// Validate resolves path to a clean absolute path and checks that it, and the
+// target of any symlink along it, stays under an allowed root
+func (s *Sandbox) Validate(path string) (string, error) {
+	// NUL bytes are never valid in paths and are rejected by the OS inconsistently
+	if strings.ContainsRune(path, 0) {
+		return "", shared.NewError(shared.CodeInvalidArgument, "invalid path: contains NUL byte")
+	}
+
+	absPath, err := filepath.Abs(filepath.Clean(path))
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve absolute path: %w", err)
+	}
+	if !s.Allowed(absPath) {
+		return "", shared.NewError(shared.CodeAccessDenied, "access denied: path outside allowed roots")
+	}
+
+	// A symlink inside a root may point outside it. Paths that don't exist
+	// yet, such as output files, are checked through their nearest existing
+	// parent.
+	existing := absPath
+	resolved, err := filepath.EvalSymlinks(existing)
+	for err != nil && filepath.Dir(existing) != existing {
+		existing = filepath.Dir(existing)
+		resolved, err = filepath.EvalSymlinks(existing)
+	}
+	if err == nil && !underRoot(resolved, s.resolved, s.resolvedPrefixes) {
+		return "", shared.NewError(shared.CodeAccessDenied, "access denied: path outside allowed roots")
+	}
+
+	return absPath, nil
+}
+
+func underRoot(path string, roots, prefixes []string) bool {
+	for _, root := range roots {
+		if path == root {
+			return true
+		}
+	}
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func withSeparator(path string) string {
+	if strings.HasSuffix(path, string(filepath.Separator)) {
+		return path
+	}
+	return path + string(filepath.Separator)
+}
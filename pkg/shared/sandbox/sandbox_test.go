@@ -0,0 +1,100 @@
+package sandbox
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kevsmith/my-mcp/pkg/shared"
+)
+
+func TestValidate(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "docs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "escape")); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	s, err := New([]string{root + string(filepath.Separator)})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	tests := map[string]bool{
+		root: true,
+		filepath.Join(root, "docs", "report.pdf"): true,
+		filepath.Join(root, "new", "out.md"):      true, // Output paths need not exist
+		filepath.Join(root, "..", "other.pdf"):    false,
+		root + "-sibling/report.pdf":              false,
+		filepath.Join(outside, "report.pdf"):      false,
+		filepath.Join(root, "escape", "secret"):   false, // Symlink out of the root
+		filepath.Join(root, "docs") + "\x00.pdf":  false,
+	}
+	for path, allowed := range tests {
+		if _, err := s.Validate(path); (err == nil) != allowed {
+			t.Errorf("Validate(%q) error = %v, want allowed %v", path, err, allowed)
+		}
+	}
+
+	var toolErr *shared.ToolError
+	if _, err := s.Validate(filepath.Join(outside, "report.pdf")); !errors.As(err, &toolErr) || toolErr.Code != shared.CodeAccessDenied {
+		t.Errorf("Expected ACCESS_DENIED outside the roots, got %v", err)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	root := t.TempDir()
+	s, err := New([]string{root})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	docs := filepath.Join(root, "docs")
+
+	if path, err := s.Resolve(docs, "report.pdf"); err != nil || path != filepath.Join(docs, "report.pdf") {
+		t.Errorf("Resolve relative = %q, %v", path, err)
+	}
+	if path, err := s.Resolve(docs, filepath.Join(root, "notes.txt")); err != nil || path != filepath.Join(root, "notes.txt") {
+		t.Errorf("Resolve absolute = %q, %v", path, err)
+	}
+	if _, err := s.Resolve(docs, filepath.Join("..", "..", "etc")); err == nil {
+		t.Error("Expected a relative path climbing out of the roots to be rejected")
+	}
+}
+
+func TestOptionalSandbox(t *testing.T) {
+	s, err := Optional(nil)
+	if err != nil || s != nil {
+		t.Fatalf("Optional(nil) = %v, %v; want no sandbox", s, err)
+	}
+	if err := s.Check("/anywhere/at/all"); err != nil {
+		t.Errorf("A nil sandbox rejected a path: %v", err)
+	}
+
+	root := t.TempDir()
+	if s, err = Optional([]string{root}); err != nil || s == nil {
+		t.Fatalf("Optional(roots) = %v, %v", s, err)
+	}
+	if err := s.Check(filepath.Join(root, "file.txt")); err != nil {
+		t.Errorf("Check rejected a path under the root: %v", err)
+	}
+	if err := s.Check(filepath.Join(filepath.Dir(root), "file.txt")); err == nil {
+		t.Error("Check accepted a path outside the root")
+	}
+}
+
+func TestNewRejectsInvalidRoots(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file.txt")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, roots := range [][]string{nil, {file}, {filepath.Join(file, "missing")}} {
+		if _, err := New(roots); err == nil {
+			t.Errorf("New(%v) succeeded, want an error", roots)
+		}
+	}
+}
@@ -0,0 +1,9 @@
+package shared
+
+// CacheStats reports a cache's lookups since the server started and its
+// current size, for metrics
+type CacheStats struct {
+	Hits    int64
+	Misses  int64
+	Entries int
+}
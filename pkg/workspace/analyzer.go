@@ -0,0 +1,322 @@
+package workspace
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kevsmith/my-mcp/pkg/document"
+	"github.com/kevsmith/my-mcp/pkg/excel"
+	"github.com/kevsmith/my-mcp/pkg/outlook"
+)
+
+// Magic numbers used to sniff saved attachments
+var (
+	pdfMagic = []byte{0x25, 0x50, 0x44, 0x46}             // %PDF
+	zipMagic = []byte{0x50, 0x4B, 0x03, 0x04}             // PK.. (DOCX, PPTX, XLSX, ODT, EPUB)
+	oleMagic = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1} // OLE compound file (DOC, PPT, XLS)
+	rtfMagic = []byte(`{\rtf`)
+)
+
+// previewLength is the number of characters of extracted document text included in a summary
+const previewLength = 1500
+
+// AttachmentKind identifies which toolset an attachment was routed to
+type AttachmentKind string
+
+const (
+	AttachmentKindSpreadsheet AttachmentKind = "spreadsheet"
+	AttachmentKindDocument    AttachmentKind = "document"
+	AttachmentKindUnsupported AttachmentKind = "unsupported"
+)
+
+// AttachmentSource is the part of the Outlook manager the analyzer depends on
+type AttachmentSource interface {
+	ListAttachments(messageID string) (*outlook.AttachmentListResponse, error)
+	SaveAttachment(messageID string, index int, directory string) (*outlook.SaveAttachmentResponse, error)
+}
+
+// SheetSummary describes one worksheet of a spreadsheet attachment
+type SheetSummary struct {
+	Name         string   `json:"name"`
+	RowCount     int      `json:"row_count"`
+	ColumnCount  int      `json:"column_count"`
+	Headers      []string `json:"headers,omitempty"`
+	FormulaCount int      `json:"formula_count"`
+}
+
+// DocumentSummary describes the text extracted from a document attachment
+type DocumentSummary struct {
+	WordCount int    `json:"word_count"`
+	Preview   string `json:"preview"`
+	Truncated bool   `json:"truncated"`
+}
+
+// AttachmentAnalysis is the unified result of analyze_email_attachment
+type AttachmentAnalysis struct {
+	MessageID   string           `json:"message_id"`
+	Index       int              `json:"index"`
+	FileName    string           `json:"file_name"`
+	Path        string           `json:"path"`
+	Size        int64            `json:"size"`
+	Kind        AttachmentKind   `json:"kind"`
+	Format      string           `json:"format"`
+	Sheets      []SheetSummary   `json:"sheets,omitempty"`
+	Document    *DocumentSummary `json:"document,omitempty"`
+	Unsupported string           `json:"unsupported_reason,omitempty"`
+}
+
+// Analyzer saves Outlook attachments and routes them to the Excel or document
+// extraction logic based on their content
+type Analyzer struct {
+	attachments AttachmentSource
+	excel       *excel.Manager
+	documents   *document.Manager
+}
+
+// NewAnalyzer creates an analyzer over the given Outlook, Excel and document managers
+func NewAnalyzer(attachments AttachmentSource, excelManager *excel.Manager, documentManager *document.Manager) *Analyzer {
+	return &Analyzer{
+		attachments: attachments,
+		excel:       excelManager,
+		documents:   documentManager,
+	}
+}
+
+// AnalyzeAttachment saves an attachment of a message to directory and summarizes it.
+// Document extraction stops when ctx is done.
+// An index of 0 selects the first attachment that isn't inline in the message body.
+func (a *Analyzer) AnalyzeAttachment(ctx context.Context, messageID string, index int, directory string) (*AttachmentAnalysis, error) {
+	if index == 0 {
+		selected, err := a.selectAttachment(messageID)
+		if err != nil {
+			return nil, err
+		}
+		index = selected
+	}
+
+	saved, err := a.attachments.SaveAttachment(messageID, index, directory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save attachment: %w", err)
+	}
+
+	analysis := &AttachmentAnalysis{
+		MessageID: messageID,
+		Index:     saved.Index,
+		FileName:  saved.FileName,
+		Path:      saved.Path,
+		Size:      saved.Size,
+	}
+
+	format, err := sniffFormat(saved.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect saved attachment: %w", err)
+	}
+	analysis.Format = format
+
+	switch format {
+	case "xlsx":
+		analysis.Kind = AttachmentKindSpreadsheet
+		path, err := ensureExtension(saved.Path, ".xlsx", ".xlsm")
+		if err != nil {
+			return nil, err
+		}
+		analysis.Path = path
+		sheets, err := a.summarizeSpreadsheet(path)
+		if err != nil {
+			return nil, err
+		}
+		analysis.Sheets = sheets
+	case "pdf", "docx", "pptx", "doc", "ppt", "rtf", "odt", "epub":
+		analysis.Kind = AttachmentKindDocument
+		path, err := ensureExtension(saved.Path, "."+format)
+		if err != nil {
+			return nil, err
+		}
+		analysis.Path = path
+		summary, err := a.summarizeDocument(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		analysis.Document = summary
+	default:
+		analysis.Kind = AttachmentKindUnsupported
+		analysis.Unsupported = fmt.Sprintf("no analyzer for %s content; supported formats are xlsx, pdf, docx, pptx, doc, ppt, rtf, odt and epub", format)
+	}
+
+	return analysis, nil
+}
+
+// selectAttachment returns the index of the first non-inline attachment of a message
+func (a *Analyzer) selectAttachment(messageID string) (int, error) {
+	list, err := a.attachments.ListAttachments(messageID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list attachments: %w", err)
+	}
+
+	for _, attachment := range list.Attachments {
+		if !attachment.Inline {
+			return attachment.Index, nil
+		}
+	}
+
+	return 0, fmt.Errorf("message has no attachments to analyze")
+}
+
+// summarizeSpreadsheet describes every sheet of a workbook
+func (a *Analyzer) summarizeSpreadsheet(path string) ([]SheetSummary, error) {
+	sheets, err := a.excel.GetSheetList(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workbook: %w", err)
+	}
+
+	summaries := make([]SheetSummary, 0, len(sheets))
+	for _, sheet := range sheets {
+		summary := SheetSummary{Name: sheet}
+
+		stats, err := a.excel.GetSheetStats(path, sheet)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read sheet %s: %w", sheet, err)
+		}
+		summary.RowCount = stats.RowCount
+		summary.ColumnCount = stats.ColumnCount
+
+		if stats.FirstDataRow > 0 {
+			headers, err := a.excel.GetRowValues(path, stats.FirstDataRow, sheet, true)
+			if err == nil {
+				summary.Headers = headers
+			}
+		}
+
+		if formulas, err := a.excel.ExplainFormulasFromSheet(path, sheet); err == nil {
+			summary.FormulaCount = len(formulas)
+		}
+
+		summaries = append(summaries, summary)
+	}
+
+	return summaries, nil
+}
+
+// summarizeDocument extracts the text of a document and keeps a preview
+func (a *Analyzer) summarizeDocument(ctx context.Context, path string) (*DocumentSummary, error) {
+	text, err := a.documents.ExtractText(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract text: %w", err)
+	}
+
+	summary := &DocumentSummary{
+		WordCount: len(strings.Fields(text)),
+		Preview:   text,
+	}
+	if runes := []rune(text); len(runes) > previewLength {
+		summary.Preview = string(runes[:previewLength])
+		summary.Truncated = true
+	}
+
+	return summary, nil
+}
+
+// sniffFormat identifies a file's format from its content rather than its name,
+// since attachment names are often missing or wrong
+func sniffFormat(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	header := make([]byte, 8)
+	n, _ := file.Read(header)
+	header = header[:n]
+
+	switch {
+	case bytes.HasPrefix(header, pdfMagic):
+		return "pdf", nil
+	case bytes.HasPrefix(header, rtfMagic):
+		return "rtf", nil
+	case bytes.HasPrefix(header, zipMagic):
+		return sniffZipFormat(path), nil
+	case bytes.HasPrefix(header, oleMagic):
+		// Legacy binary Office formats can only be told apart by name
+		switch ext := strings.ToLower(filepath.Ext(path)); ext {
+		case ".xls", ".doc", ".ppt":
+			return ext[1:], nil
+		}
+		return "ole", nil
+	default:
+		return "unknown", nil
+	}
+}
+
+// sniffZipFormat distinguishes Office Open XML packages by their main part
+func sniffZipFormat(path string) string {
+	reader, err := zip.OpenReader(path)
+	if err != nil {
+		return "zip"
+	}
+	defer reader.Close()
+
+	for _, f := range reader.File {
+		switch f.Name {
+		case "xl/workbook.xml":
+			return "xlsx"
+		case "word/document.xml":
+			return "docx"
+		case "ppt/presentation.xml":
+			return "pptx"
+		case "mimetype":
+			// OpenDocument and EPUB packages name their type in a stored entry
+			if format := sniffMimetype(f); format != "" {
+				return format
+			}
+		}
+	}
+
+	return "zip"
+}
+
+// sniffMimetype maps the mimetype entry of an ODF or EPUB package to a format
+func sniffMimetype(f *zip.File) string {
+	rc, err := f.Open()
+	if err != nil {
+		return ""
+	}
+	defer rc.Close()
+
+	mimetype := make([]byte, 64)
+	n, _ := io.ReadFull(rc, mimetype)
+	switch strings.TrimSpace(string(mimetype[:n])) {
+	case "application/vnd.oasis.opendocument.text":
+		return "odt"
+	case "application/epub+zip":
+		return "epub"
+	}
+	return ""
+}
+
+// ensureExtension renames path so it ends in one of the accepted extensions
+// (the first is used when renaming); the extraction libraries rely on it
+func ensureExtension(path string, accepted ...string) (string, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, candidate := range accepted {
+		if ext == candidate {
+			return path, nil
+		}
+	}
+
+	renamed := path + accepted[0]
+	if _, err := os.Stat(renamed); err == nil {
+		return "", fmt.Errorf("cannot rename attachment to %s: file already exists", renamed)
+	}
+	if err := os.Rename(path, renamed); err != nil {
+		return "", fmt.Errorf("failed to rename attachment: %w", err)
+	}
+
+	return renamed, nil
+}
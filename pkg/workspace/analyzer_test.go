@@ -0,0 +1,227 @@
+package workspace
+
+import (
+	"archive/zip"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kevsmith/my-mcp/pkg/document"
+	"github.com/kevsmith/my-mcp/pkg/excel"
+	"github.com/kevsmith/my-mcp/pkg/outlook"
+	"github.com/kevsmith/my-mcp/pkg/shared"
+	"github.com/kevsmith/my-mcp/pkg/shared/sandbox"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/xuri/excelize/v2"
+)
+
+// fakeAttachments serves attachments from files on disk, copying them into the
+// requested directory the way the Outlook server's SaveAsFile does
+type fakeAttachments struct {
+	attachments []outlook.Attachment
+	sources     map[int]string
+	savedIndex  int
+}
+
+func (f *fakeAttachments) ListAttachments(messageID string) (*outlook.AttachmentListResponse, error) {
+	return &outlook.AttachmentListResponse{ID: messageID, Attachments: f.attachments, Count: len(f.attachments)}, nil
+}
+
+func (f *fakeAttachments) SaveAttachment(messageID string, index int, directory string) (*outlook.SaveAttachmentResponse, error) {
+	f.savedIndex = index
+	data, err := os.ReadFile(f.sources[index])
+	if err != nil {
+		return nil, err
+	}
+
+	name := f.attachments[index-1].FileName
+	path := filepath.Join(directory, name)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return nil, err
+	}
+
+	return &outlook.SaveAttachmentResponse{ID: messageID, Index: index, FileName: name, Path: path, Size: int64(len(data))}, nil
+}
+
+func newTestAnalyzer(source AttachmentSource) *Analyzer {
+	excelManager := excel.NewManagerWithConfig(excel.CacheConfig{MaxSize: 2, DefaultTTL: time.Minute})
+	return NewAnalyzer(source, excelManager, document.NewManager())
+}
+
+func writeTestWorkbook(t *testing.T, path string) {
+	t.Helper()
+
+	f := excelize.NewFile()
+	defer f.Close()
+	f.SetSheetRow("Sheet1", "A1", &[]interface{}{"Region", "Q1", "Q2", "Total"})
+	f.SetSheetRow("Sheet1", "A2", &[]interface{}{"North", 10, 20})
+	f.SetCellFormula("Sheet1", "D2", "B2+C2")
+	if err := f.SaveAs(path); err != nil {
+		t.Fatalf("Failed to write workbook: %v", err)
+	}
+}
+
+func TestAnalyzeSpreadsheetAttachment(t *testing.T) {
+	sourceDir := t.TempDir()
+	workbook := filepath.Join(sourceDir, "budget.xlsx")
+	writeTestWorkbook(t, workbook)
+
+	source := &fakeAttachments{
+		attachments: []outlook.Attachment{
+			{Index: 1, FileName: "logo.png", Inline: true},
+			// Attachment names are not trusted: the missing extension is restored from the content
+			{Index: 2, FileName: "budget"},
+		},
+		sources: map[int]string{2: workbook},
+	}
+
+	analysis, err := newTestAnalyzer(source).AnalyzeAttachment(context.Background(), "msg1", 0, t.TempDir())
+	if err != nil {
+		t.Fatalf("AnalyzeAttachment failed: %v", err)
+	}
+
+	if source.savedIndex != 2 {
+		t.Errorf("Expected the first non-inline attachment to be analyzed, got index %d", source.savedIndex)
+	}
+	if analysis.Kind != AttachmentKindSpreadsheet || analysis.Format != "xlsx" {
+		t.Errorf("Expected xlsx spreadsheet, got %s/%s", analysis.Kind, analysis.Format)
+	}
+	if !strings.HasSuffix(analysis.Path, "budget.xlsx") {
+		t.Errorf("Expected saved file to gain .xlsx extension, got %s", analysis.Path)
+	}
+	if len(analysis.Sheets) != 1 {
+		t.Fatalf("Expected 1 sheet, got %d", len(analysis.Sheets))
+	}
+
+	sheet := analysis.Sheets[0]
+	if sheet.Name != "Sheet1" || sheet.RowCount != 2 || sheet.FormulaCount != 1 {
+		t.Errorf("Unexpected sheet summary: %+v", sheet)
+	}
+	if len(sheet.Headers) != 4 || sheet.Headers[0] != "Region" {
+		t.Errorf("Unexpected headers: %v", sheet.Headers)
+	}
+
+	summary := formatAnalysis(analysis)
+	if !strings.Contains(summary, "Sheet1: 2 rows x 4 columns, 1 formulas") || !strings.Contains(summary, "Region | Q1 | Q2 | Total") {
+		t.Errorf("Unexpected summary: %s", summary)
+	}
+}
+
+func TestAnalyzeDocumentAttachment(t *testing.T) {
+	sourceDir := t.TempDir()
+	docxPath := filepath.Join(sourceDir, "notes.docx")
+
+	file, err := os.Create(docxPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(file)
+	parts := map[string]string{
+		"[Content_Types].xml":          `<?xml version="1.0" encoding="UTF-8"?><Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"></Types>`,
+		"word/_rels/document.xml.rels": `<?xml version="1.0" encoding="UTF-8"?><Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"></Relationships>`,
+		"word/document.xml":            `<?xml version="1.0" encoding="UTF-8"?><w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body><w:p><w:r><w:t>Quarterly plan for the north region</w:t></w:r></w:p></w:body></w:document>`,
+	}
+	for name, content := range parts {
+		w, _ := zw.Create(name)
+		w.Write([]byte(content))
+	}
+	zw.Close()
+	file.Close()
+
+	source := &fakeAttachments{
+		attachments: []outlook.Attachment{{Index: 1, FileName: "notes.docx"}},
+		sources:     map[int]string{1: docxPath},
+	}
+
+	analysis, err := newTestAnalyzer(source).AnalyzeAttachment(context.Background(), "msg1", 1, t.TempDir())
+	if err != nil {
+		t.Fatalf("AnalyzeAttachment failed: %v", err)
+	}
+
+	if analysis.Kind != AttachmentKindDocument || analysis.Format != "docx" {
+		t.Errorf("Expected docx document, got %s/%s", analysis.Kind, analysis.Format)
+	}
+	if analysis.Document == nil || analysis.Document.WordCount != 6 || !strings.Contains(analysis.Document.Preview, "Quarterly plan") {
+		t.Errorf("Unexpected document summary: %+v", analysis.Document)
+	}
+}
+
+func TestAnalyzeUnsupportedAttachment(t *testing.T) {
+	sourceDir := t.TempDir()
+	textPath := filepath.Join(sourceDir, "readme.txt")
+	if err := os.WriteFile(textPath, []byte("plain text"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	source := &fakeAttachments{
+		attachments: []outlook.Attachment{{Index: 1, FileName: "readme.txt"}},
+		sources:     map[int]string{1: textPath},
+	}
+
+	analysis, err := newTestAnalyzer(source).AnalyzeAttachment(context.Background(), "msg1", 1, t.TempDir())
+	if err != nil {
+		t.Fatalf("AnalyzeAttachment failed: %v", err)
+	}
+	if analysis.Kind != AttachmentKindUnsupported || analysis.Unsupported == "" {
+		t.Errorf("Expected unsupported analysis, got %+v", analysis)
+	}
+}
+
+func TestAnalyzeAttachmentNoCandidates(t *testing.T) {
+	source := &fakeAttachments{
+		attachments: []outlook.Attachment{{Index: 1, FileName: "logo.png", Inline: true}},
+	}
+
+	if _, err := newTestAnalyzer(source).AnalyzeAttachment(context.Background(), "msg1", 0, t.TempDir()); err == nil {
+		t.Error("Expected error when the message only has inline attachments")
+	}
+}
+
+func TestAnalyzeEmailAttachmentSaveRoots(t *testing.T) {
+	sourceDir := t.TempDir()
+	textPath := filepath.Join(sourceDir, "readme.txt")
+	if err := os.WriteFile(textPath, []byte("plain text"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	source := &fakeAttachments{
+		attachments: []outlook.Attachment{{Index: 1, FileName: "readme.txt"}},
+		sources:     map[int]string{1: textPath},
+	}
+
+	root := t.TempDir()
+	paths, err := sandbox.New([]string{root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := AnalyzeEmailAttachmentHandler(newTestAnalyzer(source), paths)
+	analyze := func(directory string) *mcp.CallToolResult {
+		t.Helper()
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = map[string]any{"message_id": "msg1", "index": 1, "directory": directory}
+		result, err := handler(context.Background(), request)
+		if err != nil {
+			t.Fatalf("analyze_email_attachment failed: %v", err)
+		}
+		return result
+	}
+
+	outside := t.TempDir()
+	result := analyze(outside)
+	if !result.IsError || !strings.Contains(result.Content[0].(mcp.TextContent).Text, shared.CodeAccessDenied) {
+		t.Errorf("Expected ACCESS_DENIED outside the save root, got %+v", result)
+	}
+	if _, err := os.Stat(filepath.Join(outside, "readme.txt")); err == nil {
+		t.Error("Attachment was saved outside the save root")
+	}
+
+	// Without a directory the attachment goes to the first root
+	if result := analyze(""); result.IsError {
+		t.Fatalf("Expected a save without a directory to succeed: %+v", result)
+	}
+	if _, err := os.Stat(filepath.Join(root, "readme.txt")); err != nil {
+		t.Errorf("Attachment not saved in the save root: %v", err)
+	}
+}
@@ -0,0 +1,25 @@
+package workspace
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func GetToolDefinitions() []mcp.Tool {
+	return []mcp.Tool{
+		mcp.NewTool("analyze_email_attachment",
+			mcp.WithDescription("Save an Outlook message attachment and analyze it in one step: spreadsheets are summarized sheet by sheet (size, headers, formulas) and documents (.pdf, .docx, .pptx, .doc, .ppt, .rtf, .odt, .epub) have their text extracted. The format is detected from the file content, not its name"),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithString("message_id",
+				mcp.Description("The message ID (EntryID from Outlook)"),
+				mcp.Required(),
+			),
+			mcp.WithNumber("index",
+				mcp.Description("Attachment index from list_attachments (default: the first attachment that isn't inline in the body)"),
+			),
+			mcp.WithString("directory",
+				mcp.Description("Directory to save the attachment into; with save roots it must lie under one (default: the first save root, or an outlook-mcp-attachments folder in the temp directory)"),
+			),
+		),
+	}
+}
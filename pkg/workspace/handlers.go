@@ -0,0 +1,86 @@
+package workspace
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/kevsmith/my-mcp/pkg/shared"
+	"github.com/kevsmith/my-mcp/pkg/shared/sandbox"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+type AnalyzeEmailAttachmentArgs struct {
+	MessageID string `json:"message_id"`
+	Index     int    `json:"index,omitempty"`
+	Directory string `json:"directory,omitempty"`
+}
+
+// AnalyzeEmailAttachmentHandler handles the analyze_email_attachment tool;
+// paths restricts where the attachment is saved, like save_attachment
+func AnalyzeEmailAttachmentHandler(analyzer *Analyzer, paths *sandbox.Sandbox) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		var args AnalyzeEmailAttachmentArgs
+		argBytes, err := json.Marshal(request.Params.Arguments)
+		if err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Failed to marshal arguments"), nil
+		}
+		if err := json.Unmarshal(argBytes, &args); err != nil {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "Invalid arguments"), nil
+		}
+
+		if args.MessageID == "" {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "message_id parameter is required"), nil
+		}
+		if args.Index < 0 {
+			return shared.ErrorResultf(shared.CodeInvalidArgument, "index must be 1 or greater"), nil
+		}
+		if args.Directory == "" && paths != nil {
+			args.Directory = paths.Roots()[0]
+		}
+		if args.Directory != "" {
+			if err := paths.Check(args.Directory); err != nil {
+				return shared.ErrorResult(err), nil
+			}
+		}
+
+		analysis, err := analyzer.AnalyzeAttachment(ctx, args.MessageID, args.Index, args.Directory)
+		if err != nil {
+			return shared.ErrorResultFromErr("Failed to analyze attachment", err), nil
+		}
+
+		return mcp.NewToolResultText(formatAnalysis(analysis)), nil
+	}
+}
+
+// Helper function to format an attachment analysis as a readable summary
+func formatAnalysis(analysis *AttachmentAnalysis) string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf(`Attachment: %s (%d bytes)
+Saved to: %s
+Detected format: %s
+`, analysis.FileName, analysis.Size, analysis.Path, analysis.Format))
+
+	switch analysis.Kind {
+	case AttachmentKindSpreadsheet:
+		result.WriteString(fmt.Sprintf("\nSpreadsheet with %d sheets:\n", len(analysis.Sheets)))
+		for _, sheet := range analysis.Sheets {
+			result.WriteString(fmt.Sprintf("\n- %s: %d rows x %d columns, %d formulas\n", sheet.Name, sheet.RowCount, sheet.ColumnCount, sheet.FormulaCount))
+			if len(sheet.Headers) > 0 {
+				result.WriteString(fmt.Sprintf("  Headers: %s\n", strings.Join(sheet.Headers, " | ")))
+			}
+		}
+		result.WriteString("\nUse the excel tools with the saved path for cell-level detail.")
+	case AttachmentKindDocument:
+		result.WriteString(fmt.Sprintf("\nDocument with %d words.\n\n", analysis.Document.WordCount))
+		result.WriteString(analysis.Document.Preview)
+		if analysis.Document.Truncated {
+			result.WriteString("\n\n[Preview truncated; use extract_text with the saved path for the full text.]")
+		}
+	default:
+		result.WriteString(fmt.Sprintf("\nNot analyzed: %s", analysis.Unsupported))
+	}
+
+	return result.String()
+}